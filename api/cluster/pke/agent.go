@@ -0,0 +1,169 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+
+	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
+	"github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// AgentCommandResult is the result of a command reported by the PKE agent of a node.
+type AgentCommandResult struct {
+	Status string `json:"status" binding:"required"`
+	Output string `json:"output,omitempty"`
+	Config string `json:"config,omitempty"` // renewed kubeconfig in base64 or empty if not a master
+}
+
+type agentCommandQueue interface {
+	PollAgentCommands(node string) ([]internalPke.AgentCommand, error)
+	ReportAgentCommand(commandID uint, succeeded bool, output string, config []byte) error
+}
+
+func (a *API) getAgentCommandQueue(c *gin.Context) (agentCommandQueue, bool) {
+	commonCluster, _, ok := a.getCluster(c)
+	if !ok {
+		return nil, false
+	}
+
+	queue, ok := commonCluster.(agentCommandQueue)
+	if !ok {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "agent commands are not supported for this cluster",
+			Error:   errors.Errorf("agent commands are not implemented in %T", commonCluster).Error(),
+		})
+		return nil, false
+	}
+
+	return queue, true
+}
+
+// ListAgentCommands returns the pending commands of a node to its agent.
+// The returned commands are considered running until the agent reports their results.
+func (a *API) ListAgentCommands(c *gin.Context) {
+	queue, ok := a.getAgentCommandQueue(c)
+	if !ok {
+		return
+	}
+
+	node := c.Query("node")
+	if node == "" {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "node is required",
+			Error:   "node is required",
+		})
+		return
+	}
+
+	commands, err := queue.PollAgentCommands(node)
+	if err != nil {
+		a.errorHandler.Handle(err)
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to list agent commands",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, commands)
+}
+
+// ReportAgentCommand saves the result of a command reported by the agent of a node.
+// Master nodes report their renewed admin kubeconfig with the results of certificate renewals.
+func (a *API) ReportAgentCommand(c *gin.Context) {
+	queue, ok := a.getAgentCommandQueue(c)
+	if !ok {
+		return
+	}
+
+	commandID, err := strconv.ParseUint(c.Param("commandId"), 10, 32)
+	if err != nil {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid command ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var request AgentCommandResult
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if request.Status != internalPke.AgentCommandStatusSucceeded && request.Status != internalPke.AgentCommandStatusFailed {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request",
+			Error:   errors.Errorf("invalid command status %q", request.Status).Error(),
+		})
+		return
+	}
+
+	var config []byte
+	if request.Config != "" {
+		config, err = base64.StdEncoding.DecodeString(request.Config)
+		if err != nil {
+			ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid base64 in config field",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	err = queue.ReportAgentCommand(uint(commandID), request.Status == internalPke.AgentCommandStatusSucceeded, request.Output, config)
+	if gorm.IsRecordNotFoundError(errors.Cause(err)) {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "agent command not found",
+			Error:   err.Error(),
+		})
+		return
+	} else if e, ok := errors.Cause(err).(interface{ IsInvalid() bool }); ok && e.IsInvalid() {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	} else if err != nil {
+		a.errorHandler.Handle(err)
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to save agent command result",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, struct{}{})
+}
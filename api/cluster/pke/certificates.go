@@ -0,0 +1,182 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/auth"
+	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
+	"github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"go.uber.org/cadence/client"
+)
+
+// CertificateRotationRequest describes a certificate rotation of a cluster.
+type CertificateRotationRequest struct {
+	Type string `json:"type" binding:"required"`
+}
+
+type certificateRotator interface {
+	GetCertificateExpiries() ([]internalPke.CertificateExpiry, error)
+	RotateCertificates(ctx context.Context, rotationType string, userID uint, workflowClient client.Client) (*internalPke.CertificateRotation, error)
+	ListCertificateRotations() ([]internalPke.CertificateRotation, error)
+	GetCertificateRotation(rotationID uint) (*internalPke.CertificateRotation, error)
+}
+
+func (a *API) getCertificateRotator(c *gin.Context) (certificateRotator, bool) {
+	commonCluster, _, ok := a.getCluster(c)
+	if !ok {
+		return nil, false
+	}
+
+	rotator, ok := commonCluster.(certificateRotator)
+	if !ok {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "certificate rotation is not supported for this cluster",
+			Error:   errors.Errorf("certificate rotation is not implemented in %T", commonCluster).Error(),
+		})
+		return nil, false
+	}
+
+	return rotator, true
+}
+
+// ListCertificates lists the expiry of the certificates of the cluster, earliest first.
+func (a *API) ListCertificates(c *gin.Context) {
+	rotator, ok := a.getCertificateRotator(c)
+	if !ok {
+		return
+	}
+
+	expiries, err := rotator.GetCertificateExpiries()
+	if err != nil {
+		a.errorHandler.Handle(err)
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to list certificates",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, expiries)
+}
+
+// CreateCertificateRotation starts the rotation of the leaf certificates or the CAs of the cluster.
+func (a *API) CreateCertificateRotation(c *gin.Context) {
+	rotator, ok := a.getCertificateRotator(c)
+	if !ok {
+		return
+	}
+
+	var request CertificateRotationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID := auth.GetCurrentUser(c.Request).ID
+
+	rotation, err := rotator.RotateCertificates(c.Request.Context(), request.Type, userID, a.workflowClient)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if e, ok := errors.Cause(err).(interface{ IsInvalid() bool }); ok && e.IsInvalid() {
+			code = http.StatusBadRequest
+		} else {
+			a.errorHandler.Handle(err)
+		}
+
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    code,
+			Message: "failed to start certificate rotation",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, rotation)
+}
+
+// ListCertificateRotations lists the certificate rotations of the cluster.
+func (a *API) ListCertificateRotations(c *gin.Context) {
+	rotator, ok := a.getCertificateRotator(c)
+	if !ok {
+		return
+	}
+
+	rotations, err := rotator.ListCertificateRotations()
+	if err != nil {
+		a.errorHandler.Handle(err)
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to list certificate rotations",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rotations)
+}
+
+// GetCertificateRotation returns a certificate rotation of the cluster with its agent commands.
+func (a *API) GetCertificateRotation(c *gin.Context) {
+	rotator, ok := a.getCertificateRotator(c)
+	if !ok {
+		return
+	}
+
+	rotationID, err := strconv.ParseUint(c.Param("rotationId"), 10, 32)
+	if err != nil {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid rotation ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	rotation, err := rotator.GetCertificateRotation(uint(rotationID))
+	if gorm.IsRecordNotFoundError(errors.Cause(err)) {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "certificate rotation not found",
+			Error:   err.Error(),
+		})
+		return
+	} else if err != nil {
+		err = emperror.Wrap(err, "failed to get certificate rotation")
+		a.errorHandler.Handle(err)
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to get certificate rotation",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rotation)
+}
@@ -83,10 +83,13 @@ func (a *API) ListCommands(c *gin.Context) {
 	}
 
 	for nodePool := range nodePools {
-		commands[nodePool], _ = clusterCommander.GetBootstrapCommand(nodePool, a.externalBaseURL, token)
+		// pre-provisioned hosts have their own commands, see ListHosts
+		if command, err := clusterCommander.GetBootstrapCommand(nodePool, a.externalBaseURL, token); err == nil {
+			commands[nodePool] = command
+		}
 	}
 
-	if len(commands) == 0 { // give some examples for the user...
+	if len(nodePools) == 0 { // give some examples for the user...
 		commands["master"], _ = clusterCommander.GetBootstrapCommand("master", a.externalBaseURL, token)
 		commands["pool1"], _ = clusterCommander.GetBootstrapCommand("pool1", a.externalBaseURL, token)
 	}
@@ -0,0 +1,152 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/banzaicloud/pipeline/cluster"
+	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	"github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+// Host describes a pre-provisioned host with its readiness.
+type Host struct {
+	Name        string     `json:"name"`
+	NodePool    string     `json:"nodePool"`
+	PrivateIP   string     `json:"privateIP"`
+	Master      bool       `json:"master"`
+	Ready       bool       `json:"ready"`
+	LastCheckIn *time.Time `json:"lastCheckIn,omitempty"`
+}
+
+// HostCommand is the command to run on a pre-provisioned host to install it.
+type HostCommand struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+// ListHosts lists the pre-provisioned hosts of the cluster with their readiness.
+// The bootstrap commands contain a cluster token, so they are only returned one by one by GetHostCommand.
+func (a *API) ListHosts(c *gin.Context) {
+	commonCluster, _, ok := a.getCluster(c)
+	if !ok {
+		return
+	}
+
+	hostLister, ok := commonCluster.(interface {
+		ListHosts() []cluster.PKEHost
+	})
+	if !ok {
+		err := errors.Errorf("not implemented for this type of cluster (%T)", commonCluster)
+
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Not implemented",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	hosts := []Host{}
+	for _, host := range hostLister.ListHosts() {
+		hosts = append(hosts, Host{
+			Name:        host.Name,
+			NodePool:    host.NodePool,
+			PrivateIP:   host.PrivateIP,
+			Master:      host.Master,
+			Ready:       host.LastCheckIn != nil,
+			LastCheckIn: host.LastCheckIn,
+		})
+	}
+
+	c.JSON(http.StatusOK, hosts)
+}
+
+// GetHostCommand returns the command to install a pre-provisioned host of the cluster
+func (a *API) GetHostCommand(c *gin.Context) {
+	commonCluster, _, ok := a.getCluster(c)
+	if !ok {
+		return
+	}
+
+	hostCommander, ok := commonCluster.(interface {
+		ListHosts() []cluster.PKEHost
+		GetHostBootstrapCommand(nodePool, host, url, token string) (string, error)
+		GetPipelineToken(tokenGenerator interface{}) (string, error)
+	})
+	if !ok {
+		err := errors.Errorf("not implemented for this type of cluster (%T)", commonCluster)
+
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Not implemented",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	hostName := c.Param("hostName")
+
+	var host *cluster.PKEHost
+	for _, h := range hostCommander.ListHosts() {
+		if h.Name == hostName {
+			h := h
+			host = &h
+			break
+		}
+	}
+
+	if host == nil {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Host not found",
+			Error:   errors.Errorf("host %q not found", hostName).Error(),
+		})
+		return
+	}
+
+	token, err := hostCommander.GetPipelineToken(a.tokenGenerator)
+	if err != nil {
+		err := emperror.Wrap(err, "can't generate token")
+		a.errorHandler.Handle(err)
+
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal error",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	command, err := hostCommander.GetHostBootstrapCommand(host.NodePool, host.Name, a.externalBaseURL, token)
+	if err != nil {
+		err := emperror.WrapWith(err, "can't generate bootstrap command", "host", host.Name)
+		a.errorHandler.Handle(err)
+
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal error",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, HostCommand{Name: host.Name, Command: command})
+}
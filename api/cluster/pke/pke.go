@@ -62,7 +62,18 @@ func (a *API) getCluster(c *gin.Context) (cluster.CommonCluster, logrus.FieldLog
 }
 
 func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("agent/commands", a.ListAgentCommands)
+	r.POST("agent/commands/:commandId", a.ReportAgentCommand)
+	r.GET("certificates", a.ListCertificates)
+	r.GET("certificates/rotations", a.ListCertificateRotations)
+	r.POST("certificates/rotations", a.CreateCertificateRotation)
+	r.GET("certificates/rotations/:rotationId", a.GetCertificateRotation)
 	r.GET("commands", a.ListCommands)
+	r.GET("hosts", a.ListHosts)
+	r.GET("hosts/:hostName/command", a.GetHostCommand)
 	r.GET("ready", a.GetReady)
 	r.POST("ready", a.PostReady)
+	r.GET("upgrades", a.ListUpgrades)
+	r.POST("upgrades", a.CreateUpgrade)
+	r.GET("upgrades/:upgradeId", a.GetUpgrade)
 }
@@ -0,0 +1,161 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/auth"
+	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
+	"github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"go.uber.org/cadence/client"
+)
+
+// UpgradeRequest describes a Kubernetes version upgrade of a cluster.
+type UpgradeRequest struct {
+	Version  string `json:"version" binding:"required"`
+	Strategy string `json:"strategy,omitempty"`
+}
+
+type kubernetesUpgrader interface {
+	UpgradeKubernetes(ctx context.Context, version, strategy string, userID uint, workflowClient client.Client, externalBaseURL string) (*internalPke.KubernetesUpgrade, error)
+	ListKubernetesUpgrades() ([]internalPke.KubernetesUpgrade, error)
+	GetKubernetesUpgrade(upgradeID uint) (*internalPke.KubernetesUpgrade, error)
+}
+
+func (a *API) getUpgrader(c *gin.Context) (kubernetesUpgrader, bool) {
+	commonCluster, _, ok := a.getCluster(c)
+	if !ok {
+		return nil, false
+	}
+
+	upgrader, ok := commonCluster.(kubernetesUpgrader)
+	if !ok {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Kubernetes upgrade is not supported for this cluster",
+			Error:   errors.Errorf("Kubernetes upgrade is not implemented in %T", commonCluster).Error(),
+		})
+		return nil, false
+	}
+
+	return upgrader, true
+}
+
+// CreateUpgrade starts the upgrade of the cluster to a new Kubernetes version.
+func (a *API) CreateUpgrade(c *gin.Context) {
+	upgrader, ok := a.getUpgrader(c)
+	if !ok {
+		return
+	}
+
+	var request UpgradeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID := auth.GetCurrentUser(c.Request).ID
+
+	upgrade, err := upgrader.UpgradeKubernetes(c.Request.Context(), request.Version, request.Strategy, userID, a.workflowClient, a.externalBaseURL)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if e, ok := errors.Cause(err).(interface{ IsInvalid() bool }); ok && e.IsInvalid() {
+			code = http.StatusBadRequest
+		} else {
+			a.errorHandler.Handle(err)
+		}
+
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    code,
+			Message: "failed to start Kubernetes upgrade",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, upgrade)
+}
+
+// ListUpgrades lists the Kubernetes upgrades of the cluster.
+func (a *API) ListUpgrades(c *gin.Context) {
+	upgrader, ok := a.getUpgrader(c)
+	if !ok {
+		return
+	}
+
+	upgrades, err := upgrader.ListKubernetesUpgrades()
+	if err != nil {
+		a.errorHandler.Handle(err)
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to list Kubernetes upgrades",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, upgrades)
+}
+
+// GetUpgrade returns a Kubernetes upgrade of the cluster with the progress of each node.
+func (a *API) GetUpgrade(c *gin.Context) {
+	upgrader, ok := a.getUpgrader(c)
+	if !ok {
+		return
+	}
+
+	upgradeID, err := strconv.ParseUint(c.Param("upgradeId"), 10, 32)
+	if err != nil {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid upgrade ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	upgrade, err := upgrader.GetKubernetesUpgrade(uint(upgradeID))
+	if gorm.IsRecordNotFoundError(errors.Cause(err)) {
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Kubernetes upgrade not found",
+			Error:   err.Error(),
+		})
+		return
+	} else if err != nil {
+		err = emperror.Wrap(err, "failed to get Kubernetes upgrade")
+		a.errorHandler.Handle(err)
+		ginutils.ReplyWithErrorResponse(c, &common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to get Kubernetes upgrade",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, upgrade)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/banzaicloud/pipeline/model/defaults"
 	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
 	"github.com/banzaicloud/pipeline/secret"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -89,6 +90,41 @@ func (a *ClusterAPI) CreateCluster(
 		"cluster":      createClusterRequest.Name,
 	})
 
+	var template *defaults.ClusterTemplateModel
+	if createClusterRequest.Template != nil {
+		logger = logger.WithField("template", createClusterRequest.Template.Name)
+
+		logger.Info("create cluster request from template")
+
+		templateRequest, t, err := defaults.RenderClusterTemplate(organizationID, createClusterRequest.Template)
+		if err != nil {
+			code := http.StatusBadRequest
+			if errors.Cause(err) == pkgErrors.ErrorClusterTemplateNotFound {
+				code = http.StatusNotFound
+			}
+
+			return nil, &pkgCommon.ErrorResponse{
+				Code:    code,
+				Message: "error during creating request from template",
+				Error:   err.Error(),
+			}
+		}
+
+		createClusterRequest = applyClusterTemplateRequest(templateRequest, createClusterRequest)
+		template = t
+
+		// posthooks of the template are run with the parameters of the request
+		templatePostHooks := make(pkgCluster.PostHooks, len(createClusterRequest.PostHooks)+len(postHooks))
+		for name, param := range createClusterRequest.PostHooks {
+			templatePostHooks[name] = param
+		}
+		for name, param := range postHooks {
+			templatePostHooks[name] = param
+		}
+		postHooks = templatePostHooks
+		createClusterRequest.PostHooks = postHooks
+	}
+
 	// TODO: refactor profile handling as well?
 	if len(createClusterRequest.ProfileName) != 0 {
 		logger = logger.WithField("profile", createClusterRequest.ProfileName)
@@ -189,5 +225,35 @@ func (a *ClusterAPI) CreateCluster(
 		}
 	}
 
+	if template != nil {
+		if err := defaults.SaveClusterTemplateUsage(organizationID, commonCluster.GetID(), template); err != nil {
+			logger.Errorf("error during saving cluster template usage: %s", err.Error())
+		}
+	}
+
 	return commonCluster, nil
 }
+
+// applyClusterTemplateRequest overrides a cluster create request rendered from a template with the fields of the original request.
+// Posthooks are merged by the caller.
+func applyClusterTemplateRequest(templateRequest, createClusterRequest *pkgCluster.CreateClusterRequest) *pkgCluster.CreateClusterRequest {
+	templateRequest.Name = createClusterRequest.Name
+	templateRequest.SecretId = createClusterRequest.SecretId
+	templateRequest.SecretIds = createClusterRequest.SecretIds
+	templateRequest.SecretName = createClusterRequest.SecretName
+	templateRequest.Template = createClusterRequest.Template
+
+	if createClusterRequest.Location != "" {
+		templateRequest.Location = createClusterRequest.Location
+	}
+
+	if createClusterRequest.ScaleOptions != nil {
+		templateRequest.ScaleOptions = createClusterRequest.ScaleOptions
+	}
+
+	if createClusterRequest.TtlMinutes != 0 {
+		templateRequest.TtlMinutes = createClusterRequest.TtlMinutes
+	}
+
+	return templateRequest
+}
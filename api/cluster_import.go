@@ -0,0 +1,131 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ImportClusterRequest gin handler
+func (a *ClusterAPI) ImportClusterRequest(c *gin.Context) {
+	a.logger.Info("Cluster import started")
+
+	var importClusterRequest pkgCluster.ImportClusterRequest
+	if err := c.BindJSON(&importClusterRequest); err != nil {
+		a.logger.Error(errors.Wrap(err, "Error parsing request"))
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if importClusterRequest.SecretId == "" {
+		if importClusterRequest.SecretName == "" {
+			c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "either secretId or secretName has to be set",
+			})
+			return
+		}
+
+		importClusterRequest.SecretId = string(secret.GenerateSecretIDFromName(importClusterRequest.SecretName))
+	}
+
+	orgID := auth.GetCurrentOrganization(c.Request).ID
+	userID := auth.GetCurrentUser(c.Request).ID
+
+	ctx := ginutils.Context(context.Background(), c)
+	commonCluster, err := a.ImportCluster(ctx, &importClusterRequest, orgID, userID)
+	if err != nil {
+		c.JSON(err.Code, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, pkgCluster.CreateClusterResponse{
+		Name:       commonCluster.GetName(),
+		ResourceID: commonCluster.GetID(),
+	})
+}
+
+// ImportCluster imports an existing K8S cluster of a cloud provider
+func (a *ClusterAPI) ImportCluster(
+	ctx context.Context,
+	importClusterRequest *pkgCluster.ImportClusterRequest,
+	organizationID uint,
+	userID uint,
+) (cluster.CommonCluster, *pkgCommon.ErrorResponse) {
+	logger := a.logger.WithFields(logrus.Fields{
+		"organization": organizationID,
+		"user":         userID,
+		"cluster":      importClusterRequest.Name,
+	})
+
+	logger.Infof("Importing cluster with cloud type: %s", importClusterRequest.Cloud)
+
+	commonCluster, err := cluster.CreateCommonClusterFromImportRequest(importClusterRequest, organizationID, userID)
+	if err != nil {
+		logger.Errorf("error during create common cluster from import request: %s", err.Error())
+		return nil, &pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Error:   err.Error(),
+		}
+	}
+
+	creationCtx := cluster.CreationContext{
+		OrganizationID:  organizationID,
+		UserID:          userID,
+		Name:            importClusterRequest.Name,
+		SecretID:        importClusterRequest.SecretId,
+		Provider:        importClusterRequest.Cloud,
+		PostHooks:       importClusterRequest.PostHooks,
+		ExternalBaseURL: a.externalBaseURL,
+	}
+
+	commonCluster, err = a.clusterManager.CreateCluster(ctx, creationCtx, cluster.NewClusterImporter(commonCluster))
+
+	if err == cluster.ErrAlreadyExists || isInvalid(err) {
+		logger.Debugf("invalid cluster import: %s", err.Error())
+
+		return nil, &pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Error:   err.Error(),
+		}
+	} else if err != nil {
+		logger.Errorf("error during cluster import: %s", err.Error())
+
+		return nil, &pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err.Error(),
+		}
+	}
+
+	return commonCluster, nil
+}
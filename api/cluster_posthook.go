@@ -50,6 +50,18 @@ func (a *ClusterAPI) ReRunPostHooks(c *gin.Context) {
 		return
 	}
 
+	if err := cluster.ValidatePostHooks(commonCluster.GetCloud(), ph); err != nil {
+		logger.Debugf("invalid posthooks: %s", err.Error())
+
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid posthooks",
+			Error:   err.Error(),
+		})
+
+		return
+	}
+
 	logger.WithField("workflowName", cluster.RunPostHooksWorkflowName).Info("starting workflow")
 
 	input := cluster.RunPostHooksWorkflowInput{
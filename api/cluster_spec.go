@@ -0,0 +1,135 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// GetClusterSpec sends back the last applied desired state spec of a cluster
+func (a *ClusterAPI) GetClusterSpec(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	spec, err := cluster.GetClusterSpec(commonCluster)
+	if err != nil {
+		a.sendBackClusterSpecErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, spec)
+}
+
+// PlanClusterSpec sends back the changes needed to converge a cluster to the desired state spec
+func (a *ClusterAPI) PlanClusterSpec(c *gin.Context) {
+	var spec pkgCluster.ClusterSpec
+	if err := c.BindJSON(&spec); err != nil {
+		a.logger.Errorf("Error parsing request: %s", err.Error())
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	changes, err := cluster.PlanClusterSpec(commonCluster, &spec)
+	if err != nil {
+		a.sendBackClusterSpecErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pkgCluster.ClusterSpecPlanResponse{
+		Changes: changes,
+	})
+}
+
+// ApplyClusterSpec converges a cluster to the desired state spec and sends back the changes made
+func (a *ClusterAPI) ApplyClusterSpec(c *gin.Context) {
+	var spec pkgCluster.ClusterSpec
+	if err := c.BindJSON(&spec); err != nil {
+		a.logger.Errorf("Error parsing request: %s", err.Error())
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	updateCtx := cluster.UpdateContext{
+		OrganizationID: auth.GetCurrentOrganization(c.Request).ID,
+		UserID:         auth.GetCurrentUser(c.Request).ID,
+		ClusterID:      commonCluster.GetID(),
+	}
+
+	ctx := ginutils.Context(context.Background(), c)
+
+	changes, err := a.clusterManager.ApplyClusterSpec(ctx, updateCtx, commonCluster, &spec, a.externalBaseURL)
+	if err != nil {
+		a.sendBackClusterSpecErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, pkgCluster.ClusterSpecPlanResponse{
+		Changes: changes,
+	})
+}
+
+func (a *ClusterAPI) sendBackClusterSpecErrorResponse(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	message := "cluster spec operation failed"
+
+	switch {
+	case errors.Cause(err) == pkgErrors.ErrorClusterSpecNotFound:
+		code = http.StatusNotFound
+		message = err.Error()
+	case isInvalid(err):
+		code = http.StatusBadRequest
+		message = errors.Cause(err).Error()
+	case isPreconditionFailed(err):
+		code = http.StatusPreconditionFailed
+		message = errors.Cause(err).Error()
+	default:
+		a.errorHandler.Handle(err)
+	}
+
+	c.JSON(code, pkgCommon.ErrorResponse{
+		Code:    code,
+		Message: message,
+	})
+}
@@ -0,0 +1,200 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/model/defaults"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const clusterTemplateNameKey = "name"
+
+// ListClusterTemplates handles /clustertemplates GET api endpoint.
+// Sends back the latest version of the cluster templates of the organization.
+func ListClusterTemplates(c *gin.Context) {
+	organization := auth.GetCurrentOrganization(c.Request)
+
+	templates, err := defaults.ListClusterTemplates(organization.ID)
+	if err != nil {
+		sendBackClusterTemplateErrorResponse(c, err)
+		return
+	}
+
+	sendBackClusterTemplates(c, templates)
+}
+
+// CreateClusterTemplate handles /clustertemplates POST api endpoint.
+// Saves the first version of a cluster template.
+func CreateClusterTemplate(c *gin.Context) {
+	saveClusterTemplate(c, false)
+}
+
+// UpdateClusterTemplate handles /clustertemplates/:name PUT api endpoint.
+// Saves a new version of an existing cluster template.
+func UpdateClusterTemplate(c *gin.Context) {
+	saveClusterTemplate(c, true)
+}
+
+func saveClusterTemplate(c *gin.Context, update bool) {
+	var request pkgCluster.ClusterTemplateRequest
+	if err := c.BindJSON(&request); err != nil {
+		log.Error(errors.Wrap(err, "Error parsing request"))
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if update {
+		request.Name = c.Param(clusterTemplateNameKey)
+	}
+
+	organization := auth.GetCurrentOrganization(c.Request)
+	user := auth.GetCurrentUser(c.Request)
+
+	log.Infof("Save cluster template: %s", request.Name)
+
+	template, err := defaults.SaveClusterTemplate(organization.ID, user.ID, &request, update)
+	if err != nil {
+		sendBackClusterTemplateErrorResponse(c, err)
+		return
+	}
+
+	response, err := template.GetResponse()
+	if err != nil {
+		sendBackClusterTemplateErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetClusterTemplate handles /clustertemplates/:name GET api endpoint.
+// Sends back the latest or the requested version of a cluster template.
+func GetClusterTemplate(c *gin.Context) {
+	var version uint64
+	if v := c.Query("version"); v != "" {
+		var err error
+		version, err = strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid version",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	organization := auth.GetCurrentOrganization(c.Request)
+
+	template, err := defaults.GetClusterTemplate(organization.ID, c.Param(clusterTemplateNameKey), uint(version))
+	if err != nil {
+		sendBackClusterTemplateErrorResponse(c, err)
+		return
+	}
+
+	response, err := template.GetResponse()
+	if err != nil {
+		sendBackClusterTemplateErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListClusterTemplateVersions handles /clustertemplates/:name/versions GET api endpoint.
+// Sends back every version of a cluster template.
+func ListClusterTemplateVersions(c *gin.Context) {
+	organization := auth.GetCurrentOrganization(c.Request)
+
+	templates, err := defaults.ListClusterTemplateVersions(organization.ID, c.Param(clusterTemplateNameKey))
+	if err != nil {
+		sendBackClusterTemplateErrorResponse(c, err)
+		return
+	}
+
+	sendBackClusterTemplates(c, templates)
+}
+
+// ListClusterTemplateClusters handles /clustertemplates/:name/clusters GET api endpoint.
+// Sends back the clusters created from a cluster template, marking the ones created from an outdated version.
+func ListClusterTemplateClusters(c *gin.Context) {
+	organization := auth.GetCurrentOrganization(c.Request)
+
+	clusters, err := defaults.ListClusterTemplateClusters(organization.ID, c.Param(clusterTemplateNameKey))
+	if err != nil {
+		sendBackClusterTemplateErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clusters)
+}
+
+// DeleteClusterTemplate handles /clustertemplates/:name DELETE api endpoint.
+// Deletes every version of a cluster template.
+func DeleteClusterTemplate(c *gin.Context) {
+	organization := auth.GetCurrentOrganization(c.Request)
+	name := c.Param(clusterTemplateNameKey)
+
+	log.Infof("Delete cluster template: %s", name)
+
+	if err := defaults.DeleteClusterTemplate(organization.ID, name); err != nil {
+		sendBackClusterTemplateErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func sendBackClusterTemplates(c *gin.Context, templates []*defaults.ClusterTemplateModel) {
+	response := make([]*pkgCluster.ClusterTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		r, err := template.GetResponse()
+		if err != nil {
+			sendBackClusterTemplateErrorResponse(c, err)
+			return
+		}
+
+		response = append(response, r)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func sendBackClusterTemplateErrorResponse(c *gin.Context, err error) {
+	log.Errorf("Error during cluster template operation: %s", err.Error())
+
+	statusCode := http.StatusBadRequest
+	if errors.Cause(err) == pkgErrors.ErrorClusterTemplateNotFound {
+		statusCode = http.StatusNotFound
+	}
+
+	c.JSON(statusCode, pkgCommon.ErrorResponse{
+		Code:    statusCode,
+		Message: err.Error(),
+		Error:   err.Error(),
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	"github.com/banzaicloud/pipeline/internal/cluster/cost"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/sirupsen/logrus"
+)
+
+// CostAPI implements the cost estimation API actions.
+type CostAPI struct {
+	clusterManager *cluster.Manager
+	clusterGetter  common.ClusterGetter
+	estimator      *cost.Estimator
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewCostAPI returns a new CostAPI instance.
+func NewCostAPI(
+	clusterManager *cluster.Manager,
+	clusterGetter common.ClusterGetter,
+	estimator *cost.Estimator,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *CostAPI {
+	return &CostAPI{
+		clusterManager: clusterManager,
+		clusterGetter:  clusterGetter,
+		estimator:      estimator,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// GetOrganizationCosts returns the cost report of the running clusters of an organization.
+func (a *CostAPI) GetOrganizationCosts(c *gin.Context) {
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+
+	logger := a.logger.WithFields(logrus.Fields{
+		"organization": organizationID,
+	})
+
+	logger.Info("estimating cluster costs")
+
+	clusters, err := a.clusterManager.GetClusters(context.Background(), organizationID)
+	if err != nil {
+		a.errorHandler.Handle(err)
+
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error listing clusters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	costClusters := make([]cost.Cluster, 0, len(clusters))
+	for _, commonCluster := range clusters {
+		costClusters = append(costClusters, commonCluster)
+	}
+
+	c.JSON(http.StatusOK, a.estimator.EstimateClusters(costClusters))
+}
+
+// GetClusterCosts returns the cost estimate of a cluster, its node pools and namespaces.
+func (a *CostAPI) GetClusterCosts(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	status, err := commonCluster.GetStatus()
+	if err != nil {
+		a.errorHandler.Handle(err)
+
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error getting cluster status",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if status.Status != pkgCluster.Running {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "cluster is not running",
+			Error:   "cluster is not running",
+		})
+		return
+	}
+
+	clusterCost, err := a.estimator.EstimateCluster(commonCluster)
+	if err != nil {
+		a.errorHandler.Handle(err)
+
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error estimating cluster cost",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, clusterCost)
+}
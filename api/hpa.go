@@ -16,8 +16,10 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,14 +38,56 @@ import (
 	"github.com/spf13/viper"
 	"k8s.io/api/autoscaling/v2beta1"
 	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
 const hpaAnnotationPrefix = "hpa.autoscaling.banzaicloud.io"
 
+// hpaManagedByLabel marks Horizontal Pod Autoscalers created directly by Pipeline for scale targets
+// which are not handled by hpa-operator (anything other than Deployments and StatefulSets, or requests
+// with metrics and scale behavior hpa-operator annotations can't describe)
+const hpaManagedByLabel = "hpa.autoscaling.banzaicloud.io/managed-by"
+const hpaManagedByPipeline = "pipeline"
+
+// hpaBehaviorAnnotation is the annotation Kubernetes uses to round trip autoscaling/v2beta2 scale behavior
+// through older API versions
+const hpaBehaviorAnnotation = "autoscaling.alpha.kubernetes.io/behavior"
+
+// scaleTargetRef identifies the scale target of a request
+type scaleTargetRef struct {
+	name       string
+	kind       string
+	apiVersion string
+	namespace  string
+}
+
+func (r scaleTargetRef) listNamespace() string {
+	if len(r.namespace) > 0 {
+		return r.namespace
+	}
+	return metav1.NamespaceAll
+}
+
+func getScaleTargetRef(c *gin.Context) (scaleTargetRef, bool) {
+	scaleTarget, ok := ginutils.RequiredQueryOrAbort(c, "scaleTarget")
+	if !ok {
+		return scaleTargetRef{}, false
+	}
+
+	return scaleTargetRef{
+		name:       scaleTarget,
+		kind:       c.Query("kind"),
+		apiVersion: c.Query("apiVersion"),
+		namespace:  c.Query("namespace"),
+	}, true
+}
+
 type scaleTargetNotFoundError struct {
 	scaleTargetRef string
 }
@@ -139,7 +183,13 @@ func PutHpaResource(c *gin.Context) {
 		}
 	}
 
-	err = setDeploymentAutoscalingInfo(client, *scalingRequest)
+	if !hpa.IsWorkloadTarget(scalingRequest.Kind) {
+		err = setScaleTargetAutoscalingInfo(client, *scalingRequest)
+	} else if scalingRequest.RequiresDirectHpa() {
+		err = setWorkloadAutoscalingInfo(client, *scalingRequest)
+	} else {
+		err = setDeploymentAutoscalingInfo(client, *scalingRequest)
+	}
 	if err != nil {
 
 		httpStatusCode := http.StatusBadRequest
@@ -212,21 +262,27 @@ func runPrometheusQuery(config *rest.Config, client *kubernetes.Clientset, query
 
 }
 
-// DeleteHpaResource deletes a Hpa resource annotations from scaleTarget - K8s deployment/statefulset
+// DeleteHpaResource deletes a Hpa resource annotations from scaleTarget - K8s deployment/statefulset,
+// or the Hpa resource itself in case of other scale targets
 func DeleteHpaResource(c *gin.Context) {
 
-	scaleTarget, ok := ginutils.RequiredQueryOrAbort(c, "scaleTarget")
+	scaleTarget, ok := getScaleTargetRef(c)
 	if !ok {
 		return
 	}
-	log.Debugf("getting hpa details for scaleTarget: [%s]", scaleTarget)
+	log.Debugf("deleting hpa for scaleTarget: [%s]", scaleTarget.name)
 
 	kubeConfig, ok := GetK8sConfig(c)
 	if !ok {
 		return
 	}
 
-	err := deleteDeploymentAutoscalingInfo(kubeConfig, scaleTarget)
+	var err error
+	if hpa.IsWorkloadTarget(scaleTarget.kind) {
+		err = deleteDeploymentAutoscalingInfo(kubeConfig, scaleTarget)
+	} else {
+		err = deleteScaleTargetAutoscalingInfo(kubeConfig, scaleTarget)
+	}
 	if err != nil {
 
 		httpStatusCode := http.StatusInternalServerError
@@ -247,13 +303,13 @@ func DeleteHpaResource(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// GetHpaResource returns a Hpa resource bound to a K8s deployment/statefulset
+// GetHpaResource returns a Hpa resource bound to a scale target
 func GetHpaResource(c *gin.Context) {
-	scaleTarget, ok := ginutils.RequiredQueryOrAbort(c, "scaleTarget")
+	scaleTarget, ok := getScaleTargetRef(c)
 	if !ok {
 		return
 	}
-	log.Debugf("getting hpa details for scaleTarget: [%s]", scaleTarget)
+	log.Debugf("getting hpa details for scaleTarget: [%s]", scaleTarget.name)
 
 	kubeConfig, ok := GetK8sConfig(c)
 	if !ok {
@@ -282,7 +338,7 @@ func GetHpaResource(c *gin.Context) {
 
 }
 
-func getHpaResources(scaleTargetRef string, kubeConfig []byte) (*hpa.DeploymentScalingInfo, error) {
+func getHpaResources(scaleTarget scaleTargetRef, kubeConfig []byte) (*hpa.DeploymentScalingInfo, error) {
 	config, err := k8sclient.NewClientConfig(kubeConfig)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to create client config")
@@ -300,23 +356,30 @@ func getHpaResources(scaleTargetRef string, kubeConfig []byte) (*hpa.DeploymentS
 			APIVersion: "autoscaling/v1",
 		},
 	}
-	hpaList, err := client.AutoscalingV2beta1().HorizontalPodAutoscalers(metav1.NamespaceAll).List(listOption)
+	hpaList, err := client.AutoscalingV2beta1().HorizontalPodAutoscalers(scaleTarget.listNamespace()).List(listOption)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, hpaItem := range hpaList.Items {
-		if !hpaBelongsToDeployment(hpaItem, scaleTargetRef) {
+		if !hpaBelongsToScaleTarget(hpaItem, scaleTarget) {
 			continue
 		}
 
-		log.Debugf("hpa found: %v for scaleTragetRef: %v", hpaItem.Name, scaleTargetRef)
+		log.Debugf("hpa found: %v for scaleTragetRef: %v", hpaItem.Name, scaleTarget.name)
 		deploymentItem := hpa.DeploymentScalingInfo{
-			ScaleTarget:   scaleTargetRef,
-			Kind:          hpaItem.Spec.ScaleTargetRef.Kind,
-			MinReplicas:   *hpaItem.Spec.MinReplicas,
-			MaxReplicas:   hpaItem.Spec.MaxReplicas,
-			CustomMetrics: map[string]hpa.CustomMetricStatus{},
+			ScaleTarget:     scaleTarget.name,
+			Kind:            hpaItem.Spec.ScaleTargetRef.Kind,
+			APIVersion:      hpaItem.Spec.ScaleTargetRef.APIVersion,
+			Namespace:       hpaItem.Namespace,
+			MaxReplicas:     hpaItem.Spec.MaxReplicas,
+			CustomMetrics:   map[string]hpa.CustomMetricStatus{},
+			PodsMetrics:     map[string]hpa.PodsMetricStatus{},
+			ObjectMetrics:   map[string]hpa.ObjectMetricStatus{},
+			ExternalMetrics: map[string]hpa.ExternalMetricStatus{},
+		}
+		if hpaItem.Spec.MinReplicas != nil {
+			deploymentItem.MinReplicas = *hpaItem.Spec.MinReplicas
 		}
 
 		for _, metric := range hpaItem.Spec.Metrics {
@@ -329,22 +392,46 @@ func getHpaResources(scaleTargetRef string, kubeConfig []byte) (*hpa.DeploymentS
 					deploymentItem.Memory = getResourceMetricStatus(hpaItem, metric)
 				}
 			case v2beta1.ObjectMetricSourceType:
-				log.Warnf("custom metric %v found for hpa: %v", metric.Object.MetricName, hpaItem.Name)
-				deploymentItem.CustomMetrics[metric.Object.MetricName] = getCustomMetricStatus(hpaItem, metric)
+				if isPrometheusObjectMetric(hpaItem, metric) {
+					log.Debugf("custom metric %v found for hpa: %v", metric.Object.MetricName, hpaItem.Name)
+					deploymentItem.CustomMetrics[metric.Object.MetricName] = getCustomMetricStatus(hpaItem, metric)
+				} else {
+					deploymentItem.ObjectMetrics[metric.Object.MetricName] = getObjectMetricStatus(hpaItem, metric)
+				}
+			case v2beta1.PodsMetricSourceType:
+				deploymentItem.PodsMetrics[metric.Pods.MetricName] = getPodsMetricStatus(hpaItem, metric)
+			case v2beta1.ExternalMetricSourceType:
+				deploymentItem.ExternalMetrics[metric.External.MetricName] = getExternalMetricStatus(hpaItem, metric)
 			default:
 				log.Warnf("metric found: %v for hpa: %v", metric.Type, hpaItem.Name)
 			}
 		}
 
+		behavior, err := getBehavior(hpaItem)
+		if err != nil {
+			log.Warnf("invalid scale behavior found for hpa: %v: %s", hpaItem.Name, err.Error())
+		}
+		deploymentItem.Behavior = behavior
+
+		deploymentItem.Status.CurrentReplicas = hpaItem.Status.CurrentReplicas
+		deploymentItem.Status.DesiredReplicas = hpaItem.Status.DesiredReplicas
 		deploymentItem.Status.Message = generateStatusMessage(hpaItem.Status)
+		for _, condition := range hpaItem.Status.Conditions {
+			deploymentItem.Status.Conditions = append(deploymentItem.Status.Conditions, hpa.ScaleCondition{
+				Type:    string(condition.Type),
+				Status:  string(condition.Status),
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			})
+		}
 
-		if hpaItem.Name != scaleTargetRef {
+		if hpaItem.Name != scaleTarget.name {
 			deploymentItem.Status.Message = "You can't edit this Horizontal Pod Autoscaler resource, in order to manage it with Pipeline please set the same name as deployment name."
 		}
 		return &deploymentItem, nil
 	}
 
-	return nil, &scaleTargetNotFoundError{scaleTargetRef: scaleTargetRef}
+	return nil, &scaleTargetNotFoundError{scaleTargetRef: scaleTarget.name}
 
 }
 
@@ -403,68 +490,260 @@ func getCustomMetricStatus(hpaItem v2beta1.HorizontalPodAutoscaler, metric v2bet
 	return metricStatus
 }
 
-func hpaBelongsToDeployment(hpa v2beta1.HorizontalPodAutoscaler, scaleTragetRef string) bool {
-	if hpa.Spec.ScaleTargetRef.Name != scaleTragetRef {
+func isPrometheusObjectMetric(hpaItem v2beta1.HorizontalPodAutoscaler, metric v2beta1.MetricSpec) bool {
+	_, ok := hpaItem.Annotations[fmt.Sprintf("metric-config.object.%s.prometheus/query", metric.Object.MetricName)]
+	return ok
+}
+
+func getObjectMetricStatus(hpaItem v2beta1.HorizontalPodAutoscaler, metric v2beta1.MetricSpec) hpa.ObjectMetricStatus {
+	metricStatus := hpa.ObjectMetricStatus{
+		ObjectMetric: hpa.ObjectMetric{
+			MetricName: metric.Object.MetricName,
+			Target: hpa.ObjectReference{
+				Kind:       metric.Object.Target.Kind,
+				Name:       metric.Object.Target.Name,
+				APIVersion: metric.Object.Target.APIVersion,
+			},
+			TargetValue: metric.Object.TargetValue.String(),
+		},
+	}
+
+	for _, currentMetricStatus := range hpaItem.Status.CurrentMetrics {
+		if currentMetricStatus.Object != nil && currentMetricStatus.Object.MetricName == metric.Object.MetricName {
+			metricStatus.CurrentValue = currentMetricStatus.Object.CurrentValue.String()
+		}
+	}
+
+	return metricStatus
+}
+
+func getPodsMetricStatus(hpaItem v2beta1.HorizontalPodAutoscaler, metric v2beta1.MetricSpec) hpa.PodsMetricStatus {
+	metricStatus := hpa.PodsMetricStatus{
+		PodsMetric: hpa.PodsMetric{
+			MetricName:         metric.Pods.MetricName,
+			TargetAverageValue: metric.Pods.TargetAverageValue.String(),
+		},
+	}
+
+	for _, currentMetricStatus := range hpaItem.Status.CurrentMetrics {
+		if currentMetricStatus.Pods != nil && currentMetricStatus.Pods.MetricName == metric.Pods.MetricName {
+			metricStatus.CurrentAverageValue = currentMetricStatus.Pods.CurrentAverageValue.String()
+		}
+	}
+
+	return metricStatus
+}
+
+func getExternalMetricStatus(hpaItem v2beta1.HorizontalPodAutoscaler, metric v2beta1.MetricSpec) hpa.ExternalMetricStatus {
+	metricStatus := hpa.ExternalMetricStatus{
+		ExternalMetric: hpa.ExternalMetric{
+			MetricName: metric.External.MetricName,
+		},
+	}
+	if metric.External.MetricSelector != nil {
+		metricStatus.Selector = metric.External.MetricSelector.MatchLabels
+	}
+	if metric.External.TargetValue != nil {
+		metricStatus.TargetValue = metric.External.TargetValue.String()
+	} else if metric.External.TargetAverageValue != nil {
+		metricStatus.TargetAverageValue = metric.External.TargetAverageValue.String()
+	}
+
+	for _, currentMetricStatus := range hpaItem.Status.CurrentMetrics {
+		if currentMetricStatus.External != nil && currentMetricStatus.External.MetricName == metric.External.MetricName {
+			metricStatus.CurrentValue = currentMetricStatus.External.CurrentValue.String()
+			if currentMetricStatus.External.CurrentAverageValue != nil {
+				metricStatus.CurrentAverageValue = currentMetricStatus.External.CurrentAverageValue.String()
+			}
+		}
+	}
+
+	return metricStatus
+}
+
+func getBehavior(hpaItem v2beta1.HorizontalPodAutoscaler) (*hpa.Behavior, error) {
+	value, ok := hpaItem.Annotations[hpaBehaviorAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	var behavior hpa.Behavior
+	err := json.Unmarshal([]byte(value), &behavior)
+	if err != nil {
+		return nil, err
+	}
+
+	return &behavior, nil
+}
+
+func hpaBelongsToScaleTarget(hpa v2beta1.HorizontalPodAutoscaler, scaleTarget scaleTargetRef) bool {
+	if hpa.Spec.ScaleTargetRef.Name != scaleTarget.name {
+		return false
+	}
+	if len(scaleTarget.kind) > 0 && hpa.Spec.ScaleTargetRef.Kind != scaleTarget.kind {
+		return false
+	}
+	if len(scaleTarget.apiVersion) > 0 && hpa.Spec.ScaleTargetRef.APIVersion != scaleTarget.apiVersion {
 		return false
 	}
 	return true
 }
 
-func deleteDeploymentAutoscalingInfo(kubeConfig []byte, scaleTarget string) error {
+func deleteDeploymentAutoscalingInfo(kubeConfig []byte, scaleTarget scaleTargetRef) error {
 	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
 	if err != nil {
 		log.Errorf("Getting K8s client failed: %s", err.Error())
 		return err
 	}
 
+	workloads, err := removeWorkloadHpaAnnotations(client, scaleTarget)
+	if err != nil {
+		return err
+	}
+
+	// the Horizontal Pod Autoscaler might have been created directly by Pipeline
+	deleted, err := deleteManagedHorizontalPodAutoscalers(client, scaleTarget)
+	if err != nil {
+		return err
+	}
+
+	if len(workloads) == 0 && !deleted {
+		return &scaleTargetNotFoundError{scaleTargetRef: scaleTarget.name}
+	}
+
+	return nil
+}
+
+// workloadTarget is a Deployment or StatefulSet matching a scale target
+type workloadTarget struct {
+	kind      string
+	namespace string
+	// annotated is true if the workload had hpa-operator annotations
+	annotated bool
+}
+
+// removeWorkloadHpaAnnotations removes the hpa-operator annotations of the Deployments and StatefulSets matching the scale target,
+// and returns the matching workloads
+func removeWorkloadHpaAnnotations(client *kubernetes.Clientset, scaleTarget scaleTargetRef) ([]workloadTarget, error) {
+	var workloads []workloadTarget
+
 	// find deployment & update hpa annotations
 	// get doesn't work with metav1.NamespaceAll only if you specify the namespace exactly
 	// deployment, err := client.AppsV1().Deployments(metav1.NamespaceAll).Get(request.Name, metav1.GetOptions{})
-	scaleTargetFound := false
 	listOptions := metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%v", scaleTarget),
-	}
-	deploymentList, err := client.AppsV1().Deployments(metav1.NamespaceAll).List(listOptions)
-	if err != nil {
-		return err
+		FieldSelector: fmt.Sprintf("metadata.name=%v", scaleTarget.name),
 	}
-	for _, dep := range deploymentList.Items {
-		if dep.Name == scaleTarget {
-			scaleTargetFound = true
-			log.Debugf("remove annotations on deployment: %v", dep.Name)
-			dep.Annotations = removeHpaAnnotations(dep.Annotations)
-			_, err = client.AppsV1().Deployments(dep.Namespace).Update(&dep)
-			if err != nil {
-				return err
+	if scaleTarget.kind != hpa.StatefulSetKind {
+		deploymentList, err := client.AppsV1().Deployments(scaleTarget.listNamespace()).List(listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range deploymentList.Items {
+			if dep.Name == scaleTarget.name {
+				annotations := removeHpaAnnotations(dep.Annotations)
+				workloads = append(workloads, workloadTarget{
+					kind:      hpa.DeploymentKind,
+					namespace: dep.Namespace,
+					annotated: len(annotations) != len(dep.Annotations),
+				})
+				log.Debugf("remove annotations on deployment: %v", dep.Name)
+				dep.Annotations = annotations
+				_, err = client.AppsV1().Deployments(dep.Namespace).Update(&dep)
+				if err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
 
 	// find statefulset & update hpa annotations
-	statefulSetList, err := client.AppsV1().StatefulSets(metav1.NamespaceAll).List(listOptions)
+	if scaleTarget.kind != hpa.DeploymentKind {
+		statefulSetList, err := client.AppsV1().StatefulSets(scaleTarget.listNamespace()).List(listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, stsset := range statefulSetList.Items {
+			if stsset.Name == scaleTarget.name {
+				annotations := removeHpaAnnotations(stsset.Annotations)
+				workloads = append(workloads, workloadTarget{
+					kind:      hpa.StatefulSetKind,
+					namespace: stsset.Namespace,
+					annotated: len(annotations) != len(stsset.Annotations),
+				})
+				log.Debugf("remove annotations on statefulset: %v", stsset.Name)
+				stsset.Annotations = annotations
+				_, err = client.AppsV1().StatefulSets(stsset.Namespace).Update(&stsset)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return workloads, nil
+}
+
+func deleteScaleTargetAutoscalingInfo(kubeConfig []byte, scaleTarget scaleTargetRef) error {
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
 	if err != nil {
+		log.Errorf("Getting K8s client failed: %s", err.Error())
 		return err
 	}
-	for _, stsset := range statefulSetList.Items {
-		if stsset.Name == scaleTarget {
-			scaleTargetFound = true
-			log.Debugf("remove annotations on statefulset: %v", stsset.Name)
-			stsset.Annotations = removeHpaAnnotations(stsset.Annotations)
-			_, err = client.AppsV1().StatefulSets(stsset.Namespace).Update(&stsset)
-			if err != nil {
-				return err
-			}
-		}
+
+	deleted, err := deleteManagedHorizontalPodAutoscalers(client, scaleTarget)
+	if err != nil {
+		return err
 	}
 
-	if !scaleTargetFound {
-		return &scaleTargetNotFoundError{scaleTargetRef: scaleTarget}
+	if !deleted {
+		return &scaleTargetNotFoundError{scaleTargetRef: scaleTarget.name}
 	}
 
 	return nil
 }
 
+// deleteManagedHorizontalPodAutoscalers deletes the Horizontal Pod Autoscalers created directly by Pipeline for the scale target,
+// and returns true if any was found
+func deleteManagedHorizontalPodAutoscalers(client *kubernetes.Clientset, scaleTarget scaleTargetRef) (bool, error) {
+	listOptions := metav1.ListOptions{
+		LabelSelector: labels.Set{hpaManagedByLabel: hpaManagedByPipeline}.String(),
+	}
+	hpaList, err := client.AutoscalingV2beta1().HorizontalPodAutoscalers(scaleTarget.listNamespace()).List(listOptions)
+	if err != nil {
+		return false, err
+	}
+
+	scaleTargetFound := false
+	for _, hpaItem := range hpaList.Items {
+		if hpaItem.Name != scaleTarget.name || !hpaBelongsToScaleTarget(hpaItem, scaleTarget) {
+			continue
+		}
+
+		// an empty kind only refers to Deployments and StatefulSets
+		if hpa.IsWorkloadTarget(scaleTarget.kind) != hpa.IsWorkloadTarget(hpaItem.Spec.ScaleTargetRef.Kind) {
+			continue
+		}
+
+		scaleTargetFound = true
+		log.Debugf("delete hpa: %v", hpaItem.Name)
+		err = client.AutoscalingV2beta1().HorizontalPodAutoscalers(hpaItem.Namespace).Delete(hpaItem.Name, &metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	return scaleTargetFound, nil
+}
+
 func setDeploymentAutoscalingInfo(client *kubernetes.Clientset, request hpa.DeploymentScalingRequest) error {
+	scaleTarget := scaleTargetRef{name: request.ScaleTarget, kind: request.Kind, namespace: request.Namespace}
+
+	// hpa-operator takes over from the Horizontal Pod Autoscaler created directly by Pipeline before
+	_, err := deleteManagedHorizontalPodAutoscalers(client, scaleTarget)
+	if err != nil {
+		return err
+	}
+
 	// find deployment & update hpa annotations
 	// get doesn't work with metav1.NamespaceAll only if you specify the namespace exactly
 	//deployment, err := client.AppsV1().Deployments(metav1.NamespaceAll).Get(request.Name, metav1.GetOptions{})
@@ -472,43 +751,90 @@ func setDeploymentAutoscalingInfo(client *kubernetes.Clientset, request hpa.Depl
 	listOptions := metav1.ListOptions{
 		FieldSelector: fmt.Sprintf("metadata.name=%v", request.ScaleTarget),
 	}
-	deploymentList, err := client.AppsV1().Deployments(metav1.NamespaceAll).List(listOptions)
-	if err != nil {
-		return err
-	}
-	for _, dep := range deploymentList.Items {
-		if dep.Name == request.ScaleTarget {
-			scaleTargetFound = true
-			log.Debugf("set annotations on deployment: %v", dep.Name)
-			dep.Annotations = removeHpaAnnotations(dep.Annotations)
-			setupHpaAnnotations(request, dep.Annotations)
-			_, err = client.AppsV1().Deployments(dep.Namespace).Update(&dep)
-			if err != nil {
-				return err
+	if request.Kind != hpa.StatefulSetKind {
+		deploymentList, err := client.AppsV1().Deployments(scaleTarget.listNamespace()).List(listOptions)
+		if err != nil {
+			return err
+		}
+		for _, dep := range deploymentList.Items {
+			if dep.Name == request.ScaleTarget {
+				scaleTargetFound = true
+				log.Debugf("set annotations on deployment: %v", dep.Name)
+				dep.Annotations = removeHpaAnnotations(dep.Annotations)
+				setupHpaAnnotations(request, dep.Annotations)
+				_, err = client.AppsV1().Deployments(dep.Namespace).Update(&dep)
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
 
 	// find statefulset & update hpa annotations
-	statefulSetList, err := client.AppsV1().StatefulSets(metav1.NamespaceAll).List(listOptions)
+	if request.Kind != hpa.DeploymentKind {
+		statefulSetList, err := client.AppsV1().StatefulSets(scaleTarget.listNamespace()).List(listOptions)
+		if err != nil {
+			return err
+		}
+		for _, stsset := range statefulSetList.Items {
+			if stsset.Name == request.ScaleTarget {
+				scaleTargetFound = true
+				log.Debugf("set annotations on statefulset: %v", stsset.Name)
+				stsset.Annotations = removeHpaAnnotations(stsset.Annotations)
+				setupHpaAnnotations(request, stsset.Annotations)
+				_, err = client.AppsV1().StatefulSets(stsset.Namespace).Update(&stsset)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !scaleTargetFound {
+		return &scaleTargetNotFoundError{scaleTargetRef: request.ScaleTarget}
+	}
+
+	return nil
+}
+
+// setWorkloadAutoscalingInfo creates/updates a Horizontal Pod Autoscaler for Deployments and StatefulSets directly,
+// as hpa-operator annotations can't describe pods, object and external metrics or a scale behavior
+func setWorkloadAutoscalingInfo(client *kubernetes.Clientset, request hpa.DeploymentScalingRequest) error {
+	workloads, err := removeWorkloadHpaAnnotations(client, scaleTargetRef{name: request.ScaleTarget, kind: request.Kind, namespace: request.Namespace})
 	if err != nil {
 		return err
 	}
-	for _, stsset := range statefulSetList.Items {
-		if stsset.Name == request.ScaleTarget {
-			scaleTargetFound = true
-			log.Debugf("set annotations on statefulset: %v", stsset.Name)
-			stsset.Annotations = removeHpaAnnotations(stsset.Annotations)
-			setupHpaAnnotations(request, stsset.Annotations)
-			_, err = client.AppsV1().StatefulSets(stsset.Namespace).Update(&stsset)
-			if err != nil {
+
+	if len(workloads) == 0 {
+		return &scaleTargetNotFoundError{scaleTargetRef: request.ScaleTarget}
+	}
+
+	for _, workload := range workloads {
+		if workload.annotated {
+			// replace the Horizontal Pod Autoscaler created by hpa-operator
+			hpaClient := client.AutoscalingV2beta1().HorizontalPodAutoscalers(workload.namespace)
+			current, err := hpaClient.Get(request.ScaleTarget, metav1.GetOptions{})
+			if err != nil && !k8sErrors.IsNotFound(err) {
 				return err
 			}
+			if err == nil && current.Labels[hpaManagedByLabel] != hpaManagedByPipeline {
+				log.Debugf("delete hpa created by hpa-operator: %v", current.Name)
+				err = hpaClient.Delete(current.Name, &metav1.DeleteOptions{})
+				if err != nil && !k8sErrors.IsNotFound(err) {
+					return err
+				}
+			}
 		}
-	}
 
-	if !scaleTargetFound {
-		return &scaleTargetNotFoundError{scaleTargetRef: request.ScaleTarget}
+		workloadRequest := request
+		workloadRequest.Kind = workload.kind
+		workloadRequest.APIVersion = "apps/v1"
+		workloadRequest.Namespace = workload.namespace
+
+		err = setScaleTargetAutoscalingInfo(client, workloadRequest)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -559,3 +885,230 @@ func setupCustomMetricAnnotation(annotations map[string]string, customMetricName
 	}
 	annotations[fmt.Sprintf("prometheus.%v.%v/query", customMetricName, hpaAnnotationPrefix)] = customMetric.Query
 }
+
+// setScaleTargetAutoscalingInfo creates/updates a Horizontal Pod Autoscaler for scale targets not handled by hpa-operator,
+// these can be any resources implementing the scale subresource
+func setScaleTargetAutoscalingInfo(client *kubernetes.Clientset, request hpa.DeploymentScalingRequest) error {
+	err := checkScaleTarget(client, request)
+	if err != nil {
+		return err
+	}
+
+	hpaItem, err := newHorizontalPodAutoscaler(request)
+	if err != nil {
+		return err
+	}
+
+	hpaClient := client.AutoscalingV2beta1().HorizontalPodAutoscalers(request.Namespace)
+	current, err := hpaClient.Get(request.ScaleTarget, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		log.Debugf("create hpa: %v", hpaItem.Name)
+		_, err = hpaClient.Create(hpaItem)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	if current.Labels[hpaManagedByLabel] != hpaManagedByPipeline {
+		return errors.Errorf("Horizontal Pod Autoscaler %s already exists and is not managed by Pipeline", current.Name)
+	}
+
+	log.Debugf("update hpa: %v", hpaItem.Name)
+	hpaItem.ResourceVersion = current.ResourceVersion
+	_, err = hpaClient.Update(hpaItem)
+	return err
+}
+
+// checkScaleTarget checks whether the scale target kind implements the scale subresource and the scale target exists
+func checkScaleTarget(client *kubernetes.Clientset, request hpa.DeploymentScalingRequest) error {
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(request.APIVersion)
+	if err != nil {
+		return emperror.Wrapf(err, "failed to discover resources of %s", request.APIVersion)
+	}
+
+	resourceName := ""
+	for _, r := range resources.APIResources {
+		if r.Kind == request.Kind && !strings.Contains(r.Name, "/") {
+			resourceName = r.Name
+			break
+		}
+	}
+	if resourceName == "" {
+		return errors.Errorf("kind %s not found in %s", request.Kind, request.APIVersion)
+	}
+
+	scalable := false
+	for _, r := range resources.APIResources {
+		if r.Name == resourceName+"/scale" {
+			scalable = true
+			break
+		}
+	}
+	if !scalable {
+		return errors.Errorf("kind %s does not implement the scale subresource", request.Kind)
+	}
+
+	groupVersion, err := schema.ParseGroupVersion(request.APIVersion)
+	if err != nil {
+		return err
+	}
+	apiPath := "/apis"
+	if groupVersion.Group == "" {
+		apiPath = "/api"
+	}
+
+	err = client.AutoscalingV1().RESTClient().Get().
+		AbsPath(apiPath, request.APIVersion, "namespaces", request.Namespace, resourceName, request.ScaleTarget, "scale").
+		Do().
+		Error()
+	if k8sErrors.IsNotFound(err) {
+		return &scaleTargetNotFoundError{scaleTargetRef: request.ScaleTarget}
+	}
+
+	return err
+}
+
+func newHorizontalPodAutoscaler(request hpa.DeploymentScalingRequest) (*v2beta1.HorizontalPodAutoscaler, error) {
+	hpaItem := &v2beta1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        request.ScaleTarget,
+			Namespace:   request.Namespace,
+			Labels:      map[string]string{hpaManagedByLabel: hpaManagedByPipeline},
+			Annotations: map[string]string{},
+		},
+		Spec: v2beta1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: v2beta1.CrossVersionObjectReference{
+				Kind:       request.Kind,
+				Name:       request.ScaleTarget,
+				APIVersion: request.APIVersion,
+			},
+			MinReplicas: &request.MinReplicas,
+			MaxReplicas: request.MaxReplicas,
+		},
+	}
+
+	for _, r := range []struct {
+		name   v1.ResourceName
+		metric hpa.ResourceMetric
+	}{{v1.ResourceCPU, request.Cpu}, {v1.ResourceMemory, request.Memory}} {
+		if len(r.metric.TargetAverageValue) == 0 {
+			continue
+		}
+		source := &v2beta1.ResourceMetricSource{Name: r.name}
+		switch r.metric.TargetAverageValueType {
+		case hpa.PercentageValueType:
+			value, err := strconv.ParseInt(r.metric.TargetAverageValue, 10, 32)
+			if err != nil {
+				return nil, emperror.Wrapf(err, "invalid %s target", r.name)
+			}
+			utilization := int32(value)
+			source.TargetAverageUtilization = &utilization
+		case hpa.QuantityValueType:
+			value, err := resource.ParseQuantity(r.metric.TargetAverageValue)
+			if err != nil {
+				return nil, emperror.Wrapf(err, "invalid %s target", r.name)
+			}
+			source.TargetAverageValue = &value
+		default:
+			continue
+		}
+		hpaItem.Spec.Metrics = append(hpaItem.Spec.Metrics, v2beta1.MetricSpec{
+			Type:     v2beta1.ResourceMetricSourceType,
+			Resource: source,
+		})
+	}
+
+	for name, customMetric := range request.CustomMetrics {
+		targetValue := customMetric.TargetValue
+		if len(targetValue) == 0 {
+			targetValue = customMetric.TargetAverageValue
+			hpaItem.Annotations[fmt.Sprintf("metric-config.object.%s.prometheus/per-replica", name)] = "true"
+		}
+		value, err := resource.ParseQuantity(targetValue)
+		if err != nil {
+			return nil, emperror.Wrapf(err, "invalid custom metric %s target", name)
+		}
+		hpaItem.Annotations[fmt.Sprintf("metric-config.object.%s.prometheus/query", name)] = customMetric.Query
+		hpaItem.Spec.Metrics = append(hpaItem.Spec.Metrics, v2beta1.MetricSpec{
+			Type: v2beta1.ObjectMetricSourceType,
+			Object: &v2beta1.ObjectMetricSource{
+				Target: v2beta1.CrossVersionObjectReference{
+					Kind:       "Pod",
+					Name:       request.ScaleTarget,
+					APIVersion: "v1",
+				},
+				MetricName:  name,
+				TargetValue: value,
+			},
+		})
+	}
+
+	for name, podsMetric := range request.PodsMetrics {
+		value, err := resource.ParseQuantity(podsMetric.TargetAverageValue)
+		if err != nil {
+			return nil, emperror.Wrapf(err, "invalid pods metric %s target", name)
+		}
+		hpaItem.Spec.Metrics = append(hpaItem.Spec.Metrics, v2beta1.MetricSpec{
+			Type: v2beta1.PodsMetricSourceType,
+			Pods: &v2beta1.PodsMetricSource{
+				MetricName:         podsMetric.MetricName,
+				TargetAverageValue: value,
+			},
+		})
+	}
+
+	for name, objectMetric := range request.ObjectMetrics {
+		value, err := resource.ParseQuantity(objectMetric.TargetValue)
+		if err != nil {
+			return nil, emperror.Wrapf(err, "invalid object metric %s target", name)
+		}
+		hpaItem.Spec.Metrics = append(hpaItem.Spec.Metrics, v2beta1.MetricSpec{
+			Type: v2beta1.ObjectMetricSourceType,
+			Object: &v2beta1.ObjectMetricSource{
+				Target: v2beta1.CrossVersionObjectReference{
+					Kind:       objectMetric.Target.Kind,
+					Name:       objectMetric.Target.Name,
+					APIVersion: objectMetric.Target.APIVersion,
+				},
+				MetricName:  objectMetric.MetricName,
+				TargetValue: value,
+			},
+		})
+	}
+
+	for name, externalMetric := range request.ExternalMetrics {
+		source := &v2beta1.ExternalMetricSource{
+			MetricName: externalMetric.MetricName,
+		}
+		if len(externalMetric.Selector) > 0 {
+			source.MetricSelector = &metav1.LabelSelector{MatchLabels: externalMetric.Selector}
+		}
+		if len(externalMetric.TargetValue) > 0 {
+			value, err := resource.ParseQuantity(externalMetric.TargetValue)
+			if err != nil {
+				return nil, emperror.Wrapf(err, "invalid external metric %s target", name)
+			}
+			source.TargetValue = &value
+		} else {
+			value, err := resource.ParseQuantity(externalMetric.TargetAverageValue)
+			if err != nil {
+				return nil, emperror.Wrapf(err, "invalid external metric %s target", name)
+			}
+			source.TargetAverageValue = &value
+		}
+		hpaItem.Spec.Metrics = append(hpaItem.Spec.Metrics, v2beta1.MetricSpec{
+			Type:     v2beta1.ExternalMetricSourceType,
+			External: source,
+		})
+	}
+
+	if request.Behavior != nil {
+		behavior, err := json.Marshal(request.Behavior)
+		if err != nil {
+			return nil, emperror.Wrap(err, "failed to marshal scale behavior")
+		}
+		hpaItem.Annotations[hpaBehaviorAnnotation] = string(behavior)
+	}
+
+	return hpaItem, nil
+}
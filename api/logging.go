@@ -0,0 +1,130 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// LoggingAPI implements the log flow management API actions.
+type LoggingAPI struct {
+	clusterGetter common.ClusterGetter
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewLoggingAPI returns a new LoggingAPI instance.
+func NewLoggingAPI(
+	clusterGetter common.ClusterGetter,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *LoggingAPI {
+	return &LoggingAPI{
+		clusterGetter: clusterGetter,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// ListFlows returns the log flows of a cluster managed by Pipeline.
+func (a *LoggingAPI) ListFlows(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	flows, err := cluster.ListLoggingFlows(commonCluster)
+	if err != nil {
+		a.sendBackLoggingErrorResponse(c, err, "error listing logging flows")
+		return
+	}
+
+	c.JSON(http.StatusOK, flows)
+}
+
+// ApplyFlow creates or updates a log flow of a cluster.
+func (a *LoggingAPI) ApplyFlow(c *gin.Context) {
+	var flow pkgCluster.LoggingFlow
+	if err := c.ShouldBindJSON(&flow); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	err := cluster.ApplyLoggingFlow(commonCluster, flow)
+	if err != nil {
+		a.sendBackLoggingErrorResponse(c, err, "error applying logging flow")
+		return
+	}
+
+	c.JSON(http.StatusOK, flow)
+}
+
+// DeleteFlow deletes a log flow of a cluster.
+func (a *LoggingAPI) DeleteFlow(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	err := cluster.DeleteLoggingFlow(commonCluster, c.Param("name"))
+	if err != nil {
+		a.sendBackLoggingErrorResponse(c, err, "error deleting logging flow")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (a *LoggingAPI) sendBackLoggingErrorResponse(c *gin.Context, err error, message string) {
+	code := http.StatusInternalServerError
+
+	if errors.Cause(err) == pkgErrors.ErrorLoggingNotInstalled || isInvalid(err) {
+		code = http.StatusBadRequest
+		message = err.Error()
+	} else if k8sapierrors.IsNotFound(errors.Cause(err)) {
+		code = http.StatusNotFound
+		message = "logging flow not found"
+	} else {
+		a.errorHandler.Handle(err)
+	}
+
+	c.JSON(code, pkgCommon.ErrorResponse{
+		Code:    code,
+		Message: message,
+		Error:   err.Error(),
+	})
+}
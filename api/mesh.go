@@ -0,0 +1,216 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/internal/servicemesh"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/sirupsen/logrus"
+)
+
+// AddMeshMemberRequest describes a cluster to be joined to a mesh.
+type AddMeshMemberRequest struct {
+	ClusterID uint `json:"clusterId" binding:"required"`
+}
+
+// MeshAPI implements the multi-cluster service mesh API actions.
+type MeshAPI struct {
+	meshManager *servicemesh.Manager
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewMeshAPI returns a new MeshAPI instance.
+func NewMeshAPI(
+	meshManager *servicemesh.Manager,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *MeshAPI {
+	return &MeshAPI{
+		meshManager: meshManager,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// ListMeshes lists the multi-cluster service meshes of an organization.
+func (a *MeshAPI) ListMeshes(c *gin.Context) {
+	organization := auth.GetCurrentOrganization(c.Request)
+
+	meshes, err := a.meshManager.ListMeshes(organization.ID)
+	if err != nil {
+		a.sendBackMeshErrorResponse(c, err, "error listing meshes")
+		return
+	}
+
+	c.JSON(http.StatusOK, meshes)
+}
+
+// CreateMesh creates a multi-cluster service mesh and starts joining its clusters.
+func (a *MeshAPI) CreateMesh(c *gin.Context) {
+	var request servicemesh.CreateMeshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	organization := auth.GetCurrentOrganization(c.Request)
+
+	mesh, err := a.meshManager.CreateMesh(c.Request.Context(), organization.ID, request)
+	if err != nil {
+		a.sendBackMeshErrorResponse(c, err, "error creating mesh")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, mesh)
+}
+
+// GetMeshTopology returns the member clusters of a mesh along with their health.
+func (a *MeshAPI) GetMeshTopology(c *gin.Context) {
+	meshID, ok := a.getMeshID(c)
+	if !ok {
+		return
+	}
+
+	organization := auth.GetCurrentOrganization(c.Request)
+
+	mesh, err := a.meshManager.GetTopology(c.Request.Context(), organization.ID, meshID)
+	if err != nil {
+		a.sendBackMeshErrorResponse(c, err, "error getting mesh topology")
+		return
+	}
+
+	c.JSON(http.StatusOK, mesh)
+}
+
+// DeleteMesh deletes a mesh without remote clusters.
+func (a *MeshAPI) DeleteMesh(c *gin.Context) {
+	meshID, ok := a.getMeshID(c)
+	if !ok {
+		return
+	}
+
+	organization := auth.GetCurrentOrganization(c.Request)
+
+	err := a.meshManager.DeleteMesh(c.Request.Context(), organization.ID, meshID)
+	if err != nil {
+		a.sendBackMeshErrorResponse(c, err, "error deleting mesh")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddMember joins a remote cluster to a mesh.
+func (a *MeshAPI) AddMember(c *gin.Context) {
+	meshID, ok := a.getMeshID(c)
+	if !ok {
+		return
+	}
+
+	var request AddMeshMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	organization := auth.GetCurrentOrganization(c.Request)
+
+	mesh, err := a.meshManager.AddMember(c.Request.Context(), organization.ID, meshID, request.ClusterID)
+	if err != nil {
+		a.sendBackMeshErrorResponse(c, err, "error adding cluster to mesh")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, mesh)
+}
+
+// RemoveMember removes a remote cluster from a mesh.
+func (a *MeshAPI) RemoveMember(c *gin.Context) {
+	meshID, ok := a.getMeshID(c)
+	if !ok {
+		return
+	}
+
+	clusterID, err := strconv.ParseUint(c.Param("clusterid"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid cluster ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	organization := auth.GetCurrentOrganization(c.Request)
+
+	err = a.meshManager.RemoveMember(c.Request.Context(), organization.ID, meshID, uint(clusterID))
+	if err != nil {
+		a.sendBackMeshErrorResponse(c, err, "error removing cluster from mesh")
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+func (a *MeshAPI) getMeshID(c *gin.Context) (uint, bool) {
+	meshID, err := strconv.ParseUint(c.Param("meshid"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid mesh ID",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+
+	return uint(meshID), true
+}
+
+func (a *MeshAPI) sendBackMeshErrorResponse(c *gin.Context, err error, message string) {
+	code := http.StatusInternalServerError
+
+	if isInvalid(err) {
+		code = http.StatusBadRequest
+		message = err.Error()
+	} else if isNotFound(err) {
+		code = http.StatusNotFound
+		message = err.Error()
+	} else {
+		a.errorHandler.Handle(err)
+	}
+
+	c.JSON(code, pkgCommon.ErrorResponse{
+		Code:    code,
+		Message: message,
+		Error:   err.Error(),
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/recommender"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/sirupsen/logrus"
+)
+
+// NodePoolRecommendationAPI implements the node pool recommendation API actions.
+type NodePoolRecommendationAPI struct {
+	recommender *recommender.Recommender
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewNodePoolRecommendationAPI returns a new NodePoolRecommendationAPI instance.
+func NewNodePoolRecommendationAPI(
+	recommender *recommender.Recommender,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *NodePoolRecommendationAPI {
+	return &NodePoolRecommendationAPI{
+		recommender: recommender,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// RecommendNodePools returns node pool layouts covering the requested resources ranked by price.
+func (a *NodePoolRecommendationAPI) RecommendNodePools(c *gin.Context) {
+	var request recommender.Request
+	if err := c.BindJSON(&request); err != nil {
+		a.logger.Errorf("Error parsing request: %s", err.Error())
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	response, err := a.recommender.Recommend(request)
+	if err != nil {
+		a.errorHandler.Handle(err)
+
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Error recommending node pools",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,178 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/cluster"
+	"github.com/banzaicloud/pipeline/internal/istio"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ServiceMeshAPI implements the service mesh management API actions.
+type ServiceMeshAPI struct {
+	clusterGetter common.ClusterGetter
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewServiceMeshAPI returns a new ServiceMeshAPI instance.
+func NewServiceMeshAPI(
+	clusterGetter common.ClusterGetter,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *ServiceMeshAPI {
+	return &ServiceMeshAPI{
+		clusterGetter: clusterGetter,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// GetServiceMesh returns the current settings of the service mesh of a cluster.
+func (a *ServiceMeshAPI) GetServiceMesh(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	status, err := cluster.GetServiceMeshStatus(commonCluster)
+	if err != nil {
+		a.sendBackServiceMeshErrorResponse(c, err, "error getting service mesh status")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// UpdateServiceMesh changes the mTLS setting, the sidecar injected namespaces or the chart version of the service mesh of a cluster.
+func (a *ServiceMeshAPI) UpdateServiceMesh(c *gin.Context) {
+	var params cluster.UpdateServiceMeshParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid service mesh settings",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	err := cluster.UpdateServiceMesh(commonCluster, params)
+	if err != nil {
+		a.sendBackServiceMeshErrorResponse(c, err, "error updating service mesh")
+		return
+	}
+
+	status, err := cluster.GetServiceMeshStatus(commonCluster)
+	if err != nil {
+		a.sendBackServiceMeshErrorResponse(c, err, "error getting service mesh status")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// ListCanaries returns the traffic shifting settings of the services in a namespace.
+func (a *ServiceMeshAPI) ListCanaries(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	namespace := c.DefaultQuery("namespace", "default")
+
+	canaries, err := cluster.ListServiceMeshCanaries(commonCluster, namespace)
+	if err != nil {
+		a.sendBackServiceMeshErrorResponse(c, err, "error listing canaries")
+		return
+	}
+
+	c.JSON(http.StatusOK, canaries)
+}
+
+// ApplyCanary shifts the traffic of a service between its versions through VirtualService weights.
+func (a *ServiceMeshAPI) ApplyCanary(c *gin.Context) {
+	var canary istio.Canary
+	if err := c.ShouldBindJSON(&canary); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := canary.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid canary settings",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	err := cluster.ApplyServiceMeshCanary(commonCluster, canary)
+	if err != nil {
+		a.sendBackServiceMeshErrorResponse(c, err, "error applying canary")
+		return
+	}
+
+	c.JSON(http.StatusOK, canary)
+}
+
+func (a *ServiceMeshAPI) sendBackServiceMeshErrorResponse(c *gin.Context, err error, message string) {
+	code := http.StatusInternalServerError
+
+	if errors.Cause(err) == pkgErrors.ErrorServiceMeshNotInstalled {
+		code = http.StatusBadRequest
+		message = err.Error()
+	} else {
+		a.errorHandler.Handle(err)
+	}
+
+	c.JSON(code, pkgCommon.ErrorResponse{
+		Code:    code,
+		Message: message,
+		Error:   err.Error(),
+	})
+}
@@ -0,0 +1,124 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/internal/cluster/spotinterruption"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultInterruptionPeriod = 7 * 24 * time.Hour
+
+// SpotInterruptionAPI implements the spot interruption report API actions.
+type SpotInterruptionAPI struct {
+	clusterGetter common.ClusterGetter
+	store         *spotinterruption.Store
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewSpotInterruptionAPI returns a new SpotInterruptionAPI instance.
+func NewSpotInterruptionAPI(
+	clusterGetter common.ClusterGetter,
+	store *spotinterruption.Store,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *SpotInterruptionAPI {
+	return &SpotInterruptionAPI{
+		clusterGetter: clusterGetter,
+		store:         store,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// GetClusterInterruptionsQueryParams describes the query params of a cluster interruptions request.
+type GetClusterInterruptionsQueryParams struct {
+	// RFC3339 timestamp, defaults to 7 days before to
+	From string `form:"from"`
+	// RFC3339 timestamp, defaults to now
+	To string `form:"to"`
+}
+
+// GetClusterInterruptions returns the spot interruption report of a cluster.
+func (a *SpotInterruptionAPI) GetClusterInterruptions(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	var params GetClusterInterruptionsQueryParams
+	if err := c.BindQuery(&params); err != nil {
+		return
+	}
+
+	from, to, err := parseInterruptionPeriod(params, time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid interruption period",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	interruptions, err := a.store.Find(commonCluster.GetID(), from, to)
+	if err != nil {
+		a.errorHandler.Handle(err)
+
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error fetching spot interruptions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, spotinterruption.NewReport(commonCluster.GetID(), interruptions, from, to))
+}
+
+func parseInterruptionPeriod(params GetClusterInterruptionsQueryParams, now time.Time) (from time.Time, to time.Time, err error) {
+	to = now
+	if params.To != "" {
+		to, err = time.Parse(time.RFC3339, params.To)
+		if err != nil {
+			return from, to, errors.Wrap(err, "invalid to")
+		}
+	}
+
+	from = to.Add(-defaultInterruptionPeriod)
+	if params.From != "" {
+		from, err = time.Parse(time.RFC3339, params.From)
+		if err != nil {
+			return from, to, errors.Wrap(err, "invalid from")
+		}
+	}
+
+	if !from.Before(to) {
+		return from, to, errors.New("from must be before to")
+	}
+
+	return from, to, nil
+}
@@ -17,6 +17,7 @@ package api
 import (
 	"net/http"
 	"path"
+	"strconv"
 
 	"github.com/banzaicloud/pipeline/auth"
 	"github.com/banzaicloud/pipeline/internal/platform/gin/correlationid"
@@ -140,6 +141,12 @@ func (s *SpotguideAPI) SyncSpotguides(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+// LaunchSpotguideValidationResponse describes the invalid fields of a launch request.
+type LaunchSpotguideValidationResponse struct {
+	pkgCommon.ErrorResponse
+	Fields []spotguide.FieldError `json:"fields"`
+}
+
 // LaunchSpotguide creates a spotguide workflow, all secrets, repositories.
 func (s *SpotguideAPI) LaunchSpotguide(c *gin.Context) {
 	log := correlationid.Logger(log, c)
@@ -157,8 +164,25 @@ func (s *SpotguideAPI) LaunchSpotguide(c *gin.Context) {
 	org := auth.GetCurrentOrganization(c.Request)
 	user := auth.GetCurrentUser(c.Request)
 
-	err := s.spotguide.LaunchSpotguide(&launchRequest, org, user)
-	if err != nil {
+	launch, err := s.spotguide.LaunchSpotguide(&launchRequest, org, user)
+	if validationErr, ok := errors.Cause(err).(*spotguide.LaunchValidationError); ok {
+		c.JSON(http.StatusBadRequest, LaunchSpotguideValidationResponse{
+			ErrorResponse: pkgCommon.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid launch request",
+				Error:   err.Error(),
+			},
+			Fields: validationErr.Fields,
+		})
+		return
+	} else if isInvalid(err) {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid launch request",
+			Error:   err.Error(),
+		})
+		return
+	} else if err != nil {
 		log.Errorf("failed to Launch spotguide %s: %s", launchRequest.RepoFullname(), err.Error())
 		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -168,7 +192,259 @@ func (s *SpotguideAPI) LaunchSpotguide(c *gin.Context) {
 		return
 	}
 
-	c.Status(http.StatusAccepted)
+	if launch.DryRun {
+		c.JSON(http.StatusOK, launch)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, launch)
+}
+
+// GetSpotguideLaunch returns the progress of a spotguide launch.
+func (s *SpotguideAPI) GetSpotguideLaunch(c *gin.Context) {
+	log := correlationid.Logger(log, c)
+
+	orgID := auth.GetCurrentOrganization(c.Request).ID
+
+	launchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid launch id",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	launch, err := s.spotguide.GetLaunch(orgID, uint(launchID))
+	if err != nil {
+		if gorm.IsRecordNotFoundError(errors.Cause(err)) {
+			c.JSON(http.StatusNotFound, pkgCommon.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "spotguide launch not found",
+			})
+			return
+		}
+		log.Errorln("error getting spotguide launch:", err.Error())
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error getting spotguide launch",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, launch)
+}
+
+// UpgradeSpotguide proposes the changes of a newer spotguide release to a launched repository in a pull request.
+func (s *SpotguideAPI) UpgradeSpotguide(c *gin.Context) {
+	log := correlationid.Logger(log, c)
+
+	launchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid launch id",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var upgradeRequest spotguide.UpgradeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&upgradeRequest); err != nil {
+			c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "error parsing request",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	orgID := auth.GetCurrentOrganization(c.Request).ID
+	user := auth.GetCurrentUser(c.Request)
+
+	upgrade, err := s.spotguide.UpgradeSpotguide(orgID, uint(launchID), &upgradeRequest, user)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(errors.Cause(err)) {
+			c.JSON(http.StatusNotFound, pkgCommon.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "spotguide launch or version not found",
+			})
+			return
+		}
+		if isInvalid(err) {
+			c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "spotguide can't be upgraded",
+				Error:   err.Error(),
+			})
+			return
+		}
+		log.Errorf("failed to upgrade spotguide launch %d: %s", launchID, err.Error())
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error upgrading spotguide",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, upgrade)
+}
+
+// ListSpotguideCatalogs lists the spotguide catalogs registered by the organization.
+func (s *SpotguideAPI) ListSpotguideCatalogs(c *gin.Context) {
+	log := correlationid.Logger(log, c)
+
+	orgID := auth.GetCurrentOrganization(c.Request).ID
+
+	catalogs, err := s.spotguide.ListCatalogs(orgID)
+	if err != nil {
+		log.Errorln("error listing spotguide catalogs:", err.Error())
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error listing spotguide catalogs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, catalogs)
+}
+
+// CreateSpotguideCatalog registers a spotguide catalog, its spotguides are scraped on the next synchronization.
+func (s *SpotguideAPI) CreateSpotguideCatalog(c *gin.Context) {
+	log := correlationid.Logger(log, c)
+
+	var catalog spotguide.SpotguideCatalog
+	if err := c.BindJSON(&catalog); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	orgID := auth.GetCurrentOrganization(c.Request).ID
+
+	err := s.spotguide.CreateCatalog(orgID, &catalog)
+	if isInvalid(err) {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid spotguide catalog",
+			Error:   err.Error(),
+		})
+		return
+	} else if err != nil {
+		log.Errorln("error creating spotguide catalog:", err.Error())
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error creating spotguide catalog",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, catalog)
+}
+
+// DeleteSpotguideCatalog removes a spotguide catalog with its spotguides.
+func (s *SpotguideAPI) DeleteSpotguideCatalog(c *gin.Context) {
+	log := correlationid.Logger(log, c)
+
+	catalogID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid catalog id",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	orgID := auth.GetCurrentOrganization(c.Request).ID
+
+	err = s.spotguide.DeleteCatalog(orgID, uint(catalogID))
+	if err != nil {
+		if gorm.IsRecordNotFoundError(errors.Cause(err)) {
+			c.JSON(http.StatusNotFound, pkgCommon.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "spotguide catalog not found",
+			})
+			return
+		}
+		log.Errorln("error deleting spotguide catalog:", err.Error())
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error deleting spotguide catalog",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSpotguideSettings returns the spotguide settings of the organization.
+func (s *SpotguideAPI) GetSpotguideSettings(c *gin.Context) {
+	log := correlationid.Logger(log, c)
+
+	org := auth.GetCurrentOrganization(c.Request)
+
+	settings, err := s.spotguide.GetSettings(org)
+	if err != nil {
+		log.Errorln("error getting spotguide settings:", err.Error())
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error getting spotguide settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSpotguideSettings updates the spotguide settings of the organization, the spotguides are scraped on the next synchronization.
+func (s *SpotguideAPI) UpdateSpotguideSettings(c *gin.Context) {
+	log := correlationid.Logger(log, c)
+
+	var settings spotguide.SpotguideSettings
+	if err := c.BindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	org := auth.GetCurrentOrganization(c.Request)
+
+	err := s.spotguide.UpdateSettings(org, &settings)
+	if isInvalid(err) {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid spotguide settings",
+			Error:   err.Error(),
+		})
+		return
+	} else if err != nil {
+		log.Errorln("error updating spotguide settings:", err.Error())
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error updating spotguide settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// RegisterLaunchRoutes registers the routes of spotguide launches in an organization router.
+// Launches are served under /spotguidelaunches, since /spotguides/launches/:id would conflict with the /spotguides/:owner/:name routes.
+func (s *SpotguideAPI) RegisterLaunchRoutes(r gin.IRouter) {
+	r.GET("spotguidelaunches/:id", s.GetSpotguideLaunch)
+	r.POST("spotguidelaunches/:id/upgrade", s.UpgradeSpotguide)
 }
 
 func NewSpotguideAPI(logger logrus.FieldLogger, errorHandler emperror.Handler, spotguideManager *spotguide.SpotguideManager) *SpotguideAPI {
@@ -0,0 +1,81 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/spotguide"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpotguideAPI_GetSpotguideLaunch(t *testing.T) {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.AutoMigrate(&spotguide.SpotguideLaunch{}, &spotguide.SpotguideLaunchStep{}).Error)
+
+	launch := &spotguide.SpotguideLaunch{OrganizationID: 1, RepoFullname: "banzaicloud/spotguide-nodejs", Status: spotguide.LaunchStatusRunning}
+	require.NoError(t, db.Create(launch).Error)
+
+	spotguideAPI := NewSpotguideAPI(logrus.New(), emperror.NewNoopHandler(), spotguide.NewSpotguideManager(db, "0.0.0", "", "", nil))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	orgs := router.Group("/api/v1/orgs")
+	orgs.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), auth.CurrentOrganization, &auth.Organization{ID: 1}))
+	})
+	spotguideAPI.RegisterLaunchRoutes(orgs.Group("/:orgid"))
+
+	tests := map[string]struct {
+		launchID string
+		status   int
+	}{
+		"existing":  {launchID: fmt.Sprint(launch.ID), status: http.StatusOK},
+		"not found": {launchID: "1000", status: http.StatusNotFound},
+		"invalid":   {launchID: "launch", status: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/orgs/1/spotguidelaunches/"+test.launchID, nil)
+
+			router.ServeHTTP(recorder, request)
+
+			require.Equal(t, test.status, recorder.Code, recorder.Body.String())
+
+			if test.status == http.StatusOK {
+				var response spotguide.SpotguideLaunch
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				assert.Equal(t, launch.ID, response.ID)
+				assert.Equal(t, launch.RepoFullname, response.RepoFullname)
+			}
+		})
+	}
+}
@@ -87,12 +87,42 @@ func (a *UserAPI) GetCurrentUser(c *gin.Context) {
 		return
 	}
 
+	gitlabToken, err := auth.GetUserSCMToken(user.ID, auth.GitlabTokenID)
+
+	if err != nil {
+		message := "failed to fetch user's gitlab token"
+		a.errorHandler.Handle(emperror.Wrap(err, message))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: message,
+			Error:   message,
+		})
+		return
+	}
+
+	gitToken, err := auth.GetUserSCMToken(user.ID, auth.GitTokenID)
+
+	if err != nil {
+		message := "failed to fetch user's git token"
+		a.errorHandler.Handle(emperror.Wrap(err, message))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: message,
+			Error:   message,
+		})
+		return
+	}
+
 	var response struct {
 		*auth.User
 		GitHubTokenSet bool `json:"gitHubTokenSet"`
+		GitLabTokenSet bool `json:"gitLabTokenSet"`
+		GitTokenSet    bool `json:"gitTokenSet"`
 	}
 	response.User = user
 	response.GitHubTokenSet = (githubToken != "")
+	response.GitLabTokenSet = (gitlabToken != "")
+	response.GitTokenSet = (gitToken != "")
 
 	c.JSON(http.StatusOK, response)
 }
@@ -273,6 +303,8 @@ func (a *UserAPI) RemoveUser(c *gin.Context) {
 
 type updateUserRequest struct {
 	GitHubToken *string `json:"gitHubToken,omitempty"`
+	GitLabToken *string `json:"gitLabToken,omitempty"`
+	GitToken    *string `json:"gitToken,omitempty"`
 }
 
 // UpdateCurrentUser updates the authenticated user's settings
@@ -340,5 +372,33 @@ func (a *UserAPI) UpdateCurrentUser(c *gin.Context) {
 		}
 	}
 
+	scmTokens := map[string]*string{
+		auth.GitlabTokenID: updateUserRequest.GitLabToken,
+		auth.GitTokenID:    updateUserRequest.GitToken,
+	}
+
+	for tokenID, token := range scmTokens {
+		if token == nil {
+			continue
+		}
+
+		if *token != "" {
+			err = auth.SaveUserSCMToken(user, tokenID, *token)
+		} else {
+			err = auth.RemoveUserSCMToken(user, tokenID)
+		}
+
+		if err != nil {
+			message := fmt.Sprintf("failed to update user's %s token", tokenID)
+			a.errorHandler.Handle(emperror.Wrap(err, message))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, common.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: message,
+				Error:   message,
+			})
+			return
+		}
+	}
+
 	c.Status(http.StatusNoContent)
 }
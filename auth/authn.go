@@ -71,6 +71,8 @@ const SessionCookieName = "Pipeline session token"
 const (
 	ProviderDexGithub = "dex:github"
 	ProviderGithub    = "github"
+	ProviderDexGitlab = "dex:gitlab"
+	ProviderGitlab    = "gitlab"
 )
 
 func getBackendProvider(dexProvider string) string {
@@ -16,10 +16,8 @@ package auth
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/google/go-github/github"
-	"github.com/goph/emperror"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	"github.com/qor/auth"
@@ -50,16 +48,7 @@ func NewGithubClient(accessToken string) *github.Client {
 }
 
 func GetUserGithubToken(userID uint) (string, error) {
-	token, err := TokenStore.Lookup(fmt.Sprint(userID), GithubTokenID)
-	if err != nil {
-		return "", emperror.Wrap(err, "failed to lookup user token")
-	}
-
-	if token == nil {
-		return "", nil
-	}
-
-	return token.Value, nil
+	return GetUserSCMToken(userID, GithubTokenID)
 }
 
 func NewGithubClientForUser(userID uint) (*github.Client, error) {
@@ -0,0 +1,59 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"fmt"
+
+	bauth "github.com/banzaicloud/bank-vaults/pkg/auth"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+// GetUserSCMToken returns the user's source code management access token with the given ID (empty if not set)
+func GetUserSCMToken(userID uint, tokenID string) (string, error) {
+	token, err := TokenStore.Lookup(fmt.Sprint(userID), tokenID)
+	if err != nil {
+		return "", emperror.Wrap(err, "failed to lookup user token")
+	}
+
+	if token == nil {
+		return "", nil
+	}
+
+	return token.Value, nil
+}
+
+// SaveUserSCMToken saves a source code management access token (GitLab, plain Git) specified for a user
+func SaveUserSCMToken(user *User, tokenID string, value string) error {
+	// Revoke the old token from Vault if any
+	err := TokenStore.Revoke(user.IDString(), tokenID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to revoke old %s access token", tokenID)
+	}
+
+	token := bauth.NewToken(tokenID, fmt.Sprintf("%s access token", tokenID))
+	token.Value = value
+	err = TokenStore.Store(user.IDString(), token)
+
+	return emperror.WrapWith(err, "failed to store access token for user", "user", user.Login, "token", tokenID)
+}
+
+// RemoveUserSCMToken removes a source code management access token specified for a user
+func RemoveUserSCMToken(user *User, tokenID string) error {
+	err := TokenStore.Revoke(user.IDString(), tokenID)
+
+	return emperror.WrapWith(err, "failed to revoke access token", "user", user.Login, "token", tokenID)
+}
@@ -49,6 +49,12 @@ const (
 
 	// GithubTokenID denotes the tokenID for the user's Github token, there can be only one
 	GithubTokenID = "github"
+
+	// GitlabTokenID denotes the tokenID for the user's GitLab token, there can be only one
+	GitlabTokenID = "gitlab"
+
+	// GitTokenID denotes the tokenID for the user's plain Git server credentials, there can be only one
+	GitTokenID = "git"
 )
 
 // AuthIdentity auth identity session model
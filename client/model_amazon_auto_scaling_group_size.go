@@ -14,4 +14,6 @@ package client
 type AmazonAutoScalingGroupSize struct {
 	Min int32 `json:"min"`
 	Max int32 `json:"max"`
+	// Master node pools support 1 node, or 3 or 5 nodes spread across at least two zones for a highly available control plane
+	Desired int32 `json:"desired,omitempty"`
 }
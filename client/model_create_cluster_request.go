@@ -11,16 +11,18 @@
 
 package client
 
+// CreateClusterRequest Cloud and properties are required unless the cluster is created from a template
 type CreateClusterRequest struct {
 	Name     string `json:"name"`
-	Location string `json:"location"`
-	Cloud    string `json:"cloud"`
+	Location string `json:"location,omitempty"`
+	Cloud    string `json:"cloud,omitempty"`
 	// The lifespan of the cluster expressed in minutes after which it is automatically deleted. Zero value means the cluster is never automatically deleted.
 	TtlMinutes  int32                  `json:"ttlMinutes,omitempty"`
 	SecretId    string                 `json:"secretId,omitempty"`
 	SecretIds   []string               `json:"secretIds,omitempty"`
 	SecretName  string                 `json:"secretName,omitempty"`
 	PostHooks   map[string]interface{} `json:"postHooks,omitempty"`
+	Template    *ClusterTemplateRef    `json:"template,omitempty"`
 	ProfileName string                 `json:"profileName,omitempty"`
-	Properties  map[string]interface{} `json:"properties"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
 }
@@ -16,6 +16,7 @@ type CreateEksPropertiesEks struct {
 	NodePools map[string]NodePoolsAmazon `json:"nodePools"`
 	Vpc       EksVpc                     `json:"vpc,omitempty"`
 	// Id of the RouteTable of the VPC to be used by subnets. This is used only when subnets are created into existing VPC.
-	RouteTableId string      `json:"routeTableId,omitempty"`
-	Subnets      []EksSubnet `json:"subnets,omitempty"`
+	RouteTableId    string          `json:"routeTableId,omitempty"`
+	Subnets         []EksSubnet     `json:"subnets,omitempty"`
+	ApiServerAccess ApiServerAccess `json:"apiServerAccess,omitempty"`
 }
@@ -18,6 +18,9 @@ type CreateGkePropertiesGke struct {
 	// Name of the GCP Network (VPC) to deploy the cluster to. If omitted than the \"default\" VPC is used.
 	Vpc string `json:"vpc,omitempty"`
 	// Name of the GCP Subnet to deploy the cluster to. If \"default\" VPC is used this field can be omitted. The subnet must be in the same region as the location of the cluster.
-	Subnet    string                     `json:"subnet,omitempty"`
-	NodePools map[string]NodePoolsGoogle `json:"nodePools"`
+	Subnet          string                     `json:"subnet,omitempty"`
+	NodePools       map[string]NodePoolsGoogle `json:"nodePools"`
+	ApiServerAccess ApiServerAccess            `json:"apiServerAccess,omitempty"`
+	// The /28 IP range of the hosted master network of private clusters. Defaults to 172.16.0.0/28.
+	MasterIpv4Cidr string `json:"masterIpv4Cidr,omitempty"`
 }
@@ -12,8 +12,9 @@
 package client
 
 type CreatePkePropertiesClusterTopologyNetwork struct {
-	ApiServerAddress string `json:"apiServerAddress"`
-	ServiceCIDR      string `json:"serviceCIDR"`
-	PodCIDR          string `json:"podCIDR"`
-	Provider         string `json:"provider"`
+	ApiServerAddress string                 `json:"apiServerAddress"`
+	ServiceCIDR      string                 `json:"serviceCIDR"`
+	PodCIDR          string                 `json:"podCIDR"`
+	Provider         string                 `json:"provider"`
+	ProviderConfig   map[string]interface{} `json:"providerConfig,omitempty"`
 }
@@ -12,7 +12,8 @@
 package client
 
 type DeploymentScaleStatus struct {
-	CurrentReplicas int32  `json:"currentReplicas,omitempty"`
-	DesiredReplicas int32  `json:"desiredReplicas,omitempty"`
-	Message         string `json:"message,omitempty"`
+	CurrentReplicas int32            `json:"currentReplicas,omitempty"`
+	DesiredReplicas int32            `json:"desiredReplicas,omitempty"`
+	Message         string           `json:"message,omitempty"`
+	Conditions      []ScaleCondition `json:"conditions,omitempty"`
 }
@@ -12,10 +12,20 @@
 package client
 
 type DeploymentScalingRequest struct {
-	ScaleTarget   string                  `json:"scaleTarget"`
-	MinReplicas   int32                   `json:"minReplicas"`
-	MaxReplicas   int32                   `json:"maxReplicas"`
-	Cpu           ResourceMetric          `json:"cpu,omitempty"`
-	Memory        ResourceMetric          `json:"memory,omitempty"`
-	CustomMetrics map[string]CustomMetric `json:"customMetrics,omitempty"`
+	ScaleTarget string `json:"scaleTarget"`
+	// Scale target kind, any resource implementing the scale subresource can be used. Deployments and StatefulSets are looked up if not specified.
+	Kind string `json:"kind,omitempty"`
+	// Scale target API version, required for kinds other than Deployment and StatefulSet
+	ApiVersion string `json:"apiVersion,omitempty"`
+	// Scale target namespace, required for kinds other than Deployment and StatefulSet
+	Namespace       string                    `json:"namespace,omitempty"`
+	MinReplicas     int32                     `json:"minReplicas"`
+	MaxReplicas     int32                     `json:"maxReplicas"`
+	Cpu             ResourceMetric            `json:"cpu,omitempty"`
+	Memory          ResourceMetric            `json:"memory,omitempty"`
+	CustomMetrics   map[string]CustomMetric   `json:"customMetrics,omitempty"`
+	PodsMetrics     map[string]PodsMetric     `json:"podsMetrics,omitempty"`
+	ObjectMetrics   map[string]ObjectMetric   `json:"objectMetrics,omitempty"`
+	ExternalMetrics map[string]ExternalMetric `json:"externalMetrics,omitempty"`
+	Behavior        ScaleBehavior             `json:"behavior,omitempty"`
 }
@@ -11,6 +11,10 @@
 
 package client
 
+import (
+	"time"
+)
+
 type GetClusterStatusResponse struct {
 	Status        string `json:"status,omitempty"`
 	StatusMessage string `json:"statusMessage,omitempty"`
@@ -32,4 +36,6 @@ type GetClusterStatusResponse struct {
 	// The lifespan of the cluster expressed in minutes after which it is automatically deleted. Zero value means the cluster is never automatically deleted.
 	TtlMinutes int32                     `json:"ttlMinutes,omitempty"`
 	NodePools  map[string]NodePoolStatus `json:"nodePools,omitempty"`
+	// The earliest expiry of the cluster certificates, if known
+	CertificatesExpireAt time.Time `json:"certificatesExpireAt,omitempty"`
 }
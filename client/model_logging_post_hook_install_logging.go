@@ -11,12 +11,14 @@
 
 package client
 
+// Either the bucket secret or flows have to be set, flows support every destination type
 type LoggingPostHookInstallLogging struct {
-	BucketName     string           `json:"bucketName"`
+	BucketName     string           `json:"bucketName,omitempty"`
 	Region         string           `json:"region,omitempty"`
 	ResourceGroup  string           `json:"resourceGroup,omitempty"`
 	StorageAccount string           `json:"storageAccount,omitempty"`
-	SecretId       string           `json:"secretId"`
+	SecretId       string           `json:"secretId,omitempty"`
 	SecretName     string           `json:"secretName,omitempty"`
 	Tls            GenTlsForLogging `json:"tls"`
+	Flows          []LoggingFlow    `json:"flows,omitempty"`
 }
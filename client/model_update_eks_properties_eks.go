@@ -12,5 +12,8 @@
 package client
 
 type UpdateEksPropertiesEks struct {
-	NodePools map[string]UpdateNodePoolsEks `json:"nodePools"`
+	// Kubernetes version to upgrade the control plane and the nodes to, only the next minor version is allowed
+	Version         string                        `json:"version,omitempty"`
+	NodePools       map[string]UpdateNodePoolsEks `json:"nodePools"`
+	NodeImageUpdate EksNodeImageUpdate            `json:"nodeImageUpdate,omitempty"`
 }
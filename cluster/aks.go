@@ -89,6 +89,8 @@ func CreateAKSClusterFromRequest(request *pkgCluster.CreateClusterRequest, orgID
 			ResourceGroup:     request.Properties.CreateClusterAKS.ResourceGroup,
 			KubernetesVersion: request.Properties.CreateClusterAKS.KubernetesVersion,
 			NodePools:         nodePools,
+
+			APIServerAccess: newAPIServerAccessModel(request.Properties.CreateClusterAKS.APIServerAccess),
 		},
 		TtlMinutes: request.TtlMinutes,
 	}
@@ -966,7 +968,12 @@ func (c *AKSCluster) GetK8sIpv4Cidrs() (*pkgCluster.Ipv4Cidrs, error) {
 
 // GetK8sConfig returns the Kubernetes config
 func (c *AKSCluster) GetK8sConfig() ([]byte, error) {
-	return c.CommonClusterBase.getConfig(c)
+	config, err := c.CommonClusterBase.getConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, registerAPIServerTunnel(config, c.modelCluster.AKS.APIServerAccess)
 }
 
 // RequiresSshPublicKey returns true if a public SSH key is needed for bootstrapping the cluster
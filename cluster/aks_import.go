@@ -0,0 +1,96 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/banzaicloud/pipeline/model"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/goph/emperror"
+)
+
+// CreateAKSClusterFromImportRequest creates the model of an existing AKS cluster to be imported
+func CreateAKSClusterFromImportRequest(request *pkgCluster.ImportClusterRequest, orgID uint, userID uint) (*AKSCluster, error) {
+	var cluster AKSCluster
+
+	cluster.modelCluster = &model.ClusterModel{
+		Name:           request.Name,
+		Location:       request.Location,
+		Cloud:          request.Cloud,
+		OrganizationId: orgID,
+		CreatedBy:      userID,
+		SecretId:       request.SecretId,
+		Distribution:   pkgCluster.AKS,
+		AKS: model.AKSClusterModel{
+			ResourceGroup: request.Properties.ImportClusterAKS.ResourceGroup,
+		},
+	}
+
+	cluster.log = log.WithField("cluster", request.Name)
+
+	return &cluster, nil
+}
+
+// DiscoverCluster populates the model of an imported cluster from the AKS cluster
+func (c *AKSCluster) DiscoverCluster() error {
+	cluster, err := c.getAzureCluster()
+	if err != nil {
+		return emperror.WrapWith(err, "could not get AKS cluster", "cluster", c.GetName())
+	}
+
+	if cluster.ManagedClusterProperties == nil || cluster.ProvisioningState == nil || !isProvisioningSuccessful(cluster) {
+		return pkgErrors.ErrorImportedClusterNotRunning
+	}
+
+	if cluster.KubernetesVersion != nil {
+		c.modelCluster.AKS.KubernetesVersion = *cluster.KubernetesVersion
+	}
+	if cluster.EnableRBAC != nil {
+		c.modelCluster.RbacEnabled = *cluster.EnableRBAC
+	}
+
+	c.modelCluster.AKS.NodePools = nil
+	if cluster.AgentPoolProfiles != nil {
+		for _, profile := range *cluster.AgentPoolProfiles {
+			nodePool := &model.AKSNodePoolModel{
+				CreatedBy:        c.modelCluster.CreatedBy,
+				NodeInstanceType: string(profile.VMSize),
+			}
+			if profile.Name != nil {
+				nodePool.Name = *profile.Name
+			}
+			if profile.Count != nil {
+				nodePool.Count = int(*profile.Count)
+			}
+			if profile.VnetSubnetID != nil {
+				nodePool.VNetSubnetID = *profile.VnetSubnetID
+			}
+
+			c.modelCluster.AKS.NodePools = append(c.modelCluster.AKS.NodePools, nodePool)
+		}
+	}
+
+	return nil
+}
+
+// ImportCluster lets the nodes of the imported cluster use the storage accounts of the infrastructure resource group
+func (c *AKSCluster) ImportCluster() error {
+	if err := c.assignStorageAccountContributorRole(); err != nil {
+		return emperror.Wrap(err, "failed to assign storage account contributor role")
+	}
+	c.log.Info("Role assigned successfully")
+
+	return nil
+}
@@ -15,6 +15,12 @@
 package cluster
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
 	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
@@ -32,10 +38,26 @@ import (
 
 const cloudProviderAzure = "azure"
 const cloudProviderAws = "aws"
+const cloudProviderAlibaba = "alicloud"
 const autoScalerChart = "banzaicloud-stable/cluster-autoscaler"
-const expanderStrategy = "least-waste"
 const logLevel = "5"
 
+// Cluster autoscaler expander strategies
+const (
+	ExpanderLeastWaste = "least-waste"
+	ExpanderMostPods   = "most-pods"
+	ExpanderRandom     = "random"
+	ExpanderPriority   = "priority"
+)
+
+// Cluster autoscaler arguments managed by Pipeline
+const (
+	autoscalerExpanderArg                      = "expander"
+	autoscalerScaleDownUtilizationThresholdArg = "scale-down-utilization-threshold"
+	autoscalerScaleDownUnneededTimeArg         = "scale-down-unneeded-time"
+	autoscalerScaleDownDelayAfterAddArg        = "scale-down-delay-after-add"
+)
+
 const releaseName = "autoscaler"
 
 type deploymentAction string
@@ -43,10 +65,91 @@ type deploymentAction string
 const install deploymentAction = "Install"
 const upgrade deploymentAction = "Upgrade"
 
+// InstallClusterAutoscalerParams describes InstallClusterAutoscalerPostHook parameters
+type InstallClusterAutoscalerParams struct {
+	// Expander is the strategy used to select the node pool to scale up, defaults to autoscaler.expander
+	Expander string `json:"expander,omitempty"`
+	// ScaleDownUtilizationThreshold is the requested resources / allocatable ratio below which a node can be removed
+	ScaleDownUtilizationThreshold float64 `json:"scaleDownUtilizationThreshold,omitempty"`
+	// ScaleDownUnneededTime is how long a node should be unneeded before it is removed
+	ScaleDownUnneededTime string `json:"scaleDownUnneededTime,omitempty"`
+	// ScaleDownDelayAfterAdd is how long after a scale up scale down evaluation resumes
+	ScaleDownDelayAfterAdd string `json:"scaleDownDelayAfterAdd,omitempty"`
+	// NodePoolPriorities are the priorities of node pools used by the priority expander, higher is preferred
+	NodePoolPriorities map[string]int `json:"nodePoolPriorities,omitempty"`
+}
+
+// Validate validates cluster autoscaler params
+func (p InstallClusterAutoscalerParams) Validate() error {
+	switch p.Expander {
+	case "", ExpanderLeastWaste, ExpanderMostPods, ExpanderRandom:
+		if len(p.NodePoolPriorities) > 0 {
+			return errors.New("node pool priorities can only be used with the priority expander")
+		}
+	case ExpanderPriority:
+		if len(p.NodePoolPriorities) == 0 {
+			return errors.New("node pool priorities are required for the priority expander")
+		}
+	default:
+		return errors.Errorf("unsupported expander: %s", p.Expander)
+	}
+
+	if p.ScaleDownUtilizationThreshold < 0 || p.ScaleDownUtilizationThreshold > 1 {
+		return errors.New("scale down utilization threshold should be between 0 and 1")
+	}
+
+	for name, value := range map[string]string{
+		"scale down unneeded time":   p.ScaleDownUnneededTime,
+		"scale down delay after add": p.ScaleDownDelayAfterAdd,
+	} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+			return errors.Wrapf(err, "invalid %s", name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateForCloud validates cluster autoscaler params for a cluster of the given cloud
+func (p InstallClusterAutoscalerParams) ValidateForCloud(cloud string) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if cloud == pkgCluster.Google && (p.Expander != "" || len(p.NodePoolPriorities) > 0) {
+		return errors.New("expander and node pool priorities are not supported by the GKE managed cluster autoscaler")
+	}
+
+	return nil
+}
+
+// ValidatePostHooks validates the params of the cluster autoscaler posthook for a cluster of the given cloud
+func ValidatePostHooks(cloud string, postHooks pkgCluster.PostHooks) error {
+	param, ok := postHooks[pkgCluster.InstallClusterAutoscalerPostHook]
+	if !ok {
+		return nil
+	}
+
+	var params InstallClusterAutoscalerParams
+	if err := castToPostHookParam(&param, &params); err != nil {
+		return &invalidError{errors.Wrap(err, "invalid cluster autoscaler params")}
+	}
+
+	if err := params.ValidateForCloud(cloud); err != nil {
+		return &invalidError{errors.Wrap(err, "invalid cluster autoscaler params")}
+	}
+
+	return nil
+}
+
 type nodeGroup struct {
-	Name    string `json:"name"`
-	MinSize int    `json:"minSize"`
-	MaxSize int    `json:"maxSize"`
+	Name     string `json:"name"`
+	MinSize  int    `json:"minSize"`
+	MaxSize  int    `json:"maxSize"`
+	nodePool string
 }
 
 type rbac struct {
@@ -67,17 +170,118 @@ type autoDiscovery struct {
 	ClusterName string `json:"clusterName"`
 }
 
+type alibabaInfo struct {
+	AccessKeyID     string `json:"accessKeyID"`
+	AccessKeySecret string `json:"accessKeySecret"`
+	RegionID        string `json:"regionID"`
+}
+
 type autoscalingInfo struct {
-	CloudProvider     string            `json:"cloudProvider"`
-	AutoscalingGroups []nodeGroup       `json:"autoscalingGroups"`
-	ExtraArgs         map[string]string `json:"extraArgs"`
-	Rbac              rbac              `json:"rbac"`
-	AwsRegion         string            `json:"awsRegion"`
-	Azure             azureInfo         `json:"azure"`
-	AutoDiscovery     autoDiscovery     `json:"autoDiscovery"`
-	SslCertPath       *string           `json:"sslCertPath,omitempty"`
-	Affinity          v1.Affinity       `json:"affinity,omitempty"`
-	Tolerations       []v1.Toleration   `json:"tolerations,omitempty"`
+	CloudProvider      string              `json:"cloudProvider"`
+	AutoscalingGroups  []nodeGroup         `json:"autoscalingGroups"`
+	ExtraArgs          map[string]string   `json:"extraArgs"`
+	ExpanderPriorities map[string][]string `json:"expanderPriorities,omitempty"`
+	Rbac               rbac                `json:"rbac"`
+	AwsRegion          string              `json:"awsRegion"`
+	Azure              azureInfo           `json:"azure"`
+	Alibaba            *alibabaInfo        `json:"alicloud,omitempty"`
+	AutoDiscovery      autoDiscovery       `json:"autoDiscovery"`
+	SslCertPath        *string             `json:"sslCertPath,omitempty"`
+	Affinity           v1.Affinity         `json:"affinity,omitempty"`
+	Tolerations        []v1.Toleration     `json:"tolerations,omitempty"`
+}
+
+// autoscalerSettings are the user configurable parts of the cluster autoscaler deployment
+type autoscalerSettings struct {
+	extraArgs          map[string]string
+	expanderPriorities map[string][]string
+}
+
+// newAutoscalerSettings creates autoscaler settings from posthook params
+func newAutoscalerSettings(params InstallClusterAutoscalerParams, nodeGroups []nodeGroup) autoscalerSettings {
+	settings := autoscalerSettings{
+		extraArgs: map[string]string{
+			autoscalerExpanderArg: viper.GetString(config.AutoscalerExpander),
+		},
+	}
+
+	if params.Expander != "" {
+		settings.extraArgs[autoscalerExpanderArg] = params.Expander
+	}
+	if params.ScaleDownUtilizationThreshold > 0 {
+		settings.extraArgs[autoscalerScaleDownUtilizationThresholdArg] = strconv.FormatFloat(params.ScaleDownUtilizationThreshold, 'f', -1, 64)
+	}
+	if params.ScaleDownUnneededTime != "" {
+		settings.extraArgs[autoscalerScaleDownUnneededTimeArg] = params.ScaleDownUnneededTime
+	}
+	if params.ScaleDownDelayAfterAdd != "" {
+		settings.extraArgs[autoscalerScaleDownDelayAfterAddArg] = params.ScaleDownDelayAfterAdd
+	}
+
+	if len(params.NodePoolPriorities) > 0 {
+		settings.expanderPriorities = make(map[string][]string)
+		for _, group := range nodeGroups {
+			priority, ok := params.NodePoolPriorities[group.nodePool]
+			if !ok {
+				continue
+			}
+			key := strconv.Itoa(priority)
+			settings.expanderPriorities[key] = append(settings.expanderPriorities[key], "^"+regexp.QuoteMeta(group.Name)+"$")
+		}
+		for _, patterns := range settings.expanderPriorities {
+			sort.Strings(patterns)
+		}
+	}
+
+	return settings
+}
+
+// getDeployedAutoscalerSettings returns the settings of an already deployed cluster autoscaler,
+// so that they are kept when the deployment is reconciled on cluster update
+func getDeployedAutoscalerSettings(kubeConfig []byte) (*autoscalerSettings, error) {
+	deployment, err := helm.GetDeployment(releaseName, kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := autoscalerSettings{
+		extraArgs: make(map[string]string),
+	}
+
+	if extraArgs, ok := deployment.Values["extraArgs"].(map[string]interface{}); ok {
+		for _, arg := range []string{
+			autoscalerExpanderArg,
+			autoscalerScaleDownUtilizationThresholdArg,
+			autoscalerScaleDownUnneededTimeArg,
+			autoscalerScaleDownDelayAfterAddArg,
+		} {
+			if value, ok := extraArgs[arg]; ok {
+				settings.extraArgs[arg] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	if priorities, ok := deployment.Values["expanderPriorities"].(map[string]interface{}); ok && len(priorities) > 0 {
+		settings.expanderPriorities = make(map[string][]string, len(priorities))
+		for priority, patterns := range priorities {
+			patterns, ok := patterns.([]interface{})
+			if !ok {
+				continue
+			}
+			for _, pattern := range patterns {
+				settings.expanderPriorities[priority] = append(settings.expanderPriorities[priority], fmt.Sprint(pattern))
+			}
+		}
+	}
+
+	return &settings, nil
+}
+
+func (s autoscalerSettings) apply(values *autoscalingInfo) {
+	for key, value := range s.extraArgs {
+		values.ExtraArgs[key] = value
+	}
+	values.ExpanderPriorities = s.expanderPriorities
 }
 
 func getAmazonNodeGroups(cluster CommonCluster) ([]nodeGroup, error) {
@@ -98,9 +302,10 @@ func getAmazonNodeGroups(cluster CommonCluster) ([]nodeGroup, error) {
 			// if ScaleOptions is enabled on cluster, ClusterAutoscaler is disabled on all node pools (except head) on Amazon
 			if nodePool.Autoscaling && (nodePool.Name == headNodePoolName || !scaleEnabled) {
 				nodeGroups = append(nodeGroups, nodeGroup{
-					Name:    cluster.GetName() + ".node." + nodePool.Name,
-					MinSize: nodePool.NodeMinCount,
-					MaxSize: nodePool.NodeMaxCount,
+					Name:     cluster.GetName() + ".node." + nodePool.Name,
+					MinSize:  nodePool.NodeMinCount,
+					MaxSize:  nodePool.NodeMaxCount,
+					nodePool: nodePool.Name,
 				})
 			}
 		}
@@ -113,9 +318,10 @@ func getAmazonNodeGroups(cluster CommonCluster) ([]nodeGroup, error) {
 		for _, nodePool := range nodePools {
 			if nodePool.Autoscaling && (nodePool.Name == headNodePoolName || !scaleEnabled) {
 				nodeGroups = append(nodeGroups, nodeGroup{
-					Name:    cluster.GetName() + ".node." + nodePool.Name,
-					MinSize: nodePool.MinCount,
-					MaxSize: nodePool.MaxCount,
+					Name:     cluster.GetName() + ".node." + nodePool.Name,
+					MinSize:  nodePool.MinCount,
+					MaxSize:  nodePool.MaxCount,
+					nodePool: nodePool.Name,
 				})
 			}
 		}
@@ -134,9 +340,32 @@ func getAzureNodeGroups(cluster CommonCluster) ([]nodeGroup, error) {
 	for _, nodePool := range nodePools {
 		if nodePool.Autoscaling {
 			nodeGroups = append(nodeGroups, nodeGroup{
-				Name:    nodePool.Name,
-				MinSize: nodePool.NodeMinCount,
-				MaxSize: nodePool.NodeMaxCount,
+				Name:     nodePool.Name,
+				MinSize:  nodePool.NodeMinCount,
+				MaxSize:  nodePool.NodeMaxCount,
+				nodePool: nodePool.Name,
+			})
+		}
+	}
+	return nodeGroups, nil
+}
+
+func getAlibabaNodeGroups(cluster CommonCluster) ([]nodeGroup, error) {
+	var nodeGroups []nodeGroup
+
+	acskCluster, ok := cluster.(*ACSKCluster)
+	if !ok {
+		return nil, ErrInvalidClusterInstance
+	}
+
+	// ACSK node pools are backed by ESS scaling groups, they are autoscaled when their size is a range
+	for _, nodePool := range acskCluster.modelCluster.ACSK.NodePools {
+		if nodePool.AsgID != "" && nodePool.MinCount < nodePool.MaxCount {
+			nodeGroups = append(nodeGroups, nodeGroup{
+				Name:     nodePool.AsgID,
+				MinSize:  nodePool.MinCount,
+				MaxSize:  nodePool.MaxCount,
+				nodePool: nodePool.Name,
 			})
 		}
 	}
@@ -148,8 +377,7 @@ func createAutoscalingForEks(cluster CommonCluster, groups []nodeGroup) *autosca
 	return &autoscalingInfo{
 		CloudProvider: cloudProviderAws,
 		ExtraArgs: map[string]string{
-			"v":        logLevel,
-			"expander": expanderStrategy,
+			"v": logLevel,
 		},
 		Rbac:      rbac{Create: true},
 		AwsRegion: cluster.GetLocation(),
@@ -212,8 +440,7 @@ func createAutoscalingForAzure(cluster CommonCluster, groups []nodeGroup) *autos
 		CloudProvider:     cloudProviderAzure,
 		AutoscalingGroups: groups,
 		ExtraArgs: map[string]string{
-			"v":        logLevel,
-			"expander": expanderStrategy,
+			"v": logLevel,
 		},
 		Rbac: rbac{Create: true},
 		Azure: azureInfo{
@@ -230,8 +457,45 @@ func createAutoscalingForAzure(cluster CommonCluster, groups []nodeGroup) *autos
 	}
 }
 
-//DeployClusterAutoscaler post hook only for AWS & EKS & Azure for now
+func createAutoscalingForAlibaba(cluster CommonCluster, groups []nodeGroup) *autoscalingInfo {
+	clusterSecret, err := cluster.GetSecretWithValidation()
+	if err != nil {
+		log.Errorf("could not get cluster secret: %s", err.Error())
+		return nil
+	}
+
+	acskCluster, ok := cluster.(*ACSKCluster)
+	if !ok {
+		log.Errorf("could not cast Alibaba cluster to ACSKCluster")
+		return nil
+	}
+
+	return &autoscalingInfo{
+		CloudProvider:     cloudProviderAlibaba,
+		AutoscalingGroups: groups,
+		ExtraArgs: map[string]string{
+			"v": logLevel,
+		},
+		Rbac: rbac{Create: true},
+		Alibaba: &alibabaInfo{
+			AccessKeyID:     clusterSecret.Values[pkgSecret.AlibabaAccessKeyId],
+			AccessKeySecret: clusterSecret.Values[pkgSecret.AlibabaSecretAccessKey],
+			RegionID:        acskCluster.modelCluster.ACSK.RegionID,
+		},
+		Affinity:    getHeadNodeAffinity(cluster),
+		Tolerations: getHeadNodeTolerations(),
+	}
+}
+
+// DeployClusterAutoscaler deploys or reconciles the cluster autoscaler with the current node pools on every
+// distribution having autoscaled node pools. Settings of an already deployed autoscaler are kept.
 func DeployClusterAutoscaler(cluster CommonCluster) error {
+	return deployClusterAutoscaler(cluster, nil)
+}
+
+// deployClusterAutoscaler deploys the cluster autoscaler, params override the settings of an already deployed autoscaler.
+// GKE node pools are autoscaled by the autoscaler managed by GKE, their limits are reconciled through the GKE API on update.
+func deployClusterAutoscaler(cluster CommonCluster, params *InstallClusterAutoscalerParams) error {
 
 	var nodeGroups []nodeGroup
 	var err error
@@ -242,6 +506,10 @@ func DeployClusterAutoscaler(cluster CommonCluster) error {
 		nodeGroups, err = getAmazonNodeGroups(cluster)
 	case pkgCluster.Azure:
 		nodeGroups, err = getAzureNodeGroups(cluster)
+	case pkgCluster.Alibaba:
+		nodeGroups, err = getAlibabaNodeGroups(cluster)
+	case pkgCluster.Google:
+		return nil
 	default:
 		return nil
 	}
@@ -258,9 +526,21 @@ func DeployClusterAutoscaler(cluster CommonCluster) error {
 
 	if isAutoscalerDeployedAlready(releaseName, kubeConfig) {
 		// no need to upgrade in case of EKS since we're using nodepool autodiscovery
-		if _, isEks := cluster.(*EKSCluster); isEks {
+		if _, isEks := cluster.(*EKSCluster); isEks && params == nil {
 			return nil
 		}
+
+		var settings autoscalerSettings
+		if params != nil {
+			settings = newAutoscalerSettings(*params, nodeGroups)
+		} else {
+			deployedSettings, err := getDeployedAutoscalerSettings(kubeConfig)
+			if err != nil {
+				return errors.Wrap(err, "unable to get deployed cluster autoscaler settings")
+			}
+			settings = *deployedSettings
+		}
+
 		if len(nodeGroups) == 0 {
 			// delete
 			err := helm.DeleteDeployment(releaseName, kubeConfig)
@@ -270,7 +550,7 @@ func DeployClusterAutoscaler(cluster CommonCluster) error {
 			}
 		} else {
 			// upgrade
-			return deployAutoscalerChart(cluster, nodeGroups, kubeConfig, upgrade)
+			return deployAutoscalerChart(cluster, nodeGroups, settings, kubeConfig, upgrade)
 		}
 	} else {
 		if len(nodeGroups) == 0 {
@@ -278,8 +558,11 @@ func DeployClusterAutoscaler(cluster CommonCluster) error {
 			log.Info("No node groups configured for autoscaling")
 			return nil
 		}
+		if params == nil {
+			params = &InstallClusterAutoscalerParams{}
+		}
 		// install
-		return deployAutoscalerChart(cluster, nodeGroups, kubeConfig, install)
+		return deployAutoscalerChart(cluster, nodeGroups, newAutoscalerSettings(*params, nodeGroups), kubeConfig, install)
 
 	}
 
@@ -300,16 +583,22 @@ func isAutoscalerDeployedAlready(releaseName string, kubeConfig []byte) bool {
 	return false
 }
 
-func deployAutoscalerChart(cluster CommonCluster, nodeGroups []nodeGroup, kubeConfig []byte, action deploymentAction) error {
+func deployAutoscalerChart(cluster CommonCluster, nodeGroups []nodeGroup, settings autoscalerSettings, kubeConfig []byte, action deploymentAction) error {
 	var values *autoscalingInfo
 	switch cluster.GetDistribution() {
 	case pkgCluster.EKS, pkgCluster.PKE:
 		values = createAutoscalingForEks(cluster, nodeGroups)
 	case pkgCluster.AKS:
 		values = createAutoscalingForAzure(cluster, nodeGroups)
+	case pkgCluster.ACSK:
+		values = createAutoscalingForAlibaba(cluster, nodeGroups)
 	default:
 		return nil
 	}
+	if values == nil {
+		return errors.Errorf("unable to create %s cluster autoscaler values", cluster.GetDistribution())
+	}
+	settings.apply(values)
 	yamlValues, err := yaml.Marshal(*values)
 	if err != nil {
 		log.Errorf("Error during values marshal: %s", err.Error())
@@ -0,0 +1,121 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+func TestInstallClusterAutoscalerParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  InstallClusterAutoscalerParams
+		wantErr bool
+	}{
+		{name: "defaults", params: InstallClusterAutoscalerParams{}},
+		{
+			name: "scale down settings",
+			params: InstallClusterAutoscalerParams{
+				Expander:                      ExpanderMostPods,
+				ScaleDownUtilizationThreshold: 0.6,
+				ScaleDownUnneededTime:         "5m",
+				ScaleDownDelayAfterAdd:        "15m",
+			},
+		},
+		{
+			name:   "priority expander",
+			params: InstallClusterAutoscalerParams{Expander: ExpanderPriority, NodePoolPriorities: map[string]int{"pool1": 10}},
+		},
+		{name: "priority expander without priorities", params: InstallClusterAutoscalerParams{Expander: ExpanderPriority}, wantErr: true},
+		{name: "priorities without priority expander", params: InstallClusterAutoscalerParams{NodePoolPriorities: map[string]int{"pool1": 10}}, wantErr: true},
+		{name: "unknown expander", params: InstallClusterAutoscalerParams{Expander: "cheapest"}, wantErr: true},
+		{name: "invalid threshold", params: InstallClusterAutoscalerParams{ScaleDownUtilizationThreshold: 1.5}, wantErr: true},
+		{name: "invalid duration", params: InstallClusterAutoscalerParams{ScaleDownUnneededTime: "ten minutes"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			err := test.params.Validate()
+			if test.wantErr && err == nil {
+				t.Fatal("expected validation error")
+			} else if !test.wantErr && err != nil {
+				t.Fatalf("unexpected validation error: %s", err)
+			}
+		})
+	}
+}
+
+func TestNewAutoscalerSettings(t *testing.T) {
+	nodeGroups := []nodeGroup{
+		{Name: "cluster.node.pool1", nodePool: "pool1"},
+		{Name: "cluster.node.pool2", nodePool: "pool2"},
+		{Name: "cluster.node.pool3", nodePool: "pool3"},
+	}
+	params := InstallClusterAutoscalerParams{
+		Expander:                      ExpanderPriority,
+		ScaleDownUtilizationThreshold: 0.5,
+		ScaleDownUnneededTime:         "10m",
+		NodePoolPriorities:            map[string]int{"pool1": 10, "pool2": 50, "pool3": 10},
+	}
+
+	settings := newAutoscalerSettings(params, nodeGroups)
+
+	expectedArgs := map[string]string{
+		"expander":                         "priority",
+		"scale-down-utilization-threshold": "0.5",
+		"scale-down-unneeded-time":         "10m",
+	}
+	if !reflect.DeepEqual(settings.extraArgs, expectedArgs) {
+		t.Errorf("unexpected extra args: %v", settings.extraArgs)
+	}
+
+	expectedPriorities := map[string][]string{
+		"10": {`^cluster\.node\.pool1$`, `^cluster\.node\.pool3$`},
+		"50": {`^cluster\.node\.pool2$`},
+	}
+	if !reflect.DeepEqual(settings.expanderPriorities, expectedPriorities) {
+		t.Errorf("unexpected expander priorities: %v", settings.expanderPriorities)
+	}
+}
+
+func TestValidatePostHooks(t *testing.T) {
+	postHooks := pkgCluster.PostHooks{
+		pkgCluster.InstallClusterAutoscalerPostHook: map[string]interface{}{
+			"expander":           ExpanderPriority,
+			"nodePoolPriorities": map[string]int{"pool1": 10},
+		},
+	}
+
+	if err := ValidatePostHooks(pkgCluster.Amazon, postHooks); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	err := ValidatePostHooks(pkgCluster.Google, postHooks)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if e, ok := err.(interface{ IsInvalid() bool }); !ok || !e.IsInvalid() {
+		t.Errorf("expected invalid error, got %T", err)
+	}
+
+	if err := ValidatePostHooks(pkgCluster.Google, pkgCluster.PostHooks{pkgCluster.InstallLogging: nil}); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
 	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
 	modelOracle "github.com/banzaicloud/pipeline/pkg/providers/oracle/model"
 	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
 	"github.com/banzaicloud/pipeline/secret"
@@ -259,6 +260,51 @@ func getScaleOptionsFromModel(scaleOptions model.ScaleOptions) *pkgCluster.Scale
 	return nil
 }
 
+// newAPIServerAccessModel creates the model of the API server access settings of a create request
+func newAPIServerAccessModel(access *pkgCommon.APIServerAccess) model.APIServerAccessModel {
+	if access == nil {
+		return model.APIServerAccessModel{}
+	}
+
+	return model.APIServerAccessModel{
+		PrivateEndpoint:    access.PrivateEndpoint,
+		PublicAccessCIDRs:  strings.Join(access.PublicAccessCIDRs, ","),
+		PrivateNodes:       access.PrivateNodes,
+		TunnelAgentAddress: access.TunnelAgentAddress(),
+	}
+}
+
+func getAPIServerAccessFromModel(accessModel model.APIServerAccessModel) *pkgCommon.APIServerAccess {
+	if accessModel == (model.APIServerAccessModel{}) {
+		return nil
+	}
+
+	access := &pkgCommon.APIServerAccess{
+		PrivateEndpoint: accessModel.PrivateEndpoint,
+		PrivateNodes:    accessModel.PrivateNodes,
+	}
+	if len(accessModel.PublicAccessCIDRs) > 0 {
+		access.PublicAccessCIDRs = strings.Split(accessModel.PublicAccessCIDRs, ",")
+	}
+	if len(accessModel.TunnelAgentAddress) > 0 {
+		access.Tunnel = &pkgCommon.APIServerTunnel{AgentAddress: accessModel.TunnelAgentAddress}
+	}
+
+	return access
+}
+
+// registerAPIServerTunnel routes the Kubernetes clients of a cluster through its tunnel agent if there is one
+func registerAPIServerTunnel(kubeConfig []byte, accessModel model.APIServerAccessModel) error {
+	if len(accessModel.TunnelAgentAddress) == 0 {
+		return nil
+	}
+
+	return emperror.Wrap(
+		k8sclient.RegisterAPIServerTunnel(kubeConfig, accessModel.TunnelAgentAddress),
+		"failed to register API server tunnel",
+	)
+}
+
 // GetCommonClusterFromModel extracts CommonCluster from a ClusterModel
 func GetCommonClusterFromModel(modelCluster *model.ClusterModel) (CommonCluster, error) {
 
@@ -441,6 +487,26 @@ func CreateCommonClusterFromRequest(createClusterRequest *pkgCluster.CreateClust
 	return nil, pkgErrors.ErrorNotSupportedCloudType
 }
 
+// CreateCommonClusterFromImportRequest creates a CommonCluster for an existing cluster to be imported
+func CreateCommonClusterFromImportRequest(importClusterRequest *pkgCluster.ImportClusterRequest, orgId uint, userId uint) (CommonCluster, error) {
+	if err := importClusterRequest.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch importClusterRequest.Cloud {
+	case pkgCluster.Amazon:
+		return CreateEKSClusterFromImportRequest(importClusterRequest, orgId, userId)
+
+	case pkgCluster.Azure:
+		return CreateAKSClusterFromImportRequest(importClusterRequest, orgId, userId)
+
+	case pkgCluster.Google:
+		return CreateGKEClusterFromImportRequest(importClusterRequest, orgId, userId)
+	}
+
+	return nil, pkgErrors.ErrorClusterImportNotSupported
+}
+
 //createCommonClusterWithDistributionFromRequest creates a CommonCluster from a request
 func createCommonClusterWithDistributionFromRequest(createClusterRequest *pkgCluster.CreateClusterRequest, orgId uint, userId uint) (*EC2ClusterPKE, error) {
 	switch createClusterRequest.Cloud {
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -38,6 +39,7 @@ import (
 	"github.com/banzaicloud/pipeline/pkg/cluster/pke"
 	"github.com/banzaicloud/pipeline/pkg/common"
 	pkgError "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
 	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
 	"github.com/banzaicloud/pipeline/secret"
 	"github.com/banzaicloud/pipeline/secret/verify"
@@ -331,6 +333,10 @@ func (c *EC2ClusterPKE) IsMasterReady() (bool, error) {
 
 // RegisterNode adds a Node to the DB
 func (c *EC2ClusterPKE) RegisterNode(name, nodePoolName, ip string, master, worker bool) error {
+	if np, ok := c.getNodePool(nodePoolName); ok && np.Provider == internalPke.NPPHosts {
+		return c.checkInHost(np, name, ip)
+	}
+
 	/* TODO: decide if we need this on AWS
 	db := pipConfig.DB()
 	nodePool := internalPke.NodePool{
@@ -369,6 +375,27 @@ func (c *EC2ClusterPKE) RegisterNode(name, nodePoolName, ip string, master, work
 	return nil
 }
 
+// checkInHost records that a pre-provisioned host of the nodepool reported to be ready
+func (c *EC2ClusterPKE) checkInHost(np internalPke.NodePool, name, ip string) error {
+	for _, host := range np.Hosts {
+		if host.Name != name && (ip == "" || host.PrivateIP != ip) {
+			continue
+		}
+
+		now := time.Now()
+		err := c.db.Model(&host).Update("last_check_in", &now).Error
+		if err != nil {
+			return emperror.WrapWith(err, "failed to save host check-in", "host", host.Name)
+		}
+
+		c.log.WithField("host", host.Name).WithField("nodepool", np.Name).Info("host checked in")
+
+		return nil
+	}
+
+	return errors.Errorf("host %q (%s) is not registered in node pool %q", name, ip, np.Name)
+}
+
 // Create master CF template
 func CreateMasterCF(formation *cloudformation.CloudFormation) error {
 	return nil
@@ -402,6 +429,9 @@ func createNodePoolsFromPKERequest(nodePools pke.UpdateNodePools) []pkeworkflow.
 }
 
 func (c *EC2ClusterPKE) UpdatePKECluster(ctx context.Context, request *pkgCluster.UpdateClusterRequest, workflowClient client.Client, externalBaseURL string) error {
+	if c.UsesHosts() {
+		return errors.New("node pools of pre-provisioned hosts cannot be updated")
+	}
 
 	vpcid, ok := c.model.Network.CloudProviderConfig["vpcID"].(string)
 	if !ok {
@@ -561,6 +591,297 @@ func (c *EC2ClusterPKE) DeletePKECluster(ctx context.Context, workflowClient cli
 	return nil
 }
 
+// SetKubernetesVersion stores the Kubernetes version of the cluster.
+func (c *EC2ClusterPKE) SetKubernetesVersion(version string) error {
+	c.model.Kubernetes.Version = version
+
+	err := c.db.Save(&c.model.Kubernetes).Error
+	if err != nil {
+		return emperror.WrapWith(err, "failed to save Kubernetes version", "version", version)
+	}
+
+	return nil
+}
+
+// UpgradeKubernetes starts the upgrade of the cluster to a new Kubernetes version.
+// The upgrade runs in the background, its progress can be followed through the returned upgrade record.
+func (c *EC2ClusterPKE) UpgradeKubernetes(ctx context.Context, version, strategy string, userID uint, workflowClient client.Client, externalBaseURL string) (*internalPke.KubernetesUpgrade, error) {
+	switch strategy {
+	case "":
+		strategy = internalPke.UpgradeStrategyInPlace
+	case internalPke.UpgradeStrategyInPlace, internalPke.UpgradeStrategyReplace:
+	default:
+		return nil, &internalPke.UpgradeStrategyError{Strategy: strategy}
+	}
+
+	if strategy == internalPke.UpgradeStrategyReplace && c.UsesHosts() {
+		return nil, &internalPke.UpgradeStrategyError{Strategy: strategy}
+	}
+
+	err := internalPke.ValidateVersionSkew(c.model.Kubernetes.Version, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var running int
+	err = c.db.Model(&internalPke.KubernetesUpgrade{}).
+		Where(&internalPke.KubernetesUpgrade{ClusterID: c.GetID(), Status: internalPke.UpgradeStatusRunning}).
+		Count(&running).Error
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to check running upgrades")
+	}
+	if running > 0 {
+		return nil, errors.New("another Kubernetes upgrade is already running")
+	}
+
+	upgrade := &internalPke.KubernetesUpgrade{
+		ClusterID:   c.GetID(),
+		CreatedBy:   userID,
+		FromVersion: c.model.Kubernetes.Version,
+		ToVersion:   version,
+		Strategy:    strategy,
+		Status:      internalPke.UpgradeStatusRunning,
+	}
+	if err := c.db.Create(upgrade).Error; err != nil {
+		return nil, emperror.Wrap(err, "failed to save Kubernetes upgrade")
+	}
+
+	input := pkeworkflow.UpgradeClusterWorkflowInput{
+		OrganizationID:      c.GetOrganizationId(),
+		ClusterID:           c.GetID(),
+		ClusterName:         c.GetName(),
+		SecretID:            c.GetSecretId(),
+		Region:              c.GetLocation(),
+		PipelineExternalURL: externalBaseURL,
+		UpgradeID:           upgrade.ID,
+		Version:             version,
+		Strategy:            strategy,
+	}
+	workflowOptions := client.StartWorkflowOptions{
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 24 * time.Hour,
+	}
+	exec, err := workflowClient.StartWorkflow(ctx, workflowOptions, pkeworkflow.UpgradeClusterWorkflowName, input)
+	if err != nil {
+		statusErr := c.db.Model(upgrade).Updates(map[string]interface{}{
+			"status":         internalPke.UpgradeStatusFailed,
+			"status_message": err.Error(),
+		}).Error
+		if statusErr != nil {
+			c.log.Errorf("failed to update upgrade status: %s", statusErr)
+		}
+
+		return nil, emperror.Wrap(err, "failed to start Kubernetes upgrade")
+	}
+
+	err = c.SetCurrentWorkflowID(exec.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return upgrade, nil
+}
+
+// ListKubernetesUpgrades returns the Kubernetes upgrades of the cluster, newest first.
+func (c *EC2ClusterPKE) ListKubernetesUpgrades() ([]internalPke.KubernetesUpgrade, error) {
+	var upgrades []internalPke.KubernetesUpgrade
+
+	err := c.db.Where(&internalPke.KubernetesUpgrade{ClusterID: c.GetID()}).Order("id desc").Find(&upgrades).Error
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list Kubernetes upgrades")
+	}
+
+	return upgrades, nil
+}
+
+// GetKubernetesUpgrade returns a Kubernetes upgrade of the cluster with the progress of its nodes.
+func (c *EC2ClusterPKE) GetKubernetesUpgrade(upgradeID uint) (*internalPke.KubernetesUpgrade, error) {
+	var upgrade internalPke.KubernetesUpgrade
+
+	err := c.db.Preload("Nodes").Where(&internalPke.KubernetesUpgrade{ID: upgradeID, ClusterID: c.GetID()}).First(&upgrade).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.Wrapf(err, "Kubernetes upgrade %d not found", upgradeID)
+	} else if err != nil {
+		return nil, emperror.Wrap(err, "failed to get Kubernetes upgrade")
+	}
+
+	return &upgrade, nil
+}
+
+// GetCertificateExpiries returns the expiry of the CAs of the cluster and of the admin kubeconfig, earliest first.
+func (c *EC2ClusterPKE) GetCertificateExpiries() ([]internalPke.CertificateExpiry, error) {
+	caSecret, err := secret.Store.GetByName(c.GetOrganizationId(), fmt.Sprintf("cluster-%d-ca", c.GetID()))
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get cluster CAs")
+	}
+
+	certificates := make(map[string]string)
+	for key, name := range map[string]string{
+		pkgSecret.KubernetesCACert: pkgSecret.KubernetesCACommonName,
+		pkgSecret.EtcdCACert:       pkgSecret.EtcdCACommonName,
+		pkgSecret.FrontProxyCACert: pkgSecret.KubernetesFrontProxyCACommonName,
+	} {
+		certificates[name] = caSecret.Values[key]
+		certificates[name+" (next)"] = caSecret.Values[pkgSecret.NextCAKey(key)]
+	}
+
+	if kubeConfig, err := c.GetK8sConfig(); err == nil {
+		config, err := k8sclient.NewClientConfig(kubeConfig)
+		if err != nil {
+			return nil, emperror.Wrap(err, "failed to parse kubeconfig")
+		}
+
+		certificates["admin kubeconfig"] = string(config.TLSClientConfig.CertData)
+	}
+
+	return internalPke.ParseCertificateExpiries(certificates)
+}
+
+// RotateCertificates starts the rotation of the leaf certificates or the CAs of the cluster.
+func (c *EC2ClusterPKE) RotateCertificates(ctx context.Context, rotationType string, userID uint, workflowClient client.Client) (*internalPke.CertificateRotation, error) {
+	switch rotationType {
+	case internalPke.CertificateRotationTypeLeaf, internalPke.CertificateRotationTypeCA:
+	default:
+		return nil, &internalPke.CertificateRotationTypeError{Type: rotationType}
+	}
+
+	var running int
+	err := c.db.Model(&internalPke.CertificateRotation{}).
+		Where(&internalPke.CertificateRotation{ClusterID: c.GetID(), Status: internalPke.CertificateRotationStatusRunning}).
+		Count(&running).Error
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to check running certificate rotations")
+	}
+	if running > 0 {
+		return nil, errors.New("another certificate rotation is already running")
+	}
+
+	rotation := &internalPke.CertificateRotation{
+		ClusterID: c.GetID(),
+		CreatedBy: userID,
+		Type:      rotationType,
+		Status:    internalPke.CertificateRotationStatusRunning,
+	}
+	if err := c.db.Create(rotation).Error; err != nil {
+		return nil, emperror.Wrap(err, "failed to save certificate rotation")
+	}
+
+	input := pkeworkflow.RotateCertificatesWorkflowInput{
+		ClusterID:  c.GetID(),
+		RotationID: rotation.ID,
+		Type:       rotationType,
+	}
+	workflowOptions := client.StartWorkflowOptions{
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 24 * time.Hour,
+	}
+	_, err = workflowClient.StartWorkflow(ctx, workflowOptions, pkeworkflow.RotateCertificatesWorkflowName, input)
+	if err != nil {
+		statusErr := c.db.Model(rotation).Updates(map[string]interface{}{
+			"status":         internalPke.CertificateRotationStatusFailed,
+			"status_message": err.Error(),
+		}).Error
+		if statusErr != nil {
+			c.log.Errorf("failed to update certificate rotation status: %s", statusErr)
+		}
+
+		return nil, emperror.Wrap(err, "failed to start certificate rotation")
+	}
+
+	return rotation, nil
+}
+
+// ListCertificateRotations returns the certificate rotations of the cluster, newest first.
+func (c *EC2ClusterPKE) ListCertificateRotations() ([]internalPke.CertificateRotation, error) {
+	var rotations []internalPke.CertificateRotation
+
+	err := c.db.Where(&internalPke.CertificateRotation{ClusterID: c.GetID()}).Order("id desc").Find(&rotations).Error
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list certificate rotations")
+	}
+
+	return rotations, nil
+}
+
+// GetCertificateRotation returns a certificate rotation of the cluster with the agent commands run by it.
+func (c *EC2ClusterPKE) GetCertificateRotation(rotationID uint) (*internalPke.CertificateRotation, error) {
+	var rotation internalPke.CertificateRotation
+
+	err := c.db.Preload("Commands").Where(&internalPke.CertificateRotation{ID: rotationID, ClusterID: c.GetID()}).First(&rotation).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.Wrapf(err, "certificate rotation %d not found", rotationID)
+	} else if err != nil {
+		return nil, emperror.Wrap(err, "failed to get certificate rotation")
+	}
+
+	return &rotation, nil
+}
+
+// PollAgentCommands returns the pending commands of a node, and marks them as running.
+func (c *EC2ClusterPKE) PollAgentCommands(node string) ([]internalPke.AgentCommand, error) {
+	var commands []internalPke.AgentCommand
+
+	err := c.db.
+		Where(&internalPke.AgentCommand{ClusterID: c.GetID(), Node: node, Status: internalPke.AgentCommandStatusPending}).
+		Order("id").
+		Find(&commands).Error
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list agent commands")
+	}
+
+	now := time.Now()
+	for i := range commands {
+		commands[i].Status = internalPke.AgentCommandStatusRunning
+		commands[i].StartedAt = &now
+
+		if err := c.db.Save(&commands[i]).Error; err != nil {
+			return nil, emperror.Wrapf(err, "failed to start agent command %d", commands[i].ID)
+		}
+	}
+
+	return commands, nil
+}
+
+// ReportAgentCommand saves the result of a command reported by the agent of a node.
+// The renewed admin kubeconfig is only accepted with the successful result of a master certificate renewal.
+func (c *EC2ClusterPKE) ReportAgentCommand(commandID uint, succeeded bool, output string, config []byte) error {
+	var command internalPke.AgentCommand
+
+	err := c.db.Where(&internalPke.AgentCommand{ID: commandID, ClusterID: c.GetID()}).First(&command).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return errors.Wrapf(err, "agent command %d not found", commandID)
+	} else if err != nil {
+		return emperror.Wrap(err, "failed to get agent command")
+	}
+
+	if command.Finished() {
+		return &invalidError{errors.Errorf("agent command %d is already finished", commandID)}
+	}
+
+	if len(config) > 0 {
+		if !command.RenewsMasterCertificates() {
+			return &invalidError{errors.Errorf("agent command %d doesn't renew master certificates, config is not accepted", commandID)}
+		}
+
+		if succeeded {
+			if err := StoreKubernetesConfig(c, config); err != nil {
+				return emperror.Wrap(err, "failed to store renewed kubeconfig")
+			}
+		}
+	}
+
+	now := time.Now()
+
+	command.Status = internalPke.AgentCommandStatusFailed
+	if succeeded {
+		command.Status = internalPke.AgentCommandStatusSucceeded
+	}
+	command.Output = output
+	command.FinishedAt = &now
+
+	return emperror.Wrapf(c.db.Save(&command).Error, "failed to save agent command %d", commandID)
+}
+
 func (c *EC2ClusterPKE) DownloadK8sConfig() ([]byte, error) {
 	return nil, pkgError.ErrorFunctionShouldNotBeCalled
 }
@@ -615,6 +936,16 @@ func (c *EC2ClusterPKE) GetStatus() (*pkgCluster.GetClusterStatusResponse, error
 	hasSpotNodePool := false
 	nodePools := make(map[string]*pkgCluster.NodePoolStatus)
 	for _, np := range c.model.NodePools {
+		if np.Provider == internalPke.NPPHosts {
+			nodePools[np.Name] = &pkgCluster.NodePoolStatus{
+				Count:             len(np.Hosts),
+				MinCount:          len(np.Hosts),
+				MaxCount:          len(np.Hosts),
+				CreatorBaseFields: *NewCreatorBaseFields(np.CreatedAt, np.CreatedBy),
+			}
+			continue
+		}
+
 		providerConfig := internalPke.NodePoolProviderConfigAmazon{}
 		err := mapstructure.Decode(np.ProviderConfig, &providerConfig)
 		if err != nil {
@@ -636,9 +967,35 @@ func (c *EC2ClusterPKE) GetStatus() (*pkgCluster.GetClusterStatusResponse, error
 		}
 	}
 
+	status, statusMessage := c.model.Cluster.Status, c.model.Cluster.StatusMessage
+
+	// the state of pre-provisioned hosts is only known from their check-ins
+	if status == pkgCluster.Running {
+		if pending := c.getPendingHosts(); len(pending) > 0 {
+			status = pkgCluster.Warning
+			statusMessage = fmt.Sprintf("hosts not reported ready yet: %s", strings.Join(pending, ", "))
+		}
+	}
+
+	// report the earliest certificate expiry, and warn about certificates to be rotated soon
+	var certificatesExpireAt *time.Time
+	if status == pkgCluster.Running || status == pkgCluster.Warning {
+		expiries, err := c.GetCertificateExpiries()
+		if err != nil {
+			c.log.Warnf("failed to check certificate expiry: %s", err)
+		} else if len(expiries) > 0 {
+			certificatesExpireAt = &expiries[0].NotAfter
+
+			if status == pkgCluster.Running && expiries[0].Expiring(time.Now()) {
+				status = pkgCluster.Warning
+				statusMessage = fmt.Sprintf("%s certificate expires at %s", expiries[0].Name, expiries[0].NotAfter.Format(time.RFC3339))
+			}
+		}
+	}
+
 	return &pkgCluster.GetClusterStatusResponse{
-		Status:            c.model.Cluster.Status,
-		StatusMessage:     c.model.Cluster.StatusMessage,
+		Status:            status,
+		StatusMessage:     statusMessage,
 		Name:              c.model.Cluster.Name,
 		Location:          c.model.Cluster.Location,
 		Cloud:             c.model.Cluster.Cloud,
@@ -655,17 +1012,47 @@ func (c *EC2ClusterPKE) GetStatus() (*pkgCluster.GetClusterStatusResponse, error
 		Region:            c.model.Cluster.Location,
 		TtlMinutes:        c.model.Cluster.TtlMinutes,
 		StartedAt:         c.model.Cluster.StartedAt,
+
+		CertificatesExpireAt: certificatesExpireAt,
 	}, nil
 }
 
 // IsReady checks if the cluster is running according to the cloud provider.
+// Clusters of pre-provisioned hosts are ready when all of their master hosts have checked in.
 func (c *EC2ClusterPKE) IsReady() (bool, error) {
+	for _, np := range c.model.NodePools {
+		if np.Provider != internalPke.NPPHosts || !np.Roles.Contains(internalPke.RoleMaster) {
+			continue
+		}
+
+		for _, host := range np.Hosts {
+			if host.LastCheckIn == nil {
+				return false, nil
+			}
+		}
+	}
+
 	// TODO: is this a correct implementation?
 	return true, nil
 }
 
+// getPendingHosts returns the names of the pre-provisioned hosts that have not reported to be ready yet
+func (c *EC2ClusterPKE) getPendingHosts() []string {
+	var pending []string
+	for _, np := range c.model.NodePools {
+		for _, host := range np.Hosts {
+			if np.Provider == internalPke.NPPHosts && host.LastCheckIn == nil {
+				pending = append(pending, host.Name)
+			}
+		}
+	}
+
+	return pending
+}
+
 type PKENodePool struct {
 	Name              string
+	Provider          string
 	MinCount          int
 	MaxCount          int
 	Count             int
@@ -700,6 +1087,12 @@ func (c *EC2ClusterPKE) GetNodePools() []PKENodePool {
 			ImageID:           amazonPool.AutoScalingGroup.Image,
 			SpotPrice:         amazonPool.AutoScalingGroup.SpotPrice,
 			Autoscaling:       np.Autoscaling,
+			Provider:          string(np.Provider),
+		}
+		if np.Provider == internalPke.NPPHosts {
+			pools[i].MinCount = len(np.Hosts)
+			pools[i].MaxCount = len(np.Hosts)
+			pools[i].Count = len(np.Hosts)
 		}
 		for _, role := range np.Roles {
 			if role == "master" {
@@ -714,6 +1107,37 @@ func (c *EC2ClusterPKE) GetNodePools() []PKENodePool {
 	return pools
 }
 
+// PKEHost describes a pre-provisioned host of a PKE cluster
+type PKEHost struct {
+	Name        string
+	NodePool    string
+	PrivateIP   string
+	Master      bool
+	LastCheckIn *time.Time
+}
+
+// ListHosts returns the pre-provisioned hosts of the cluster
+func (c *EC2ClusterPKE) ListHosts() []PKEHost {
+	var hosts []PKEHost
+	for _, np := range c.model.NodePools {
+		if np.Provider != internalPke.NPPHosts {
+			continue
+		}
+
+		for _, host := range np.Hosts {
+			hosts = append(hosts, PKEHost{
+				Name:        host.Name,
+				NodePool:    np.Name,
+				PrivateIP:   host.PrivateIP,
+				Master:      np.Roles.Contains(internalPke.RoleMaster),
+				LastCheckIn: host.LastCheckIn,
+			})
+		}
+	}
+
+	return hosts
+}
+
 // ListNodeNames returns node names to label them
 func (c *EC2ClusterPKE) ListNodeNames() (common.NodeNames, error) {
 	var nodes = make(map[string][]string)
@@ -765,38 +1189,114 @@ func (c *EC2ClusterPKE) GetPipelineToken(tokenGenerator interface{}) (string, er
 
 // GetBootstrapCommand returns a command line to use to install a node in the given nodepool
 func (c *EC2ClusterPKE) GetBootstrapCommand(nodePoolName, url, token string) (string, error) {
-	subcommand := "worker"
-	var np internalPke.NodePool
-	for _, np = range c.model.NodePools {
-		if np.Name == nodePoolName {
-			for _, role := range np.Roles {
-				if role == internalPke.RoleMaster {
-					subcommand = "master"
-					break
-				}
+	return c.getCloudBootstrapCommand(nodePoolName, url, token, false)
+}
+
+// GetJoinControlPlaneCommand returns a command line to use to install an additional master node of a highly available control plane
+func (c *EC2ClusterPKE) GetJoinControlPlaneCommand(nodePoolName, url, token string) (string, error) {
+	return c.getCloudBootstrapCommand(nodePoolName, url, token, true)
+}
+
+// GetHostBootstrapCommand returns a command line to use to install a pre-provisioned host of the given nodepool
+func (c *EC2ClusterPKE) GetHostBootstrapCommand(nodePoolName, hostName, url, token string) (string, error) {
+	np, ok := c.getNodePool(nodePoolName)
+	if !ok || np.Provider != internalPke.NPPHosts {
+		return "", errors.Errorf("node pool %q does not consist of pre-provisioned hosts", nodePoolName)
+	}
+
+	for i, host := range np.Hosts {
+		if host.Name != hostName {
+			continue
+		}
+
+		// the first master host initializes the control plane, the others join it
+		joinControlPlane := i > 0 && np.Roles.Contains(internalPke.RoleMaster)
+
+		// the network interface of the host is selected by its address
+		return c.getBootstrapCommand(nodePoolName, url, token, host.PrivateIP+"/32", joinControlPlane)
+	}
+
+	return "", errors.Errorf("host %q not found in node pool %q", hostName, nodePoolName)
+}
+
+// GetMasterCount returns the number of master nodes in the control plane
+func (c *EC2ClusterPKE) GetMasterCount() int {
+	for _, np := range c.GetNodePools() {
+		if np.Master {
+			if np.Count > 1 {
+				return np.Count
 			}
+			break
 		}
 	}
-	if nodePoolName == "master" {
-		subcommand = "master"
+
+	return 1
+}
+
+// UsesHosts returns true if the cluster is installed on pre-provisioned hosts instead of EC2 instances
+func (c *EC2ClusterPKE) UsesHosts() bool {
+	for _, np := range c.model.NodePools {
+		if np.Provider == internalPke.NPPHosts {
+			return true
+		}
 	}
 
-	version := c.model.Kubernetes.Version
-	if version == "" {
-		version = defaultPKEVersion
+	return false
+}
+
+func (c *EC2ClusterPKE) getNodePool(nodePoolName string) (internalPke.NodePool, bool) {
+	for _, np := range c.model.NodePools {
+		if np.Name == nodePoolName {
+			return np, true
+		}
 	}
-	if version[0] == 'v' {
-		version = version[1:]
+
+	return internalPke.NodePool{}, false
+}
+
+func (c *EC2ClusterPKE) getCloudBootstrapCommand(nodePoolName, url, token string, joinControlPlane bool) (string, error) {
+	if np, ok := c.getNodePool(nodePoolName); ok && np.Provider == internalPke.NPPHosts {
+		return "", errors.Errorf("node pool %q consists of pre-provisioned hosts, use the bootstrap commands of the hosts", nodePoolName)
 	}
+
+	infrastructureCIDR, err := c.getInfrastructureCIDR(nodePoolName)
+	if err != nil {
+		return "", err
+	}
+
+	return c.getBootstrapCommand(nodePoolName, url, token, infrastructureCIDR, joinControlPlane)
+}
+
+// getInfrastructureCIDR returns the address range of the network the nodes of the nodepool are connected to
+func (c *EC2ClusterPKE) getInfrastructureCIDR(nodePoolName string) (string, error) {
+	highAvailability := c.GetMasterCount() > 1
+
 	infrastructureCIDR := ""
-	cloudProvider, _, subnets, err := c.GetNetworkCloudProvider()
+	cloudProvider, vpcID, subnets, err := c.GetNetworkCloudProvider()
 	if err != nil {
 		return "", err
 	}
 	switch cloudProvider {
 	case string(internalPke.CNPAmazon):
-		// match subnet
-		if len(subnets) > 0 {
+		// nodes of clusters with a highly available control plane are spread across subnets
+		if highAvailability && vpcID != "" {
+			s, err := c.GetAWSClient()
+			if err != nil {
+				return "", err
+			}
+
+			// query VPC CIDR from amazon
+			out, err := ec2.New(s).DescribeVpcs(&ec2.DescribeVpcsInput{
+				VpcIds: aws.StringSlice([]string{vpcID}),
+			})
+			if err != nil {
+				return "", err
+			}
+			if len(out.Vpcs) > 0 {
+				infrastructureCIDR = aws.StringValue(out.Vpcs[0].CidrBlock)
+			}
+		} else if len(subnets) > 0 {
+			// match subnet
 			s, err := c.GetAWSClient()
 			if err != nil {
 				return "", err
@@ -825,6 +1325,45 @@ func (c *EC2ClusterPKE) GetBootstrapCommand(nodePoolName, url, token string) (st
 		return "", errors.New("cloud not query nodepool subnet")
 	}
 
+	return infrastructureCIDR, nil
+}
+
+func (c *EC2ClusterPKE) getBootstrapCommand(nodePoolName, url, token, infrastructureCIDR string, joinControlPlane bool) (string, error) {
+	subcommand := "worker"
+	var np internalPke.NodePool
+	for _, np = range c.model.NodePools {
+		if np.Name == nodePoolName {
+			for _, role := range np.Roles {
+				if role == internalPke.RoleMaster {
+					subcommand = "master"
+					break
+				}
+			}
+		}
+	}
+	if nodePoolName == "master" {
+		subcommand = "master"
+	}
+
+	version := c.model.Kubernetes.Version
+	if version == "" {
+		version = defaultPKEVersion
+	}
+	if version[0] == 'v' {
+		version = version[1:]
+	}
+	if joinControlPlane && subcommand != "master" {
+		return "", errors.Errorf("node pool %q is not a master node pool", nodePoolName)
+	}
+
+	highAvailability := c.GetMasterCount() > 1
+
+	// pre-provisioned hosts are not integrated with the cloud provider
+	cloudProviderFlag := "--kubernetes-cloud-provider=aws "
+	if c.UsesHosts() {
+		cloudProviderFlag = ""
+	}
+
 	apiAddress, _, err := c.GetNetworkApiServerAddress()
 	if err != nil {
 		return "", err
@@ -832,17 +1371,31 @@ func (c *EC2ClusterPKE) GetBootstrapCommand(nodePoolName, url, token string) (st
 
 	// master
 	if subcommand == "master" {
+		networkProvider := c.model.Network.Provider
+		if networkProvider == "" {
+			networkProvider = internalPke.NPWeave
+		}
+
+		// fall back to the ranges used before the network settings were passed to PKE
+		serviceCIDR, podCIDR := c.model.Network.ServiceCIDR, c.model.Network.PodCIDR
+		if serviceCIDR == "" {
+			serviceCIDR = "10.10.0.0/16"
+		}
+		if podCIDR == "" {
+			podCIDR = "10.20.0.0/16"
+		}
+
 		command := fmt.Sprintf("pke install %s "+
 			"--pipeline-url=%q "+
 			"--pipeline-token=%q "+
 			"--pipeline-org-id=%d "+
 			"--pipeline-cluster-id=%d "+
 			"--pipeline-nodepool=%q "+
-			"--kubernetes-cloud-provider=aws "+
+			"%s"+
 			"--kubernetes-version=%q "+
-			"--kubernetes-network-provider=weave "+
-			"--kubernetes-service-cidr=10.10.0.0/16 "+
-			"--kubernetes-pod-network-cidr=10.20.0.0/16 "+
+			"--kubernetes-network-provider=%q "+
+			"--kubernetes-service-cidr=%q "+
+			"--kubernetes-pod-network-cidr=%q "+
 			"--kubernetes-infrastructure-cidr=%q "+
 			"--kubernetes-api-server=%q "+
 			"--kubernetes-cluster-name=%q",
@@ -852,12 +1405,33 @@ func (c *EC2ClusterPKE) GetBootstrapCommand(nodePoolName, url, token string) (st
 			c.model.Cluster.OrganizationID,
 			c.model.Cluster.ID,
 			nodePoolName,
+			cloudProviderFlag,
 			version,
+			networkProvider,
+			serviceCIDR,
+			podCIDR,
 			infrastructureCIDR,
 			apiAddress,
 			c.GetName(),
 		)
 
+		networkProviderFlags, err := getNetworkProviderFlags(networkProvider, c.model.Network.ProviderConfig)
+		if err != nil {
+			return "", err
+		}
+		for _, flag := range networkProviderFlags {
+			command = fmt.Sprintf("%s %s", command, flag)
+		}
+
+		if highAvailability {
+			command = fmt.Sprintf("%s --kubernetes-master-mode=ha", command)
+
+			// additional masters join the control plane using the certificates of the cluster CA secret
+			if joinControlPlane {
+				command = fmt.Sprintf("%s --kubernetes-join-control-plane", command)
+			}
+		}
+
 		if c.model.DexEnabled {
 			dexIssuerURL := viper.GetString("auth.dexURL")
 			dexClientID := c.GetUID()
@@ -881,7 +1455,7 @@ func (c *EC2ClusterPKE) GetBootstrapCommand(nodePoolName, url, token string) (st
 		"--pipeline-org-id=%d "+
 		"--pipeline-cluster-id=%d "+
 		"--pipeline-nodepool=%q "+
-		"--kubernetes-cloud-provider=aws "+
+		"%s"+
 		"--kubernetes-version=%q "+
 		"--kubernetes-infrastructure-cidr=%q",
 		subcommand,
@@ -890,6 +1464,7 @@ func (c *EC2ClusterPKE) GetBootstrapCommand(nodePoolName, url, token string) (st
 		c.model.Cluster.OrganizationID,
 		c.model.Cluster.ID,
 		nodePoolName,
+		cloudProviderFlag,
 		version,
 		infrastructureCIDR,
 	), nil
@@ -1014,6 +1589,7 @@ func CreateEC2ClusterPKEFromModel(modelCluster *model.ClusterModel) (*EC2Cluster
 		Preload("Cluster").
 		Preload("Network").
 		Preload("NodePools").
+		Preload("NodePools.Hosts").
 		Preload("Kubernetes").
 		Preload("KubeADM").
 		Preload("CRI").
@@ -1096,12 +1672,51 @@ func createEC2PKENetworkFromRequest(network pke.Network, userId uint) internalPk
 		ServiceCIDR:      network.ServiceCIDR,
 		PodCIDR:          network.PodCIDR,
 		Provider:         convertNetworkProvider(network.Provider),
+		ProviderConfig:   internalPke.Config(network.ProviderConfig),
 		APIServerAddress: network.APIServerAddress,
 	}
 	n.CreatedBy = userId
 	return n
 }
 
+// getNetworkProviderFlags returns the PKE install flags of the network provider specific settings
+func getNetworkProviderFlags(provider internalPke.NetworkProvider, providerConfig internalPke.Config) ([]string, error) {
+	var flags []string
+	var mtu int
+
+	switch provider {
+	case internalPke.NPCalico:
+		config, err := pke.DecodeCalicoProviderConfig(providerConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		flags = append(flags,
+			fmt.Sprintf("--calico-ipip-mode=%q", config.IPIPMode),
+			fmt.Sprintf("--calico-block-size=%d", config.BlockSize),
+		)
+		mtu = config.MTU
+
+	case internalPke.NPCilium:
+		config, err := pke.DecodeCiliumProviderConfig(providerConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		flags = append(flags,
+			fmt.Sprintf("--cilium-tunnel=%q", config.Tunnel),
+			fmt.Sprintf("--kubernetes-node-cidr-mask-size=%d", config.NodeCIDRMaskSize),
+		)
+		mtu = config.MTU
+	}
+
+	if mtu > 0 {
+		flags = append(flags, fmt.Sprintf("--kubernetes-network-mtu=%d", mtu))
+	}
+
+	return flags, nil
+}
+
 func convertNetworkProvider(provider pke.NetworkProvider) (result internalPke.NetworkProvider) {
 	return internalPke.NetworkProvider(provider)
 }
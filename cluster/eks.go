@@ -90,6 +90,8 @@ func CreateEKSClusterFromRequest(request *pkgCluster.CreateClusterRequest, orgId
 			VpcCidr:      &request.Properties.CreateClusterEKS.Vpc.Cidr,
 			RouteTableId: &request.Properties.CreateClusterEKS.RouteTableId,
 			Subnets:      createSubnetsFromRequest(request.Properties.CreateClusterEKS.Subnets),
+
+			APIServerAccess: newAPIServerAccessModel(request.Properties.CreateClusterEKS.APIServerAccess),
 		},
 		CreatedBy:  userId,
 		TtlMinutes: request.TtlMinutes,
@@ -243,6 +245,7 @@ func (c *EKSCluster) CreateCluster() error {
 	}
 
 	creationContext.ScaleEnabled = c.GetScaleOptions() != nil && c.GetScaleOptions().Enabled
+	creationContext.PrivateNodes = c.modelCluster.EKS.APIServerAccess.PrivateNodes
 	ASGWaitLoopCount := int(viper.GetDuration(config.EksASGFulfillmentTimeout).Seconds() / asgWaitLoopSleepSeconds)
 	headNodePoolName := viper.GetString(config.PipelineHeadNodePoolName)
 
@@ -338,6 +341,19 @@ func (c *EKSCluster) CreateCluster() error {
 		return emperror.WrapWith(err, "failed to create config map", "configmap", awsAuthConfigMap.Name)
 	}
 
+	// The API server endpoint is restricted only after bootstrapping, as Pipeline
+	// reaches private endpoints through the tunnel agent from now on
+	apiServerAccess := c.modelCluster.EKS.APIServerAccess
+	if apiServerAccess.PrivateEndpoint || apiServerAccess.PublicAccessCIDRs != "" {
+		publicAccessCIDRs := getAPIServerAccessFromModel(apiServerAccess).GetPublicAccessCIDRs()
+		endpointAction := action.NewUpdateEksEndpointAccessAction(c.log, creationContext, true, !apiServerAccess.PrivateEndpoint, publicAccessCIDRs)
+
+		_, err = utils.NewActionExecutor(c.log).ExecuteActions([]utils.Action{endpointAction}, nil, false)
+		if err != nil {
+			return emperror.Wrap(err, "failed to restrict EKS API server endpoint access")
+		}
+	}
+
 	err = c.modelCluster.Save()
 	if err != nil {
 		return emperror.Wrap(err, "failed to persist cluster to database")
@@ -385,6 +401,11 @@ func (c *EKSCluster) GetDistribution() string {
 	return c.modelCluster.Distribution
 }
 
+// IsImported returns true if the cluster was not created by Pipeline
+func (c *EKSCluster) IsImported() bool {
+	return c.modelCluster.EKS.Imported
+}
+
 // DeleteCluster deletes cluster from EKS
 func (c *EKSCluster) DeleteCluster() error {
 	c.log.Info("Start delete EKS cluster")
@@ -412,14 +433,28 @@ func (c *EKSCluster) DeleteCluster() error {
 	nodePoolStackNames := c.getNodepoolStackNamesToDelete(session)
 	deleteNodePoolsAction := action.NewDeleteStacksAction(c.log, deleteContext, nodePoolStackNames...)
 
+	var adoptedAutoScalingGroups []string
+	for _, nodePool := range c.modelCluster.EKS.NodePools {
+		if nodePool.AutoScalingGroupName != "" {
+			adoptedAutoScalingGroups = append(adoptedAutoScalingGroups, nodePool.AutoScalingGroupName)
+		}
+	}
+
 	actions = append(actions,
 		deleteNodePoolsAction,
+		action.NewDeleteAdoptedNodePoolsAction(c.log, deleteContext, adoptedAutoScalingGroups...),
 		action.NewDeleteClusterAction(c.log, deleteContext),
 		action.NewDeleteSSHKeyAction(c.log, deleteContext, c.generateSSHKeyNameForCluster()),
 		action.NewDeleteClusterUserAccessKeyAction(c.log, deleteContext),
 		action.NewDeleteClusterUserAccessKeySecretAction(c.log, deleteContext, c.GetOrganizationId()),
-		action.NewDeleteStacksAction(c.log, deleteContext, c.generateStackNameForCluster()),
 	)
+
+	// imported clusters have no cluster stack, their user is created by Pipeline directly
+	if c.modelCluster.EKS.Imported {
+		actions = append(actions, action.NewDeleteClusterUserAction(c.log, deleteContext))
+	} else {
+		actions = append(actions, action.NewDeleteStacksAction(c.log, deleteContext, c.generateStackNameForCluster()))
+	}
 	_, err = utils.NewActionExecutor(c.log).ExecuteActions(actions, nil, false)
 	if err != nil {
 		c.log.Errorln("EKS cluster delete error:", err.Error())
@@ -434,6 +469,10 @@ func (c *EKSCluster) getNodepoolStackNamesToDelete(sess *session.Session) []stri
 	uniqueMap := make(map[string]bool, 0)
 
 	for _, nodePool := range c.modelCluster.EKS.NodePools {
+		if nodePool.AutoScalingGroupName != "" {
+			continue
+		}
+
 		nodePoolStackName := c.generateNodePoolStackName(nodePool)
 		stackNames = append(stackNames, nodePoolStackName)
 		uniqueMap[nodePoolStackName] = true
@@ -487,6 +526,8 @@ func (c *EKSCluster) createNodePoolsFromUpdateRequest(requestedNodePools map[str
 				NodeMaxCount:     nodePool.MaxCount,
 				Count:            nodePool.Count,
 				Delete:           false,
+
+				AutoScalingGroupName: currentNodePoolMap[nodePoolName].AutoScalingGroupName,
 			})
 
 		} else {
@@ -526,6 +567,10 @@ func (c *EKSCluster) createNodePoolsFromUpdateRequest(requestedNodePools map[str
 
 	for _, nodePool := range c.modelCluster.EKS.NodePools {
 		if requestedNodePools[nodePool.Name] == nil {
+			if nodePool.AutoScalingGroupName != "" {
+				return nil, emperror.With(pkgErrors.ErrorImportedNodePoolCannotBeDeleted, "nodePool", nodePool.Name)
+			}
+
 			updatedNodePools = append(updatedNodePools, &model.AmazonNodePoolsModel{
 				ID:        nodePool.ID,
 				ClusterID: nodePool.ClusterID,
@@ -559,29 +604,44 @@ func (c *EKSCluster) UpdateCluster(updateRequest *pkgCluster.UpdateClusterReques
 	var actions []utils.Action
 
 	clusterStackName := c.generateStackNameForCluster()
-	describeStacksInput := &cloudformation.DescribeStacksInput{StackName: aws.String(clusterStackName)}
 	cloudformationSrv := cloudformation.New(session)
 	autoscalingSrv := autoscaling.New(session)
-	describeStacksOutput, err := cloudformationSrv.DescribeStacks(describeStacksInput)
-	if err != nil {
-		return nil
-	}
 
 	var vpcId, subnetIds, securityGroupId, nodeSecurityGroupId, nodeInstanceRoleId, clusterUserArn, clusterUserAccessKeyId, clusterUserSecretAccessKey string
-	for _, output := range describeStacksOutput.Stacks[0].Outputs {
-		switch aws.StringValue(output.OutputKey) {
-		case "SecurityGroups":
-			securityGroupId = aws.StringValue(output.OutputValue)
-		case "NodeSecurityGroup":
-			nodeSecurityGroupId = aws.StringValue(output.OutputValue)
-		case "VpcId":
-			vpcId = aws.StringValue(output.OutputValue)
-		case "SubnetIds":
-			subnetIds = aws.StringValue(output.OutputValue)
-		case "NodeInstanceRoleId":
-			nodeInstanceRoleId = aws.StringValue(output.OutputValue)
-		case "ClusterUserArn":
-			clusterUserArn = aws.StringValue(output.OutputValue)
+	if c.modelCluster.EKS.Imported {
+		// imported clusters have no cluster stack, the values are discovered at import
+		vpcId = aws.StringValue(c.modelCluster.EKS.VpcId)
+		securityGroupId = aws.StringValue(c.modelCluster.EKS.SecurityGroupId)
+		nodeSecurityGroupId = aws.StringValue(c.modelCluster.EKS.NodeSecurityGroupId)
+		nodeInstanceRoleId = c.modelCluster.EKS.NodeInstanceRoleId
+
+		var subnets []string
+		for _, subnet := range c.modelCluster.EKS.Subnets {
+			subnets = append(subnets, aws.StringValue(subnet.SubnetId))
+		}
+		subnetIds = strings.Join(subnets, ",")
+	} else {
+		describeStacksInput := &cloudformation.DescribeStacksInput{StackName: aws.String(clusterStackName)}
+		describeStacksOutput, err := cloudformationSrv.DescribeStacks(describeStacksInput)
+		if err != nil {
+			return nil
+		}
+
+		for _, output := range describeStacksOutput.Stacks[0].Outputs {
+			switch aws.StringValue(output.OutputKey) {
+			case "SecurityGroups":
+				securityGroupId = aws.StringValue(output.OutputValue)
+			case "NodeSecurityGroup":
+				nodeSecurityGroupId = aws.StringValue(output.OutputValue)
+			case "VpcId":
+				vpcId = aws.StringValue(output.OutputValue)
+			case "SubnetIds":
+				subnetIds = aws.StringValue(output.OutputValue)
+			case "NodeInstanceRoleId":
+				nodeInstanceRoleId = aws.StringValue(output.OutputValue)
+			case "ClusterUserArn":
+				clusterUserArn = aws.StringValue(output.OutputValue)
+			}
 		}
 	}
 
@@ -611,6 +671,20 @@ func (c *EKSCluster) UpdateCluster(updateRequest *pkgCluster.UpdateClusterReques
 		return err
 	}
 
+	kubernetesVersion := c.modelCluster.EKS.Version
+	if len(updateRequest.EKS.Version) > 0 {
+		kubernetesVersion = updateRequest.EKS.Version
+	}
+	upgradeVersion := kubernetesVersion != c.modelCluster.EKS.Version
+	if upgradeVersion {
+		if err := pkgEks.ValidateVersionUpgrade(c.modelCluster.EKS.Version, kubernetesVersion); err != nil {
+			return err
+		}
+	}
+
+	// node images are replaced on version upgrades or when explicitly requested
+	updateImages := upgradeVersion || updateRequest.EKS.NodeImageUpdate != nil
+
 	createUpdateContext := action.NewEksClusterUpdateContext(
 		session,
 		c.modelCluster.Name,
@@ -626,6 +700,7 @@ func (c *EKSCluster) UpdateCluster(updateRequest *pkgCluster.UpdateClusterReques
 		clusterUserSecretAccessKey,
 	)
 	createUpdateContext.ScaleEnabled = c.GetScaleOptions() != nil && c.GetScaleOptions().Enabled
+	createUpdateContext.PrivateNodes = c.modelCluster.EKS.APIServerAccess.PrivateNodes
 
 	deleteContext := action.NewEksClusterDeleteContext(
 		session,
@@ -635,9 +710,38 @@ func (c *EKSCluster) UpdateCluster(updateRequest *pkgCluster.UpdateClusterReques
 	var nodePoolsToCreate []*model.AmazonNodePoolsModel
 	var nodePoolsToUpdate []*model.AmazonNodePoolsModel
 	var nodePoolsToDelete []string
+	var adoptedNodePoolsToUpdate []*model.AmazonNodePoolsModel
 
 	for _, nodePool := range modelNodePools {
 
+		// node pools adopted at cluster import are scaled through their auto scaling groups directly
+		if nodePool.AutoScalingGroupName != "" {
+			if updateImages {
+				c.log.Warnf("nodePool %v was not created by Pipeline, its image won't be updated", nodePool.Name)
+			}
+
+			if nodePool.Autoscaling {
+				group, err := autoscalingSrv.DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+					AutoScalingGroupNames: []*string{aws.String(nodePool.AutoScalingGroupName)},
+				})
+				if err != nil {
+					return emperror.WrapWith(err, "could not describe auto scaling group", "nodePool", nodePool.Name)
+				}
+				if len(group.AutoScalingGroups) > 0 && group.AutoScalingGroups[0].DesiredCapacity != nil {
+					nodePool.Count = int(*group.AutoScalingGroups[0].DesiredCapacity)
+				}
+				if nodePool.Count < nodePool.NodeMinCount {
+					nodePool.Count = nodePool.NodeMinCount
+				}
+				if nodePool.Count > nodePool.NodeMaxCount {
+					nodePool.Count = nodePool.NodeMaxCount
+				}
+			}
+
+			adoptedNodePoolsToUpdate = append(adoptedNodePoolsToUpdate, nodePool)
+			continue
+		}
+
 		stackName := c.generateNodePoolStackName(nodePool)
 		describeStacksInput := &cloudformation.DescribeStacksInput{StackName: aws.String(stackName)}
 		describeStacksOutput, err := cloudformationSrv.DescribeStacks(describeStacksInput)
@@ -661,6 +765,10 @@ func (c *EKSCluster) UpdateCluster(updateRequest *pkgCluster.UpdateClusterReques
 					nodePool.NodeSpotPrice = *param.ParameterValue
 				}
 			}
+			if updateImages {
+				nodePool.NodeImage = c.selectNodeImage(updateRequest.EKS.NodePools[nodePool.Name].Image, kubernetesVersion)
+				c.log.Infof("nodePool %v will be updated to image %v", nodePool.Name, nodePool.NodeImage)
+			}
 			// get current Desired count from ASG linked to nodeGroup stack if Autoscaling is enabled, as we don't to override
 			// in this case only min/max counts
 			group, err := getAutoScalingGroup(cloudformationSrv, autoscalingSrv, stackName)
@@ -703,19 +811,97 @@ func (c *EKSCluster) UpdateCluster(updateRequest *pkgCluster.UpdateClusterReques
 	createNodePoolAction := action.NewCreateUpdateNodePoolStackAction(c.log, true, createUpdateContext, ASGWaitLoopCount, asgWaitLoopSleepSeconds*time.Second, headNodePoolName, nodePoolsToCreate...)
 	updateNodePoolAction := action.NewCreateUpdateNodePoolStackAction(c.log, false, createUpdateContext, ASGWaitLoopCount, asgWaitLoopSleepSeconds*time.Second, headNodePoolName, nodePoolsToUpdate...)
 
+	// the control plane has to be upgraded before the nodes
+	if upgradeVersion {
+		actions = append(actions, action.NewUpdateEksControlPlaneVersionAction(c.log, createUpdateContext, kubernetesVersion))
+	}
+
 	actions = append(actions, createNodePoolAction, updateNodePoolAction, deleteNodePoolAction)
 
+	if len(adoptedNodePoolsToUpdate) > 0 {
+		actions = append(actions, action.NewUpdateAdoptedNodePoolsAction(c.log, createUpdateContext, ASGWaitLoopCount, asgWaitLoopSleepSeconds*time.Second, adoptedNodePoolsToUpdate...))
+	}
+
+	// any change of the launch configuration (not only the image) leaves the running instances outdated,
+	// they are replaced with drained rolling updates, which is a no-op for up to date node pools
+	if len(nodePoolsToUpdate) > 0 {
+		rollNodePoolAction, err := c.newRollNodePoolInstancesAction(createUpdateContext, updateRequest.EKS.NodeImageUpdate, ASGWaitLoopCount, nodePoolsToUpdate)
+		if err != nil {
+			return err
+		}
+
+		actions = append(actions, rollNodePoolAction)
+	}
+
 	_, err = utils.NewActionExecutor(c.log).ExecuteActions(actions, nil, false)
 	if err != nil {
 		c.log.Errorln("EKS cluster update error:", err.Error())
 		return err
 	}
 
+	c.modelCluster.EKS.Version = kubernetesVersion
 	c.modelCluster.EKS.NodePools = modelNodePools
 
 	return nil
 }
 
+// selectNodeImage returns the image of a node pool for the given Kubernetes version.
+// Custom images are kept, default images are replaced with the default image of the version.
+func (c *EKSCluster) selectNodeImage(requestedImage, kubernetesVersion string) string {
+	location := c.modelCluster.Location
+
+	if len(requestedImage) > 0 && requestedImage != pkgEks.DefaultImages[c.modelCluster.EKS.Version][location] {
+		return requestedImage
+	}
+
+	images, err := ListEksImages(kubernetesVersion, location)
+	if err != nil || len(images[location]) == 0 {
+		c.log.Warnf("no default image found for version %v in %v", kubernetesVersion, location)
+		return requestedImage
+	}
+
+	return images[location][0]
+}
+
+// newRollNodePoolInstancesAction creates an action which replaces the outdated instances of the given node pools
+func (c *EKSCluster) newRollNodePoolInstancesAction(
+	updateContext *action.EksClusterCreateUpdateContext,
+	nodeImageUpdate *pkgEks.NodeImageUpdate,
+	waitAttempts int,
+	nodePools []*model.AmazonNodePoolsModel,
+) (*action.RollNodePoolInstancesAction, error) {
+	kubeConfig, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to retrieve K8S config")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to create K8S client")
+	}
+
+	surge := pkgEks.DefaultNodeImageUpdateSurge
+	if nodeImageUpdate != nil && nodeImageUpdate.Surge > 0 {
+		surge = nodeImageUpdate.Surge
+	}
+
+	nodePoolNames := make([]string, 0, len(nodePools))
+	for _, nodePool := range nodePools {
+		nodePoolNames = append(nodePoolNames, nodePool.Name)
+	}
+
+	return action.NewRollNodePoolInstancesAction(
+		c.log,
+		updateContext,
+		client,
+		surge,
+		viper.GetDuration(config.EksNodeDrainTimeout),
+		waitAttempts,
+		asgWaitLoopSleepSeconds*time.Second,
+		nodePoolNames...,
+	), nil
+}
+
 // UpdateNodePools updates nodes pools of a cluster
 func (c *EKSCluster) UpdateNodePools(request *pkgCluster.UpdateNodePoolsRequest, userId uint) error {
 	c.log.Info("Start updating nodepools")
@@ -766,6 +952,12 @@ func (c *EKSCluster) UpdateNodePools(request *pkgCluster.UpdateNodePoolsRequest,
 
 		waitRoutines++
 		go func(poolName string) {
+			if nodePoolModel := c.getNodePoolByName(poolName); nodePoolModel != nil && nodePoolModel.AutoScalingGroupName != "" {
+				waitChan <- action.WaitForAdoptedASGToBeFulfilled(session, c.log, nodePoolModel,
+					ASGWaitLoopCount, asgWaitLoopSleepSeconds*time.Second)
+				return
+			}
+
 			waitChan <- action.WaitForASGToBeFulfilled(session, c.log, c.modelCluster.Name,
 				poolName, ASGWaitLoopCount, asgWaitLoopSleepSeconds*time.Second)
 		}(poolName)
@@ -807,6 +999,10 @@ func getAutoScalingGroup(cloudformationSrv *cloudformation.CloudFormation, autos
 }
 
 func (c *EKSCluster) getAutoScalingGroupName(cloudformationSrv *cloudformation.CloudFormation, autoscalingSrv *autoscaling.AutoScaling, nodePoolName string) (*string, error) {
+	if nodePool := c.getNodePoolByName(nodePoolName); nodePool != nil && nodePool.AutoScalingGroupName != "" {
+		return aws.String(nodePool.AutoScalingGroupName), nil
+	}
+
 	logResourceId := "NodeGroup"
 	stackName := action.GenerateNodePoolStackName(c.modelCluster.Name, nodePoolName)
 	describeStackResourceInput := &cloudformation.DescribeStackResourceInput{
@@ -976,9 +1172,16 @@ func (c *EKSCluster) CheckEqualityToUpdate(r *pkgCluster.UpdateClusterRequest) e
 	}
 
 	preCl := &pkgEks.UpdateClusterAmazonEKS{
+		Version:   c.modelCluster.EKS.Version,
 		NodePools: preNodePools,
 	}
 
+	if r.EKS != nil && r.EKS.Version != preCl.Version {
+		if err := pkgEks.ValidateVersionUpgrade(preCl.Version, r.EKS.Version); err != nil {
+			return err
+		}
+	}
+
 	log.Info("Check stored & updated cluster equals")
 
 	// check equality
@@ -987,10 +1190,19 @@ func (c *EKSCluster) CheckEqualityToUpdate(r *pkgCluster.UpdateClusterRequest) e
 
 // AddDefaultsToUpdate adds defaults to update request
 func (c *EKSCluster) AddDefaultsToUpdate(r *pkgCluster.UpdateClusterRequest) {
-	defaultImage := pkgEks.DefaultImages[c.modelCluster.EKS.Version][c.modelCluster.Location]
+	if r == nil || r.EKS == nil {
+		return
+	}
+
+	// keep the current Kubernetes version if not provided
+	if len(r.EKS.Version) == 0 {
+		r.EKS.Version = c.modelCluster.EKS.Version
+	}
+
+	defaultImage := pkgEks.DefaultImages[r.EKS.Version][c.modelCluster.Location]
 
 	// add default node image(s) if needed
-	if r != nil && r.EKS != nil && r.EKS.NodePools != nil {
+	if r.EKS.NodePools != nil {
 		for _, np := range r.EKS.NodePools {
 			if len(np.Image) == 0 {
 				np.Image = defaultImage
@@ -1011,7 +1223,11 @@ func (c *EKSCluster) DeleteFromDatabase() error {
 
 // ListNodeNames returns node names to label them
 func (c *EKSCluster) ListNodeNames() (nodeNames pkgCommon.NodeNames, err error) {
-	// nodes are labeled in create request
+	// nodes are labeled in create request, except the ones of node pools adopted at cluster import
+	if c.modelCluster.EKS.Imported {
+		return c.listAdoptedNodePoolNodeNames()
+	}
+
 	return
 }
 
@@ -1021,6 +1237,16 @@ func (c *EKSCluster) UpdateStatus(status string, statusMessage string) error {
 }
 
 // NodePoolExists returns true if node pool with nodePoolName exists
+// getNodePoolByName returns saved NodePool by name
+func (c *EKSCluster) getNodePoolByName(name string) *model.AmazonNodePoolsModel {
+	for _, np := range c.modelCluster.EKS.NodePools {
+		if np != nil && np.Name == name {
+			return np
+		}
+	}
+	return nil
+}
+
 func (c *EKSCluster) NodePoolExists(nodePoolName string) bool {
 	for _, np := range c.modelCluster.EKS.NodePools {
 		if np != nil && np.Name == nodePoolName {
@@ -1245,7 +1471,12 @@ func (c *EKSCluster) GetK8sIpv4Cidrs() (*pkgCluster.Ipv4Cidrs, error) {
 
 // GetK8sConfig returns the Kubernetes config
 func (c *EKSCluster) GetK8sConfig() ([]byte, error) {
-	return c.CommonClusterBase.getConfig(c)
+	config, err := c.CommonClusterBase.getConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, registerAPIServerTunnel(config, c.modelCluster.EKS.APIServerAccess)
 }
 
 // RequiresSshPublicKey returns true as a public ssh key is needed for bootstrapping
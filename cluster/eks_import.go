@@ -0,0 +1,435 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/banzaicloud/pipeline/model"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/cluster/eks/action"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/banzaicloud/pipeline/utils"
+	"github.com/goph/emperror"
+	v1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const eksctlNodeGroupNameTag = "alpha.eksctl.io/nodegroup-name"
+
+// CreateEKSClusterFromImportRequest creates the model of an existing EKS cluster to be imported
+func CreateEKSClusterFromImportRequest(request *pkgCluster.ImportClusterRequest, orgId uint, userId uint) (*EKSCluster, error) {
+	cluster := EKSCluster{
+		log: log.WithField("cluster", request.Name),
+	}
+
+	cluster.modelCluster = &model.ClusterModel{
+		Name:           request.Name,
+		Location:       request.Location,
+		Cloud:          request.Cloud,
+		OrganizationId: orgId,
+		SecretId:       request.SecretId,
+		Distribution:   pkgCluster.EKS,
+		RbacEnabled:    true,
+		EKS: model.EKSClusterModel{
+			Imported: true,
+		},
+		CreatedBy: userId,
+	}
+
+	return &cluster, nil
+}
+
+func (c *EKSCluster) newSession() (*session.Session, error) {
+	awsCred, err := c.createAWSCredentialsFromSecret()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to retrieve AWS credentials from secret")
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(c.modelCluster.Location),
+		Credentials: awsCred,
+	})
+
+	return sess, emperror.Wrap(err, "failed to create AWS session")
+}
+
+// DiscoverCluster populates the model of an imported cluster from the EKS cluster and the auto scaling groups of its nodes
+func (c *EKSCluster) DiscoverCluster() error {
+	sess, err := c.newSession()
+	if err != nil {
+		return err
+	}
+
+	clusterInfo, err := eks.New(sess).DescribeCluster(&eks.DescribeClusterInput{
+		Name: aws.String(c.modelCluster.Name),
+	})
+	if err != nil {
+		return emperror.WrapWith(err, "could not describe EKS cluster", "cluster", c.modelCluster.Name)
+	}
+
+	eksCluster := clusterInfo.Cluster
+	if aws.StringValue(eksCluster.Status) != eks.ClusterStatusActive {
+		return pkgErrors.ErrorImportedClusterNotRunning
+	}
+
+	c.modelCluster.EKS.Version = aws.StringValue(eksCluster.Version)
+	c.APIEndpoint = aws.StringValue(eksCluster.Endpoint)
+	c.CertificateAuthorityData, err = base64.StdEncoding.DecodeString(aws.StringValue(eksCluster.CertificateAuthority.Data))
+	if err != nil {
+		return emperror.Wrap(err, "failed to base64 decode EKS K8S certificate authority data")
+	}
+
+	vpcConfig := eksCluster.ResourcesVpcConfig
+	c.modelCluster.EKS.VpcId = vpcConfig.VpcId
+	if len(vpcConfig.SecurityGroupIds) > 0 {
+		c.modelCluster.EKS.SecurityGroupId = vpcConfig.SecurityGroupIds[0]
+	}
+
+	c.modelCluster.EKS.Subnets = nil
+	for _, subnetID := range vpcConfig.SubnetIds {
+		c.modelCluster.EKS.Subnets = append(c.modelCluster.EKS.Subnets, &model.EKSSubnetModel{SubnetId: subnetID})
+	}
+
+	vpcs, err := ec2.New(sess).DescribeVpcs(&ec2.DescribeVpcsInput{VpcIds: []*string{vpcConfig.VpcId}})
+	if err != nil {
+		return emperror.WrapWith(err, "could not describe VPC", "vpcId", aws.StringValue(vpcConfig.VpcId))
+	}
+	if len(vpcs.Vpcs) > 0 {
+		c.modelCluster.EKS.VpcCidr = vpcs.Vpcs[0].CidrBlock
+	}
+
+	return c.discoverNodePools(sess)
+}
+
+// discoverNodePools adopts the auto scaling groups owned by the cluster as node pools
+func (c *EKSCluster) discoverNodePools(sess *session.Session) error {
+	asSvc := autoscaling.New(sess)
+	clusterTag := "kubernetes.io/cluster/" + c.modelCluster.Name
+
+	var groups []*autoscaling.Group
+	err := asSvc.DescribeAutoScalingGroupsPages(&autoscaling.DescribeAutoScalingGroupsInput{},
+		func(page *autoscaling.DescribeAutoScalingGroupsOutput, lastPage bool) bool {
+			for _, group := range page.AutoScalingGroups {
+				if getAutoScalingGroupTag(group, clusterTag) == "owned" {
+					groups = append(groups, group)
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return emperror.Wrap(err, "could not list auto scaling groups")
+	}
+
+	c.modelCluster.EKS.NodePools = nil
+	for _, group := range groups {
+		asgName := aws.StringValue(group.AutoScalingGroupName)
+
+		if group.LaunchConfigurationName == nil {
+			c.log.Warnf("auto scaling group %s has no launch configuration, it is not adopted as a node pool", asgName)
+			continue
+		}
+
+		launchConfigs, err := asSvc.DescribeLaunchConfigurations(&autoscaling.DescribeLaunchConfigurationsInput{
+			LaunchConfigurationNames: []*string{group.LaunchConfigurationName},
+		})
+		if err != nil {
+			return emperror.WrapWith(err, "could not describe launch configuration", "asgName", asgName)
+		}
+		if len(launchConfigs.LaunchConfigurations) == 0 {
+			c.log.Warnf("launch configuration of auto scaling group %s not found, it is not adopted as a node pool", asgName)
+			continue
+		}
+		launchConfig := launchConfigs.LaunchConfigurations[0]
+
+		// new node pools are created with the security group and role of the existing nodes
+		if c.modelCluster.EKS.NodeSecurityGroupId == nil && len(launchConfig.SecurityGroups) > 0 {
+			c.modelCluster.EKS.NodeSecurityGroupId = launchConfig.SecurityGroups[0]
+		}
+		if c.modelCluster.EKS.NodeInstanceRoleId == "" && launchConfig.IamInstanceProfile != nil {
+			roleName, err := getInstanceProfileRoleName(iam.New(sess), aws.StringValue(launchConfig.IamInstanceProfile))
+			if err != nil {
+				return err
+			}
+			c.modelCluster.EKS.NodeInstanceRoleId = roleName
+		}
+
+		_, autoscalingEnabled := getAutoScalingGroupTagOk(group, "k8s.io/cluster-autoscaler/enabled")
+
+		c.modelCluster.EKS.NodePools = append(c.modelCluster.EKS.NodePools, &model.AmazonNodePoolsModel{
+			CreatedBy:            c.modelCluster.CreatedBy,
+			Name:                 c.adoptedNodePoolName(group),
+			NodeSpotPrice:        aws.StringValue(launchConfig.SpotPrice),
+			Autoscaling:          autoscalingEnabled,
+			NodeMinCount:         int(aws.Int64Value(group.MinSize)),
+			NodeMaxCount:         int(aws.Int64Value(group.MaxSize)),
+			Count:                int(aws.Int64Value(group.DesiredCapacity)),
+			NodeImage:            aws.StringValue(launchConfig.ImageId),
+			NodeInstanceType:     aws.StringValue(launchConfig.InstanceType),
+			AutoScalingGroupName: asgName,
+		})
+	}
+
+	return nil
+}
+
+// adoptedNodePoolName names the node pool of an auto scaling group after its node group,
+// falling back to the name of the group
+func (c *EKSCluster) adoptedNodePoolName(group *autoscaling.Group) string {
+	name := getAutoScalingGroupTag(group, eksctlNodeGroupNameTag)
+	if name == "" {
+		// node groups created from the AWS templates are named <cluster>-<node group>-Node
+		name = getAutoScalingGroupTag(group, "Name")
+		if !strings.HasPrefix(name, c.modelCluster.Name+"-") {
+			name = ""
+		}
+		name = strings.TrimSuffix(strings.TrimPrefix(name, c.modelCluster.Name+"-"), "-Node")
+	}
+
+	if name == "" || c.NodePoolExists(name) {
+		name = aws.StringValue(group.AutoScalingGroupName)
+	}
+
+	return name
+}
+
+func getAutoScalingGroupTagOk(group *autoscaling.Group, key string) (string, bool) {
+	for _, tag := range group.Tags {
+		if aws.StringValue(tag.Key) == key {
+			return aws.StringValue(tag.Value), true
+		}
+	}
+
+	return "", false
+}
+
+func getAutoScalingGroupTag(group *autoscaling.Group, key string) string {
+	value, _ := getAutoScalingGroupTagOk(group, key)
+	return value
+}
+
+// getInstanceProfileRoleName returns the name of the role of an instance profile given by its name or ARN
+func getInstanceProfileRoleName(iamSvc *iam.IAM, instanceProfile string) (string, error) {
+	if profileARN, err := arn.Parse(instanceProfile); err == nil {
+		instanceProfile = profileARN.Resource[strings.LastIndex(profileARN.Resource, "/")+1:]
+	}
+
+	profile, err := iamSvc.GetInstanceProfile(&iam.GetInstanceProfileInput{
+		InstanceProfileName: aws.String(instanceProfile),
+	})
+	if err != nil {
+		return "", emperror.WrapWith(err, "could not get instance profile", "instanceProfile", instanceProfile)
+	}
+
+	if len(profile.InstanceProfile.Roles) == 0 {
+		return "", nil
+	}
+
+	return aws.StringValue(profile.InstanceProfile.Roles[0].RoleName), nil
+}
+
+// ImportCluster gives Pipeline its own user on the imported EKS cluster
+func (c *EKSCluster) ImportCluster() error {
+	c.log.Info("Start importing EKS cluster")
+
+	sess, err := c.newSession()
+	if err != nil {
+		return err
+	}
+
+	sshSecret, err := c.getSshSecret(c)
+	if err != nil {
+		return emperror.Wrap(err, "failed to get ssh secret")
+	}
+
+	importContext := action.NewEksClusterCreationContext(sess, c.modelCluster.Name, c.generateSSHKeyNameForCluster(), "")
+
+	actions := []utils.Action{
+		action.NewCreateClusterUserAction(c.log, importContext),
+		action.NewCreateClusterUserAccessKeyAction(c.log, importContext),
+		action.NewPersistClusterUserAccessKeyAction(c.log, importContext, c.GetOrganizationId()),
+		action.NewUploadSSHKeyAction(c.log, importContext, sshSecret),
+	}
+
+	_, err = utils.NewActionExecutor(c.log).ExecuteActions(actions, nil, true)
+	if err != nil {
+		return emperror.Wrap(err, "failed to create EKS cluster user")
+	}
+
+	// the cluster user is authorized with the credentials of the organization,
+	// which are supposed to be granted access to the cluster already
+	awsCred, err := c.createAWSCredentialsFromSecret()
+	if err != nil {
+		return err
+	}
+	bootstrapCredentials, err := awsCred.Get()
+	if err != nil {
+		return emperror.Wrap(err, "failed to retrieve AWS credentials")
+	}
+	c.awsAccessKeyID = bootstrapCredentials.AccessKeyID
+	c.awsSecretAccessKey = bootstrapCredentials.SecretAccessKey
+
+	defer func() {
+		c.awsAccessKeyID = importContext.ClusterUserAccessKeyId
+		c.awsSecretAccessKey = importContext.ClusterUserSecretAccessKey
+		// AWS needs some time to distribute the access key to every service
+		time.Sleep(15 * time.Second)
+	}()
+
+	kubeConfig, err := c.DownloadK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "failed to retrieve K8S config")
+	}
+
+	if err := registerAPIServerTunnel(kubeConfig, c.modelCluster.EKS.APIServerAccess); err != nil {
+		return err
+	}
+
+	kubeClient, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to create K8S client")
+	}
+
+	if err := addUserToAWSAuthConfigMap(kubeClient, importContext.ClusterUserArn, c.modelCluster.Name); err != nil {
+		return err
+	}
+
+	err = c.modelCluster.Save()
+	if err != nil {
+		return emperror.Wrap(err, "failed to persist cluster to database")
+	}
+
+	c.log.Info("EKS cluster imported.")
+
+	return nil
+}
+
+// addUserToAWSAuthConfigMap maps an IAM user to a cluster admin in the aws-auth ConfigMap
+// See: https://docs.aws.amazon.com/eks/latest/userguide/add-user-role.html
+func addUserToAWSAuthConfigMap(client kubernetes.Interface, userArn, userName string) error {
+	configMaps := client.CoreV1().ConfigMaps("kube-system")
+	mapUser := fmt.Sprintf(mapUsersTemplate, userArn, userName)
+
+	awsAuthConfigMap, err := configMaps.Get("aws-auth", metav1.GetOptions{})
+	if k8sapierrors.IsNotFound(err) {
+		_, err = configMaps.Create(&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "aws-auth"},
+			Data: map[string]string{
+				"mapUsers": mapUser,
+			},
+		})
+
+		return emperror.Wrap(err, "failed to create aws-auth config map")
+	}
+	if err != nil {
+		return emperror.Wrap(err, "failed to get aws-auth config map")
+	}
+
+	if strings.Contains(awsAuthConfigMap.Data["mapUsers"], userArn) {
+		return nil
+	}
+
+	if awsAuthConfigMap.Data == nil {
+		awsAuthConfigMap.Data = make(map[string]string)
+	}
+	mapUsers := awsAuthConfigMap.Data["mapUsers"]
+	if mapUsers != "" && !strings.HasSuffix(mapUsers, "\n") {
+		mapUsers += "\n"
+	}
+	awsAuthConfigMap.Data["mapUsers"] = mapUsers + mapUser
+
+	_, err = configMaps.Update(awsAuthConfigMap)
+
+	return emperror.Wrap(err, "failed to update aws-auth config map")
+}
+
+// listAdoptedNodePoolNodeNames returns the names of the nodes of the node pools adopted at cluster import
+func (c *EKSCluster) listAdoptedNodePoolNodeNames() (pkgCommon.NodeNames, error) {
+	asgNames := make(map[string]string)
+	for _, nodePool := range c.modelCluster.EKS.NodePools {
+		if nodePool.AutoScalingGroupName != "" {
+			asgNames[nodePool.AutoScalingGroupName] = nodePool.Name
+		}
+	}
+
+	nodeNames := make(pkgCommon.NodeNames)
+	if len(asgNames) == 0 {
+		return nodeNames, nil
+	}
+
+	sess, err := c.newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	kubeConfig, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	kubeClient, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to create K8S client")
+	}
+
+	nodes, err := kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list nodes")
+	}
+
+	// provider IDs look like aws:///<zone>/<instance id>
+	instanceNodeNames := make(map[string]string, len(nodes.Items))
+	for _, node := range nodes.Items {
+		providerID := node.Spec.ProviderID
+		instanceNodeNames[providerID[strings.LastIndex(providerID, "/")+1:]] = node.Name
+	}
+
+	var groupNames []*string
+	for asgName := range asgNames {
+		groupNames = append(groupNames, aws.String(asgName))
+	}
+
+	groups, err := autoscaling.New(sess).DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: groupNames,
+	})
+	if err != nil {
+		return nil, emperror.Wrap(err, "could not describe auto scaling groups")
+	}
+
+	for _, group := range groups.AutoScalingGroups {
+		nodePoolName := asgNames[aws.StringValue(group.AutoScalingGroupName)]
+		for _, instance := range group.Instances {
+			if nodeName, ok := instanceNodeNames[aws.StringValue(instance.InstanceId)]; ok {
+				nodeNames[nodePoolName] = append(nodeNames[nodePoolName], nodeName)
+			}
+		}
+	}
+
+	return nodeNames, nil
+}
@@ -0,0 +1,87 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAddUserToAWSAuthConfigMap(t *testing.T) {
+	const userArn = "arn:aws:iam::123456789012:user/imported"
+
+	nodeRoles := "- rolearn: arn:aws:iam::123456789012:role/nodes\n"
+	existingUser := fmt.Sprintf(mapUsersTemplate, "arn:aws:iam::123456789012:user/admin", "admin")
+
+	tests := map[string]struct {
+		existing *v1.ConfigMap
+		expected string
+	}{
+		"missing config map": {
+			expected: fmt.Sprintf(mapUsersTemplate, userArn, "imported"),
+		},
+		"no users mapped": {
+			existing: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "aws-auth", Namespace: "kube-system"},
+				Data:       map[string]string{"mapRoles": nodeRoles},
+			},
+			expected: fmt.Sprintf(mapUsersTemplate, userArn, "imported"),
+		},
+		"users mapped": {
+			existing: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "aws-auth", Namespace: "kube-system"},
+				Data:       map[string]string{"mapRoles": nodeRoles, "mapUsers": existingUser},
+			},
+			expected: existingUser + fmt.Sprintf(mapUsersTemplate, userArn, "imported"),
+		},
+		"user already mapped": {
+			existing: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "aws-auth", Namespace: "kube-system"},
+				Data:       map[string]string{"mapUsers": fmt.Sprintf(mapUsersTemplate, userArn, "imported")},
+			},
+			expected: fmt.Sprintf(mapUsersTemplate, userArn, "imported"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			if test.existing != nil {
+				client = fake.NewSimpleClientset(test.existing)
+			}
+
+			if err := addUserToAWSAuthConfigMap(client, userArn, "imported"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			configMap, err := client.CoreV1().ConfigMaps("kube-system").Get("aws-auth", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if actual := configMap.Data["mapUsers"]; actual != test.expected {
+				t.Errorf("unexpected mapUsers\nexpected: %q\nactual:   %q", test.expected, actual)
+			}
+
+			if test.existing != nil && configMap.Data["mapRoles"] != test.existing.Data["mapRoles"] {
+				t.Error("mapRoles should be kept")
+			}
+		})
+	}
+}
@@ -31,6 +31,7 @@ import (
 	pkgClusterGoogle "github.com/banzaicloud/pipeline/pkg/cluster/gke"
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
 	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
 	pkgProviderGoogle "github.com/banzaicloud/pipeline/pkg/providers/google"
 	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
 	"github.com/banzaicloud/pipeline/secret"
@@ -75,6 +76,8 @@ const (
 	kubernetesIO   = "kubernetes.io"
 	targetPrefix   = "gke-"
 	clusterNameKey = "cluster-name"
+
+	gkeNodePoolLabelKey = "cloud.google.com/gke-nodepool"
 )
 
 // CreateGKEClusterFromRequest creates ClusterModel struct from the request
@@ -112,6 +115,9 @@ func CreateGKEClusterFromRequest(request *pkgCluster.CreateClusterRequest, orgID
 		ProjectId:     request.Properties.CreateClusterGKE.ProjectId,
 		Vpc:           request.Properties.CreateClusterGKE.Vpc,
 		Subnet:        request.Properties.CreateClusterGKE.Subnet,
+
+		APIServerAccess: newAPIServerAccessModel(request.Properties.CreateClusterGKE.APIServerAccess),
+		MasterIPv4CIDR:  request.Properties.CreateClusterGKE.MasterIPv4CIDR,
 	}
 
 	updateScaleOptions(&c.model.Cluster.ScaleOptions, request.ScaleOptions)
@@ -290,6 +296,11 @@ func (c *GKECluster) CreateCluster() error {
 		Network:       c.model.Vpc,
 		SubNetwork:    c.model.Subnet,
 		NodePools:     nodePools,
+
+		PrivateEndpoint:   c.model.APIServerAccess.PrivateEndpoint,
+		PrivateNodes:      c.model.APIServerAccess.PrivateNodes,
+		MasterIPv4CIDR:    c.model.MasterIPv4CIDR,
+		PublicAccessCIDRs: getAPIServerAccessFromModel(c.model.APIServerAccess).GetPublicAccessCIDRs(),
 	}
 
 	ccr := generateClusterCreateRequest(cc)
@@ -382,6 +393,11 @@ func (c *GKECluster) GetDistribution() string {
 	return c.model.Cluster.Distribution
 }
 
+// IsImported returns true if the cluster was not created by Pipeline
+func (c *GKECluster) IsImported() bool {
+	return c.model.Imported
+}
+
 //GetStatus gets cluster status
 func (c *GKECluster) GetStatus() (*pkgCluster.GetClusterStatusResponse, error) {
 	c.log.Info("Create cluster status response")
@@ -863,6 +879,14 @@ type googleCluster struct {
 	ImageType string
 	// The node pools the cluster's nodes are created from
 	NodePools []*gke.NodePool
+	// The master is only reachable on its private endpoint
+	PrivateEndpoint bool
+	// The nodes have no public IP addresses
+	PrivateNodes bool
+	// The IP range of the master network of private clusters
+	MasterIPv4CIDR string
+	// The CIDR blocks the master is reachable from
+	PublicAccessCIDRs []string
 }
 
 func generateClusterCreateRequest(cc googleCluster) *gke.CreateClusterRequest {
@@ -892,6 +916,30 @@ func generateClusterCreateRequest(cc googleCluster) *gke.CreateClusterRequest {
 	request.Cluster.NodePools = cc.NodePools
 	request.ProjectId = cc.ProjectID
 
+	// private clusters have to be VPC-native
+	if cc.PrivateNodes {
+		request.Cluster.IpAllocationPolicy = &gke.IPAllocationPolicy{
+			UseIpAliases: true,
+		}
+		request.Cluster.PrivateClusterConfig = &gke.PrivateClusterConfig{
+			EnablePrivateNodes:    true,
+			EnablePrivateEndpoint: cc.PrivateEndpoint,
+			MasterIpv4CidrBlock:   cc.MasterIPv4CIDR,
+		}
+	}
+
+	if len(cc.PublicAccessCIDRs) > 0 {
+		request.Cluster.MasterAuthorizedNetworksConfig = &gke.MasterAuthorizedNetworksConfig{
+			Enabled: true,
+		}
+		for _, cidr := range cc.PublicAccessCIDRs {
+			request.Cluster.MasterAuthorizedNetworksConfig.CidrBlocks = append(
+				request.Cluster.MasterAuthorizedNetworksConfig.CidrBlocks,
+				&gke.CidrBlock{CidrBlock: cidr},
+			)
+		}
+	}
+
 	return &request
 }
 
@@ -2178,7 +2226,12 @@ func (c *GKECluster) GetK8sIpv4Cidrs() (*pkgCluster.Ipv4Cidrs, error) {
 
 // GetK8sConfig returns the Kubernetes config
 func (c *GKECluster) GetK8sConfig() ([]byte, error) {
-	return c.CommonClusterBase.getConfig(c)
+	config, err := c.CommonClusterBase.getConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, registerAPIServerTunnel(config, c.model.APIServerAccess)
 }
 
 func waitForOperation(getter operationInfoer, operationName string, logger logrus.FieldLogger) error {
@@ -2206,8 +2259,33 @@ func waitForOperation(getter operationInfoer, operationName string, logger logru
 
 // ListNodeNames returns node names to label them
 func (c *GKECluster) ListNodeNames() (nodeNames pkgCommon.NodeNames, err error) {
-	// nodes are labeled in create request
-	return
+	// nodes are labeled in create request, but the nodes of imported clusters have only the GKE node pool label
+	kubeConfig, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to create K8S client")
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: gkeNodePoolLabelKey})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list nodes")
+	}
+
+	nodeNames = make(pkgCommon.NodeNames)
+	for _, node := range nodes.Items {
+		if _, ok := node.Labels[pkgCommon.LabelKey]; ok {
+			continue
+		}
+
+		nodePoolName := node.Labels[gkeNodePoolLabelKey]
+		nodeNames[nodePoolName] = append(nodeNames[nodePoolName], node.Name)
+	}
+
+	return nodeNames, nil
 }
 
 // RbacEnabled returns true if rbac enabled on the cluster
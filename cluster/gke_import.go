@@ -0,0 +1,118 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"strings"
+
+	pipConfig "github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/providers/google"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/goph/emperror"
+)
+
+// CreateGKEClusterFromImportRequest creates the model of an existing GKE cluster to be imported
+func CreateGKEClusterFromImportRequest(request *pkgCluster.ImportClusterRequest, orgID uint, userID uint) (*GKECluster, error) {
+	c := GKECluster{
+		log: log.WithField("cluster", request.Name),
+	}
+
+	var err error
+	c.repository, err = NewDBGKEClusterRepository(pipConfig.DB())
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to create GKE cluster repository")
+	}
+
+	c.model = &google.GKEClusterModel{
+		Cluster: cluster.ClusterModel{
+			Name:           request.Name,
+			Location:       request.Location,
+			OrganizationID: orgID,
+			SecretID:       request.SecretId,
+			Cloud:          google.Provider,
+			Distribution:   google.ClusterDistributionGKE,
+			CreatedBy:      userID,
+			RbacEnabled:    true,
+		},
+		Imported: true,
+	}
+
+	return &c, nil
+}
+
+// DiscoverCluster populates the model of an imported cluster from the GKE cluster
+func (c *GKECluster) DiscoverCluster() error {
+	secretItem, err := c.GetSecretWithValidation()
+	if err != nil {
+		return emperror.Wrap(err, "failed to retrieve cluster credential secret")
+	}
+
+	c.model.ProjectId = secretItem.GetValue(pkgSecret.ProjectId)
+
+	gkeCluster, err := c.GetGoogleCluster()
+	if err != nil {
+		return emperror.WrapWith(err, "could not get GKE cluster", "cluster", c.model.Cluster.Name)
+	}
+
+	if gkeCluster.Status != statusRunning {
+		return pkgErrors.ErrorImportedClusterNotRunning
+	}
+
+	c.model.Region, err = c.getRegionByZone(c.model.ProjectId, c.model.Cluster.Location)
+	if err != nil {
+		c.log.Warnf("error during getting region: %s", err.Error())
+	}
+
+	c.updateModel(gkeCluster, nil)
+	c.updateCurrentVersions(gkeCluster)
+
+	for _, nodePool := range gkeCluster.NodePools {
+		for _, nodePoolModel := range c.model.NodePools {
+			if nodePoolModel.Name == nodePool.Name {
+				nodePoolModel.CreatedBy = c.model.Cluster.CreatedBy
+				if nodePool.Config != nil {
+					nodePoolModel.Preemptible = nodePool.Config.Preemptible
+				}
+			}
+		}
+	}
+
+	c.model.Vpc = gkeCluster.Network
+	c.model.Subnet = gkeCluster.Subnetwork
+
+	if config := gkeCluster.PrivateClusterConfig; config != nil {
+		c.model.APIServerAccess.PrivateEndpoint = config.EnablePrivateEndpoint
+		c.model.APIServerAccess.PrivateNodes = config.EnablePrivateNodes
+		c.model.MasterIPv4CIDR = config.MasterIpv4CidrBlock
+	}
+
+	if config := gkeCluster.MasterAuthorizedNetworksConfig; config != nil && config.Enabled {
+		var cidrs []string
+		for _, block := range config.CidrBlocks {
+			cidrs = append(cidrs, block.CidrBlock)
+		}
+		c.model.APIServerAccess.PublicAccessCIDRs = strings.Join(cidrs, ",")
+	}
+
+	return nil
+}
+
+// ImportCluster does nothing as Pipeline can manage GKE clusters with the credentials of the organization
+func (c *GKECluster) ImportCluster() error {
+	return nil
+}
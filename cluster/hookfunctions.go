@@ -49,7 +49,7 @@ var HookMap = map[string]PostFunctioner{
 		f:            InstallKubernetesDashboardPostHook,
 		ErrorHandler: ErrorHandler{},
 	},
-	pkgCluster.InstallClusterAutoscalerPostHook: &BasePostFunction{
+	pkgCluster.InstallClusterAutoscalerPostHook: &PostFunctionWithParam{
 		f:            InstallClusterAutoscalerPostHook,
 		ErrorHandler: ErrorHandler{},
 	},
@@ -57,7 +57,7 @@ var HookMap = map[string]PostFunctioner{
 		f:            InstallHorizontalPodAutoscalerPostHook,
 		ErrorHandler: ErrorHandler{},
 	},
-	pkgCluster.InstallMonitoring: &BasePostFunction{
+	pkgCluster.InstallMonitoring: &PostFunctionWithParam{
 		f:            InstallMonitoring,
 		ErrorHandler: ErrorHandler{},
 	},
@@ -46,6 +46,7 @@ import (
 	"github.com/banzaicloud/pipeline/helm"
 	"github.com/banzaicloud/pipeline/internal/ark"
 	arkAPI "github.com/banzaicloud/pipeline/internal/ark/api"
+	"github.com/banzaicloud/pipeline/internal/cluster/spotinterruption/watcher"
 	alibabaObjectstore "github.com/banzaicloud/pipeline/internal/providers/alibaba"
 	amazonObjectstore "github.com/banzaicloud/pipeline/internal/providers/amazon"
 	anchore "github.com/banzaicloud/pipeline/internal/security"
@@ -139,12 +140,12 @@ func InstallLogging(cluster CommonCluster, param pkgCluster.PostHookParam) error
 	namespace := viper.GetString(pipConfig.PipelineSystemNamespace)
 	loggingParam.GenTLSForLogging.TLSEnabled = true
 	// Set TLS default values (default True)
-	if loggingParam.SecretId == "" {
-		if loggingParam.SecretName == "" {
-			return fmt.Errorf("either secretId or secretName has to be set")
-		}
+	if loggingParam.SecretId == "" && loggingParam.SecretName != "" {
 		loggingParam.SecretId = string(secret.GenerateSecretIDFromName(loggingParam.SecretName))
 	}
+	if loggingParam.SecretId == "" && len(loggingParam.Flows) == 0 {
+		return fmt.Errorf("either secretId, secretName or flows have to be set")
+	}
 	if loggingParam.GenTLSForLogging.Namespace == "" {
 		loggingParam.GenTLSForLogging.Namespace = namespace
 	}
@@ -206,6 +207,18 @@ func InstallLogging(cluster CommonCluster, param pkgCluster.PostHookParam) error
 		return emperror.Wrap(err, "install logging-operator failed")
 	}
 
+	for _, flow := range loggingParam.Flows {
+		err = applyLoggingFlow(cluster, flow)
+		if err != nil {
+			return emperror.WrapWith(err, "failed to apply logging flow", "flow", flow.Name)
+		}
+	}
+
+	if loggingParam.SecretId == "" {
+		cluster.SetLogging(true)
+		return nil
+	}
+
 	// Determine the type of output plugin
 	logSecret, err := secret.Store.Get(cluster.GetOrganizationId(), loggingParam.SecretId)
 	if err != nil {
@@ -360,7 +373,7 @@ func InstallLogging(cluster CommonCluster, param pkgCluster.PostHookParam) error
 			return emperror.Wrap(err, "install azure-output failed")
 		}
 	default:
-		return fmt.Errorf("unexpected logging secret type: %s", logSecret.Type)
+		return fmt.Errorf("unexpected logging secret type: %s, other destinations are supported through flows", logSecret.Type)
 	}
 	// Install output related secret
 	cluster.SetLogging(true)
@@ -482,6 +495,40 @@ func installDeployment(cluster CommonCluster, namespace string, deploymentName s
 	return nil
 }
 
+// installOrUpgradeDeployment installs a deployment or upgrades it with the given values if it is already deployed.
+func installOrUpgradeDeployment(cluster CommonCluster, namespace string, deploymentName string, releaseName string, values []byte, chartVersion string, wait bool) error {
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "failed to get k8s config")
+	}
+
+	deployments, err := helm.ListDeployments(&releaseName, "", kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to list deployments")
+	}
+
+	for _, release := range deployments.GetReleases() {
+		if release.Name != releaseName || release.GetInfo().GetStatus().GetCode() != pkgHelmRelease.Status_DEPLOYED {
+			continue
+		}
+
+		org, err := auth.GetOrganizationById(cluster.GetOrganizationId())
+		if err != nil {
+			return emperror.Wrap(err, "failed to get organization")
+		}
+
+		_, err = helm.UpgradeDeployment(releaseName, deploymentName, chartVersion, nil, values, false, kubeConfig, helm.GenerateHelmRepoEnv(org.Name))
+		if err != nil {
+			return emperror.WrapWith(err, "failed to upgrade deployment", "release", releaseName)
+		}
+
+		log.Infof("'%s' upgraded", deploymentName)
+		return nil
+	}
+
+	return installDeployment(cluster, namespace, deploymentName, releaseName, values, chartVersion, wait)
+}
+
 func CreateDefaultStorageclass(commonCluster CommonCluster) error {
 	if distro := commonCluster.GetDistribution(); distro != pkgCluster.PKE {
 		log.Infof("Not creating storageclass for %s", distro)
@@ -668,9 +715,20 @@ func setAdminRights(client *kubernetes.Clientset, userName string) (err error) {
 	return
 }
 
-// InstallClusterAutoscalerPostHook post hook only for AWS & Azure for now
-func InstallClusterAutoscalerPostHook(cluster CommonCluster) error {
-	return DeployClusterAutoscaler(cluster)
+// InstallClusterAutoscalerPostHook deploys the cluster autoscaler with the given expander and scale down settings
+func InstallClusterAutoscalerPostHook(cluster CommonCluster, param pkgCluster.PostHookParam) error {
+	var params InstallClusterAutoscalerParams
+	err := castToPostHookParam(&param, &params)
+	if err != nil {
+		return emperror.Wrap(err, "failed to cast posthook param")
+	}
+
+	err = params.ValidateForCloud(cluster.GetCloud())
+	if err != nil {
+		return emperror.Wrap(err, "invalid cluster autoscaler params")
+	}
+
+	return deployClusterAutoscaler(cluster, &params)
 }
 
 func metricsServerIsInstalled(cluster CommonCluster) bool {
@@ -1058,11 +1116,17 @@ func RegisterDomainPostHook(commonCluster CommonCluster) error {
 }
 
 // LabelNodesWithNodePoolName add node pool name labels for all nodes.
-// It's used only used in case of ec2_banzaicloud, ACSK etc. when we're not able to add labels via API.
+// It's used only used in case of ec2_banzaicloud, ACSK, imported clusters etc. when we're not able to add labels via API.
 func LabelNodesWithNodePoolName(commonCluster CommonCluster) error {
 
 	switch commonCluster.GetDistribution() {
-	case pkgCluster.EKS, pkgCluster.OKE, pkgCluster.GKE:
+	case pkgCluster.EKS, pkgCluster.GKE:
+		// node pools created by Pipeline are labelled through the cloud API, only imported ones have to be labelled here
+		if imported, ok := commonCluster.(interface{ IsImported() bool }); !ok || !imported.IsImported() {
+			log.Infof("nodes are already labelled on : %v", commonCluster.GetDistribution())
+			return nil
+		}
+	case pkgCluster.OKE:
 		log.Infof("nodes are already labelled on : %v", commonCluster.GetDistribution())
 		return nil
 	}
@@ -1294,12 +1358,20 @@ func InitSpotConfig(cluster CommonCluster) error {
 func DeployInstanceTerminationHandler(cluster CommonCluster) error {
 	distribution := cluster.GetDistribution()
 
+	pipelineSystemNamespace := viper.GetString(pipConfig.PipelineSystemNamespace)
+
+	// preemption and low-priority eviction notices are recorded by the spot interruption watcher
+	if distribution == pkgCluster.GKE || distribution == pkgCluster.AKS {
+		err := deploySpotInterruptionWatcher(cluster, pipelineSystemNamespace)
+		if err != nil {
+			return err
+		}
+	}
+
 	if distribution != pkgCluster.GKE && distribution != pkgCluster.EKS && distribution != pkgCluster.PKE {
 		return nil
 	}
 
-	pipelineSystemNamespace := viper.GetString(pipConfig.PipelineSystemNamespace)
-
 	values := map[string]interface{}{
 		"tolerations": []v1.Toleration{
 			{
@@ -1316,6 +1388,22 @@ func DeployInstanceTerminationHandler(cluster CommonCluster) error {
 	return installDeployment(cluster, pipelineSystemNamespace, pkgHelm.BanzaiRepository+"/instance-termination-handler", "ith", marshalledValues, "", false)
 }
 
+func deploySpotInterruptionWatcher(cluster CommonCluster, namespace string) error {
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to create client from kubeconfig")
+	}
+
+	image := viper.GetString(pipConfig.SpotInterruptionWatcherImage)
+
+	return emperror.Wrap(watcher.Deploy(client, namespace, image, cluster.GetCloud()), "failed to deploy spot interruption watcher")
+}
+
 func isSpotCluster(cluster CommonCluster) (bool, error) {
 	status, err := cluster.GetStatus()
 	if err != nil {
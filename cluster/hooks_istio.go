@@ -71,7 +71,7 @@ func InstallServiceMesh(cluster CommonCluster, param cluster.PostHookParam) erro
 		return emperror.Wrap(err, "failed to marshal yaml values")
 	}
 
-	err = installDeployment(cluster, istio.Namespace, pkgHelm.BanzaiRepository+"/istio", "istio", overrideValues, viper.GetString(pConfig.IstioChartVersion), false)
+	err = installDeployment(cluster, istio.Namespace, pkgHelm.BanzaiRepository+"/istio", istio.ReleaseName, overrideValues, viper.GetString(pConfig.IstioChartVersion), false)
 	if err != nil {
 		return emperror.Wrap(err, "installing Istio failed")
 	}
@@ -21,6 +21,7 @@ import (
 	"github.com/banzaicloud/pipeline/auth"
 	pipConfig "github.com/banzaicloud/pipeline/config"
 	"github.com/banzaicloud/pipeline/dns"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
 	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
 	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
 	"github.com/banzaicloud/pipeline/secret"
@@ -29,8 +30,69 @@ import (
 	"github.com/spf13/viper"
 )
 
+// InstallMonitoringParams describes InstallMonitoring posthook params
+type InstallMonitoringParams struct {
+	// Retention is the time Prometheus keeps the collected metrics for (eg. 15d)
+	Retention string `json:"retention,omitempty"`
+	// StorageClass is the storage class of the Prometheus server volume
+	StorageClass string `json:"storageClass,omitempty"`
+	// StorageSize is the size of the Prometheus server volume, the volume is persisted only if it is set
+	StorageSize string `json:"storageSize,omitempty"`
+	// AlertRules lists the predefined alert rule bundles to install, all of them are installed if it is not set
+	AlertRules []string `json:"alertRules,omitempty"`
+	// Receivers lists the Alertmanager receivers the alerts are routed to
+	Receivers []MonitoringReceiverParams `json:"receivers,omitempty"`
+}
+
+// MonitoringReceiverParams describes an Alertmanager receiver
+type MonitoringReceiverParams struct {
+	// Name is the name of the receiver
+	Name string `json:"name"`
+	// SecretName is the name of a slack, pagerduty or smtp secret containing the receiver credentials
+	SecretName string `json:"secretName"`
+	// Channel overrides the default channel of Slack receivers
+	Channel string `json:"channel,omitempty"`
+	// To is the email address of SMTP receivers
+	To string `json:"to,omitempty"`
+	// Severity limits the alerts routed to the receiver to the given severity
+	Severity string `json:"severity,omitempty"`
+}
+
 // InstallMonitoring installs monitoring tools (Prometheus, Grafana) to a cluster.
-func InstallMonitoring(cluster CommonCluster) error {
+func InstallMonitoring(cluster CommonCluster, param pkgCluster.PostHookParam) error {
+	var params InstallMonitoringParams
+	err := castToPostHookParam(&param, &params)
+	if err != nil {
+		return emperror.Wrap(err, "failed to cast posthook param")
+	}
+
+	alertRuleGroups, err := getMonitoringAlertRuleGroups(params.AlertRules)
+	if err != nil {
+		return emperror.Wrap(err, "invalid alert rules")
+	}
+
+	var alertmanagerConfig map[string]interface{}
+	if len(params.Receivers) > 0 {
+		receivers := make([]alertmanagerReceiver, 0, len(params.Receivers))
+		for _, receiverParams := range params.Receivers {
+			receiverSecret, err := secret.Store.GetByName(cluster.GetOrganizationId(), receiverParams.SecretName)
+			if err != nil {
+				return emperror.WrapWith(err, "failed to get receiver secret", "receiver", receiverParams.Name, "secret", receiverParams.SecretName)
+			}
+
+			receivers = append(receivers, alertmanagerReceiver{
+				MonitoringReceiverParams: receiverParams,
+				secretType:               receiverSecret.Type,
+				values:                   receiverSecret.Values,
+			})
+		}
+
+		alertmanagerConfig, err = newAlertmanagerConfig(receivers)
+		if err != nil {
+			return emperror.Wrap(err, "invalid alertmanager receivers")
+		}
+	}
+
 	monitoringNamespace := viper.GetString(pipConfig.PipelineSystemNamespace)
 
 	clusterNameSecretTag := fmt.Sprintf("cluster:%s", cluster.GetName())
@@ -113,6 +175,10 @@ func InstallMonitoring(cluster CommonCluster) error {
 		},
 	}
 	prometheusK8Secret, err := InstallSecret(cluster, kubePrometheusSecretName, installPromSecretRequest)
+	if err == ErrKubernetesSecretAlreadyExists {
+		// the posthook is rerun, the regenerated credentials replace the installed ones
+		prometheusK8Secret, err = MergeSecret(cluster, kubePrometheusSecretName, installPromSecretRequest)
+	}
 	if err != nil {
 		return emperror.Wrap(err, "failed to install tls secret to cluster")
 	}
@@ -137,6 +203,63 @@ func InstallMonitoring(cluster CommonCluster) error {
 
 	log.Debugf("grafana ingress host: %s", host)
 
+	prometheusServerValues := map[string]interface{}{
+		"affinity":    getHeadNodeAffinity(cluster),
+		"tolerations": getHeadNodeTolerations(),
+		"ingress": map[string]interface{}{
+			"enabled": true,
+			"annotations": map[string]string{
+				"traefik.ingress.kubernetes.io/auth-type":   "basic",
+				"traefik.ingress.kubernetes.io/auth-secret": kubePrometheusSecretName,
+			},
+			"hosts": []string{
+				host + "/prometheus",
+			},
+		},
+	}
+	if params.Retention != "" {
+		prometheusServerValues["retention"] = params.Retention
+	}
+	if params.StorageSize != "" {
+		persistentVolume := map[string]interface{}{
+			"enabled": true,
+			"size":    params.StorageSize,
+		}
+		if params.StorageClass != "" {
+			persistentVolume["storageClass"] = params.StorageClass
+		}
+		prometheusServerValues["persistentVolume"] = persistentVolume
+	}
+
+	prometheusValues := map[string]interface{}{
+		"alertmanager": map[string]interface{}{
+			"affinity":    getHeadNodeAffinity(cluster),
+			"tolerations": getHeadNodeTolerations(),
+		},
+		"kubeStateMetrics": map[string]interface{}{
+			"affinity":    getHeadNodeAffinity(cluster),
+			"tolerations": getHeadNodeTolerations(),
+		},
+		"nodeExporter": map[string]interface{}{
+			"tolerations": getHeadNodeTolerations(),
+		},
+		"server": prometheusServerValues,
+		"pushgateway": map[string]interface{}{
+			"affinity":    getHeadNodeAffinity(cluster),
+			"tolerations": getHeadNodeTolerations(),
+		},
+		"serverFiles": map[string]interface{}{
+			"alerts": map[string]interface{}{
+				"groups": alertRuleGroups,
+			},
+		},
+	}
+	if alertmanagerConfig != nil {
+		prometheusValues["alertmanagerFiles"] = map[string]interface{}{
+			"alertmanager.yml": alertmanagerConfig,
+		}
+	}
+
 	values := map[string]interface{}{
 		"grafana": map[string]interface{}{
 			"adminUser":     grafanaAdminUsername,
@@ -145,37 +268,7 @@ func InstallMonitoring(cluster CommonCluster) error {
 			"affinity":      getHeadNodeAffinity(cluster),
 			"tolerations":   getHeadNodeTolerations(),
 		},
-		"prometheus": map[string]interface{}{
-			"alertmanager": map[string]interface{}{
-				"affinity":    getHeadNodeAffinity(cluster),
-				"tolerations": getHeadNodeTolerations(),
-			},
-			"kubeStateMetrics": map[string]interface{}{
-				"affinity":    getHeadNodeAffinity(cluster),
-				"tolerations": getHeadNodeTolerations(),
-			},
-			"nodeExporter": map[string]interface{}{
-				"tolerations": getHeadNodeTolerations(),
-			},
-			"server": map[string]interface{}{
-				"affinity":    getHeadNodeAffinity(cluster),
-				"tolerations": getHeadNodeTolerations(),
-				"ingress": map[string]interface{}{
-					"enabled": true,
-					"annotations": map[string]string{
-						"traefik.ingress.kubernetes.io/auth-type":   "basic",
-						"traefik.ingress.kubernetes.io/auth-secret": kubePrometheusSecretName,
-					},
-					"hosts": []string{
-						host + "/prometheus",
-					},
-				},
-			},
-			"pushgateway": map[string]interface{}{
-				"affinity":    getHeadNodeAffinity(cluster),
-				"tolerations": getHeadNodeTolerations(),
-			},
-		},
+		"prometheus": prometheusValues,
 	}
 
 	valuesJSON, err := yaml.Marshal(values)
@@ -183,7 +276,7 @@ func InstallMonitoring(cluster CommonCluster) error {
 		return emperror.Wrap(err, "values JSON conversion failed")
 	}
 
-	err = installDeployment(
+	err = installOrUpgradeDeployment(
 		cluster,
 		monitoringNamespace,
 		pkgHelm.BanzaiRepository+"/pipeline-cluster-monitor",
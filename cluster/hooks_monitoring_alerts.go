@@ -0,0 +1,256 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"sort"
+
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/pkg/errors"
+)
+
+// alertRule describes a Prometheus alerting rule
+type alertRule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// alertRuleGroup describes a group of Prometheus alerting rules
+type alertRuleGroup struct {
+	Name  string      `json:"name"`
+	Rules []alertRule `json:"rules"`
+}
+
+// predefined alert rule bundles
+const (
+	AlertRulesNode        = "node"
+	AlertRulesPod         = "pod"
+	AlertRulesPVC         = "pvc"
+	AlertRulesCertificate = "certificate"
+)
+
+// nolint: gochecknoglobals
+var monitoringAlertRuleBundles = map[string]alertRuleGroup{
+	AlertRulesNode: {
+		Name: "node.rules",
+		Rules: []alertRule{
+			{
+				Alert:       "NodeNotReady",
+				Expr:        `kube_node_status_condition{condition="Ready",status="true"} == 0`,
+				For:         "5m",
+				Labels:      map[string]string{"severity": "critical"},
+				Annotations: map[string]string{"summary": "Node {{ $labels.node }} is not ready"},
+			},
+			{
+				Alert:       "NodeMemoryPressure",
+				Expr:        `kube_node_status_condition{condition="MemoryPressure",status="true"} == 1`,
+				For:         "5m",
+				Labels:      map[string]string{"severity": "warning"},
+				Annotations: map[string]string{"summary": "Node {{ $labels.node }} is under memory pressure"},
+			},
+			{
+				Alert:       "NodeFilesystemAlmostFull",
+				Expr:        `node_filesystem_avail_bytes{fstype!~"tmpfs|rootfs"} / node_filesystem_size_bytes{fstype!~"tmpfs|rootfs"} < 0.1`,
+				For:         "10m",
+				Labels:      map[string]string{"severity": "warning"},
+				Annotations: map[string]string{"summary": "Filesystem {{ $labels.mountpoint }} on {{ $labels.instance }} has less than 10% space left"},
+			},
+		},
+	},
+	AlertRulesPod: {
+		Name: "pod.rules",
+		Rules: []alertRule{
+			{
+				Alert:       "PodCrashLooping",
+				Expr:        `rate(kube_pod_container_status_restarts_total[15m]) * 60 * 5 > 0`,
+				For:         "15m",
+				Labels:      map[string]string{"severity": "warning"},
+				Annotations: map[string]string{"summary": "Pod {{ $labels.namespace }}/{{ $labels.pod }} is crash looping"},
+			},
+			{
+				Alert:       "PodNotReady",
+				Expr:        `sum by (namespace, pod) (kube_pod_status_phase{phase=~"Pending|Unknown"}) > 0`,
+				For:         "15m",
+				Labels:      map[string]string{"severity": "warning"},
+				Annotations: map[string]string{"summary": "Pod {{ $labels.namespace }}/{{ $labels.pod }} has not been ready for 15 minutes"},
+			},
+		},
+	},
+	AlertRulesPVC: {
+		Name: "pvc.rules",
+		Rules: []alertRule{
+			{
+				Alert:       "PersistentVolumeFillingUp",
+				Expr:        `kubelet_volume_stats_available_bytes / kubelet_volume_stats_capacity_bytes < 0.1`,
+				For:         "5m",
+				Labels:      map[string]string{"severity": "warning"},
+				Annotations: map[string]string{"summary": "Volume of claim {{ $labels.namespace }}/{{ $labels.persistentvolumeclaim }} has less than 10% space left"},
+			},
+			{
+				Alert:       "PersistentVolumeClaimPending",
+				Expr:        `kube_persistentvolumeclaim_status_phase{phase="Pending"} == 1`,
+				For:         "15m",
+				Labels:      map[string]string{"severity": "warning"},
+				Annotations: map[string]string{"summary": "Claim {{ $labels.namespace }}/{{ $labels.persistentvolumeclaim }} has been pending for 15 minutes"},
+			},
+		},
+	},
+	AlertRulesCertificate: {
+		Name: "certificate.rules",
+		Rules: []alertRule{
+			{
+				Alert:       "ClientCertificateExpiringSoon",
+				Expr:        `apiserver_client_certificate_expiration_seconds_count > 0 and histogram_quantile(0.01, sum by (job, le) (rate(apiserver_client_certificate_expiration_seconds_bucket[5m]))) < 604800`,
+				Labels:      map[string]string{"severity": "warning"},
+				Annotations: map[string]string{"summary": "A client certificate used to authenticate to the API server expires in less than 7 days"},
+			},
+			{
+				Alert:       "CertificateExpiringSoon",
+				Expr:        `certmanager_certificate_expiration_timestamp_seconds - time() < 604800`,
+				Labels:      map[string]string{"severity": "warning"},
+				Annotations: map[string]string{"summary": "Certificate {{ $labels.namespace }}/{{ $labels.name }} expires in less than 7 days"},
+			},
+		},
+	},
+}
+
+// getMonitoringAlertRuleGroups returns the groups of the given alert rule bundles, or of all of them if no bundles are given.
+func getMonitoringAlertRuleGroups(bundles []string) ([]alertRuleGroup, error) {
+	if bundles == nil {
+		for bundle := range monitoringAlertRuleBundles {
+			bundles = append(bundles, bundle)
+		}
+	}
+
+	sorted := append([]string{}, bundles...)
+	sort.Strings(sorted)
+
+	groups := make([]alertRuleGroup, 0, len(sorted))
+	for _, bundle := range sorted {
+		group, ok := monitoringAlertRuleBundles[bundle]
+		if !ok {
+			return nil, errors.Errorf("unknown alert rule bundle: %s", bundle)
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+const defaultAlertmanagerReceiver = "default"
+
+// alertmanagerReceiver is a receiver of the monitoring posthook params with the values of its secret
+type alertmanagerReceiver struct {
+	MonitoringReceiverParams
+
+	secretType string
+	values     map[string]string
+}
+
+// newAlertmanagerConfig returns an Alertmanager configuration routing the alerts to every receiver.
+func newAlertmanagerConfig(receivers []alertmanagerReceiver) (map[string]interface{}, error) {
+	receiverConfigs := []interface{}{
+		map[string]interface{}{"name": defaultAlertmanagerReceiver},
+	}
+	routes := make([]interface{}, 0, len(receivers))
+	names := map[string]bool{defaultAlertmanagerReceiver: true}
+
+	for _, receiver := range receivers {
+		if receiver.Name == "" {
+			return nil, errors.New("receiver name is required")
+		}
+		if names[receiver.Name] {
+			return nil, errors.Errorf("receiver name is used more than once: %s", receiver.Name)
+		}
+		names[receiver.Name] = true
+
+		receiverConfig := map[string]interface{}{
+			"name": receiver.Name,
+		}
+
+		switch receiver.secretType {
+		case pkgSecret.SlackSecretType:
+			slackConfig := map[string]interface{}{
+				"api_url":       receiver.values[pkgSecret.SlackAPIURL],
+				"send_resolved": true,
+			}
+			if receiver.Channel != "" {
+				slackConfig["channel"] = receiver.Channel
+			}
+			receiverConfig["slack_configs"] = []interface{}{slackConfig}
+
+		case pkgSecret.PagerDutySecretType:
+			receiverConfig["pagerduty_configs"] = []interface{}{
+				map[string]interface{}{
+					"routing_key":   receiver.values[pkgSecret.PagerDutyRoutingKey],
+					"send_resolved": true,
+				},
+			}
+
+		case pkgSecret.SMTPSecretType:
+			if receiver.To == "" {
+				return nil, errors.Errorf("email address is required for receiver: %s", receiver.Name)
+			}
+
+			emailConfig := map[string]interface{}{
+				"to":            receiver.To,
+				"from":          receiver.values[pkgSecret.SMTPFrom],
+				"smarthost":     receiver.values[pkgSecret.SMTPSmarthost],
+				"send_resolved": true,
+			}
+			if username := receiver.values[pkgSecret.Username]; username != "" {
+				emailConfig["auth_username"] = username
+				emailConfig["auth_password"] = receiver.values[pkgSecret.Password]
+			}
+			receiverConfig["email_configs"] = []interface{}{emailConfig}
+
+		default:
+			return nil, errors.Errorf(
+				"secret of receiver %s must be of type %s, %s or %s",
+				receiver.Name, pkgSecret.SlackSecretType, pkgSecret.PagerDutySecretType, pkgSecret.SMTPSecretType,
+			)
+		}
+
+		receiverConfigs = append(receiverConfigs, receiverConfig)
+
+		route := map[string]interface{}{
+			"receiver": receiver.Name,
+			"continue": true,
+		}
+		if receiver.Severity != "" {
+			route["match"] = map[string]string{"severity": receiver.Severity}
+		}
+		routes = append(routes, route)
+	}
+
+	return map[string]interface{}{
+		"global": map[string]interface{}{
+			"resolve_timeout": "5m",
+		},
+		"route": map[string]interface{}{
+			"receiver":        defaultAlertmanagerReceiver,
+			"group_by":        []string{"alertname", "namespace"},
+			"group_wait":      "30s",
+			"group_interval":  "5m",
+			"repeat_interval": "4h",
+			"routes":          routes,
+		},
+		"receivers": receiverConfigs,
+	}, nil
+}
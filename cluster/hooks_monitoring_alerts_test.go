@@ -0,0 +1,111 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+)
+
+func TestGetMonitoringAlertRuleGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		bundles []string
+		groups  []string
+		wantErr bool
+	}{
+		{name: "all", bundles: nil, groups: []string{"certificate.rules", "node.rules", "pod.rules", "pvc.rules"}},
+		{name: "none", bundles: []string{}, groups: []string{}},
+		{name: "selected", bundles: []string{AlertRulesPod, AlertRulesNode}, groups: []string{"node.rules", "pod.rules"}},
+		{name: "unknown", bundles: []string{"etcd"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			groups, err := getMonitoringAlertRuleGroups(test.bundles)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			names := make([]string, 0, len(groups))
+			for _, group := range groups {
+				names = append(names, group.Name)
+			}
+			if !reflect.DeepEqual(names, test.groups) {
+				t.Errorf("expected groups %v, got %v", test.groups, names)
+			}
+		})
+	}
+}
+
+func TestNewAlertmanagerConfig(t *testing.T) {
+	receivers := []alertmanagerReceiver{
+		{
+			MonitoringReceiverParams: MonitoringReceiverParams{Name: "ops", Channel: "#alerts"},
+			secretType:               pkgSecret.SlackSecretType,
+			values:                   map[string]string{pkgSecret.SlackAPIURL: "https://hooks.slack.com/x"},
+		},
+		{
+			MonitoringReceiverParams: MonitoringReceiverParams{Name: "oncall", Severity: "critical"},
+			secretType:               pkgSecret.PagerDutySecretType,
+			values:                   map[string]string{pkgSecret.PagerDutyRoutingKey: "key"},
+		},
+	}
+
+	config, err := newAlertmanagerConfig(receivers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	routes := config["route"].(map[string]interface{})["routes"].([]interface{})
+	expectedRoutes := []interface{}{
+		map[string]interface{}{"receiver": "ops", "continue": true},
+		map[string]interface{}{"receiver": "oncall", "continue": true, "match": map[string]string{"severity": "critical"}},
+	}
+	if !reflect.DeepEqual(routes, expectedRoutes) {
+		t.Errorf("expected routes %v, got %v", expectedRoutes, routes)
+	}
+
+	receiverConfigs := config["receivers"].([]interface{})
+	if len(receiverConfigs) != 3 {
+		t.Fatalf("expected 3 receivers, got %d", len(receiverConfigs))
+	}
+
+	slackConfig := receiverConfigs[1].(map[string]interface{})["slack_configs"].([]interface{})[0].(map[string]interface{})
+	if slackConfig["api_url"] != "https://hooks.slack.com/x" || slackConfig["channel"] != "#alerts" {
+		t.Errorf("unexpected slack config: %v", slackConfig)
+	}
+
+	invalid := []alertmanagerReceiver{
+		{MonitoringReceiverParams: MonitoringReceiverParams{Name: "mail"}, secretType: pkgSecret.SMTPSecretType},
+		{MonitoringReceiverParams: MonitoringReceiverParams{Name: "aws"}, secretType: pkgSecret.PasswordSecretType},
+		{MonitoringReceiverParams: MonitoringReceiverParams{Name: defaultAlertmanagerReceiver}, secretType: pkgSecret.SlackSecretType},
+	}
+	for _, receiver := range invalid {
+		if _, err := newAlertmanagerConfig([]alertmanagerReceiver{receiver}); err == nil {
+			t.Errorf("expected error for receiver %s", receiver.Name)
+		}
+	}
+}
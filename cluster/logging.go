@@ -0,0 +1,456 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+
+	pipConfig "github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/internal/logging"
+	alibabaObjectstore "github.com/banzaicloud/pipeline/internal/providers/alibaba"
+	amazonObjectstore "github.com/banzaicloud/pipeline/internal/providers/amazon"
+	oracleObjectstore "github.com/banzaicloud/pipeline/internal/providers/oracle"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/banzaicloud/pipeline/pkg/providers/azure"
+	azureObjectstore "github.com/banzaicloud/pipeline/pkg/providers/azure/objectstore"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// keys of the generic secrets generated for log flows
+const (
+	loggingGoogleCredentials   = "credentials.json"
+	loggingAzureStorageAccount = "storageAccountName"
+	loggingAzureStorageKey     = "storageAccountKey"
+)
+
+// loggingOracleEndpointFormat is the S3 compatible API endpoint of Oracle object storage
+const loggingOracleEndpointFormat = "https://%s.compat.objectstorage.%s.oraclecloud.com"
+
+// loggingDestination is the destination of a log flow with the values of its secret
+type loggingDestination struct {
+	pkgCluster.LoggingFlow
+
+	secretType string
+	values     map[string]string
+
+	// secretName is the name of the Kubernetes secret the output parameters are sourced from
+	secretName string
+	// objectStorageNamespace is the object storage namespace of Oracle outputs
+	objectStorageNamespace string
+}
+
+// ListLoggingFlows returns the log flows of a cluster managed by pipeline.
+func ListLoggingFlows(cluster CommonCluster) ([]logging.Flow, error) {
+	if !cluster.GetLogging() {
+		return nil, pkgErrors.ErrorLoggingNotInstalled
+	}
+
+	client, err := newDynamicClient(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return logging.ListFlows(client, viper.GetString(pipConfig.PipelineSystemNamespace))
+}
+
+// ApplyLoggingFlow creates or updates a log flow of a cluster.
+func ApplyLoggingFlow(cluster CommonCluster, flow pkgCluster.LoggingFlow) error {
+	if !cluster.GetLogging() {
+		return pkgErrors.ErrorLoggingNotInstalled
+	}
+
+	return applyLoggingFlow(cluster, flow)
+}
+
+// DeleteLoggingFlow deletes a log flow of a cluster together with the secret generated for it.
+func DeleteLoggingFlow(cluster CommonCluster, name string) error {
+	if !cluster.GetLogging() {
+		return pkgErrors.ErrorLoggingNotInstalled
+	}
+
+	client, err := newDynamicClient(cluster)
+	if err != nil {
+		return err
+	}
+
+	namespace := viper.GetString(pipConfig.PipelineSystemNamespace)
+
+	err = logging.DeleteFlow(client, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	return deleteLoggingFlowSecret(cluster, namespace, name)
+}
+
+func applyLoggingFlow(cluster CommonCluster, flow pkgCluster.LoggingFlow) error {
+	if err := validateLoggingFlow(flow); err != nil {
+		return errors.Wrap(&invalidError{err}, "invalid logging flow")
+	}
+
+	namespace := viper.GetString(pipConfig.PipelineSystemNamespace)
+
+	destination, err := newLoggingDestination(cluster, namespace, flow)
+	if err != nil {
+		return err
+	}
+
+	output, err := newLoggingOutput(destination)
+	if err != nil {
+		return errors.Wrap(&invalidError{err}, "invalid logging flow")
+	}
+
+	client, err := newDynamicClient(cluster)
+	if err != nil {
+		return err
+	}
+
+	return logging.ApplyFlow(client, namespace, logging.Flow{
+		Name:     flow.Name,
+		AppLabel: flow.AppLabel,
+		Output:   output,
+	})
+}
+
+func validateLoggingFlow(flow pkgCluster.LoggingFlow) error {
+	if errs := validation.IsDNS1123Label(flow.Name); len(errs) > 0 {
+		return errors.Errorf("invalid flow name %q: %s", flow.Name, errs[0])
+	}
+
+	if flow.AppLabel != "" && flow.AppLabel != logging.AllApps {
+		if errs := validation.IsValidLabelValue(flow.AppLabel); len(errs) > 0 {
+			return errors.Errorf("invalid app label %q: %s", flow.AppLabel, errs[0])
+		}
+	}
+
+	if flow.SecretId == "" && flow.SecretName == "" {
+		return errors.New("either secretId or secretName has to be set")
+	}
+
+	return nil
+}
+
+// newLoggingDestination looks up the secret and the bucket details of a flow and installs the secret sourced by the output to the cluster.
+func newLoggingDestination(cluster CommonCluster, namespace string, flow pkgCluster.LoggingFlow) (loggingDestination, error) {
+	organizationID := cluster.GetOrganizationId()
+
+	secretID := flow.SecretId
+	if secretID == "" {
+		secretID = secret.GenerateSecretIDFromName(flow.SecretName)
+	}
+
+	flowSecret, err := secret.Store.Get(organizationID, secretID)
+	if err != nil {
+		return loggingDestination{}, emperror.WrapWith(err, "failed to get logging flow secret", "flow", flow.Name)
+	}
+
+	if err := validateLoggingSecretType(flowSecret.Type); err != nil {
+		return loggingDestination{}, errors.Wrap(&invalidError{err}, "invalid logging flow")
+	}
+
+	destination := loggingDestination{
+		LoggingFlow: flow,
+		secretType:  flowSecret.Type,
+		values:      flowSecret.Values,
+	}
+
+	switch flowSecret.Type {
+	case pkgCluster.Amazon, pkgCluster.Google, pkgCluster.Azure, pkgCluster.Alibaba, pkgCluster.Oracle:
+		if flow.BucketName == "" {
+			return destination, errors.Wrap(&invalidError{errors.New("bucketName is required for object store outputs")}, "invalid logging flow")
+		}
+	}
+
+	switch flowSecret.Type {
+	case pkgCluster.Amazon:
+		if destination.Region == "" {
+			defaultRegion := viper.GetString(pipConfig.AmazonInitializeRegionKey)
+			destination.Region, err = amazonObjectstore.GetBucketRegion(flowSecret, flow.BucketName, defaultRegion, organizationID, log)
+			if err != nil {
+				return destination, emperror.WrapWith(err, "failed to get S3 bucket region", "bucket", flow.BucketName)
+			}
+		}
+
+	case pkgCluster.Alibaba:
+		if destination.Region == "" {
+			defaultRegion := viper.GetString(pipConfig.AlibabaInitializeRegionKey)
+			destination.Region, err = alibabaObjectstore.GetBucketLocation(flowSecret, flow.BucketName, defaultRegion, organizationID, log)
+			if err != nil {
+				return destination, emperror.WrapWith(err, "failed to get OSS bucket region", "bucket", flow.BucketName)
+			}
+		}
+
+	case pkgCluster.Oracle:
+		if flow.AccessSecretName == "" {
+			return destination, errors.Wrap(&invalidError{errors.New("accessSecretName is required for Oracle outputs")}, "invalid logging flow")
+		}
+
+		accessSecret, err := secret.Store.Get(organizationID, secret.GenerateSecretIDFromName(flow.AccessSecretName))
+		if err == secret.ErrSecretNotExists {
+			return destination, errors.Wrap(&invalidError{errors.Errorf("access secret %q is not found", flow.AccessSecretName)}, "invalid logging flow")
+		} else if err != nil {
+			return destination, emperror.WrapWith(err, "failed to get logging flow access secret", "flow", flow.Name)
+		}
+
+		if accessSecret.Type != pkgSecret.PasswordSecretType {
+			return destination, errors.Wrap(&invalidError{errors.Errorf("access secret %q must be a password secret", flow.AccessSecretName)}, "invalid logging flow")
+		}
+
+		if destination.Region == "" {
+			destination.Region = flowSecret.Values[pkgSecret.OracleRegion]
+		}
+
+		destination.objectStorageNamespace, err = oracleObjectstore.GetNamespace(flowSecret, destination.Region)
+		if err != nil {
+			return destination, emperror.Wrap(err, "failed to get object storage namespace")
+		}
+
+		// the S3 compatible API is accessed with the customer secret key
+		secretID = secret.GenerateSecretIDFromName(flow.AccessSecretName)
+
+	case pkgCluster.Google:
+		credentials, err := json.Marshal(flowSecret.Values)
+		if err != nil {
+			return destination, emperror.Wrap(err, "failed to marshal google credentials")
+		}
+
+		secretID, err = storeLoggingFlowSecret(cluster, flow.Name, map[string]string{
+			loggingGoogleCredentials: string(credentials),
+		})
+		if err != nil {
+			return destination, err
+		}
+
+	case pkgCluster.Azure:
+		storageAccountClient, err := azureObjectstore.NewAuthorizedStorageAccountClientFromSecret(*azure.NewCredentials(flowSecret.Values))
+		if err != nil {
+			return destination, emperror.Wrap(err, "failed to create storage account client")
+		}
+
+		key, err := storageAccountClient.GetStorageAccountKey(flow.ResourceGroup, flow.StorageAccount)
+		if err != nil {
+			return destination, emperror.Wrap(err, "failed to get storage account key")
+		}
+
+		secretID, err = storeLoggingFlowSecret(cluster, flow.Name, map[string]string{
+			loggingAzureStorageAccount: flow.StorageAccount,
+			loggingAzureStorageKey:     key,
+		})
+		if err != nil {
+			return destination, err
+		}
+	}
+
+	installedSecrets, err := InstallSecrets(cluster, &pkgSecret.ListSecretsQuery{IDs: []string{secretID}}, namespace)
+	if err != nil {
+		return destination, emperror.WrapWith(err, "failed to install logging flow secret", "flow", flow.Name)
+	}
+
+	if len(installedSecrets) == 0 {
+		return destination, errors.Errorf("secret of logging flow %s is not found", flow.Name)
+	}
+	destination.secretName = installedSecrets[0].Name
+
+	return destination, nil
+}
+
+// validateLoggingSecretType checks that a log flow output can be configured from a secret type.
+func validateLoggingSecretType(secretType string) error {
+	switch secretType {
+	case pkgCluster.Amazon, pkgCluster.Google, pkgCluster.Azure, pkgCluster.Alibaba, pkgCluster.Oracle,
+		pkgSecret.ElasticsearchSecretType, pkgSecret.LokiSecretType, pkgSecret.KafkaSecretType, pkgSecret.SyslogSecretType:
+		return nil
+	default:
+		return errors.Errorf("unsupported logging flow secret type: %s", secretType)
+	}
+}
+
+// loggingFlowSecretName returns the name of the secret generated for a flow, which is installed to the cluster by the same name.
+func loggingFlowSecretName(cluster CommonCluster, flowName string) string {
+	return fmt.Sprintf("logging-flow-%d-%s", cluster.GetID(), flowName)
+}
+
+// storeLoggingFlowSecret stores the credentials of a flow derived from its destination secret.
+func storeLoggingFlowSecret(cluster CommonCluster, flowName string, values map[string]string) (string, error) {
+	secretID, err := secret.Store.CreateOrUpdate(cluster.GetOrganizationId(), &secret.CreateSecretRequest{
+		Name: loggingFlowSecretName(cluster, flowName),
+		Type: pkgSecret.GenericSecret,
+		Tags: []string{
+			fmt.Sprintf("clusterUID:%s", cluster.GetUID()),
+			pkgSecret.TagBanzaiReadonly,
+			fmt.Sprintf("release:%s", pipConfig.LoggingReleaseName),
+		},
+		Values: values,
+	})
+
+	return secretID, emperror.WrapWith(err, "failed to store logging flow secret", "flow", flowName)
+}
+
+// deleteLoggingFlowSecret deletes the secret generated for a flow from the cluster and from Vault,
+// flows of destinations using the credentials as they are have no generated secret.
+func deleteLoggingFlowSecret(cluster CommonCluster, namespace string, flowName string) error {
+	secretName := loggingFlowSecretName(cluster, flowName)
+
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to create client from kubeconfig")
+	}
+
+	err = client.CoreV1().Secrets(namespace).Delete(secretName, &metav1.DeleteOptions{})
+	if err != nil && !k8sapierrors.IsNotFound(err) {
+		return emperror.WrapWith(err, "failed to delete logging flow secret", "secret", secretName, "namespace", namespace)
+	}
+
+	err = secret.Store.Delete(cluster.GetOrganizationId(), secret.GenerateSecretIDFromName(secretName))
+	if err != nil && errors.Cause(err) != secret.ErrSecretNotExists {
+		return emperror.WrapWith(err, "failed to delete logging flow secret", "secret", secretName)
+	}
+
+	return nil
+}
+
+// newLoggingOutput returns the fluentd output of a destination.
+func newLoggingOutput(d loggingDestination) (logging.Output, error) {
+	switch d.secretType {
+	case pkgCluster.Amazon:
+		return logging.Output{
+			Type: "s3",
+			Parameters: []logging.Parameter{
+				d.secretParameter("aws_key_id", pkgSecret.AwsAccessKeyId),
+				d.secretParameter("aws_sec_key", pkgSecret.AwsSecretAccessKey),
+				logging.NewParameter("s3_bucket", d.BucketName),
+				logging.NewParameter("s3_region", d.Region),
+			},
+		}, nil
+
+	case pkgCluster.Oracle:
+		return logging.Output{
+			Type: "s3",
+			Parameters: []logging.Parameter{
+				d.secretParameter("aws_key_id", pkgSecret.Username),
+				d.secretParameter("aws_sec_key", pkgSecret.Password),
+				logging.NewParameter("s3_bucket", d.BucketName),
+				logging.NewParameter("s3_region", d.Region),
+				logging.NewParameter("s3_endpoint", fmt.Sprintf(loggingOracleEndpointFormat, d.objectStorageNamespace, d.Region)),
+				logging.NewParameter("force_path_style", "true"),
+			},
+		}, nil
+
+	case pkgCluster.Alibaba:
+		return logging.Output{
+			Type: "oss",
+			Parameters: []logging.Parameter{
+				d.secretParameter("access_key_id", pkgSecret.AlibabaAccessKeyId),
+				d.secretParameter("access_key_secret", pkgSecret.AlibabaSecretAccessKey),
+				logging.NewParameter("bucket", d.BucketName),
+				logging.NewParameter("endpoint", fmt.Sprintf("oss-%s.aliyuncs.com", d.Region)),
+			},
+		}, nil
+
+	case pkgCluster.Google:
+		return logging.Output{
+			Type: "gcs",
+			Parameters: []logging.Parameter{
+				logging.NewParameter("project", d.values[pkgSecret.ProjectId]),
+				d.secretParameter("credentials_json", loggingGoogleCredentials),
+				logging.NewParameter("bucket", d.BucketName),
+			},
+		}, nil
+
+	case pkgCluster.Azure:
+		return logging.Output{
+			Type: "azurestorage",
+			Parameters: []logging.Parameter{
+				d.secretParameter("azure_storage_account", loggingAzureStorageAccount),
+				d.secretParameter("azure_storage_access_key", loggingAzureStorageKey),
+				logging.NewParameter("azure_container", d.BucketName),
+			},
+		}, nil
+
+	case pkgSecret.ElasticsearchSecretType:
+		parameters := []logging.Parameter{d.secretParameter("host", pkgSecret.ElasticsearchHost)}
+		parameters = append(parameters, d.optionalSecretParameter("port", pkgSecret.ElasticsearchPort)...)
+		parameters = append(parameters, d.optionalSecretParameter("scheme", pkgSecret.ElasticsearchScheme)...)
+		parameters = append(parameters, d.optionalSecretParameter("user", pkgSecret.Username)...)
+		parameters = append(parameters, d.optionalSecretParameter("password", pkgSecret.Password)...)
+
+		if d.Index != "" {
+			parameters = append(parameters, logging.NewParameter("index_name", d.Index))
+		} else {
+			parameters = append(parameters, logging.NewParameter("logstash_format", "true"))
+		}
+
+		return logging.Output{Type: "elasticsearch", Parameters: parameters}, nil
+
+	case pkgSecret.LokiSecretType:
+		parameters := []logging.Parameter{d.secretParameter("url", pkgSecret.LokiURL)}
+		parameters = append(parameters, d.optionalSecretParameter("username", pkgSecret.Username)...)
+		parameters = append(parameters, d.optionalSecretParameter("password", pkgSecret.Password)...)
+
+		return logging.Output{Type: "loki", Parameters: parameters}, nil
+
+	case pkgSecret.KafkaSecretType:
+		if d.Topic == "" {
+			return logging.Output{}, errors.New("topic is required for Kafka outputs")
+		}
+
+		parameters := []logging.Parameter{
+			d.secretParameter("brokers", pkgSecret.KafkaBrokers),
+			logging.NewParameter("default_topic", d.Topic),
+		}
+		parameters = append(parameters, d.optionalSecretParameter("username", pkgSecret.Username)...)
+		parameters = append(parameters, d.optionalSecretParameter("password", pkgSecret.Password)...)
+
+		return logging.Output{Type: "kafka2", Parameters: parameters}, nil
+
+	case pkgSecret.SyslogSecretType:
+		parameters := []logging.Parameter{d.secretParameter("host", pkgSecret.SyslogHost)}
+		parameters = append(parameters, d.optionalSecretParameter("port", pkgSecret.SyslogPort)...)
+		parameters = append(parameters, d.optionalSecretParameter("protocol", pkgSecret.SyslogProtocol)...)
+
+		return logging.Output{Type: "remote_syslog", Parameters: parameters}, nil
+
+	default:
+		return logging.Output{}, errors.Errorf("unsupported logging flow secret type: %s", d.secretType)
+	}
+}
+
+func (d loggingDestination) secretParameter(name string, key string) logging.Parameter {
+	return logging.NewSecretParameter(name, d.secretName, key)
+}
+
+// optionalSecretParameter returns the parameter only if its key is set in the destination secret.
+func (d loggingDestination) optionalSecretParameter(name string, key string) []logging.Parameter {
+	if d.values[key] == "" {
+		return nil
+	}
+
+	return []logging.Parameter{d.secretParameter(name, key)}
+}
@@ -0,0 +1,190 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/pipeline/internal/logging"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+)
+
+func TestValidateLoggingFlow(t *testing.T) {
+	tests := []struct {
+		name    string
+		flow    pkgCluster.LoggingFlow
+		wantErr bool
+	}{
+		{name: "valid", flow: pkgCluster.LoggingFlow{Name: "es", SecretName: "my-es"}},
+		{name: "app label", flow: pkgCluster.LoggingFlow{Name: "es", AppLabel: "frontend", SecretId: "abc"}},
+		{name: "all apps", flow: pkgCluster.LoggingFlow{Name: "es", AppLabel: logging.AllApps, SecretId: "abc"}},
+		{name: "invalid name", flow: pkgCluster.LoggingFlow{Name: "ES_1", SecretName: "my-es"}, wantErr: true},
+		{name: "invalid app label", flow: pkgCluster.LoggingFlow{Name: "es", AppLabel: "front end", SecretName: "my-es"}, wantErr: true},
+		{name: "no secret", flow: pkgCluster.LoggingFlow{Name: "es"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			err := validateLoggingFlow(test.flow)
+			if test.wantErr && err == nil {
+				t.Error("expected error")
+			} else if !test.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateLoggingSecretType(t *testing.T) {
+	for _, secretType := range []string{pkgCluster.Amazon, pkgCluster.Oracle, pkgSecret.ElasticsearchSecretType, pkgSecret.SyslogSecretType} {
+		if err := validateLoggingSecretType(secretType); err != nil {
+			t.Errorf("unexpected error for secret type %s: %v", secretType, err)
+		}
+	}
+
+	for _, secretType := range []string{pkgSecret.PasswordSecretType, pkgSecret.GenericSecret, pkgSecret.TLSSecretType} {
+		if err := validateLoggingSecretType(secretType); err == nil {
+			t.Errorf("expected error for secret type %s", secretType)
+		}
+	}
+}
+
+func TestNewLoggingOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination loggingDestination
+		output      logging.Output
+	}{
+		{
+			name: "oracle",
+			destination: loggingDestination{
+				LoggingFlow:            pkgCluster.LoggingFlow{Name: "oci", BucketName: "logs", Region: "eu-frankfurt-1"},
+				secretType:             pkgCluster.Oracle,
+				secretName:             "oci-access",
+				objectStorageNamespace: "tenancy",
+			},
+			output: logging.Output{
+				Type: "s3",
+				Parameters: []logging.Parameter{
+					logging.NewSecretParameter("aws_key_id", "oci-access", pkgSecret.Username),
+					logging.NewSecretParameter("aws_sec_key", "oci-access", pkgSecret.Password),
+					logging.NewParameter("s3_bucket", "logs"),
+					logging.NewParameter("s3_region", "eu-frankfurt-1"),
+					logging.NewParameter("s3_endpoint", "https://tenancy.compat.objectstorage.eu-frankfurt-1.oraclecloud.com"),
+					logging.NewParameter("force_path_style", "true"),
+				},
+			},
+		},
+		{
+			name: "elasticsearch with index",
+			destination: loggingDestination{
+				LoggingFlow: pkgCluster.LoggingFlow{Name: "es", Index: "logs"},
+				secretType:  pkgSecret.ElasticsearchSecretType,
+				secretName:  "my-es",
+				values:      map[string]string{pkgSecret.ElasticsearchHost: "es.example.com", pkgSecret.Username: "elastic", pkgSecret.Password: "secret"},
+			},
+			output: logging.Output{
+				Type: "elasticsearch",
+				Parameters: []logging.Parameter{
+					logging.NewSecretParameter("host", "my-es", pkgSecret.ElasticsearchHost),
+					logging.NewSecretParameter("user", "my-es", pkgSecret.Username),
+					logging.NewSecretParameter("password", "my-es", pkgSecret.Password),
+					logging.NewParameter("index_name", "logs"),
+				},
+			},
+		},
+		{
+			name: "elasticsearch logstash indices",
+			destination: loggingDestination{
+				LoggingFlow: pkgCluster.LoggingFlow{Name: "es"},
+				secretType:  pkgSecret.ElasticsearchSecretType,
+				secretName:  "my-es",
+				values:      map[string]string{pkgSecret.ElasticsearchHost: "es.example.com", pkgSecret.ElasticsearchPort: "9243"},
+			},
+			output: logging.Output{
+				Type: "elasticsearch",
+				Parameters: []logging.Parameter{
+					logging.NewSecretParameter("host", "my-es", pkgSecret.ElasticsearchHost),
+					logging.NewSecretParameter("port", "my-es", pkgSecret.ElasticsearchPort),
+					logging.NewParameter("logstash_format", "true"),
+				},
+			},
+		},
+		{
+			name: "kafka",
+			destination: loggingDestination{
+				LoggingFlow: pkgCluster.LoggingFlow{Name: "kafka", Topic: "logs"},
+				secretType:  pkgSecret.KafkaSecretType,
+				secretName:  "my-kafka",
+				values:      map[string]string{pkgSecret.KafkaBrokers: "kafka-0:9092,kafka-1:9092"},
+			},
+			output: logging.Output{
+				Type: "kafka2",
+				Parameters: []logging.Parameter{
+					logging.NewSecretParameter("brokers", "my-kafka", pkgSecret.KafkaBrokers),
+					logging.NewParameter("default_topic", "logs"),
+				},
+			},
+		},
+		{
+			name: "syslog",
+			destination: loggingDestination{
+				LoggingFlow: pkgCluster.LoggingFlow{Name: "syslog"},
+				secretType:  pkgSecret.SyslogSecretType,
+				secretName:  "my-syslog",
+				values:      map[string]string{pkgSecret.SyslogHost: "syslog.example.com", pkgSecret.SyslogProtocol: "tcp"},
+			},
+			output: logging.Output{
+				Type: "remote_syslog",
+				Parameters: []logging.Parameter{
+					logging.NewSecretParameter("host", "my-syslog", pkgSecret.SyslogHost),
+					logging.NewSecretParameter("protocol", "my-syslog", pkgSecret.SyslogProtocol),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			output, err := newLoggingOutput(test.destination)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(output, test.output) {
+				t.Errorf("expected output %+v, got %+v", test.output, output)
+			}
+		})
+	}
+}
+
+func TestNewLoggingOutput_Invalid(t *testing.T) {
+	invalid := []loggingDestination{
+		{LoggingFlow: pkgCluster.LoggingFlow{Name: "kafka"}, secretType: pkgSecret.KafkaSecretType},
+		{LoggingFlow: pkgCluster.LoggingFlow{Name: "ssh"}, secretType: pkgSecret.SSHSecretType},
+	}
+
+	for _, destination := range invalid {
+		if _, err := newLoggingOutput(destination); err == nil {
+			t.Errorf("expected error for flow %s", destination.Name)
+		}
+	}
+}
@@ -106,7 +106,7 @@ func (c *pkeCreator) Create(ctx context.Context) error {
 		DexEnabled:          c.dexEnabled,
 	}
 
-	providerConfig := c.request.Properties.CreateClusterPKE.Network.ProviderConfig
+	providerConfig := c.request.Properties.CreateClusterPKE.Network.CloudProviderConfig
 	if providerConfig != nil {
 		cpc := &pke.NetworkCloudProviderConfigAmazon{}
 		err := mapstructure.Decode(providerConfig, &cpc)
@@ -121,11 +121,19 @@ func (c *pkeCreator) Create(ctx context.Context) error {
 		}
 	}
 
+	workflowName := pkeworkflow.CreateClusterWorkflowName
 	workflowOptions := client.StartWorkflowOptions{
 		TaskList:                     "pipeline",
 		ExecutionStartToCloseTimeout: 40 * time.Minute, // TODO: lower timeout
 	}
-	exec, err := c.workflowClient.ExecuteWorkflow(ctx, workflowOptions, pkeworkflow.CreateClusterWorkflowName, input)
+
+	// pre-provisioned hosts are installed by the users at their own pace
+	if c.request.Properties.CreateClusterPKE.UsesHosts() {
+		workflowName = pkeworkflow.CreateHostsClusterWorkflowName
+		workflowOptions.ExecutionStartToCloseTimeout = 24 * time.Hour
+	}
+
+	exec, err := c.workflowClient.ExecuteWorkflow(ctx, workflowOptions, workflowName, input)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,62 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+)
+
+// importableCluster is implemented by clusters that can be created from an existing cloud cluster.
+type importableCluster interface {
+	// DiscoverCluster populates the cluster model from the existing cloud cluster.
+	DiscoverCluster() error
+
+	// ImportCluster makes the existing cloud cluster manageable by Pipeline.
+	ImportCluster() error
+}
+
+type commonImporter struct {
+	cluster CommonCluster
+}
+
+// NewClusterImporter returns a new cluster creator instance which imports an existing cluster.
+func NewClusterImporter(cluster CommonCluster) *commonImporter {
+	return &commonImporter{
+		cluster: cluster,
+	}
+}
+
+// Validate implements the clusterCreator interface.
+func (c *commonImporter) Validate(ctx context.Context) error {
+	importable, ok := c.cluster.(importableCluster)
+	if !ok {
+		return pkgErrors.ErrorClusterImportNotSupported
+	}
+
+	return importable.DiscoverCluster()
+}
+
+// Prepare implements the clusterCreator interface.
+func (c *commonImporter) Prepare(ctx context.Context) (CommonCluster, error) {
+	return c.cluster, c.cluster.Persist(pkgCluster.Creating, pkgCluster.ImportingMessage)
+}
+
+// Create implements the clusterCreator interface.
+func (c *commonImporter) Create(ctx context.Context) error {
+	return c.cluster.(importableCluster).ImportCluster()
+}
@@ -98,5 +98,14 @@ func (c *commonNodepoolUpdater) Prepare(ctx context.Context) (CommonCluster, err
 
 // Update implements the clusterUpdater interface.
 func (c *commonNodepoolUpdater) Update(ctx context.Context) error {
-	return c.cluster.UpdateNodePools(c.request, c.userID)
+	if err := c.cluster.UpdateNodePools(c.request, c.userID); err != nil {
+		return err
+	}
+
+	// the autoscaler has to follow the new sizes of the node pools
+	if err := DeployClusterAutoscaler(c.cluster); err != nil {
+		return emperror.Wrap(err, "deploying cluster autoscaler failed")
+	}
+
+	return nil
 }
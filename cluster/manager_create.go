@@ -103,6 +103,10 @@ func (m *Manager) CreateCluster(ctx context.Context, creationCtx CreationContext
 		return nil, errors.Wrap(&invalidError{err}, "validation failed")
 	}
 
+	if err := ValidatePostHooks(creationCtx.Provider, creationCtx.PostHooks); err != nil {
+		return nil, errors.Wrap(err, "validation failed")
+	}
+
 	logger.Debug("preparing cluster creation")
 	cluster, err := creator.Prepare(ctx)
 	if err != nil {
@@ -134,7 +138,12 @@ func (m *Manager) CreateCluster(ctx context.Context, creationCtx CreationContext
 		return nil, err
 	}
 
-	if err := cluster.UpdateStatus(pkgCluster.Creating, pkgCluster.CreatingMessage); err != nil {
+	statusMessage := pkgCluster.CreatingMessage
+	if _, ok := creator.(*commonImporter); ok {
+		statusMessage = pkgCluster.ImportingMessage
+	}
+
+	if err := cluster.UpdateStatus(pkgCluster.Creating, statusMessage); err != nil {
 		return nil, err
 	}
 
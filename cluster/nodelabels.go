@@ -162,3 +162,24 @@ func DeployNodePoolLabelsSet(cluster CommonCluster, nodePoolLabels map[string]ma
 
 	return nil
 }
+
+// listNodePoolLabelSets returns the NodePoolLabelSet resources deployed to the cluster.
+func listNodePoolLabelSets(cluster CommonCluster) (npls.NodepoolLabelSets, error) {
+
+	pipelineSystemNamespace := viper.GetString(config.PipelineSystemNamespace)
+
+	k8sConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list labels of cluster")
+	}
+	k8sClientConfig, err := k8sclient.NewClientConfig(k8sConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list labels of cluster")
+	}
+	m, err := npls.NewNPLSManager(k8sClientConfig, pipelineSystemNamespace)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list labels of cluster")
+	}
+
+	return m.GetAll()
+}
@@ -53,12 +53,16 @@ func makeUpgradeTransport(config *rest.Config, keepalive time.Duration) (proxy.U
 	if err != nil {
 		return nil, err
 	}
-	rt := utilnet.SetOldTransportDefaults(&http.Transport{
-		TLSClientConfig: tlsConfig,
-		DialContext: (&net.Dialer{
+	dial := config.Dial
+	if dial == nil {
+		dial = (&net.Dialer{
 			Timeout:   30 * time.Second,
 			KeepAlive: keepalive,
-		}).DialContext,
+		}).DialContext
+	}
+	rt := utilnet.SetOldTransportDefaults(&http.Transport{
+		TLSClientConfig: tlsConfig,
+		DialContext:     dial,
 	})
 
 	upgrader, err := transport.HTTPWrappersForConfig(transportConfig, proxy.MirrorRequest)
@@ -0,0 +1,264 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/helm"
+	"github.com/banzaicloud/pipeline/internal/istio"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+// ServiceMeshStatus describes the current settings of the service mesh of a cluster
+type ServiceMeshStatus struct {
+	ChartVersion                string   `json:"chartVersion"`
+	EnableMtls                  bool     `json:"mtls"`
+	AutoSidecarInjectNamespaces []string `json:"autoSidecarInjectNamespaces"`
+}
+
+// UpdateServiceMeshParams describes the changes of the service mesh settings of a cluster, omitted fields are left unchanged
+type UpdateServiceMeshParams struct {
+	// EnableMtls turns mutual TLS on or off in the service mesh
+	EnableMtls *bool `json:"mtls,omitempty"`
+	// EnableSidecarInjectNamespaces list of namespaces to be labelled with istio-injection=enabled
+	EnableSidecarInjectNamespaces []string `json:"enableSidecarInjectNamespaces,omitempty"`
+	// DisableSidecarInjectNamespaces list of namespaces the istio-injection label is removed from
+	DisableSidecarInjectNamespaces []string `json:"disableSidecarInjectNamespaces,omitempty"`
+	// ChartVersion is the Istio chart version to upgrade to
+	ChartVersion string `json:"chartVersion,omitempty"`
+}
+
+// Validate validates the service mesh update params
+func (p UpdateServiceMeshParams) Validate() error {
+	enabled := make(map[string]bool, len(p.EnableSidecarInjectNamespaces))
+	for _, ns := range p.EnableSidecarInjectNamespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return errors.Errorf("invalid namespace %q: %s", ns, errs[0])
+		}
+		enabled[ns] = true
+	}
+
+	for _, ns := range p.DisableSidecarInjectNamespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return errors.Errorf("invalid namespace %q: %s", ns, errs[0])
+		}
+		if enabled[ns] {
+			return errors.Errorf("sidecar injection cannot be both enabled and disabled in namespace %q", ns)
+		}
+	}
+
+	return nil
+}
+
+// GetServiceMeshStatus returns the current settings of the service mesh of a cluster
+func GetServiceMeshStatus(cluster CommonCluster) (*ServiceMeshStatus, error) {
+	if !cluster.GetServiceMesh() {
+		return nil, pkgErrors.ErrorServiceMeshNotInstalled
+	}
+
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	deployment, err := helm.GetDeployment(istio.ReleaseName, kubeConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get Istio deployment")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to create client from kubeconfig")
+	}
+
+	namespaces, err := istio.GetLabelledNamespaces(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var config istio.Config
+	err = convertValues(deployment.Values, &config)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to parse Istio deployment values")
+	}
+
+	return &ServiceMeshStatus{
+		ChartVersion:                deployment.ChartVersion,
+		EnableMtls:                  config.Global.Mtls.Enabled,
+		AutoSidecarInjectNamespaces: namespaces,
+	}, nil
+}
+
+// UpdateServiceMesh changes the settings of the service mesh of a cluster
+func UpdateServiceMesh(cluster CommonCluster, params UpdateServiceMeshParams) error {
+	if !cluster.GetServiceMesh() {
+		return pkgErrors.ErrorServiceMeshNotInstalled
+	}
+
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	if params.EnableMtls != nil || params.ChartVersion != "" {
+		err = upgradeServiceMesh(cluster, kubeConfig, params)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to create client from kubeconfig")
+	}
+
+	err = istio.LabelNamespaces(log, client, params.EnableSidecarInjectNamespaces)
+	if err != nil {
+		return emperror.Wrap(err, "failed to label namespaces")
+	}
+
+	err = istio.UnlabelNamespaces(log, client, params.DisableSidecarInjectNamespaces)
+	if err != nil {
+		return emperror.Wrap(err, "failed to unlabel namespaces")
+	}
+
+	return nil
+}
+
+// upgradeServiceMesh upgrades the Istio release reusing its values, the chart version is only changed when requested
+func upgradeServiceMesh(cluster CommonCluster, kubeConfig []byte, params UpdateServiceMeshParams) error {
+	deployment, err := helm.GetDeployment(istio.ReleaseName, kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to get Istio deployment")
+	}
+
+	chartVersion := params.ChartVersion
+	if chartVersion == "" {
+		chartVersion = deployment.ChartVersion
+	}
+
+	var config istio.Config
+	err = convertValues(deployment.Values, &config)
+	if err != nil {
+		return emperror.Wrap(err, "failed to parse Istio deployment values")
+	}
+
+	if params.EnableMtls != nil {
+		config.Global.Mtls.Enabled = *params.EnableMtls
+	}
+
+	values, err := yaml.Marshal(map[string]interface{}{
+		"global": map[string]interface{}{
+			"mtls": map[string]interface{}{
+				"enabled": config.Global.Mtls.Enabled,
+			},
+		},
+	})
+	if err != nil {
+		return emperror.Wrap(err, "failed to marshal yaml values")
+	}
+
+	org, err := auth.GetOrganizationById(cluster.GetOrganizationId())
+	if err != nil {
+		return emperror.Wrap(err, "failed to get organization")
+	}
+
+	_, err = helm.UpgradeDeployment(istio.ReleaseName, pkgHelm.BanzaiRepository+"/istio", chartVersion, nil, values, true, kubeConfig, helm.GenerateHelmRepoEnv(org.Name))
+	if err != nil {
+		return emperror.WrapWith(err, "failed to upgrade Istio", "chartVersion", chartVersion)
+	}
+
+	log.Infof("Istio upgraded to chart version %s", chartVersion)
+	return nil
+}
+
+// ApplyServiceMeshCanary configures the traffic shifting between the versions of a service in the service mesh of a cluster
+func ApplyServiceMeshCanary(cluster CommonCluster, canary istio.Canary) error {
+	client, err := newServiceMeshDynamicClient(cluster)
+	if err != nil {
+		return err
+	}
+
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	deployment, err := helm.GetDeployment(istio.ReleaseName, kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to get Istio deployment")
+	}
+
+	var config istio.Config
+	err = convertValues(deployment.Values, &config)
+	if err != nil {
+		return emperror.Wrap(err, "failed to parse Istio deployment values")
+	}
+
+	return istio.ApplyCanary(client, canary, config.Global.Mtls.Enabled)
+}
+
+// ListServiceMeshCanaries returns the traffic shifting settings of the services in a namespace
+func ListServiceMeshCanaries(cluster CommonCluster, namespace string) ([]istio.Canary, error) {
+	client, err := newServiceMeshDynamicClient(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return istio.ListCanaries(client, namespace)
+}
+
+func newServiceMeshDynamicClient(cluster CommonCluster) (dynamic.Interface, error) {
+	if !cluster.GetServiceMesh() {
+		return nil, pkgErrors.ErrorServiceMeshNotInstalled
+	}
+
+	return newDynamicClient(cluster)
+}
+
+func newDynamicClient(cluster CommonCluster) (dynamic.Interface, error) {
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	config, err := k8sclient.NewClientConfig(kubeConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to create client config from kubeconfig")
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to create dynamic client")
+	}
+
+	return client, nil
+}
+
+func convertValues(values map[string]interface{}, out interface{}) error {
+	b, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(b, out)
+}
@@ -0,0 +1,965 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/cadence/client"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sHelm "k8s.io/helm/pkg/helm"
+
+	"github.com/banzaicloud/pipeline/auth"
+	pipConfig "github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/helm"
+	"github.com/banzaicloud/pipeline/internal/istio"
+	"github.com/banzaicloud/pipeline/model"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgClusterACSK "github.com/banzaicloud/pipeline/pkg/cluster/acsk"
+	pkgClusterAzure "github.com/banzaicloud/pipeline/pkg/cluster/aks"
+	pkgEks "github.com/banzaicloud/pipeline/pkg/cluster/eks"
+	pkgClusterGoogle "github.com/banzaicloud/pipeline/pkg/cluster/gke"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+)
+
+// clusterSpecFeature binds a cluster spec feature to the posthook installing it and the release it installs
+type clusterSpecFeature struct {
+	name        string
+	postHook    string
+	releaseName string
+	spec        func(pkgCluster.ClusterFeaturesSpec) *pkgCluster.ClusterFeatureSpec
+	enabled     func(CommonCluster) bool
+	setEnabled  func(CommonCluster, bool)
+}
+
+var clusterSpecFeatures = []clusterSpecFeature{
+	{
+		name:        "monitoring",
+		postHook:    pkgCluster.InstallMonitoring,
+		releaseName: pipConfig.MonitorReleaseName,
+		spec:        func(f pkgCluster.ClusterFeaturesSpec) *pkgCluster.ClusterFeatureSpec { return f.Monitoring },
+		enabled:     CommonCluster.GetMonitoring,
+		setEnabled:  CommonCluster.SetMonitoring,
+	},
+	{
+		name:        "logging",
+		postHook:    pkgCluster.InstallLogging,
+		releaseName: pipConfig.LoggingReleaseName,
+		spec:        func(f pkgCluster.ClusterFeaturesSpec) *pkgCluster.ClusterFeatureSpec { return f.Logging },
+		enabled:     CommonCluster.GetLogging,
+		setEnabled:  CommonCluster.SetLogging,
+	},
+	{
+		name:        "serviceMesh",
+		postHook:    pkgCluster.InstallServiceMesh,
+		releaseName: istio.ReleaseName,
+		spec:        func(f pkgCluster.ClusterFeaturesSpec) *pkgCluster.ClusterFeatureSpec { return f.ServiceMesh },
+		enabled:     CommonCluster.GetServiceMesh,
+		setEnabled:  CommonCluster.SetServiceMesh,
+	},
+	{
+		name:        "securityScan",
+		postHook:    pkgCluster.InstallAnchoreImageValidator,
+		releaseName: "anchore",
+		spec:        func(f pkgCluster.ClusterFeaturesSpec) *pkgCluster.ClusterFeatureSpec { return f.SecurityScan },
+		enabled:     CommonCluster.GetSecurityScan,
+		setEnabled:  CommonCluster.SetSecurityScan,
+	},
+}
+
+// clusterState describes the current state of the parts of a cluster managed by a cluster spec
+type clusterState struct {
+	nodePools map[string]*pkgCluster.NodePoolSpec
+	features  map[string]bool
+	secrets   map[string]bool
+	releases  map[string]*releaseState
+}
+
+// releaseState describes a Helm release deployed to a cluster
+type releaseState struct {
+	name      string
+	version   string
+	namespace string
+	values    map[string]interface{}
+}
+
+func secretSpecKey(s pkgCluster.ClusterSecretSpec) string {
+	return s.Namespace + "/" + s.Name
+}
+
+// GetClusterSpec returns the last applied spec of a cluster.
+func GetClusterSpec(cluster CommonCluster) (*pkgCluster.ClusterSpecResponse, error) {
+	specModel, err := model.GetClusterSpec(cluster.GetID())
+	if err != nil {
+		return nil, emperror.WrapWith(err, "failed to get cluster spec", "cluster", cluster.GetID())
+	}
+	if specModel == nil {
+		return nil, pkgErrors.ErrorClusterSpecNotFound
+	}
+
+	var spec pkgCluster.ClusterSpec
+	if err := json.Unmarshal([]byte(specModel.Spec), &spec); err != nil {
+		return nil, emperror.WrapWith(err, "failed to unmarshal cluster spec", "cluster", cluster.GetID())
+	}
+
+	return &pkgCluster.ClusterSpecResponse{
+		Spec:      &spec,
+		AppliedAt: specModel.UpdatedAt,
+		AppliedBy: specModel.UpdatedBy,
+	}, nil
+}
+
+// getLastAppliedClusterSpec returns the last applied spec of a cluster or an empty spec if none has been applied yet.
+func getLastAppliedClusterSpec(cluster CommonCluster) (*pkgCluster.ClusterSpec, error) {
+	response, err := GetClusterSpec(cluster)
+	if err == pkgErrors.ErrorClusterSpecNotFound {
+		return &pkgCluster.ClusterSpec{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return response.Spec, nil
+}
+
+func saveClusterSpec(cluster CommonCluster, spec *pkgCluster.ClusterSpec, userID uint) error {
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal cluster spec")
+	}
+
+	specModel := model.ClusterSpecModel{
+		ClusterID: cluster.GetID(),
+		Spec:      string(specJSON),
+		UpdatedBy: userID,
+	}
+
+	return emperror.WrapWith(specModel.Save(), "failed to save cluster spec", "cluster", cluster.GetID())
+}
+
+// getClusterState collects the current state of the parts of a cluster managed by the desired or the last applied spec.
+func getClusterState(cluster CommonCluster, desired, lastApplied *pkgCluster.ClusterSpec) (*clusterState, error) {
+	state := &clusterState{
+		nodePools: make(map[string]*pkgCluster.NodePoolSpec),
+		features:  make(map[string]bool),
+		secrets:   make(map[string]bool),
+		releases:  make(map[string]*releaseState),
+	}
+
+	status, err := cluster.GetStatus()
+	if err != nil {
+		return nil, emperror.Wrap(err, "could not get cluster status")
+	}
+
+	labelSets, err := listNodePoolLabelSets(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, np := range status.NodePools {
+		labels := np.Labels
+		if labelSet, ok := labelSets[name]; ok {
+			labels = labelSet
+		}
+
+		userLabels := make(map[string]string)
+		for key, value := range labels {
+			if !IsReservedDomainKey(key) {
+				userLabels[key] = value
+			}
+		}
+
+		state.nodePools[name] = &pkgCluster.NodePoolSpec{
+			InstanceType: np.InstanceType,
+			SpotPrice:    np.SpotPrice,
+			Preemptible:  np.Preemptible,
+			Autoscaling:  np.Autoscaling,
+			Count:        np.Count,
+			MinCount:     np.MinCount,
+			MaxCount:     np.MaxCount,
+			Labels:       userLabels,
+		}
+	}
+
+	for _, feature := range clusterSpecFeatures {
+		state.features[feature.name] = feature.enabled(cluster)
+	}
+
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get k8s config")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to create kubernetes client")
+	}
+
+	for _, s := range append(append([]pkgCluster.ClusterSecretSpec{}, desired.Secrets...), lastApplied.Secrets...) {
+		_, err := client.CoreV1().Secrets(s.Namespace).Get(s.Name, metav1.GetOptions{})
+		if k8sapierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, emperror.WrapWith(err, "failed to get secret", "secret", s.Name, "namespace", s.Namespace)
+		}
+
+		state.secrets[secretSpecKey(s)] = true
+	}
+
+	deployments, err := helm.ListDeployments(nil, "", kubeConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list deployments")
+	}
+
+	if deployments != nil {
+		for _, release := range deployments.Releases {
+			values := make(map[string]interface{})
+			if raw := release.GetConfig().GetRaw(); raw != "" {
+				if err := yaml.Unmarshal([]byte(raw), &values); err != nil {
+					return nil, emperror.WrapWith(err, "failed to parse release values", "release", release.Name)
+				}
+			}
+
+			state.releases[release.Name] = &releaseState{
+				name:      release.GetChart().GetMetadata().GetName(),
+				version:   release.GetChart().GetMetadata().GetVersion(),
+				namespace: release.Namespace,
+				values:    values,
+			}
+		}
+	}
+
+	return state, nil
+}
+
+// PlanClusterSpec returns the changes needed to converge a cluster to the desired state described by the spec.
+func PlanClusterSpec(cluster CommonCluster, spec *pkgCluster.ClusterSpec) ([]pkgCluster.ClusterSpecChange, error) {
+	changes, _, err := planClusterSpecWithState(cluster, spec)
+
+	return changes, err
+}
+
+func planClusterSpecWithState(cluster CommonCluster, spec *pkgCluster.ClusterSpec) ([]pkgCluster.ClusterSpecChange, *clusterState, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, nil, &commonUpdateValidationError{
+			msg:            err.Error(),
+			invalidRequest: true,
+		}
+	}
+
+	lastApplied, err := getLastAppliedClusterSpec(cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	state, err := getClusterState(cluster, spec, lastApplied)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return planClusterSpec(state, spec, lastApplied), state, nil
+}
+
+// ApplyClusterSpec converges a cluster to the desired state described by the spec and returns the changes made.
+// Node pools are updated asynchronously by the cluster updaters, features are installed by the posthooks workflow.
+func (m *Manager) ApplyClusterSpec(ctx context.Context, updateCtx UpdateContext, cluster CommonCluster, spec *pkgCluster.ClusterSpec, externalBaseURL string) ([]pkgCluster.ClusterSpecChange, error) {
+	logger := m.getLogger(ctx).WithFields(logrus.Fields{
+		"organization": updateCtx.OrganizationID,
+		"user":         updateCtx.UserID,
+		"cluster":      updateCtx.ClusterID,
+	})
+
+	changes, state, err := planClusterSpecWithState(cluster, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes) == 0 {
+		logger.Info("cluster is up to date with its spec")
+
+		return changes, saveClusterSpec(cluster, spec, updateCtx.UserID)
+	}
+
+	status, err := cluster.GetStatus()
+	if err != nil {
+		return nil, emperror.Wrap(err, "could not get cluster status")
+	}
+
+	if status.Status != pkgCluster.Running && status.Status != pkgCluster.Warning {
+		return nil, emperror.With(
+			&commonUpdateValidationError{
+				msg:                fmt.Sprintf("cluster is not in %s or %s state yet", pkgCluster.Running, pkgCluster.Warning),
+				preconditionFailed: true,
+			},
+			"status", status.Status,
+		)
+	}
+
+	nodePoolUpdater, labelChanges, err := newClusterSpecNodePoolUpdater(cluster, spec, state, status, changes, updateCtx.UserID, m.workflowClient, externalBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get k8s config")
+	}
+
+	org, err := auth.GetOrganizationById(cluster.GetOrganizationId())
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get organization")
+	}
+
+	postHooks := make(pkgCluster.PostHooks)
+	featuresChanged := false
+
+	for _, change := range changes {
+		switch change.Kind {
+		case pkgCluster.ClusterSpecFeature:
+			feature, err := getClusterSpecFeature(change.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			if change.Action == pkgCluster.ClusterSpecDelete {
+				logger.WithField("feature", feature.name).Info("disabling cluster feature")
+
+				if err := deleteReleaseIfExists(feature.releaseName, kubeConfig); err != nil {
+					return nil, emperror.With(err, "feature", feature.name)
+				}
+
+				feature.setEnabled(cluster, false)
+				featuresChanged = true
+
+				continue
+			}
+
+			postHooks[feature.postHook] = feature.spec(spec.Features).Params
+
+		case pkgCluster.ClusterSpecSecret:
+			if err := applyClusterSpecSecretChange(cluster, kubeConfig, change); err != nil {
+				return nil, err
+			}
+
+		case pkgCluster.ClusterSpecRelease:
+			if err := applyClusterSpecReleaseChange(kubeConfig, org.Name, change); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if featuresChanged {
+		if err := cluster.Persist(status.Status, status.StatusMessage); err != nil {
+			return nil, emperror.Wrap(err, "failed to persist cluster features")
+		}
+	}
+
+	if len(labelChanges) > 0 {
+		labelsMap, err := GetDesiredLabelsForCluster(cluster, labelChanges, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := DeployNodePoolLabelsSet(cluster, labelsMap); err != nil {
+			return nil, err
+		}
+	}
+
+	if nodePoolUpdater != nil {
+		err := m.UpdateCluster(ctx, updateCtx, &clusterSpecUpdater{
+			clusterUpdater: nodePoolUpdater,
+			manager:        m,
+			clusterID:      cluster.GetID(),
+			postHooks:      postHooks,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if len(postHooks) > 0 {
+		if err := m.startPostHooksWorkflow(ctx, cluster.GetID(), postHooks); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.WithField("changes", len(changes)).Info("cluster spec applied")
+
+	return changes, saveClusterSpec(cluster, spec, updateCtx.UserID)
+}
+
+// clusterSpecUpdater starts the posthooks of the features enabled by a cluster spec once its node pools are updated
+type clusterSpecUpdater struct {
+	clusterUpdater
+
+	manager   *Manager
+	clusterID uint
+	postHooks pkgCluster.PostHooks
+}
+
+// Update implements the clusterUpdater interface.
+func (u *clusterSpecUpdater) Update(ctx context.Context) error {
+	if err := u.clusterUpdater.Update(ctx); err != nil {
+		return err
+	}
+
+	if len(u.postHooks) == 0 {
+		return nil
+	}
+
+	return u.manager.startPostHooksWorkflow(ctx, u.clusterID, u.postHooks)
+}
+
+func (m *Manager) startPostHooksWorkflow(ctx context.Context, clusterID uint, postHooks pkgCluster.PostHooks) error {
+	input := RunPostHooksWorkflowInput{
+		ClusterID: clusterID,
+		PostHooks: BuildWorkflowPostHookFunctions(postHooks, false),
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 2 * time.Hour,
+	}
+
+	exec, err := m.workflowClient.ExecuteWorkflow(ctx, workflowOptions, RunPostHooksWorkflowName, input)
+	if err != nil {
+		return emperror.WrapWith(err, "failed to start workflow", "workflowName", RunPostHooksWorkflowName)
+	}
+
+	m.getLogger(ctx).WithFields(logrus.Fields{
+		"cluster":       clusterID,
+		"workflowName":  RunPostHooksWorkflowName,
+		"workflowID":    exec.GetID(),
+		"workflowRunID": exec.GetRunID(),
+	}).Info("workflow started successfully")
+
+	return nil
+}
+
+func getClusterSpecFeature(name string) (clusterSpecFeature, error) {
+	for _, feature := range clusterSpecFeatures {
+		if feature.name == name {
+			return feature, nil
+		}
+	}
+
+	return clusterSpecFeature{}, errors.Errorf("unknown cluster spec feature: %s", name)
+}
+
+// newClusterSpecNodePoolUpdater returns the updater converging the node pools of a cluster to the spec,
+// or the node pools to be relabeled if only the sizes or the labels of the node pools change.
+func newClusterSpecNodePoolUpdater(
+	cluster CommonCluster,
+	spec *pkgCluster.ClusterSpec,
+	state *clusterState,
+	status *pkgCluster.GetClusterStatusResponse,
+	changes []pkgCluster.ClusterSpecChange,
+	userID uint,
+	workflowClient client.Client,
+	externalBaseURL string,
+) (clusterUpdater, map[string]*pkgCluster.NodePoolStatus, error) {
+	resize := &pkgCluster.UpdateNodePoolsRequest{
+		NodePools: make(map[string]*pkgCluster.NodePoolData),
+	}
+	labelChanges := make(map[string]*pkgCluster.NodePoolStatus)
+	structural := false
+
+	for _, change := range changes {
+		if change.Kind != pkgCluster.ClusterSpecNodePool {
+			continue
+		}
+
+		if change.Action != pkgCluster.ClusterSpecUpdate {
+			structural = true
+			break
+		}
+
+		np := spec.NodePools[change.Name]
+		for _, field := range change.Fields {
+			switch field {
+			case "count":
+				resize.NodePools[change.Name] = &pkgCluster.NodePoolData{Count: np.Count}
+			case "labels":
+				nodePool := *status.NodePools[change.Name]
+				nodePool.Labels = np.Labels
+				labelChanges[change.Name] = &nodePool
+			default:
+				structural = true
+			}
+		}
+	}
+
+	if structural {
+		request, err := newNodePoolsUpdateClusterRequest(cluster, spec.NodePools, state.nodePools, status.NodePools)
+		if err != nil {
+			return nil, nil, &commonUpdateValidationError{
+				msg:            err.Error(),
+				invalidRequest: true,
+			}
+		}
+
+		return NewCommonClusterUpdater(request, cluster, userID, workflowClient, externalBaseURL), nil, nil
+	}
+
+	if len(resize.NodePools) > 0 {
+		return NewCommonNodepoolUpdater(resize, cluster, userID), labelChanges, nil
+	}
+
+	return nil, labelChanges, nil
+}
+
+// newNodePoolsUpdateClusterRequest returns an update cluster request with the desired node pools of a cluster spec.
+// Node pool properties not declared in the spec keep their current values.
+func newNodePoolsUpdateClusterRequest(
+	cluster CommonCluster,
+	nodePools map[string]*pkgCluster.NodePoolSpec,
+	current map[string]*pkgCluster.NodePoolSpec,
+	currentStatus map[string]*pkgCluster.NodePoolStatus,
+) (*pkgCluster.UpdateClusterRequest, error) {
+	request := &pkgCluster.UpdateClusterRequest{
+		Cloud:        cluster.GetCloud(),
+		ScaleOptions: cluster.GetScaleOptions(),
+		TtlMinutes:   uint(cluster.GetTTL().Minutes()),
+	}
+
+	desired := make(map[string]pkgCluster.NodePoolSpec, len(nodePools))
+	for name, np := range nodePools {
+		nodePool := *np
+		if cur, ok := current[name]; ok {
+			if nodePool.InstanceType == "" {
+				nodePool.InstanceType = cur.InstanceType
+			}
+			if nodePool.SpotPrice == "" {
+				nodePool.SpotPrice = cur.SpotPrice
+			}
+			if nodePool.Labels == nil {
+				nodePool.Labels = cur.Labels
+			}
+		}
+		desired[name] = nodePool
+	}
+
+	switch cluster.GetDistribution() {
+	case pkgCluster.EKS:
+		request.EKS = &pkgEks.UpdateClusterAmazonEKS{
+			NodePools: make(map[string]*pkgEks.NodePool, len(desired)),
+		}
+		for name, np := range desired {
+			var image string
+			if status, ok := currentStatus[name]; ok {
+				image = status.Image
+			}
+
+			request.EKS.NodePools[name] = &pkgEks.NodePool{
+				InstanceType: np.InstanceType,
+				SpotPrice:    np.SpotPrice,
+				Autoscaling:  np.Autoscaling,
+				MinCount:     np.MinCount,
+				MaxCount:     np.MaxCount,
+				Count:        np.Count,
+				Image:        image,
+				Labels:       np.Labels,
+			}
+		}
+
+	case pkgCluster.GKE:
+		request.GKE = &pkgClusterGoogle.UpdateClusterGoogle{
+			NodePools: make(map[string]*pkgClusterGoogle.NodePool, len(desired)),
+		}
+		for name, np := range desired {
+			request.GKE.NodePools[name] = &pkgClusterGoogle.NodePool{
+				Autoscaling:      np.Autoscaling,
+				MinCount:         np.MinCount,
+				MaxCount:         np.MaxCount,
+				Count:            np.Count,
+				NodeInstanceType: np.InstanceType,
+				Preemptible:      np.Preemptible,
+				Labels:           np.Labels,
+			}
+		}
+
+	case pkgCluster.AKS:
+		request.AKS = &pkgClusterAzure.UpdateClusterAzure{
+			NodePools: make(map[string]*pkgClusterAzure.NodePoolUpdate, len(desired)),
+		}
+		for name, np := range desired {
+			request.AKS.NodePools[name] = &pkgClusterAzure.NodePoolUpdate{
+				Autoscaling: np.Autoscaling,
+				MinCount:    np.MinCount,
+				MaxCount:    np.MaxCount,
+				Count:       np.Count,
+				Labels:      np.Labels,
+			}
+		}
+
+	case pkgCluster.ACSK:
+		request.ACSK = &pkgClusterACSK.UpdateClusterACSK{
+			NodePools: make(pkgClusterACSK.NodePools, len(desired)),
+		}
+		for name, np := range desired {
+			request.ACSK.NodePools[name] = &pkgClusterACSK.NodePool{
+				InstanceType: np.InstanceType,
+				MinCount:     np.MinCount,
+				MaxCount:     np.MaxCount,
+				Labels:       np.Labels,
+			}
+		}
+
+	default:
+		return nil, pkgErrors.ErrorClusterSpecNodePoolsNotSupported
+	}
+
+	return request, nil
+}
+
+func applyClusterSpecSecretChange(cluster CommonCluster, kubeConfig []byte, change pkgCluster.ClusterSpecChange) error {
+	if change.Action != pkgCluster.ClusterSpecCreate {
+		current := change.Current.(pkgCluster.ClusterSecretSpec)
+
+		client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+		if err != nil {
+			return emperror.Wrap(err, "failed to create kubernetes client")
+		}
+
+		err = client.CoreV1().Secrets(current.Namespace).Delete(current.Name, &metav1.DeleteOptions{})
+		if err != nil && !k8sapierrors.IsNotFound(err) {
+			return emperror.WrapWith(err, "failed to delete secret", "secret", current.Name, "namespace", current.Namespace)
+		}
+	}
+
+	if change.Action == pkgCluster.ClusterSpecDelete {
+		return nil
+	}
+
+	desired := change.Desired.(pkgCluster.ClusterSecretSpec)
+
+	_, err := InstallSecretByK8SConfig(kubeConfig, cluster.GetOrganizationId(), desired.Name, InstallSecretRequest{
+		SourceSecretName: desired.SourceSecretName,
+		Namespace:        desired.Namespace,
+	})
+
+	return emperror.WrapWith(err, "failed to install secret", "secret", desired.Name, "namespace", desired.Namespace)
+}
+
+func applyClusterSpecReleaseChange(kubeConfig []byte, orgName string, change pkgCluster.ClusterSpecChange) error {
+	if change.Action == pkgCluster.ClusterSpecDelete {
+		return deleteReleaseIfExists(change.Name, kubeConfig)
+	}
+
+	desired := change.Desired.(pkgCluster.ClusterReleaseSpec)
+
+	values, err := yaml.Marshal(desired.Values)
+	if err != nil {
+		return emperror.WrapWith(err, "failed to marshal release values", "release", desired.ReleaseName)
+	}
+
+	env := helm.GenerateHelmRepoEnv(orgName)
+
+	if change.Action == pkgCluster.ClusterSpecUpdate {
+		_, err = helm.UpgradeDeployment(desired.ReleaseName, desired.Name, desired.Version, nil, values, false, kubeConfig, env)
+
+		return emperror.WrapWith(err, "failed to upgrade release", "release", desired.ReleaseName)
+	}
+
+	_, err = helm.CreateDeployment(desired.Name, desired.Version, nil, desired.Namespace, desired.ReleaseName, false, nil, kubeConfig, env, k8sHelm.ValueOverrides(values))
+
+	return emperror.WrapWith(err, "failed to create release", "release", desired.ReleaseName)
+}
+
+func deleteReleaseIfExists(releaseName string, kubeConfig []byte) error {
+	deployments, err := helm.ListDeployments(&releaseName, "", kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to list deployments")
+	}
+
+	if deployments != nil {
+		for _, release := range deployments.Releases {
+			if release.Name == releaseName {
+				return emperror.WrapWith(helm.DeleteDeployment(releaseName, kubeConfig), "failed to delete release", "release", releaseName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// planClusterSpec diffs the desired spec against the current state of a cluster.
+// Secrets and releases are only deleted if they were declared by the last applied spec.
+func planClusterSpec(state *clusterState, desired, lastApplied *pkgCluster.ClusterSpec) []pkgCluster.ClusterSpecChange {
+	changes := make([]pkgCluster.ClusterSpecChange, 0)
+
+	if desired.NodePools != nil {
+		for _, name := range sortedNodePoolNames(desired.NodePools) {
+			np := desired.NodePools[name]
+			current, ok := state.nodePools[name]
+			if !ok {
+				changes = append(changes, pkgCluster.ClusterSpecChange{
+					Kind:    pkgCluster.ClusterSpecNodePool,
+					Action:  pkgCluster.ClusterSpecCreate,
+					Name:    name,
+					Desired: np,
+				})
+				continue
+			}
+
+			if fields := diffNodePool(current, np); len(fields) > 0 {
+				changes = append(changes, pkgCluster.ClusterSpecChange{
+					Kind:    pkgCluster.ClusterSpecNodePool,
+					Action:  pkgCluster.ClusterSpecUpdate,
+					Name:    name,
+					Fields:  fields,
+					Current: current,
+					Desired: np,
+				})
+			}
+		}
+
+		for _, name := range sortedNodePoolNames(state.nodePools) {
+			if _, ok := desired.NodePools[name]; !ok {
+				changes = append(changes, pkgCluster.ClusterSpecChange{
+					Kind:    pkgCluster.ClusterSpecNodePool,
+					Action:  pkgCluster.ClusterSpecDelete,
+					Name:    name,
+					Current: state.nodePools[name],
+				})
+			}
+		}
+	}
+
+	for _, feature := range clusterSpecFeatures {
+		spec := feature.spec(desired.Features)
+		if spec == nil {
+			continue
+		}
+
+		change := pkgCluster.ClusterSpecChange{
+			Kind:    pkgCluster.ClusterSpecFeature,
+			Name:    feature.name,
+			Desired: spec,
+		}
+
+		enabled := state.features[feature.name]
+		switch {
+		case spec.Enabled && !enabled:
+			change.Action = pkgCluster.ClusterSpecCreate
+		case !spec.Enabled && enabled:
+			change.Action = pkgCluster.ClusterSpecDelete
+		case spec.Enabled && enabled:
+			var lastParams interface{}
+			if last := feature.spec(lastApplied.Features); last != nil {
+				lastParams = last.Params
+			}
+			if equalValues(spec.Params, lastParams) {
+				continue
+			}
+
+			change.Action = pkgCluster.ClusterSpecUpdate
+			change.Fields = []string{"params"}
+		default:
+			continue
+		}
+
+		changes = append(changes, change)
+	}
+
+	lastAppliedSecrets := make(map[string]pkgCluster.ClusterSecretSpec)
+	for _, s := range lastApplied.Secrets {
+		lastAppliedSecrets[secretSpecKey(s)] = s
+	}
+
+	desiredSecrets := make(map[string]bool)
+	for _, s := range desired.Secrets {
+		key := secretSpecKey(s)
+		desiredSecrets[key] = true
+
+		if !state.secrets[key] {
+			changes = append(changes, pkgCluster.ClusterSpecChange{
+				Kind:    pkgCluster.ClusterSpecSecret,
+				Action:  pkgCluster.ClusterSpecCreate,
+				Name:    key,
+				Desired: s,
+			})
+		} else if last, ok := lastAppliedSecrets[key]; ok && last.SourceSecretName != s.SourceSecretName {
+			changes = append(changes, pkgCluster.ClusterSpecChange{
+				Kind:    pkgCluster.ClusterSpecSecret,
+				Action:  pkgCluster.ClusterSpecUpdate,
+				Name:    key,
+				Fields:  []string{"sourceSecretName"},
+				Current: last,
+				Desired: s,
+			})
+		}
+	}
+
+	for _, s := range lastApplied.Secrets {
+		key := secretSpecKey(s)
+		if !desiredSecrets[key] && state.secrets[key] {
+			changes = append(changes, pkgCluster.ClusterSpecChange{
+				Kind:    pkgCluster.ClusterSpecSecret,
+				Action:  pkgCluster.ClusterSpecDelete,
+				Name:    key,
+				Current: s,
+			})
+		}
+	}
+
+	desiredReleases := make(map[string]bool)
+	for _, r := range desired.Releases {
+		desiredReleases[r.ReleaseName] = true
+
+		current, ok := state.releases[r.ReleaseName]
+		if !ok {
+			changes = append(changes, pkgCluster.ClusterSpecChange{
+				Kind:    pkgCluster.ClusterSpecRelease,
+				Action:  pkgCluster.ClusterSpecCreate,
+				Name:    r.ReleaseName,
+				Desired: r,
+			})
+			continue
+		}
+
+		if fields := diffRelease(current, r); len(fields) > 0 {
+			changes = append(changes, pkgCluster.ClusterSpecChange{
+				Kind:   pkgCluster.ClusterSpecRelease,
+				Action: pkgCluster.ClusterSpecUpdate,
+				Name:   r.ReleaseName,
+				Fields: fields,
+				Current: pkgCluster.ClusterReleaseSpec{
+					ReleaseName: r.ReleaseName,
+					Name:        current.name,
+					Version:     current.version,
+					Namespace:   current.namespace,
+					Values:      current.values,
+				},
+				Desired: r,
+			})
+		}
+	}
+
+	for _, r := range lastApplied.Releases {
+		if _, ok := state.releases[r.ReleaseName]; ok && !desiredReleases[r.ReleaseName] {
+			changes = append(changes, pkgCluster.ClusterSpecChange{
+				Kind:    pkgCluster.ClusterSpecRelease,
+				Action:  pkgCluster.ClusterSpecDelete,
+				Name:    r.ReleaseName,
+				Current: r,
+			})
+		}
+	}
+
+	return changes
+}
+
+func sortedNodePoolNames(nodePools map[string]*pkgCluster.NodePoolSpec) []string {
+	names := make([]string, 0, len(nodePools))
+	for name := range nodePools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// diffNodePool returns the fields of a node pool to be changed.
+// Empty instance type and spot price, and nil labels keep the current values.
+func diffNodePool(current, desired *pkgCluster.NodePoolSpec) []string {
+	var fields []string
+
+	if desired.InstanceType != "" && desired.InstanceType != current.InstanceType {
+		fields = append(fields, "instanceType")
+	}
+	if desired.SpotPrice != "" && desired.SpotPrice != current.SpotPrice {
+		fields = append(fields, "spotPrice")
+	}
+	if desired.Autoscaling != current.Autoscaling {
+		fields = append(fields, "autoscaling")
+	}
+	if desired.Autoscaling {
+		if desired.MinCount != current.MinCount {
+			fields = append(fields, "minCount")
+		}
+		if desired.MaxCount != current.MaxCount {
+			fields = append(fields, "maxCount")
+		}
+	} else if desired.Count != current.Count {
+		fields = append(fields, "count")
+	}
+	if desired.Labels != nil && !equalLabels(desired.Labels, current.Labels) {
+		fields = append(fields, "labels")
+	}
+
+	return fields
+}
+
+func equalLabels(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if v, ok := b[key]; !ok || v != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// diffRelease returns the fields of a release to be changed.
+// Empty version keeps the current chart version.
+func diffRelease(current *releaseState, desired pkgCluster.ClusterReleaseSpec) []string {
+	var fields []string
+
+	chartName := desired.Name
+	if i := strings.LastIndex(chartName, "/"); i >= 0 {
+		chartName = chartName[i+1:]
+	}
+	if chartName != current.name {
+		fields = append(fields, "name")
+	}
+	if desired.Version != "" && desired.Version != current.version {
+		fields = append(fields, "version")
+	}
+	if !equalValues(desired.Values, current.values) {
+		fields = append(fields, "values")
+	}
+
+	return fields
+}
+
+// equalValues compares values by their JSON representation, treating nil and empty values as equal.
+func equalValues(a, b interface{}) bool {
+	normalize := func(v interface{}) interface{} {
+		var n interface{}
+		if raw, err := json.Marshal(v); err == nil {
+			_ = json.Unmarshal(raw, &n)
+		}
+		if m, ok := n.(map[string]interface{}); ok && len(m) == 0 {
+			return nil
+		}
+
+		return n
+	}
+
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
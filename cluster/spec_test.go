@@ -0,0 +1,141 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+type planChange struct {
+	kind   string
+	action string
+	name   string
+	fields []string
+}
+
+func TestPlanClusterSpec(t *testing.T) {
+	state := &clusterState{
+		nodePools: map[string]*pkgCluster.NodePoolSpec{
+			"pool1": {InstanceType: "m4.xlarge", Count: 3, MinCount: 1, MaxCount: 3, Labels: map[string]string{"team": "a"}},
+			"pool2": {InstanceType: "m4.xlarge", Autoscaling: true, Count: 5, MinCount: 2, MaxCount: 10},
+		},
+		features: map[string]bool{"monitoring": true, "logging": false},
+		secrets:  map[string]bool{"default/tls": true, "default/old": true},
+		releases: map[string]*releaseState{
+			"web": {name: "nginx", version: "1.0.0", namespace: "default", values: map[string]interface{}{"replicas": float64(2)}},
+			"old": {name: "redis", version: "5.0.0", namespace: "default"},
+		},
+	}
+
+	lastApplied := &pkgCluster.ClusterSpec{
+		Secrets: []pkgCluster.ClusterSecretSpec{
+			{Name: "tls", Namespace: "default", SourceSecretName: "tls-v1"},
+			{Name: "old", Namespace: "default", SourceSecretName: "old"},
+		},
+		Releases: []pkgCluster.ClusterReleaseSpec{
+			{ReleaseName: "web", Name: "stable/nginx"},
+			{ReleaseName: "old", Name: "stable/redis"},
+		},
+	}
+
+	tests := map[string]struct {
+		desired  *pkgCluster.ClusterSpec
+		expected []planChange
+	}{
+		"empty spec": {
+			desired: &pkgCluster.ClusterSpec{},
+			expected: []planChange{
+				{pkgCluster.ClusterSpecSecret, pkgCluster.ClusterSpecDelete, "default/tls", nil},
+				{pkgCluster.ClusterSpecSecret, pkgCluster.ClusterSpecDelete, "default/old", nil},
+				{pkgCluster.ClusterSpecRelease, pkgCluster.ClusterSpecDelete, "web", nil},
+				{pkgCluster.ClusterSpecRelease, pkgCluster.ClusterSpecDelete, "old", nil},
+			},
+		},
+		"up to date": {
+			desired: &pkgCluster.ClusterSpec{
+				NodePools: map[string]*pkgCluster.NodePoolSpec{
+					"pool1": {Count: 3},
+					"pool2": {Autoscaling: true, Count: 3, MinCount: 2, MaxCount: 10},
+				},
+				Features: pkgCluster.ClusterFeaturesSpec{
+					Monitoring: &pkgCluster.ClusterFeatureSpec{Enabled: true},
+					Logging:    &pkgCluster.ClusterFeatureSpec{Enabled: false},
+				},
+				Secrets:  lastApplied.Secrets,
+				Releases: []pkgCluster.ClusterReleaseSpec{{ReleaseName: "web", Name: "stable/nginx", Values: map[string]interface{}{"replicas": 2}}, lastApplied.Releases[1]},
+			},
+			expected: []planChange{},
+		},
+		"node pools": {
+			desired: &pkgCluster.ClusterSpec{
+				NodePools: map[string]*pkgCluster.NodePoolSpec{
+					"pool1": {InstanceType: "m4.2xlarge", Count: 4, Labels: map[string]string{"team": "b"}},
+					"pool3": {InstanceType: "m4.xlarge", Count: 1},
+				},
+				Secrets:  lastApplied.Secrets,
+				Releases: []pkgCluster.ClusterReleaseSpec{{ReleaseName: "web", Name: "stable/nginx", Values: map[string]interface{}{"replicas": 2}}, lastApplied.Releases[1]},
+			},
+			expected: []planChange{
+				{pkgCluster.ClusterSpecNodePool, pkgCluster.ClusterSpecUpdate, "pool1", []string{"instanceType", "count", "labels"}},
+				{pkgCluster.ClusterSpecNodePool, pkgCluster.ClusterSpecCreate, "pool3", nil},
+				{pkgCluster.ClusterSpecNodePool, pkgCluster.ClusterSpecDelete, "pool2", nil},
+			},
+		},
+		"features, secrets and releases": {
+			desired: &pkgCluster.ClusterSpec{
+				Features: pkgCluster.ClusterFeaturesSpec{
+					Monitoring: &pkgCluster.ClusterFeatureSpec{Enabled: false},
+					Logging:    &pkgCluster.ClusterFeatureSpec{Enabled: true, Params: map[string]interface{}{"bucketName": "logs"}},
+				},
+				Secrets: []pkgCluster.ClusterSecretSpec{
+					{Name: "tls", Namespace: "default", SourceSecretName: "tls-v2"},
+					{Name: "db", Namespace: "default", SourceSecretName: "db"},
+				},
+				Releases: []pkgCluster.ClusterReleaseSpec{
+					{ReleaseName: "web", Name: "stable/nginx", Version: "1.1.0", Values: map[string]interface{}{"replicas": 3}},
+					{ReleaseName: "cache", Name: "stable/memcached"},
+				},
+			},
+			expected: []planChange{
+				{pkgCluster.ClusterSpecFeature, pkgCluster.ClusterSpecDelete, "monitoring", nil},
+				{pkgCluster.ClusterSpecFeature, pkgCluster.ClusterSpecCreate, "logging", nil},
+				{pkgCluster.ClusterSpecSecret, pkgCluster.ClusterSpecUpdate, "default/tls", []string{"sourceSecretName"}},
+				{pkgCluster.ClusterSpecSecret, pkgCluster.ClusterSpecCreate, "default/db", nil},
+				{pkgCluster.ClusterSpecSecret, pkgCluster.ClusterSpecDelete, "default/old", nil},
+				{pkgCluster.ClusterSpecRelease, pkgCluster.ClusterSpecUpdate, "web", []string{"version", "values"}},
+				{pkgCluster.ClusterSpecRelease, pkgCluster.ClusterSpecCreate, "cache", nil},
+				{pkgCluster.ClusterSpecRelease, pkgCluster.ClusterSpecDelete, "old", nil},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			changes := planClusterSpec(state, test.desired, lastApplied)
+
+			actual := make([]planChange, 0, len(changes))
+			for _, change := range changes {
+				actual = append(actual, planChange{change.Kind, change.Action, change.Name, change.Fields})
+			}
+
+			if !reflect.DeepEqual(test.expected, actual) {
+				t.Errorf("unexpected changes\nexpected: %v\nactual:   %v", test.expected, actual)
+			}
+		})
+	}
+}
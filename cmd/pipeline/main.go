@@ -50,6 +50,10 @@ import (
 	intClusterAuth "github.com/banzaicloud/pipeline/internal/cluster/auth"
 	"github.com/banzaicloud/pipeline/internal/cluster/clustersecret"
 	"github.com/banzaicloud/pipeline/internal/cluster/clustersecret/clustersecretadapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/cost"
+	"github.com/banzaicloud/pipeline/internal/cluster/recommender"
+	"github.com/banzaicloud/pipeline/internal/cluster/resourceusage"
+	"github.com/banzaicloud/pipeline/internal/cluster/spotinterruption"
 	"github.com/banzaicloud/pipeline/internal/dashboard"
 	"github.com/banzaicloud/pipeline/internal/monitor"
 	"github.com/banzaicloud/pipeline/internal/notification"
@@ -57,6 +61,7 @@ import (
 	"github.com/banzaicloud/pipeline/internal/platform/gin/correlationid"
 	ginlog "github.com/banzaicloud/pipeline/internal/platform/gin/log"
 	platformlog "github.com/banzaicloud/pipeline/internal/platform/log"
+	"github.com/banzaicloud/pipeline/internal/servicemesh"
 	"github.com/banzaicloud/pipeline/model/defaults"
 	"github.com/banzaicloud/pipeline/pkg/k8sclient"
 	"github.com/banzaicloud/pipeline/pkg/providers"
@@ -237,6 +242,39 @@ func main() {
 		go monitor.NewSpotMetricsExporter(context.Background(), clusterManager, log.WithField("subsystem", "spot-metrics-exporter")).Run(viper.GetDuration(config.SpotMetricsCollectionInterval))
 	}
 
+	if viper.GetBool(config.ResourceUsageEnabled) {
+		go resourceusage.NewCollector(
+			context.Background(),
+			clusterManager,
+			resourceusage.NewStore(db),
+			resourceusage.CollectorConfig{
+				Interval:     viper.GetDuration(config.ResourceUsageInterval),
+				RawRetention: viper.GetDuration(config.ResourceUsageRawRetention),
+				Resolution:   viper.GetDuration(config.ResourceUsageResolution),
+				Retention:    viper.GetDuration(config.ResourceUsageRetention),
+			},
+			log.WithField("subsystem", "resource-usage-collector"),
+			errorHandler,
+		).Run()
+	}
+
+	if viper.GetBool(config.SpotInterruptionEnabled) {
+		go spotinterruption.NewCollector(
+			context.Background(),
+			clusterManager,
+			spotinterruption.NewStore(db),
+			spotinterruption.NewEvents(clusterEventBus),
+			spotinterruption.CollectorConfig{
+				Interval:           viper.GetDuration(config.SpotInterruptionInterval),
+				ReschedulingWindow: viper.GetDuration(config.SpotInterruptionReschedulingWindow),
+				TrackingPeriod:     viper.GetDuration(config.SpotInterruptionTrackingPeriod),
+				Retention:          viper.GetDuration(config.SpotInterruptionRetention),
+			},
+			log.WithField("subsystem", "spot-interruption-collector"),
+			errorHandler,
+		).Run()
+	}
+
 	clusterAPI := api.NewClusterAPI(clusterManager, clusterGetter, workflowClient, log, errorHandler, externalBaseURL)
 
 	//Initialise Gin router
@@ -284,18 +322,38 @@ func main() {
 	dgroup.Use(api.OrganizationMiddleware)
 	dgroup.Use(authorizationMiddleware)
 	dgroup.GET("/:orgid/clusters", dashboard.GetDashboard)
+	dgroup.GET("/:orgid/clusters/:id/usage", dashboard.GetClusterUsage)
 
 	domainAPI := api.NewDomainAPI(clusterManager, log, errorHandler)
+	nodePoolRecommendationAPI := api.NewNodePoolRecommendationAPI(recommender.NewRecommender(recommender.NewCloudInfoMachineTypesGetter()), log, errorHandler)
+	serviceMeshAPI := api.NewServiceMeshAPI(clusterGetter, log, errorHandler)
+	loggingAPI := api.NewLoggingAPI(clusterGetter, log, errorHandler)
+	meshManager := servicemesh.NewManager(clusterManager, servicemesh.NewStore(db), secret.Store, log, errorHandler)
+	meshAPI := api.NewMeshAPI(meshManager, log, errorHandler)
+	spotInterruptionAPI := api.NewSpotInterruptionAPI(clusterGetter, spotinterruption.NewStore(db), log, errorHandler)
+	costAPI := api.NewCostAPI(clusterManager, clusterGetter, cost.NewEstimator(cost.NewCloudInfoMachineDetailsGetter()), log, errorHandler)
 	organizationAPI := api.NewOrganizationAPI(githubImporter)
 	userAPI := api.NewUserAPI(accessManager, db, log, errorHandler)
 	networkAPI := api.NewNetworkAPI(log)
 
-	sharedSpotguideOrg, err := spotguide.CreateSharedSpotguideOrganization(config.DB(), viper.GetString(config.SpotguideSharedLibraryGitHubOrganization))
+	sharedSpotguideProvider := viper.GetString(config.SpotguideSharedLibraryProvider)
+	sharedSpotguideOrg, err := spotguide.CreateSharedSpotguideOrganization(config.DB(), sharedSpotguideProvider, viper.GetString(config.SpotguideSharedLibraryGitHubOrganization))
 	if err != nil {
 		log.Errorf("failed to create shared Spotguide organization: %s", err)
 	}
 
-	spotguideManager := spotguide.NewSpotguideManager(config.DB(), version, viper.GetString("github.token"), sharedSpotguideOrg)
+	// the shared library is accessed with the token of the configured provider (github.token, gitlab.token or git.token)
+	spotguideManager := spotguide.NewSpotguideManager(config.DB(), version, sharedSpotguideProvider, viper.GetString(sharedSpotguideProvider+".token"), sharedSpotguideOrg)
+
+	// launches are executed in the background, the ones running before a restart can't be resumed
+	if err := spotguideManager.FailInterruptedLaunches(); err != nil {
+		log.Errorf("failed to mark interrupted spotguide launches as failed: %s", err)
+	}
+
+	// mesh members are joined and removed in the background as well
+	if err := meshManager.FailInterruptedMembers(); err != nil {
+		log.Errorf("failed to mark interrupted mesh members as failed: %s", err)
+	}
 
 	// subscribe to organization creations and sync spotguides into the newly created organizations
 	spotguide.AuthEventEmitter.NotifyOrganizationRegistered(func(orgID uint, userID uint) {
@@ -339,17 +397,52 @@ func main() {
 			orgs.GET("/:orgid/spotguides/:owner/:name", spotguideAPI.GetSpotguide)
 			orgs.HEAD("/:orgid/spotguides/:owner/:name", spotguideAPI.GetSpotguide)
 			orgs.GET("/:orgid/spotguides/:owner/:name/icon", spotguideAPI.GetSpotguideIcon)
+			spotguideAPI.RegisterLaunchRoutes(orgs.Group("/:orgid"))
+			orgs.GET("/:orgid/spotguidecatalogs", spotguideAPI.ListSpotguideCatalogs)
+			orgs.POST("/:orgid/spotguidecatalogs", spotguideAPI.CreateSpotguideCatalog)
+			orgs.DELETE("/:orgid/spotguidecatalogs/:id", spotguideAPI.DeleteSpotguideCatalog)
+			orgs.GET("/:orgid/spotguidesettings", spotguideAPI.GetSpotguideSettings)
+			orgs.PUT("/:orgid/spotguidesettings", spotguideAPI.UpdateSpotguideSettings)
 
 			orgs.GET("/:orgid/domain", domainAPI.GetDomain)
+			orgs.GET("/:orgid/costs", costAPI.GetOrganizationCosts)
 			orgs.POST("/:orgid/clusters", clusterAPI.CreateClusterRequest)
+			orgs.POST("/:orgid/clusterimports", clusterAPI.ImportClusterRequest)
+			orgs.POST("/:orgid/nodepoolrecommendations", nodePoolRecommendationAPI.RecommendNodePools)
+			orgs.GET("/:orgid/clustertemplates", api.ListClusterTemplates)
+			orgs.POST("/:orgid/clustertemplates", api.CreateClusterTemplate)
+			orgs.GET("/:orgid/clustertemplates/:name", api.GetClusterTemplate)
+			orgs.PUT("/:orgid/clustertemplates/:name", api.UpdateClusterTemplate)
+			orgs.DELETE("/:orgid/clustertemplates/:name", api.DeleteClusterTemplate)
+			orgs.GET("/:orgid/clustertemplates/:name/versions", api.ListClusterTemplateVersions)
+			orgs.GET("/:orgid/clustertemplates/:name/clusters", api.ListClusterTemplateClusters)
 			//v1.GET("/status", api.Status)
 			orgs.GET("/:orgid/clusters", clusterAPI.GetClusters)
 			orgs.GET("/:orgid/clusters/:id", clusterAPI.GetCluster)
 			orgs.GET("/:orgid/clusters/:id/pods", api.GetPodDetails)
 			orgs.GET("/:orgid/clusters/:id/bootstrap", clusterAPI.GetBootstrapInfo)
+			orgs.GET("/:orgid/clusters/:id/costs", costAPI.GetClusterCosts)
+			orgs.GET("/:orgid/clusters/:id/interruptions", spotInterruptionAPI.GetClusterInterruptions)
+			orgs.GET("/:orgid/clusters/:id/servicemesh", serviceMeshAPI.GetServiceMesh)
+			orgs.PATCH("/:orgid/clusters/:id/servicemesh", serviceMeshAPI.UpdateServiceMesh)
+			orgs.GET("/:orgid/clusters/:id/servicemesh/canaries", serviceMeshAPI.ListCanaries)
+			orgs.PUT("/:orgid/clusters/:id/servicemesh/canaries", serviceMeshAPI.ApplyCanary)
+			orgs.GET("/:orgid/clusters/:id/logging/flows", loggingAPI.ListFlows)
+			orgs.PUT("/:orgid/clusters/:id/logging/flows", loggingAPI.ApplyFlow)
+			orgs.DELETE("/:orgid/clusters/:id/logging/flows/:name", loggingAPI.DeleteFlow)
+
+			orgs.GET("/:orgid/meshes", meshAPI.ListMeshes)
+			orgs.POST("/:orgid/meshes", meshAPI.CreateMesh)
+			orgs.GET("/:orgid/meshes/:meshid", meshAPI.GetMeshTopology)
+			orgs.DELETE("/:orgid/meshes/:meshid", meshAPI.DeleteMesh)
+			orgs.POST("/:orgid/meshes/:meshid/members", meshAPI.AddMember)
+			orgs.DELETE("/:orgid/meshes/:meshid/members/:clusterid", meshAPI.RemoveMember)
 			orgs.PUT("/:orgid/clusters/:id", clusterAPI.UpdateCluster)
 
 			orgs.PUT("/:orgid/clusters/:id/posthooks", clusterAPI.ReRunPostHooks)
+			orgs.GET("/:orgid/clusters/:id/spec", clusterAPI.GetClusterSpec)
+			orgs.PUT("/:orgid/clusters/:id/spec", clusterAPI.ApplyClusterSpec)
+			orgs.POST("/:orgid/clusters/:id/spec/plan", clusterAPI.PlanClusterSpec)
 			orgs.POST("/:orgid/clusters/:id/secrets", api.InstallSecretsToCluster)
 			orgs.POST("/:orgid/clusters/:id/secrets/:secretName", api.InstallSecretToCluster)
 			orgs.PATCH("/:orgid/clusters/:id/secrets/:secretName", api.MergeSecretInCluster)
@@ -0,0 +1,86 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/spotinterruption/watcher"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ServiceName is an identifier-like name used anywhere this app needs to be identified.
+const ServiceName = "spotwatcher"
+
+// checkInterval is the time between two checks of the instance metadata,
+// GCE gives 30 seconds notice before preempting an instance.
+const checkInterval = 5 * time.Second
+
+func main() {
+	logger := logrus.New().WithField("application", ServiceName)
+	logger.WithField("version", version).Info("starting spot interruption watcher")
+
+	nodeName := os.Getenv("NODE_NAME")
+	if nodeName == "" {
+		logger.Fatal("NODE_NAME environment variable is required")
+	}
+
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+
+	var checker watcher.NoticeChecker
+	switch cloud := os.Getenv("CLOUD"); cloud {
+	case pkgCluster.Google:
+		checker = watcher.NewGoogleNoticeChecker(httpClient)
+	case pkgCluster.Azure:
+		checker = watcher.NewAzureNoticeChecker(httpClient)
+	default:
+		logger.Fatalf("unsupported cloud: %q", cloud)
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		logger.WithError(err).Fatal("failed to get in-cluster config")
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create kubernetes client")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	err = watcher.NewWatcher(client, checker, nodeName, checkInterval, logger.WithField("node", nodeName)).Run(ctx)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to record interruption")
+	}
+
+	// the pod is kept running until the node goes away, so the notice is not checked again
+	<-ctx.Done()
+}
@@ -114,8 +114,11 @@ func main() {
 		emperror.Panic(err)
 
 		workflow.RegisterWithOptions(pkeworkflow.CreateClusterWorkflow, workflow.RegisterOptions{Name: pkeworkflow.CreateClusterWorkflowName})
+		workflow.RegisterWithOptions(pkeworkflow.CreateHostsClusterWorkflow, workflow.RegisterOptions{Name: pkeworkflow.CreateHostsClusterWorkflowName})
 		workflow.RegisterWithOptions(pkeworkflow.DeleteClusterWorkflow, workflow.RegisterOptions{Name: pkeworkflow.DeleteClusterWorkflowName})
 		workflow.RegisterWithOptions(pkeworkflow.UpdateClusterWorkflow, workflow.RegisterOptions{Name: pkeworkflow.UpdateClusterWorkflowName})
+		workflow.RegisterWithOptions(pkeworkflow.UpgradeClusterWorkflow, workflow.RegisterOptions{Name: pkeworkflow.UpgradeClusterWorkflowName})
+		workflow.RegisterWithOptions(pkeworkflow.RotateCertificatesWorkflow, workflow.RegisterOptions{Name: pkeworkflow.RotateCertificatesWorkflowName})
 
 		db, err := database.Connect(config.Database)
 		if err != nil {
@@ -168,6 +171,12 @@ func main() {
 		updateClusterNetworkActivitiy := pkeworkflow.NewUpdateClusterNetworkActivity(clusters)
 		activity.RegisterWithOptions(updateClusterNetworkActivitiy.Execute, activity.RegisterOptions{Name: pkeworkflow.UpdateClusterNetworkActivityName})
 
+		selectVPCSubnetsActivity := pkeworkflow.NewSelectVPCSubnetsActivity(awsClientFactory)
+		activity.RegisterWithOptions(selectVPCSubnetsActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.SelectVPCSubnetsActivityName})
+
+		createNLBActivity := pkeworkflow.NewCreateNLBActivity(awsClientFactory)
+		activity.RegisterWithOptions(createNLBActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.CreateNLBActivityName})
+
 		createElasticIPActivity := pkeworkflow.NewCreateElasticIPActivity(awsClientFactory)
 		activity.RegisterWithOptions(createElasticIPActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.CreateElasticIPActivityName})
 
@@ -198,6 +207,9 @@ func main() {
 		deleteElasticIPActivity := pkeworkflow.NewDeleteElasticIPActivity(clusters)
 		activity.RegisterWithOptions(deleteElasticIPActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.DeleteElasticIPActivityName})
 
+		deleteNLBActivity := pkeworkflow.NewDeleteNLBActivity(clusters)
+		activity.RegisterWithOptions(deleteNLBActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.DeleteNLBActivityName})
+
 		deleteVPCActivity := pkeworkflow.NewDeleteVPCActivity(clusters)
 		activity.RegisterWithOptions(deleteVPCActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.DeleteVPCActivityName})
 
@@ -207,6 +219,49 @@ func main() {
 		deleteSshKeyPairActivity := pkeworkflow.NewDeleteSSHKeyPairActivity(clusters)
 		activity.RegisterWithOptions(deleteSshKeyPairActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.DeleteSSHKeyPairActivityName})
 
+		kubernetesUpgrades := pkeworkflowadapter.NewKubernetesUpgradeStore(db)
+
+		listNodesActivity := pkeworkflow.NewListNodesActivity(clusters)
+		activity.RegisterWithOptions(listNodesActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.ListNodesActivityName})
+
+		updateUpgradeNodeActivity := pkeworkflow.NewUpdateUpgradeNodeActivity(kubernetesUpgrades)
+		activity.RegisterWithOptions(updateUpgradeNodeActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.UpdateUpgradeNodeActivityName})
+
+		updateUpgradeStatusActivity := pkeworkflow.NewUpdateUpgradeStatusActivity(kubernetesUpgrades)
+		activity.RegisterWithOptions(updateUpgradeStatusActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.UpdateUpgradeStatusActivityName})
+
+		drainNodeActivity := pkeworkflow.NewDrainNodeActivity(clusters)
+		activity.RegisterWithOptions(drainNodeActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.DrainNodeActivityName})
+
+		uncordonNodeActivity := pkeworkflow.NewUncordonNodeActivity(clusters)
+		activity.RegisterWithOptions(uncordonNodeActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.UncordonNodeActivityName})
+
+		upgradeNodeActivity := pkeworkflow.NewUpgradeNodeActivity(clusters, viper.GetString(conf.PKEUpgradeImage))
+		activity.RegisterWithOptions(upgradeNodeActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.UpgradeNodeActivityName})
+
+		replaceNodeActivity := pkeworkflow.NewReplaceNodeActivity(awsClientFactory, clusters)
+		activity.RegisterWithOptions(replaceNodeActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.ReplaceNodeActivityName})
+
+		updatePoolBootstrapActivity := pkeworkflow.NewUpdatePoolBootstrapActivity(clusters, tokenGenerator)
+		activity.RegisterWithOptions(updatePoolBootstrapActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.UpdatePoolBootstrapActivityName})
+
+		updateKubernetesVersionActivity := pkeworkflow.NewUpdateKubernetesVersionActivity(clusters)
+		activity.RegisterWithOptions(updateKubernetesVersionActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.UpdateKubernetesVersionActivityName})
+
+		updateCertificateRotationActivity := pkeworkflow.NewUpdateCertificateRotationActivity(pkeworkflowadapter.NewCertificateRotationStore(db))
+		activity.RegisterWithOptions(updateCertificateRotationActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.UpdateCertificateRotationActivityName})
+
+		runAgentCommandActivity := pkeworkflow.NewRunAgentCommandActivity(pkeworkflowadapter.NewAgentCommandStore(db))
+		activity.RegisterWithOptions(runAgentCommandActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.RunAgentCommandActivityName})
+
+		certificateAuthorities := pkeworkflowadapter.NewCertificateAuthorityStore(clusters, secret.Store)
+
+		generateNextCAsActivity := pkeworkflow.NewGenerateNextCAsActivity(certificateAuthorities)
+		activity.RegisterWithOptions(generateNextCAsActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.GenerateNextCAsActivityName})
+
+		promoteNextCAsActivity := pkeworkflow.NewPromoteNextCAsActivity(certificateAuthorities)
+		activity.RegisterWithOptions(promoteNextCAsActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.PromoteNextCAsActivityName})
+
 		workflow.RegisterWithOptions(cluster.RunPostHooksWorkflow, workflow.RegisterOptions{Name: cluster.RunPostHooksWorkflowName})
 
 		runPostHookActivity := cluster.NewRunPostHookActivity(clusterManager)
@@ -70,6 +70,8 @@ const (
 	EksTemplateLocation = "eks.templateLocation"
 	// EksASGFulfillmentTimeout configuration key for the timeout of EKS ASG instance fulfillments
 	EksASGFulfillmentTimeout = "eks.ASGFulfillmentTimeout"
+	// EksNodeDrainTimeout configuration key for the timeout of draining a node during EKS node image updates
+	EksNodeDrainTimeout = "eks.nodeDrainTimeout"
 
 	// AwsCredentialPath is the path in Vault to get AWS credentials from for Pipeline
 	AwsCredentialPath = "aws.credentials.path"
@@ -105,6 +107,24 @@ const (
 	SpotMetricsEnabled            = "spotmetrics.enabled"
 	SpotMetricsCollectionInterval = "spotmetrics.collectionInterval"
 
+	// Resource usage history
+	ResourceUsageEnabled      = "resourceusage.enabled"
+	ResourceUsageInterval     = "resourceusage.interval"
+	ResourceUsageRawRetention = "resourceusage.rawRetention"
+	ResourceUsageResolution   = "resourceusage.resolution"
+	ResourceUsageRetention    = "resourceusage.retention"
+
+	// Cluster autoscaler
+	AutoscalerExpander = "autoscaler.expander"
+
+	// Spot interruptions
+	SpotInterruptionEnabled            = "spotinterruption.enabled"
+	SpotInterruptionInterval           = "spotinterruption.interval"
+	SpotInterruptionReschedulingWindow = "spotinterruption.reschedulingWindow"
+	SpotInterruptionTrackingPeriod     = "spotinterruption.trackingPeriod"
+	SpotInterruptionRetention          = "spotinterruption.retention"
+	SpotInterruptionWatcherImage       = "spotinterruption.watcherImage"
+
 	// Database
 	DBAutoMigrateEnabled = "database.autoMigrateEnabled"
 
@@ -137,6 +157,10 @@ const (
 	SpotguideAllowPrivateRepos               = "spotguide.allowPrivateRepos"
 	SpotguideSyncInterval                    = "spotguide.syncInterval"
 	SpotguideSharedLibraryGitHubOrganization = "spotguide.sharedLibraryGitHubOrganization"
+	SpotguideSharedLibraryProvider           = "spotguide.sharedLibraryProvider"
+	SpotguideGitlabURL                       = "spotguide.gitlabURL"
+	SpotguideGitURL                          = "spotguide.gitURL"
+	SpotguideSharedLibraryPublicKey          = "spotguide.sharedLibraryPublicKey"
 
 	// full endpoint url of CloudInfo for ex: https://alpha.dev.banzaicloud.com/cloudinfo/api/v1
 	CloudInfoEndPoint = "cloudinfo.endpointUrl"
@@ -155,6 +179,9 @@ const (
 	// Default regions config keys to initialize clients
 	AmazonInitializeRegionKey  = "amazon.defaultApiRegion"
 	AlibabaInitializeRegionKey = "alibaba.defaultApiRegion"
+
+	// PKE constants
+	PKEUpgradeImage = "pke.upgradeImage" // image of the pods running the PKE upgrade on the nodes
 )
 
 //Init initializes the configurations
@@ -247,6 +274,21 @@ func init() {
 	viper.SetDefault(SpotMetricsEnabled, false)
 	viper.SetDefault(SpotMetricsCollectionInterval, "30s")
 
+	viper.SetDefault(ResourceUsageEnabled, true)
+	viper.SetDefault(ResourceUsageInterval, "5m")
+	viper.SetDefault(ResourceUsageRawRetention, "24h")
+	viper.SetDefault(ResourceUsageResolution, "1h")
+	viper.SetDefault(ResourceUsageRetention, "720h")
+
+	viper.SetDefault(AutoscalerExpander, "least-waste")
+
+	viper.SetDefault(SpotInterruptionEnabled, true)
+	viper.SetDefault(SpotInterruptionInterval, "30s")
+	viper.SetDefault(SpotInterruptionReschedulingWindow, "5m")
+	viper.SetDefault(SpotInterruptionTrackingPeriod, "1h")
+	viper.SetDefault(SpotInterruptionRetention, "720h")
+	viper.SetDefault(SpotInterruptionWatcherImage, "banzaicloud/pipeline:latest")
+
 	viper.SetDefault(MonitorEnabled, false)
 	viper.SetDefault(MonitorConfigMap, "")
 	viper.SetDefault(MonitorConfigMapPrometheusKey, "prometheus.yml")
@@ -264,11 +306,16 @@ func init() {
 	viper.SetDefault(PipelineSystemNamespace, "pipeline-system")
 	viper.SetDefault(EksTemplateLocation, filepath.Join(pwd, "templates", "eks"))
 	viper.SetDefault(EksASGFulfillmentTimeout, "10m")
+	viper.SetDefault(EksNodeDrainTimeout, "10m")
 
 	viper.SetDefault(SpotguideAllowPrereleases, false)
 	viper.SetDefault(SpotguideAllowPrivateRepos, false)
 	viper.SetDefault(SpotguideSyncInterval, 5*time.Minute)
 	viper.SetDefault(SpotguideSharedLibraryGitHubOrganization, "spotguides")
+	viper.SetDefault(SpotguideSharedLibraryProvider, "github")
+	viper.SetDefault(SpotguideGitlabURL, "https://gitlab.com")
+	viper.SetDefault(SpotguideGitURL, "")
+	viper.SetDefault(SpotguideSharedLibraryPublicKey, "")
 
 	viper.SetDefault("issue.type", "github")
 	viper.SetDefault("issue.githubLabels", []string{"community"})
@@ -302,6 +349,8 @@ func init() {
 	viper.SetDefault(PrometheusServiceContext, "prometheus")
 	viper.SetDefault(PrometheusLocalPort, 9090)
 
+	viper.SetDefault(PKEUpgradeImage, "alpine:3.9")
+
 	// Find and read the config file
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatalf("Error reading config file, %s", err)
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `spotguide_launch_steps`;
+DROP TABLE IF EXISTS `spotguide_launches`;
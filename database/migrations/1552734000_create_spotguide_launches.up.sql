@@ -0,0 +1,28 @@
+CREATE TABLE `spotguide_launches` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `user_id` int(10) unsigned DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `spotguide_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `spotguide_version` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `repo_provider` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `repo_fullname` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `status` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `status_message` text COLLATE utf8mb4_unicode_ci,
+  `request` text COLLATE utf8mb4_unicode_ci,
+  PRIMARY KEY (`id`),
+  KEY `idx_spotguide_launches_organization_id` (`organization_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `spotguide_launch_steps` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `launch_id` int(10) unsigned DEFAULT NULL,
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `status` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `message` text COLLATE utf8mb4_unicode_ci,
+  `started_at` timestamp NULL DEFAULT NULL,
+  `finished_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_spotguide_launch_steps_launch_id` (`launch_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,12 @@
+CREATE TABLE `spotguide_upgrades` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `launch_id` int(10) unsigned DEFAULT NULL,
+  `user_id` int(10) unsigned DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `from_version` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `to_version` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `branch` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `pull_request_url` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_spotguide_upgrades_launch_id` (`launch_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `spotguide_catalogs`;
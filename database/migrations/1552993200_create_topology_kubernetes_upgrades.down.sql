@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `topology_kubernetes_upgrade_nodes`;
+DROP TABLE IF EXISTS `topology_kubernetes_upgrades`;
@@ -0,0 +1,28 @@
+CREATE TABLE `topology_kubernetes_upgrades` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `created_by` int(10) unsigned DEFAULT NULL,
+  `from_version` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `to_version` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `strategy` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `status` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `status_message` text COLLATE utf8mb4_unicode_ci,
+  PRIMARY KEY (`id`),
+  KEY `idx_topology_kubernetes_upgrades_cluster_id` (`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `topology_kubernetes_upgrade_nodes` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `upgrade_id` int(10) unsigned DEFAULT NULL,
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `node_pool` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `master` tinyint(1) DEFAULT NULL,
+  `status` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `message` text COLLATE utf8mb4_unicode_ci,
+  `started_at` timestamp NULL DEFAULT NULL,
+  `finished_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_topology_kubernetes_upgrade_nodes_upgrade_id` (`upgrade_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+ALTER TABLE `topology_networks` DROP COLUMN `provider_config`;
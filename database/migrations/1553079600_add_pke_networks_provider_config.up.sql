@@ -0,0 +1,2 @@
+ALTER TABLE `topology_networks`
+  ADD `provider_config` text COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `topology_agent_commands`;
+DROP TABLE IF EXISTS `topology_certificate_rotations`;
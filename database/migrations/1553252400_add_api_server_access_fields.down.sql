@@ -0,0 +1,15 @@
+ALTER TABLE `amazon_eks_clusters` DROP COLUMN `api_server_private_endpoint`;
+ALTER TABLE `amazon_eks_clusters` DROP COLUMN `api_server_public_access_cidrs`;
+ALTER TABLE `amazon_eks_clusters` DROP COLUMN `api_server_private_nodes`;
+ALTER TABLE `amazon_eks_clusters` DROP COLUMN `api_server_tunnel_agent_address`;
+
+ALTER TABLE `azure_aks_clusters` DROP COLUMN `api_server_private_endpoint`;
+ALTER TABLE `azure_aks_clusters` DROP COLUMN `api_server_public_access_cidrs`;
+ALTER TABLE `azure_aks_clusters` DROP COLUMN `api_server_private_nodes`;
+ALTER TABLE `azure_aks_clusters` DROP COLUMN `api_server_tunnel_agent_address`;
+
+ALTER TABLE `google_gke_clusters` DROP COLUMN `api_server_private_endpoint`;
+ALTER TABLE `google_gke_clusters` DROP COLUMN `api_server_public_access_cidrs`;
+ALTER TABLE `google_gke_clusters` DROP COLUMN `api_server_private_nodes`;
+ALTER TABLE `google_gke_clusters` DROP COLUMN `api_server_tunnel_agent_address`;
+ALTER TABLE `google_gke_clusters` DROP COLUMN `master_ipv4_cidr`;
@@ -0,0 +1,15 @@
+ALTER TABLE `amazon_eks_clusters` ADD COLUMN `api_server_private_endpoint` boolean DEFAULT false;
+ALTER TABLE `amazon_eks_clusters` ADD COLUMN `api_server_public_access_cidrs` text;
+ALTER TABLE `amazon_eks_clusters` ADD COLUMN `api_server_private_nodes` boolean DEFAULT false;
+ALTER TABLE `amazon_eks_clusters` ADD COLUMN `api_server_tunnel_agent_address` varchar(255) DEFAULT NULL;
+
+ALTER TABLE `azure_aks_clusters` ADD COLUMN `api_server_private_endpoint` boolean DEFAULT false;
+ALTER TABLE `azure_aks_clusters` ADD COLUMN `api_server_public_access_cidrs` text;
+ALTER TABLE `azure_aks_clusters` ADD COLUMN `api_server_private_nodes` boolean DEFAULT false;
+ALTER TABLE `azure_aks_clusters` ADD COLUMN `api_server_tunnel_agent_address` varchar(255) DEFAULT NULL;
+
+ALTER TABLE `google_gke_clusters` ADD COLUMN `api_server_private_endpoint` boolean DEFAULT false;
+ALTER TABLE `google_gke_clusters` ADD COLUMN `api_server_public_access_cidrs` text;
+ALTER TABLE `google_gke_clusters` ADD COLUMN `api_server_private_nodes` boolean DEFAULT false;
+ALTER TABLE `google_gke_clusters` ADD COLUMN `api_server_tunnel_agent_address` varchar(255) DEFAULT NULL;
+ALTER TABLE `google_gke_clusters` ADD COLUMN `master_ipv4_cidr` varchar(18) DEFAULT NULL;
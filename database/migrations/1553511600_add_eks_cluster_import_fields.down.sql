@@ -0,0 +1,6 @@
+ALTER TABLE `amazon_eks_clusters` DROP COLUMN `imported`;
+ALTER TABLE `amazon_eks_clusters` DROP COLUMN `security_group_id`;
+ALTER TABLE `amazon_eks_clusters` DROP COLUMN `node_security_group_id`;
+ALTER TABLE `amazon_eks_clusters` DROP COLUMN `node_instance_role_id`;
+
+ALTER TABLE `amazon_node_pools` DROP COLUMN `auto_scaling_group_name`;
@@ -0,0 +1,6 @@
+ALTER TABLE `amazon_eks_clusters` ADD COLUMN `imported` boolean DEFAULT false;
+ALTER TABLE `amazon_eks_clusters` ADD COLUMN `security_group_id` varchar(32) DEFAULT NULL;
+ALTER TABLE `amazon_eks_clusters` ADD COLUMN `node_security_group_id` varchar(32) DEFAULT NULL;
+ALTER TABLE `amazon_eks_clusters` ADD COLUMN `node_instance_role_id` varchar(255) DEFAULT NULL;
+
+ALTER TABLE `amazon_node_pools` ADD COLUMN `auto_scaling_group_name` varchar(255) DEFAULT NULL;
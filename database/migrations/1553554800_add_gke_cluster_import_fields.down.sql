@@ -0,0 +1 @@
+ALTER TABLE `google_gke_clusters` DROP COLUMN `imported`;
@@ -0,0 +1 @@
+ALTER TABLE `google_gke_clusters` ADD COLUMN `imported` boolean DEFAULT false;
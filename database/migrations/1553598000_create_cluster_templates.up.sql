@@ -0,0 +1,27 @@
+CREATE TABLE `cluster_templates` (
+    `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+    `organization_id` int(10) unsigned DEFAULT NULL,
+    `name` varchar(64) DEFAULT NULL,
+    `version` int(10) unsigned DEFAULT NULL,
+    `description` text,
+    `extends` varchar(64) DEFAULT NULL,
+    `extends_version` int(10) unsigned DEFAULT NULL,
+    `parameters` text,
+    `spec` text,
+    `created_at` timestamp NULL DEFAULT NULL,
+    `created_by` int(10) unsigned DEFAULT NULL,
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE UNIQUE INDEX idx_cluster_template_org_name_version ON `cluster_templates` (`organization_id`, `name`, `version`);
+
+CREATE TABLE `cluster_template_clusters` (
+    `cluster_id` int(10) unsigned NOT NULL,
+    `organization_id` int(10) unsigned DEFAULT NULL,
+    `template_name` varchar(64) DEFAULT NULL,
+    `template_version` int(10) unsigned DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE INDEX idx_cluster_template_cluster_org_name ON `cluster_template_clusters` (`organization_id`, `template_name`);
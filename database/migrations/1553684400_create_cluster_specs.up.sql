@@ -0,0 +1,7 @@
+CREATE TABLE `cluster_specs` (
+    `cluster_id` int(10) unsigned NOT NULL,
+    `spec` text,
+    `updated_at` timestamp NULL DEFAULT NULL,
+    `updated_by` int(10) unsigned DEFAULT NULL,
+    PRIMARY KEY (`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,18 @@
+CREATE TABLE `cluster_resource_usage_samples` (
+    `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id` int(10) unsigned NOT NULL,
+    `node_pool` varchar(255) NOT NULL,
+    `sampled_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `resolution` int(10) unsigned NOT NULL,
+    `nodes` double NOT NULL,
+    `cpu_request` double NOT NULL,
+    `cpu_limit` double NOT NULL,
+    `cpu_allocatable` double NOT NULL,
+    `cpu_capacity` double NOT NULL,
+    `memory_request` double NOT NULL,
+    `memory_limit` double NOT NULL,
+    `memory_allocatable` double NOT NULL,
+    `memory_capacity` double NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_resource_usage_samples_cluster_id_sampled_at` (`cluster_id`, `sampled_at`, `node_pool`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
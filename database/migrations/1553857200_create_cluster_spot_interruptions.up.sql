@@ -0,0 +1,19 @@
+CREATE TABLE `cluster_spot_interruptions` (
+    `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+    `created_at` timestamp NULL DEFAULT NULL,
+    `updated_at` timestamp NULL DEFAULT NULL,
+    `cluster_id` int(10) unsigned NOT NULL,
+    `event_uid` varchar(255) NOT NULL,
+    `node_name` varchar(255) NOT NULL,
+    `node_pool` varchar(255) NOT NULL,
+    `instance_type` varchar(255) NOT NULL,
+    `noticed_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `owners` text,
+    `rescheduled_pods` int(11) NOT NULL,
+    `pending_pods` int(11) NOT NULL,
+    `rescheduled_at` timestamp NULL DEFAULT NULL,
+    `completed` tinyint(1) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_spot_interruptions_cluster_id_event_uid` (`cluster_id`, `event_uid`),
+    KEY `idx_cluster_spot_interruptions_cluster_id_noticed_at` (`cluster_id`, `noticed_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `service_mesh_members`;
+DROP TABLE IF EXISTS `service_meshes`;
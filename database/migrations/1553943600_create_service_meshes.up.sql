@@ -0,0 +1,25 @@
+CREATE TABLE `service_meshes` (
+    `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+    `created_at` timestamp NULL DEFAULT NULL,
+    `updated_at` timestamp NULL DEFAULT NULL,
+    `organization_id` int(10) unsigned NOT NULL,
+    `name` varchar(255) NOT NULL,
+    `ca_secret_id` varchar(255) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_service_meshes_organization_id_name` (`organization_id`, `name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `service_mesh_members` (
+    `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+    `created_at` timestamp NULL DEFAULT NULL,
+    `updated_at` timestamp NULL DEFAULT NULL,
+    `mesh_id` int(10) unsigned NOT NULL,
+    `cluster_id` int(10) unsigned NOT NULL,
+    `cluster_name` varchar(255) NOT NULL,
+    `role` varchar(255) NOT NULL,
+    `status` varchar(255) NOT NULL,
+    `status_message` text,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uix_service_mesh_members_cluster_id` (`cluster_id`),
+    KEY `idx_service_mesh_members_mesh_id` (`mesh_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+ALTER TABLE `topology_nodepool_hosts` DROP COLUMN `last_check_in`;
@@ -0,0 +1 @@
+ALTER TABLE `topology_nodepool_hosts` ADD COLUMN `last_check_in` timestamp NULL DEFAULT NULL;
@@ -0,0 +1 @@
+ALTER TABLE `spotguide_repos` ADD COLUMN `provider` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT 'github';
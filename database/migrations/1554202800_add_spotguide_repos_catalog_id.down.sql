@@ -0,0 +1 @@
+ALTER TABLE `spotguide_repos` DROP COLUMN `catalog_id`;
@@ -0,0 +1 @@
+ALTER TABLE `spotguide_repos` ADD COLUMN `catalog_id` int(10) unsigned NOT NULL DEFAULT 0;
@@ -58,9 +58,19 @@ type VMKey struct {
 	instanceType string
 }
 
+type regionKey struct {
+	cloud    string
+	service  string
+	region   string
+	location string
+}
+
 // nolint: gochecknoglobals
 var instanceTypeMap = make(map[VMKey]MachineDetails)
 
+// nolint: gochecknoglobals
+var regionMachineTypesMap = make(map[regionKey][]MachineDetails)
+
 func fetchMachineTypes(cloud string, service string, region string, location string) error {
 	cloudInfoEndPoint := viper.GetString(config.CloudInfoEndPoint)
 	if len(cloudInfoEndPoint) == 0 {
@@ -85,7 +95,9 @@ func fetchMachineTypes(cloud string, service string, region string, location str
 	var vmDetails CloudInfoResponse
 	json.Unmarshal(respBody, &vmDetails)
 
+	machineTypes := make([]MachineDetails, 0, len(vmDetails.Products))
 	for _, product := range vmDetails.Products {
+		machineTypes = append(machineTypes, *product)
 		instanceTypeMap[VMKey{
 			cloud,
 			service,
@@ -94,6 +106,7 @@ func fetchMachineTypes(cloud string, service string, region string, location str
 			product.Type,
 		}] = *product
 	}
+	regionMachineTypesMap[regionKey{cloud, service, region, location}] = machineTypes
 
 	return nil
 }
@@ -120,3 +133,19 @@ func GetMachineDetails(cloud string, service string, region string, location str
 
 	return &vmDetails, nil
 }
+
+// GetMachineTypes returns the details of every machine type available in a region.
+func GetMachineTypes(cloud string, service string, region string, location string) ([]MachineDetails, error) {
+	key := regionKey{cloud, service, region, location}
+
+	machineTypes, ok := regionMachineTypesMap[key]
+	if !ok {
+		err := fetchMachineTypes(cloud, service, region, location)
+		if err != nil {
+			return nil, emperror.WrapWith(err, "failed to retrieve service machine types", "cloud", cloud, "region", region, "service", service)
+		}
+		machineTypes = regionMachineTypesMap[key]
+	}
+
+	return machineTypes, nil
+}
@@ -0,0 +1,317 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/internal/cloudinfo"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/goph/emperror"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	resourcev1 "k8s.io/kubernetes/pkg/api/v1/resource"
+)
+
+// HoursPerMonth is the average number of hours in a month used for monthly estimates.
+const HoursPerMonth = 730
+
+// UnallocatedNamespace is the name the cost of node pool resources not requested by any pod is attributed to.
+const UnallocatedNamespace = "(unallocated)"
+
+// MachineDetailsGetter returns the details and prices of a machine type.
+type MachineDetailsGetter interface {
+	GetMachineDetails(cloud string, service string, region string, location string, instanceType string) (*cloudinfo.MachineDetails, error)
+}
+
+type cloudInfoMachineDetailsGetter struct{}
+
+// NewCloudInfoMachineDetailsGetter returns a MachineDetailsGetter querying the cloudinfo service.
+func NewCloudInfoMachineDetailsGetter() MachineDetailsGetter {
+	return cloudInfoMachineDetailsGetter{}
+}
+
+func (cloudInfoMachineDetailsGetter) GetMachineDetails(cloud string, service string, region string, location string, instanceType string) (*cloudinfo.MachineDetails, error) {
+	return cloudinfo.GetMachineDetails(cloud, service, region, location, instanceType)
+}
+
+// Cost is an hourly and monthly cost estimate in USD.
+type Cost struct {
+	OnDemandHourly float64 `json:"onDemandHourly"`
+	SpotHourly     float64 `json:"spotHourly"`
+	Hourly         float64 `json:"hourly"`
+	Monthly        float64 `json:"monthly"`
+}
+
+func (c *Cost) add(other Cost) {
+	c.OnDemandHourly += other.OnDemandHourly
+	c.SpotHourly += other.SpotHourly
+	c.Hourly += other.Hourly
+	c.Monthly += other.Monthly
+}
+
+func (c Cost) scale(factor float64) Cost {
+	return Cost{
+		OnDemandHourly: c.OnDemandHourly * factor,
+		SpotHourly:     c.SpotHourly * factor,
+		Hourly:         c.Hourly * factor,
+		Monthly:        c.Monthly * factor,
+	}
+}
+
+func newCost(hourly float64, spot bool) Cost {
+	cost := Cost{
+		Hourly:  hourly,
+		Monthly: hourly * HoursPerMonth,
+	}
+	if spot {
+		cost.SpotHourly = hourly
+	} else {
+		cost.OnDemandHourly = hourly
+	}
+
+	return cost
+}
+
+// NodePoolCost is the cost estimate of a node pool.
+type NodePoolCost struct {
+	Cost
+
+	Name         string  `json:"name"`
+	InstanceType string  `json:"instanceType"`
+	Count        int     `json:"count"`
+	Spot         bool    `json:"spot"`
+	NodePrice    float64 `json:"nodePrice"`
+	PriceUnknown bool    `json:"priceUnknown,omitempty"`
+}
+
+// NamespaceCost is the part of the cluster cost attributed to a namespace.
+type NamespaceCost struct {
+	Cost
+
+	Namespace string `json:"namespace"`
+}
+
+// EstimateNodePools estimates the cost of the node pools of a cluster.
+// Node counts found in the cluster override the desired counts of the node pools.
+func EstimateNodePools(
+	machineDetailsGetter MachineDetailsGetter,
+	status *pkgCluster.GetClusterStatusResponse,
+	nodeCounts map[string]int,
+) ([]NodePoolCost, error) {
+	nodePools := make([]NodePoolCost, 0, len(status.NodePools))
+
+	for name, nodePool := range status.NodePools {
+		count := nodePool.Count
+		if actualCount, ok := nodeCounts[name]; ok {
+			count = actualCount
+		}
+
+		machineDetails, err := machineDetailsGetter.GetMachineDetails(
+			status.Cloud,
+			status.Distribution,
+			status.Region,
+			status.Location,
+			nodePool.InstanceType,
+		)
+		if err != nil {
+			return nil, emperror.WrapWith(err, "failed to get machine details", "nodePool", name, "instanceType", nodePool.InstanceType)
+		}
+
+		spot, price := getNodePrice(nodePool, machineDetails)
+
+		nodePools = append(nodePools, NodePoolCost{
+			Cost:         newCost(price*float64(count), spot),
+			Name:         name,
+			InstanceType: nodePool.InstanceType,
+			Count:        count,
+			Spot:         spot,
+			NodePrice:    price,
+			PriceUnknown: price == 0,
+		})
+	}
+
+	sort.Slice(nodePools, func(i, j int) bool {
+		return nodePools[i].Name < nodePools[j].Name
+	})
+
+	return nodePools, nil
+}
+
+// getNodePrice returns whether the nodes of a node pool are spot (or preemptible) instances and their hourly price.
+// Spot instances are priced at the average spot price of the zones, capped by the bid price if there is one.
+func getNodePrice(nodePool *pkgCluster.NodePoolStatus, machineDetails *cloudinfo.MachineDetails) (bool, float64) {
+	bidPrice, _ := strconv.ParseFloat(nodePool.SpotPrice, 64)
+	spot := bidPrice > 0 || nodePool.Preemptible
+
+	if machineDetails == nil {
+		return spot, bidPrice
+	}
+
+	if !spot {
+		return false, machineDetails.OnDemandPrice
+	}
+
+	var sum float64
+	for _, price := range machineDetails.SpotPrice {
+		sum += price
+	}
+
+	price := machineDetails.OnDemandPrice
+	if len(machineDetails.SpotPrice) > 0 {
+		price = sum / float64(len(machineDetails.SpotPrice))
+	}
+
+	if bidPrice > 0 && (price == 0 || bidPrice < price) {
+		price = bidPrice
+	}
+
+	return true, price
+}
+
+// CountNodes returns the number of nodes in each node pool.
+func CountNodes(nodes []v1.Node) map[string]int {
+	counts := make(map[string]int)
+	for _, node := range nodes {
+		if nodePool, ok := node.Labels[pkgCommon.LabelKey]; ok {
+			counts[nodePool]++
+		}
+	}
+
+	return counts
+}
+
+// AttributeToNamespaces distributes the cost of the node pools among namespaces by the CPU and memory requests of their pods.
+// The share of a namespace in a node pool is the average of its share of the allocatable CPU and memory.
+func AttributeToNamespaces(nodePools []NodePoolCost, nodes []v1.Node, pods []v1.Pod) []NamespaceCost {
+	nodePoolNames := make(map[string]string, len(nodes))
+	allocatable := make(map[string]v1.ResourceList)
+	for _, node := range nodes {
+		nodePool, ok := node.Labels[pkgCommon.LabelKey]
+		if !ok {
+			continue
+		}
+
+		nodePoolNames[node.Name] = nodePool
+		allocatable[nodePool] = addResources(allocatable[nodePool], node.Status.Allocatable)
+	}
+
+	requests := make(map[string]map[string]v1.ResourceList)
+	for _, pod := range pods {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+
+		nodePool, ok := nodePoolNames[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+
+		if requests[nodePool] == nil {
+			requests[nodePool] = make(map[string]v1.ResourceList)
+		}
+
+		podRequests, _ := resourcev1.PodRequestsAndLimits(&pod)
+		requests[nodePool][pod.Namespace] = addResources(requests[nodePool][pod.Namespace], podRequests)
+	}
+
+	costs := make(map[string]*NamespaceCost)
+	attribute := func(namespace string, cost Cost) {
+		namespaceCost, ok := costs[namespace]
+		if !ok {
+			namespaceCost = &NamespaceCost{Namespace: namespace}
+			costs[namespace] = namespaceCost
+		}
+		namespaceCost.add(cost)
+	}
+
+	for _, nodePool := range nodePools {
+		shares := make(map[string]float64)
+		var total float64
+		for namespace, namespaceRequests := range requests[nodePool.Name] {
+			share := (resourceShare(v1.ResourceCPU, namespaceRequests, allocatable[nodePool.Name]) +
+				resourceShare(v1.ResourceMemory, namespaceRequests, allocatable[nodePool.Name])) / 2
+
+			shares[namespace] = share
+			total += share
+		}
+
+		// requests may exceed the allocatable resources of nodes that are not counted (eg. terminating ones)
+		if total > 1 {
+			for namespace := range shares {
+				shares[namespace] /= total
+			}
+			total = 1
+		}
+
+		for namespace, share := range shares {
+			attribute(namespace, nodePool.Cost.scale(share))
+		}
+
+		if total < 1 {
+			attribute(UnallocatedNamespace, nodePool.Cost.scale(1-total))
+		}
+	}
+
+	namespaces := make([]NamespaceCost, 0, len(costs))
+	for _, cost := range costs {
+		namespaces = append(namespaces, *cost)
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Namespace < namespaces[j].Namespace
+	})
+
+	return namespaces
+}
+
+func addResources(sum v1.ResourceList, resources v1.ResourceList) v1.ResourceList {
+	if sum == nil {
+		sum = v1.ResourceList{}
+	}
+
+	for name, quantity := range resources {
+		value, ok := sum[name]
+		if !ok {
+			sum[name] = quantity.DeepCopy()
+			continue
+		}
+
+		value.Add(quantity)
+		sum[name] = value
+	}
+
+	return sum
+}
+
+func resourceShare(name v1.ResourceName, requests v1.ResourceList, allocatable v1.ResourceList) float64 {
+	available, ok := allocatable[name]
+	if !ok || available.IsZero() {
+		return 0
+	}
+
+	requested, ok := requests[name]
+	if !ok {
+		return 0
+	}
+
+	return quantityValue(requested) / quantityValue(available)
+}
+
+func quantityValue(quantity resource.Quantity) float64 {
+	return float64(quantity.MilliValue())
+}
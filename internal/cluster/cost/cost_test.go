@@ -0,0 +1,151 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"math"
+	"testing"
+
+	"github.com/banzaicloud/pipeline/internal/cloudinfo"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type machineDetailsGetterStub map[string]*cloudinfo.MachineDetails
+
+func (s machineDetailsGetterStub) GetMachineDetails(cloud string, service string, region string, location string, instanceType string) (*cloudinfo.MachineDetails, error) {
+	if details, ok := s[instanceType]; ok {
+		return details, nil
+	}
+
+	return &cloudinfo.MachineDetails{}, nil
+}
+
+// nolint: gochecknoglobals
+var machineDetailsStub = machineDetailsGetterStub{
+	"m5.large": {
+		Type:          "m5.large",
+		OnDemandPrice: 0.1,
+		SpotPrice:     cloudinfo.SpotPriceInfo{"eu-west-1a": 0.03, "eu-west-1b": 0.05},
+	},
+}
+
+func equal(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEstimateNodePools(t *testing.T) {
+	status := &pkgCluster.GetClusterStatusResponse{
+		Cloud:        pkgCluster.Amazon,
+		Distribution: pkgCluster.EKS,
+		Location:     "eu-west-1",
+		NodePools: map[string]*pkgCluster.NodePoolStatus{
+			"ondemand": {InstanceType: "m5.large", Count: 2},
+			"spot":     {InstanceType: "m5.large", Count: 1, SpotPrice: "0.2"},
+			"lowbid":   {InstanceType: "m5.large", Count: 1, SpotPrice: "0.02"},
+			"unknown":  {InstanceType: "x1.unknown", Count: 1},
+		},
+	}
+
+	nodePools, err := EstimateNodePools(machineDetailsStub, status, map[string]int{"ondemand": 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]struct {
+		count        int
+		spot         bool
+		price        float64
+		priceUnknown bool
+	}{
+		"lowbid":   {count: 1, spot: true, price: 0.02},
+		"ondemand": {count: 3, spot: false, price: 0.1},
+		"spot":     {count: 1, spot: true, price: 0.04},
+		"unknown":  {count: 1, priceUnknown: true},
+	}
+
+	if len(nodePools) != len(expected) {
+		t.Fatalf("expected %d node pools, got %d", len(expected), len(nodePools))
+	}
+
+	for _, nodePool := range nodePools {
+		e := expected[nodePool.Name]
+		if nodePool.Count != e.count || nodePool.Spot != e.spot || !equal(nodePool.NodePrice, e.price) || nodePool.PriceUnknown != e.priceUnknown {
+			t.Errorf("unexpected estimate of node pool %s: %+v", nodePool.Name, nodePool)
+		}
+
+		if !equal(nodePool.Hourly, e.price*float64(e.count)) || !equal(nodePool.Monthly, nodePool.Hourly*HoursPerMonth) {
+			t.Errorf("unexpected cost of node pool %s: %+v", nodePool.Name, nodePool.Cost)
+		}
+
+		if e.spot && !equal(nodePool.SpotHourly, nodePool.Hourly) || !e.spot && !equal(nodePool.OnDemandHourly, nodePool.Hourly) {
+			t.Errorf("unexpected spot and on-demand cost of node pool %s: %+v", nodePool.Name, nodePool.Cost)
+		}
+	}
+}
+
+func TestAttributeToNamespaces(t *testing.T) {
+	resources := func(cpu string, memory string) v1.ResourceList {
+		return v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse(memory),
+		}
+	}
+	pod := func(namespace string, cpu string, memory string) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec: v1.PodSpec{
+				NodeName:   "node1",
+				Containers: []v1.Container{{Resources: v1.ResourceRequirements{Requests: resources(cpu, memory)}}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+
+	nodes := []v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{pkgCommon.LabelKey: "pool1"}},
+			Status:     v1.NodeStatus{Allocatable: resources("4", "8Gi")},
+		},
+	}
+	pods := []v1.Pod{
+		pod("team-a", "2", "2Gi"),
+		pod("team-b", "1", "4Gi"),
+	}
+	nodePools := []NodePoolCost{
+		{Name: "pool1", Cost: newCost(1, false)},
+	}
+
+	namespaces := AttributeToNamespaces(nodePools, nodes, pods)
+
+	expected := map[string]float64{
+		"team-a":             (0.5 + 0.25) / 2,
+		"team-b":             (0.25 + 0.5) / 2,
+		UnallocatedNamespace: 0.25,
+	}
+
+	if len(namespaces) != len(expected) {
+		t.Fatalf("expected %d namespaces, got %+v", len(expected), namespaces)
+	}
+
+	for _, namespace := range namespaces {
+		if !equal(namespace.Hourly, expected[namespace.Namespace]) || !equal(namespace.OnDemandHourly, namespace.Hourly) {
+			t.Errorf("unexpected cost of namespace %s: %+v", namespace.Namespace, namespace.Cost)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"sort"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/goph/emperror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Cluster is a cluster the cost of which can be estimated.
+type Cluster interface {
+	GetID() uint
+	GetName() string
+	GetStatus() (*pkgCluster.GetClusterStatusResponse, error)
+	GetK8sConfig() ([]byte, error)
+}
+
+// ClusterCost is the cost estimate of a cluster.
+type ClusterCost struct {
+	Cost
+
+	ID           uint            `json:"id"`
+	Name         string          `json:"name"`
+	Cloud        string          `json:"cloud"`
+	Distribution string          `json:"distribution"`
+	Location     string          `json:"location"`
+	NodePools    []NodePoolCost  `json:"nodePools"`
+	Namespaces   []NamespaceCost `json:"namespaces"`
+}
+
+// Report is the cost estimate of the clusters of an organization.
+type Report struct {
+	Cost
+
+	Clusters   []ClusterCost   `json:"clusters"`
+	Namespaces []NamespaceCost `json:"namespaces"`
+	Errors     []ClusterError  `json:"errors,omitempty"`
+}
+
+// ClusterError describes why the cost of a cluster could not be estimated.
+type ClusterError struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// Estimator estimates the cost of clusters.
+type Estimator struct {
+	machineDetailsGetter MachineDetailsGetter
+}
+
+// NewEstimator returns a new Estimator instance.
+func NewEstimator(machineDetailsGetter MachineDetailsGetter) *Estimator {
+	return &Estimator{
+		machineDetailsGetter: machineDetailsGetter,
+	}
+}
+
+// EstimateCluster estimates the cost of a running cluster and attributes it to namespaces.
+func (e *Estimator) EstimateCluster(cluster Cluster) (*ClusterCost, error) {
+	status, err := cluster.GetStatus()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get cluster status")
+	}
+
+	return e.estimateCluster(cluster, status)
+}
+
+func (e *Estimator) estimateCluster(cluster Cluster, status *pkgCluster.GetClusterStatusResponse) (*ClusterCost, error) {
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get k8s config")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to create k8s client")
+	}
+
+	nodeList, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list nodes")
+	}
+
+	podList, err := client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list pods")
+	}
+
+	nodePools, err := EstimateNodePools(e.machineDetailsGetter, status, CountNodes(nodeList.Items))
+	if err != nil {
+		return nil, err
+	}
+
+	clusterCost := &ClusterCost{
+		ID:           cluster.GetID(),
+		Name:         cluster.GetName(),
+		Cloud:        status.Cloud,
+		Distribution: status.Distribution,
+		Location:     status.Location,
+		NodePools:    nodePools,
+		Namespaces:   AttributeToNamespaces(nodePools, nodeList.Items, podList.Items),
+	}
+
+	for _, nodePool := range nodePools {
+		clusterCost.add(nodePool.Cost)
+	}
+
+	return clusterCost, nil
+}
+
+// EstimateClusters creates a cost report of the running clusters.
+// Clusters the cost of which cannot be estimated are listed among the errors of the report.
+func (e *Estimator) EstimateClusters(clusters []Cluster) Report {
+	report := Report{
+		Clusters:   []ClusterCost{},
+		Namespaces: []NamespaceCost{},
+	}
+
+	namespaces := make(map[string]*NamespaceCost)
+	for _, cluster := range clusters {
+		status, err := cluster.GetStatus()
+		if err == nil && status.Status != pkgCluster.Running {
+			continue
+		}
+
+		var clusterCost *ClusterCost
+		if err == nil {
+			clusterCost, err = e.estimateCluster(cluster, status)
+		} else {
+			err = emperror.Wrap(err, "failed to get cluster status")
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, ClusterError{
+				ID:    cluster.GetID(),
+				Name:  cluster.GetName(),
+				Error: err.Error(),
+			})
+			continue
+		}
+
+		report.Clusters = append(report.Clusters, *clusterCost)
+		report.add(clusterCost.Cost)
+
+		for _, namespaceCost := range clusterCost.Namespaces {
+			cost, ok := namespaces[namespaceCost.Namespace]
+			if !ok {
+				cost = &NamespaceCost{Namespace: namespaceCost.Namespace}
+				namespaces[namespaceCost.Namespace] = cost
+			}
+			cost.add(namespaceCost.Cost)
+		}
+	}
+
+	for _, cost := range namespaces {
+		report.Namespaces = append(report.Namespaces, *cost)
+	}
+
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		return report.Namespaces[i].Namespace < report.Namespaces[j].Namespace
+	})
+
+	return report
+}
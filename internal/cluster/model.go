@@ -27,6 +27,8 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 	tables := []interface{}{
 		&ClusterModel{},
 		&StatusHistoryModel{},
+		&ResourceUsageSampleModel{},
+		&SpotInterruptionModel{},
 	}
 
 	var tableNames string
@@ -0,0 +1,394 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recommender
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/internal/cloudinfo"
+	"github.com/banzaicloud/pipeline/internal/cluster/cost"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/cluster/aks"
+	"github.com/banzaicloud/pipeline/pkg/cluster/eks"
+	"github.com/banzaicloud/pipeline/pkg/cluster/gke"
+	"github.com/banzaicloud/pipeline/pkg/cluster/pke"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultMaxNodes   = 50
+	defaultMaxLayouts = 5
+
+	onDemandNodePoolName = "ondemand"
+	spotNodePoolName     = "spot"
+
+	pkeMasterNodePoolName     = "master"
+	pkeMasterNodeInstanceType = "c5.large"
+)
+
+// nolint: gochecknoglobals
+var distributionClouds = map[string]string{
+	pkgCluster.EKS: pkgCluster.Amazon,
+	pkgCluster.PKE: pkgCluster.Amazon,
+	pkgCluster.GKE: pkgCluster.Google,
+	pkgCluster.AKS: pkgCluster.Azure,
+}
+
+// MachineTypesGetter returns the machine types available in a region.
+type MachineTypesGetter interface {
+	GetMachineTypes(cloud string, service string, region string, location string) ([]cloudinfo.MachineDetails, error)
+}
+
+type cloudInfoMachineTypesGetter struct{}
+
+// NewCloudInfoMachineTypesGetter returns a MachineTypesGetter querying the cloudinfo service.
+func NewCloudInfoMachineTypesGetter() MachineTypesGetter {
+	return cloudInfoMachineTypesGetter{}
+}
+
+func (cloudInfoMachineTypesGetter) GetMachineTypes(cloud string, service string, region string, location string) ([]cloudinfo.MachineDetails, error) {
+	return cloudinfo.GetMachineTypes(cloud, service, region, location)
+}
+
+// Request describes the resource needs of a workload node pools are recommended for.
+type Request struct {
+	pkgCluster.ScaleOptions
+
+	Cloud        string `json:"cloud" binding:"required"`
+	Distribution string `json:"distribution" binding:"required"`
+	Region       string `json:"region" binding:"required"`
+	// MaxNodes is the maximum number of nodes of a layout, defaults to 50
+	MaxNodes int `json:"maxNodes,omitempty" binding:"min=0"`
+	// MaxLayouts is the maximum number of returned layouts, defaults to 5
+	MaxLayouts int `json:"maxLayouts,omitempty" binding:"min=0"`
+}
+
+// Validate checks the request fields.
+func (r *Request) Validate() error {
+	cloud, ok := distributionClouds[r.Distribution]
+	if !ok {
+		return errors.Errorf("distribution %s is not supported", r.Distribution)
+	}
+
+	if r.Cloud != cloud {
+		return errors.Errorf("distribution %s is not available on %s", r.Distribution, r.Cloud)
+	}
+
+	if r.DesiredCpu <= 0 || r.DesiredMem <= 0 {
+		return errors.New("desired CPU and memory must be positive")
+	}
+
+	if r.DesiredGpu < 0 || r.OnDemandPct < 0 || r.OnDemandPct > 100 {
+		return errors.New("desired GPU must not be negative and on-demand percentage must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// Response contains the recommended node pool layouts ranked by price.
+type Response struct {
+	Layouts []Layout `json:"layouts"`
+}
+
+// Layout is a recommended set of node pools covering the resource needs.
+type Layout struct {
+	cost.Cost
+
+	Cpu       float64    `json:"cpu"`
+	Mem       float64    `json:"mem"`
+	Gpu       float64    `json:"gpu"`
+	Nodes     int        `json:"nodes"`
+	NodePools []NodePool `json:"nodePools"`
+	// Properties can be used as the properties of a create cluster request.
+	// PKE properties contain an additional single node on-demand master node pool, which is not included in the costs.
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// NodePool is a node pool of a recommended layout.
+type NodePool struct {
+	Name         string  `json:"name"`
+	InstanceType string  `json:"instanceType"`
+	Count        int     `json:"count"`
+	Spot         bool    `json:"spot"`
+	NodePrice    float64 `json:"nodePrice"`
+	// BidPrice is the maximum price of spot instances on Amazon, the on-demand price of the instance type
+	BidPrice string  `json:"bidPrice,omitempty"`
+	Cpu      float64 `json:"cpuPerNode"`
+	Mem      float64 `json:"memPerNode"`
+	Gpu      float64 `json:"gpuPerNode"`
+	// Zones are the zones of the request region the instance type is available in
+	Zones []string `json:"zones,omitempty"`
+}
+
+// Recommender recommends node pool layouts based on cloudinfo machine types and prices.
+type Recommender struct {
+	machineTypesGetter MachineTypesGetter
+}
+
+// NewRecommender returns a new Recommender instance.
+func NewRecommender(machineTypesGetter MachineTypesGetter) *Recommender {
+	return &Recommender{
+		machineTypesGetter: machineTypesGetter,
+	}
+}
+
+// Recommend returns the cheapest node pool layouts covering the resource needs of the request.
+// Each layout consists of a single instance type split into an on-demand and a spot node pool by the on-demand percentage.
+// Distributions without spot support get on-demand node pools only.
+func (r *Recommender) Recommend(request Request) (*Response, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	maxNodes := request.MaxNodes
+	if maxNodes == 0 {
+		maxNodes = defaultMaxNodes
+	}
+
+	maxLayouts := request.MaxLayouts
+	if maxLayouts == 0 {
+		maxLayouts = defaultMaxLayouts
+	}
+
+	machineTypes, err := r.machineTypesGetter.GetMachineTypes(request.Cloud, request.Distribution, request.Region, request.Region)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get machine types")
+	}
+
+	excluded := make(map[string]bool, len(request.Excludes))
+	for _, instanceType := range request.Excludes {
+		excluded[instanceType] = true
+	}
+
+	layouts := make([]Layout, 0, len(machineTypes))
+	for _, machineType := range machineTypes {
+		if excluded[machineType.Type] {
+			continue
+		}
+
+		layout, ok := newLayout(request, machineType, maxNodes)
+		if ok {
+			layouts = append(layouts, layout)
+		}
+	}
+
+	sort.SliceStable(layouts, func(i, j int) bool {
+		if layouts[i].Hourly != layouts[j].Hourly {
+			return layouts[i].Hourly < layouts[j].Hourly
+		}
+
+		return layouts[i].Nodes < layouts[j].Nodes
+	})
+
+	if len(layouts) > maxLayouts {
+		layouts = layouts[:maxLayouts]
+	}
+
+	for i := range layouts {
+		layouts[i].Properties = newProperties(request.Distribution, layouts[i].NodePools)
+	}
+
+	return &Response{Layouts: layouts}, nil
+}
+
+// newLayout calculates the layout of a single machine type, or returns false if the machine type is not suitable.
+func newLayout(request Request, machineType cloudinfo.MachineDetails, maxNodes int) (Layout, bool) {
+	if machineType.Cpus <= 0 || machineType.Mem <= 0 || machineType.OnDemandPrice <= 0 {
+		return Layout{}, false
+	}
+
+	if request.DesiredGpu > 0 && machineType.Gpus <= 0 {
+		return Layout{}, false
+	}
+
+	nodes := math.Max(math.Ceil(request.DesiredCpu/machineType.Cpus), math.Ceil(request.DesiredMem/machineType.Mem))
+	if request.DesiredGpu > 0 {
+		nodes = math.Max(nodes, math.Ceil(float64(request.DesiredGpu)/machineType.Gpus))
+	}
+
+	if nodes > float64(maxNodes) {
+		return Layout{}, false
+	}
+
+	count := int(nodes)
+
+	onDemandCount := count
+	spotPrice := averageSpotPrice(machineType)
+	if supportsSpot(request.Distribution) && spotPrice > 0 {
+		onDemandCount = int(math.Ceil(float64(count) * float64(request.OnDemandPct) / 100))
+	}
+
+	layout := Layout{
+		Cpu:       float64(count) * machineType.Cpus,
+		Mem:       float64(count) * machineType.Mem,
+		Gpu:       float64(count) * machineType.Gpus,
+		Nodes:     count,
+		NodePools: []NodePool{},
+	}
+
+	addNodePool := func(name string, count int, spot bool, price float64) {
+		if count == 0 {
+			return
+		}
+
+		hourly := float64(count) * price
+		layout.Hourly += hourly
+		layout.Monthly += hourly * cost.HoursPerMonth
+		if spot {
+			layout.SpotHourly += hourly
+		} else {
+			layout.OnDemandHourly += hourly
+		}
+
+		var bidPrice string
+		if spot && request.Cloud == pkgCluster.Amazon {
+			bidPrice = strconv.FormatFloat(machineType.OnDemandPrice, 'f', -1, 64)
+		}
+
+		layout.NodePools = append(layout.NodePools, NodePool{
+			Name:         name,
+			InstanceType: machineType.Type,
+			Count:        count,
+			Spot:         spot,
+			NodePrice:    price,
+			BidPrice:     bidPrice,
+			Cpu:          machineType.Cpus,
+			Mem:          machineType.Mem,
+			Gpu:          machineType.Gpus,
+			Zones:        machineType.Zones,
+		})
+	}
+
+	addNodePool(onDemandNodePoolName, onDemandCount, false, machineType.OnDemandPrice)
+	addNodePool(spotNodePoolName, count-onDemandCount, true, spotPrice)
+
+	return layout, true
+}
+
+func averageSpotPrice(machineType cloudinfo.MachineDetails) float64 {
+	if len(machineType.SpotPrice) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, price := range machineType.SpotPrice {
+		sum += price
+	}
+
+	return sum / float64(len(machineType.SpotPrice))
+}
+
+func supportsSpot(distribution string) bool {
+	return distribution != pkgCluster.AKS
+}
+
+// newProperties returns the node pools of a layout in the format of the create cluster request properties of a distribution.
+// Spot node pools are preemptible on Google, PKE clusters get a master node pool in the zones of the first node pool.
+func newProperties(distribution string, nodePools []NodePool) map[string]interface{} {
+	switch distribution {
+	case pkgCluster.EKS:
+		eksNodePools := make(map[string]*eks.NodePool, len(nodePools))
+		for _, nodePool := range nodePools {
+			eksNodePools[nodePool.Name] = &eks.NodePool{
+				InstanceType: nodePool.InstanceType,
+				SpotPrice:    nodePool.BidPrice,
+				MinCount:     nodePool.Count,
+				MaxCount:     nodePool.Count,
+				Count:        nodePool.Count,
+			}
+		}
+
+		return map[string]interface{}{
+			pkgCluster.EKS: map[string]interface{}{"nodePools": eksNodePools},
+		}
+
+	case pkgCluster.GKE:
+		gkeNodePools := make(map[string]*gke.NodePool, len(nodePools))
+		for _, nodePool := range nodePools {
+			gkeNodePools[nodePool.Name] = &gke.NodePool{
+				MinCount:         nodePool.Count,
+				MaxCount:         nodePool.Count,
+				Count:            nodePool.Count,
+				NodeInstanceType: nodePool.InstanceType,
+				Preemptible:      nodePool.Spot,
+			}
+		}
+
+		return map[string]interface{}{
+			pkgCluster.GKE: map[string]interface{}{"nodePools": gkeNodePools},
+		}
+
+	case pkgCluster.AKS:
+		aksNodePools := make(map[string]*aks.NodePoolCreate, len(nodePools))
+		for _, nodePool := range nodePools {
+			aksNodePools[nodePool.Name] = &aks.NodePoolCreate{
+				MinCount:         nodePool.Count,
+				MaxCount:         nodePool.Count,
+				Count:            nodePool.Count,
+				NodeInstanceType: nodePool.InstanceType,
+			}
+		}
+
+		return map[string]interface{}{
+			pkgCluster.AKS: map[string]interface{}{"nodePools": aksNodePools},
+		}
+
+	case pkgCluster.PKE:
+		var masterZones []string
+		if len(nodePools) > 0 {
+			masterZones = nodePools[0].Zones
+		}
+
+		pkeNodePools := make(pke.NodePools, 0, len(nodePools)+1)
+		pkeNodePools = append(pkeNodePools, newPKENodePool(pkeMasterNodePoolName, pke.RoleMaster, pkeMasterNodeInstanceType, "", masterZones, 1))
+		for _, nodePool := range nodePools {
+			pkeNodePools = append(pkeNodePools, newPKENodePool(nodePool.Name, pke.RoleWorker, nodePool.InstanceType, nodePool.BidPrice, nodePool.Zones, nodePool.Count))
+		}
+
+		return map[string]interface{}{
+			pkgCluster.PKE: map[string]interface{}{"nodepools": pkeNodePools},
+		}
+	}
+
+	return nil
+}
+
+func newPKENodePool(name string, role pke.Role, instanceType string, spotPrice string, zones []string, count int) pke.NodePool {
+	if zones == nil {
+		zones = []string{}
+	}
+
+	return pke.NodePool{
+		Name:     name,
+		Roles:    pke.Roles{role},
+		Provider: pke.NPPAmazon,
+		ProviderConfig: map[string]interface{}{
+			"autoScalingGroup": map[string]interface{}{
+				"name":         name,
+				"instanceType": instanceType,
+				"spotPrice":    spotPrice,
+				"zones":        zones,
+				"size": map[string]int{
+					"min":     count,
+					"max":     count,
+					"desired": count,
+				},
+			},
+		},
+	}
+}
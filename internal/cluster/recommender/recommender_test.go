@@ -0,0 +1,161 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recommender
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/banzaicloud/pipeline/internal/cloudinfo"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+type machineTypesGetterStub []cloudinfo.MachineDetails
+
+func (s machineTypesGetterStub) GetMachineTypes(cloud string, service string, region string, location string) ([]cloudinfo.MachineDetails, error) {
+	return s, nil
+}
+
+// nolint: gochecknoglobals
+var machineTypesStub = machineTypesGetterStub{
+	{Type: "small", Cpus: 2, Mem: 4, OnDemandPrice: 0.1, SpotPrice: cloudinfo.SpotPriceInfo{"a": 0.03, "b": 0.05}, Zones: []string{"a", "b"}},
+	{Type: "large", Cpus: 8, Mem: 32, OnDemandPrice: 0.5, SpotPrice: cloudinfo.SpotPriceInfo{"a": 0.1}},
+	{Type: "gpu", Cpus: 8, Mem: 64, Gpus: 1, OnDemandPrice: 1},
+	{Type: "free", Cpus: 2, Mem: 2},
+}
+
+func equal(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestRecommend(t *testing.T) {
+	recommender := NewRecommender(machineTypesStub)
+
+	request := Request{
+		ScaleOptions: pkgCluster.ScaleOptions{DesiredCpu: 8, DesiredMem: 16, OnDemandPct: 50},
+		Cloud:        pkgCluster.Amazon,
+		Distribution: pkgCluster.EKS,
+		Region:       "eu-west-1",
+	}
+
+	response, err := recommender.Recommend(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(response.Layouts) != 3 {
+		t.Fatalf("expected 3 layouts, got %d", len(response.Layouts))
+	}
+
+	// 2 on-demand and 2 spot small nodes: 2*0.1 + 2*0.04
+	cheapest := response.Layouts[0]
+	if cheapest.Nodes != 4 || !equal(cheapest.Hourly, 0.28) || !equal(cheapest.SpotHourly, 0.08) || len(cheapest.NodePools) != 2 {
+		t.Errorf("unexpected cheapest layout: %+v", cheapest)
+	}
+
+	if cheapest.NodePools[1].BidPrice != "0.1" {
+		t.Errorf("expected on-demand price as spot bid price, got %q", cheapest.NodePools[1].BidPrice)
+	}
+
+	var properties pkgCluster.CreateClusterProperties
+	data, _ := json.Marshal(cheapest.Properties)
+	if err := json.Unmarshal(data, &properties); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if properties.CreateClusterEKS == nil || properties.CreateClusterEKS.NodePools["spot"].Count != 2 {
+		t.Errorf("unexpected create request properties: %s", data)
+	}
+
+	request.Excludes = []string{"small"}
+	request.MaxLayouts = 1
+	response, err = recommender.Recommend(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(response.Layouts) != 1 || response.Layouts[0].NodePools[0].InstanceType != "large" {
+		t.Errorf("unexpected layouts: %+v", response.Layouts)
+	}
+}
+
+func TestRecommendPKE(t *testing.T) {
+	response, err := NewRecommender(machineTypesStub).Recommend(Request{
+		ScaleOptions: pkgCluster.ScaleOptions{DesiredCpu: 8, DesiredMem: 16, OnDemandPct: 50},
+		Cloud:        pkgCluster.Amazon,
+		Distribution: pkgCluster.PKE,
+		Region:       "eu-west-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var properties pkgCluster.CreateClusterProperties
+	data, _ := json.Marshal(response.Layouts[0].Properties)
+	if err := json.Unmarshal(data, &properties); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := properties.CreateClusterPKE.Validate(); err != nil {
+		t.Fatalf("invalid create request properties %s: %v", data, err)
+	}
+
+	nodePools := properties.CreateClusterPKE.NodePools
+	if len(nodePools) != 3 || nodePools[0].Name != pkeMasterNodePoolName {
+		t.Fatalf("expected a master and two worker node pools, got %s", data)
+	}
+
+	for _, nodePool := range nodePools {
+		zones := nodePool.ProviderConfig["autoScalingGroup"].(map[string]interface{})["zones"].([]interface{})
+		if len(zones) != 2 {
+			t.Errorf("expected the zones of the region in node pool %q, got %v", nodePool.Name, zones)
+		}
+	}
+}
+
+func TestRecommendWithoutSpotSupport(t *testing.T) {
+	response, err := NewRecommender(machineTypesStub).Recommend(Request{
+		ScaleOptions: pkgCluster.ScaleOptions{DesiredCpu: 8, DesiredMem: 16, DesiredGpu: 1},
+		Cloud:        pkgCluster.Azure,
+		Distribution: pkgCluster.AKS,
+		Region:       "westeurope",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(response.Layouts) != 1 {
+		t.Fatalf("expected a single GPU layout, got %+v", response.Layouts)
+	}
+
+	layout := response.Layouts[0]
+	if len(layout.NodePools) != 1 || layout.NodePools[0].Spot || layout.NodePools[0].Count != 1 || !equal(layout.OnDemandHourly, 1) {
+		t.Errorf("unexpected layout: %+v", layout)
+	}
+}
+
+func TestRequestValidate(t *testing.T) {
+	requests := []Request{
+		{ScaleOptions: pkgCluster.ScaleOptions{DesiredCpu: 1, DesiredMem: 1}, Cloud: pkgCluster.Google, Distribution: pkgCluster.EKS},
+		{ScaleOptions: pkgCluster.ScaleOptions{DesiredCpu: 1, DesiredMem: 1}, Cloud: pkgCluster.Amazon, Distribution: "acsk"},
+		{ScaleOptions: pkgCluster.ScaleOptions{DesiredCpu: 1}, Cloud: pkgCluster.Google, Distribution: pkgCluster.GKE},
+		{ScaleOptions: pkgCluster.ScaleOptions{DesiredCpu: 1, DesiredMem: 1, OnDemandPct: 120}, Cloud: pkgCluster.Amazon, Distribution: pkgCluster.PKE},
+	}
+
+	for _, request := range requests {
+		if err := request.Validate(); err == nil {
+			t.Errorf("expected error for request %+v", request)
+		}
+	}
+}
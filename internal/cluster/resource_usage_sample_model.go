@@ -0,0 +1,49 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+)
+
+const (
+	resourceUsageSampleTableName = "cluster_resource_usage_samples"
+)
+
+// ResourceUsageSampleModel stores the resource requests, limits and capacity of a cluster node pool at a given time.
+// Samples older than the raw retention period are downsampled, so a sample may represent the average of a longer period.
+type ResourceUsageSampleModel struct {
+	ID uint `gorm:"primary_key"`
+
+	ClusterID  uint      `gorm:"not null;unique_index:idx_cluster_resource_usage_samples_cluster_id_sampled_at"`
+	NodePool   string    `gorm:"not null;unique_index:idx_cluster_resource_usage_samples_cluster_id_sampled_at"`
+	SampledAt  time.Time `gorm:"not null;unique_index:idx_cluster_resource_usage_samples_cluster_id_sampled_at"`
+	Resolution uint      `gorm:"not null"` // seconds
+
+	Nodes             float64 `gorm:"not null"`
+	CPURequest        float64 `gorm:"not null"` // cores
+	CPULimit          float64 `gorm:"not null"` // cores
+	CPUAllocatable    float64 `gorm:"not null"` // cores
+	CPUCapacity       float64 `gorm:"not null"` // cores
+	MemoryRequest     float64 `gorm:"not null"` // bytes
+	MemoryLimit       float64 `gorm:"not null"` // bytes
+	MemoryAllocatable float64 `gorm:"not null"` // bytes
+	MemoryCapacity    float64 `gorm:"not null"` // bytes
+}
+
+// TableName changes the default table name.
+func (ResourceUsageSampleModel) TableName() string {
+	return resourceUsageSampleTableName
+}
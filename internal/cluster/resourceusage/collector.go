@@ -0,0 +1,147 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceusage
+
+import (
+	"context"
+	"time"
+
+	"github.com/banzaicloud/pipeline/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/goph/emperror"
+	"github.com/sirupsen/logrus"
+)
+
+// CollectorConfig contains the sampling and retention settings of the collector.
+type CollectorConfig struct {
+	// Interval is the time between two samples
+	Interval time.Duration
+	// RawRetention is the time samples are kept in their original resolution
+	RawRetention time.Duration
+	// Resolution is the period older samples are averaged over
+	Resolution time.Duration
+	// Retention is the time samples are kept
+	Retention time.Duration
+}
+
+// Collector periodically samples the resource usage of running clusters.
+// Samples are taken at the start of each interval and stored once per cluster,
+// so collectors running in several Pipeline replicas do not duplicate each other's samples.
+type Collector struct {
+	ctx          context.Context
+	manager      *cluster.Manager
+	store        *Store
+	config       CollectorConfig
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewCollector returns a new Collector instance.
+func NewCollector(
+	ctx context.Context,
+	manager *cluster.Manager,
+	store *Store,
+	config CollectorConfig,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Collector {
+	return &Collector{
+		ctx:          ctx,
+		manager:      manager,
+		store:        store,
+		config:       config,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Run collects samples with the configured interval until the context is cancelled.
+func (c *Collector) Run() {
+	c.logger.WithField("interval", c.config.Interval.String()).Debug("collecting resource usage samples")
+	c.collect()
+
+	ticker := time.NewTicker(c.config.Interval)
+	for {
+		select {
+		case <-ticker.C:
+			c.collect()
+		case <-c.ctx.Done():
+			c.logger.Debug("closing ticker")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (c *Collector) collect() {
+	now := time.Now().UTC().Truncate(c.config.Interval)
+
+	clusters, err := c.manager.GetAllClusters(c.ctx)
+	if err != nil {
+		c.errorHandler.Handle(emperror.Wrap(err, "could not get clusters from cluster manager"))
+		return
+	}
+
+	for _, commonCluster := range clusters {
+		err := c.collectCluster(commonCluster, now)
+		if err != nil {
+			c.errorHandler.Handle(emperror.With(err, "clusterID", commonCluster.GetID(), "clusterName", commonCluster.GetName()))
+		}
+	}
+
+	err = c.store.DeleteBefore(now.Add(-c.config.Retention))
+	if err != nil {
+		c.errorHandler.Handle(err)
+	}
+}
+
+func (c *Collector) collectCluster(commonCluster cluster.CommonCluster, now time.Time) error {
+	status, err := commonCluster.GetStatus()
+	if err != nil {
+		return emperror.Wrap(err, "could not get cluster status")
+	}
+	if status.Status != pkgCluster.Running {
+		return nil
+	}
+
+	// another replica has already sampled the cluster in this interval
+	sampled, err := c.store.Sampled(commonCluster.GetID(), now)
+	if err != nil || sampled {
+		return err
+	}
+
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "could not get k8s config")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "could not create k8s client")
+	}
+
+	samples, err := CollectSamples(client, now, c.config.Interval)
+	if err != nil {
+		return emperror.Wrap(err, "could not collect resource usage samples")
+	}
+
+	err = c.store.Save(commonCluster.GetID(), samples)
+	if err != nil {
+		return err
+	}
+
+	return c.store.Downsample(commonCluster.GetID(), now.Add(-c.config.RawRetention), c.config.Resolution)
+}
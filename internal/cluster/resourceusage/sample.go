@@ -0,0 +1,139 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceusage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/resourcesummary"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Sample is the resource usage of a cluster node pool at a given time.
+// A sample covers a period of the given resolution, downsampled samples contain the averages of the period.
+type Sample struct {
+	NodePool   string
+	SampledAt  time.Time
+	Resolution time.Duration
+
+	Nodes  float64
+	CPU    Resource
+	Memory Resource
+}
+
+// Resource contains the requests, limits and capacity of a resource.
+// CPU is measured in cores, memory in bytes.
+type Resource struct {
+	Request     float64
+	Limit       float64
+	Allocatable float64
+	Capacity    float64
+}
+
+// CollectSamples samples the resource usage of every node pool of a cluster.
+func CollectSamples(client kubernetes.Interface, sampledAt time.Time, resolution time.Duration) ([]Sample, error) {
+	nodeList, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+
+	podList, err := client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pods")
+	}
+
+	return CalculateSamples(nodeList.Items, podList.Items, sampledAt, resolution), nil
+}
+
+// CalculateSamples calculates the resource usage of the node pools the given nodes belong to.
+// Nodes without a node pool label are accounted to a node pool with an empty name.
+func CalculateSamples(nodes []v1.Node, pods []v1.Pod, sampledAt time.Time, resolution time.Duration) []Sample {
+	nodePoolNodes := make(map[string][]v1.Node)
+	nodePoolNames := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		nodePool := node.Labels[pkgCommon.LabelKey]
+		nodePoolNodes[nodePool] = append(nodePoolNodes[nodePool], node)
+		nodePoolNames[node.Name] = nodePool
+	}
+
+	nodePoolPods := make(map[string][]v1.Pod)
+	for _, pod := range pods {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+
+		nodePool, ok := nodePoolNames[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+
+		nodePoolPods[nodePool] = append(nodePoolPods[nodePool], pod)
+	}
+
+	samples := make([]Sample, 0, len(nodePoolNodes))
+	for nodePool, nodes := range nodePoolNodes {
+		capacity, allocatable := resourcesummary.CalculateNodesTotalCapacityAndAllocatable(nodes)
+		requests, limits := resourcesummary.CalculatePodsTotalRequestsAndLimits(nodePoolPods[nodePool])
+
+		samples = append(samples, Sample{
+			NodePool:   nodePool,
+			SampledAt:  sampledAt,
+			Resolution: resolution,
+			Nodes:      float64(len(nodes)),
+			CPU: Resource{
+				Request:     cpuCores(requests),
+				Limit:       cpuCores(limits),
+				Allocatable: cpuCores(allocatable),
+				Capacity:    cpuCores(capacity),
+			},
+			Memory: Resource{
+				Request:     memoryBytes(requests),
+				Limit:       memoryBytes(limits),
+				Allocatable: memoryBytes(allocatable),
+				Capacity:    memoryBytes(capacity),
+			},
+		})
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].NodePool < samples[j].NodePool
+	})
+
+	return samples
+}
+
+func cpuCores(resources map[v1.ResourceName]resource.Quantity) float64 {
+	value, ok := resources[v1.ResourceCPU]
+	if !ok {
+		return 0
+	}
+
+	return float64(value.MilliValue()) / 1000
+}
+
+func memoryBytes(resources map[v1.ResourceName]resource.Quantity) float64 {
+	value, ok := resources[v1.ResourceMemory]
+	if !ok {
+		return 0
+	}
+
+	return float64(value.Value())
+}
@@ -0,0 +1,189 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceusage
+
+import (
+	"time"
+
+	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Store persists the resource usage samples of clusters.
+type Store struct {
+	db *gorm.DB
+}
+
+// NewStore returns a new Store instance.
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Save saves the samples of a cluster.
+func (s *Store) Save(clusterID uint, samples []Sample) error {
+	tx := s.db.Begin()
+	for _, sample := range samples {
+		m := newSampleModel(clusterID, sample)
+		if err := tx.Create(&m).Error; err != nil {
+			tx.Rollback()
+
+			return errors.Wrap(err, "could not save resource usage sample")
+		}
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not save resource usage samples")
+}
+
+// Sampled returns true if the cluster already has samples taken at the given time.
+func (s *Store) Sampled(clusterID uint, sampledAt time.Time) (bool, error) {
+	var count int
+
+	err := s.db.
+		Model(&intCluster.ResourceUsageSampleModel{}).
+		Where("cluster_id = ? AND sampled_at = ?", clusterID, sampledAt).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.Wrap(err, "could not count resource usage samples")
+	}
+
+	return count > 0, nil
+}
+
+// Find returns the samples of a cluster taken in the given period ordered by time.
+func (s *Store) Find(clusterID uint, from time.Time, to time.Time) ([]Sample, error) {
+	var models []intCluster.ResourceUsageSampleModel
+
+	err := s.db.
+		Where("cluster_id = ? AND sampled_at >= ? AND sampled_at < ?", clusterID, from, to).
+		Order("sampled_at").
+		Find(&models).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch resource usage samples")
+	}
+
+	samples := make([]Sample, 0, len(models))
+	for _, m := range models {
+		samples = append(samples, newSample(m))
+	}
+
+	return samples, nil
+}
+
+// Downsample replaces the samples of a cluster taken before the given time with their averages over periods of the given resolution.
+func (s *Store) Downsample(clusterID uint, before time.Time, resolution time.Duration) error {
+	before = before.Truncate(resolution)
+
+	var models []intCluster.ResourceUsageSampleModel
+
+	err := s.db.
+		Where("cluster_id = ? AND sampled_at < ? AND resolution < ?", clusterID, before, uint(resolution.Seconds())).
+		Order("sampled_at").
+		Find(&models).Error
+	if err != nil {
+		return errors.Wrap(err, "could not fetch resource usage samples")
+	}
+
+	if len(models) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(models))
+	samples := make([]Sample, 0, len(models))
+	for _, m := range models {
+		ids = append(ids, m.ID)
+		samples = append(samples, newSample(m))
+	}
+
+	tx := s.db.Begin()
+
+	result := tx.Where("id IN (?)", ids).Delete(intCluster.ResourceUsageSampleModel{})
+	if result.Error != nil {
+		tx.Rollback()
+
+		return errors.Wrap(result.Error, "could not delete downsampled resource usage samples")
+	}
+
+	// the samples are being downsampled by another replica
+	if result.RowsAffected != int64(len(ids)) {
+		tx.Rollback()
+
+		return nil
+	}
+
+	for _, sample := range Downsample(samples, resolution) {
+		m := newSampleModel(clusterID, sample)
+		if err := tx.Create(&m).Error; err != nil {
+			tx.Rollback()
+
+			return errors.Wrap(err, "could not save downsampled resource usage sample")
+		}
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not downsample resource usage samples")
+}
+
+// DeleteBefore deletes the samples of every cluster taken before the given time.
+func (s *Store) DeleteBefore(before time.Time) error {
+	err := s.db.Where("sampled_at < ?", before).Delete(intCluster.ResourceUsageSampleModel{}).Error
+
+	return errors.Wrap(err, "could not delete resource usage samples")
+}
+
+// DeleteByCluster deletes every sample of a cluster.
+func (s *Store) DeleteByCluster(clusterID uint) error {
+	err := s.db.Where("cluster_id = ?", clusterID).Delete(intCluster.ResourceUsageSampleModel{}).Error
+
+	return errors.Wrap(err, "could not delete resource usage samples")
+}
+
+func newSampleModel(clusterID uint, sample Sample) intCluster.ResourceUsageSampleModel {
+	return intCluster.ResourceUsageSampleModel{
+		ClusterID:         clusterID,
+		NodePool:          sample.NodePool,
+		SampledAt:         sample.SampledAt,
+		Resolution:        uint(sample.Resolution.Seconds()),
+		Nodes:             sample.Nodes,
+		CPURequest:        sample.CPU.Request,
+		CPULimit:          sample.CPU.Limit,
+		CPUAllocatable:    sample.CPU.Allocatable,
+		CPUCapacity:       sample.CPU.Capacity,
+		MemoryRequest:     sample.Memory.Request,
+		MemoryLimit:       sample.Memory.Limit,
+		MemoryAllocatable: sample.Memory.Allocatable,
+		MemoryCapacity:    sample.Memory.Capacity,
+	}
+}
+
+func newSample(m intCluster.ResourceUsageSampleModel) Sample {
+	return Sample{
+		NodePool:   m.NodePool,
+		SampledAt:  m.SampledAt,
+		Resolution: time.Duration(m.Resolution) * time.Second,
+		Nodes:      m.Nodes,
+		CPU: Resource{
+			Request:     m.CPURequest,
+			Limit:       m.CPULimit,
+			Allocatable: m.CPUAllocatable,
+			Capacity:    m.CPUCapacity,
+		},
+		Memory: Resource{
+			Request:     m.MemoryRequest,
+			Limit:       m.MemoryLimit,
+			Allocatable: m.MemoryAllocatable,
+			Capacity:    m.MemoryCapacity,
+		},
+	}
+}
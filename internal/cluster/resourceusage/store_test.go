@@ -0,0 +1,69 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceusage
+
+import (
+	"testing"
+	"time"
+
+	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&intCluster.ResourceUsageSampleModel{}).Error)
+
+	return NewStore(db)
+}
+
+func TestStore_Sampled(t *testing.T) {
+	store := newTestStore(t)
+	sampledAt := time.Date(2019, 3, 1, 10, 5, 0, 0, time.UTC)
+
+	require.NoError(t, store.Save(1, []Sample{{NodePool: "pool1", SampledAt: sampledAt, Resolution: 5 * time.Minute}}))
+
+	sampled, err := store.Sampled(1, sampledAt)
+	require.NoError(t, err)
+	assert.True(t, sampled)
+
+	sampled, err = store.Sampled(2, sampledAt)
+	require.NoError(t, err)
+	assert.False(t, sampled)
+
+	// a replica saving the same sample again is rejected
+	assert.Error(t, store.Save(1, []Sample{{NodePool: "pool1", SampledAt: sampledAt, Resolution: 5 * time.Minute}}))
+}
+
+func TestStore_Downsample(t *testing.T) {
+	store := newTestStore(t)
+	start := time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.Save(1, []Sample{{NodePool: "pool1", SampledAt: start, Resolution: 30 * time.Minute, Nodes: 2}}))
+	require.NoError(t, store.Save(1, []Sample{{NodePool: "pool1", SampledAt: start.Add(30 * time.Minute), Resolution: 30 * time.Minute, Nodes: 4}}))
+
+	require.NoError(t, store.Downsample(1, start.Add(time.Hour), time.Hour))
+	require.NoError(t, store.Downsample(1, start.Add(time.Hour), time.Hour))
+
+	samples, err := store.Find(1, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, float64(3), samples[0].Nodes)
+	assert.Equal(t, time.Hour, samples[0].Resolution)
+}
@@ -0,0 +1,308 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceusage
+
+import (
+	"sort"
+	"time"
+)
+
+// Node pool provisioning states
+const (
+	ProvisioningOver     = "over"
+	ProvisioningUnder    = "under"
+	ProvisioningBalanced = "balanced"
+	ProvisioningUnknown  = "unknown"
+)
+
+const (
+	// overProvisionedPeakPercent is the request percent neither CPU nor memory reaches in an over-provisioned node pool
+	overProvisionedPeakPercent = 40
+	// underProvisionedAveragePercent is the average request percent of CPU or memory above which a node pool is under-provisioned
+	underProvisionedAveragePercent = 85
+)
+
+// Usage is the resource usage history of a cluster and its node pools.
+type Usage struct {
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	Step      string          `json:"step"`
+	Cluster   Series          `json:"cluster"`
+	NodePools []NodePoolUsage `json:"nodePools"`
+}
+
+// NodePoolUsage is the resource usage history of a node pool.
+type NodePoolUsage struct {
+	Series
+
+	Name         string `json:"name"`
+	Provisioning string `json:"provisioning"`
+}
+
+// Series contains resource usage points and their trends.
+type Series struct {
+	Points []Point `json:"points"`
+	CPU    Trend   `json:"cpu"`
+	Memory Trend   `json:"memory"`
+}
+
+// Point is the average resource usage of a step.
+type Point struct {
+	Time   time.Time     `json:"time"`
+	Nodes  float64       `json:"nodes"`
+	CPU    ResourcePoint `json:"cpu"`
+	Memory ResourcePoint `json:"memory"`
+}
+
+// ResourcePoint is the average usage of a resource in a step.
+type ResourcePoint struct {
+	Request        float64 `json:"request"`
+	Limit          float64 `json:"limit"`
+	Allocatable    float64 `json:"allocatable"`
+	Capacity       float64 `json:"capacity"`
+	RequestPercent float64 `json:"requestPercent"`
+}
+
+// Trend summarizes the requests of a resource compared to the allocatable amount.
+type Trend struct {
+	AverageRequestPercent float64 `json:"averageRequestPercent"`
+	PeakRequestPercent    float64 `json:"peakRequestPercent"`
+	// Change is the difference of the request percent of the last and the first point
+	Change float64 `json:"change"`
+}
+
+// Downsample averages the samples of each node pool over periods of the given resolution.
+func Downsample(samples []Sample, resolution time.Duration) []Sample {
+	return aggregate(samples, resolution, func(t time.Time) time.Time {
+		return t.Truncate(resolution)
+	})
+}
+
+// GetUsage calculates the usage history of a cluster from its samples taken in the given period.
+func GetUsage(samples []Sample, from time.Time, to time.Time, step time.Duration) Usage {
+	usage := Usage{
+		From:      from,
+		To:        to,
+		Step:      step.String(),
+		NodePools: []NodePoolUsage{},
+	}
+
+	var inPeriod []Sample
+	for _, sample := range samples {
+		if !sample.SampledAt.Before(from) && sample.SampledAt.Before(to) {
+			inPeriod = append(inPeriod, sample)
+		}
+	}
+
+	steps := aggregate(inPeriod, step, func(t time.Time) time.Time {
+		return from.Add(t.Sub(from) / step * step)
+	})
+
+	nodePoolPoints := make(map[string][]Point)
+	clusterPoints := make(map[time.Time]*Point)
+	var nodePools []string
+	for _, sample := range steps {
+		if _, ok := nodePoolPoints[sample.NodePool]; !ok {
+			nodePools = append(nodePools, sample.NodePool)
+		}
+		nodePoolPoints[sample.NodePool] = append(nodePoolPoints[sample.NodePool], newPoint(sample))
+
+		clusterPoint, ok := clusterPoints[sample.SampledAt]
+		if !ok {
+			clusterPoint = &Point{Time: sample.SampledAt}
+			clusterPoints[sample.SampledAt] = clusterPoint
+		}
+		clusterPoint.Nodes += sample.Nodes
+		addResource(&clusterPoint.CPU, sample.CPU)
+		addResource(&clusterPoint.Memory, sample.Memory)
+	}
+
+	for _, nodePool := range nodePools {
+		series := newSeries(nodePoolPoints[nodePool])
+		usage.NodePools = append(usage.NodePools, NodePoolUsage{
+			Series:       series,
+			Name:         nodePool,
+			Provisioning: getProvisioning(series),
+		})
+	}
+
+	points := make([]Point, 0, len(clusterPoints))
+	for _, point := range clusterPoints {
+		point.CPU.RequestPercent = requestPercent(point.CPU.Request, point.CPU.Allocatable)
+		point.Memory.RequestPercent = requestPercent(point.Memory.Request, point.Memory.Allocatable)
+		points = append(points, *point)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Time.Before(points[j].Time)
+	})
+	usage.Cluster = newSeries(points)
+
+	return usage
+}
+
+// aggregate calculates the averages of the samples of each node pool in the periods returned by the period function.
+// Samples are weighted by their resolution, the result is ordered by node pool and time.
+func aggregate(samples []Sample, resolution time.Duration, period func(time.Time) time.Time) []Sample {
+	type key struct {
+		nodePool string
+		period   time.Time
+	}
+
+	sums := make(map[key]*Sample)
+	weights := make(map[key]float64)
+	for _, sample := range samples {
+		k := key{nodePool: sample.NodePool, period: period(sample.SampledAt)}
+
+		sum, ok := sums[k]
+		if !ok {
+			sum = &Sample{NodePool: k.nodePool, SampledAt: k.period, Resolution: resolution}
+			sums[k] = sum
+		}
+
+		weight := sample.Resolution.Seconds()
+		if weight <= 0 {
+			weight = 1
+		}
+		weights[k] += weight
+
+		sum.Nodes += sample.Nodes * weight
+		sum.CPU = weightedSum(sum.CPU, sample.CPU, weight)
+		sum.Memory = weightedSum(sum.Memory, sample.Memory, weight)
+	}
+
+	result := make([]Sample, 0, len(sums))
+	for k, sum := range sums {
+		weight := weights[k]
+
+		sum.Nodes /= weight
+		sum.CPU = Resource{
+			Request:     sum.CPU.Request / weight,
+			Limit:       sum.CPU.Limit / weight,
+			Allocatable: sum.CPU.Allocatable / weight,
+			Capacity:    sum.CPU.Capacity / weight,
+		}
+		sum.Memory = Resource{
+			Request:     sum.Memory.Request / weight,
+			Limit:       sum.Memory.Limit / weight,
+			Allocatable: sum.Memory.Allocatable / weight,
+			Capacity:    sum.Memory.Capacity / weight,
+		}
+
+		result = append(result, *sum)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].NodePool != result[j].NodePool {
+			return result[i].NodePool < result[j].NodePool
+		}
+
+		return result[i].SampledAt.Before(result[j].SampledAt)
+	})
+
+	return result
+}
+
+func weightedSum(sum Resource, value Resource, weight float64) Resource {
+	return Resource{
+		Request:     sum.Request + value.Request*weight,
+		Limit:       sum.Limit + value.Limit*weight,
+		Allocatable: sum.Allocatable + value.Allocatable*weight,
+		Capacity:    sum.Capacity + value.Capacity*weight,
+	}
+}
+
+func addResource(point *ResourcePoint, value Resource) {
+	point.Request += value.Request
+	point.Limit += value.Limit
+	point.Allocatable += value.Allocatable
+	point.Capacity += value.Capacity
+}
+
+func newPoint(sample Sample) Point {
+	return Point{
+		Time:   sample.SampledAt,
+		Nodes:  sample.Nodes,
+		CPU:    newResourcePoint(sample.CPU),
+		Memory: newResourcePoint(sample.Memory),
+	}
+}
+
+func newResourcePoint(value Resource) ResourcePoint {
+	return ResourcePoint{
+		Request:        value.Request,
+		Limit:          value.Limit,
+		Allocatable:    value.Allocatable,
+		Capacity:       value.Capacity,
+		RequestPercent: requestPercent(value.Request, value.Allocatable),
+	}
+}
+
+func requestPercent(request float64, allocatable float64) float64 {
+	if allocatable <= 0 {
+		return 0
+	}
+
+	return request / allocatable * 100
+}
+
+func newSeries(points []Point) Series {
+	if points == nil {
+		points = []Point{}
+	}
+
+	return Series{
+		Points: points,
+		CPU: newTrend(points, func(p Point) float64 {
+			return p.CPU.RequestPercent
+		}),
+		Memory: newTrend(points, func(p Point) float64 {
+			return p.Memory.RequestPercent
+		}),
+	}
+}
+
+func newTrend(points []Point, value func(Point) float64) Trend {
+	var trend Trend
+	if len(points) == 0 {
+		return trend
+	}
+
+	var sum float64
+	for _, point := range points {
+		v := value(point)
+		sum += v
+		if v > trend.PeakRequestPercent {
+			trend.PeakRequestPercent = v
+		}
+	}
+
+	trend.AverageRequestPercent = sum / float64(len(points))
+	trend.Change = value(points[len(points)-1]) - value(points[0])
+
+	return trend
+}
+
+func getProvisioning(series Series) string {
+	switch {
+	case len(series.Points) == 0:
+		return ProvisioningUnknown
+	case series.CPU.AverageRequestPercent > underProvisionedAveragePercent || series.Memory.AverageRequestPercent > underProvisionedAveragePercent:
+		return ProvisioningUnder
+	case series.CPU.PeakRequestPercent < overProvisionedPeakPercent && series.Memory.PeakRequestPercent < overProvisionedPeakPercent:
+		return ProvisioningOver
+	default:
+		return ProvisioningBalanced
+	}
+}
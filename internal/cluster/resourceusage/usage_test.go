@@ -0,0 +1,174 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceusage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNode(name string, nodePool string, cpu string, memory string) v1.Node {
+	resources := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{pkgCommon.LabelKey: nodePool}},
+		Status:     v1.NodeStatus{Capacity: resources, Allocatable: resources},
+	}
+}
+
+func newPod(nodeName string, phase v1.PodPhase, cpu string, memory string) v1.Pod {
+	return v1.Pod{
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse(cpu),
+							v1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				},
+			},
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
+
+func TestCalculateSamples(t *testing.T) {
+	now := time.Date(2019, 3, 28, 10, 0, 0, 0, time.UTC)
+
+	nodes := []v1.Node{
+		newNode("node1", "pool1", "2", "4Gi"),
+		newNode("node2", "pool1", "2", "4Gi"),
+		newNode("node3", "pool2", "4", "16Gi"),
+	}
+	pods := []v1.Pod{
+		newPod("node1", v1.PodRunning, "500m", "1Gi"),
+		newPod("node2", v1.PodRunning, "1", "1Gi"),
+		newPod("node3", v1.PodSucceeded, "4", "16Gi"),
+		newPod("", v1.PodPending, "1", "1Gi"),
+	}
+
+	samples := CalculateSamples(nodes, pods, now, 5*time.Minute)
+
+	expected := []Sample{
+		{
+			NodePool:   "pool1",
+			SampledAt:  now,
+			Resolution: 5 * time.Minute,
+			Nodes:      2,
+			CPU:        Resource{Request: 1.5, Allocatable: 4, Capacity: 4},
+			Memory:     Resource{Request: 2 << 30, Allocatable: 8 << 30, Capacity: 8 << 30},
+		},
+		{
+			NodePool:   "pool2",
+			SampledAt:  now,
+			Resolution: 5 * time.Minute,
+			Nodes:      1,
+			CPU:        Resource{Allocatable: 4, Capacity: 4},
+			Memory:     Resource{Allocatable: 16 << 30, Capacity: 16 << 30},
+		},
+	}
+
+	if !reflect.DeepEqual(samples, expected) {
+		t.Errorf("expected samples %+v, got %+v", expected, samples)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2019, 3, 28, 10, 0, 0, 0, time.UTC)
+
+	samples := []Sample{
+		{NodePool: "pool1", SampledAt: start, Resolution: 30 * time.Minute, Nodes: 2, CPU: Resource{Request: 1}},
+		{NodePool: "pool1", SampledAt: start.Add(30 * time.Minute), Resolution: 30 * time.Minute, Nodes: 4, CPU: Resource{Request: 3}},
+		{NodePool: "pool1", SampledAt: start.Add(time.Hour), Resolution: 30 * time.Minute, Nodes: 4, CPU: Resource{Request: 5}},
+	}
+
+	expected := []Sample{
+		{NodePool: "pool1", SampledAt: start, Resolution: time.Hour, Nodes: 3, CPU: Resource{Request: 2}},
+		{NodePool: "pool1", SampledAt: start.Add(time.Hour), Resolution: time.Hour, Nodes: 4, CPU: Resource{Request: 5}},
+	}
+
+	if downsampled := Downsample(samples, time.Hour); !reflect.DeepEqual(downsampled, expected) {
+		t.Errorf("expected samples %+v, got %+v", expected, downsampled)
+	}
+}
+
+func TestGetUsage(t *testing.T) {
+	from := time.Date(2019, 3, 28, 10, 0, 0, 0, time.UTC)
+	to := from.Add(2 * time.Hour)
+
+	var samples []Sample
+	for i := 0; i < 4; i++ {
+		sampledAt := from.Add(time.Duration(i) * 30 * time.Minute)
+
+		samples = append(samples,
+			Sample{
+				NodePool:   "busy",
+				SampledAt:  sampledAt,
+				Resolution: 30 * time.Minute,
+				Nodes:      1,
+				CPU:        Resource{Request: 3.6, Allocatable: 4},
+				Memory:     Resource{Request: 4, Allocatable: 8},
+			},
+			Sample{
+				NodePool:   "idle",
+				SampledAt:  sampledAt,
+				Resolution: 30 * time.Minute,
+				Nodes:      2,
+				CPU:        Resource{Request: 0.4 * float64(i+1), Allocatable: 4},
+				Memory:     Resource{Request: 1, Allocatable: 8},
+			},
+		)
+	}
+
+	// outside of the period
+	samples = append(samples, Sample{NodePool: "busy", SampledAt: to, Resolution: 30 * time.Minute, Nodes: 10})
+
+	usage := GetUsage(samples, from, to, time.Hour)
+
+	if len(usage.Cluster.Points) != 2 {
+		t.Fatalf("expected 2 cluster points, got %d", len(usage.Cluster.Points))
+	}
+
+	if point := usage.Cluster.Points[1]; point.Time != from.Add(time.Hour) || point.Nodes != 3 || point.CPU.Allocatable != 8 {
+		t.Errorf("unexpected cluster point: %+v", point)
+	}
+
+	provisioning := make(map[string]string)
+	for _, nodePool := range usage.NodePools {
+		provisioning[nodePool.Name] = nodePool.Provisioning
+	}
+
+	expected := map[string]string{"busy": ProvisioningUnder, "idle": ProvisioningOver}
+	if !reflect.DeepEqual(provisioning, expected) {
+		t.Errorf("expected provisioning %v, got %v", expected, provisioning)
+	}
+
+	idle := usage.NodePools[1]
+	if idle.CPU.Change <= 0 {
+		t.Errorf("expected increasing CPU trend, got %+v", idle.CPU)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+)
+
+const (
+	spotInterruptionTableName = "cluster_spot_interruptions"
+)
+
+// SpotInterruptionModel stores an interruption notice received by a spot, preemptible or low-priority node of a cluster
+// and the rescheduling of the workloads running on it.
+type SpotInterruptionModel struct {
+	ID        uint `gorm:"primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	ClusterID    uint      `gorm:"not null;unique_index:idx_cluster_spot_interruptions_cluster_id_event_uid;index:idx_cluster_spot_interruptions_cluster_id_noticed_at"`
+	EventUID     string    `gorm:"not null;unique_index:idx_cluster_spot_interruptions_cluster_id_event_uid"`
+	NodeName     string    `gorm:"not null"`
+	NodePool     string    `gorm:"not null"`
+	InstanceType string    `gorm:"not null"`
+	NoticedAt    time.Time `gorm:"not null;index:idx_cluster_spot_interruptions_cluster_id_noticed_at"`
+	Owners       string    `gorm:"type:text"` // JSON encoded controllers of the pods running on the node
+
+	RescheduledPods int `gorm:"not null"`
+	PendingPods     int `gorm:"not null"`
+	RescheduledAt   *time.Time
+	Completed       bool `gorm:"not null"`
+}
+
+// TableName changes the default table name.
+func (SpotInterruptionModel) TableName() string {
+	return spotInterruptionTableName
+}
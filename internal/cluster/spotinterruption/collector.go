@@ -0,0 +1,200 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotinterruption
+
+import (
+	"context"
+	"time"
+
+	"github.com/banzaicloud/pipeline/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/goph/emperror"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CollectorConfig contains the tracking and retention settings of the collector.
+type CollectorConfig struct {
+	// Interval is the time between two collections
+	Interval time.Duration
+	// ReschedulingWindow is the period following a notice pods created in are considered replacements
+	ReschedulingWindow time.Duration
+	// TrackingPeriod is the time the rescheduling of the workloads of an interrupted node is tracked
+	TrackingPeriod time.Duration
+	// Retention is the time interruptions are kept
+	Retention time.Duration
+}
+
+// Collector periodically records the interruption notices of running clusters and tracks the rescheduling of their workloads.
+type Collector struct {
+	ctx          context.Context
+	manager      *cluster.Manager
+	store        *Store
+	events       *Events
+	config       CollectorConfig
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewCollector returns a new Collector instance.
+func NewCollector(
+	ctx context.Context,
+	manager *cluster.Manager,
+	store *Store,
+	events *Events,
+	config CollectorConfig,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Collector {
+	return &Collector{
+		ctx:          ctx,
+		manager:      manager,
+		store:        store,
+		events:       events,
+		config:       config,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Run collects interruptions with the configured interval until the context is cancelled.
+func (c *Collector) Run() {
+	c.logger.WithField("interval", c.config.Interval.String()).Debug("collecting spot interruptions")
+	c.collect()
+
+	ticker := time.NewTicker(c.config.Interval)
+	for {
+		select {
+		case <-ticker.C:
+			c.collect()
+		case <-c.ctx.Done():
+			c.logger.Debug("closing ticker")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (c *Collector) collect() {
+	now := time.Now().UTC()
+
+	clusters, err := c.manager.GetAllClusters(c.ctx)
+	if err != nil {
+		c.errorHandler.Handle(emperror.Wrap(err, "could not get clusters from cluster manager"))
+		return
+	}
+
+	for _, commonCluster := range clusters {
+		err := c.collectCluster(commonCluster, now)
+		if err != nil {
+			c.errorHandler.Handle(emperror.With(err, "clusterID", commonCluster.GetID(), "clusterName", commonCluster.GetName()))
+		}
+	}
+
+	err = c.store.DeleteBefore(now.Add(-c.config.Retention))
+	if err != nil {
+		c.errorHandler.Handle(err)
+	}
+}
+
+func (c *Collector) collectCluster(commonCluster cluster.CommonCluster, now time.Time) error {
+	status, err := commonCluster.GetStatus()
+	if err != nil {
+		return emperror.Wrap(err, "could not get cluster status")
+	}
+	if status.Status != pkgCluster.Running {
+		return nil
+	}
+
+	// interruption notices are recorded by the instance termination handler on EC2 and by the watcher on GKE and AKS
+	switch commonCluster.GetDistribution() {
+	case pkgCluster.EKS, pkgCluster.PKE, pkgCluster.GKE, pkgCluster.AKS:
+	default:
+		return nil
+	}
+
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "could not get k8s config")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "could not create k8s client")
+	}
+
+	interruptions, err := CollectInterruptions(client)
+	if err != nil {
+		return emperror.Wrap(err, "could not collect interruptions")
+	}
+
+	for _, interruption := range interruptions {
+		exists, err := c.store.Exists(commonCluster.GetID(), interruption.EventUID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		interruption.Owners, err = CollectPodOwners(client, interruption.NodeName)
+		if err != nil {
+			return emperror.Wrap(err, "could not collect pod owners")
+		}
+
+		added, err := c.store.Add(commonCluster.GetID(), interruption)
+		if err != nil {
+			return err
+		}
+
+		if added {
+			c.logger.WithFields(logrus.Fields{
+				"clusterID": commonCluster.GetID(),
+				"node":      interruption.NodeName,
+				"nodePool":  interruption.NodePool,
+			}).Info("node received interruption notice")
+
+			c.events.Interrupted(commonCluster.GetID(), interruption)
+		}
+	}
+
+	return c.trackRescheduling(commonCluster.GetID(), client, now)
+}
+
+func (c *Collector) trackRescheduling(clusterID uint, client kubernetes.Interface, now time.Time) error {
+	interruptions, err := c.store.FindInProgress(clusterID)
+	if err != nil {
+		return err
+	}
+
+	for _, interruption := range interruptions {
+		rescheduling, err := CollectRescheduling(client, interruption, c.config.ReschedulingWindow)
+		if err != nil {
+			return emperror.Wrap(err, "could not collect rescheduling")
+		}
+
+		windowEnded := now.After(interruption.NoticedAt.Add(c.config.ReschedulingWindow))
+		trackingEnded := now.After(interruption.NoticedAt.Add(c.config.TrackingPeriod))
+		rescheduling.Completed = (windowEnded && rescheduling.PendingPods == 0) || trackingEnded
+
+		err = c.store.UpdateRescheduling(interruption.ID, rescheduling)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotinterruption
+
+type eventBus interface {
+	Publish(topic string, args ...interface{})
+}
+
+const interruptionTopic = "cluster_spot_interruption"
+
+// Events publishes the interruptions of clusters as cluster events.
+type Events struct {
+	eb eventBus
+}
+
+// NewEvents returns a new Events instance.
+func NewEvents(eb eventBus) *Events {
+	return &Events{
+		eb: eb,
+	}
+}
+
+// Interrupted is emitted when a node of a cluster receives an interruption notice.
+func (e *Events) Interrupted(clusterID uint, interruption Interruption) {
+	e.eb.Publish(interruptionTopic, clusterID, interruption)
+}
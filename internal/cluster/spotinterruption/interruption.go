@@ -0,0 +1,247 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotinterruption
+
+import (
+	"sort"
+	"time"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/spotinterruption/watcher"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EventReason is the reason of the Kubernetes events recorded on nodes receiving an interruption notice.
+const EventReason = watcher.EventReason
+
+const instanceTypeLabelKey = "beta.kubernetes.io/instance-type"
+
+// Interruption is an interruption notice received by a node and the rescheduling of the workloads running on it.
+type Interruption struct {
+	ID           uint      `json:"id"`
+	EventUID     string    `json:"eventUid"`
+	NodeName     string    `json:"nodeName"`
+	NodePool     string    `json:"nodePool"`
+	InstanceType string    `json:"instanceType"`
+	NoticedAt    time.Time `json:"noticedAt"`
+	// Owners are the controllers of the pods running on the node when the notice was collected
+	Owners []PodOwner `json:"-"`
+
+	Rescheduling
+}
+
+// PodOwner is the controller of pods running on an interrupted node.
+type PodOwner struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	// Pods is the number of pods of the controller running on the node
+	Pods int `json:"pods"`
+}
+
+// Rescheduling is the state of rescheduling the workloads of an interrupted node.
+type Rescheduling struct {
+	// RescheduledPods is the number of replacement pods which became ready
+	RescheduledPods int `json:"rescheduledPods"`
+	// PendingPods is the number of replacement pods which are not ready yet
+	PendingPods int `json:"pendingPods"`
+	// RescheduledAt is the time the last replacement pod became ready, nil until every replacement pod is ready
+	RescheduledAt *time.Time `json:"rescheduledAt,omitempty"`
+	// Completed is set when the rescheduling is not tracked anymore
+	Completed bool `json:"completed"`
+}
+
+// Latency returns the time it took to reschedule the workloads of the interrupted node.
+func (i Interruption) Latency() *time.Duration {
+	if i.RescheduledAt == nil {
+		return nil
+	}
+
+	latency := i.RescheduledAt.Sub(i.NoticedAt)
+
+	return &latency
+}
+
+// CollectInterruptions returns the interruption notices recorded in the cluster.
+func CollectInterruptions(client kubernetes.Interface) ([]Interruption, error) {
+	eventList, err := client.CoreV1().Events(metav1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: fields.Set{"involvedObject.kind": "Node", "reason": EventReason}.String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list interruption events")
+	}
+
+	interruptions := make([]Interruption, 0, len(eventList.Items))
+	for _, event := range eventList.Items {
+		interruption := Interruption{
+			EventUID:  string(event.UID),
+			NodeName:  event.InvolvedObject.Name,
+			NoticedAt: event.FirstTimestamp.Time.UTC(),
+		}
+
+		node, err := client.CoreV1().Nodes().Get(interruption.NodeName, metav1.GetOptions{})
+		if err == nil {
+			interruption.NodePool = node.Labels[pkgCommon.LabelKey]
+			interruption.InstanceType = node.Labels[instanceTypeLabelKey]
+		} else if !k8sErrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "failed to get node %s", interruption.NodeName)
+		}
+
+		interruptions = append(interruptions, interruption)
+	}
+
+	return interruptions, nil
+}
+
+// CollectPodOwners returns the controllers of the pods running on a node, which replacement pods are expected from.
+// Pods being terminated are still listed, so the owners can be collected while the node is drained.
+func CollectPodOwners(client kubernetes.Interface, nodeName string) ([]PodOwner, error) {
+	podList, err := client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list pods of node %s", nodeName)
+	}
+
+	return getPodOwners(podList.Items), nil
+}
+
+func getPodOwners(pods []v1.Pod) []PodOwner {
+	owners := make(map[PodOwner]int)
+	for _, pod := range pods {
+		owner, ok := getReschedulableOwner(pod)
+		if !ok {
+			continue
+		}
+
+		owners[owner]++
+	}
+
+	podOwners := make([]PodOwner, 0, len(owners))
+	for owner, pods := range owners {
+		owner.Pods = pods
+		podOwners = append(podOwners, owner)
+	}
+
+	sort.Slice(podOwners, func(i, j int) bool {
+		a, b := podOwners[i], podOwners[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	return podOwners
+}
+
+// getReschedulableOwner returns the controller of a pod which replaces the pod when it is evicted,
+// pods of DaemonSets are not rescheduled and finished pods are not replaced
+func getReschedulableOwner(pod v1.Pod) (PodOwner, bool) {
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return PodOwner{}, false
+	}
+
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil || owner.Kind == "DaemonSet" {
+		return PodOwner{}, false
+	}
+
+	return PodOwner{Namespace: pod.Namespace, Kind: owner.Kind, Name: owner.Name}, true
+}
+
+// CollectRescheduling returns the rescheduling state of the workloads of an interrupted node.
+func CollectRescheduling(client kubernetes.Interface, interruption Interruption, window time.Duration) (Rescheduling, error) {
+	podList, err := client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return Rescheduling{}, errors.Wrap(err, "failed to list pods")
+	}
+
+	return CalculateRescheduling(interruption, podList.Items, window), nil
+}
+
+// CalculateRescheduling calculates the rescheduling state of the workloads of an interrupted node.
+// Replacement pods are the pods created on other nodes within the window following the notice by the controllers
+// of the pods running on the interrupted node, at most as many for each controller as it had on the node.
+func CalculateRescheduling(interruption Interruption, pods []v1.Pod, window time.Duration) Rescheduling {
+	var rescheduling Rescheduling
+	var lastReadyAt time.Time
+
+	expected := make(map[PodOwner]int, len(interruption.Owners))
+	for _, owner := range interruption.Owners {
+		pods := owner.Pods
+		owner.Pods = 0
+		expected[owner] += pods
+	}
+
+	// the earliest pods of a controller are considered replacements
+	pods = append([]v1.Pod(nil), pods...)
+	sort.SliceStable(pods, func(i, j int) bool {
+		return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+	})
+
+	windowEnd := interruption.NoticedAt.Add(window)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == interruption.NodeName {
+			continue
+		}
+
+		created := pod.CreationTimestamp.Time
+		if created.Before(interruption.NoticedAt) || !created.Before(windowEnd) {
+			continue
+		}
+
+		owner, ok := getReschedulableOwner(pod)
+		if !ok || expected[owner] == 0 {
+			continue
+		}
+		expected[owner]--
+
+		readyAt := getPodReadyTime(pod)
+		if readyAt == nil {
+			rescheduling.PendingPods++
+			continue
+		}
+
+		rescheduling.RescheduledPods++
+		if readyAt.After(lastReadyAt) {
+			lastReadyAt = *readyAt
+		}
+	}
+
+	if rescheduling.PendingPods == 0 && rescheduling.RescheduledPods > 0 {
+		lastReadyAt = lastReadyAt.UTC()
+		rescheduling.RescheduledAt = &lastReadyAt
+	}
+
+	return rescheduling
+}
+
+func getPodReadyTime(pod v1.Pod) *time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue {
+			return &condition.LastTransitionTime.Time
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotinterruption
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPod(nodeName string, ownerKind string, ownerName string, created time.Time, readyAt *time.Time) v1.Pod {
+	controller := true
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences:   []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, Controller: &controller}},
+		},
+		Spec:   v1.PodSpec{NodeName: nodeName},
+		Status: v1.PodStatus{Phase: v1.PodPending},
+	}
+
+	if readyAt != nil {
+		pod.Status.Phase = v1.PodRunning
+		pod.Status.Conditions = []v1.PodCondition{
+			{Type: v1.PodReady, Status: v1.ConditionTrue, LastTransitionTime: metav1.NewTime(*readyAt)},
+		}
+	}
+
+	return pod
+}
+
+func TestCalculateRescheduling(t *testing.T) {
+	noticedAt := time.Date(2019, 3, 29, 10, 0, 0, 0, time.UTC)
+	interruption := Interruption{
+		NodeName:  "node1",
+		NoticedAt: noticedAt,
+		Owners: []PodOwner{
+			{Namespace: "default", Kind: "ReplicaSet", Name: "web", Pods: 2},
+			{Namespace: "default", Kind: "StatefulSet", Name: "db", Pods: 1},
+		},
+	}
+	window := 5 * time.Minute
+
+	at := func(d time.Duration) *time.Time {
+		t := noticedAt.Add(d)
+		return &t
+	}
+
+	tests := map[string]struct {
+		pods     []v1.Pod
+		expected Rescheduling
+	}{
+		"rescheduled": {
+			pods: []v1.Pod{
+				newPod("node2", "ReplicaSet", "web", *at(10 * time.Second), at(40*time.Second)),
+				newPod("node3", "StatefulSet", "db", *at(20 * time.Second), at(90*time.Second)),
+				newPod("node2", "ReplicaSet", "web", *at(-time.Hour), at(-time.Hour)),
+				newPod("node3", "DaemonSet", "agent", *at(30 * time.Second), nil),
+				newPod("node1", "ReplicaSet", "web", *at(30 * time.Second), nil),
+				newPod("node2", "ReplicaSet", "web", *at(10 * time.Minute), nil),
+			},
+			expected: Rescheduling{RescheduledPods: 2, RescheduledAt: at(90 * time.Second)},
+		},
+		"pending": {
+			pods: []v1.Pod{
+				newPod("node2", "ReplicaSet", "web", *at(10 * time.Second), at(40*time.Second)),
+				newPod("node3", "ReplicaSet", "web", *at(20 * time.Second), nil),
+			},
+			expected: Rescheduling{RescheduledPods: 1, PendingPods: 1},
+		},
+		"unrelated pods": {
+			pods: []v1.Pod{
+				newPod("node2", "ReplicaSet", "web", *at(10 * time.Second), at(40*time.Second)),
+				newPod("node2", "Job", "backup", *at(15 * time.Second), nil),
+				newPod("node3", "ReplicaSet", "api", *at(20 * time.Second), nil),
+			},
+			expected: Rescheduling{RescheduledPods: 1, RescheduledAt: at(40 * time.Second)},
+		},
+		"scaled up": {
+			pods: []v1.Pod{
+				newPod("node2", "ReplicaSet", "web", *at(10 * time.Second), at(40*time.Second)),
+				newPod("node3", "ReplicaSet", "web", *at(20 * time.Second), at(50*time.Second)),
+				newPod("node3", "ReplicaSet", "web", *at(30 * time.Second), nil),
+				newPod("node3", "StatefulSet", "db", *at(30 * time.Second), at(60*time.Second)),
+			},
+			expected: Rescheduling{RescheduledPods: 3, RescheduledAt: at(60 * time.Second)},
+		},
+		"no workload": {
+			expected: Rescheduling{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rescheduling := CalculateRescheduling(interruption, test.pods, window)
+
+			if rescheduling.RescheduledPods != test.expected.RescheduledPods || rescheduling.PendingPods != test.expected.PendingPods {
+				t.Fatalf("expected %d rescheduled and %d pending pods, got %d and %d",
+					test.expected.RescheduledPods, test.expected.PendingPods, rescheduling.RescheduledPods, rescheduling.PendingPods)
+			}
+
+			if (rescheduling.RescheduledAt == nil) != (test.expected.RescheduledAt == nil) ||
+				(rescheduling.RescheduledAt != nil && !rescheduling.RescheduledAt.Equal(*test.expected.RescheduledAt)) {
+				t.Fatalf("expected rescheduled at %v, got %v", test.expected.RescheduledAt, rescheduling.RescheduledAt)
+			}
+		})
+	}
+}
+
+func TestGetPodOwners(t *testing.T) {
+	created := time.Date(2019, 3, 29, 10, 0, 0, 0, time.UTC)
+
+	completed := newPod("node1", "Job", "backup", created, nil)
+	completed.Status.Phase = v1.PodSucceeded
+
+	pods := []v1.Pod{
+		newPod("node1", "StatefulSet", "db", created, &created),
+		newPod("node1", "ReplicaSet", "web", created, &created),
+		newPod("node1", "ReplicaSet", "web", created, nil),
+		newPod("node1", "DaemonSet", "agent", created, &created),
+		completed,
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "static"}},
+	}
+
+	owners := getPodOwners(pods)
+
+	expected := []PodOwner{
+		{Namespace: "default", Kind: "ReplicaSet", Name: "web", Pods: 2},
+		{Namespace: "default", Kind: "StatefulSet", Name: "db", Pods: 1},
+	}
+	if !reflect.DeepEqual(owners, expected) {
+		t.Errorf("expected owners %+v, got %+v", expected, owners)
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	noticedAt := time.Date(2019, 3, 29, 10, 0, 0, 0, time.UTC)
+
+	newInterruption := func(nodePool string, latency time.Duration, completed bool) Interruption {
+		interruption := Interruption{NodePool: nodePool, NoticedAt: noticedAt}
+		interruption.Completed = completed
+		if latency > 0 {
+			rescheduledAt := noticedAt.Add(latency)
+			interruption.RescheduledAt = &rescheduledAt
+		}
+
+		return interruption
+	}
+
+	interruptions := []Interruption{
+		newInterruption("pool1", 10*time.Second, true),
+		newInterruption("pool1", 30*time.Second, true),
+		newInterruption("pool1", 0, false),
+		newInterruption("pool2", 60*time.Second, true),
+		newInterruption("pool2", 0, true),
+	}
+
+	report := NewReport(1, interruptions, noticedAt.Add(-time.Hour), noticedAt.Add(time.Hour))
+
+	expected := Summary{
+		Interruptions:  5,
+		Rescheduled:    3,
+		Pending:        1,
+		Unrescheduled:  1,
+		AverageLatency: 100.0 / 3,
+		P90Latency:     60,
+		MaxLatency:     60,
+	}
+	if report.Summary != expected {
+		t.Errorf("expected summary %+v, got %+v", expected, report.Summary)
+	}
+
+	pool1 := report.NodePools["pool1"]
+	if pool1.Interruptions != 3 || pool1.Rescheduled != 2 || pool1.Pending != 1 || pool1.MaxLatency != 30 {
+		t.Errorf("unexpected pool1 summary %+v", pool1)
+	}
+
+	pool2 := report.NodePools["pool2"]
+	if pool2.Interruptions != 2 || pool2.Rescheduled != 1 || pool2.Unrescheduled != 1 || pool2.AverageLatency != 60 {
+		t.Errorf("unexpected pool2 summary %+v", pool2)
+	}
+}
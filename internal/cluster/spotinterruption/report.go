@@ -0,0 +1,137 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotinterruption
+
+import (
+	"sort"
+	"time"
+)
+
+// Report summarizes the interruptions of a cluster in a period.
+type Report struct {
+	ClusterID     uint               `json:"clusterId"`
+	From          time.Time          `json:"from"`
+	To            time.Time          `json:"to"`
+	Summary       Summary            `json:"summary"`
+	NodePools     map[string]Summary `json:"nodePools"`
+	Interruptions []ReportItem       `json:"interruptions"`
+}
+
+// ReportItem is an interruption with its rescheduling latency.
+type ReportItem struct {
+	Interruption
+
+	// Latency is the rescheduling latency in seconds, omitted until every replacement pod is ready
+	Latency *float64 `json:"latency,omitempty"`
+}
+
+// Summary contains interruption counts and rescheduling latency statistics.
+type Summary struct {
+	// Interruptions is the number of interruption notices
+	Interruptions int `json:"interruptions"`
+	// Rescheduled is the number of interruptions after which every workload was rescheduled
+	Rescheduled int `json:"rescheduled"`
+	// Pending is the number of interruptions after which workloads are still being rescheduled
+	Pending int `json:"pending"`
+	// Unrescheduled is the number of interruptions after which no workload was rescheduled in time
+	Unrescheduled int `json:"unrescheduled"`
+	// AverageLatency is the average rescheduling latency in seconds
+	AverageLatency float64 `json:"averageLatency"`
+	// P90Latency is the 90th percentile of rescheduling latencies in seconds
+	P90Latency float64 `json:"p90Latency"`
+	// MaxLatency is the maximum rescheduling latency in seconds
+	MaxLatency float64 `json:"maxLatency"`
+}
+
+// NewReport returns a report of the interruptions of a cluster in a period.
+func NewReport(clusterID uint, interruptions []Interruption, from time.Time, to time.Time) Report {
+	report := Report{
+		ClusterID:     clusterID,
+		From:          from,
+		To:            to,
+		Summary:       newSummary(interruptions),
+		NodePools:     make(map[string]Summary),
+		Interruptions: make([]ReportItem, 0, len(interruptions)),
+	}
+
+	nodePools := make(map[string][]Interruption)
+	for _, interruption := range interruptions {
+		nodePools[interruption.NodePool] = append(nodePools[interruption.NodePool], interruption)
+
+		item := ReportItem{Interruption: interruption}
+		if latency := interruption.Latency(); latency != nil {
+			seconds := latency.Seconds()
+			item.Latency = &seconds
+		}
+		report.Interruptions = append(report.Interruptions, item)
+	}
+
+	for nodePool, nodePoolInterruptions := range nodePools {
+		report.NodePools[nodePool] = newSummary(nodePoolInterruptions)
+	}
+
+	return report
+}
+
+func newSummary(interruptions []Interruption) Summary {
+	summary := Summary{
+		Interruptions: len(interruptions),
+	}
+
+	var latencies []float64
+	for _, interruption := range interruptions {
+		latency := interruption.Latency()
+
+		switch {
+		case latency != nil:
+			summary.Rescheduled++
+			latencies = append(latencies, latency.Seconds())
+		case !interruption.Completed:
+			summary.Pending++
+		default:
+			summary.Unrescheduled++
+		}
+	}
+
+	if len(latencies) == 0 {
+		return summary
+	}
+
+	sort.Float64s(latencies)
+
+	var sum float64
+	for _, latency := range latencies {
+		sum += latency
+	}
+
+	summary.AverageLatency = sum / float64(len(latencies))
+	summary.P90Latency = percentile(latencies, 0.9)
+	summary.MaxLatency = latencies[len(latencies)-1]
+
+	return summary
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+
+	return sorted[rank]
+}
@@ -0,0 +1,162 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotinterruption
+
+import (
+	"encoding/json"
+	"time"
+
+	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Store persists the interruptions of clusters.
+type Store struct {
+	db *gorm.DB
+}
+
+// NewStore returns a new Store instance.
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Exists returns true if an interruption of a cluster is already saved.
+func (s *Store) Exists(clusterID uint, eventUID string) (bool, error) {
+	var count int
+
+	err := s.db.
+		Model(&intCluster.SpotInterruptionModel{}).
+		Where("cluster_id = ? AND event_uid = ?", clusterID, eventUID).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.Wrap(err, "could not check interruption")
+	}
+
+	return count > 0, nil
+}
+
+// Add saves an interruption of a cluster unless it is already saved.
+// It returns true if the interruption is new.
+func (s *Store) Add(clusterID uint, interruption Interruption) (bool, error) {
+	exists, err := s.Exists(clusterID, interruption.EventUID)
+	if err != nil || exists {
+		return false, err
+	}
+
+	m, err := newInterruptionModel(clusterID, interruption)
+	if err != nil {
+		return false, err
+	}
+
+	return true, errors.Wrap(s.db.Create(&m).Error, "could not save interruption")
+}
+
+// UpdateRescheduling updates the rescheduling state of an interruption.
+func (s *Store) UpdateRescheduling(id uint, rescheduling Rescheduling) error {
+	err := s.db.
+		Model(&intCluster.SpotInterruptionModel{ID: id}).
+		Updates(map[string]interface{}{
+			"rescheduled_pods": rescheduling.RescheduledPods,
+			"pending_pods":     rescheduling.PendingPods,
+			"rescheduled_at":   rescheduling.RescheduledAt,
+			"completed":        rescheduling.Completed,
+		}).Error
+
+	return errors.Wrap(err, "could not update interruption")
+}
+
+// FindInProgress returns the interruptions of a cluster which rescheduling is still tracked.
+func (s *Store) FindInProgress(clusterID uint) ([]Interruption, error) {
+	return s.find(s.db.Where("cluster_id = ? AND completed = ?", clusterID, false))
+}
+
+// Find returns the interruptions of a cluster noticed in the given period ordered by time.
+func (s *Store) Find(clusterID uint, from time.Time, to time.Time) ([]Interruption, error) {
+	return s.find(s.db.Where("cluster_id = ? AND noticed_at >= ? AND noticed_at < ?", clusterID, from, to))
+}
+
+func (s *Store) find(query *gorm.DB) ([]Interruption, error) {
+	var models []intCluster.SpotInterruptionModel
+
+	err := query.Order("noticed_at").Find(&models).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch interruptions")
+	}
+
+	interruptions := make([]Interruption, 0, len(models))
+	for _, m := range models {
+		interruption, err := newInterruption(m)
+		if err != nil {
+			return nil, err
+		}
+		interruptions = append(interruptions, interruption)
+	}
+
+	return interruptions, nil
+}
+
+// DeleteBefore deletes the interruptions of every cluster noticed before the given time.
+func (s *Store) DeleteBefore(before time.Time) error {
+	err := s.db.Where("noticed_at < ?", before).Delete(intCluster.SpotInterruptionModel{}).Error
+
+	return errors.Wrap(err, "could not delete interruptions")
+}
+
+func newInterruptionModel(clusterID uint, interruption Interruption) (intCluster.SpotInterruptionModel, error) {
+	owners, err := json.Marshal(interruption.Owners)
+	if err != nil {
+		return intCluster.SpotInterruptionModel{}, errors.Wrap(err, "could not marshal pod owners")
+	}
+
+	return intCluster.SpotInterruptionModel{
+		ClusterID:       clusterID,
+		EventUID:        interruption.EventUID,
+		NodeName:        interruption.NodeName,
+		NodePool:        interruption.NodePool,
+		InstanceType:    interruption.InstanceType,
+		NoticedAt:       interruption.NoticedAt,
+		Owners:          string(owners),
+		RescheduledPods: interruption.RescheduledPods,
+		PendingPods:     interruption.PendingPods,
+		RescheduledAt:   interruption.RescheduledAt,
+		Completed:       interruption.Completed,
+	}, nil
+}
+
+func newInterruption(m intCluster.SpotInterruptionModel) (Interruption, error) {
+	var owners []PodOwner
+	if m.Owners != "" {
+		if err := json.Unmarshal([]byte(m.Owners), &owners); err != nil {
+			return Interruption{}, errors.Wrapf(err, "could not unmarshal pod owners of interruption %d", m.ID)
+		}
+	}
+
+	return Interruption{
+		ID:           m.ID,
+		EventUID:     m.EventUID,
+		NodeName:     m.NodeName,
+		NodePool:     m.NodePool,
+		InstanceType: m.InstanceType,
+		NoticedAt:    m.NoticedAt,
+		Owners:       owners,
+		Rescheduling: Rescheduling{
+			RescheduledPods: m.RescheduledPods,
+			PendingPods:     m.PendingPods,
+			RescheduledAt:   m.RescheduledAt,
+			Completed:       m.Completed,
+		},
+	}, nil
+}
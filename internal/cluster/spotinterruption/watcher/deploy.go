@@ -0,0 +1,144 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watcher
+
+import (
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Name is the name of the watcher DaemonSet and its RBAC resources
+	Name = "spot-interruption-watcher"
+
+	// Command is the path of the watcher binary in the Pipeline image
+	Command = "/spotwatcher"
+
+	googlePreemptibleLabel = "cloud.google.com/gke-preemptible"
+)
+
+// Deploy creates or updates the watcher DaemonSet of a cluster with the RBAC resources it needs
+// to record events on nodes. On GKE the watcher only runs on preemptible nodes.
+func Deploy(client kubernetes.Interface, namespace string, image string, cloud string) error {
+	switch cloud {
+	case pkgCluster.Google, pkgCluster.Azure:
+	default:
+		return errors.Errorf("spot interruption watcher is not supported on %s", cloud)
+	}
+
+	serviceAccount := &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: Name, Namespace: namespace},
+	}
+	_, err := client.CoreV1().ServiceAccounts(namespace).Create(serviceAccount)
+	if err != nil && !k8sapierrors.IsAlreadyExists(err) {
+		return emperror.Wrap(err, "failed to create service account")
+	}
+
+	clusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: Name},
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get"}},
+			{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"list", "create"}},
+		},
+	}
+	_, err = client.RbacV1().ClusterRoles().Create(clusterRole)
+	if k8sapierrors.IsAlreadyExists(err) {
+		_, err = client.RbacV1().ClusterRoles().Update(clusterRole)
+	}
+	if err != nil {
+		return emperror.Wrap(err, "failed to create cluster role")
+	}
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: Name},
+		Subjects: []rbacv1.Subject{
+			{Kind: rbacv1.ServiceAccountKind, Name: Name, Namespace: namespace},
+		},
+		RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: Name},
+	}
+	_, err = client.RbacV1().ClusterRoleBindings().Create(clusterRoleBinding)
+	if err != nil && !k8sapierrors.IsAlreadyExists(err) {
+		return emperror.Wrap(err, "failed to create cluster role binding")
+	}
+
+	daemonSet := newDaemonSet(namespace, image, cloud)
+	_, err = client.AppsV1().DaemonSets(namespace).Create(daemonSet)
+	if k8sapierrors.IsAlreadyExists(err) {
+		_, err = client.AppsV1().DaemonSets(namespace).Update(daemonSet)
+	}
+
+	return emperror.Wrap(err, "failed to create spot interruption watcher daemon set")
+}
+
+func newDaemonSet(namespace string, image string, cloud string) *appsv1.DaemonSet {
+	labels := map[string]string{"app": Name}
+
+	var nodeSelector map[string]string
+	if cloud == pkgCluster.Google {
+		nodeSelector = map[string]string{googlePreemptibleLabel: "true"}
+	}
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					ServiceAccountName: Name,
+					// the instance metadata service is reached on the network of the node
+					HostNetwork:  true,
+					DNSPolicy:    v1.DNSClusterFirstWithHostNet,
+					NodeSelector: nodeSelector,
+					Tolerations:  []v1.Toleration{{Operator: v1.TolerationOpExists}},
+					Containers: []v1.Container{
+						{
+							Name:    Name,
+							Image:   image,
+							Command: []string{Command},
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse("10m"),
+									v1.ResourceMemory: resource.MustParse("32Mi"),
+								},
+							},
+							Env: []v1.EnvVar{
+								{Name: "CLOUD", Value: cloud},
+								{
+									Name: "NODE_NAME",
+									ValueFrom: &v1.EnvVarSource{
+										FieldRef: &v1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EventReason is the reason of the Kubernetes events recorded on nodes receiving an interruption notice,
+// by the instance termination handler on EC2 and by the watcher on GCE and Azure.
+const EventReason = "SpotInterruption"
+
+// Component is the source component of the events recorded by the watcher.
+const Component = "spot-interruption-watcher"
+
+// metadata endpoints of the clouds, overridden in tests
+const (
+	googleMetadataURL = "http://metadata.google.internal"
+	azureMetadataURL  = "http://169.254.169.254"
+)
+
+// NoticeChecker checks whether the instance of the node received an interruption notice.
+type NoticeChecker interface {
+	// CheckNotice returns a description of the notice or an empty string if there is no notice.
+	CheckNotice(ctx context.Context) (string, error)
+}
+
+// GoogleNoticeChecker checks the preemption notice of a GCE preemptible instance.
+type GoogleNoticeChecker struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewGoogleNoticeChecker returns a new GoogleNoticeChecker instance.
+func NewGoogleNoticeChecker(client *http.Client) *GoogleNoticeChecker {
+	return &GoogleNoticeChecker{
+		client:  client,
+		baseURL: googleMetadataURL,
+	}
+}
+
+// CheckNotice implements the NoticeChecker interface.
+func (c *GoogleNoticeChecker) CheckNotice(ctx context.Context) (string, error) {
+	body, err := getMetadata(ctx, c.client, c.baseURL+"/computeMetadata/v1/instance/preempted", "Metadata-Flavor", "Google")
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(string(body)) != "TRUE" {
+		return "", nil
+	}
+
+	return "GCE preemption notice received", nil
+}
+
+// AzureNoticeChecker checks the eviction notice of an Azure low-priority virtual machine in its scheduled events.
+type AzureNoticeChecker struct {
+	client  *http.Client
+	baseURL string
+
+	vmName string
+}
+
+// NewAzureNoticeChecker returns a new AzureNoticeChecker instance.
+func NewAzureNoticeChecker(client *http.Client) *AzureNoticeChecker {
+	return &AzureNoticeChecker{
+		client:  client,
+		baseURL: azureMetadataURL,
+	}
+}
+
+type azureScheduledEvents struct {
+	Events []struct {
+		EventID   string   `json:"EventId"`
+		EventType string   `json:"EventType"`
+		Resources []string `json:"Resources"`
+		NotBefore string   `json:"NotBefore"`
+	} `json:"Events"`
+}
+
+// CheckNotice implements the NoticeChecker interface.
+func (c *AzureNoticeChecker) CheckNotice(ctx context.Context) (string, error) {
+	if c.vmName == "" {
+		body, err := getMetadata(ctx, c.client, c.baseURL+"/metadata/instance/compute/name?api-version=2017-08-01&format=text", "Metadata", "true")
+		if err != nil {
+			return "", err
+		}
+
+		c.vmName = strings.TrimSpace(string(body))
+	}
+
+	body, err := getMetadata(ctx, c.client, c.baseURL+"/metadata/scheduledevents?api-version=2017-11-01", "Metadata", "true")
+	if err != nil {
+		return "", err
+	}
+
+	var scheduledEvents azureScheduledEvents
+	if err := json.Unmarshal(body, &scheduledEvents); err != nil {
+		return "", errors.Wrap(err, "failed to parse scheduled events")
+	}
+
+	for _, event := range scheduledEvents.Events {
+		if event.EventType != "Preempt" {
+			continue
+		}
+
+		for _, resource := range event.Resources {
+			if resource == c.vmName {
+				return fmt.Sprintf("Azure low-priority eviction scheduled not before %s", event.NotBefore), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func getMetadata(ctx context.Context, client *http.Client, url string, header string, value string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create metadata request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set(header, value)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get instance metadata")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected instance metadata response status: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	return body, errors.Wrap(err, "failed to read instance metadata")
+}
+
+// Watcher polls the instance metadata of a node and records an interruption event on the node once a notice arrives.
+type Watcher struct {
+	client   kubernetes.Interface
+	checker  NoticeChecker
+	nodeName string
+	interval time.Duration
+	logger   logrus.FieldLogger
+}
+
+// NewWatcher returns a new Watcher instance.
+func NewWatcher(client kubernetes.Interface, checker NoticeChecker, nodeName string, interval time.Duration, logger logrus.FieldLogger) *Watcher {
+	return &Watcher{
+		client:   client,
+		checker:  checker,
+		nodeName: nodeName,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run polls the notices until one arrives or the context is cancelled.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		message, err := w.checker.CheckNotice(ctx)
+		if err != nil {
+			w.logger.WithError(err).Warn("failed to check interruption notice")
+		} else if message != "" {
+			w.logger.WithField("node", w.nodeName).Info(message)
+
+			return RecordInterruption(w.client, w.nodeName, message, time.Now())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// RecordInterruption records an interruption event on a node, unless the node already has one.
+func RecordInterruption(client kubernetes.Interface, nodeName string, message string, noticedAt time.Time) error {
+	events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(metav1.ListOptions{
+		FieldSelector: fields.Set{"involvedObject.kind": "Node", "involvedObject.name": nodeName, "reason": EventReason}.String(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list interruption events")
+	}
+
+	if len(events.Items) > 0 {
+		return nil
+	}
+
+	node, err := client.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get node %s", nodeName)
+	}
+
+	timestamp := metav1.NewTime(noticedAt)
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", nodeName, noticedAt.UnixNano()),
+			Namespace: metav1.NamespaceDefault,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind: "Node",
+			Name: nodeName,
+			UID:  node.UID,
+		},
+		Reason:         EventReason,
+		Message:        message,
+		Type:           v1.EventTypeWarning,
+		Source:         v1.EventSource{Component: Component, Host: nodeName},
+		FirstTimestamp: timestamp,
+		LastTimestamp:  timestamp,
+		Count:          1,
+	}
+
+	_, err = client.CoreV1().Events(metav1.NamespaceDefault).Create(event)
+
+	return errors.Wrap(err, "failed to record interruption event")
+}
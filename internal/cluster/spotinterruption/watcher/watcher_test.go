@@ -0,0 +1,166 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package watcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGoogleNoticeChecker_CheckNotice(t *testing.T) {
+	tests := map[string]struct {
+		preempted string
+		notice    bool
+	}{
+		"preempted":     {preempted: "TRUE", notice: true},
+		"not preempted": {preempted: "FALSE", notice: false},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/computeMetadata/v1/instance/preempted" || r.Header.Get("Metadata-Flavor") != "Google" {
+					w.WriteHeader(http.StatusNotFound)
+
+					return
+				}
+
+				_, _ = w.Write([]byte(test.preempted))
+			}))
+			defer server.Close()
+
+			checker := NewGoogleNoticeChecker(server.Client())
+			checker.baseURL = server.URL
+
+			message, err := checker.CheckNotice(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if (message != "") != test.notice {
+				t.Errorf("unexpected notice: %q", message)
+			}
+		})
+	}
+}
+
+func TestAzureNoticeChecker_CheckNotice(t *testing.T) {
+	tests := map[string]struct {
+		events string
+		notice bool
+	}{
+		"evicted":     {events: `{"Events":[{"EventId":"1","EventType":"Preempt","Resources":["vm-1"],"NotBefore":"Mon, 19 Sep 2016 18:29:47 GMT"}]}`, notice: true},
+		"other vm":    {events: `{"Events":[{"EventId":"1","EventType":"Preempt","Resources":["vm-2"]}]}`, notice: false},
+		"other event": {events: `{"Events":[{"EventId":"1","EventType":"Reboot","Resources":["vm-1"]}]}`, notice: false},
+		"no events":   {events: `{"Events":[]}`, notice: false},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Metadata") != "true" {
+					w.WriteHeader(http.StatusBadRequest)
+
+					return
+				}
+
+				switch r.URL.Path {
+				case "/metadata/instance/compute/name":
+					_, _ = w.Write([]byte("vm-1"))
+				case "/metadata/scheduledevents":
+					_, _ = w.Write([]byte(test.events))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			checker := NewAzureNoticeChecker(server.Client())
+			checker.baseURL = server.URL
+
+			message, err := checker.CheckNotice(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if (message != "") != test.notice {
+				t.Errorf("unexpected notice: %q", message)
+			}
+		})
+	}
+}
+
+func TestRecordInterruption(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", UID: "uid-1"},
+	})
+
+	for i := 0; i < 2; i++ {
+		err := RecordInterruption(client, "node-1", "notice", time.Now().Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events.Items) != 1 {
+		t.Fatalf("expected exactly one event, got %d", len(events.Items))
+	}
+
+	event := events.Items[0]
+	if event.Reason != EventReason || event.InvolvedObject.Kind != "Node" || event.InvolvedObject.UID != "uid-1" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	// deploying twice updates the existing objects
+	for i := 0; i < 2; i++ {
+		err := Deploy(client, "pipeline-system", "banzaicloud/pipeline:latest", pkgCluster.Google)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	daemonSet, err := client.AppsV1().DaemonSets("pipeline-system").Get(Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if daemonSet.Spec.Template.Spec.NodeSelector[googlePreemptibleLabel] != "true" {
+		t.Errorf("expected preemptible node selector, got %v", daemonSet.Spec.Template.Spec.NodeSelector)
+	}
+
+	if err := Deploy(client, "pipeline-system", "banzaicloud/pipeline:latest", pkgCluster.Amazon); err == nil {
+		t.Error("expected error for unsupported cloud")
+	}
+}
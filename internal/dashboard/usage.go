@@ -0,0 +1,128 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/cluster"
+	"github.com/banzaicloud/pipeline/config"
+	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/cluster/resourceusage"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/pkg/providers"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultUsagePeriod = 24 * time.Hour
+	defaultUsageStep   = time.Hour
+	maxUsagePoints     = 1000
+)
+
+// GetClusterUsageQueryParams describes the query params of a cluster usage request.
+type GetClusterUsageQueryParams struct {
+	// RFC3339 timestamp, defaults to 24 hours before to
+	From string `form:"from"`
+	// RFC3339 timestamp, defaults to now
+	To string `form:"to"`
+	// duration of a point, eg. 1h
+	Step string `form:"step"`
+}
+
+// GetClusterUsage returns the resource usage history of a cluster and its node pools.
+func GetClusterUsage(c *gin.Context) {
+	// TODO: move these to a struct and create them only once upon application init
+	secretValidator := providers.NewSecretValidator(secret.Store)
+	clusterManager := cluster.NewManager(intCluster.NewClusters(config.DB()), secretValidator, cluster.NewNopClusterEvents(), nil, nil, nil, log, errorHandler)
+
+	commonCluster, ok := common.NewClusterGetter(clusterManager, log, errorHandler).GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	var params GetClusterUsageQueryParams
+	if err := c.BindQuery(&params); err != nil {
+		return
+	}
+
+	from, to, step, err := parseUsagePeriod(params, time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid usage period",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	samples, err := resourceusage.NewStore(config.DB()).Find(commonCluster.GetID(), from, to)
+	if err != nil {
+		errorHandler.Handle(err)
+
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error fetching resource usage",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resourceusage.GetUsage(samples, from, to, step))
+}
+
+func parseUsagePeriod(params GetClusterUsageQueryParams, now time.Time) (from time.Time, to time.Time, step time.Duration, err error) {
+	to = now
+	if params.To != "" {
+		to, err = time.Parse(time.RFC3339, params.To)
+		if err != nil {
+			return from, to, step, errors.Wrap(err, "invalid to")
+		}
+	}
+
+	from = to.Add(-defaultUsagePeriod)
+	if params.From != "" {
+		from, err = time.Parse(time.RFC3339, params.From)
+		if err != nil {
+			return from, to, step, errors.Wrap(err, "invalid from")
+		}
+	}
+
+	step = defaultUsageStep
+	if params.Step != "" {
+		step, err = time.ParseDuration(params.Step)
+		if err != nil {
+			return from, to, step, errors.Wrap(err, "invalid step")
+		}
+	}
+
+	if !from.Before(to) {
+		return from, to, step, errors.New("from must be before to")
+	}
+
+	if step < time.Minute {
+		return from, to, step, errors.New("step must be at least one minute")
+	}
+
+	if to.Sub(from)/step > maxUsagePoints {
+		return from, to, step, errors.Errorf("period must not contain more than %d steps", maxUsagePoints)
+	}
+
+	return from, to, step, nil
+}
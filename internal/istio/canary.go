@@ -0,0 +1,329 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"encoding/json"
+
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// VersionLabel is the pod label the versions of a service are selected by
+	VersionLabel = "version"
+
+	managedByLabel      = "istio.banzaicloud.io/managed-by"
+	managedByLabelValue = "pipeline"
+
+	networkingAPIVersion = "networking.istio.io/v1alpha3"
+)
+
+var (
+	virtualServiceResource  = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "virtualservices"}
+	destinationRuleResource = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "destinationrules"}
+)
+
+// Canary describes how the traffic of a service is shifted between its versions
+type Canary struct {
+	Namespace string          `json:"namespace" binding:"required"`
+	Service   string          `json:"service" binding:"required"`
+	Versions  []CanaryVersion `json:"versions" binding:"required"`
+}
+
+// CanaryVersion is a version of a service and the percentage of the traffic it receives
+type CanaryVersion struct {
+	// Version is the value of the version label of the pods
+	Version string `json:"version" binding:"required"`
+	Weight  int    `json:"weight"`
+}
+
+// Validate validates the canary traffic shifting settings
+func (c Canary) Validate() error {
+	if errs := validation.IsDNS1123Label(c.Namespace); len(errs) > 0 {
+		return errors.Errorf("invalid namespace %q: %s", c.Namespace, errs[0])
+	}
+
+	if errs := validation.IsDNS1123Label(c.Service); len(errs) > 0 {
+		return errors.Errorf("invalid service %q: %s", c.Service, errs[0])
+	}
+
+	if len(c.Versions) == 0 {
+		return errors.New("at least one version is required")
+	}
+
+	versions := make(map[string]bool, len(c.Versions))
+	var totalWeight int
+	for _, version := range c.Versions {
+		if errs := validation.IsDNS1123Label(version.Version); len(errs) > 0 {
+			return errors.Errorf("invalid version %q: %s", version.Version, errs[0])
+		}
+
+		if versions[version.Version] {
+			return errors.Errorf("version %q is listed more than once", version.Version)
+		}
+		versions[version.Version] = true
+
+		if version.Weight < 0 || version.Weight > 100 {
+			return errors.Errorf("weight of version %q must be between 0 and 100", version.Version)
+		}
+		totalWeight += version.Weight
+	}
+
+	if totalWeight != 100 {
+		return errors.Errorf("weights must add up to 100, got %d", totalWeight)
+	}
+
+	return nil
+}
+
+type virtualServiceSpec struct {
+	Hosts []string    `json:"hosts"`
+	HTTP  []httpRoute `json:"http"`
+}
+
+type httpRoute struct {
+	Route []destinationWeight `json:"route"`
+}
+
+type destinationWeight struct {
+	Destination destination `json:"destination"`
+	Weight      int         `json:"weight"`
+}
+
+type destination struct {
+	Host   string `json:"host"`
+	Subset string `json:"subset,omitempty"`
+}
+
+type destinationRuleSpec struct {
+	Host          string         `json:"host"`
+	TrafficPolicy *trafficPolicy `json:"trafficPolicy,omitempty"`
+	Subsets       []subset       `json:"subsets"`
+}
+
+type trafficPolicy struct {
+	TLS tlsSettings `json:"tls"`
+}
+
+type tlsSettings struct {
+	Mode string `json:"mode"`
+}
+
+type subset struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+}
+
+func newVirtualServiceSpec(canary Canary) virtualServiceSpec {
+	route := httpRoute{}
+	for _, version := range canary.Versions {
+		route.Route = append(route.Route, destinationWeight{
+			Destination: destination{Host: canary.Service, Subset: version.Version},
+			Weight:      version.Weight,
+		})
+	}
+
+	return virtualServiceSpec{
+		Hosts: []string{canary.Service},
+		HTTP:  []httpRoute{route},
+	}
+}
+
+// newDestinationRuleSpec returns the DestinationRule of the versions of a service,
+// the traffic sent to the subsets has to use Istio mutual TLS when mTLS is enabled in the mesh
+func newDestinationRuleSpec(canary Canary, mtls bool) destinationRuleSpec {
+	spec := destinationRuleSpec{
+		Host: canary.Service,
+	}
+	if mtls {
+		spec.TrafficPolicy = &trafficPolicy{TLS: tlsSettings{Mode: "ISTIO_MUTUAL"}}
+	}
+	for _, version := range canary.Versions {
+		spec.Subsets = append(spec.Subsets, subset{
+			Name:   version.Version,
+			Labels: map[string]string{VersionLabel: version.Version},
+		})
+	}
+
+	return spec
+}
+
+// ApplyCanary creates or updates the DestinationRule and VirtualService shifting the traffic of a service between its versions.
+// Subsets are only removed from the DestinationRule after the VirtualService stops routing to them,
+// so the traffic is never routed to an undefined subset.
+func ApplyCanary(client dynamic.Interface, canary Canary, mtls bool) error {
+	ruleSpec := newDestinationRuleSpec(canary, mtls)
+
+	removedSubsets, err := getRemovedSubsets(client, canary.Namespace, canary.Service, ruleSpec)
+	if err != nil {
+		return err
+	}
+
+	transitionSpec := ruleSpec
+	transitionSpec.Subsets = append(append([]subset(nil), ruleSpec.Subsets...), removedSubsets...)
+
+	err = applyResource(client, destinationRuleResource, "DestinationRule", canary.Namespace, canary.Service, transitionSpec)
+	if err != nil {
+		return emperror.Wrap(err, "failed to apply destination rule")
+	}
+
+	err = applyResource(client, virtualServiceResource, "VirtualService", canary.Namespace, canary.Service, newVirtualServiceSpec(canary))
+	if err != nil {
+		return emperror.Wrap(err, "failed to apply virtual service")
+	}
+
+	if len(removedSubsets) > 0 {
+		err = applyResource(client, destinationRuleResource, "DestinationRule", canary.Namespace, canary.Service, ruleSpec)
+		if err != nil {
+			return emperror.Wrap(err, "failed to remove subsets from destination rule")
+		}
+	}
+
+	return nil
+}
+
+// getRemovedSubsets returns the subsets of the existing DestinationRule of a service which are not part of the new spec
+func getRemovedSubsets(client dynamic.Interface, namespace string, name string, spec destinationRuleSpec) ([]subset, error) {
+	obj, err := client.Resource(destinationRuleResource).Namespace(namespace).Get(name, metav1.GetOptions{})
+	if k8sapierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, emperror.WrapWith(err, "failed to get resource", "kind", "DestinationRule", "name", name)
+	}
+
+	if obj.GetLabels()[managedByLabel] != managedByLabelValue {
+		return nil, errors.Errorf("DestinationRule %s/%s is not managed by pipeline", namespace, name)
+	}
+
+	var existing destinationRuleSpec
+	err = convert(obj.Object["spec"], &existing)
+	if err != nil {
+		return nil, emperror.WrapWith(err, "failed to parse destination rule", "name", name)
+	}
+
+	subsets := make(map[string]bool, len(spec.Subsets))
+	for _, s := range spec.Subsets {
+		subsets[s.Name] = true
+	}
+
+	var removed []subset
+	for _, s := range existing.Subsets {
+		if !subsets[s.Name] {
+			removed = append(removed, s)
+		}
+	}
+
+	return removed, nil
+}
+
+func applyResource(client dynamic.Interface, resource schema.GroupVersionResource, kind string, namespace string, name string, spec interface{}) error {
+	specMap, err := toMap(spec)
+	if err != nil {
+		return err
+	}
+
+	resourceClient := client.Resource(resource).Namespace(namespace)
+
+	obj, err := resourceClient.Get(name, metav1.GetOptions{})
+	if k8sapierrors.IsNotFound(err) {
+		obj = &unstructured.Unstructured{}
+		obj.SetAPIVersion(networkingAPIVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		obj.SetLabels(map[string]string{managedByLabel: managedByLabelValue})
+		obj.Object["spec"] = specMap
+
+		_, err = resourceClient.Create(obj)
+		return emperror.WrapWith(err, "failed to create resource", "kind", kind, "name", name)
+	} else if err != nil {
+		return emperror.WrapWith(err, "failed to get resource", "kind", kind, "name", name)
+	}
+
+	if obj.GetLabels()[managedByLabel] != managedByLabelValue {
+		return errors.Errorf("%s %s/%s is not managed by pipeline", kind, namespace, name)
+	}
+
+	obj.Object["spec"] = specMap
+
+	_, err = resourceClient.Update(obj)
+	return emperror.WrapWith(err, "failed to update resource", "kind", kind, "name", name)
+}
+
+// ListCanaries returns the canary traffic shifting settings managed by pipeline in a namespace
+func ListCanaries(client dynamic.Interface, namespace string) ([]Canary, error) {
+	list, err := client.Resource(virtualServiceResource).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: labels.Set{managedByLabel: managedByLabelValue}.String(),
+	})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list virtual services")
+	}
+
+	canaries := make([]Canary, 0, len(list.Items))
+	for _, obj := range list.Items {
+		var spec virtualServiceSpec
+		err := convert(obj.Object["spec"], &spec)
+		if err != nil {
+			return nil, emperror.WrapWith(err, "failed to parse virtual service", "name", obj.GetName())
+		}
+
+		canaries = append(canaries, newCanary(obj.GetNamespace(), obj.GetName(), spec))
+	}
+
+	return canaries, nil
+}
+
+func newCanary(namespace string, service string, spec virtualServiceSpec) Canary {
+	canary := Canary{
+		Namespace: namespace,
+		Service:   service,
+		Versions:  []CanaryVersion{},
+	}
+
+	for _, route := range spec.HTTP {
+		for _, dw := range route.Route {
+			canary.Versions = append(canary.Versions, CanaryVersion{
+				Version: dw.Destination.Subset,
+				Weight:  dw.Weight,
+			})
+		}
+	}
+
+	return canary
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	err := convert(v, &m)
+
+	return m, err
+}
+
+func convert(in interface{}, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return emperror.Wrap(err, "failed to marshal spec")
+	}
+
+	return emperror.Wrap(json.Unmarshal(b, out), "failed to unmarshal spec")
+}
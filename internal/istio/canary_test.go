@@ -0,0 +1,159 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestCanary_Validate(t *testing.T) {
+	tests := map[string]struct {
+		canary Canary
+		valid  bool
+	}{
+		"valid": {
+			canary: Canary{Namespace: "default", Service: "reviews", Versions: []CanaryVersion{{"v1", 90}, {"v2", 10}}},
+			valid:  true,
+		},
+		"single version": {
+			canary: Canary{Namespace: "default", Service: "reviews", Versions: []CanaryVersion{{"v1", 100}}},
+			valid:  true,
+		},
+		"no versions": {
+			canary: Canary{Namespace: "default", Service: "reviews"},
+		},
+		"invalid sum": {
+			canary: Canary{Namespace: "default", Service: "reviews", Versions: []CanaryVersion{{"v1", 90}, {"v2", 20}}},
+		},
+		"negative weight": {
+			canary: Canary{Namespace: "default", Service: "reviews", Versions: []CanaryVersion{{"v1", 110}, {"v2", -10}}},
+		},
+		"duplicate version": {
+			canary: Canary{Namespace: "default", Service: "reviews", Versions: []CanaryVersion{{"v1", 50}, {"v1", 50}}},
+		},
+		"invalid version": {
+			canary: Canary{Namespace: "default", Service: "reviews", Versions: []CanaryVersion{{"V_1", 100}}},
+		},
+		"invalid service": {
+			canary: Canary{Namespace: "default", Service: "", Versions: []CanaryVersion{{"v1", 100}}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.canary.Validate()
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if !test.valid && err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestApplyCanary(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	canary := Canary{Namespace: "default", Service: "reviews", Versions: []CanaryVersion{{"v1", 90}, {"v2", 10}}}
+	if err := ApplyCanary(client, canary, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	canary.Versions = []CanaryVersion{{"v2", 50}, {"v3", 50}}
+	if err := ApplyCanary(client, canary, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	obj, err := client.Resource(virtualServiceResource).Namespace("default").Get("reviews", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var spec virtualServiceSpec
+	if err := convert(obj.Object["spec"], &spec); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if actual := newCanary("default", "reviews", spec); !reflect.DeepEqual(actual, canary) {
+		t.Errorf("expected %+v, got %+v", canary, actual)
+	}
+
+	obj, err = client.Resource(destinationRuleResource).Namespace("default").Get("reviews", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var ruleSpec destinationRuleSpec
+	if err := convert(obj.Object["spec"], &ruleSpec); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(ruleSpec, newDestinationRuleSpec(canary, true)) {
+		t.Errorf("unexpected destination rule spec %+v", ruleSpec)
+	}
+
+	if ruleSpec.TrafficPolicy == nil || ruleSpec.TrafficPolicy.TLS.Mode != "ISTIO_MUTUAL" {
+		t.Errorf("expected ISTIO_MUTUAL traffic policy, got %+v", ruleSpec.TrafficPolicy)
+	}
+}
+
+func TestApplyCanary_RemovedVersions(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	canary := Canary{Namespace: "default", Service: "reviews", Versions: []CanaryVersion{{"v1", 90}, {"v2", 10}}}
+	if err := ApplyCanary(client, canary, false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	client.ClearActions()
+
+	canary.Versions = []CanaryVersion{{"v2", 100}}
+	if err := ApplyCanary(client, canary, false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var updates []string
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" {
+			updates = append(updates, action.GetResource().Resource)
+		}
+	}
+
+	expected := []string{"destinationrules", "virtualservices", "destinationrules"}
+	if !reflect.DeepEqual(updates, expected) {
+		t.Errorf("expected updates %v, got %v", expected, updates)
+	}
+}
+
+func TestApplyCanary_NotManaged(t *testing.T) {
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion(networkingAPIVersion)
+	existing.SetKind("DestinationRule")
+	existing.SetNamespace("default")
+	existing.SetName("reviews")
+
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme(), existing)
+
+	canary := Canary{Namespace: "default", Service: "reviews", Versions: []CanaryVersion{{"v1", 100}}}
+	if err := ApplyCanary(client, canary, false); err == nil {
+		t.Error("expected error for a destination rule not managed by pipeline")
+	}
+}
@@ -14,15 +14,33 @@
 
 package istio
 
-const Namespace = "istio-system"
+const (
+	Namespace = "istio-system"
+
+	// ReleaseName is the name of the Helm release of the Istio control plane
+	ReleaseName = "istio"
+	// RemoteReleaseName is the name of the Helm release of Istio on the remote clusters of a multi-cluster mesh
+	RemoteReleaseName = "istio-remote"
+)
 
 type Config struct {
-	Global Global `json:"global,omitempty"`
+	Global   Global   `json:"global,omitempty"`
+	Security Security `json:"security,omitempty"`
 }
 
 type Global struct {
 	Mtls  MTLS  `json:"mtls,omitempty"`
 	Proxy Proxy `json:"proxy,omitempty"`
+
+	// control plane addresses of the primary cluster, used by the istio-remote chart
+	RemotePilotAddress     string `json:"remotePilotAddress,omitempty"`
+	RemotePolicyAddress    string `json:"remotePolicyAddress,omitempty"`
+	RemoteTelemetryAddress string `json:"remoteTelemetryAddress,omitempty"`
+}
+
+type Security struct {
+	// SelfSigned is set to false when Citadel signs certificates with the plugged in cacerts secret
+	SelfSigned *bool `json:"selfSigned,omitempty"`
 }
 
 type MTLS struct {
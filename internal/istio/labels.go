@@ -15,15 +15,26 @@
 package istio
 
 import (
+	"encoding/json"
+
 	"github.com/banzaicloud/pipeline/pkg/k8sutil"
 	"github.com/goph/emperror"
 	"github.com/sirupsen/logrus"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	sidecarInjectionLabel        = "istio-injection"
+	sidecarInjectionEnabledValue = "enabled"
+)
+
 func LabelNamespaces(log logrus.FieldLogger, client kubernetes.Interface, namespaces []string) error {
 	var nsLabels = map[string]string{
-		"istio-injection": "enabled",
+		sidecarInjectionLabel: sidecarInjectionEnabledValue,
 	}
 
 	for _, ns := range namespaces {
@@ -34,3 +45,43 @@ func LabelNamespaces(log logrus.FieldLogger, client kubernetes.Interface, namesp
 	}
 	return nil
 }
+
+// UnlabelNamespaces removes the sidecar injection label from the given namespaces, missing namespaces are skipped
+func UnlabelNamespaces(log logrus.FieldLogger, client kubernetes.Interface, namespaces []string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{
+				sidecarInjectionLabel: nil,
+			},
+		},
+	})
+	if err != nil {
+		return emperror.Wrap(err, "failed to marshal namespace patch")
+	}
+
+	for _, ns := range namespaces {
+		_, err := client.CoreV1().Namespaces().Patch(ns, types.MergePatchType, patch)
+		if k8sapierrors.IsNotFound(err) {
+			log.Warnf("namespace %s not found", ns)
+		} else if err != nil {
+			return emperror.WrapWith(err, "failed to unlabel namespace", "namespace", ns)
+		}
+	}
+	return nil
+}
+
+// GetLabelledNamespaces returns the namespaces labelled for automatic sidecar injection
+func GetLabelledNamespaces(client kubernetes.Interface) ([]string, error) {
+	namespaceList, err := client.CoreV1().Namespaces().List(metav1.ListOptions{
+		LabelSelector: labels.Set{sidecarInjectionLabel: sidecarInjectionEnabledValue}.String(),
+	})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list namespaces")
+	}
+
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for _, ns := range namespaceList.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+	return namespaces, nil
+}
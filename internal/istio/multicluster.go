@@ -0,0 +1,294 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// CACertsSecretName is the name of the secret Citadel loads its plugged in signing certificates from
+	CACertsSecretName = "cacerts"
+
+	// RemoteServiceAccountName is the service account the istio-remote chart creates for the primary control plane
+	RemoteServiceAccountName = "istio-multi"
+
+	multiClusterSecretLabel = "istio/multiCluster"
+
+	remoteClusterSecretPrefix = "istio-remote-"
+
+	// control plane services are exposed to remote clusters by load balancer services with this suffix
+	multiClusterServiceSuffix = "-multicluster"
+
+	pilotServiceName     = "istio-pilot"
+	policyServiceName    = "istio-policy"
+	telemetryServiceName = "istio-telemetry"
+)
+
+// ControlPlaneAddresses are the addresses remote clusters reach the control plane of the primary cluster on
+type ControlPlaneAddresses struct {
+	Pilot     string
+	Policy    string
+	Telemetry string
+}
+
+// CreateCACertsSecret creates or updates the cacerts secret with the intermediate CA of a cluster signed by the root CA
+// of a multi-cluster mesh, so that workload certificates issued in any cluster of the mesh are trusted by the others
+func CreateCACertsSecret(client kubernetes.Interface, caCert string, caKey string, rootCert string) error {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CACertsSecretName,
+			Namespace: Namespace,
+		},
+		StringData: map[string]string{
+			"ca-cert.pem":    caCert,
+			"ca-key.pem":     caKey,
+			"root-cert.pem":  rootCert,
+			"cert-chain.pem": caCert + rootCert,
+		},
+	}
+
+	return emperror.Wrap(createOrUpdateSecret(client, secret), "failed to create cacerts secret")
+}
+
+// DeleteCACertsSecret deletes the cacerts secret of a cluster leaving a multi-cluster mesh
+func DeleteCACertsSecret(client kubernetes.Interface) error {
+	err := client.CoreV1().Secrets(Namespace).Delete(CACertsSecretName, &metav1.DeleteOptions{})
+	if err != nil && !k8sapierrors.IsNotFound(err) {
+		return emperror.Wrap(err, "failed to delete cacerts secret")
+	}
+
+	return nil
+}
+
+// ExposeControlPlane creates or updates load balancer services in front of the control plane components
+// of the primary cluster, so that remote clusters reach them on stable addresses
+func ExposeControlPlane(client kubernetes.Interface) error {
+	for _, name := range []string{pilotServiceName, policyServiceName, telemetryServiceName} {
+		service, err := client.CoreV1().Services(Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return emperror.WrapWith(err, "failed to get control plane service", "service", name)
+		}
+
+		lbService := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + multiClusterServiceSuffix,
+				Namespace: Namespace,
+				Labels:    map[string]string{managedByLabel: managedByLabelValue},
+			},
+			Spec: v1.ServiceSpec{
+				Type:     v1.ServiceTypeLoadBalancer,
+				Selector: service.Spec.Selector,
+			},
+		}
+		for _, port := range service.Spec.Ports {
+			lbService.Spec.Ports = append(lbService.Spec.Ports, v1.ServicePort{
+				Name:       port.Name,
+				Protocol:   port.Protocol,
+				Port:       port.Port,
+				TargetPort: port.TargetPort,
+			})
+		}
+
+		_, err = client.CoreV1().Services(Namespace).Create(lbService)
+		if k8sapierrors.IsAlreadyExists(err) {
+			var existing *v1.Service
+			existing, err = client.CoreV1().Services(Namespace).Get(lbService.Name, metav1.GetOptions{})
+			if err == nil {
+				existing.Spec.Selector = lbService.Spec.Selector
+				existing.Spec.Ports = mergeServicePorts(existing.Spec.Ports, lbService.Spec.Ports)
+				_, err = client.CoreV1().Services(Namespace).Update(existing)
+			}
+		}
+		if err != nil {
+			return emperror.WrapWith(err, "failed to expose control plane service", "service", name)
+		}
+	}
+
+	return nil
+}
+
+// mergeServicePorts keeps the node ports allocated to the existing ports of a load balancer service
+func mergeServicePorts(existing []v1.ServicePort, ports []v1.ServicePort) []v1.ServicePort {
+	nodePorts := make(map[string]int32, len(existing))
+	for _, port := range existing {
+		nodePorts[port.Name] = port.NodePort
+	}
+
+	for i := range ports {
+		ports[i].NodePort = nodePorts[ports[i].Name]
+	}
+
+	return ports
+}
+
+// UnexposeControlPlane deletes the load balancer services the control plane is exposed to remote clusters with
+func UnexposeControlPlane(client kubernetes.Interface) error {
+	for _, name := range []string{pilotServiceName, policyServiceName, telemetryServiceName} {
+		err := client.CoreV1().Services(Namespace).Delete(name+multiClusterServiceSuffix, &metav1.DeleteOptions{})
+		if err != nil && !k8sapierrors.IsNotFound(err) {
+			return emperror.WrapWith(err, "failed to delete control plane load balancer service", "service", name)
+		}
+	}
+
+	return nil
+}
+
+// GetControlPlaneAddresses returns the load balancer addresses of the control plane components of the primary cluster,
+// the control plane has to be exposed first
+func GetControlPlaneAddresses(client kubernetes.Interface) (*ControlPlaneAddresses, error) {
+	pilot, err := getLoadBalancerAddress(client, pilotServiceName+multiClusterServiceSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := getLoadBalancerAddress(client, policyServiceName+multiClusterServiceSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	telemetry, err := getLoadBalancerAddress(client, telemetryServiceName+multiClusterServiceSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ControlPlaneAddresses{
+		Pilot:     pilot,
+		Policy:    policy,
+		Telemetry: telemetry,
+	}, nil
+}
+
+// getLoadBalancerAddress returns the ingress IP of a load balancer service, or its hostname when the cloud provider
+// exposes load balancers on hostnames
+func getLoadBalancerAddress(client kubernetes.Interface, name string) (string, error) {
+	service, err := client.CoreV1().Services(Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", emperror.WrapWith(err, "failed to get service", "service", name)
+	}
+
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP, nil
+		}
+	}
+
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.Hostname != "" {
+			return ingress.Hostname, nil
+		}
+	}
+
+	return "", errors.Errorf("load balancer of service %s has no address yet", name)
+}
+
+// NewRemoteKubeConfig returns a kubeconfig the primary control plane can watch a remote cluster with,
+// authenticated by the token of the service account created by the istio-remote chart
+func NewRemoteKubeConfig(client kubernetes.Interface, clusterName string, server string) ([]byte, error) {
+	serviceAccount, err := client.CoreV1().ServiceAccounts(Namespace).Get(RemoteServiceAccountName, metav1.GetOptions{})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get remote service account")
+	}
+
+	if len(serviceAccount.Secrets) == 0 {
+		return nil, errors.New("remote service account has no token yet")
+	}
+
+	tokenSecret, err := client.CoreV1().Secrets(Namespace).Get(serviceAccount.Secrets[0].Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get remote service account token")
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = &clientcmdapi.Cluster{
+		Server:                   server,
+		CertificateAuthorityData: tokenSecret.Data[v1.ServiceAccountRootCAKey],
+	}
+	config.AuthInfos[clusterName] = &clientcmdapi.AuthInfo{
+		Token: string(tokenSecret.Data[v1.ServiceAccountTokenKey]),
+	}
+	config.Contexts[clusterName] = &clientcmdapi.Context{
+		Cluster:  clusterName,
+		AuthInfo: clusterName,
+	}
+	config.CurrentContext = clusterName
+
+	kubeConfig, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to write remote kubeconfig")
+	}
+
+	return kubeConfig, nil
+}
+
+// AddRemoteCluster registers the kubeconfig of a remote cluster in the primary cluster for cross-cluster service discovery
+func AddRemoteCluster(client kubernetes.Interface, clusterName string, kubeConfig []byte) error {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      remoteClusterSecretName(clusterName),
+			Namespace: Namespace,
+			Labels:    map[string]string{multiClusterSecretLabel: "true"},
+		},
+		Data: map[string][]byte{
+			clusterName: kubeConfig,
+		},
+	}
+
+	return emperror.WrapWith(createOrUpdateSecret(client, secret), "failed to create remote cluster secret", "cluster", clusterName)
+}
+
+// RemoveRemoteCluster deletes the kubeconfig of a remote cluster from the primary cluster
+func RemoveRemoteCluster(client kubernetes.Interface, clusterName string) error {
+	err := client.CoreV1().Secrets(Namespace).Delete(remoteClusterSecretName(clusterName), &metav1.DeleteOptions{})
+	if err != nil && !k8sapierrors.IsNotFound(err) {
+		return emperror.WrapWith(err, "failed to delete remote cluster secret", "cluster", clusterName)
+	}
+
+	return nil
+}
+
+// IsRemoteClusterRegistered checks whether the kubeconfig of a remote cluster is registered in the primary cluster
+func IsRemoteClusterRegistered(client kubernetes.Interface, clusterName string) (bool, error) {
+	secret, err := client.CoreV1().Secrets(Namespace).Get(remoteClusterSecretName(clusterName), metav1.GetOptions{})
+	if k8sapierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, emperror.WrapWith(err, "failed to get remote cluster secret", "cluster", clusterName)
+	}
+
+	return secret.Labels[multiClusterSecretLabel] == "true", nil
+}
+
+// remoteClusterSecretName returns the name of the secret of a remote cluster,
+// prefixed so that it does not collide with other secrets of the Istio namespace
+func remoteClusterSecretName(clusterName string) string {
+	return remoteClusterSecretPrefix + clusterName
+}
+
+func createOrUpdateSecret(client kubernetes.Interface, secret *v1.Secret) error {
+	_, err := client.CoreV1().Secrets(secret.Namespace).Create(secret)
+	if k8sapierrors.IsAlreadyExists(err) {
+		_, err = client.CoreV1().Secrets(secret.Namespace).Update(secret)
+	}
+
+	return err
+}
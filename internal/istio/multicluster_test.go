@@ -0,0 +1,129 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newControlPlaneService(name string, selector string) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: Namespace},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"istio": selector},
+			Ports:    []v1.ServicePort{{Name: "grpc", Port: 15010}},
+		},
+	}
+}
+
+func TestExposeControlPlane(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		newControlPlaneService(pilotServiceName, "pilot"),
+		newControlPlaneService(policyServiceName, "mixer"),
+		newControlPlaneService(telemetryServiceName, "mixer"),
+	)
+
+	if err := ExposeControlPlane(client); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := GetControlPlaneAddresses(client); err == nil {
+		t.Error("expected an error before the load balancers get addresses")
+	}
+
+	ingresses := map[string]v1.LoadBalancerIngress{
+		pilotServiceName:     {IP: "10.0.0.1"},
+		policyServiceName:    {IP: "10.0.0.2"},
+		telemetryServiceName: {Hostname: "telemetry.example.com"},
+	}
+	for name, ingress := range ingresses {
+		service, err := client.CoreV1().Services(Namespace).Get(name+multiClusterServiceSuffix, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if service.Spec.Type != v1.ServiceTypeLoadBalancer || service.Spec.Selector["istio"] == "" || len(service.Spec.Ports) != 1 {
+			t.Errorf("unexpected service %+v", service.Spec)
+		}
+
+		service.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{ingress}
+		if _, err := client.CoreV1().Services(Namespace).UpdateStatus(service); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	// exposing again keeps the load balancers
+	if err := ExposeControlPlane(client); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	addresses, err := GetControlPlaneAddresses(client)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := ControlPlaneAddresses{Pilot: "10.0.0.1", Policy: "10.0.0.2", Telemetry: "telemetry.example.com"}
+	if *addresses != expected {
+		t.Errorf("expected addresses %+v, got %+v", expected, *addresses)
+	}
+
+	if err := UnexposeControlPlane(client); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := GetControlPlaneAddresses(client); err == nil {
+		t.Error("expected an error after the load balancers are deleted")
+	}
+}
+
+func TestAddRemoteCluster(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "remote", Namespace: Namespace}})
+
+	if err := AddRemoteCluster(client, "remote", []byte("kubeconfig")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret, err := client.CoreV1().Secrets(Namespace).Get("istio-remote-remote", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if string(secret.Data["remote"]) != "kubeconfig" {
+		t.Errorf("unexpected remote cluster secret data %v", secret.Data)
+	}
+
+	registered, err := IsRemoteClusterRegistered(client, "remote")
+	if err != nil || !registered {
+		t.Fatalf("expected the remote cluster to be registered, got %t: %v", registered, err)
+	}
+
+	if err := RemoveRemoteCluster(client, "remote"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// an unrelated secret with the name of the cluster is left untouched
+	if _, err := client.CoreV1().Secrets(Namespace).Get("remote", metav1.GetOptions{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	registered, err = IsRemoteClusterRegistered(client, "remote")
+	if err != nil || registered {
+		t.Errorf("expected the remote cluster to be deregistered, got %t: %v", registered, err)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"encoding/json"
+
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// AllApps selects the logs of every pod
+	AllApps = "*"
+
+	managedByLabel      = "logging.banzaicloud.io/managed-by"
+	managedByLabelValue = "pipeline"
+
+	pluginAPIVersion = "logging.banzaicloud.com/v1alpha1"
+	pluginKind       = "Plugin"
+)
+
+// nolint: gochecknoglobals
+var pluginResource = schema.GroupVersionResource{Group: "logging.banzaicloud.com", Version: "v1alpha1", Resource: "plugins"}
+
+// Flow ships the logs of the pods with a given app label to an output
+type Flow struct {
+	Name string `json:"name"`
+	// AppLabel is the value of the app label of the pods, every pod is selected by AllApps
+	AppLabel string `json:"appLabel"`
+	Output   Output `json:"output"`
+}
+
+// Output is a fluentd output plugin rendered by the logging operator
+type Output struct {
+	Type       string      `json:"type"`
+	Parameters []Parameter `json:"parameters"`
+}
+
+// Parameter is a parameter of an output plugin with either a plain value or a value sourced from a Kubernetes secret
+type Parameter struct {
+	Name      string     `json:"name"`
+	Value     string     `json:"value,omitempty"`
+	ValueFrom *ValueFrom `json:"valueFrom,omitempty"`
+}
+
+// ValueFrom sources a parameter value from a Kubernetes secret
+type ValueFrom struct {
+	SecretKeyRef SecretKeyRef `json:"secretKeyRef"`
+}
+
+// SecretKeyRef selects a key of a Kubernetes secret
+type SecretKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// NewParameter returns a parameter with a plain value
+func NewParameter(name string, value string) Parameter {
+	return Parameter{Name: name, Value: value}
+}
+
+// NewSecretParameter returns a parameter sourced from a key of a Kubernetes secret
+func NewSecretParameter(name string, secretName string, key string) Parameter {
+	return Parameter{
+		Name: name,
+		ValueFrom: &ValueFrom{
+			SecretKeyRef: SecretKeyRef{Name: secretName, Key: key},
+		},
+	}
+}
+
+type pluginSpec struct {
+	Input  pluginInput    `json:"input"`
+	Output []pluginOutput `json:"output"`
+}
+
+type pluginInput struct {
+	Label map[string]string `json:"label"`
+}
+
+type pluginOutput struct {
+	Type       string      `json:"type"`
+	Name       string      `json:"name"`
+	Parameters []Parameter `json:"parameters"`
+}
+
+func newPluginSpec(flow Flow) pluginSpec {
+	appLabel := flow.AppLabel
+	if appLabel == "" {
+		appLabel = AllApps
+	}
+
+	return pluginSpec{
+		Input: pluginInput{
+			Label: map[string]string{"app": appLabel},
+		},
+		Output: []pluginOutput{
+			{
+				Type:       flow.Output.Type,
+				Name:       flow.Name,
+				Parameters: flow.Output.Parameters,
+			},
+		},
+	}
+}
+
+func newFlow(name string, spec pluginSpec) Flow {
+	flow := Flow{
+		Name:     name,
+		AppLabel: spec.Input.Label["app"],
+	}
+
+	if len(spec.Output) > 0 {
+		flow.Output = Output{
+			Type:       spec.Output[0].Type,
+			Parameters: spec.Output[0].Parameters,
+		}
+	}
+
+	return flow
+}
+
+// ApplyFlow creates or updates the logging operator Plugin of a flow
+func ApplyFlow(client dynamic.Interface, namespace string, flow Flow) error {
+	var spec map[string]interface{}
+	err := convert(newPluginSpec(flow), &spec)
+	if err != nil {
+		return err
+	}
+
+	resourceClient := client.Resource(pluginResource).Namespace(namespace)
+
+	obj, err := resourceClient.Get(flow.Name, metav1.GetOptions{})
+	if k8sapierrors.IsNotFound(err) {
+		obj = &unstructured.Unstructured{}
+		obj.SetAPIVersion(pluginAPIVersion)
+		obj.SetKind(pluginKind)
+		obj.SetNamespace(namespace)
+		obj.SetName(flow.Name)
+		obj.SetLabels(map[string]string{managedByLabel: managedByLabelValue})
+		obj.Object["spec"] = spec
+
+		_, err = resourceClient.Create(obj)
+		return emperror.WrapWith(err, "failed to create logging plugin", "name", flow.Name)
+	} else if err != nil {
+		return emperror.WrapWith(err, "failed to get logging plugin", "name", flow.Name)
+	}
+
+	if obj.GetLabels()[managedByLabel] != managedByLabelValue {
+		return errors.Errorf("logging plugin %s/%s is not managed by pipeline", namespace, flow.Name)
+	}
+
+	obj.Object["spec"] = spec
+
+	_, err = resourceClient.Update(obj)
+	return emperror.WrapWith(err, "failed to update logging plugin", "name", flow.Name)
+}
+
+// ListFlows returns the flows managed by pipeline in a namespace
+func ListFlows(client dynamic.Interface, namespace string) ([]Flow, error) {
+	list, err := client.Resource(pluginResource).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: labels.Set{managedByLabel: managedByLabelValue}.String(),
+	})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list logging plugins")
+	}
+
+	flows := make([]Flow, 0, len(list.Items))
+	for _, obj := range list.Items {
+		var spec pluginSpec
+		err := convert(obj.Object["spec"], &spec)
+		if err != nil {
+			return nil, emperror.WrapWith(err, "failed to parse logging plugin", "name", obj.GetName())
+		}
+
+		flows = append(flows, newFlow(obj.GetName(), spec))
+	}
+
+	return flows, nil
+}
+
+// DeleteFlow deletes the logging operator Plugin of a flow managed by pipeline
+func DeleteFlow(client dynamic.Interface, namespace string, name string) error {
+	resourceClient := client.Resource(pluginResource).Namespace(namespace)
+
+	obj, err := resourceClient.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return emperror.WrapWith(err, "failed to get logging plugin", "name", name)
+	}
+
+	if obj.GetLabels()[managedByLabel] != managedByLabelValue {
+		return errors.Errorf("logging plugin %s/%s is not managed by pipeline", namespace, name)
+	}
+
+	err = resourceClient.Delete(name, &metav1.DeleteOptions{})
+	return emperror.WrapWith(err, "failed to delete logging plugin", "name", name)
+}
+
+func convert(in interface{}, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return emperror.Wrap(err, "failed to marshal spec")
+	}
+
+	return emperror.Wrap(json.Unmarshal(b, out), "failed to unmarshal spec")
+}
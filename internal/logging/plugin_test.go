@@ -0,0 +1,101 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestApplyFlow(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	flow := Flow{
+		Name: "es",
+		Output: Output{
+			Type: "elasticsearch",
+			Parameters: []Parameter{
+				NewSecretParameter("host", "my-es", "host"),
+				NewParameter("index_name", "logs"),
+			},
+		},
+	}
+	if err := ApplyFlow(client, "pipeline-system", flow); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	flow.AppLabel = "frontend"
+	flow.Output.Parameters[1].Value = "frontend"
+	if err := ApplyFlow(client, "pipeline-system", flow); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	obj, err := client.Resource(pluginResource).Namespace("pipeline-system").Get("es", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if obj.GetLabels()[managedByLabel] != managedByLabelValue {
+		t.Error("plugin should be labelled as managed by pipeline")
+	}
+
+	var spec pluginSpec
+	if err := convert(obj.Object["spec"], &spec); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if actual := newFlow("es", spec); !reflect.DeepEqual(actual, flow) {
+		t.Errorf("expected %+v, got %+v", flow, actual)
+	}
+
+	if err := DeleteFlow(client, "pipeline-system", "es"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := DeleteFlow(client, "pipeline-system", "es"); err == nil {
+		t.Error("expected error for a deleted flow")
+	}
+}
+
+func TestNewPluginSpec_AllApps(t *testing.T) {
+	spec := newPluginSpec(Flow{Name: "all", Output: Output{Type: "loki"}})
+
+	if app := spec.Input.Label["app"]; app != AllApps {
+		t.Errorf("expected every app to be selected, got %q", app)
+	}
+}
+
+func TestApplyFlow_NotManaged(t *testing.T) {
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion(pluginAPIVersion)
+	existing.SetKind(pluginKind)
+	existing.SetNamespace("pipeline-system")
+	existing.SetName("s3-output")
+
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme(), existing)
+
+	if err := ApplyFlow(client, "pipeline-system", Flow{Name: "s3-output", Output: Output{Type: "s3"}}); err == nil {
+		t.Error("expected error for a plugin not managed by pipeline")
+	}
+
+	if err := DeleteFlow(client, "pipeline-system", "s3-output"); err == nil {
+		t.Error("expected error for a plugin not managed by pipeline")
+	}
+}
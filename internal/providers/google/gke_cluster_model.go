@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/model"
 	"github.com/jinzhu/gorm"
 )
 
@@ -42,6 +43,12 @@ type GKEClusterModel struct {
 	ProjectId     string
 	Vpc           string `gorm:"size:64"`
 	Subnet        string `gorm:"size:64"`
+
+	APIServerAccess model.APIServerAccessModel `gorm:"embedded;embedded_prefix:api_server_"`
+	MasterIPv4CIDR  string                     `gorm:"column:master_ipv4_cidr;size:18"`
+
+	// Imported clusters were not created by Pipeline, their node pools are not labelled at creation
+	Imported bool
 }
 
 // TableName changes the default table name.
@@ -39,6 +39,8 @@ func (bucketNotFoundError) NotFound() bool { return true }
 
 type oracleObjectStore interface {
 	commonObjectstore.ObjectStore
+
+	GetNamespace() string
 }
 
 // ObjectStore stores all required parameters for container creation
@@ -330,3 +332,13 @@ func (o *ObjectStore) newBucketSearchCriteria(bucketName string) *ObjectStoreBuc
 		Location:      o.location,
 	}
 }
+
+// GetNamespace returns the object storage namespace of the tenancy the given secret belongs to
+func GetNamespace(secret *secret.SecretItemResponse, region string) (string, error) {
+	ostore, err := getProviderObjectStore(secret, region)
+	if err != nil {
+		return "", emperror.Wrap(err, "could not create Oracle object storage client")
+	}
+
+	return ostore.GetNamespace(), nil
+}
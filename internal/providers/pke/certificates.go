@@ -0,0 +1,203 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CertificateExpiryWarningPeriod is the period before the expiry of a cluster certificate
+// when the cluster status turns into a warning.
+const CertificateExpiryWarningPeriod = 30 * 24 * time.Hour
+
+// Certificate rotation types
+const (
+	// CertificateRotationTypeLeaf renews the apiserver, kubelet and etcd certificates signed by the current CAs
+	CertificateRotationTypeLeaf = "leaf"
+	// CertificateRotationTypeCA replaces the cluster CAs and renews all certificates signed by them
+	CertificateRotationTypeCA = "ca"
+)
+
+// Certificate rotation statuses
+const (
+	CertificateRotationStatusRunning   = "RUNNING"
+	CertificateRotationStatusSucceeded = "SUCCEEDED"
+	CertificateRotationStatusFailed    = "FAILED"
+)
+
+// Certificate rotation phases
+const (
+	// CertificateRotationPhaseGenerate generates the next CAs of the cluster
+	CertificateRotationPhaseGenerate = "generate"
+	// CertificateRotationPhaseTrust makes the nodes trust both the current and the next CAs
+	CertificateRotationPhaseTrust = "trust"
+	// CertificateRotationPhaseRenew renews the leaf certificates of the nodes
+	CertificateRotationPhaseRenew = "renew"
+	// CertificateRotationPhaseFinalize promotes the next CAs and drops the trust of the previous ones
+	CertificateRotationPhaseFinalize = "finalize"
+)
+
+// CertificateRotation is the record of a certificate rotation of a cluster.
+type CertificateRotation struct {
+	ID            uint           `json:"id" gorm:"primary_key"`
+	ClusterID     uint           `json:"clusterId" gorm:"index"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	CreatedBy     uint           `json:"createdBy"`
+	Type          string         `json:"type"`
+	Phase         string         `json:"phase,omitempty"`
+	Status        string         `json:"status"`
+	StatusMessage string         `json:"statusMessage,omitempty" gorm:"type:text"`
+	Commands      []AgentCommand `json:"commands,omitempty" gorm:"foreignkey:RotationID"`
+}
+
+// TableName changes the default table name.
+func (CertificateRotation) TableName() string {
+	return "topology_certificate_rotations"
+}
+
+// CertificateRotationTypeError is returned when an unknown certificate rotation type is requested.
+type CertificateRotationTypeError struct {
+	Type string
+}
+
+func (e *CertificateRotationTypeError) Error() string {
+	return fmt.Sprintf("unknown certificate rotation type %q", e.Type)
+}
+
+// IsInvalid returns true as the error is caused by an invalid rotation request.
+func (e *CertificateRotationTypeError) IsInvalid() bool {
+	return true
+}
+
+// Agent command statuses
+const (
+	AgentCommandStatusPending   = "PENDING"
+	AgentCommandStatusRunning   = "RUNNING"
+	AgentCommandStatusSucceeded = "SUCCEEDED"
+	AgentCommandStatusFailed    = "FAILED"
+)
+
+// AgentCommand is a command to be executed by the PKE agent of a node.
+// Agents poll the pending commands of their node, and report the result of each command.
+type AgentCommand struct {
+	ID         uint       `json:"id" gorm:"primary_key"`
+	ClusterID  uint       `json:"-" gorm:"index"`
+	RotationID uint       `json:"-" gorm:"index"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	Node       string     `json:"node"`
+	Command    string     `json:"command" gorm:"type:text"`
+	Status     string     `json:"status"`
+	Output     string     `json:"output,omitempty" gorm:"type:text"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// TableName changes the default table name.
+func (AgentCommand) TableName() string {
+	return "topology_agent_commands"
+}
+
+// Finished returns true if the agent reported the result of the command.
+func (c AgentCommand) Finished() bool {
+	return c.Status == AgentCommandStatusSucceeded || c.Status == AgentCommandStatusFailed
+}
+
+// AgentCommandRenewMasterCertificates is the command renewing the certificates of a master node,
+// its result is reported with the renewed admin kubeconfig.
+const AgentCommandRenewMasterCertificates = "pke certificates renew master"
+
+// RenewsMasterCertificates returns true if the command renews the certificates of a master node.
+func (c AgentCommand) RenewsMasterCertificates() bool {
+	return c.Command == AgentCommandRenewMasterCertificates || strings.HasPrefix(c.Command, AgentCommandRenewMasterCertificates+" ")
+}
+
+// CertificateExpiry is the expiry of a cluster certificate.
+type CertificateExpiry struct {
+	Name     string    `json:"name"`
+	NotAfter time.Time `json:"notAfter"`
+}
+
+// Expiring returns true if the certificate expires within the warning period.
+func (e CertificateExpiry) Expiring(now time.Time) bool {
+	return e.NotAfter.Before(now.Add(CertificateExpiryWarningPeriod))
+}
+
+// ParseCertificateExpiry returns the earliest expiry of the certificates of a PEM bundle.
+func ParseCertificateExpiry(name string, data string) (CertificateExpiry, error) {
+	expiry := CertificateExpiry{Name: name}
+
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return expiry, errors.Wrapf(err, "failed to parse %s certificate", name)
+		}
+
+		if expiry.NotAfter.IsZero() || cert.NotAfter.Before(expiry.NotAfter) {
+			expiry.NotAfter = cert.NotAfter
+		}
+	}
+
+	if expiry.NotAfter.IsZero() {
+		return expiry, errors.Errorf("no certificate found in %s", name)
+	}
+
+	return expiry, nil
+}
+
+// ParseCertificateExpiries returns the expiry of named PEM certificate bundles, earliest first.
+// Empty bundles are skipped.
+func ParseCertificateExpiries(certificates map[string]string) ([]CertificateExpiry, error) {
+	var expiries []CertificateExpiry
+	for name, data := range certificates {
+		if data == "" {
+			continue
+		}
+
+		expiry, err := ParseCertificateExpiry(name, data)
+		if err != nil {
+			return nil, err
+		}
+
+		expiries = append(expiries, expiry)
+	}
+
+	sort.Slice(expiries, func(i, j int) bool {
+		if expiries[i].NotAfter.Equal(expiries[j].NotAfter) {
+			return expiries[i].Name < expiries[j].Name
+		}
+		return expiries[i].NotAfter.Before(expiries[j].NotAfter)
+	})
+
+	return expiries, nil
+}
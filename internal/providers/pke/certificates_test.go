@@ -0,0 +1,122 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func generateCertificate(t *testing.T, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             notAfter.Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestParseCertificateExpiry(t *testing.T) {
+	now := time.Now().Truncate(time.Second).UTC()
+	intermediate := generateCertificate(t, now.Add(24*time.Hour))
+	root := generateCertificate(t, now.Add(48*time.Hour))
+
+	expiry, err := ParseCertificateExpiry("bundle", intermediate+"\n"+root)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !expiry.NotAfter.Equal(now.Add(24 * time.Hour)) {
+		t.Errorf("expected the earliest expiry %s, got %s", now.Add(24*time.Hour), expiry.NotAfter)
+	}
+
+	if !expiry.Expiring(now) {
+		t.Error("expected the certificate to be expiring")
+	}
+
+	if _, err := ParseCertificateExpiry("empty", "not a certificate"); err == nil {
+		t.Error("expected an error for data without certificates")
+	}
+}
+
+func TestParseCertificateExpiries(t *testing.T) {
+	now := time.Now().Truncate(time.Second).UTC()
+
+	certificates := map[string]string{
+		"kubernetes-ca":        generateCertificate(t, now.Add(3*365*24*time.Hour)),
+		"etcd-ca":              generateCertificate(t, now.Add(24*time.Hour)),
+		"front-proxy-ca":       generateCertificate(t, now.Add(2*365*24*time.Hour)),
+		"kubernetes-ca (next)": "",
+	}
+
+	expiries, err := ParseCertificateExpiries(certificates)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"etcd-ca", "front-proxy-ca", "kubernetes-ca"}
+
+	if len(expiries) != len(expected) {
+		t.Fatalf("expected %d expiries, got %d", len(expected), len(expiries))
+	}
+
+	for i, name := range expected {
+		if expiries[i].Name != name {
+			t.Errorf("expected %q at position %d, got %q", name, i, expiries[i].Name)
+		}
+	}
+
+	if !expiries[0].Expiring(now) || expiries[1].Expiring(now) {
+		t.Error("only the etcd CA is expected to be expiring")
+	}
+}
+
+func TestAgentCommand_RenewsMasterCertificates(t *testing.T) {
+	tests := map[string]bool{
+		"pke certificates renew master":            true,
+		"pke certificates renew master --ca=next":  true,
+		"pke certificates renew worker":            false,
+		"pke certificates renew masters":           false,
+		"pke certificates trust --next":            false,
+		"pke certificates renew master; rm -rf /x": false,
+	}
+
+	for command, expected := range tests {
+		if actual := (AgentCommand{Command: command}).RenewsMasterCertificates(); actual != expected {
+			t.Errorf("command %q: expected %t, got %t", command, expected, actual)
+		}
+	}
+}
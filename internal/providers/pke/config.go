@@ -37,5 +37,10 @@ var _ sql.Scanner = (*Config)(nil)
 
 // Scan implements the sql.Scanner interface
 func (n *Config) Scan(src interface{}) error {
+	if src == nil {
+		*n = nil
+		return nil
+	}
+
 	return json.Unmarshal([]byte(string(src.([]uint8))), &n)
 }
@@ -42,6 +42,10 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 		Network{},
 		NodePool{},
 		Host{},
+		KubernetesUpgrade{},
+		KubernetesUpgradeNode{},
+		CertificateRotation{},
+		AgentCommand{},
 	}
 
 	var tableNames string
@@ -27,6 +27,7 @@ type Network struct {
 	ServiceCIDR         string               `yaml:"serviceCIDR" gorm:"column:service_cidr"`
 	PodCIDR             string               `yaml:"podCIDR" gorm:"column:pod_cidr"`
 	Provider            NetworkProvider      `yaml:"provider"`
+	ProviderConfig      Config               `yaml:"providerConfig" gorm:"column:provider_config;type:text"`
 	APIServerAddress    string               `yaml:"apiServerAddress"`
 	CloudProvider       CloudNetworkProvider `yaml:"cloudProvider" gorm:"column:cloud_provider"`
 	CloudProviderConfig Config               `yaml:"cloudProviderConfig" gorm:"column:cloud_provider_config;type:text"`
@@ -55,7 +56,9 @@ func (n Network) String() string {
 type NetworkProvider string
 
 const (
-	NPWeave NetworkProvider = "weave" // Weave network provider.
+	NPWeave  NetworkProvider = "weave"  // Weave network provider.
+	NPCalico NetworkProvider = "calico" // Calico network provider.
+	NPCilium NetworkProvider = "cilium" // Cilium network provider.
 )
 
 var _ driver.Valuer = (*NetworkProvider)(nil)
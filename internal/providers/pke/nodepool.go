@@ -63,6 +63,7 @@ type NodePoolProvider string
 
 const (
 	NPPAmazon NodePoolProvider = "amazon"
+	NPPHosts  NodePoolProvider = "hosts"
 )
 
 var _ driver.Valuer = (*NodePoolProvider)(nil)
@@ -83,6 +84,17 @@ func (n *NodePoolProvider) Scan(src interface{}) error {
 type Roles []Role
 type Role string
 
+// Contains returns true if the role is in the list
+func (n Roles) Contains(role Role) bool {
+	for _, r := range n {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
 const (
 	RoleMaster         Role = "master"
 	RoleWorker         Role = "worker"
@@ -136,6 +148,9 @@ type Host struct {
 	Roles            Roles  `yaml:"roles" gorm:"type:varchar(255)"`
 	Labels           Labels `yaml:"labels" gorm:"type:varchar(255)"`
 	Taints           Taints `yaml:"taint" gorm:"type:varchar(255)"`
+
+	// LastCheckIn is the last time the host reported to be ready
+	LastCheckIn *time.Time `yaml:"lastCheckIn"`
 }
 
 // TableName changes the default table name.
@@ -0,0 +1,75 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const GenerateNextCAsActivityName = "pke-generate-next-cas-activity"
+const PromoteNextCAsActivityName = "pke-promote-next-cas-activity"
+
+// CertificateAuthorities manages the CAs of clusters during CA rotations.
+type CertificateAuthorities interface {
+	// GenerateNext generates the next CAs of a cluster next to the current ones.
+	GenerateNext(ctx context.Context, clusterID uint, generation string) error
+
+	// PromoteNext replaces the current CAs of a cluster with the next ones.
+	PromoteNext(ctx context.Context, clusterID uint) error
+}
+
+type GenerateNextCAsActivity struct {
+	cas CertificateAuthorities
+}
+
+func NewGenerateNextCAsActivity(cas CertificateAuthorities) *GenerateNextCAsActivity {
+	return &GenerateNextCAsActivity{
+		cas: cas,
+	}
+}
+
+type GenerateNextCAsActivityInput struct {
+	ClusterID  uint
+	RotationID uint
+}
+
+func (a *GenerateNextCAsActivity) Execute(ctx context.Context, input GenerateNextCAsActivityInput) error {
+	err := a.cas.GenerateNext(ctx, input.ClusterID, fmt.Sprint(input.RotationID))
+
+	return errors.Wrap(err, "failed to generate next CAs")
+}
+
+type PromoteNextCAsActivity struct {
+	cas CertificateAuthorities
+}
+
+func NewPromoteNextCAsActivity(cas CertificateAuthorities) *PromoteNextCAsActivity {
+	return &PromoteNextCAsActivity{
+		cas: cas,
+	}
+}
+
+type PromoteNextCAsActivityInput struct {
+	ClusterID uint
+}
+
+func (a *PromoteNextCAsActivity) Execute(ctx context.Context, input PromoteNextCAsActivityInput) error {
+	err := a.cas.PromoteNext(ctx, input.ClusterID)
+
+	return errors.Wrap(err, "failed to promote next CAs")
+}
@@ -0,0 +1,48 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+)
+
+const UpdateCertificateRotationActivityName = "pke-update-certificate-rotation-activity"
+
+// CertificateRotations stores the progress of certificate rotations.
+type CertificateRotations interface {
+	// Update updates the phase and the status of a rotation. Empty values are left unchanged.
+	Update(ctx context.Context, rotationID uint, phase, status, message string) error
+}
+
+type UpdateCertificateRotationActivity struct {
+	rotations CertificateRotations
+}
+
+func NewUpdateCertificateRotationActivity(rotations CertificateRotations) *UpdateCertificateRotationActivity {
+	return &UpdateCertificateRotationActivity{
+		rotations: rotations,
+	}
+}
+
+type UpdateCertificateRotationActivityInput struct {
+	RotationID uint
+	Phase      string
+	Status     string
+	Message    string
+}
+
+func (a *UpdateCertificateRotationActivity) Execute(ctx context.Context, input UpdateCertificateRotationActivityInput) error {
+	return a.rotations.Update(ctx, input.RotationID, input.Phase, input.Status, input.Message)
+}
@@ -39,12 +39,14 @@ type Cluster interface {
 type AWSCluster interface {
 	GetAWSClient() (*session.Session, error)
 	GetBootstrapCommand(string, string, string) (string, error)
+	GetJoinControlPlaneCommand(string, string, string) (string, error)
 	SaveNetworkCloudProvider(string, string, []string) error
 	SaveNetworkApiServerAddress(string, string) error
 }
 
 type NodePool struct {
 	Name              string
+	Provider          string
 	MinCount          int
 	MaxCount          int
 	Count             int
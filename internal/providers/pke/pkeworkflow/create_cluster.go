@@ -120,16 +120,51 @@ func CreateClusterWorkflow(ctx workflow.Context, input CreateClusterWorkflowInpu
 		}
 	}
 
+	var nodePools []NodePool
+
+	// List node pools
+	{
+		activityInput := ListNodePoolsActivityInput{ClusterID: input.ClusterID}
+		err := workflow.ExecuteActivity(ctx, ListNodePoolsActivityName, activityInput).Get(ctx, &nodePools)
+		if err != nil {
+			return err
+		}
+	}
+
+	var masterAvailabilityZone string
+	var master NodePool
+	for _, np := range nodePools {
+		if np.Master {
+			master = np
+			if len(np.AvailabilityZones) <= 0 || np.AvailabilityZones[0] == "" {
+				return errors.Errorf("missing availability zone for nodepool %q", np.Name)
+			}
+
+			masterAvailabilityZone = np.AvailabilityZones[0]
+
+			break
+		}
+	}
+
+	// masters of a highly available control plane are spread across availability zones
+	masterCount := 1
+	masterAvailabilityZones := []string{masterAvailabilityZone}
+	if master.Count > 1 {
+		masterCount = master.Count
+		masterAvailabilityZones = uniqueStrings(master.AvailabilityZones)
+	}
+
 	var vpcStackID string
 
 	// Create VPC
 	{
 		activityInput := CreateVPCActivityInput{
-			AWSActivityInput: awsActivityInput,
-			ClusterID:        input.ClusterID,
-			ClusterName:      input.ClusterName,
-			VPCID:            input.VPCID,
-			SubnetID:         input.SubnetID,
+			AWSActivityInput:  awsActivityInput,
+			ClusterID:         input.ClusterID,
+			ClusterName:       input.ClusterName,
+			VPCID:             input.VPCID,
+			SubnetID:          input.SubnetID,
+			AvailabilityZones: masterAvailabilityZones,
 		}
 		err := workflow.ExecuteActivity(ctx, CreateVPCActivityName, activityInput).Get(ctx, &vpcStackID)
 		if err != nil {
@@ -153,22 +188,75 @@ func CreateClusterWorkflow(ctx workflow.Context, input CreateClusterWorkflowInpu
 		}
 	}
 
+	subnetIDs := strings.Split(vpcOutput["SubnetIds"], ",")
+	masterSubnetIDs := subnetIDs
+
+	// an existing subnet covers a single availability zone, the masters need one in each of their zones
+	if input.VPCID != "" && input.SubnetID != "" && len(masterAvailabilityZones) > 1 {
+		activityInput := SelectVPCSubnetsActivityInput{
+			AWSActivityInput:  awsActivityInput,
+			VPCID:             input.VPCID,
+			SubnetIDs:         subnetIDs,
+			AvailabilityZones: masterAvailabilityZones,
+		}
+
+		err := workflow.ExecuteActivity(ctx, SelectVPCSubnetsActivityName, activityInput).Get(ctx, &masterSubnetIDs)
+		if err != nil {
+			return err
+		}
+	}
+
 	var eip CreateElasticIPActivityOutput
+	var nlbOutput map[string]string
+	var apiServerAddress string
+
+	if masterCount > 1 {
+		var nlbStackID string
+
+		// Create NLB
+		{
+			activityInput := CreateNLBActivityInput{
+				AWSActivityInput: awsActivityInput,
+				ClusterID:        input.ClusterID,
+				ClusterName:      input.ClusterName,
+				SubnetIDs:        masterSubnetIDs,
+			}
+			err := workflow.ExecuteActivity(ctx, CreateNLBActivityName, activityInput).Get(ctx, &nlbStackID)
+			if err != nil {
+				return err
+			}
+		}
 
-	// Create EIP
-	{
+		// Wait for NLB
+		{
+			if nlbStackID == "" {
+				return errors.New("missing NLB stack ID")
+			}
+
+			activityInput := WaitCFCompletionActivityInput{AWSActivityInput: awsActivityInput, StackID: nlbStackID}
+			err := workflow.ExecuteActivity(ctx, WaitCFCompletionActivityName, activityInput).Get(ctx, &nlbOutput)
+			if err != nil {
+				return err
+			}
+		}
+
+		apiServerAddress = nlbOutput["DNSName"]
+	} else {
+		// Create EIP
 		activityInput := &CreateElasticIPActivityInput{AWSActivityInput: awsActivityInput, ClusterID: input.ClusterID, ClusterName: input.ClusterName}
 		err := workflow.ExecuteActivity(ctx, CreateElasticIPActivityName, activityInput).Get(ctx, &eip)
 		if err != nil {
 			return err
 		}
+
+		apiServerAddress = eip.PublicIp
 	}
 
 	// Update cluster network
 	{
 		activityInput := &UpdateClusterNetworkActivityInput{
 			ClusterID:       input.ClusterID,
-			APISeverAddress: eip.PublicIp,
+			APISeverAddress: apiServerAddress,
 			VPCID:           vpcOutput["VpcId"],
 			Subnets:         vpcOutput["SubnetIds"],
 		}
@@ -178,17 +266,6 @@ func CreateClusterWorkflow(ctx workflow.Context, input CreateClusterWorkflowInpu
 		}
 	}
 
-	var nodePools []NodePool
-
-	// List node pools
-	{
-		activityInput := ListNodePoolsActivityInput{ClusterID: input.ClusterID}
-		err := workflow.ExecuteActivity(ctx, ListNodePoolsActivityName, activityInput).Get(ctx, &nodePools)
-		if err != nil {
-			return err
-		}
-	}
-
 	var keyOut UploadSSHKeyPairActivityOutput
 
 	// Upload SSH key pair
@@ -213,21 +290,6 @@ func CreateClusterWorkflow(ctx workflow.Context, input CreateClusterWorkflowInpu
 		}
 	}
 
-	var masterAvailabilityZone string
-	var master NodePool
-	for _, np := range nodePools {
-		if np.Master {
-			master = np
-			if len(np.AvailabilityZones) <= 0 || np.AvailabilityZones[0] == "" {
-				return errors.Errorf("missing availability zone for nodepool %q", np.Name)
-			}
-
-			masterAvailabilityZone = np.AvailabilityZones[0]
-
-			break
-		}
-	}
-
 	var masterStackID string
 
 	// Create master
@@ -237,13 +299,20 @@ func CreateClusterWorkflow(ctx workflow.Context, input CreateClusterWorkflowInpu
 			ClusterID:             input.ClusterID,
 			AvailabilityZone:      masterAvailabilityZone,
 			VPCID:                 vpcOutput["VpcId"],
-			SubnetID:              strings.Split(vpcOutput["SubnetIds"], ",")[0],
+			SubnetID:              masterSubnetIDs[0],
 			EIPAllocationID:       eip.AllocationId,
 			MasterInstanceProfile: rolesOutput["MasterInstanceProfile"],
 			ExternalBaseUrl:       input.PipelineExternalURL,
 			Pool:                  master,
 			SSHKeyName:            keyOut.KeyName,
+			LoadBalancerArn:       nlbOutput["LoadBalancerArn"],
+		}
+
+		// additional masters are distributed among the subnets of the availability zones
+		for i := 1; i < masterCount; i++ {
+			activityInput.JoinSubnetIDs = append(activityInput.JoinSubnetIDs, masterSubnetIDs[i%len(masterSubnetIDs)])
 		}
+
 		err := workflow.ExecuteActivity(ctx, CreateMasterActivityName, activityInput).Get(ctx, &masterStackID)
 		if err != nil {
 			return err
@@ -258,8 +327,15 @@ func CreateClusterWorkflow(ctx workflow.Context, input CreateClusterWorkflowInpu
 			return errors.New("missing VPC stack ID")
 		}
 
+		waitCtx := ctx
+		if masterCount > 1 {
+			// additional masters join the control plane only after the first one is ready
+			waitCtx = workflow.WithStartToCloseTimeout(waitCtx, 25*time.Minute)
+			waitCtx = workflow.WithScheduleToCloseTimeout(waitCtx, 30*time.Minute)
+		}
+
 		activityInput := WaitCFCompletionActivityInput{AWSActivityInput: awsActivityInput, StackID: masterStackID}
-		err := workflow.ExecuteActivity(ctx, WaitCFCompletionActivityName, activityInput).Get(ctx, &masterOutput)
+		err := workflow.ExecuteActivity(waitCtx, WaitCFCompletionActivityName, activityInput).Get(ctx, &masterOutput)
 		if err != nil {
 			return err
 		}
@@ -293,7 +369,7 @@ func CreateClusterWorkflow(ctx workflow.Context, input CreateClusterWorkflowInpu
 					Pool:                  np,
 					WorkerInstanceProfile: rolesOutput["WorkerInstanceProfile"],
 					VPCID:                 vpcOutput["VpcId"],
-					SubnetID:              strings.Split(vpcOutput["SubnetIds"], ",")[0],
+					SubnetID:              subnetIDs[0],
 					ClusterSecurityGroup:  masterOutput["ClusterSecurityGroup"],
 					ExternalBaseUrl:       input.PipelineExternalURL,
 					SSHKeyName:            keyOut.KeyName,
@@ -309,3 +385,17 @@ func CreateClusterWorkflow(ctx workflow.Context, input CreateClusterWorkflowInpu
 
 	return nil
 }
+
+func uniqueStrings(values []string) []string {
+	var unique []string
+	seen := make(map[string]bool)
+
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	return unique
+}
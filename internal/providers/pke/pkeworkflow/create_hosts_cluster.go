@@ -0,0 +1,91 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"time"
+
+	"go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+)
+
+const CreateHostsClusterWorkflowName = "pke-create-hosts-cluster"
+
+// CreateHostsClusterWorkflow prepares a cluster installed on pre-provisioned hosts.
+// No cloud resources are created: the hosts are installed by the users with their bootstrap commands,
+// and the workflow finishes when the first master host reports to be ready.
+func CreateHostsClusterWorkflow(ctx workflow.Context, input CreateClusterWorkflowInput) error {
+	ao := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 5 * time.Minute,
+		StartToCloseTimeout:    10 * time.Minute,
+		ScheduleToCloseTimeout: 15 * time.Minute,
+		WaitForCancellation:    true,
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	// Generate CA certificates
+	{
+		activityInput := GenerateCertificatesActivityInput{ClusterID: input.ClusterID}
+
+		err := workflow.ExecuteActivity(ctx, GenerateCertificatesActivityName, activityInput).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create dex client for the cluster
+	if input.DexEnabled {
+		activityInput := CreateDexClientActivityInput{
+			ClusterID: input.ClusterID,
+		}
+		err := workflow.ExecuteActivity(ctx, CreateDexClientActivityName, activityInput).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	var nodePools []NodePool
+
+	// List node pools
+	{
+		activityInput := ListNodePoolsActivityInput{ClusterID: input.ClusterID}
+		err := workflow.ExecuteActivity(ctx, ListNodePoolsActivityName, activityInput).Get(ctx, &nodePools)
+		if err != nil {
+			return err
+		}
+	}
+
+	signalName := "master-ready"
+	signalChan := workflow.GetSignalChannel(ctx, signalName)
+
+	s := workflow.NewSelector(ctx)
+	s.AddReceive(signalChan, func(c workflow.Channel, more bool) {
+		c.Receive(ctx, nil)
+		workflow.GetLogger(ctx).Info("Received signal!", zap.String("signal", signalName))
+	})
+	s.Select(ctx)
+
+	if len(nodePools) == 1 {
+		err := workflow.ExecuteActivity(ctx, SetMasterTaintActivityName, SetMasterTaintActivityInput{
+			ClusterID: input.ClusterID,
+		}).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -51,6 +52,11 @@ type CreateMasterActivityInput struct {
 	ExternalBaseUrl       string
 	Pool                  NodePool
 	SSHKeyName            string
+
+	// JoinSubnetIDs are the subnets of the additional masters of a highly available control plane
+	JoinSubnetIDs []string
+	// LoadBalancerArn is the network load balancer in front of the API servers of a highly available control plane
+	LoadBalancerArn string
 }
 
 func (a *CreateMasterActivity) Execute(ctx context.Context, input CreateMasterActivityInput) (string, error) {
@@ -149,6 +155,35 @@ func (a *CreateMasterActivity) Execute(ctx context.Context, input CreateMasterAc
 		},
 	}
 
+	if masterCount := len(input.JoinSubnetIDs) + 1; masterCount > 1 {
+		joinCommand, err := awsCluster.GetJoinControlPlaneCommand(input.Pool.Name, input.ExternalBaseUrl, signedToken)
+		if err != nil {
+			return "", emperror.Wrap(err, "failed to fetch join command")
+		}
+
+		stackInput.Parameters = append(stackInput.Parameters,
+			&cloudformation.Parameter{
+				ParameterKey:   aws.String("MasterCount"),
+				ParameterValue: aws.String(strconv.Itoa(masterCount)),
+			},
+			&cloudformation.Parameter{
+				ParameterKey:   aws.String("JoinPkeCommand"),
+				ParameterValue: &joinCommand,
+			},
+			&cloudformation.Parameter{
+				ParameterKey:   aws.String("LoadBalancerArn"),
+				ParameterValue: &input.LoadBalancerArn,
+			},
+		)
+
+		for i, subnetID := range input.JoinSubnetIDs {
+			stackInput.Parameters = append(stackInput.Parameters, &cloudformation.Parameter{
+				ParameterKey:   aws.String(fmt.Sprintf("Master%dSubnetId", i+2)),
+				ParameterValue: aws.String(subnetID),
+			})
+		}
+	}
+
 	output, err := cfClient.CreateStack(stackInput)
 	if err, ok := err.(awserr.Error); ok {
 		switch err.Code() {
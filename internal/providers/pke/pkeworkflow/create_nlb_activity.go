@@ -0,0 +1,93 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/goph/emperror"
+	"go.uber.org/cadence/activity"
+)
+
+const CreateNLBActivityName = "pke-create-nlb-activity"
+
+// CreateNLBActivity creates the network load balancer in front of the API servers of a highly available control plane.
+type CreateNLBActivity struct {
+	awsClientFactory *AWSClientFactory
+}
+
+func NewCreateNLBActivity(awsClientFactory *AWSClientFactory) *CreateNLBActivity {
+	return &CreateNLBActivity{
+		awsClientFactory: awsClientFactory,
+	}
+}
+
+type CreateNLBActivityInput struct {
+	AWSActivityInput
+	ClusterID   uint
+	ClusterName string
+	SubnetIDs   []string
+}
+
+func (a *CreateNLBActivity) Execute(ctx context.Context, input CreateNLBActivityInput) (string, error) {
+	log := activity.GetLogger(ctx).Sugar().With("clusterID", input.ClusterID)
+
+	client, err := a.awsClientFactory.New(input.OrganizationID, input.SecretID, input.Region)
+	if err != nil {
+		return "", err
+	}
+
+	cfClient := cloudformation.New(client)
+
+	buf, err := ioutil.ReadFile("templates/pke/nlb.cf.yaml")
+	if err != nil {
+		return "", emperror.Wrap(err, "loading CF template")
+	}
+
+	stackName := "pke-nlb-" + input.ClusterName
+	stackInput := &cloudformation.CreateStackInput{
+		StackName:    &stackName,
+		TemplateBody: aws.String(string(buf)),
+		Parameters: []*cloudformation.Parameter{
+			{
+				ParameterKey:   aws.String("ClusterName"),
+				ParameterValue: aws.String(input.ClusterName),
+			},
+			{
+				ParameterKey:   aws.String("SubnetIds"),
+				ParameterValue: aws.String(strings.Join(input.SubnetIDs, ",")),
+			},
+		},
+	}
+
+	output, err := cfClient.CreateStack(stackInput)
+	if err, ok := err.(awserr.Error); ok {
+		switch err.Code() {
+		case cloudformation.ErrCodeAlreadyExistsException:
+			log.Infof("stack already exists: %s", err.Message())
+			return stackName, nil
+
+		default:
+			return "", err
+		}
+	}
+
+	return *output.StackId, nil
+}
@@ -16,6 +16,7 @@ package pkeworkflow
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -43,6 +44,9 @@ type CreateVPCActivityInput struct {
 	ClusterName string
 	VPCID       string
 	SubnetID    string
+
+	// AvailabilityZones of the subnets created for the cluster, at most three
+	AvailabilityZones []string
 }
 
 func (a *CreateVPCActivity) Execute(ctx context.Context, input CreateVPCActivityInput) (string, error) {
@@ -81,6 +85,17 @@ func (a *CreateVPCActivity) Execute(ctx context.Context, input CreateVPCActivity
 		},
 	}
 
+	for i, zone := range input.AvailabilityZones {
+		if i >= 3 {
+			break
+		}
+
+		stackInput.Parameters = append(stackInput.Parameters, &cloudformation.Parameter{
+			ParameterKey:   aws.String(fmt.Sprintf("Subnet%02dAvailabilityZone", i+1)),
+			ParameterValue: aws.String(zone),
+		})
+	}
+
 	output, err := cfClient.CreateStack(stackInput)
 	if err, ok := err.(awserr.Error); ok {
 		switch err.Code() {
@@ -17,6 +17,7 @@ package pkeworkflow
 import (
 	"time"
 
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
 	"go.uber.org/cadence/workflow"
 )
 
@@ -44,6 +45,19 @@ func DeleteClusterWorkflow(ctx workflow.Context, input DeleteClusterWorkflowInpu
 		return err
 	}
 
+	// pre-provisioned hosts are left intact, only the cloud resources are removed
+	var cloudNodePools []NodePool
+	for _, np := range nodePools {
+		if np.Provider != string(internalPke.NPPHosts) {
+			cloudNodePools = append(cloudNodePools, np)
+		}
+	}
+
+	if len(cloudNodePools) == 0 {
+		return deleteDexClient(ctx, input.ClusterID)
+	}
+	nodePools = cloudNodePools
+
 	var poolActivities []workflow.Future
 
 	for _, np := range nodePools {
@@ -84,6 +98,15 @@ func DeleteClusterWorkflow(ctx workflow.Context, input DeleteClusterWorkflowInpu
 		}
 	}
 
+	// remove the API server load balancer of highly available control planes
+
+	deleteNLBActivityInput := &DeleteNLBActivityInput{
+		ClusterID: input.ClusterID,
+	}
+	if err := workflow.ExecuteActivity(ctx, DeleteNLBActivityName, deleteNLBActivityInput).Get(ctx, nil); err != nil {
+		return err
+	}
+
 	// clean-up ssh key
 	deleteSSHKeyPairActivityInput := &DeleteSSHKeyPairActivityInput{
 		ClusterID: input.ClusterID,
@@ -112,10 +135,7 @@ func DeleteClusterWorkflow(ctx workflow.Context, input DeleteClusterWorkflowInpu
 
 	// remove dex client (if we created it)
 
-	deleteDexClientActivityInput := &DeleteDexClientActivityInput{
-		ClusterID: input.ClusterID,
-	}
-	if err := workflow.ExecuteActivity(ctx, DeleteDexClientActivityName, deleteDexClientActivityInput).Get(ctx, nil); err != nil {
+	if err := deleteDexClient(ctx, input.ClusterID); err != nil {
 		return err
 	}
 
@@ -123,3 +143,11 @@ func DeleteClusterWorkflow(ctx workflow.Context, input DeleteClusterWorkflowInpu
 
 	return nil
 }
+
+func deleteDexClient(ctx workflow.Context, clusterID uint) error {
+	deleteDexClientActivityInput := &DeleteDexClientActivityInput{
+		ClusterID: clusterID,
+	}
+
+	return workflow.ExecuteActivity(ctx, DeleteDexClientActivityName, deleteDexClientActivityInput).Get(ctx, nil)
+}
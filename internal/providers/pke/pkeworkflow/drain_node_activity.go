@@ -0,0 +1,87 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/banzaicloud/pipeline/pkg/k8sutil"
+	"go.uber.org/cadence/activity"
+)
+
+const DrainNodeActivityName = "pke-drain-node-activity"
+const UncordonNodeActivityName = "pke-uncordon-node-activity"
+
+const drainPollInterval = 5 * time.Second
+
+// DrainNodeActivity cordons a node and evicts the pods running on it.
+type DrainNodeActivity struct {
+	clusters Clusters
+}
+
+func NewDrainNodeActivity(clusters Clusters) *DrainNodeActivity {
+	return &DrainNodeActivity{
+		clusters: clusters,
+	}
+}
+
+type DrainNodeActivityInput struct {
+	ClusterID uint
+	Node      string
+}
+
+func (a *DrainNodeActivity) Execute(ctx context.Context, input DrainNodeActivityInput) error {
+	logger := activity.GetLogger(ctx).Sugar().With("clusterID", input.ClusterID, "node", input.Node)
+
+	client, err := newKubernetesClient(ctx, a.clusters, input.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	// pod disruption budgets may refuse evictions for a while, those are retried until the activity times out
+	if err := k8sutil.DrainNode(ctx, client, input.Node, drainPollInterval); err != nil {
+		return err
+	}
+
+	logger.Info("node drained")
+
+	return nil
+}
+
+// UncordonNodeActivity marks a node schedulable again.
+type UncordonNodeActivity struct {
+	clusters Clusters
+}
+
+func NewUncordonNodeActivity(clusters Clusters) *UncordonNodeActivity {
+	return &UncordonNodeActivity{
+		clusters: clusters,
+	}
+}
+
+type UncordonNodeActivityInput struct {
+	ClusterID uint
+	Node      string
+}
+
+func (a *UncordonNodeActivity) Execute(ctx context.Context, input UncordonNodeActivityInput) error {
+	client, err := newKubernetesClient(ctx, a.clusters, input.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	return k8sutil.SetNodeUnschedulable(client, input.Node, false)
+}
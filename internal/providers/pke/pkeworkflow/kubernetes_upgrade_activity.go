@@ -0,0 +1,72 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+)
+
+const UpdateUpgradeNodeActivityName = "pke-update-upgrade-node-activity"
+const UpdateUpgradeStatusActivityName = "pke-update-upgrade-status-activity"
+
+// KubernetesUpgrades stores the progress of Kubernetes version upgrades.
+type KubernetesUpgrades interface {
+	// UpdateNode creates or updates the progress of a node of an upgrade.
+	UpdateNode(ctx context.Context, upgradeID uint, node Node, status, message string) error
+
+	// UpdateStatus updates the overall status of an upgrade.
+	UpdateStatus(ctx context.Context, upgradeID uint, status, message string) error
+}
+
+type UpdateUpgradeNodeActivity struct {
+	upgrades KubernetesUpgrades
+}
+
+func NewUpdateUpgradeNodeActivity(upgrades KubernetesUpgrades) *UpdateUpgradeNodeActivity {
+	return &UpdateUpgradeNodeActivity{
+		upgrades: upgrades,
+	}
+}
+
+type UpdateUpgradeNodeActivityInput struct {
+	UpgradeID uint
+	Node      Node
+	Status    string
+	Message   string
+}
+
+func (a *UpdateUpgradeNodeActivity) Execute(ctx context.Context, input UpdateUpgradeNodeActivityInput) error {
+	return a.upgrades.UpdateNode(ctx, input.UpgradeID, input.Node, input.Status, input.Message)
+}
+
+type UpdateUpgradeStatusActivity struct {
+	upgrades KubernetesUpgrades
+}
+
+func NewUpdateUpgradeStatusActivity(upgrades KubernetesUpgrades) *UpdateUpgradeStatusActivity {
+	return &UpdateUpgradeStatusActivity{
+		upgrades: upgrades,
+	}
+}
+
+type UpdateUpgradeStatusActivityInput struct {
+	UpgradeID uint
+	Status    string
+	Message   string
+}
+
+func (a *UpdateUpgradeStatusActivity) Execute(ctx context.Context, input UpdateUpgradeStatusActivityInput) error {
+	return a.upgrades.UpdateStatus(ctx, input.UpgradeID, input.Status, input.Message)
+}
@@ -0,0 +1,106 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"sort"
+
+	"github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/goph/emperror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const ListNodesActivityName = "pke-list-nodes-activity"
+
+const masterWorkerKey = "node-role.kubernetes.io/master-worker"
+
+// Node is a Kubernetes node of a cluster.
+type Node struct {
+	Name           string
+	NodePool       string
+	Master         bool
+	ProviderID     string
+	KubeletVersion string
+}
+
+type ListNodesActivity struct {
+	clusters Clusters
+}
+
+func NewListNodesActivity(clusters Clusters) *ListNodesActivity {
+	return &ListNodesActivity{
+		clusters: clusters,
+	}
+}
+
+type ListNodesActivityInput struct {
+	ClusterID uint
+}
+
+// Execute returns the nodes of the cluster, masters first.
+func (a *ListNodesActivity) Execute(ctx context.Context, input ListNodesActivityInput) ([]Node, error) {
+	client, err := newKubernetesClient(ctx, a.clusters, input.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeList, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list nodes")
+	}
+
+	nodes := make([]Node, 0, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		_, master := node.Labels[masterKey]
+		_, masterWorker := node.Labels[masterWorkerKey]
+
+		nodes = append(nodes, Node{
+			Name:           node.Name,
+			NodePool:       node.Labels[common.LabelKey],
+			Master:         master || masterWorker,
+			ProviderID:     node.Spec.ProviderID,
+			KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+		})
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Master != nodes[j].Master {
+			return nodes[i].Master
+		}
+		if nodes[i].NodePool != nodes[j].NodePool {
+			return nodes[i].NodePool < nodes[j].NodePool
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	return nodes, nil
+}
+
+func newKubernetesClient(ctx context.Context, clusters Clusters, clusterID uint) (*kubernetes.Clientset, error) {
+	cluster, err := clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return k8sclient.NewClientFromKubeConfig(kubeConfig)
+}
@@ -0,0 +1,136 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflowadapter
+
+import (
+	"context"
+	"fmt"
+
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// CertificateRotationStore stores the progress of certificate rotations in the database.
+type CertificateRotationStore struct {
+	db *gorm.DB
+}
+
+// NewCertificateRotationStore returns a new CertificateRotationStore.
+func NewCertificateRotationStore(db *gorm.DB) *CertificateRotationStore {
+	return &CertificateRotationStore{
+		db: db,
+	}
+}
+
+func (s *CertificateRotationStore) Update(ctx context.Context, rotationID uint, phase, status, message string) error {
+	fields := map[string]interface{}{}
+	if phase != "" {
+		fields["phase"] = phase
+	}
+	if status != "" {
+		fields["status"] = status
+		fields["status_message"] = message
+	}
+
+	err := s.db.Model(&internalPke.CertificateRotation{ID: rotationID}).Updates(fields).Error
+
+	return errors.Wrap(err, "failed to update certificate rotation")
+}
+
+// AgentCommandStore stores the commands of the PKE agents in the database.
+type AgentCommandStore struct {
+	db *gorm.DB
+}
+
+// NewAgentCommandStore returns a new AgentCommandStore.
+func NewAgentCommandStore(db *gorm.DB) *AgentCommandStore {
+	return &AgentCommandStore{
+		db: db,
+	}
+}
+
+func (s *AgentCommandStore) Create(ctx context.Context, clusterID uint, rotationID uint, node string, command string) (uint, error) {
+	model := internalPke.AgentCommand{
+		ClusterID:  clusterID,
+		RotationID: rotationID,
+		Node:       node,
+		Command:    command,
+		Status:     internalPke.AgentCommandStatusPending,
+	}
+
+	err := s.db.Create(&model).Error
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to create agent command for node %q", node)
+	}
+
+	return model.ID, nil
+}
+
+func (s *AgentCommandStore) Get(ctx context.Context, commandID uint) (internalPke.AgentCommand, error) {
+	var model internalPke.AgentCommand
+
+	err := s.db.Where(&internalPke.AgentCommand{ID: commandID}).First(&model).Error
+
+	return model, errors.Wrapf(err, "failed to get agent command %d", commandID)
+}
+
+// InternalPKESecretStore is an interface for the CA rotation methods of the internal secret store.
+type InternalPKESecretStore interface {
+	// GenerateNextPKECAs generates a new set of CAs for a PKE secret under a new PKI generation.
+	GenerateNextPKECAs(organizationID uint, secretID string, generation string) error
+
+	// PromoteNextPKECAs replaces the CAs of a PKE secret with the next ones.
+	PromoteNextPKECAs(organizationID uint, secretID string) error
+}
+
+// CertificateAuthorityStore manages the CAs stored in the PKE secrets of clusters.
+type CertificateAuthorityStore struct {
+	clusters pkeworkflow.Clusters
+	secrets  InternalPKESecretStore
+}
+
+// NewCertificateAuthorityStore returns a new CertificateAuthorityStore.
+func NewCertificateAuthorityStore(clusters pkeworkflow.Clusters, secrets InternalPKESecretStore) *CertificateAuthorityStore {
+	return &CertificateAuthorityStore{
+		clusters: clusters,
+		secrets:  secrets,
+	}
+}
+
+func (s *CertificateAuthorityStore) GenerateNext(ctx context.Context, clusterID uint, generation string) error {
+	cluster, err := s.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return s.secrets.GenerateNextPKECAs(cluster.GetOrganizationId(), caSecretID(clusterID), generation)
+}
+
+func (s *CertificateAuthorityStore) PromoteNext(ctx context.Context, clusterID uint) error {
+	cluster, err := s.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return s.secrets.PromoteNextPKECAs(cluster.GetOrganizationId(), caSecretID(clusterID))
+}
+
+// caSecretID returns the ID of the secret created by GenerateCertificatesActivity.
+func caSecretID(clusterID uint) string {
+	return secret.GenerateSecretIDFromName(fmt.Sprintf("cluster-%d-ca", clusterID))
+}
@@ -69,6 +69,7 @@ func (c *Cluster) GetNodePools() []pkeworkflow.NodePool {
 	for i, np := range clusterNodePools {
 		nodePools[i] = pkeworkflow.NodePool{
 			Name:              np.Name,
+			Provider:          np.Provider,
 			MinCount:          np.MinCount,
 			MaxCount:          np.MaxCount,
 			Count:             np.Count,
@@ -98,6 +99,13 @@ func (c *Cluster) GetBootstrapCommand(nodePoolName, url, token string) (string,
 	return "", errors.New(fmt.Sprintf("failed to cast cluster to AWSCluster, got type: %T", c.CommonCluster))
 }
 
+func (c *Cluster) GetJoinControlPlaneCommand(nodePoolName, url, token string) (string, error) {
+	if awscluster, ok := c.CommonCluster.(pkeworkflow.AWSCluster); ok {
+		return awscluster.GetJoinControlPlaneCommand(nodePoolName, url, token)
+	}
+	return "", errors.New(fmt.Sprintf("failed to cast cluster to AWSCluster, got type: %T", c.CommonCluster))
+}
+
 func (c *Cluster) SaveNetworkCloudProvider(cloudProvider, vpcID string, subnets []string) error {
 	if awscluster, ok := c.CommonCluster.(pkeworkflow.AWSCluster); ok {
 		return awscluster.SaveNetworkCloudProvider(cloudProvider, vpcID, subnets)
@@ -0,0 +1,72 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflowadapter
+
+import (
+	"context"
+	"time"
+
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// KubernetesUpgradeStore stores the progress of Kubernetes version upgrades in the database.
+type KubernetesUpgradeStore struct {
+	db *gorm.DB
+}
+
+// NewKubernetesUpgradeStore returns a new KubernetesUpgradeStore.
+func NewKubernetesUpgradeStore(db *gorm.DB) *KubernetesUpgradeStore {
+	return &KubernetesUpgradeStore{
+		db: db,
+	}
+}
+
+func (s *KubernetesUpgradeStore) UpdateNode(ctx context.Context, upgradeID uint, node pkeworkflow.Node, status, message string) error {
+	var model internalPke.KubernetesUpgradeNode
+
+	err := s.db.
+		Where(internalPke.KubernetesUpgradeNode{UpgradeID: upgradeID, Name: node.Name}).
+		Attrs(internalPke.KubernetesUpgradeNode{NodePool: node.NodePool, Master: node.Master}).
+		FirstOrCreate(&model).Error
+	if err != nil {
+		return errors.Wrapf(err, "failed to find upgrade node %q", node.Name)
+	}
+
+	now := time.Now()
+
+	model.Status = status
+	model.Message = message
+
+	if status != internalPke.UpgradeNodeStatusPending && model.StartedAt == nil {
+		model.StartedAt = &now
+	}
+
+	if status == internalPke.UpgradeNodeStatusSucceeded || status == internalPke.UpgradeNodeStatusFailed {
+		model.FinishedAt = &now
+	}
+
+	return errors.Wrapf(s.db.Save(&model).Error, "failed to save upgrade node %q", node.Name)
+}
+
+func (s *KubernetesUpgradeStore) UpdateStatus(ctx context.Context, upgradeID uint, status, message string) error {
+	err := s.db.
+		Model(&internalPke.KubernetesUpgrade{ID: upgradeID}).
+		Updates(map[string]interface{}{"status": status, "status_message": message}).Error
+
+	return errors.Wrap(err, "failed to update upgrade status")
+}
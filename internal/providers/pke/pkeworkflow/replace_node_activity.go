@@ -0,0 +1,127 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/pkg/k8sutil"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"go.uber.org/cadence/activity"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const ReplaceNodeActivityName = "pke-replace-node-activity"
+
+// ReplaceNodeActivity terminates the instance of a drained worker node,
+// and waits for the auto scaling group to launch a replacement running the new Kubernetes version.
+type ReplaceNodeActivity struct {
+	awsClientFactory *AWSClientFactory
+	clusters         Clusters
+}
+
+func NewReplaceNodeActivity(awsClientFactory *AWSClientFactory, clusters Clusters) *ReplaceNodeActivity {
+	return &ReplaceNodeActivity{
+		awsClientFactory: awsClientFactory,
+		clusters:         clusters,
+	}
+}
+
+type ReplaceNodeActivityInput struct {
+	AWSActivityInput
+	ClusterID uint
+	Node      Node
+	Version   string
+}
+
+func (a *ReplaceNodeActivity) Execute(ctx context.Context, input ReplaceNodeActivityInput) error {
+	logger := activity.GetLogger(ctx).Sugar().With("clusterID", input.ClusterID, "node", input.Node.Name)
+
+	// the provider ID of EC2 nodes looks like aws:///eu-west-1a/i-0123456789abcdef0
+	instanceID := input.Node.ProviderID[strings.LastIndex(input.Node.ProviderID, "/")+1:]
+	if !strings.HasPrefix(instanceID, "i-") {
+		return errors.Errorf("cannot find instance ID of node %q", input.Node.Name)
+	}
+
+	client, err := newKubernetesClient(ctx, a.clusters, input.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{common.LabelKey: input.Node.NodePool}).String()
+
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return emperror.Wrapf(err, "failed to list nodes of pool %q", input.Node.NodePool)
+	}
+
+	existing := make(map[string]bool, len(nodes.Items))
+	for _, node := range nodes.Items {
+		existing[node.Name] = true
+	}
+
+	session, err := a.awsClientFactory.New(input.OrganizationID, input.SecretID, input.Region)
+	if err != nil {
+		return err
+	}
+
+	_, err = autoscaling.New(session).TerminateInstanceInAutoScalingGroup(&autoscaling.TerminateInstanceInAutoScalingGroupInput{
+		InstanceId:                     aws.String(instanceID),
+		ShouldDecrementDesiredCapacity: aws.Bool(false),
+	})
+	if err != nil {
+		return emperror.Wrapf(err, "failed to terminate instance %s", instanceID)
+	}
+
+	logger.Infof("instance %s terminated", instanceID)
+
+	err = client.CoreV1().Nodes().Delete(input.Node.Name, &metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return emperror.Wrapf(err, "failed to delete node %q", input.Node.Name)
+	}
+
+	for {
+		nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return emperror.Wrapf(err, "failed to list nodes of pool %q", input.Node.NodePool)
+		}
+
+		for _, node := range nodes.Items {
+			if existing[node.Name] {
+				continue
+			}
+
+			if node.Status.NodeInfo.KubeletVersion == "v"+strings.TrimPrefix(input.Version, "v") && k8sutil.IsNodeReady(&node) {
+				logger.Infof("node replaced by %s", node.Name)
+
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return emperror.Wrapf(ctx.Err(), "waiting for the replacement of node %q", input.Node.Name)
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
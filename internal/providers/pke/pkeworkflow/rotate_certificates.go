@@ -0,0 +1,201 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"time"
+
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
+	"github.com/pkg/errors"
+	"go.uber.org/cadence/workflow"
+)
+
+const RotateCertificatesWorkflowName = "pke-rotate-certificates"
+
+// PKE agent commands of certificate rotations
+const (
+	renewCertificatesCommand = "pke certificates renew"
+	trustCertificatesCommand = "pke certificates trust"
+)
+
+type RotateCertificatesWorkflowInput struct {
+	ClusterID  uint
+	RotationID uint
+	Type       string
+}
+
+// RotateCertificatesWorkflow rotates the certificates of a cluster through the PKE agents of the nodes.
+//
+// A leaf rotation renews the apiserver, kubelet and etcd certificates of the nodes, masters first.
+//
+// A CA rotation generates the next CAs of the cluster, and makes every node trust both the current
+// and the next CAs. Once all nodes trust both, the leaf certificates are renewed with the next CAs.
+// Finally the next CAs replace the current ones, and the nodes drop the trust of the previous CAs.
+// Master nodes report their renewed admin kubeconfig with the results of their renew commands.
+func RotateCertificatesWorkflow(ctx workflow.Context, input RotateCertificatesWorkflowInput) error {
+	ao := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 5 * time.Minute,
+		StartToCloseTimeout:    10 * time.Minute,
+		ScheduleToCloseTimeout: 15 * time.Minute,
+		WaitForCancellation:    true,
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	statusInput := UpdateCertificateRotationActivityInput{
+		RotationID: input.RotationID,
+		Status:     internalPke.CertificateRotationStatusSucceeded,
+	}
+
+	rotateErr := rotateCertificates(ctx, input)
+	if rotateErr != nil {
+		statusInput.Status = internalPke.CertificateRotationStatusFailed
+		statusInput.Message = rotateErr.Error()
+	}
+
+	err := workflow.ExecuteActivity(ctx, UpdateCertificateRotationActivityName, statusInput).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Sugar().Errorf("failed to update certificate rotation status: %s", err)
+	}
+
+	return rotateErr
+}
+
+func rotateCertificates(ctx workflow.Context, input RotateCertificatesWorkflowInput) error {
+	var nodes []Node
+
+	// List nodes
+	{
+		activityInput := ListNodesActivityInput{ClusterID: input.ClusterID}
+		err := workflow.ExecuteActivity(ctx, ListNodesActivityName, activityInput).Get(ctx, &nodes)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch input.Type {
+	case internalPke.CertificateRotationTypeLeaf:
+		err := updateCertificateRotationPhase(ctx, input.RotationID, internalPke.CertificateRotationPhaseRenew)
+		if err != nil {
+			return err
+		}
+
+		return runAgentCommands(ctx, input, nodes, renewCertificatesCommand, "")
+
+	case internalPke.CertificateRotationTypeCA:
+		// Generate the next CAs
+		{
+			err := updateCertificateRotationPhase(ctx, input.RotationID, internalPke.CertificateRotationPhaseGenerate)
+			if err != nil {
+				return err
+			}
+
+			activityInput := GenerateNextCAsActivityInput{ClusterID: input.ClusterID, RotationID: input.RotationID}
+			err = workflow.ExecuteActivity(ctx, GenerateNextCAsActivityName, activityInput).Get(ctx, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Trust both the current and the next CAs
+		{
+			err := updateCertificateRotationPhase(ctx, input.RotationID, internalPke.CertificateRotationPhaseTrust)
+			if err != nil {
+				return err
+			}
+
+			err = runAgentCommands(ctx, input, nodes, trustCertificatesCommand, "--next")
+			if err != nil {
+				return err
+			}
+		}
+
+		// Renew the leaf certificates with the next CAs
+		{
+			err := updateCertificateRotationPhase(ctx, input.RotationID, internalPke.CertificateRotationPhaseRenew)
+			if err != nil {
+				return err
+			}
+
+			err = runAgentCommands(ctx, input, nodes, renewCertificatesCommand, "--ca=next")
+			if err != nil {
+				return err
+			}
+		}
+
+		// Promote the next CAs, and drop the trust of the previous ones
+		{
+			err := updateCertificateRotationPhase(ctx, input.RotationID, internalPke.CertificateRotationPhaseFinalize)
+			if err != nil {
+				return err
+			}
+
+			activityInput := PromoteNextCAsActivityInput{ClusterID: input.ClusterID}
+			err = workflow.ExecuteActivity(ctx, PromoteNextCAsActivityName, activityInput).Get(ctx, nil)
+			if err != nil {
+				return err
+			}
+
+			return runAgentCommands(ctx, input, nodes, trustCertificatesCommand, "")
+		}
+
+	default:
+		return errors.Errorf("unknown certificate rotation type %q", input.Type)
+	}
+}
+
+// runAgentCommands runs a command on the nodes one by one, masters first.
+// The role of the node is passed to renew commands.
+func runAgentCommands(ctx workflow.Context, input RotateCertificatesWorkflowInput, nodes []Node, command string, flags string) error {
+	// agent commands wait for the nodes, so they get more time
+	commandCtx := workflow.WithStartToCloseTimeout(ctx, 25*time.Minute)
+	commandCtx = workflow.WithScheduleToCloseTimeout(commandCtx, 30*time.Minute)
+
+	for _, node := range nodes {
+		nodeCommand := command
+		if command == renewCertificatesCommand {
+			if node.Master {
+				nodeCommand += " master"
+			} else {
+				nodeCommand += " worker"
+			}
+		}
+		if flags != "" {
+			nodeCommand += " " + flags
+		}
+
+		activityInput := RunAgentCommandActivityInput{
+			ClusterID:  input.ClusterID,
+			RotationID: input.RotationID,
+			Node:       node.Name,
+			Command:    nodeCommand,
+		}
+		err := workflow.ExecuteActivity(commandCtx, RunAgentCommandActivityName, activityInput).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateCertificateRotationPhase(ctx workflow.Context, rotationID uint, phase string) error {
+	activityInput := UpdateCertificateRotationActivityInput{
+		RotationID: rotationID,
+		Phase:      phase,
+	}
+
+	return workflow.ExecuteActivity(ctx, UpdateCertificateRotationActivityName, activityInput).Get(ctx, nil)
+}
@@ -0,0 +1,89 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"go.uber.org/cadence/activity"
+)
+
+const RunAgentCommandActivityName = "pke-run-agent-command-activity"
+
+const agentCommandPollInterval = 10 * time.Second
+
+// AgentCommands is the queue of commands executed by the PKE agents of the nodes.
+type AgentCommands interface {
+	// Create queues a new command for the agent of a node.
+	Create(ctx context.Context, clusterID uint, rotationID uint, node string, command string) (uint, error)
+
+	// Get returns a command with its current status.
+	Get(ctx context.Context, commandID uint) (internalPke.AgentCommand, error)
+}
+
+// RunAgentCommandActivity queues a command for the PKE agent of a node, and waits for its result.
+type RunAgentCommandActivity struct {
+	commands AgentCommands
+}
+
+func NewRunAgentCommandActivity(commands AgentCommands) *RunAgentCommandActivity {
+	return &RunAgentCommandActivity{
+		commands: commands,
+	}
+}
+
+type RunAgentCommandActivityInput struct {
+	ClusterID  uint
+	RotationID uint
+	Node       string
+	Command    string
+}
+
+func (a *RunAgentCommandActivity) Execute(ctx context.Context, input RunAgentCommandActivityInput) error {
+	logger := activity.GetLogger(ctx).Sugar().With("clusterID", input.ClusterID, "node", input.Node)
+
+	commandID, err := a.commands.Create(ctx, input.ClusterID, input.RotationID, input.Node, input.Command)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("agent command %d queued: %s", commandID, input.Command)
+
+	for {
+		command, err := a.commands.Get(ctx, commandID)
+		if err != nil {
+			return err
+		}
+
+		switch command.Status {
+		case internalPke.AgentCommandStatusSucceeded:
+			return nil
+
+		case internalPke.AgentCommandStatusFailed:
+			return errors.Errorf("agent command %q failed on node %q: %s", input.Command, input.Node, strings.TrimSpace(command.Output))
+		}
+
+		select {
+		case <-ctx.Done():
+			return emperror.Wrapf(ctx.Err(), "waiting for agent command %d on node %q", commandID, input.Node)
+		case <-time.After(agentCommandPollInterval):
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+const SelectVPCSubnetsActivityName = "pke-select-vpc-subnets-activity"
+
+// SelectVPCSubnetsActivity finds a subnet for each availability zone of a highly available control plane in an existing VPC.
+type SelectVPCSubnetsActivity struct {
+	awsClientFactory *AWSClientFactory
+}
+
+func NewSelectVPCSubnetsActivity(awsClientFactory *AWSClientFactory) *SelectVPCSubnetsActivity {
+	return &SelectVPCSubnetsActivity{
+		awsClientFactory: awsClientFactory,
+	}
+}
+
+type SelectVPCSubnetsActivityInput struct {
+	AWSActivityInput
+	VPCID string
+
+	// SubnetIDs are the subnets specified for the cluster, they are preferred in their availability zones
+	SubnetIDs []string
+
+	AvailabilityZones []string
+}
+
+// Execute returns one subnet ID for each availability zone, in the order of the zones.
+func (a *SelectVPCSubnetsActivity) Execute(ctx context.Context, input SelectVPCSubnetsActivityInput) ([]string, error) {
+	client, err := a.awsClientFactory.New(input.OrganizationID, input.SecretID, input.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := ec2.New(client).DescribeSubnets(&ec2.DescribeSubnetsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: aws.StringSlice([]string{input.VPCID}),
+			},
+			{
+				Name:   aws.String("availability-zone"),
+				Values: aws.StringSlice(input.AvailabilityZones),
+			},
+		},
+	})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to describe VPC subnets")
+	}
+
+	return selectZoneSubnets(output.Subnets, input.SubnetIDs, input.AvailabilityZones)
+}
+
+func selectZoneSubnets(subnets []*ec2.Subnet, preferredSubnetIDs []string, zones []string) ([]string, error) {
+	preferred := make(map[string]bool, len(preferredSubnetIDs))
+	for _, subnetID := range preferredSubnetIDs {
+		preferred[subnetID] = true
+	}
+
+	// subnets are ordered to make the selection deterministic: preferred ones first, then by ID
+	sort.Slice(subnets, func(i, j int) bool {
+		pi, pj := preferred[aws.StringValue(subnets[i].SubnetId)], preferred[aws.StringValue(subnets[j].SubnetId)]
+		if pi != pj {
+			return pi
+		}
+		return aws.StringValue(subnets[i].SubnetId) < aws.StringValue(subnets[j].SubnetId)
+	})
+
+	zoneSubnets := map[string]string{}
+	for _, subnet := range subnets {
+		zone := aws.StringValue(subnet.AvailabilityZone)
+		if _, ok := zoneSubnets[zone]; !ok {
+			zoneSubnets[zone] = aws.StringValue(subnet.SubnetId)
+		}
+	}
+
+	var subnetIDs []string
+	var missingZones []string
+
+	for _, zone := range zones {
+		subnetID, ok := zoneSubnets[zone]
+		if !ok {
+			missingZones = append(missingZones, zone)
+			continue
+		}
+
+		subnetIDs = append(subnetIDs, subnetID)
+	}
+
+	if len(missingZones) > 0 {
+		return nil, errors.Errorf("no subnet found in availability zones: %s", strings.Join(missingZones, ", "))
+	}
+
+	return subnetIDs, nil
+}
@@ -0,0 +1,55 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const UpdateKubernetesVersionActivityName = "pke-update-kubernetes-version-activity"
+
+// UpdateKubernetesVersionActivity stores the Kubernetes version of a cluster once its control plane is upgraded.
+type UpdateKubernetesVersionActivity struct {
+	clusters Clusters
+}
+
+func NewUpdateKubernetesVersionActivity(clusters Clusters) *UpdateKubernetesVersionActivity {
+	return &UpdateKubernetesVersionActivity{
+		clusters: clusters,
+	}
+}
+
+type UpdateKubernetesVersionActivityInput struct {
+	ClusterID uint
+	Version   string
+}
+
+func (a *UpdateKubernetesVersionActivity) Execute(ctx context.Context, input UpdateKubernetesVersionActivityInput) error {
+	c, err := a.clusters.GetCluster(ctx, input.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	if cluster, ok := c.(interface {
+		SetKubernetesVersion(string) error
+	}); ok {
+		return cluster.SetKubernetesVersion(input.Version)
+	}
+
+	return errors.New(fmt.Sprintf("unable to set Kubernetes version for cluster type %T", c))
+}
@@ -0,0 +1,140 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"go.uber.org/cadence/activity"
+)
+
+const UpdatePoolBootstrapActivityName = "pke-update-pool-bootstrap-activity"
+
+// UpdatePoolBootstrapActivity updates the bootstrap command of the launch configuration of a worker pool,
+// so that new instances of the pool join the cluster with its current Kubernetes version.
+type UpdatePoolBootstrapActivity struct {
+	clusters       Clusters
+	tokenGenerator TokenGenerator
+}
+
+func NewUpdatePoolBootstrapActivity(clusters Clusters, tokenGenerator TokenGenerator) *UpdatePoolBootstrapActivity {
+	return &UpdatePoolBootstrapActivity{
+		clusters:       clusters,
+		tokenGenerator: tokenGenerator,
+	}
+}
+
+type UpdatePoolBootstrapActivityInput struct {
+	ClusterID       uint
+	Pool            string
+	ExternalBaseUrl string
+}
+
+func (a *UpdatePoolBootstrapActivity) Execute(ctx context.Context, input UpdatePoolBootstrapActivityInput) error {
+	log := activity.GetLogger(ctx).Sugar().With("clusterID", input.ClusterID, "pool", input.Pool)
+
+	cluster, err := a.clusters.GetCluster(ctx, input.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	// pre-provisioned hosts are installed with the bootstrap commands of the hosts
+	for _, np := range cluster.GetNodePools() {
+		if np.Name == input.Pool && np.Provider == string(internalPke.NPPHosts) {
+			log.Info("skipping bootstrap update of pre-provisioned hosts")
+			return nil
+		}
+	}
+
+	awsCluster, ok := cluster.(AWSCluster)
+	if !ok {
+		return errors.New(fmt.Sprintf("can't get AWS client for %t", cluster))
+	}
+
+	_, signedToken, err := a.tokenGenerator.GenerateClusterToken(cluster.GetOrganizationId(), cluster.GetID())
+	if err != nil {
+		return emperror.Wrap(err, "can't generate Pipeline token")
+	}
+
+	bootstrapCommand, err := awsCluster.GetBootstrapCommand(input.Pool, input.ExternalBaseUrl, signedToken)
+	if err != nil {
+		return emperror.Wrap(err, "failed to fetch bootstrap command")
+	}
+
+	client, err := awsCluster.GetAWSClient()
+	if err != nil {
+		return emperror.Wrap(err, "failed to connect to AWS")
+	}
+
+	cfClient := cloudformation.New(client)
+
+	stackName := fmt.Sprintf("pke-pool-%s-worker-%s", cluster.GetName(), input.Pool)
+	describeStacksInput := &cloudformation.DescribeStacksInput{StackName: aws.String(stackName)}
+
+	stacks, err := cfClient.DescribeStacks(describeStacksInput)
+	if err != nil {
+		return emperror.Wrapf(err, "failed to describe stack %s", stackName)
+	}
+	if len(stacks.Stacks) == 0 {
+		return errors.Errorf("stack %s not found", stackName)
+	}
+
+	var parameters []*cloudformation.Parameter
+	for _, parameter := range stacks.Stacks[0].Parameters {
+		if aws.StringValue(parameter.ParameterKey) == "PkeCommand" {
+			parameters = append(parameters, &cloudformation.Parameter{
+				ParameterKey:   parameter.ParameterKey,
+				ParameterValue: aws.String(bootstrapCommand),
+			})
+			continue
+		}
+
+		parameters = append(parameters, &cloudformation.Parameter{
+			ParameterKey:     parameter.ParameterKey,
+			UsePreviousValue: aws.Bool(true),
+		})
+	}
+
+	_, err = cfClient.UpdateStack(&cloudformation.UpdateStackInput{
+		StackName:           aws.String(stackName),
+		UsePreviousTemplate: aws.Bool(true),
+		Parameters:          parameters,
+	})
+	if err, ok := err.(awserr.Error); ok {
+		if err.Code() == "ValidationError" && strings.Contains(err.Message(), "No updates are to be performed") {
+			log.Info("bootstrap command is up to date")
+			return nil
+		}
+
+		return err
+	}
+
+	err = cfClient.WaitUntilStackUpdateCompleteWithContext(ctx, describeStacksInput)
+	if err != nil {
+		return emperror.Wrap(err, "error waiting Cloud Formation template")
+	}
+
+	log.Info("bootstrap command updated")
+
+	return nil
+}
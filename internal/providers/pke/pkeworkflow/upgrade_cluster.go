@@ -0,0 +1,233 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"time"
+
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
+	"github.com/goph/emperror"
+	"go.uber.org/cadence/workflow"
+)
+
+const UpgradeClusterWorkflowName = "pke-upgrade-cluster"
+
+type UpgradeClusterWorkflowInput struct {
+	OrganizationID      uint
+	ClusterID           uint
+	ClusterName         string
+	SecretID            string
+	Region              string
+	PipelineExternalURL string
+	UpgradeID           uint
+	Version             string
+	Strategy            string
+}
+
+// UpgradeClusterWorkflow upgrades the Kubernetes version of a cluster.
+// The masters are upgraded one by one first, then the worker nodes of each pool are drained
+// and either upgraded in place or replaced. The upgrade stops at the first failing node.
+func UpgradeClusterWorkflow(ctx workflow.Context, input UpgradeClusterWorkflowInput) error {
+	ao := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 5 * time.Minute,
+		StartToCloseTimeout:    10 * time.Minute,
+		ScheduleToCloseTimeout: 15 * time.Minute,
+		WaitForCancellation:    true,
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	statusInput := UpdateUpgradeStatusActivityInput{
+		UpgradeID: input.UpgradeID,
+		Status:    internalPke.UpgradeStatusSucceeded,
+	}
+
+	upgradeErr := upgradeCluster(ctx, input)
+	if upgradeErr != nil {
+		statusInput.Status = internalPke.UpgradeStatusFailed
+		statusInput.Message = upgradeErr.Error()
+	}
+
+	err := workflow.ExecuteActivity(ctx, UpdateUpgradeStatusActivityName, statusInput).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Sugar().Errorf("failed to update upgrade status: %s", err)
+	}
+
+	return upgradeErr
+}
+
+func upgradeCluster(ctx workflow.Context, input UpgradeClusterWorkflowInput) error {
+	awsActivityInput := AWSActivityInput{
+		OrganizationID: input.OrganizationID,
+		SecretID:       input.SecretID,
+		Region:         input.Region,
+	}
+
+	// node operations wait for pods and instances, so they get more time
+	nodeCtx := workflow.WithStartToCloseTimeout(ctx, 25*time.Minute)
+	nodeCtx = workflow.WithScheduleToCloseTimeout(nodeCtx, 30*time.Minute)
+
+	var nodes []Node
+
+	// List nodes
+	{
+		activityInput := ListNodesActivityInput{ClusterID: input.ClusterID}
+		err := workflow.ExecuteActivity(ctx, ListNodesActivityName, activityInput).Get(ctx, &nodes)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, node := range nodes {
+		err := updateUpgradeNode(ctx, input.UpgradeID, node, internalPke.UpgradeNodeStatusPending, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Upgrade masters
+	firstMaster := true
+	for _, node := range nodes {
+		if !node.Master {
+			continue
+		}
+
+		err := updateUpgradeNode(ctx, input.UpgradeID, node, internalPke.UpgradeNodeStatusUpgrading, "")
+		if err != nil {
+			return err
+		}
+
+		activityInput := UpgradeNodeActivityInput{
+			ClusterID:              input.ClusterID,
+			Node:                   node.Name,
+			Version:                input.Version,
+			Master:                 true,
+			AdditionalControlPlane: !firstMaster,
+		}
+		err = workflow.ExecuteActivity(nodeCtx, UpgradeNodeActivityName, activityInput).Get(ctx, nil)
+		if err != nil {
+			return failUpgradeNode(ctx, input.UpgradeID, node, err)
+		}
+
+		err = updateUpgradeNode(ctx, input.UpgradeID, node, internalPke.UpgradeNodeStatusSucceeded, "")
+		if err != nil {
+			return err
+		}
+
+		firstMaster = false
+	}
+
+	// New nodes join with the version of the upgraded control plane
+	{
+		activityInput := UpdateKubernetesVersionActivityInput{ClusterID: input.ClusterID, Version: input.Version}
+		err := workflow.ExecuteActivity(ctx, UpdateKubernetesVersionActivityName, activityInput).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Roll worker pools
+	updatedPools := map[string]bool{}
+	for _, node := range nodes {
+		if node.Master {
+			continue
+		}
+
+		if node.NodePool != "" && !updatedPools[node.NodePool] {
+			activityInput := UpdatePoolBootstrapActivityInput{
+				ClusterID:       input.ClusterID,
+				Pool:            node.NodePool,
+				ExternalBaseUrl: input.PipelineExternalURL,
+			}
+			err := workflow.ExecuteActivity(nodeCtx, UpdatePoolBootstrapActivityName, activityInput).Get(ctx, nil)
+			if err != nil {
+				return emperror.Wrapf(err, "updating bootstrap command of pool %q", node.NodePool)
+			}
+
+			updatedPools[node.NodePool] = true
+		}
+
+		err := updateUpgradeNode(ctx, input.UpgradeID, node, internalPke.UpgradeNodeStatusDraining, "")
+		if err != nil {
+			return err
+		}
+
+		err = workflow.ExecuteActivity(nodeCtx, DrainNodeActivityName, DrainNodeActivityInput{
+			ClusterID: input.ClusterID,
+			Node:      node.Name,
+		}).Get(ctx, nil)
+		if err != nil {
+			return failUpgradeNode(ctx, input.UpgradeID, node, err)
+		}
+
+		err = updateUpgradeNode(ctx, input.UpgradeID, node, internalPke.UpgradeNodeStatusUpgrading, "")
+		if err != nil {
+			return err
+		}
+
+		// the node is left cordoned on failure
+		if input.Strategy == internalPke.UpgradeStrategyReplace && node.NodePool != "" {
+			err = workflow.ExecuteActivity(nodeCtx, ReplaceNodeActivityName, ReplaceNodeActivityInput{
+				AWSActivityInput: awsActivityInput,
+				ClusterID:        input.ClusterID,
+				Node:             node,
+				Version:          input.Version,
+			}).Get(ctx, nil)
+		} else {
+			err = workflow.ExecuteActivity(nodeCtx, UpgradeNodeActivityName, UpgradeNodeActivityInput{
+				ClusterID: input.ClusterID,
+				Node:      node.Name,
+				Version:   input.Version,
+			}).Get(ctx, nil)
+			if err == nil {
+				err = workflow.ExecuteActivity(ctx, UncordonNodeActivityName, UncordonNodeActivityInput{
+					ClusterID: input.ClusterID,
+					Node:      node.Name,
+				}).Get(ctx, nil)
+			}
+		}
+		if err != nil {
+			return failUpgradeNode(ctx, input.UpgradeID, node, err)
+		}
+
+		err = updateUpgradeNode(ctx, input.UpgradeID, node, internalPke.UpgradeNodeStatusSucceeded, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateUpgradeNode(ctx workflow.Context, upgradeID uint, node Node, status, message string) error {
+	activityInput := UpdateUpgradeNodeActivityInput{
+		UpgradeID: upgradeID,
+		Node:      node,
+		Status:    status,
+		Message:   message,
+	}
+
+	return workflow.ExecuteActivity(ctx, UpdateUpgradeNodeActivityName, activityInput).Get(ctx, nil)
+}
+
+func failUpgradeNode(ctx workflow.Context, upgradeID uint, node Node, err error) error {
+	err = emperror.Wrapf(err, "upgrading node %q", node.Name)
+
+	if updateErr := updateUpgradeNode(ctx, upgradeID, node, internalPke.UpgradeNodeStatusFailed, err.Error()); updateErr != nil {
+		workflow.GetLogger(ctx).Sugar().Errorf("failed to update upgrade node status: %s", updateErr)
+	}
+
+	return err
+}
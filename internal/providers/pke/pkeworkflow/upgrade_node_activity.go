@@ -0,0 +1,204 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/banzaicloud/pipeline/pkg/k8sutil"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"go.uber.org/cadence/activity"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const UpgradeNodeActivityName = "pke-upgrade-node-activity"
+
+const upgradeNamespace = "kube-system"
+
+// UpgradeNodeActivity upgrades the Kubernetes components of a node in place.
+// The PKE installer of the node is run by a privileged pod in the host namespaces.
+type UpgradeNodeActivity struct {
+	clusters Clusters
+	image    string
+}
+
+func NewUpgradeNodeActivity(clusters Clusters, image string) *UpgradeNodeActivity {
+	return &UpgradeNodeActivity{
+		clusters: clusters,
+		image:    image,
+	}
+}
+
+type UpgradeNodeActivityInput struct {
+	ClusterID uint
+	Node      string
+	Version   string
+	Master    bool
+
+	// AdditionalControlPlane is set for the masters upgraded after the first one
+	AdditionalControlPlane bool
+}
+
+func (a *UpgradeNodeActivity) Execute(ctx context.Context, input UpgradeNodeActivityInput) error {
+	logger := activity.GetLogger(ctx).Sugar().With("clusterID", input.ClusterID, "node", input.Node)
+
+	client, err := newKubernetesClient(ctx, a.clusters, input.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	command := fmt.Sprintf("/usr/local/bin/pke upgrade worker --kubernetes-version=%q", input.Version)
+	if input.Master {
+		command = fmt.Sprintf("/usr/local/bin/pke upgrade master --kubernetes-version=%q", input.Version)
+		if input.AdditionalControlPlane {
+			command += " --kubernetes-additional-control-plane"
+		}
+	}
+
+	privileged := true
+	pod, err := client.CoreV1().Pods(upgradeNamespace).Create(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "pke-upgrade-",
+			Labels: map[string]string{
+				"app": "pke-upgrade",
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      input.Node,
+			HostPID:       true,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Tolerations: []corev1.Toleration{
+				{Operator: corev1.TolerationOpExists},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    "upgrade",
+					Image:   a.image,
+					Command: []string{"nsenter", "--target", "1", "--mount", "--uts", "--ipc", "--net", "--pid", "--", "/bin/sh", "-c", command},
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: &privileged,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return emperror.Wrap(err, "failed to create upgrade pod")
+	}
+
+	logger.Infof("upgrade pod %s created", pod.Name)
+
+	defer func() {
+		err := client.CoreV1().Pods(upgradeNamespace).Delete(pod.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			logger.Warnf("failed to delete upgrade pod %s: %s", pod.Name, err)
+		}
+	}()
+
+	err = waitForPod(ctx, client, pod.Name)
+	if err != nil {
+		return err
+	}
+
+	err = waitForNodeVersion(ctx, client, input.Node, input.Version)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("node upgraded to %s", input.Version)
+
+	return nil
+}
+
+// isTransientError tells whether a Kubernetes API error is worth retrying:
+// the API server may be unreachable or overloaded while the control plane or the node itself is upgraded.
+func isTransientError(err error) bool {
+	if _, ok := err.(k8serrors.APIStatus); !ok {
+		// errors without an API status are connection errors (refused, reset, timeout, EOF)
+		return true
+	}
+
+	return k8serrors.IsServerTimeout(err) || k8serrors.IsTimeout(err) || k8serrors.IsTooManyRequests(err) ||
+		k8serrors.IsInternalError(err) || k8serrors.IsServiceUnavailable(err) || k8serrors.IsUnexpectedServerError(err)
+}
+
+// waitForPod waits until a pod completes, and returns its log tail on failure.
+// Transient API errors are retried until the context is done.
+func waitForPod(ctx context.Context, client kubernetes.Interface, name string) error {
+	for {
+		pod, err := client.CoreV1().Pods(upgradeNamespace).Get(name, metav1.GetOptions{})
+		if err != nil && !isTransientError(err) {
+			return emperror.Wrapf(err, "failed to get pod %s", name)
+		}
+
+		if err == nil {
+			switch pod.Status.Phase {
+			case corev1.PodSucceeded:
+				return nil
+
+			case corev1.PodFailed:
+				tailLines := int64(20)
+				logs, err := client.CoreV1().Pods(upgradeNamespace).GetLogs(name, &corev1.PodLogOptions{TailLines: &tailLines}).Do().Raw()
+				if err != nil {
+					return errors.Errorf("upgrade pod %s failed", name)
+				}
+
+				return errors.Errorf("upgrade pod %s failed: %s", name, strings.TrimSpace(string(logs)))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return emperror.Wrapf(err, "waiting for pod %s", name)
+			}
+			return emperror.Wrapf(ctx.Err(), "waiting for pod %s", name)
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+// waitForNodeVersion waits until a node is ready and its kubelet runs the given version.
+// Transient API errors are retried until the context is done.
+func waitForNodeVersion(ctx context.Context, client kubernetes.Interface, name string, version string) error {
+	kubeletVersion := "v" + strings.TrimPrefix(version, "v")
+
+	for {
+		node, err := client.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+		if err != nil && !isTransientError(err) {
+			return emperror.Wrapf(err, "failed to get node %q", name)
+		}
+
+		if err == nil && node.Status.NodeInfo.KubeletVersion == kubeletVersion && k8sutil.IsNodeReady(node) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return emperror.Wrapf(err, "waiting for node %q to run Kubernetes %s", name, version)
+			}
+			return emperror.Wrapf(ctx.Err(), "waiting for node %q to run Kubernetes %s", name, version)
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
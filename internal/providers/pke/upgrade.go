@@ -0,0 +1,150 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+)
+
+// Kubernetes upgrade statuses
+const (
+	UpgradeStatusRunning   = "RUNNING"
+	UpgradeStatusSucceeded = "SUCCEEDED"
+	UpgradeStatusFailed    = "FAILED"
+)
+
+// Kubernetes upgrade node statuses
+const (
+	UpgradeNodeStatusPending   = "PENDING"
+	UpgradeNodeStatusDraining  = "DRAINING"
+	UpgradeNodeStatusUpgrading = "UPGRADING"
+	UpgradeNodeStatusSucceeded = "SUCCEEDED"
+	UpgradeNodeStatusFailed    = "FAILED"
+)
+
+// Worker node upgrade strategies
+const (
+	// UpgradeStrategyInPlace upgrades the Kubernetes components on the drained worker nodes
+	UpgradeStrategyInPlace = "inPlace"
+	// UpgradeStrategyReplace replaces the drained worker nodes with new instances
+	UpgradeStrategyReplace = "replace"
+)
+
+// KubernetesUpgrade is the record of a Kubernetes version upgrade of a cluster.
+type KubernetesUpgrade struct {
+	ID            uint                    `json:"id" gorm:"primary_key"`
+	ClusterID     uint                    `json:"clusterId" gorm:"index"`
+	CreatedAt     time.Time               `json:"createdAt"`
+	UpdatedAt     time.Time               `json:"updatedAt"`
+	CreatedBy     uint                    `json:"createdBy"`
+	FromVersion   string                  `json:"fromVersion"`
+	ToVersion     string                  `json:"toVersion"`
+	Strategy      string                  `json:"strategy"`
+	Status        string                  `json:"status"`
+	StatusMessage string                  `json:"statusMessage,omitempty" gorm:"type:text"`
+	Nodes         []KubernetesUpgradeNode `json:"nodes" gorm:"foreignkey:UpgradeID"`
+}
+
+// TableName changes the default table name.
+func (KubernetesUpgrade) TableName() string {
+	return "topology_kubernetes_upgrades"
+}
+
+// Finished returns true if the upgrade is not running anymore.
+func (u KubernetesUpgrade) Finished() bool {
+	return u.Status != UpgradeStatusRunning
+}
+
+// KubernetesUpgradeNode is the progress of a single node of a Kubernetes version upgrade.
+type KubernetesUpgradeNode struct {
+	ID         uint       `json:"-" gorm:"primary_key"`
+	UpgradeID  uint       `json:"-" gorm:"index"`
+	Name       string     `json:"name"`
+	NodePool   string     `json:"nodePool"`
+	Master     bool       `json:"master"`
+	Status     string     `json:"status"`
+	Message    string     `json:"message,omitempty" gorm:"type:text"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// TableName changes the default table name.
+func (KubernetesUpgradeNode) TableName() string {
+	return "topology_kubernetes_upgrade_nodes"
+}
+
+// VersionSkewError is returned when a Kubernetes version upgrade is not supported.
+type VersionSkewError struct {
+	From string
+	To   string
+	msg  string
+}
+
+func (e *VersionSkewError) Error() string {
+	return fmt.Sprintf("cannot upgrade Kubernetes from %s to %s: %s", e.From, e.To, e.msg)
+}
+
+// IsInvalid returns true as the error is caused by an invalid upgrade request.
+func (e *VersionSkewError) IsInvalid() bool {
+	return true
+}
+
+// ValidateVersionSkew checks that a cluster can be upgraded from one Kubernetes version to the other.
+// Kubeadm supports upgrading to newer patch versions, and to the next minor version only.
+func ValidateVersionSkew(from, to string) error {
+	fromVersion, err := semver.NewVersion(from)
+	if err != nil {
+		return errors.Wrapf(err, "invalid current Kubernetes version %q", from)
+	}
+
+	toVersion, err := semver.NewVersion(to)
+	if err != nil {
+		return &VersionSkewError{From: from, To: to, msg: "invalid version"}
+	}
+
+	switch {
+	case toVersion.Prerelease() != "":
+		return &VersionSkewError{From: from, To: to, msg: "pre-release versions are not supported"}
+
+	case !toVersion.GreaterThan(fromVersion):
+		return &VersionSkewError{From: from, To: to, msg: "the new version must be greater than the current one"}
+
+	case toVersion.Major() != fromVersion.Major():
+		return &VersionSkewError{From: from, To: to, msg: "major version upgrades are not supported"}
+
+	case toVersion.Minor() > fromVersion.Minor()+1:
+		return &VersionSkewError{From: from, To: to, msg: "minor versions cannot be skipped"}
+	}
+
+	return nil
+}
+
+// UpgradeStrategyError is returned when an unknown worker node upgrade strategy is requested.
+type UpgradeStrategyError struct {
+	Strategy string
+}
+
+func (e *UpgradeStrategyError) Error() string {
+	return fmt.Sprintf("unknown upgrade strategy %q", e.Strategy)
+}
+
+// IsInvalid returns true as the error is caused by an invalid upgrade request.
+func (e *UpgradeStrategyError) IsInvalid() bool {
+	return true
+}
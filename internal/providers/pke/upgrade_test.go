@@ -0,0 +1,53 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"testing"
+)
+
+func TestValidateVersionSkew(t *testing.T) {
+	tests := []struct {
+		from  string
+		to    string
+		valid bool
+	}{
+		{from: "1.12.2", to: "1.12.5", valid: true},
+		{from: "1.12.2", to: "1.13.0", valid: true},
+		{from: "1.12.2", to: "1.14.0", valid: false},
+		{from: "1.12.2", to: "1.12.2", valid: false},
+		{from: "1.13.0", to: "1.12.5", valid: false},
+		{from: "1.12.2", to: "2.0.0", valid: false},
+		{from: "1.12.2", to: "1.13.0-beta.1", valid: false},
+		{from: "1.12.2", to: "latest", valid: false},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.from+"->"+test.to, func(t *testing.T) {
+			err := ValidateVersionSkew(test.from, test.to)
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if !test.valid {
+				if err == nil {
+					t.Error("expected an error")
+				} else if e, ok := err.(interface{ IsInvalid() bool }); !ok || !e.IsInvalid() {
+					t.Errorf("expected an invalid request error, got %T", err)
+				}
+			}
+		})
+	}
+}
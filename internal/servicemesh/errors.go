@@ -0,0 +1,51 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicemesh
+
+import (
+	"fmt"
+)
+
+type meshNotFoundError struct {
+	organizationID uint
+	meshID         uint
+}
+
+func (e *meshNotFoundError) Error() string {
+	return fmt.Sprintf("service mesh %d not found", e.meshID)
+}
+
+func (e *meshNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"mesh", e.meshID,
+		"organization", e.organizationID,
+	}
+}
+
+func (e *meshNotFoundError) NotFound() bool {
+	return true
+}
+
+type meshValidationError struct {
+	msg string
+}
+
+func (e *meshValidationError) Error() string {
+	return e.msg
+}
+
+func (e *meshValidationError) IsInvalid() bool {
+	return true
+}
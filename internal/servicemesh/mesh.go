@@ -0,0 +1,902 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicemesh
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	"github.com/banzaicloud/pipeline/helm"
+	"github.com/banzaicloud/pipeline/internal/backoff"
+	"github.com/banzaicloud/pipeline/internal/istio"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/crypto/cert"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/banzaicloud/pipeline/pkg/k8sutil"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	k8sHelm "k8s.io/helm/pkg/helm"
+	pkgHelmRelease "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// Member roles
+const (
+	RolePrimary = "primary"
+	RoleRemote  = "remote"
+)
+
+// Member statuses
+const (
+	StatusJoining = "joining"
+	StatusReady   = "ready"
+	StatusLeaving = "leaving"
+	StatusError   = "error"
+)
+
+// intermediateCAValidity is the validity of the intermediate CA of a member, it never exceeds the validity of the root CA
+const intermediateCAValidity = 10 * 365 * 24 * time.Hour
+
+type clusterGetter interface {
+	GetClusterByID(ctx context.Context, organizationID uint, clusterID uint) (cluster.CommonCluster, error)
+}
+
+type secretStore interface {
+	GetOrCreate(organizationID uint, value *secret.CreateSecretRequest) (string, error)
+	Get(organizationID uint, secretID string) (*secret.SecretItemResponse, error)
+	Delete(organizationID uint, secretID string) error
+}
+
+// CreateMeshRequest describes a multi-cluster service mesh to be created.
+type CreateMeshRequest struct {
+	Name             string `json:"name" binding:"required"`
+	PrimaryClusterID uint   `json:"primaryClusterId" binding:"required"`
+	RemoteClusterIDs []uint `json:"remoteClusterIds,omitempty"`
+}
+
+// Mesh is a multi-cluster service mesh and its member clusters.
+type Mesh struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	Healthy   *bool     `json:"healthy,omitempty"`
+	Members   []Member  `json:"members"`
+}
+
+// Member is a cluster joined to a multi-cluster service mesh.
+type Member struct {
+	ClusterID     uint    `json:"clusterId"`
+	ClusterName   string  `json:"clusterName"`
+	Role          string  `json:"role"`
+	Status        string  `json:"status"`
+	StatusMessage string  `json:"statusMessage,omitempty"`
+	Health        *Health `json:"health,omitempty"`
+}
+
+// Health is the result of checking a mesh member.
+type Health struct {
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+// Manager joins the clusters of an organization into multi-cluster Istio meshes.
+// The primary cluster runs the control plane, remote clusters run the istio-remote chart and are discovered by the
+// primary through their kubeconfig. Every member plugs in its own intermediate CA signed by the root CA of the mesh
+// stored in Vault, so mutual TLS works across clusters. Remote clusters reach the control plane through load balancers.
+type Manager struct {
+	clusters clusterGetter
+	store    *Store
+	secrets  secretStore
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewManager returns a new Manager instance.
+func NewManager(
+	clusters clusterGetter,
+	store *Store,
+	secrets secretStore,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Manager {
+	return &Manager{
+		clusters: clusters,
+		store:    store,
+		secrets:  secrets,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// ListMeshes returns the meshes of an organization.
+func (m *Manager) ListMeshes(organizationID uint) ([]Mesh, error) {
+	models, err := m.store.ListMeshes(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	meshes := make([]Mesh, 0, len(models))
+	for _, model := range models {
+		meshes = append(meshes, newMesh(model))
+	}
+
+	return meshes, nil
+}
+
+// GetTopology returns a mesh with the health of its members.
+func (m *Manager) GetTopology(ctx context.Context, organizationID uint, meshID uint) (*Mesh, error) {
+	model, err := m.store.GetMesh(organizationID, meshID)
+	if err != nil {
+		return nil, err
+	}
+
+	mesh := newMesh(*model)
+
+	var primaryClient kubernetes.Interface
+	var addresses *istio.ControlPlaneAddresses
+	for i, member := range mesh.Members {
+		if member.Role == RolePrimary {
+			primaryClient, addresses, mesh.Members[i].Health = m.checkPrimary(ctx, organizationID, member)
+		}
+	}
+
+	healthy := true
+	for i, member := range mesh.Members {
+		if member.Role == RoleRemote {
+			mesh.Members[i].Health = m.checkRemote(ctx, organizationID, member, primaryClient, addresses)
+		}
+
+		healthy = healthy && mesh.Members[i].Health.Healthy
+	}
+	mesh.Healthy = &healthy
+
+	return &mesh, nil
+}
+
+// CreateMesh creates a mesh and starts joining its clusters in the background.
+func (m *Manager) CreateMesh(ctx context.Context, organizationID uint, request CreateMeshRequest) (*Mesh, error) {
+	if errs := validation.IsDNS1123Label(request.Name); len(errs) > 0 {
+		return nil, &meshValidationError{msg: fmt.Sprintf("invalid mesh name %q: %s", request.Name, errs[0])}
+	}
+
+	meshes, err := m.store.ListMeshes(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, mesh := range meshes {
+		if mesh.Name == request.Name {
+			return nil, &meshValidationError{msg: fmt.Sprintf("mesh %q already exists", request.Name)}
+		}
+	}
+
+	model := MeshModel{
+		OrganizationID: organizationID,
+		Name:           request.Name,
+	}
+
+	primary, err := m.getJoinableCluster(ctx, organizationID, request.PrimaryClusterID, RolePrimary)
+	if err != nil {
+		return nil, err
+	}
+	model.Members = append(model.Members, newMemberModel(primary, RolePrimary))
+
+	for _, clusterID := range request.RemoteClusterIDs {
+		for _, member := range model.Members {
+			if member.ClusterID == clusterID {
+				return nil, &meshValidationError{msg: fmt.Sprintf("cluster %d is listed more than once", clusterID)}
+			}
+		}
+
+		remote, err := m.getJoinableCluster(ctx, organizationID, clusterID, RoleRemote)
+		if err != nil {
+			return nil, err
+		}
+		model.Members = append(model.Members, newMemberModel(remote, RoleRemote))
+	}
+
+	model.CASecretID, err = m.secrets.GetOrCreate(organizationID, &secret.CreateSecretRequest{
+		Name: fmt.Sprintf("istio-mesh-%s-ca", request.Name),
+		Type: pkgSecret.TLSSecretType,
+		Tags: []string{
+			pkgSecret.TagBanzaiReadonly,
+		},
+		Values: map[string]string{
+			pkgSecret.TLSHosts: "istio-ca",
+		},
+	})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to create mesh root CA")
+	}
+
+	err = m.store.CreateMesh(&model)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer emperror.HandleRecover(m.errorHandler)
+
+		m.joinMembers(context.Background(), organizationID, model)
+	}()
+
+	mesh := newMesh(model)
+
+	return &mesh, nil
+}
+
+// AddMember joins a cluster to a mesh as a remote cluster in the background.
+func (m *Manager) AddMember(ctx context.Context, organizationID uint, meshID uint, clusterID uint) (*Mesh, error) {
+	model, err := m.store.GetMesh(organizationID, meshID)
+	if err != nil {
+		return nil, err
+	}
+
+	primary := getPrimary(*model)
+	if primary == nil || primary.Status != StatusReady {
+		return nil, &meshValidationError{msg: "primary cluster has not joined the mesh yet"}
+	}
+
+	remote, err := m.getJoinableCluster(ctx, organizationID, clusterID, RoleRemote)
+	if err != nil {
+		return nil, err
+	}
+
+	member := newMemberModel(remote, RoleRemote)
+	member.MeshID = model.ID
+
+	err = m.store.AddMember(&member)
+	if err != nil {
+		return nil, err
+	}
+	model.Members = append(model.Members, member)
+
+	go func() {
+		defer emperror.HandleRecover(m.errorHandler)
+
+		m.setMemberStatus(member, m.joinRemote(context.Background(), organizationID, *model, member))
+	}()
+
+	mesh := newMesh(*model)
+
+	return &mesh, nil
+}
+
+// RemoveMember removes a remote cluster from a mesh in the background.
+func (m *Manager) RemoveMember(ctx context.Context, organizationID uint, meshID uint, clusterID uint) error {
+	model, err := m.store.GetMesh(organizationID, meshID)
+	if err != nil {
+		return err
+	}
+
+	var member *MemberModel
+	for i := range model.Members {
+		if model.Members[i].ClusterID == clusterID {
+			member = &model.Members[i]
+		}
+	}
+
+	if member == nil {
+		return &meshValidationError{msg: fmt.Sprintf("cluster %d is not a member of the mesh", clusterID)}
+	}
+
+	if member.Role == RolePrimary {
+		return &meshValidationError{msg: "the primary cluster cannot leave the mesh, delete the mesh instead"}
+	}
+
+	if member.Status == StatusJoining || member.Status == StatusLeaving {
+		return &meshValidationError{msg: fmt.Sprintf("cluster %d is %s", clusterID, member.Status)}
+	}
+
+	err = m.store.UpdateMemberStatus(member.ID, StatusLeaving, "")
+	if err != nil {
+		return err
+	}
+
+	go func(member MemberModel) {
+		defer emperror.HandleRecover(m.errorHandler)
+
+		err := m.leaveRemote(context.Background(), organizationID, *model, member)
+		if err != nil {
+			m.setMemberStatus(member, err)
+			return
+		}
+
+		err = m.store.DeleteMember(member.ID)
+		if err != nil {
+			m.errorHandler.Handle(err)
+		}
+	}(*member)
+
+	return nil
+}
+
+// DeleteMesh deletes a mesh which has no remote clusters left,
+// the primary cluster falls back to the self-signed CA of its control plane.
+func (m *Manager) DeleteMesh(ctx context.Context, organizationID uint, meshID uint) error {
+	model, err := m.store.GetMesh(organizationID, meshID)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range model.Members {
+		if member.Role == RoleRemote {
+			return &meshValidationError{msg: "remote clusters have to leave the mesh before deleting it"}
+		}
+	}
+
+	primary := getPrimary(*model)
+	if primary != nil {
+		if primary.Status == StatusJoining {
+			return &meshValidationError{msg: "primary cluster is joining the mesh"}
+		}
+
+		err = m.leavePrimary(ctx, organizationID, *model, *primary)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = m.secrets.Delete(organizationID, model.CASecretID)
+	if err != nil {
+		return emperror.Wrap(err, "failed to delete mesh root CA")
+	}
+
+	return m.store.DeleteMesh(model.ID)
+}
+
+// FailInterruptedMembers marks the members left joining or leaving by a previous Pipeline instance as failed,
+// since their background execution was lost and they will never finish.
+func (m *Manager) FailInterruptedMembers() error {
+	return m.store.FailInterruptedMembers("interrupted by a Pipeline restart")
+}
+
+func (m *Manager) getJoinableCluster(ctx context.Context, organizationID uint, clusterID uint, role string) (cluster.CommonCluster, error) {
+	commonCluster, err := m.clusters.GetClusterByID(ctx, organizationID, clusterID)
+	if err != nil {
+		return nil, emperror.With(err, "clusterID", clusterID)
+	}
+
+	status, err := commonCluster.GetStatus()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get cluster status")
+	}
+
+	if status.Status != pkgCluster.Running {
+		return nil, &meshValidationError{msg: fmt.Sprintf("cluster %d is not running", clusterID)}
+	}
+
+	member, err := m.store.FindMemberByClusterID(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member != nil {
+		return nil, &meshValidationError{msg: fmt.Sprintf("cluster %d is already a member of a mesh", clusterID)}
+	}
+
+	if role == RolePrimary && !commonCluster.GetServiceMesh() {
+		return nil, &meshValidationError{msg: fmt.Sprintf("service mesh has to be installed on primary cluster %d", clusterID)}
+	}
+
+	if role == RoleRemote && commonCluster.GetServiceMesh() {
+		return nil, &meshValidationError{msg: fmt.Sprintf("remote cluster %d must not run its own service mesh control plane", clusterID)}
+	}
+
+	return commonCluster, nil
+}
+
+func (m *Manager) joinMembers(ctx context.Context, organizationID uint, mesh MeshModel) {
+	primary := getPrimary(mesh)
+
+	err := m.joinPrimary(ctx, organizationID, mesh, *primary)
+	m.setMemberStatus(*primary, err)
+
+	for _, member := range mesh.Members {
+		if member.Role != RoleRemote {
+			continue
+		}
+
+		if err != nil {
+			m.setMemberStatus(member, emperror.Wrap(err, "primary cluster failed to join"))
+			continue
+		}
+
+		m.setMemberStatus(member, m.joinRemote(ctx, organizationID, mesh, member))
+	}
+}
+
+// joinPrimary plugs the root CA of the mesh into the control plane of the primary cluster.
+func (m *Manager) joinPrimary(ctx context.Context, organizationID uint, mesh MeshModel, member MemberModel) error {
+	m.logger.WithFields(logrus.Fields{"mesh": mesh.Name, "cluster": member.ClusterName}).Info("joining primary cluster to mesh")
+
+	commonCluster, kubeConfig, client, err := m.getClusterClient(ctx, organizationID, member.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	err = m.installCACerts(organizationID, mesh, member.ClusterName, client)
+	if err != nil {
+		return err
+	}
+
+	err = setSelfSignedCA(commonCluster, kubeConfig, false)
+	if err != nil {
+		return err
+	}
+
+	err = istio.ExposeControlPlane(client)
+	if err != nil {
+		return err
+	}
+
+	// remote clusters can only join once the load balancers of the control plane have addresses
+	err = backoff.Retry(func() error {
+		_, err := istio.GetControlPlaneAddresses(client)
+
+		return err
+	}, backoff.NewConstantBackoffPolicy(&backoff.ConstantBackoffConfig{
+		Delay:      10 * time.Second,
+		MaxRetries: 30,
+	}))
+
+	return emperror.Wrap(err, "failed to get control plane addresses")
+}
+
+// leavePrimary reverts the control plane of the primary cluster to its self-signed CA and removes the mesh resources.
+// Clusters which no longer exist are skipped.
+func (m *Manager) leavePrimary(ctx context.Context, organizationID uint, mesh MeshModel, member MemberModel) error {
+	m.logger.WithFields(logrus.Fields{"mesh": mesh.Name, "cluster": member.ClusterName}).Info("removing primary cluster from mesh")
+
+	commonCluster, kubeConfig, client, err := m.getClusterClient(ctx, organizationID, member.ClusterID)
+	if isClusterNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	err = istio.UnexposeControlPlane(client)
+	if err != nil {
+		return err
+	}
+
+	deployed, err := isReleaseDeployed(istio.ReleaseName, kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	if deployed {
+		err = setSelfSignedCA(commonCluster, kubeConfig, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return istio.DeleteCACertsSecret(client)
+}
+
+// joinRemote installs istio-remote on a remote cluster and registers it in the primary cluster for service discovery.
+func (m *Manager) joinRemote(ctx context.Context, organizationID uint, mesh MeshModel, member MemberModel) error {
+	m.logger.WithFields(logrus.Fields{"mesh": mesh.Name, "cluster": member.ClusterName}).Info("joining remote cluster to mesh")
+
+	primary := getPrimary(mesh)
+
+	_, primaryKubeConfig, primaryClient, err := m.getClusterClient(ctx, organizationID, primary.ClusterID)
+	if err != nil {
+		return emperror.Wrap(err, "failed to connect to primary cluster")
+	}
+
+	commonCluster, kubeConfig, client, err := m.getClusterClient(ctx, organizationID, member.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	deployment, err := helm.GetDeployment(istio.ReleaseName, primaryKubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to get Istio deployment of primary cluster")
+	}
+
+	var primaryConfig istio.Config
+	err = convertValues(deployment.Values, &primaryConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to parse Istio deployment values of primary cluster")
+	}
+
+	addresses, err := istio.GetControlPlaneAddresses(primaryClient)
+	if err != nil {
+		return emperror.Wrap(err, "failed to get control plane addresses of primary cluster")
+	}
+
+	err = k8sutil.EnsureNamespace(client, istio.Namespace)
+	if err != nil {
+		return err
+	}
+
+	err = m.installCACerts(organizationID, mesh, member.ClusterName, client)
+	if err != nil {
+		return err
+	}
+
+	selfSigned := false
+	values, err := yaml.Marshal(istio.Config{
+		Global: istio.Global{
+			Mtls:                   primaryConfig.Global.Mtls,
+			RemotePilotAddress:     addresses.Pilot,
+			RemotePolicyAddress:    addresses.Policy,
+			RemoteTelemetryAddress: addresses.Telemetry,
+		},
+		Security: istio.Security{
+			SelfSigned: &selfSigned,
+		},
+	})
+	if err != nil {
+		return emperror.Wrap(err, "failed to marshal yaml values")
+	}
+
+	err = installOrUpgradeRemote(commonCluster, kubeConfig, deployment.ChartVersion, values)
+	if err != nil {
+		return err
+	}
+
+	restConfig, err := k8sclient.NewClientConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to create client config from kubeconfig")
+	}
+
+	var remoteKubeConfig []byte
+	err = backoff.Retry(func() error {
+		var err error
+		remoteKubeConfig, err = istio.NewRemoteKubeConfig(client, commonCluster.GetName(), restConfig.Host)
+
+		return err
+	}, backoff.NewConstantBackoffPolicy(&backoff.ConstantBackoffConfig{
+		Delay:      5 * time.Second,
+		MaxRetries: 12,
+	}))
+	if err != nil {
+		return emperror.Wrap(err, "failed to create remote kubeconfig")
+	}
+
+	return istio.AddRemoteCluster(primaryClient, commonCluster.GetName(), remoteKubeConfig)
+}
+
+// leaveRemote deregisters a remote cluster from the primary cluster and removes istio-remote from it.
+// Clusters which no longer exist are skipped.
+func (m *Manager) leaveRemote(ctx context.Context, organizationID uint, mesh MeshModel, member MemberModel) error {
+	m.logger.WithFields(logrus.Fields{"mesh": mesh.Name, "cluster": member.ClusterName}).Info("removing remote cluster from mesh")
+
+	primary := getPrimary(mesh)
+
+	_, _, primaryClient, err := m.getClusterClient(ctx, organizationID, primary.ClusterID)
+	if err != nil && !isClusterNotFound(err) {
+		return emperror.Wrap(err, "failed to connect to primary cluster")
+	} else if err == nil {
+		err = istio.RemoveRemoteCluster(primaryClient, member.ClusterName)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, kubeConfig, client, err := m.getClusterClient(ctx, organizationID, member.ClusterID)
+	if isClusterNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	deployed, err := isReleaseDeployed(istio.RemoteReleaseName, kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	if deployed {
+		err = helm.DeleteDeployment(istio.RemoteReleaseName, kubeConfig)
+		if err != nil {
+			return emperror.Wrap(err, "failed to delete istio-remote deployment")
+		}
+	}
+
+	return istio.DeleteCACertsSecret(client)
+}
+
+func (m *Manager) checkPrimary(ctx context.Context, organizationID uint, member Member) (kubernetes.Interface, *istio.ControlPlaneAddresses, *Health) {
+	_, client, health := m.checkMember(ctx, organizationID, member, istio.ReleaseName)
+	if !health.Healthy {
+		return client, nil, health
+	}
+
+	addresses, err := istio.GetControlPlaneAddresses(client)
+	if err != nil {
+		return client, nil, &Health{Message: err.Error()}
+	}
+
+	return client, addresses, health
+}
+
+func (m *Manager) checkRemote(ctx context.Context, organizationID uint, member Member, primaryClient kubernetes.Interface, addresses *istio.ControlPlaneAddresses) *Health {
+	kubeConfig, _, health := m.checkMember(ctx, organizationID, member, istio.RemoteReleaseName)
+	if !health.Healthy {
+		return health
+	}
+
+	if primaryClient == nil || addresses == nil {
+		return &Health{Message: "primary cluster is unreachable"}
+	}
+
+	deployment, err := helm.GetDeployment(istio.RemoteReleaseName, kubeConfig)
+	if err != nil {
+		return &Health{Message: err.Error()}
+	}
+
+	var config istio.Config
+	err = convertValues(deployment.Values, &config)
+	if err != nil {
+		return &Health{Message: err.Error()}
+	}
+
+	if message := checkRemoteAddresses(config.Global, *addresses); message != "" {
+		return &Health{Message: message}
+	}
+
+	registered, err := istio.IsRemoteClusterRegistered(primaryClient, member.ClusterName)
+	if err != nil {
+		return &Health{Message: err.Error()}
+	}
+
+	if !registered {
+		return &Health{Message: "cluster is not registered in the primary cluster"}
+	}
+
+	return health
+}
+
+// checkRemoteAddresses returns a message if the control plane addresses configured in a remote cluster
+// differ from the current addresses of the primary cluster
+func checkRemoteAddresses(global istio.Global, addresses istio.ControlPlaneAddresses) string {
+	configured := istio.ControlPlaneAddresses{
+		Pilot:     global.RemotePilotAddress,
+		Policy:    global.RemotePolicyAddress,
+		Telemetry: global.RemoteTelemetryAddress,
+	}
+
+	if configured != addresses {
+		return fmt.Sprintf("control plane addresses %+v of the cluster differ from the addresses %+v of the primary cluster", configured, addresses)
+	}
+
+	return ""
+}
+
+func (m *Manager) checkMember(ctx context.Context, organizationID uint, member Member, releaseName string) ([]byte, kubernetes.Interface, *Health) {
+	if member.Status != StatusReady {
+		return nil, nil, &Health{Message: fmt.Sprintf("cluster is %s", member.Status)}
+	}
+
+	commonCluster, kubeConfig, client, err := m.getClusterClient(ctx, organizationID, member.ClusterID)
+	if isClusterNotFound(err) {
+		return nil, nil, &Health{Message: "cluster not found"}
+	} else if err != nil {
+		return nil, nil, &Health{Message: err.Error()}
+	}
+
+	status, err := commonCluster.GetStatus()
+	if err != nil {
+		return nil, nil, &Health{Message: err.Error()}
+	}
+
+	if status.Status != pkgCluster.Running {
+		return nil, nil, &Health{Message: fmt.Sprintf("cluster is %s", status.Status)}
+	}
+
+	deployed, err := isReleaseDeployed(releaseName, kubeConfig)
+	if err != nil {
+		return kubeConfig, client, &Health{Message: err.Error()}
+	}
+
+	if !deployed {
+		return kubeConfig, client, &Health{Message: fmt.Sprintf("%s release is not deployed", releaseName)}
+	}
+
+	return kubeConfig, client, &Health{Healthy: true}
+}
+
+func (m *Manager) getClusterClient(ctx context.Context, organizationID uint, clusterID uint) (cluster.CommonCluster, []byte, kubernetes.Interface, error) {
+	commonCluster, err := m.clusters.GetClusterByID(ctx, organizationID, clusterID)
+	if err != nil {
+		return nil, nil, nil, emperror.With(err, "clusterID", clusterID)
+	}
+
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return nil, nil, nil, emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, nil, nil, emperror.Wrap(err, "failed to create client from kubeconfig")
+	}
+
+	return commonCluster, kubeConfig, client, nil
+}
+
+// installCACerts issues an intermediate CA of a member signed by the root CA of the mesh,
+// the key of the root CA never leaves Vault and Pipeline
+func (m *Manager) installCACerts(organizationID uint, mesh MeshModel, clusterName string, client kubernetes.Interface) error {
+	ca, err := m.secrets.Get(organizationID, mesh.CASecretID)
+	if err != nil {
+		return emperror.Wrap(err, "failed to get mesh root CA")
+	}
+
+	caCert, caKey, err := cert.GenerateIntermediateCA(
+		[]byte(ca.Values[pkgSecret.CACert]),
+		[]byte(ca.Values[pkgSecret.CAKey]),
+		fmt.Sprintf("istio-mesh-%s-%s", mesh.Name, clusterName),
+		intermediateCAValidity,
+	)
+	if err != nil {
+		return emperror.Wrap(err, "failed to generate intermediate CA")
+	}
+
+	return istio.CreateCACertsSecret(client, string(caCert), string(caKey), ca.Values[pkgSecret.CACert])
+}
+
+func (m *Manager) setMemberStatus(member MemberModel, err error) {
+	status, message := StatusReady, ""
+	if err != nil {
+		m.errorHandler.Handle(emperror.With(err, "meshID", member.MeshID, "clusterID", member.ClusterID))
+		status, message = StatusError, err.Error()
+	}
+
+	if err := m.store.UpdateMemberStatus(member.ID, status, message); err != nil {
+		m.errorHandler.Handle(err)
+	}
+}
+
+// setSelfSignedCA switches Citadel of the primary cluster between its self-signed CA and the plugged in cacerts secret
+func setSelfSignedCA(commonCluster cluster.CommonCluster, kubeConfig []byte, selfSigned bool) error {
+	deployment, err := helm.GetDeployment(istio.ReleaseName, kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to get Istio deployment")
+	}
+
+	values, err := yaml.Marshal(map[string]interface{}{
+		"security": map[string]interface{}{
+			"selfSigned": selfSigned,
+		},
+	})
+	if err != nil {
+		return emperror.Wrap(err, "failed to marshal yaml values")
+	}
+
+	org, err := auth.GetOrganizationById(commonCluster.GetOrganizationId())
+	if err != nil {
+		return emperror.Wrap(err, "failed to get organization")
+	}
+
+	_, err = helm.UpgradeDeployment(istio.ReleaseName, pkgHelm.BanzaiRepository+"/istio", deployment.ChartVersion, nil, values, true, kubeConfig, helm.GenerateHelmRepoEnv(org.Name))
+
+	return emperror.Wrap(err, "failed to upgrade Istio")
+}
+
+func installOrUpgradeRemote(commonCluster cluster.CommonCluster, kubeConfig []byte, chartVersion string, values []byte) error {
+	org, err := auth.GetOrganizationById(commonCluster.GetOrganizationId())
+	if err != nil {
+		return emperror.Wrap(err, "failed to get organization")
+	}
+
+	env := helm.GenerateHelmRepoEnv(org.Name)
+	chartName := pkgHelm.BanzaiRepository + "/istio-remote"
+
+	deployed, err := isReleaseDeployed(istio.RemoteReleaseName, kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	if deployed {
+		_, err = helm.UpgradeDeployment(istio.RemoteReleaseName, chartName, chartVersion, nil, values, false, kubeConfig, env)
+
+		return emperror.Wrap(err, "failed to upgrade istio-remote")
+	}
+
+	_, err = helm.CreateDeployment(chartName, chartVersion, nil, istio.Namespace, istio.RemoteReleaseName, false, nil, kubeConfig, env,
+		k8sHelm.InstallWait(true),
+		k8sHelm.ValueOverrides(values),
+	)
+
+	return emperror.Wrap(err, "failed to install istio-remote")
+}
+
+func isReleaseDeployed(releaseName string, kubeConfig []byte) (bool, error) {
+	deployments, err := helm.ListDeployments(&releaseName, "", kubeConfig)
+	if err != nil {
+		return false, emperror.Wrap(err, "failed to list deployments")
+	}
+
+	for _, release := range deployments.GetReleases() {
+		if release.Name == releaseName && release.GetInfo().GetStatus().GetCode() == pkgHelmRelease.Status_DEPLOYED {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func isClusterNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	e, ok := errors.Cause(err).(interface{ NotFound() bool })
+
+	return ok && e.NotFound()
+}
+
+func getPrimary(mesh MeshModel) *MemberModel {
+	for i := range mesh.Members {
+		if mesh.Members[i].Role == RolePrimary {
+			return &mesh.Members[i]
+		}
+	}
+
+	return nil
+}
+
+func newMemberModel(commonCluster cluster.CommonCluster, role string) MemberModel {
+	return MemberModel{
+		ClusterID:   commonCluster.GetID(),
+		ClusterName: commonCluster.GetName(),
+		Role:        role,
+		Status:      StatusJoining,
+	}
+}
+
+func newMesh(model MeshModel) Mesh {
+	mesh := Mesh{
+		ID:        model.ID,
+		Name:      model.Name,
+		CreatedAt: model.CreatedAt,
+		Members:   make([]Member, 0, len(model.Members)),
+	}
+
+	for _, member := range model.Members {
+		mesh.Members = append(mesh.Members, Member{
+			ClusterID:     member.ClusterID,
+			ClusterName:   member.ClusterName,
+			Role:          member.Role,
+			Status:        member.Status,
+			StatusMessage: member.StatusMessage,
+		})
+	}
+
+	return mesh
+}
+
+func convertValues(values map[string]interface{}, out interface{}) error {
+	b, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(b, out)
+}
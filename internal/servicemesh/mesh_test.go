@@ -0,0 +1,92 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicemesh
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/pipeline/internal/istio"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+func TestManager_CreateMesh_InvalidName(t *testing.T) {
+	manager := NewManager(nil, nil, nil, logrus.New(), emperror.NewNoopHandler())
+
+	for _, name := range []string{"", "Mesh", "mesh_1", "-mesh"} {
+		_, err := manager.CreateMesh(context.Background(), 1, CreateMeshRequest{Name: name, PrimaryClusterID: 1})
+		if err == nil {
+			t.Fatalf("expected an error for mesh name %q", name)
+		}
+
+		if e, ok := errors.Cause(err).(interface{ IsInvalid() bool }); !ok || !e.IsInvalid() {
+			t.Errorf("expected a validation error for mesh name %q, got: %v", name, err)
+		}
+	}
+}
+
+func TestNewMesh(t *testing.T) {
+	createdAt := time.Now()
+
+	mesh := newMesh(MeshModel{
+		ID:        1,
+		Name:      "mesh",
+		CreatedAt: createdAt,
+		Members: []MemberModel{
+			{ClusterID: 1, ClusterName: "primary", Role: RolePrimary, Status: StatusReady},
+			{ClusterID: 2, ClusterName: "remote", Role: RoleRemote, Status: StatusError, StatusMessage: "failed"},
+		},
+	})
+
+	if mesh.ID != 1 || mesh.Name != "mesh" || !mesh.CreatedAt.Equal(createdAt) {
+		t.Errorf("unexpected mesh: %+v", mesh)
+	}
+
+	if mesh.Healthy != nil {
+		t.Error("health should only be reported by the topology")
+	}
+
+	expected := []Member{
+		{ClusterID: 1, ClusterName: "primary", Role: RolePrimary, Status: StatusReady},
+		{ClusterID: 2, ClusterName: "remote", Role: RoleRemote, Status: StatusError, StatusMessage: "failed"},
+	}
+
+	if len(mesh.Members) != len(expected) {
+		t.Fatalf("expected %d members, got %d", len(expected), len(mesh.Members))
+	}
+
+	for i, member := range mesh.Members {
+		if member != expected[i] {
+			t.Errorf("expected member %+v, got %+v", expected[i], member)
+		}
+	}
+}
+
+func TestCheckRemoteAddresses(t *testing.T) {
+	addresses := istio.ControlPlaneAddresses{Pilot: "10.0.0.1", Policy: "10.0.0.2", Telemetry: "10.0.0.3"}
+
+	global := istio.Global{RemotePilotAddress: "10.0.0.1", RemotePolicyAddress: "10.0.0.2", RemoteTelemetryAddress: "10.0.0.3"}
+	if message := checkRemoteAddresses(global, addresses); message != "" {
+		t.Errorf("unexpected message: %s", message)
+	}
+
+	global.RemotePilotAddress = "10.1.0.1"
+	if message := checkRemoteAddresses(global, addresses); message == "" {
+		t.Error("expected a message for a stale pilot address")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicemesh
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	meshTableName   = "service_meshes"
+	memberTableName = "service_mesh_members"
+)
+
+// MeshModel stores a multi-cluster service mesh of an organization.
+type MeshModel struct {
+	ID        uint `gorm:"primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	OrganizationID uint   `gorm:"not null;unique_index:idx_service_meshes_organization_id_name"`
+	Name           string `gorm:"not null;unique_index:idx_service_meshes_organization_id_name"`
+	CASecretID     string `gorm:"not null"`
+
+	Members []MemberModel `gorm:"foreignkey:MeshID"`
+}
+
+// TableName changes the default table name.
+func (MeshModel) TableName() string {
+	return meshTableName
+}
+
+// MemberModel stores a cluster joined to a multi-cluster service mesh.
+type MemberModel struct {
+	ID        uint `gorm:"primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	MeshID        uint   `gorm:"not null;index"`
+	ClusterID     uint   `gorm:"not null;unique_index"`
+	ClusterName   string `gorm:"not null"`
+	Role          string `gorm:"not null"`
+	Status        string `gorm:"not null"`
+	StatusMessage string `gorm:"type:text"`
+}
+
+// TableName changes the default table name.
+func (MemberModel) TableName() string {
+	return memberTableName
+}
+
+// Migrate executes the table migrations for the service mesh module.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&MeshModel{},
+		&MemberModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating service mesh tables")
+
+	return db.AutoMigrate(tables...).Error
+}
@@ -0,0 +1,133 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicemesh
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Store persists multi-cluster service meshes and their members.
+type Store struct {
+	db *gorm.DB
+}
+
+// NewStore returns a new Store instance.
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// CreateMesh saves a mesh together with its members.
+func (s *Store) CreateMesh(mesh *MeshModel) error {
+	return errors.Wrap(s.db.Create(mesh).Error, "could not save mesh")
+}
+
+// GetMesh returns a mesh of an organization with its members.
+func (s *Store) GetMesh(organizationID uint, meshID uint) (*MeshModel, error) {
+	var mesh MeshModel
+
+	err := s.db.
+		Preload("Members").
+		Where(MeshModel{OrganizationID: organizationID, ID: meshID}).
+		First(&mesh).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, &meshNotFoundError{organizationID: organizationID, meshID: meshID}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not fetch mesh")
+	}
+
+	return &mesh, nil
+}
+
+// ListMeshes returns the meshes of an organization with their members.
+func (s *Store) ListMeshes(organizationID uint) ([]MeshModel, error) {
+	var meshes []MeshModel
+
+	err := s.db.
+		Preload("Members").
+		Where(MeshModel{OrganizationID: organizationID}).
+		Order("name").
+		Find(&meshes).Error
+
+	return meshes, errors.Wrap(err, "could not fetch meshes")
+}
+
+// FindMemberByClusterID returns the membership of a cluster or nil if the cluster is not a member of any mesh.
+func (s *Store) FindMemberByClusterID(clusterID uint) (*MemberModel, error) {
+	var member MemberModel
+
+	err := s.db.Where(MemberModel{ClusterID: clusterID}).First(&member).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not fetch mesh member")
+	}
+
+	return &member, nil
+}
+
+// AddMember saves a new member of a mesh.
+func (s *Store) AddMember(member *MemberModel) error {
+	return errors.Wrap(s.db.Create(member).Error, "could not save mesh member")
+}
+
+// UpdateMemberStatus updates the status of a mesh member.
+func (s *Store) UpdateMemberStatus(memberID uint, status string, message string) error {
+	err := s.db.
+		Model(&MemberModel{ID: memberID}).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"status_message": message,
+		}).Error
+
+	return errors.Wrap(err, "could not update mesh member status")
+}
+
+// FailInterruptedMembers marks the members of every mesh which are joining or leaving as failed.
+func (s *Store) FailInterruptedMembers(message string) error {
+	err := s.db.
+		Model(&MemberModel{}).
+		Where("status IN (?)", []string{StatusJoining, StatusLeaving}).
+		Updates(map[string]interface{}{
+			"status":         StatusError,
+			"status_message": message,
+		}).Error
+
+	return errors.Wrap(err, "could not update interrupted mesh members")
+}
+
+// DeleteMember deletes a mesh member.
+func (s *Store) DeleteMember(memberID uint) error {
+	return errors.Wrap(s.db.Delete(&MemberModel{ID: memberID}).Error, "could not delete mesh member")
+}
+
+// DeleteMesh deletes a mesh and its remaining members.
+func (s *Store) DeleteMesh(meshID uint) error {
+	tx := s.db.Begin()
+
+	err := tx.Where(MemberModel{MeshID: meshID}).Delete(MemberModel{}).Error
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "could not delete mesh members")
+	}
+
+	err = tx.Delete(&MeshModel{ID: meshID}).Error
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "could not delete mesh")
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not delete mesh")
+}
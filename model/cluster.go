@@ -145,8 +145,10 @@ type AmazonNodePoolsModel struct {
 	Count            int
 	NodeImage        string
 	NodeInstanceType string
-	Labels           map[string]string `gorm:"-"`
-	Delete           bool              `gorm:"-"`
+	// AutoScalingGroupName is set for node pools adopted at cluster import, these have no Pipeline node pool stack
+	AutoScalingGroupName string            `gorm:"size:255"`
+	Labels               map[string]string `gorm:"-"`
+	Delete               bool              `gorm:"-"`
 }
 
 // BeforeDelete deletes all nodepool labels that belongs to this AmazonNodePoolsModel
@@ -187,6 +189,22 @@ type EKSClusterModel struct {
 	VpcCidr      *string                 `gorm:"size:18"`
 	RouteTableId *string                 `gorm:"size:32"`
 	Subnets      []*EKSSubnetModel       `gorm:"foreignkey:ClusterID"`
+
+	APIServerAccess APIServerAccessModel `gorm:"embedded;embedded_prefix:api_server_"`
+
+	// Imported clusters have no Pipeline cluster stack, the resources otherwise read from its outputs are stored here
+	Imported            bool
+	SecurityGroupId     *string `gorm:"size:32"`
+	NodeSecurityGroupId *string `gorm:"size:32"`
+	NodeInstanceRoleId  string
+}
+
+// APIServerAccessModel describes how the API server and the nodes of a managed cluster are exposed
+type APIServerAccessModel struct {
+	PrivateEndpoint    bool
+	PublicAccessCIDRs  string `gorm:"column:public_access_cidrs" sql:"type:text;"`
+	PrivateNodes       bool
+	TunnelAgentAddress string
 }
 
 //AKSClusterModel describes the aks cluster model
@@ -195,6 +213,8 @@ type AKSClusterModel struct {
 	ResourceGroup     string
 	KubernetesVersion string
 	NodePools         []*AKSNodePoolModel `gorm:"foreignkey:ClusterID"`
+
+	APIServerAccess APIServerAccessModel `gorm:"embedded;embedded_prefix:api_server_"`
 }
 
 // AKSNodePoolModel describes AKS node pools model of a cluster
@@ -0,0 +1,58 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	clusterSpecTableName = "cluster_specs"
+)
+
+// ClusterSpecModel stores the last applied desired state spec of a cluster.
+type ClusterSpecModel struct {
+	ClusterID uint   `gorm:"primary_key"`
+	Spec      string `sql:"type:text;"`
+	UpdatedAt time.Time
+	UpdatedBy uint
+}
+
+// TableName changes the default table name.
+func (ClusterSpecModel) TableName() string {
+	return clusterSpecTableName
+}
+
+// GetClusterSpec returns the last applied spec of a cluster or nil if no spec has been applied yet.
+func GetClusterSpec(clusterID uint) (*ClusterSpecModel, error) {
+	var spec ClusterSpecModel
+
+	err := config.DB().Where(ClusterSpecModel{ClusterID: clusterID}).First(&spec).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+// Save saves the applied spec of a cluster.
+func (m *ClusterSpecModel) Save() error {
+	return config.DB().Save(m).Error
+}
@@ -35,6 +35,8 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 		&GKEProfile{},
 		&GKENodePoolProfile{},
 		&GKENodePoolLabelsProfile{},
+		&ClusterTemplateModel{},
+		&ClusterTemplateClusterModel{},
 	}
 
 	var tableNames string
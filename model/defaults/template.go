@@ -0,0 +1,398 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/model"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// cluster template table names
+const (
+	ClusterTemplateTableName        = "cluster_templates"
+	ClusterTemplateClusterTableName = "cluster_template_clusters"
+)
+
+// maxClusterTemplateDepth limits the length of template inheritance chains
+const maxClusterTemplateDepth = 10
+
+// ClusterTemplateModel describes a version of an organization's cluster template
+type ClusterTemplateModel struct {
+	ID             uint   `gorm:"primary_key"`
+	OrganizationID uint   `gorm:"unique_index:idx_cluster_template_org_name_version"`
+	Name           string `gorm:"unique_index:idx_cluster_template_org_name_version;size:64"`
+	Version        uint   `gorm:"unique_index:idx_cluster_template_org_name_version"`
+	Description    string `sql:"type:text;"`
+	Extends        string `gorm:"size:64"`
+	ExtendsVersion uint
+	Parameters     string `sql:"type:text;"`
+	Spec           string `sql:"type:text;"`
+	CreatedAt      time.Time
+	CreatedBy      uint
+}
+
+// ClusterTemplateClusterModel records the cluster template version a cluster is created from
+type ClusterTemplateClusterModel struct {
+	ClusterID       uint   `gorm:"primary_key;auto_increment:false"`
+	OrganizationID  uint   `gorm:"index:idx_cluster_template_cluster_org_name"`
+	TemplateName    string `gorm:"index:idx_cluster_template_cluster_org_name;size:64"`
+	TemplateVersion uint
+	CreatedAt       time.Time
+}
+
+// TableName overrides ClusterTemplateModel's table name
+func (ClusterTemplateModel) TableName() string {
+	return ClusterTemplateTableName
+}
+
+// TableName overrides ClusterTemplateClusterModel's table name
+func (ClusterTemplateClusterModel) TableName() string {
+	return ClusterTemplateClusterTableName
+}
+
+func (m *ClusterTemplateModel) getParameters() ([]pkgCluster.ClusterTemplateParameter, error) {
+	var parameters []pkgCluster.ClusterTemplateParameter
+	if m.Parameters == "" {
+		return parameters, nil
+	}
+
+	err := json.Unmarshal([]byte(m.Parameters), &parameters)
+
+	return parameters, errors.Wrap(err, "failed to unmarshal cluster template parameters")
+}
+
+func (m *ClusterTemplateModel) getSpec() (map[string]interface{}, error) {
+	spec := make(map[string]interface{})
+	if m.Spec == "" {
+		return spec, nil
+	}
+
+	err := json.Unmarshal([]byte(m.Spec), &spec)
+
+	return spec, errors.Wrap(err, "failed to unmarshal cluster template spec")
+}
+
+// GetResponse converts the cluster template to its API representation
+func (m *ClusterTemplateModel) GetResponse() (*pkgCluster.ClusterTemplateResponse, error) {
+	parameters, err := m.getParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := m.getSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pkgCluster.ClusterTemplateResponse{
+		Name:           m.Name,
+		Version:        m.Version,
+		Description:    m.Description,
+		Extends:        m.Extends,
+		ExtendsVersion: m.ExtendsVersion,
+		Parameters:     parameters,
+		Spec:           spec,
+		CreatedAt:      m.CreatedAt,
+		CreatedBy:      m.CreatedBy,
+	}, nil
+}
+
+// resolve merges the template over the templates it extends, returning the full spec and parameters
+func (m *ClusterTemplateModel) resolve() (map[string]interface{}, []pkgCluster.ClusterTemplateParameter, error) {
+	var chain []*ClusterTemplateModel
+	visited := make(map[string]bool)
+
+	for template := m; ; {
+		if visited[template.Name] || len(chain) == maxClusterTemplateDepth {
+			return nil, nil, emperror.With(pkgErrors.ErrorClusterTemplateCycle, "template", m.Name)
+		}
+		visited[template.Name] = true
+		chain = append(chain, template)
+
+		if template.Extends == "" {
+			break
+		}
+
+		parent, err := GetClusterTemplate(template.OrganizationID, template.Extends, template.ExtendsVersion)
+		if err != nil {
+			return nil, nil, emperror.With(err, "template", template.Name, "extends", template.Extends)
+		}
+		template = parent
+	}
+
+	spec := make(map[string]interface{})
+	var parameters []pkgCluster.ClusterTemplateParameter
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		templateSpec, err := chain[i].getSpec()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		templateParameters, err := chain[i].getParameters()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		spec = mergeTemplateSpecs(spec, templateSpec)
+		parameters = mergeTemplateParameters(parameters, templateParameters)
+	}
+
+	return spec, parameters, nil
+}
+
+// SaveClusterTemplate saves a new version of a cluster template.
+// New templates start at version 1, updates of existing templates get the next version.
+func SaveClusterTemplate(orgID uint, userID uint, request *pkgCluster.ClusterTemplateRequest, update bool) (*ClusterTemplateModel, error) {
+	if request.Name == "" {
+		return nil, pkgErrors.ErrorClusterTemplateNameEmpty
+	}
+
+	var version uint = 1
+	latest, err := GetClusterTemplate(orgID, request.Name, 0)
+	switch {
+	case err == nil && !update:
+		return nil, pkgErrors.ErrorClusterTemplateExists
+	case err == nil:
+		version = latest.Version + 1
+	case errors.Cause(err) != pkgErrors.ErrorClusterTemplateNotFound:
+		return nil, err
+	case update:
+		return nil, err
+	}
+
+	if err := validateTemplateParameters(request.Parameters); err != nil {
+		return nil, err
+	}
+
+	// the version of the extended template is pinned so that a template version always renders the same way
+	var extendsVersion uint
+	if request.Extends != "" {
+		parent, err := GetClusterTemplate(orgID, request.Extends, request.ExtendsVersion)
+		if err != nil {
+			return nil, emperror.With(err, "extends", request.Extends)
+		}
+		extendsVersion = parent.Version
+	}
+
+	parameters, err := json.Marshal(request.Parameters)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal cluster template parameters")
+	}
+
+	spec, err := json.Marshal(request.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal cluster template spec")
+	}
+
+	template := &ClusterTemplateModel{
+		OrganizationID: orgID,
+		Name:           request.Name,
+		Version:        version,
+		Description:    request.Description,
+		Extends:        request.Extends,
+		ExtendsVersion: extendsVersion,
+		Parameters:     string(parameters),
+		Spec:           string(spec),
+		CreatedBy:      userID,
+	}
+
+	// every parameter referenced in the template or the templates it extends has to be declared
+	resolvedSpec, resolvedParameters, err := template.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	declared := make(map[string]bool, len(resolvedParameters))
+	for _, parameter := range resolvedParameters {
+		declared[parameter.Name] = true
+	}
+
+	for _, reference := range templateParameterReferences(resolvedSpec) {
+		if !declared[reference] {
+			return nil, errors.Errorf("undeclared parameter: %q", reference)
+		}
+	}
+
+	if err := config.DB().Create(template).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to save cluster template")
+	}
+
+	return template, nil
+}
+
+// GetClusterTemplate returns a version of a cluster template, version 0 means the latest one
+func GetClusterTemplate(orgID uint, name string, version uint) (*ClusterTemplateModel, error) {
+	query := config.DB().Where(&ClusterTemplateModel{OrganizationID: orgID, Name: name})
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+
+	var template ClusterTemplateModel
+	err := query.Order("version desc").First(&template).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, emperror.With(pkgErrors.ErrorClusterTemplateNotFound, "template", name, "version", version)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster template")
+	}
+
+	return &template, nil
+}
+
+// ListClusterTemplates returns the latest version of every cluster template of an organization
+func ListClusterTemplates(orgID uint) ([]*ClusterTemplateModel, error) {
+	var templates []*ClusterTemplateModel
+	err := config.DB().Where(&ClusterTemplateModel{OrganizationID: orgID}).Order("name, version desc").Find(&templates).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list cluster templates")
+	}
+
+	latest := make([]*ClusterTemplateModel, 0, len(templates))
+	for _, template := range templates {
+		if len(latest) == 0 || latest[len(latest)-1].Name != template.Name {
+			latest = append(latest, template)
+		}
+	}
+
+	return latest, nil
+}
+
+// ListClusterTemplateVersions returns every version of a cluster template, latest first
+func ListClusterTemplateVersions(orgID uint, name string) ([]*ClusterTemplateModel, error) {
+	var templates []*ClusterTemplateModel
+	err := config.DB().Where(&ClusterTemplateModel{OrganizationID: orgID, Name: name}).Order("version desc").Find(&templates).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list cluster template versions")
+	}
+
+	if len(templates) == 0 {
+		return nil, emperror.With(pkgErrors.ErrorClusterTemplateNotFound, "template", name)
+	}
+
+	return templates, nil
+}
+
+// DeleteClusterTemplate deletes every version of a cluster template unless other templates extend it
+func DeleteClusterTemplate(orgID uint, name string) error {
+	if _, err := GetClusterTemplate(orgID, name, 0); err != nil {
+		return err
+	}
+
+	var count int
+	err := config.DB().Model(&ClusterTemplateModel{}).
+		Where(&ClusterTemplateModel{OrganizationID: orgID, Extends: name}).
+		Where("name <> ?", name).
+		Count(&count).Error
+	if err != nil {
+		return errors.Wrap(err, "failed to look up extending cluster templates")
+	}
+	if count > 0 {
+		return emperror.With(pkgErrors.ErrorClusterTemplateExtended, "template", name)
+	}
+
+	tx := config.DB().Begin()
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	err = tx.Where(&ClusterTemplateClusterModel{OrganizationID: orgID, TemplateName: name}).Delete(&ClusterTemplateClusterModel{}).Error
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "failed to delete cluster template usages")
+	}
+
+	err = tx.Where(&ClusterTemplateModel{OrganizationID: orgID, Name: name}).Delete(&ClusterTemplateModel{}).Error
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "failed to delete cluster template")
+	}
+
+	return tx.Commit().Error
+}
+
+// RenderClusterTemplate creates a cluster create request from a cluster template and parameter values
+func RenderClusterTemplate(orgID uint, ref *pkgCluster.ClusterTemplateRef) (*pkgCluster.CreateClusterRequest, *ClusterTemplateModel, error) {
+	template, err := GetClusterTemplate(orgID, ref.Name, ref.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	spec, parameters, err := template.resolve()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request, err := renderTemplateSpec(spec, parameters, ref.Parameters)
+	if err != nil {
+		return nil, nil, emperror.With(err, "template", template.Name, "version", template.Version)
+	}
+
+	return request, template, nil
+}
+
+// SaveClusterTemplateUsage records the cluster template version a cluster is created from
+func SaveClusterTemplateUsage(orgID uint, clusterID uint, template *ClusterTemplateModel) error {
+	return config.DB().Save(&ClusterTemplateClusterModel{
+		ClusterID:       clusterID,
+		OrganizationID:  orgID,
+		TemplateName:    template.Name,
+		TemplateVersion: template.Version,
+	}).Error
+}
+
+// ListClusterTemplateClusters returns the existing clusters created from a cluster template
+func ListClusterTemplateClusters(orgID uint, name string) ([]pkgCluster.ClusterTemplateClusterResponse, error) {
+	latest, err := GetClusterTemplate(orgID, name, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var usages []ClusterTemplateClusterModel
+	err = config.DB().Where(&ClusterTemplateClusterModel{OrganizationID: orgID, TemplateName: name}).Order("cluster_id").Find(&usages).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list cluster template usages")
+	}
+
+	clusters := make([]pkgCluster.ClusterTemplateClusterResponse, 0, len(usages))
+	for _, usage := range usages {
+		var cluster model.ClusterModel
+		err := config.DB().Where(&model.ClusterModel{ID: usage.ClusterID}).First(&cluster).Error
+		if gorm.IsRecordNotFoundError(err) {
+			// deleted cluster
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get cluster")
+		}
+
+		clusters = append(clusters, pkgCluster.ClusterTemplateClusterResponse{
+			ClusterID:       cluster.ID,
+			ClusterName:     cluster.Name,
+			TemplateVersion: usage.TemplateVersion,
+			Outdated:        usage.TemplateVersion < latest.Version,
+		})
+	}
+
+	return clusters, nil
+}
@@ -0,0 +1,309 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/pkg/errors"
+)
+
+var templateParameterRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// mergeTemplateSpecs deep merges the spec of a template over the spec of the template it extends
+func mergeTemplateSpecs(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range override {
+		baseMap, baseIsMap := merged[key].(map[string]interface{})
+		overrideMap, overrideIsMap := value.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			merged[key] = mergeTemplateSpecs(baseMap, overrideMap)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+// mergeTemplateParameters merges the parameters of a template over the parameters of the template it extends
+func mergeTemplateParameters(base, override []pkgCluster.ClusterTemplateParameter) []pkgCluster.ClusterTemplateParameter {
+	merged := make([]pkgCluster.ClusterTemplateParameter, 0, len(base)+len(override))
+	indexes := make(map[string]int, len(base)+len(override))
+
+	for _, parameters := range [][]pkgCluster.ClusterTemplateParameter{base, override} {
+		for _, parameter := range parameters {
+			if i, ok := indexes[parameter.Name]; ok {
+				merged[i] = parameter
+				continue
+			}
+
+			indexes[parameter.Name] = len(merged)
+			merged = append(merged, parameter)
+		}
+	}
+
+	return merged
+}
+
+// validateTemplateParameters checks the declaration of template parameters
+func validateTemplateParameters(parameters []pkgCluster.ClusterTemplateParameter) error {
+	names := make(map[string]bool, len(parameters))
+
+	for _, parameter := range parameters {
+		if !templateParameterRegexp.MatchString("${" + parameter.Name + "}") {
+			return errors.Errorf("invalid parameter name: %q", parameter.Name)
+		}
+
+		if names[parameter.Name] {
+			return errors.Errorf("duplicate parameter: %q", parameter.Name)
+		}
+		names[parameter.Name] = true
+
+		switch parameter.Type {
+		case pkgCluster.TemplateParameterString, pkgCluster.TemplateParameterInteger,
+			pkgCluster.TemplateParameterNumber, pkgCluster.TemplateParameterBoolean:
+		default:
+			return errors.Errorf("unknown type of parameter %q: %q", parameter.Name, parameter.Type)
+		}
+
+		if parameter.Default != nil {
+			if _, err := convertTemplateParameterValue(parameter, parameter.Default); err != nil {
+				return errors.Wrapf(err, "invalid default value of parameter %q", parameter.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveTemplateParameterValues checks and converts the given parameter values, falling back to the defaults
+func resolveTemplateParameterValues(parameters []pkgCluster.ClusterTemplateParameter, values map[string]interface{}) (map[string]interface{}, error) {
+	declared := make(map[string]bool, len(parameters))
+	resolved := make(map[string]interface{}, len(parameters))
+
+	for _, parameter := range parameters {
+		declared[parameter.Name] = true
+
+		value, ok := values[parameter.Name]
+		if !ok || value == nil {
+			if parameter.Default == nil {
+				if parameter.Required {
+					return nil, errors.Errorf("parameter %q is required", parameter.Name)
+				}
+
+				continue
+			}
+
+			value = parameter.Default
+		}
+
+		converted, err := convertTemplateParameterValue(parameter, value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of parameter %q", parameter.Name)
+		}
+
+		resolved[parameter.Name] = converted
+	}
+
+	for name := range values {
+		if !declared[name] {
+			return nil, errors.Errorf("unknown parameter: %q", name)
+		}
+	}
+
+	return resolved, nil
+}
+
+// convertTemplateParameterValue converts a (JSON decoded) value to the type of the parameter
+func convertTemplateParameterValue(parameter pkgCluster.ClusterTemplateParameter, value interface{}) (interface{}, error) {
+	switch parameter.Type {
+	case pkgCluster.TemplateParameterString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+
+	case pkgCluster.TemplateParameterInteger:
+		switch v := value.(type) {
+		case int:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case uint:
+			return int64(v), nil
+		case float64:
+			if v == math.Trunc(v) {
+				return int64(v), nil
+			}
+		case json.Number:
+			return v.Int64()
+		case string:
+			return strconv.ParseInt(v, 10, 64)
+		}
+
+	case pkgCluster.TemplateParameterNumber:
+		switch v := value.(type) {
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case json.Number:
+			return v.Float64()
+		case string:
+			return strconv.ParseFloat(v, 64)
+		}
+
+	case pkgCluster.TemplateParameterBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(v)
+		}
+
+	default:
+		return nil, errors.Errorf("unknown parameter type: %q", parameter.Type)
+	}
+
+	return nil, errors.Errorf("%v is not a valid %s", value, parameter.Type)
+}
+
+// substituteTemplateParameters replaces the parameter references in a template spec.
+// A string consisting of a single reference is replaced with the typed value of the parameter.
+func substituteTemplateParameters(spec interface{}, values map[string]interface{}) (interface{}, error) {
+	switch v := spec.(type) {
+	case map[string]interface{}:
+		substituted := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			newKey, err := substituteTemplateParametersInString(key, values)
+			if err != nil {
+				return nil, err
+			}
+
+			newValue, err := substituteTemplateParameters(value, values)
+			if err != nil {
+				return nil, err
+			}
+
+			substituted[fmt.Sprint(newKey)] = newValue
+		}
+		return substituted, nil
+
+	case []interface{}:
+		substituted := make([]interface{}, 0, len(v))
+		for _, value := range v {
+			newValue, err := substituteTemplateParameters(value, values)
+			if err != nil {
+				return nil, err
+			}
+
+			substituted = append(substituted, newValue)
+		}
+		return substituted, nil
+
+	case string:
+		return substituteTemplateParametersInString(v, values)
+
+	default:
+		return spec, nil
+	}
+}
+
+func substituteTemplateParametersInString(s string, values map[string]interface{}) (interface{}, error) {
+	if match := templateParameterRegexp.FindStringSubmatch(s); match != nil && match[0] == s {
+		value, ok := values[match[1]]
+		if !ok {
+			return nil, errors.Errorf("parameter %q has no value", match[1])
+		}
+
+		return value, nil
+	}
+
+	var err error
+	substituted := templateParameterRegexp.ReplaceAllStringFunc(s, func(reference string) string {
+		name := templateParameterRegexp.FindStringSubmatch(reference)[1]
+
+		value, ok := values[name]
+		if !ok {
+			err = errors.Errorf("parameter %q has no value", name)
+			return reference
+		}
+
+		return fmt.Sprint(value)
+	})
+
+	return substituted, err
+}
+
+// templateParameterReferences returns the names of the parameters referenced in a template spec
+func templateParameterReferences(spec interface{}) []string {
+	var references []string
+
+	switch v := spec.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			references = append(references, templateParameterReferences(key)...)
+			references = append(references, templateParameterReferences(value)...)
+		}
+
+	case []interface{}:
+		for _, value := range v {
+			references = append(references, templateParameterReferences(value)...)
+		}
+
+	case string:
+		for _, match := range templateParameterRegexp.FindAllStringSubmatch(v, -1) {
+			references = append(references, match[1])
+		}
+	}
+
+	return references
+}
+
+// renderTemplateSpec substitutes the parameters into a resolved template spec and converts it to a cluster create request
+func renderTemplateSpec(spec map[string]interface{}, parameters []pkgCluster.ClusterTemplateParameter, values map[string]interface{}) (*pkgCluster.CreateClusterRequest, error) {
+	resolved, err := resolveTemplateParameterValues(parameters, values)
+	if err != nil {
+		return nil, err
+	}
+
+	substituted, err := substituteTemplateParameters(spec, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(substituted)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal cluster template spec")
+	}
+
+	var request pkgCluster.CreateClusterRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		return nil, errors.Wrap(err, "cluster template spec is not a valid cluster create request")
+	}
+
+	return &request, nil
+}
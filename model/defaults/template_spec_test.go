@@ -0,0 +1,190 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+func TestMergeTemplateSpecs(t *testing.T) {
+	base := map[string]interface{}{
+		"cloud":    "amazon",
+		"location": "us-west-2",
+		"properties": map[string]interface{}{
+			"eks": map[string]interface{}{
+				"version": "1.11",
+				"nodePools": map[string]interface{}{
+					"pool1": map[string]interface{}{"instanceType": "m4.xlarge", "count": 1},
+				},
+			},
+		},
+	}
+	override := map[string]interface{}{
+		"location": "eu-west-1",
+		"properties": map[string]interface{}{
+			"eks": map[string]interface{}{
+				"nodePools": map[string]interface{}{
+					"pool1": map[string]interface{}{"count": 3},
+				},
+			},
+		},
+	}
+
+	expected := map[string]interface{}{
+		"cloud":    "amazon",
+		"location": "eu-west-1",
+		"properties": map[string]interface{}{
+			"eks": map[string]interface{}{
+				"version": "1.11",
+				"nodePools": map[string]interface{}{
+					"pool1": map[string]interface{}{"instanceType": "m4.xlarge", "count": 3},
+				},
+			},
+		},
+	}
+
+	if actual := mergeTemplateSpecs(base, override); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("unexpected merged spec\nexpected: %v\nactual:   %v", expected, actual)
+	}
+
+	if base["location"] != "us-west-2" {
+		t.Error("base spec should not be modified")
+	}
+}
+
+func TestMergeTemplateParameters(t *testing.T) {
+	base := []pkgCluster.ClusterTemplateParameter{
+		{Name: "region", Type: "string", Default: "us-west-2"},
+		{Name: "nodes", Type: "integer", Default: 1.0},
+	}
+	override := []pkgCluster.ClusterTemplateParameter{
+		{Name: "nodes", Type: "integer", Default: 3.0},
+		{Name: "spot", Type: "boolean"},
+	}
+
+	expected := []pkgCluster.ClusterTemplateParameter{
+		{Name: "region", Type: "string", Default: "us-west-2"},
+		{Name: "nodes", Type: "integer", Default: 3.0},
+		{Name: "spot", Type: "boolean"},
+	}
+
+	if actual := mergeTemplateParameters(base, override); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("unexpected merged parameters\nexpected: %v\nactual:   %v", expected, actual)
+	}
+}
+
+func TestRenderTemplateSpec(t *testing.T) {
+	spec := map[string]interface{}{
+		"cloud":      "amazon",
+		"location":   "${region}",
+		"ttlMinutes": "${ttl}",
+		"properties": map[string]interface{}{
+			"eks": map[string]interface{}{
+				"version": "1.11",
+				"nodePools": map[string]interface{}{
+					"${pool}-workers": map[string]interface{}{
+						"instanceType": "m4.xlarge",
+						"count":        "${nodes}",
+						"autoscaling":  "${autoscaling}",
+					},
+				},
+			},
+		},
+	}
+	parameters := []pkgCluster.ClusterTemplateParameter{
+		{Name: "region", Type: pkgCluster.TemplateParameterString, Required: true},
+		{Name: "pool", Type: pkgCluster.TemplateParameterString, Default: "app"},
+		{Name: "nodes", Type: pkgCluster.TemplateParameterInteger, Default: 2.0},
+		{Name: "ttl", Type: pkgCluster.TemplateParameterInteger, Default: 0.0},
+		{Name: "autoscaling", Type: pkgCluster.TemplateParameterBoolean, Default: false},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		request, err := renderTemplateSpec(spec, parameters, map[string]interface{}{
+			"region":      "eu-west-1",
+			"nodes":       5.0,
+			"autoscaling": "true",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if request.Location != "eu-west-1" {
+			t.Errorf("unexpected location: %q", request.Location)
+		}
+
+		nodePool := request.Properties.CreateClusterEKS.NodePools["app-workers"]
+		if nodePool == nil {
+			t.Fatalf("node pool is missing: %v", request.Properties.CreateClusterEKS.NodePools)
+		}
+		if nodePool.Count != 5 || !nodePool.Autoscaling {
+			t.Errorf("unexpected node pool: %+v", nodePool)
+		}
+	})
+
+	errorCases := map[string]map[string]interface{}{
+		"missing required parameter": {},
+		"unknown parameter":          {"region": "eu-west-1", "zone": "a"},
+		"invalid integer":            {"region": "eu-west-1", "nodes": 2.5},
+		"invalid string":             {"region": 1.0},
+	}
+
+	for name, values := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := renderTemplateSpec(spec, parameters, values); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestValidateTemplateParameters(t *testing.T) {
+	testCases := map[string]struct {
+		parameters []pkgCluster.ClusterTemplateParameter
+		valid      bool
+	}{
+		"valid": {
+			parameters: []pkgCluster.ClusterTemplateParameter{{Name: "nodes", Type: "integer", Default: 1.0}},
+			valid:      true,
+		},
+		"invalid name": {
+			parameters: []pkgCluster.ClusterTemplateParameter{{Name: "node-count", Type: "integer"}},
+		},
+		"unknown type": {
+			parameters: []pkgCluster.ClusterTemplateParameter{{Name: "nodes", Type: "list"}},
+		},
+		"duplicate": {
+			parameters: []pkgCluster.ClusterTemplateParameter{{Name: "nodes", Type: "integer"}, {Name: "nodes", Type: "string"}},
+		},
+		"invalid default": {
+			parameters: []pkgCluster.ClusterTemplateParameter{{Name: "nodes", Type: "integer", Default: "many"}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := validateTemplateParameters(tc.parameters)
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
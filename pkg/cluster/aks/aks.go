@@ -35,6 +35,7 @@ type CreateClusterAKS struct {
 	ResourceGroup     string                     `json:"resourceGroup" yaml:"resourceGroup"`
 	KubernetesVersion string                     `json:"kubernetesVersion" yaml:"kubernetesVersion"`
 	NodePools         map[string]*NodePoolCreate `json:"nodePools,omitempty" yaml:"nodePools,omitempty"`
+	APIServerAccess   *pkgCommon.APIServerAccess `json:"apiServerAccess,omitempty" yaml:"apiServerAccess,omitempty"`
 }
 
 // NodePoolCreate describes Azure's node fields of a CreateCluster request
@@ -114,6 +115,15 @@ func (azure *CreateClusterAKS) Validate() error {
 		azure.KubernetesVersion = defaultKubernetesVersion
 	}
 
+	if err := azure.APIServerAccess.Validate(); err != nil {
+		return err
+	}
+
+	// the AKS API version in use cannot restrict the API server endpoint, nodes never get public IP addresses
+	if azure.APIServerAccess != nil && (azure.APIServerAccess.PrivateEndpoint || len(azure.APIServerAccess.PublicAccessCIDRs) > 0) {
+		return pkgErrors.ErrorAPIServerAccessNotSupported
+	}
+
 	return nil
 }
 
@@ -166,3 +176,21 @@ type ClusterProfileAKS struct {
 	KubernetesVersion string                     `json:"kubernetesVersion"`
 	NodePools         map[string]*NodePoolCreate `json:"nodePools,omitempty"`
 }
+
+// ImportClusterAKS describes the Azure fields of an ImportCluster request
+type ImportClusterAKS struct {
+	ResourceGroup string `json:"resourceGroup" yaml:"resourceGroup"`
+}
+
+// Validate validates the aks cluster import request
+func (azure *ImportClusterAKS) Validate() error {
+	if azure == nil {
+		return pkgErrors.ErrorAzureFieldIsEmpty
+	}
+
+	if len(azure.ResourceGroup) == 0 {
+		return pkgErrors.ErrorResourceGroupRequired
+	}
+
+	return nil
+}
@@ -41,10 +41,11 @@ const (
 	Warning  = "WARNING"
 	Error    = "ERROR"
 
-	CreatingMessage = "Cluster is creating"
-	RunningMessage  = "Cluster is running"
-	UpdatingMessage = "Cluster is updating"
-	DeletingMessage = "Cluster is deleting"
+	CreatingMessage  = "Cluster is creating"
+	ImportingMessage = "Cluster is importing"
+	RunningMessage   = "Cluster is running"
+	UpdatingMessage  = "Cluster is updating"
+	DeletingMessage  = "Cluster is deleting"
 )
 
 // Cloud constants
@@ -115,13 +116,14 @@ const (
 type CreateClusterRequest struct {
 	Name         string                   `json:"name" yaml:"name" binding:"required"`
 	Location     string                   `json:"location" yaml:"location"`
-	Cloud        string                   `json:"cloud" yaml:"cloud" binding:"required"`
+	Cloud        string                   `json:"cloud" yaml:"cloud"`
 	SecretId     string                   `json:"secretId" yaml:"secretId"`
 	SecretIds    []string                 `json:"secretIds,omitempty" yaml:"secretIds,omitempty"`
 	SecretName   string                   `json:"secretName" yaml:"secretName"`
 	ProfileName  string                   `json:"profileName" yaml:"profileName"`
+	Template     *ClusterTemplateRef      `json:"template,omitempty" yaml:"template,omitempty"`
 	PostHooks    PostHooks                `json:"postHooks" yaml:"postHooks"`
-	Properties   *CreateClusterProperties `json:"properties" yaml:"properties" binding:"required"`
+	Properties   *CreateClusterProperties `json:"properties" yaml:"properties"`
 	ScaleOptions *ScaleOptions            `json:"scaleOptions,omitempty" yaml:"scaleOptions,omitempty"`
 	TtlMinutes   uint                     `json:"ttlMinutes,omitempty" yaml:"ttlMinutes,omitempty"`
 }
@@ -138,6 +140,22 @@ type CreateClusterProperties struct {
 	CreateClusterPKE        *pke.CreateClusterPKE               `json:"pke,omitempty" yaml:"pke,omitempty"`
 }
 
+// ImportClusterRequest describes an existing cluster of a cloud provider to be managed by Pipeline
+type ImportClusterRequest struct {
+	Name       string                   `json:"name" yaml:"name" binding:"required"`
+	Location   string                   `json:"location" yaml:"location"`
+	Cloud      string                   `json:"cloud" yaml:"cloud" binding:"required"`
+	SecretId   string                   `json:"secretId" yaml:"secretId"`
+	SecretName string                   `json:"secretName" yaml:"secretName"`
+	PostHooks  PostHooks                `json:"postHooks" yaml:"postHooks"`
+	Properties *ImportClusterProperties `json:"properties,omitempty" yaml:"properties,omitempty"`
+}
+
+// ImportClusterProperties contains the cloud specific properties needed to look up the cluster.
+type ImportClusterProperties struct {
+	ImportClusterAKS *aks.ImportClusterAKS `json:"aks,omitempty" yaml:"aks,omitempty"`
+}
+
 // ScaleOptions describes scale options
 type ScaleOptions struct {
 	Enabled             bool     `json:"enabled"`
@@ -162,13 +180,37 @@ type GenTLSForLogging struct {
 
 // LoggingParam describes the logging posthook params
 type LoggingParam struct {
-	BucketName       string           `json:"bucketName" binding:"required"`
+	BucketName       string           `json:"bucketName"`
 	Region           string           `json:"region"`
 	ResourceGroup    string           `json:"resourceGroup"`
 	StorageAccount   string           `json:"storageAccount"`
 	SecretId         string           `json:"secretId"`
 	SecretName       string           `json:"secretName"`
 	GenTLSForLogging GenTLSForLogging `json:"tls" binding:"required"`
+	Flows            []LoggingFlow    `json:"flows,omitempty"`
+}
+
+// LoggingFlow describes a log flow shipping the logs of the pods with a given app label to a destination.
+// The type of the destination secret determines the output: amazon, google, azure, alibaba and oracle secrets
+// ship the logs to object store buckets, elasticsearch, loki, kafka and syslog secrets to the respective services.
+type LoggingFlow struct {
+	Name string `json:"name" binding:"required"`
+	// AppLabel selects the pods by their app label, the logs of every pod are shipped if it is not set
+	AppLabel   string `json:"appLabel,omitempty"`
+	SecretId   string `json:"secretId,omitempty"`
+	SecretName string `json:"secretName,omitempty"`
+	// BucketName is the bucket (or Azure storage container) of object store outputs
+	BucketName string `json:"bucketName,omitempty"`
+	// Region is the region of the bucket, it is looked up for Amazon and Alibaba, and it defaults to the region of the secret for Oracle
+	Region         string `json:"region,omitempty"`
+	ResourceGroup  string `json:"resourceGroup,omitempty"`
+	StorageAccount string `json:"storageAccount,omitempty"`
+	// AccessSecretName is the name of a password secret holding the customer secret key of Oracle outputs (key ID as username, key as password)
+	AccessSecretName string `json:"accessSecretName,omitempty"`
+	// Index is the Elasticsearch index, daily logstash indices are used if it is not set
+	Index string `json:"index,omitempty"`
+	// Topic is the Kafka topic
+	Topic string `json:"topic,omitempty"`
 }
 
 // AnchoreParam describes the anchore posthook params
@@ -205,6 +247,9 @@ type GetClusterStatusResponse struct {
 	Region     string     `json:"region,omitempty"`
 	TtlMinutes uint       `json:"ttlMinutes,omitempty"`
 	StartedAt  *time.Time `json:"startedAt,omitempty"`
+
+	// Earliest expiry of the cluster certificates, if known
+	CertificatesExpireAt *time.Time `json:"certificatesExpireAt,omitempty"`
 }
 
 // NodePoolStatus describes cluster's node status
@@ -341,6 +386,10 @@ func (r *UpdateClusterRequest) String() string { // todo expand
 
 // AddDefaults puts default values to optional field(s)
 func (r *CreateClusterRequest) AddDefaults() error {
+	if r.Properties == nil {
+		return pkgErrors.ErrorClusterPropertiesRequired
+	}
+
 	switch r.Cloud {
 	case Amazon:
 		if r.Properties.CreateClusterPKE != nil {
@@ -368,8 +417,7 @@ func (r *CreateClusterRequest) Validate() error {
 	case Amazon:
 		// eks validate
 		if r.Properties.CreateClusterPKE != nil {
-			//r.Properties.CreateClusterPKE.Validate()
-			return nil
+			return r.Properties.CreateClusterPKE.Validate()
 		}
 		return r.Properties.CreateClusterEKS.Validate()
 	case Azure:
@@ -393,8 +441,30 @@ func (r *CreateClusterRequest) Validate() error {
 	}
 }
 
+// Validate checks the import cluster request
+func (r *ImportClusterRequest) Validate() error {
+	if len(r.Location) == 0 {
+		return pkgErrors.ErrorLocationEmpty
+	}
+
+	switch r.Cloud {
+	case Amazon, Google:
+		return nil
+	case Azure:
+		if r.Properties == nil {
+			return pkgErrors.ErrorAzureFieldIsEmpty
+		}
+		return r.Properties.ImportClusterAKS.Validate()
+	default:
+		return pkgErrors.ErrorClusterImportNotSupported
+	}
+}
+
 // validateMainFields checks the request's main fields
 func (r *CreateClusterRequest) validateMainFields() error {
+	if r.Properties == nil {
+		return pkgErrors.ErrorClusterPropertiesRequired
+	}
 	if r.Cloud != Kubernetes && r.Cloud != Alibaba {
 		if len(r.Location) == 0 {
 			return pkgErrors.ErrorLocationEmpty
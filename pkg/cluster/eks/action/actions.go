@@ -76,6 +76,7 @@ type EksClusterCreateUpdateContext struct {
 	ClusterUserSecretAccessKey string
 	RouteTableID               *string
 	ScaleEnabled               bool
+	PrivateNodes               bool
 }
 
 // NewEksClusterCreationContext creates a new EksClusterCreateUpdateContext
@@ -733,6 +734,33 @@ func WaitForASGToBeFulfilled(
 		FieldLogger: logger,
 	})
 	asgName := GenerateNodePoolStackName(clusterName, nodePoolName)
+
+	return waitForASGToBeFulfilled(logger, m.GetAutoscalingGroupByStackName, asgName, nodePoolName, waitAttempts, waitInterval)
+}
+
+// WaitForAdoptedASGToBeFulfilled waits until an ASG adopted at cluster import has the desired amount of healthy nodes
+func WaitForAdoptedASGToBeFulfilled(
+	awsSession *session.Session,
+	logger logrus.FieldLogger,
+	nodePool *model.AmazonNodePoolsModel,
+	waitAttempts int,
+	waitInterval time.Duration) error {
+
+	m := autoscaling.NewManager(awsSession, autoscaling.MetricsEnabled(true), autoscaling.Logger{
+		FieldLogger: logger,
+	})
+
+	return waitForASGToBeFulfilled(logger, m.GetAutoscalingGroupByID, nodePool.AutoScalingGroupName, nodePool.Name, waitAttempts, waitInterval)
+}
+
+func waitForASGToBeFulfilled(
+	logger logrus.FieldLogger,
+	getGroup func(string) (*autoscaling.Group, error),
+	asgName string,
+	nodePoolName string,
+	waitAttempts int,
+	waitInterval time.Duration) error {
+
 	log := logger.WithField("asg-name", asgName)
 	log.WithFields(logrus.Fields{
 		"attempts": waitAttempts,
@@ -740,7 +768,7 @@ func WaitForASGToBeFulfilled(
 	}).Info("EXECUTE WaitForASGToBeFulfilled")
 
 	for i := 0; i <= waitAttempts; i++ {
-		asGroup, err := m.GetAutoscalingGroupByStackName(asgName)
+		asGroup, err := getGroup(asgName)
 		if err != nil {
 			if aerr, ok := err.(awserr.Error); ok {
 				if aerr.Code() == "ValidationError" || aerr.Code() == "ASGNotFoundInResponse" {
@@ -881,6 +909,10 @@ func (a *CreateUpdateNodePoolStackAction) ExecuteAction(input interface{}) (outp
 					ParameterKey:   aws.String("TerminationDetachEnabled"),
 					ParameterValue: aws.String(fmt.Sprint(terminationDetachEnabled)),
 				},
+				{
+					ParameterKey:   aws.String("NodeAssociatePublicIpAddress"),
+					ParameterValue: aws.String(fmt.Sprint(!a.context.PrivateNodes)),
+				},
 			}
 
 			if a.isCreate {
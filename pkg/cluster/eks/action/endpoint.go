@@ -0,0 +1,124 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/banzaicloud/pipeline/utils"
+	"github.com/goph/emperror"
+	"github.com/sirupsen/logrus"
+)
+
+// The vendored EKS client predates cluster endpoint access control, so the
+// UpdateClusterConfig operation is described here by hand.
+type updateClusterConfigInput struct {
+	_ struct{} `type:"structure"`
+
+	Name *string `location:"uri" locationName:"name" type:"string" required:"true"`
+
+	ResourcesVpcConfig *endpointAccessVpcConfig `locationName:"resourcesVpcConfig" type:"structure"`
+}
+
+type endpointAccessVpcConfig struct {
+	_ struct{} `type:"structure"`
+
+	EndpointPrivateAccess *bool `locationName:"endpointPrivateAccess" type:"boolean"`
+
+	EndpointPublicAccess *bool `locationName:"endpointPublicAccess" type:"boolean"`
+
+	PublicAccessCidrs []*string `locationName:"publicAccessCidrs" type:"list"`
+}
+
+type updateClusterConfigOutput struct {
+	_ struct{} `type:"structure"`
+
+	Update *eks.Update `locationName:"update" type:"structure"`
+}
+
+var _ utils.Action = (*UpdateEksEndpointAccessAction)(nil)
+
+// UpdateEksEndpointAccessAction restricts the access to the Kubernetes API server endpoint of an EKS cluster
+type UpdateEksEndpointAccessAction struct {
+	context           *EksClusterCreateUpdateContext
+	privateAccess     bool
+	publicAccess      bool
+	publicAccessCIDRs []string
+	log               logrus.FieldLogger
+}
+
+// NewUpdateEksEndpointAccessAction creates a new UpdateEksEndpointAccessAction
+func NewUpdateEksEndpointAccessAction(log logrus.FieldLogger, context *EksClusterCreateUpdateContext, privateAccess, publicAccess bool, publicAccessCIDRs []string) *UpdateEksEndpointAccessAction {
+	return &UpdateEksEndpointAccessAction{
+		context:           context,
+		privateAccess:     privateAccess,
+		publicAccess:      publicAccess,
+		publicAccessCIDRs: publicAccessCIDRs,
+		log:               log,
+	}
+}
+
+// GetName returns the name of this UpdateEksEndpointAccessAction
+func (a *UpdateEksEndpointAccessAction) GetName() string {
+	return "UpdateEksEndpointAccessAction"
+}
+
+// ExecuteAction executes this UpdateEksEndpointAccessAction
+func (a *UpdateEksEndpointAccessAction) ExecuteAction(input interface{}) (output interface{}, err error) {
+	a.log.Infof("EXECUTE UpdateEksEndpointAccessAction, private: %t, public: %t, CIDRs: %v", a.privateAccess, a.publicAccess, a.publicAccessCIDRs)
+	eksSvc := eks.New(a.context.Session)
+
+	op := &request.Operation{
+		Name:       "UpdateClusterConfig",
+		HTTPMethod: "POST",
+		HTTPPath:   "/clusters/{name}/update-config",
+	}
+	updateInput := &updateClusterConfigInput{
+		Name: aws.String(a.context.ClusterName),
+		ResourcesVpcConfig: &endpointAccessVpcConfig{
+			EndpointPrivateAccess: aws.Bool(a.privateAccess),
+			EndpointPublicAccess:  aws.Bool(a.publicAccess),
+		},
+	}
+	if a.publicAccess && len(a.publicAccessCIDRs) > 0 {
+		updateInput.ResourcesVpcConfig.PublicAccessCidrs = aws.StringSlice(a.publicAccessCIDRs)
+	}
+	result := &updateClusterConfigOutput{}
+
+	err = eksSvc.NewRequest(op, updateInput, result).Send()
+	if err != nil {
+		return nil, emperror.Wrap(err, "could not update EKS cluster endpoint access")
+	}
+	if result.Update == nil {
+		return nil, nil
+	}
+
+	startTime := time.Now()
+	a.log.Info("Waiting for EKS cluster endpoint access update")
+	describeUpdateInput := &eks.DescribeUpdateInput{
+		Name:     aws.String(a.context.ClusterName),
+		UpdateId: result.Update.Id,
+	}
+	err = waitUntilEksUpdateComplete(eksSvc, aws.BackgroundContext(), describeUpdateInput)
+	if err != nil {
+		return nil, emperror.WrapWith(err, "EKS cluster endpoint access update failed", "updateId", aws.StringValue(result.Update.Id))
+	}
+	a.log.Infoln("EKS cluster endpoint access updated successfully in", time.Since(startTime).String())
+
+	return nil, nil
+}
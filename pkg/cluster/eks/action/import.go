@@ -0,0 +1,283 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsAutoscaling "github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/banzaicloud/pipeline/model"
+	"github.com/banzaicloud/pipeline/pkg/amazon"
+	"github.com/banzaicloud/pipeline/utils"
+	"github.com/goph/emperror"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	clusterAutoscalerEnabledTag  = "k8s.io/cluster-autoscaler/enabled"
+	clusterAutoscalerDisabledTag = "k8s.io/cluster-autoscaler/disabled"
+)
+
+var _ utils.RevocableAction = (*CreateClusterUserAction)(nil)
+
+// CreateClusterUserAction creates the IAM user of an imported EKS cluster,
+// created clusters get their user from the cluster stack
+type CreateClusterUserAction struct {
+	context *EksClusterCreateUpdateContext
+	created bool
+	log     logrus.FieldLogger
+}
+
+// NewCreateClusterUserAction creates a new CreateClusterUserAction
+func NewCreateClusterUserAction(log logrus.FieldLogger, context *EksClusterCreateUpdateContext) *CreateClusterUserAction {
+	return &CreateClusterUserAction{
+		context: context,
+		log:     log,
+	}
+}
+
+// GetName returns the name of this CreateClusterUserAction
+func (a *CreateClusterUserAction) GetName() string {
+	return "CreateClusterUserAction"
+}
+
+// ExecuteAction executes this CreateClusterUserAction
+func (a *CreateClusterUserAction) ExecuteAction(input interface{}) (output interface{}, err error) {
+	a.log.Infoln("EXECUTE CreateClusterUserAction, cluster user name:", a.context.ClusterName)
+
+	iamSvc := iam.New(a.context.Session)
+	clusterUserName := aws.String(a.context.ClusterName)
+
+	user, err := amazon.GetIAMUser(iamSvc, clusterUserName)
+	if err != nil {
+		return nil, emperror.WrapWith(err, "could not get cluster user", "user", a.context.ClusterName)
+	}
+
+	if user == nil {
+		result, err := iamSvc.CreateUser(&iam.CreateUserInput{UserName: clusterUserName})
+		if err != nil {
+			return nil, emperror.WrapWith(err, "could not create cluster user", "user", a.context.ClusterName)
+		}
+
+		user = result.User
+		a.created = true
+	}
+
+	a.context.ClusterUserArn = aws.StringValue(user.Arn)
+
+	return nil, nil
+}
+
+// UndoAction rolls back this CreateClusterUserAction
+func (a *CreateClusterUserAction) UndoAction() error {
+	if !a.created {
+		return nil
+	}
+
+	a.log.Infoln("EXECUTE UNDO CreateClusterUserAction, deleting cluster user:", a.context.ClusterName)
+
+	return amazon.DeleteIAMUser(iam.New(a.context.Session), aws.String(a.context.ClusterName))
+}
+
+// ---
+
+var _ utils.Action = (*DeleteClusterUserAction)(nil)
+
+// DeleteClusterUserAction deletes the IAM user of an imported EKS cluster
+type DeleteClusterUserAction struct {
+	context *EksClusterDeletionContext
+	log     logrus.FieldLogger
+}
+
+// NewDeleteClusterUserAction creates a new DeleteClusterUserAction
+func NewDeleteClusterUserAction(log logrus.FieldLogger, context *EksClusterDeletionContext) *DeleteClusterUserAction {
+	return &DeleteClusterUserAction{
+		context: context,
+		log:     log,
+	}
+}
+
+// GetName returns the name of this DeleteClusterUserAction
+func (a *DeleteClusterUserAction) GetName() string {
+	return "DeleteClusterUserAction"
+}
+
+// ExecuteAction executes this DeleteClusterUserAction
+func (a *DeleteClusterUserAction) ExecuteAction(input interface{}) (output interface{}, err error) {
+	a.log.Infoln("EXECUTE DeleteClusterUserAction, cluster user name:", a.context.ClusterName)
+
+	err = amazon.DeleteIAMUser(iam.New(a.context.Session), aws.String(a.context.ClusterName))
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == iam.ErrCodeNoSuchEntityException {
+		return nil, nil
+	}
+
+	return nil, emperror.WrapWith(err, "could not delete cluster user", "user", a.context.ClusterName)
+}
+
+// ---
+
+var _ utils.Action = (*UpdateAdoptedNodePoolsAction)(nil)
+
+// UpdateAdoptedNodePoolsAction resizes the auto scaling groups of node pools adopted at cluster import
+type UpdateAdoptedNodePoolsAction struct {
+	context      *EksClusterCreateUpdateContext
+	nodePools    []*model.AmazonNodePoolsModel
+	waitAttempts int
+	waitInterval time.Duration
+	log          logrus.FieldLogger
+}
+
+// NewUpdateAdoptedNodePoolsAction creates a new UpdateAdoptedNodePoolsAction
+func NewUpdateAdoptedNodePoolsAction(
+	log logrus.FieldLogger,
+	context *EksClusterCreateUpdateContext,
+	waitAttempts int,
+	waitInterval time.Duration,
+	nodePools ...*model.AmazonNodePoolsModel,
+) *UpdateAdoptedNodePoolsAction {
+	return &UpdateAdoptedNodePoolsAction{
+		context:      context,
+		nodePools:    nodePools,
+		waitAttempts: waitAttempts,
+		waitInterval: waitInterval,
+		log:          log,
+	}
+}
+
+// GetName returns the name of this UpdateAdoptedNodePoolsAction
+func (a *UpdateAdoptedNodePoolsAction) GetName() string {
+	return "UpdateAdoptedNodePoolsAction"
+}
+
+// ExecuteAction executes this UpdateAdoptedNodePoolsAction
+func (a *UpdateAdoptedNodePoolsAction) ExecuteAction(input interface{}) (output interface{}, err error) {
+	asSvc := awsAutoscaling.New(a.context.Session)
+
+	for _, nodePool := range a.nodePools {
+		a.log.Infof("EXECUTE UpdateAdoptedNodePoolsAction, node pool: %s, auto scaling group: %s", nodePool.Name, nodePool.AutoScalingGroupName)
+
+		_, err := asSvc.UpdateAutoScalingGroup(&awsAutoscaling.UpdateAutoScalingGroupInput{
+			AutoScalingGroupName: aws.String(nodePool.AutoScalingGroupName),
+			MinSize:              aws.Int64(int64(nodePool.NodeMinCount)),
+			MaxSize:              aws.Int64(int64(nodePool.NodeMaxCount)),
+			DesiredCapacity:      aws.Int64(int64(nodePool.Count)),
+		})
+		if err != nil {
+			return nil, emperror.WrapWith(err, "could not update auto scaling group", "nodePool", nodePool.Name, "asgName", nodePool.AutoScalingGroupName)
+		}
+
+		if err := a.tagClusterAutoscaler(asSvc, nodePool); err != nil {
+			return nil, err
+		}
+	}
+
+	errs := emperror.NewMultiErrorBuilder()
+	for _, nodePool := range a.nodePools {
+		errs.Add(WaitForAdoptedASGToBeFulfilled(a.context.Session, a.log, nodePool, a.waitAttempts, a.waitInterval))
+	}
+
+	return nil, errs.ErrOrNil()
+}
+
+// tagClusterAutoscaler tags the auto scaling group the same way as the node pool stacks do
+// to let the cluster autoscaler discover it
+func (a *UpdateAdoptedNodePoolsAction) tagClusterAutoscaler(asSvc *awsAutoscaling.AutoScaling, nodePool *model.AmazonNodePoolsModel) error {
+	addTag, removeTag := clusterAutoscalerDisabledTag, clusterAutoscalerEnabledTag
+	if a.context.ScaleEnabled || nodePool.Autoscaling {
+		addTag, removeTag = clusterAutoscalerEnabledTag, clusterAutoscalerDisabledTag
+	}
+
+	_, err := asSvc.CreateOrUpdateTags(&awsAutoscaling.CreateOrUpdateTagsInput{
+		Tags: []*awsAutoscaling.Tag{newAutoScalingGroupTag(nodePool.AutoScalingGroupName, addTag)},
+	})
+	if err != nil {
+		return emperror.WrapWith(err, "could not tag auto scaling group", "asgName", nodePool.AutoScalingGroupName, "tag", addTag)
+	}
+
+	_, err = asSvc.DeleteTags(&awsAutoscaling.DeleteTagsInput{
+		Tags: []*awsAutoscaling.Tag{newAutoScalingGroupTag(nodePool.AutoScalingGroupName, removeTag)},
+	})
+
+	return emperror.WrapWith(err, "could not untag auto scaling group", "asgName", nodePool.AutoScalingGroupName, "tag", removeTag)
+}
+
+func newAutoScalingGroupTag(asgName, key string) *awsAutoscaling.Tag {
+	return &awsAutoscaling.Tag{
+		ResourceId:        aws.String(asgName),
+		ResourceType:      aws.String("auto-scaling-group"),
+		Key:               aws.String(key),
+		Value:             aws.String("true"),
+		PropagateAtLaunch: aws.Bool(false),
+	}
+}
+
+// ---
+
+var _ utils.Action = (*DeleteAdoptedNodePoolsAction)(nil)
+
+// DeleteAdoptedNodePoolsAction deletes the auto scaling groups of node pools adopted at cluster import
+type DeleteAdoptedNodePoolsAction struct {
+	context           *EksClusterDeletionContext
+	autoScalingGroups []string
+	log               logrus.FieldLogger
+}
+
+// NewDeleteAdoptedNodePoolsAction creates a new DeleteAdoptedNodePoolsAction
+func NewDeleteAdoptedNodePoolsAction(log logrus.FieldLogger, context *EksClusterDeletionContext, autoScalingGroups ...string) *DeleteAdoptedNodePoolsAction {
+	return &DeleteAdoptedNodePoolsAction{
+		context:           context,
+		autoScalingGroups: autoScalingGroups,
+		log:               log,
+	}
+}
+
+// GetName returns the name of this DeleteAdoptedNodePoolsAction
+func (a *DeleteAdoptedNodePoolsAction) GetName() string {
+	return "DeleteAdoptedNodePoolsAction"
+}
+
+// ExecuteAction executes this DeleteAdoptedNodePoolsAction
+func (a *DeleteAdoptedNodePoolsAction) ExecuteAction(input interface{}) (output interface{}, err error) {
+	a.log.Infof("EXECUTE DeleteAdoptedNodePoolsAction: %q", a.autoScalingGroups)
+
+	asSvc := awsAutoscaling.New(a.context.Session)
+
+	for _, asgName := range a.autoScalingGroups {
+		_, err := asSvc.DeleteAutoScalingGroup(&awsAutoscaling.DeleteAutoScalingGroupInput{
+			AutoScalingGroupName: aws.String(asgName),
+			ForceDelete:          aws.Bool(true),
+		})
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "ValidationError" {
+			// the group does not exist anymore
+			continue
+		}
+		if err != nil {
+			return nil, emperror.WrapWith(err, "could not delete auto scaling group", "asgName", asgName)
+		}
+	}
+
+	if len(a.autoScalingGroups) == 0 {
+		return nil, nil
+	}
+
+	err = asSvc.WaitUntilGroupNotExists(&awsAutoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: aws.StringSlice(a.autoScalingGroups),
+	})
+
+	return nil, emperror.Wrap(err, "waiting for auto scaling groups to be deleted failed")
+}
@@ -0,0 +1,368 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awsAutoscaling "github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/pkg/k8sutil"
+	"github.com/banzaicloud/pipeline/pkg/providers/amazon/autoscaling"
+	"github.com/banzaicloud/pipeline/utils"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+const nodeDrainPollInterval = 5 * time.Second
+
+var _ utils.Action = (*UpdateEksControlPlaneVersionAction)(nil)
+
+// UpdateEksControlPlaneVersionAction upgrades the Kubernetes version of an EKS control plane
+type UpdateEksControlPlaneVersionAction struct {
+	context           *EksClusterCreateUpdateContext
+	kubernetesVersion string
+	log               logrus.FieldLogger
+}
+
+// NewUpdateEksControlPlaneVersionAction creates a new UpdateEksControlPlaneVersionAction
+func NewUpdateEksControlPlaneVersionAction(log logrus.FieldLogger, context *EksClusterCreateUpdateContext, kubernetesVersion string) *UpdateEksControlPlaneVersionAction {
+	return &UpdateEksControlPlaneVersionAction{
+		context:           context,
+		kubernetesVersion: kubernetesVersion,
+		log:               log,
+	}
+}
+
+// GetName returns the name of this UpdateEksControlPlaneVersionAction
+func (a *UpdateEksControlPlaneVersionAction) GetName() string {
+	return "UpdateEksControlPlaneVersionAction"
+}
+
+// ExecuteAction executes this UpdateEksControlPlaneVersionAction
+func (a *UpdateEksControlPlaneVersionAction) ExecuteAction(input interface{}) (output interface{}, err error) {
+	a.log.Infoln("EXECUTE UpdateEksControlPlaneVersionAction, version:", a.kubernetesVersion)
+	eksSvc := eks.New(a.context.Session)
+
+	result, err := eksSvc.UpdateClusterVersion(&eks.UpdateClusterVersionInput{
+		Name:    aws.String(a.context.ClusterName),
+		Version: aws.String(a.kubernetesVersion),
+	})
+	if err != nil {
+		return nil, emperror.WrapWith(err, "could not update EKS cluster version", "version", a.kubernetesVersion)
+	}
+
+	startTime := time.Now()
+	a.log.Info("Waiting for EKS cluster version update")
+	describeUpdateInput := &eks.DescribeUpdateInput{
+		Name:     aws.String(a.context.ClusterName),
+		UpdateId: result.Update.Id,
+	}
+	err = waitUntilEksUpdateComplete(eksSvc, aws.BackgroundContext(), describeUpdateInput)
+	if err != nil {
+		return nil, emperror.WrapWith(err, "EKS cluster version update failed", "updateId", aws.StringValue(result.Update.Id))
+	}
+	a.log.Infoln("EKS cluster version updated successfully in", time.Since(startTime).String())
+
+	return nil, nil
+}
+
+// waitUntilEksUpdateComplete waits until an EKS cluster update reaches a final state
+func waitUntilEksUpdateComplete(eksSvc *eks.EKS, ctx aws.Context, input *eks.DescribeUpdateInput, opts ...request.WaiterOption) error {
+	w := request.Waiter{
+		Name:        "WaitUntilUpdateComplete",
+		MaxAttempts: 120,
+		Delay:       request.ConstantWaiterDelay(30 * time.Second),
+		Acceptors: []request.WaiterAcceptor{
+			{
+				State:   request.SuccessWaiterState,
+				Matcher: request.PathWaiterMatch, Argument: "Update.Status",
+				Expected: eks.UpdateStatusSuccessful,
+			},
+			{
+				State:   request.FailureWaiterState,
+				Matcher: request.PathWaiterMatch, Argument: "Update.Status",
+				Expected: eks.UpdateStatusFailed,
+			},
+			{
+				State:   request.FailureWaiterState,
+				Matcher: request.PathWaiterMatch, Argument: "Update.Status",
+				Expected: eks.UpdateStatusCancelled,
+			},
+		},
+		Logger: eksSvc.Config.Logger,
+		NewRequest: func(opts []request.Option) (*request.Request, error) {
+			var inCpy *eks.DescribeUpdateInput
+			if input != nil {
+				tmp := *input
+				inCpy = &tmp
+			}
+			req, _ := eksSvc.DescribeUpdateRequest(inCpy)
+			req.SetContext(ctx)
+			req.ApplyOptions(opts...)
+			return req, nil
+		},
+	}
+	w.ApplyOptions(opts...)
+
+	return w.WaitWithContext(ctx)
+}
+
+// ---
+
+var _ utils.Action = (*RollNodePoolInstancesAction)(nil)
+
+// RollNodePoolInstancesAction replaces the instances of node pools which are not running
+// the current launch configuration of their auto scaling group.
+// Instances are replaced in batches of surge size: new instances are launched first,
+// then the outdated nodes are cordoned, drained and terminated.
+type RollNodePoolInstancesAction struct {
+	context      *EksClusterCreateUpdateContext
+	client       kubernetes.Interface
+	nodePools    []string
+	surge        int
+	drainTimeout time.Duration
+	waitAttempts int
+	waitInterval time.Duration
+	log          logrus.FieldLogger
+}
+
+// NewRollNodePoolInstancesAction creates a new RollNodePoolInstancesAction
+func NewRollNodePoolInstancesAction(
+	log logrus.FieldLogger,
+	context *EksClusterCreateUpdateContext,
+	client kubernetes.Interface,
+	surge int,
+	drainTimeout time.Duration,
+	waitAttempts int,
+	waitInterval time.Duration,
+	nodePools ...string) *RollNodePoolInstancesAction {
+	return &RollNodePoolInstancesAction{
+		context:      context,
+		client:       client,
+		nodePools:    nodePools,
+		surge:        surge,
+		drainTimeout: drainTimeout,
+		waitAttempts: waitAttempts,
+		waitInterval: waitInterval,
+		log:          log,
+	}
+}
+
+// GetName returns the name of this RollNodePoolInstancesAction
+func (a *RollNodePoolInstancesAction) GetName() string {
+	return "RollNodePoolInstancesAction"
+}
+
+// ExecuteAction executes this RollNodePoolInstancesAction sequentially for each node pool
+func (a *RollNodePoolInstancesAction) ExecuteAction(input interface{}) (output interface{}, err error) {
+	for _, nodePool := range a.nodePools {
+		if err := a.rollNodePool(nodePool); err != nil {
+			return nil, emperror.WrapWith(err, "failed to roll node pool instances", "nodePool", nodePool)
+		}
+	}
+
+	return nil, nil
+}
+
+func (a *RollNodePoolInstancesAction) rollNodePool(nodePool string) error {
+	log := a.log.WithField("nodePool", nodePool)
+	autoscalingSrv := awsAutoscaling.New(a.context.Session)
+
+	group, err := a.getAutoScalingGroup(nodePool)
+	if err != nil {
+		return err
+	}
+
+	outdated := outdatedInstances(group)
+	if len(outdated) == 0 {
+		log.Info("all instances are up to date")
+		return nil
+	}
+
+	log.Infof("replacing %d outdated instances", len(outdated))
+
+	desiredCapacity := aws.Int64Value(group.DesiredCapacity)
+	maxSize := aws.Int64Value(group.MaxSize)
+
+	// the original max size is restored even if the rolling update fails
+	defer func() {
+		_, err := autoscalingSrv.UpdateAutoScalingGroup(&awsAutoscaling.UpdateAutoScalingGroupInput{
+			AutoScalingGroupName: group.AutoScalingGroupName,
+			MaxSize:              aws.Int64(maxSize),
+		})
+		if err != nil {
+			log.Errorf("failed to restore max size of ASG: %s", err.Error())
+		}
+	}()
+
+	for len(outdated) > 0 {
+		batch := outdated
+		if len(batch) > a.surge {
+			batch = batch[:a.surge]
+		}
+		surge := int64(len(batch))
+
+		updateInput := &awsAutoscaling.UpdateAutoScalingGroupInput{
+			AutoScalingGroupName: group.AutoScalingGroupName,
+			DesiredCapacity:      aws.Int64(desiredCapacity + surge),
+		}
+		if desiredCapacity+surge > maxSize {
+			updateInput.MaxSize = aws.Int64(desiredCapacity + surge)
+		}
+
+		_, err := autoscalingSrv.UpdateAutoScalingGroup(updateInput)
+		if err != nil {
+			return emperror.Wrap(err, "failed to increase ASG capacity")
+		}
+
+		err = WaitForASGToBeFulfilled(a.context.Session, a.log, a.context.ClusterName, nodePool, a.waitAttempts, a.waitInterval)
+		if err != nil {
+			return err
+		}
+
+		group, err = a.getAutoScalingGroup(nodePool)
+		if err != nil {
+			return err
+		}
+
+		if err := a.waitForNodesToBeReady(nodePool, group); err != nil {
+			return err
+		}
+
+		for _, instanceID := range batch {
+			if err := a.replaceInstance(autoscalingSrv, nodePool, instanceID); err != nil {
+				return err
+			}
+
+			log.Infof("instance %s replaced", instanceID)
+		}
+
+		outdated = outdated[len(batch):]
+	}
+
+	return nil
+}
+
+func (a *RollNodePoolInstancesAction) getAutoScalingGroup(nodePool string) (*awsAutoscaling.Group, error) {
+	m := autoscaling.NewManager(a.context.Session, autoscaling.Logger{
+		FieldLogger: a.log,
+	})
+	asgName := GenerateNodePoolStackName(a.context.ClusterName, nodePool)
+
+	group, err := m.GetAutoscalingGroupByStackName(asgName)
+	if err != nil {
+		return nil, emperror.WrapWith(err, "could not get ASG", "asg-name", asgName)
+	}
+
+	return group.Group, nil
+}
+
+// waitForNodesToBeReady waits until every instance running the current launch configuration joined the cluster as a ready node
+func (a *RollNodePoolInstancesAction) waitForNodesToBeReady(nodePool string, group *awsAutoscaling.Group) error {
+	selector := labels.SelectorFromSet(labels.Set{common.LabelKey: nodePool}).String()
+
+	for i := 0; i <= a.waitAttempts; i++ {
+		nodes, err := a.client.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return emperror.Wrapf(err, "failed to list nodes of pool %q", nodePool)
+		}
+
+		ready := true
+		for _, instance := range group.Instances {
+			if isInstanceOutdated(group, instance) {
+				continue
+			}
+
+			node := findNodeOfInstance(nodes.Items, aws.StringValue(instance.InstanceId))
+			if node == nil || !k8sutil.IsNodeReady(node) {
+				ready = false
+				break
+			}
+		}
+
+		if ready {
+			return nil
+		}
+
+		time.Sleep(a.waitInterval)
+	}
+
+	return errors.Errorf("timeout waiting for the new nodes of pool %q to be ready", nodePool)
+}
+
+// replaceInstance drains the node of an instance and terminates it while decrementing the desired capacity of the ASG
+func (a *RollNodePoolInstancesAction) replaceInstance(autoscalingSrv *awsAutoscaling.AutoScaling, nodePool, instanceID string) error {
+	selector := labels.SelectorFromSet(labels.Set{common.LabelKey: nodePool}).String()
+
+	nodes, err := a.client.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return emperror.Wrapf(err, "failed to list nodes of pool %q", nodePool)
+	}
+
+	// the instance may not have joined the cluster at all, in that case there is nothing to drain
+	if node := findNodeOfInstance(nodes.Items, instanceID); node != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), a.drainTimeout)
+		defer cancel()
+
+		if err := k8sutil.DrainNode(ctx, a.client, node.Name, nodeDrainPollInterval); err != nil {
+			return err
+		}
+	}
+
+	_, err = autoscalingSrv.TerminateInstanceInAutoScalingGroup(&awsAutoscaling.TerminateInstanceInAutoScalingGroupInput{
+		InstanceId:                     aws.String(instanceID),
+		ShouldDecrementDesiredCapacity: aws.Bool(true),
+	})
+
+	return emperror.Wrapf(err, "failed to terminate instance %s", instanceID)
+}
+
+// outdatedInstances returns the IDs of the instances which were not launched with the current launch configuration of the ASG
+func outdatedInstances(group *awsAutoscaling.Group) []string {
+	var instanceIDs []string
+	for _, instance := range group.Instances {
+		if isInstanceOutdated(group, instance) {
+			instanceIDs = append(instanceIDs, aws.StringValue(instance.InstanceId))
+		}
+	}
+
+	return instanceIDs
+}
+
+func isInstanceOutdated(group *awsAutoscaling.Group, instance *awsAutoscaling.Instance) bool {
+	return aws.StringValue(instance.LaunchConfigurationName) != aws.StringValue(group.LaunchConfigurationName)
+}
+
+// findNodeOfInstance returns the node of an EC2 instance based on its provider ID which looks like aws:///eu-west-1a/i-0123456789abcdef0
+func findNodeOfInstance(nodes []corev1.Node, instanceID string) *corev1.Node {
+	for i := range nodes {
+		if strings.HasSuffix(nodes[i].Spec.ProviderID, "/"+instanceID) {
+			return &nodes[i]
+		}
+	}
+
+	return nil
+}
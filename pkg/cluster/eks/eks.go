@@ -15,24 +15,37 @@
 package eks
 
 import (
+	"github.com/Masterminds/semver"
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
 	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
 )
 
 // CreateClusterEKS describes Pipeline's Amazon EKS fields of a CreateCluster request
 type CreateClusterEKS struct {
-	Version      string               `json:"version,omitempty" yaml:"version,omitempty"`
-	NodePools    map[string]*NodePool `json:"nodePools,omitempty" yaml:"nodePools,omitempty"`
-	Vpc          *ClusterVPC          `json:"vpc,omitempty" yaml:"vpc,omitempty"`
-	RouteTableId string               `json:"routeTableId,omitempty" yaml:"routeTableId,omitempty"`
-	Subnets      []*ClusterSubnet     `json:"subnets,omitempty" yaml:"subnets,omitempty"`
+	Version         string                     `json:"version,omitempty" yaml:"version,omitempty"`
+	NodePools       map[string]*NodePool       `json:"nodePools,omitempty" yaml:"nodePools,omitempty"`
+	Vpc             *ClusterVPC                `json:"vpc,omitempty" yaml:"vpc,omitempty"`
+	RouteTableId    string                     `json:"routeTableId,omitempty" yaml:"routeTableId,omitempty"`
+	Subnets         []*ClusterSubnet           `json:"subnets,omitempty" yaml:"subnets,omitempty"`
+	APIServerAccess *pkgCommon.APIServerAccess `json:"apiServerAccess,omitempty" yaml:"apiServerAccess,omitempty"`
 }
 
 // UpdateClusterAmazonEKS describes Amazon EKS's node fields of an UpdateCluster request
 type UpdateClusterAmazonEKS struct {
-	NodePools map[string]*NodePool `json:"nodePools,omitempty"`
+	Version         string               `json:"version,omitempty"`
+	NodePools       map[string]*NodePool `json:"nodePools,omitempty"`
+	NodeImageUpdate *NodeImageUpdate     `json:"nodeImageUpdate,omitempty"`
+}
+
+// NodeImageUpdate describes how the nodes are replaced when the image of node pools changes
+type NodeImageUpdate struct {
+	// Surge is the number of extra nodes launched in a node pool before outdated nodes are drained and terminated
+	Surge int `json:"surge,omitempty"`
 }
 
+// DefaultNodeImageUpdateSurge is the number of nodes replaced at once by default
+const DefaultNodeImageUpdateSurge = 1
+
 // NodePool describes Amazon's node fields of a CreateCluster/Update request
 type NodePool struct {
 	InstanceType string            `json:"instanceType" yaml:"instanceType"`
@@ -172,6 +185,23 @@ func (eks *CreateClusterEKS) Validate() error {
 		}
 	}
 
+	if err := eks.APIServerAccess.Validate(); err != nil {
+		return err
+	}
+
+	// nodes without public IP addresses need existing subnets routed through a NAT gateway
+	if eks.APIServerAccess != nil && eks.APIServerAccess.PrivateNodes {
+		if eks.Vpc == nil || len(eks.Vpc.VpcId) == 0 || len(eks.Subnets) == 0 {
+			return pkgErrors.ErrorEksPrivateNodesRequireExistingSubnets
+		}
+
+		for _, subnet := range eks.Subnets {
+			if subnet == nil || len(subnet.SubnetId) == 0 {
+				return pkgErrors.ErrorEksPrivateNodesRequireExistingSubnets
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -222,6 +252,15 @@ func (eks *UpdateClusterAmazonEKS) Validate() error {
 		return pkgErrors.ErrorAmazonEksFieldIsEmpty
 	}
 
+	// validate K8s version
+	if !isValidVersion(eks.Version) {
+		return pkgErrors.ErrorNotValidKubernetesVersion
+	}
+
+	if eks.NodeImageUpdate != nil && eks.NodeImageUpdate.Surge < 0 {
+		return pkgErrors.ErrorNotValidNodeImageUpdateSurge
+	}
+
 	for _, np := range eks.NodePools {
 		if err := np.ValidateForUpdate(); err != nil {
 			return err
@@ -231,6 +270,30 @@ func (eks *UpdateClusterAmazonEKS) Validate() error {
 	return nil
 }
 
+// ValidateVersionUpgrade checks whether a cluster running the current Kubernetes version can be upgraded to the target one.
+// EKS only supports upgrading to the next minor version.
+func ValidateVersionUpgrade(current, target string) error {
+	if current == target {
+		return nil
+	}
+
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return pkgErrors.ErrorNotValidKubernetesVersion
+	}
+
+	targetVersion, err := semver.NewVersion(target)
+	if err != nil {
+		return pkgErrors.ErrorNotValidKubernetesVersion
+	}
+
+	if targetVersion.Major() != currentVersion.Major() || targetVersion.Minor() != currentVersion.Minor()+1 {
+		return pkgErrors.ErrorNotSupportedKubernetesVersionUpgrade
+	}
+
+	return nil
+}
+
 // isValidVersion validates the given K8S version
 func isValidVersion(version string) bool {
 	if len(version) == 0 {
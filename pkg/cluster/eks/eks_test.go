@@ -0,0 +1,46 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eks
+
+import (
+	"testing"
+
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+)
+
+func TestValidateVersionUpgrade(t *testing.T) {
+	tests := map[string]struct {
+		current string
+		target  string
+		err     error
+	}{
+		"same version":       {current: "1.10", target: "1.10"},
+		"next minor version": {current: "1.10", target: "1.11"},
+		"skipped version":    {current: "1.10", target: "1.12", err: pkgErrors.ErrorNotSupportedKubernetesVersionUpgrade},
+		"downgrade":          {current: "1.11", target: "1.10", err: pkgErrors.ErrorNotSupportedKubernetesVersionUpgrade},
+		"invalid version":    {current: "1.10", target: "latest", err: pkgErrors.ErrorNotValidKubernetesVersion},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			err := ValidateVersionUpgrade(test.current, test.target)
+			if err != test.err {
+				t.Errorf("expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package gke
 
 import (
+	"net"
 	"regexp"
 
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
@@ -24,17 +25,20 @@ import (
 
 // ### [ Constants to Google cluster default values ] ### //
 const (
-	DefaultNodePoolName = "default-pool"
+	DefaultNodePoolName   = "default-pool"
+	DefaultMasterIPv4CIDR = "172.16.0.0/28"
 )
 
 // CreateClusterGKE describes Pipeline's Google fields of a CreateCluster request
 type CreateClusterGKE struct {
-	NodeVersion string               `json:"nodeVersion,omitempty" yaml:"nodeVersion,omitempty"`
-	NodePools   map[string]*NodePool `json:"nodePools,omitempty" yaml:"nodePools,omitempty"`
-	Master      *Master              `json:"master,omitempty" yaml:"master,omitempty"`
-	Vpc         string               `json:"vpc,omitempty" yaml:"vpc,omitempty"`
-	Subnet      string               `json:"subnet,omitempty" yaml:"subnet,omitempty"`
-	ProjectId   string               `json:"projectId" yaml:"projectId"`
+	NodeVersion     string                     `json:"nodeVersion,omitempty" yaml:"nodeVersion,omitempty"`
+	NodePools       map[string]*NodePool       `json:"nodePools,omitempty" yaml:"nodePools,omitempty"`
+	Master          *Master                    `json:"master,omitempty" yaml:"master,omitempty"`
+	Vpc             string                     `json:"vpc,omitempty" yaml:"vpc,omitempty"`
+	Subnet          string                     `json:"subnet,omitempty" yaml:"subnet,omitempty"`
+	ProjectId       string                     `json:"projectId" yaml:"projectId"`
+	APIServerAccess *pkgCommon.APIServerAccess `json:"apiServerAccess,omitempty" yaml:"apiServerAccess,omitempty"`
+	MasterIPv4CIDR  string                     `json:"masterIpv4Cidr,omitempty" yaml:"masterIpv4Cidr,omitempty"`
 }
 
 // Master describes Google's master fields of a CreateCluster request
@@ -99,6 +103,25 @@ func (g *CreateClusterGKE) Validate() error {
 		return pkgErrors.ErrorGkeVPCRequiredFieldIsEmpty
 	}
 
+	if err := g.APIServerAccess.Validate(); err != nil {
+		return err
+	}
+
+	// the private endpoint of the master is only available for clusters with private nodes
+	if g.APIServerAccess != nil && g.APIServerAccess.PrivateEndpoint && !g.APIServerAccess.PrivateNodes {
+		return pkgErrors.ErrorGkePrivateEndpointWithoutPrivateNodes
+	}
+
+	if g.APIServerAccess != nil && g.APIServerAccess.PrivateNodes && len(g.MasterIPv4CIDR) == 0 {
+		g.MasterIPv4CIDR = DefaultMasterIPv4CIDR
+	}
+
+	if len(g.MasterIPv4CIDR) > 0 {
+		if _, ipNet, err := net.ParseCIDR(g.MasterIPv4CIDR); err != nil || ipNet.Mask.String() != net.CIDRMask(28, 32).String() {
+			return pkgErrors.ErrorGkeNotValidMasterIPv4CIDR
+		}
+	}
+
 	for _, nodePool := range g.NodePools {
 
 		// ---- [ Min & Max count fields are required in case of auto scaling ] ---- //
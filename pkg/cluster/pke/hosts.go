@@ -0,0 +1,92 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// UsesHosts returns true if the cluster is installed on pre-provisioned hosts instead of cloud instances.
+func (pke *CreateClusterPKE) UsesHosts() bool {
+	for _, np := range pke.NodePools {
+		if np.Provider == NPPHosts {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateHosts checks the node pools of clusters installed on pre-provisioned hosts.
+// Hosts cannot be mixed with cloud instances, as the cloud provider integration of Kubernetes would remove the nodes it does not know about.
+func (pke *CreateClusterPKE) validateHosts() error {
+	if !pke.UsesHosts() {
+		return nil
+	}
+
+	names := make(map[string]bool)
+	addresses := make(map[string]bool)
+
+	for _, np := range pke.NodePools {
+		if np.Provider != NPPHosts {
+			return errors.Errorf("node pool %q: %q node pools cannot be mixed with %q node pools", np.Name, np.Provider, NPPHosts)
+		}
+
+		if np.Autoscaling {
+			return errors.Errorf("node pool %q: node pools of pre-provisioned hosts cannot be autoscaled", np.Name)
+		}
+
+		if len(np.Hosts) == 0 {
+			return errors.Errorf("node pool %q: at least one host is required", np.Name)
+		}
+
+		for _, host := range np.Hosts {
+			if host.Name == "" {
+				return errors.Errorf("node pool %q: host name is required", np.Name)
+			}
+			if names[host.Name] {
+				return errors.Errorf("node pool %q: duplicate host name %q", np.Name, host.Name)
+			}
+			names[host.Name] = true
+
+			if ip := net.ParseIP(host.PrivateIP); ip == nil || ip.To4() == nil {
+				return errors.Errorf("node pool %q: invalid IPv4 address %q of host %q", np.Name, host.PrivateIP, host.Name)
+			}
+			if addresses[host.PrivateIP] {
+				return errors.Errorf("node pool %q: duplicate host address %q", np.Name, host.PrivateIP)
+			}
+			addresses[host.PrivateIP] = true
+		}
+
+		if !np.Roles.Contains(RoleMaster) {
+			continue
+		}
+
+		switch len(np.Hosts) {
+		case 1:
+		case 3, 5:
+			// the hosts of a highly available control plane are reached through a load balancer set up by the user
+			if pke.Network.APIServerAddress == "" {
+				return errors.Errorf("node pool %q: the API server address of the load balancer in front of the master hosts is required", np.Name)
+			}
+		default:
+			return errors.Errorf("master node pool %q must have 1, 3 or 5 hosts, got %d", np.Name, len(np.Hosts))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"testing"
+)
+
+func TestCreateClusterPKE_ValidateHosts(t *testing.T) {
+	hosts := func(addresses ...string) Hosts {
+		var hosts Hosts
+		for _, address := range addresses {
+			hosts = append(hosts, Host{Name: "host-" + address, PrivateIP: address})
+		}
+		return hosts
+	}
+
+	tests := map[string]struct {
+		request CreateClusterPKE
+		valid   bool
+	}{
+		"single master": {
+			request: CreateClusterPKE{
+				NodePools: NodePools{
+					{Name: "master", Roles: Roles{RoleMaster}, Provider: NPPHosts, Hosts: hosts("192.168.1.10")},
+					{Name: "pool1", Roles: Roles{RoleWorker}, Provider: NPPHosts, Hosts: hosts("192.168.1.11", "192.168.1.12")},
+				},
+			},
+			valid: true,
+		},
+		"highly available masters without load balancer": {
+			request: CreateClusterPKE{
+				NodePools: NodePools{
+					{Name: "master", Roles: Roles{RoleMaster}, Provider: NPPHosts, Hosts: hosts("192.168.1.10", "192.168.1.11", "192.168.1.12")},
+				},
+			},
+		},
+		"highly available masters": {
+			request: CreateClusterPKE{
+				Network: Network{APIServerAddress: "k8s.example.com"},
+				NodePools: NodePools{
+					{Name: "master", Roles: Roles{RoleMaster}, Provider: NPPHosts, Hosts: hosts("192.168.1.10", "192.168.1.11", "192.168.1.12")},
+				},
+			},
+			valid: true,
+		},
+		"mixed providers": {
+			request: CreateClusterPKE{
+				NodePools: NodePools{
+					{Name: "master", Roles: Roles{RoleMaster}, Provider: NPPHosts, Hosts: hosts("192.168.1.10")},
+					{Name: "pool1", Roles: Roles{RoleWorker}, Provider: NPPAmazon},
+				},
+			},
+		},
+		"duplicate address": {
+			request: CreateClusterPKE{
+				NodePools: NodePools{
+					{Name: "master", Roles: Roles{RoleMaster}, Provider: NPPHosts, Hosts: hosts("192.168.1.10")},
+					{Name: "pool1", Roles: Roles{RoleWorker}, Provider: NPPHosts, Hosts: hosts("192.168.1.11", "192.168.1.10")},
+				},
+			},
+		},
+		"invalid address": {
+			request: CreateClusterPKE{
+				NodePools: NodePools{
+					{Name: "master", Roles: Roles{RoleMaster}, Provider: NPPHosts, Hosts: hosts("node-1")},
+				},
+			},
+		},
+		"empty pool": {
+			request: CreateClusterPKE{
+				NodePools: NodePools{
+					{Name: "master", Roles: Roles{RoleMaster}, Provider: NPPHosts, Hosts: hosts("192.168.1.10")},
+					{Name: "pool1", Roles: Roles{RoleWorker}, Provider: NPPHosts},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			if err := test.request.AddDefaults(); err != nil {
+				t.Fatal(err)
+			}
+
+			err := test.request.Validate()
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if !test.valid && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"net"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+type NetworkProvider string
+
+const (
+	NPWeave  NetworkProvider = "weave"
+	NPCalico NetworkProvider = "calico"
+	NPCilium NetworkProvider = "cilium"
+)
+
+// Calico IP-in-IP encapsulation modes
+const (
+	CalicoIPIPModeAlways      = "Always"
+	CalicoIPIPModeCrossSubnet = "CrossSubnet"
+	CalicoIPIPModeNever       = "Never"
+)
+
+// Cilium tunneling modes
+const (
+	CiliumTunnelVXLAN    = "vxlan"
+	CiliumTunnelGeneve   = "geneve"
+	CiliumTunnelDisabled = "disabled"
+)
+
+const (
+	defaultCalicoBlockSize        = 26
+	defaultCiliumNodeCIDRMaskSize = 24
+
+	minMTU = 576
+	maxMTU = 9001
+)
+
+// CalicoProviderConfig describes the settings of the Calico network provider.
+type CalicoProviderConfig struct {
+	// IPIPMode is the IP-in-IP encapsulation mode of the pod network, pods are routed by BGP when it is Never.
+	IPIPMode string `json:"ipipMode" yaml:"ipipMode"`
+	// BlockSize is the prefix length of the pod address blocks assigned to nodes.
+	BlockSize int `json:"blockSize" yaml:"blockSize"`
+	MTU       int `json:"mtu,omitempty" yaml:"mtu,omitempty"`
+}
+
+// CiliumProviderConfig describes the settings of the Cilium network provider.
+type CiliumProviderConfig struct {
+	// Tunnel is the encapsulation mode of the pod network, pods are routed natively when it is disabled.
+	Tunnel string `json:"tunnel" yaml:"tunnel"`
+	// NodeCIDRMaskSize is the prefix length of the pod CIDRs assigned to nodes.
+	NodeCIDRMaskSize int `json:"nodeCIDRMaskSize" yaml:"nodeCIDRMaskSize"`
+	MTU              int `json:"mtu,omitempty" yaml:"mtu,omitempty"`
+}
+
+// DecodeCalicoProviderConfig decodes the Calico settings of a network and fills in the defaults.
+func DecodeCalicoProviderConfig(providerConfig map[string]interface{}) (CalicoProviderConfig, error) {
+	config := CalicoProviderConfig{
+		IPIPMode:  CalicoIPIPModeAlways,
+		BlockSize: defaultCalicoBlockSize,
+	}
+
+	return config, decodeProviderConfig(providerConfig, &config)
+}
+
+// DecodeCiliumProviderConfig decodes the Cilium settings of a network and fills in the defaults.
+func DecodeCiliumProviderConfig(providerConfig map[string]interface{}) (CiliumProviderConfig, error) {
+	config := CiliumProviderConfig{
+		Tunnel:           CiliumTunnelVXLAN,
+		NodeCIDRMaskSize: defaultCiliumNodeCIDRMaskSize,
+	}
+
+	return config, decodeProviderConfig(providerConfig, &config)
+}
+
+func decodeProviderConfig(providerConfig map[string]interface{}, config interface{}) error {
+	if len(providerConfig) == 0 {
+		return nil
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused: true,
+		Result:      config,
+	})
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(decoder.Decode(providerConfig), "invalid network provider config")
+}
+
+// Validate checks that the network provider supports the requested settings and address ranges.
+func (n Network) Validate() error {
+	podNet, err := parseIPv4CIDR("pod", n.PodCIDR)
+	if err != nil {
+		return err
+	}
+
+	serviceNet, err := parseIPv4CIDR("service", n.ServiceCIDR)
+	if err != nil {
+		return err
+	}
+
+	if podNet != nil && serviceNet != nil && (podNet.Contains(serviceNet.IP) || serviceNet.Contains(podNet.IP)) {
+		return errors.Errorf("pod CIDR %s and service CIDR %s overlap", n.PodCIDR, n.ServiceCIDR)
+	}
+
+	switch n.Provider {
+	case NPWeave, "":
+		if len(n.ProviderConfig) > 0 {
+			return errors.Errorf("network provider %q has no settings", NPWeave)
+		}
+
+	case NPCalico:
+		config, err := DecodeCalicoProviderConfig(n.ProviderConfig)
+		if err != nil {
+			return err
+		}
+
+		switch config.IPIPMode {
+		case CalicoIPIPModeAlways, CalicoIPIPModeCrossSubnet, CalicoIPIPModeNever:
+		default:
+			return errors.Errorf("invalid Calico IP-in-IP mode %q", config.IPIPMode)
+		}
+
+		// Calico IPAM accepts block sizes between /20 and /32
+		if config.BlockSize < 20 || config.BlockSize > 32 {
+			return errors.Errorf("Calico block size must be between 20 and 32, got %d", config.BlockSize)
+		}
+
+		if err := validatePodCIDRSplit(podNet, config.BlockSize, "Calico block size"); err != nil {
+			return err
+		}
+
+		if err := validateMTU(config.MTU); err != nil {
+			return err
+		}
+
+	case NPCilium:
+		config, err := DecodeCiliumProviderConfig(n.ProviderConfig)
+		if err != nil {
+			return err
+		}
+
+		switch config.Tunnel {
+		case CiliumTunnelVXLAN, CiliumTunnelGeneve, CiliumTunnelDisabled:
+		default:
+			return errors.Errorf("invalid Cilium tunnel mode %q", config.Tunnel)
+		}
+
+		if config.NodeCIDRMaskSize < 16 || config.NodeCIDRMaskSize > 28 {
+			return errors.Errorf("Cilium node CIDR mask size must be between 16 and 28, got %d", config.NodeCIDRMaskSize)
+		}
+
+		if err := validatePodCIDRSplit(podNet, config.NodeCIDRMaskSize, "Cilium node CIDR mask size"); err != nil {
+			return err
+		}
+
+		if err := validateMTU(config.MTU); err != nil {
+			return err
+		}
+
+	default:
+		return errors.Errorf("unsupported network provider %q", n.Provider)
+	}
+
+	return nil
+}
+
+// parseIPv4CIDR parses an optional IPv4 CIDR, none of the network providers are set up for IPv6
+func parseIPv4CIDR(name, cidr string) (*net.IPNet, error) {
+	if cidr == "" {
+		return nil, nil
+	}
+
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s CIDR", name)
+	}
+
+	if ip.To4() == nil {
+		return nil, errors.Errorf("%s CIDR %s is not an IPv4 range", name, cidr)
+	}
+
+	return ipNet, nil
+}
+
+// validatePodCIDRSplit checks that the pod CIDR can be split into per node ranges of the given prefix length
+func validatePodCIDRSplit(podNet *net.IPNet, prefixLength int, name string) error {
+	if podNet == nil {
+		return nil
+	}
+
+	if ones, _ := podNet.Mask.Size(); ones >= prefixLength {
+		return errors.Errorf("pod CIDR %s must be larger than the %s /%d", podNet, name, prefixLength)
+	}
+
+	return nil
+}
+
+func validateMTU(mtu int) error {
+	if mtu != 0 && (mtu < minMTU || mtu > maxMTU) {
+		return errors.Errorf("MTU must be between %d and %d, got %d", minMTU, maxMTU, mtu)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"testing"
+)
+
+func TestNetwork_Validate(t *testing.T) {
+	tests := map[string]struct {
+		network Network
+		valid   bool
+	}{
+		"weave defaults": {
+			network: Network{Provider: NPWeave, PodCIDR: "10.200.0.0/16", ServiceCIDR: "10.32.0.0/24"},
+			valid:   true,
+		},
+		"weave with settings": {
+			network: Network{Provider: NPWeave, ProviderConfig: map[string]interface{}{"mtu": 1400}},
+		},
+		"overlapping CIDRs": {
+			network: Network{Provider: NPWeave, PodCIDR: "10.0.0.0/8", ServiceCIDR: "10.32.0.0/24"},
+		},
+		"IPv6 pod CIDR": {
+			network: Network{Provider: NPCilium, PodCIDR: "fd00::/64"},
+		},
+		"calico": {
+			network: Network{
+				Provider:       NPCalico,
+				PodCIDR:        "10.200.0.0/16",
+				ProviderConfig: map[string]interface{}{"ipipMode": "CrossSubnet", "blockSize": float64(24), "mtu": 1440},
+			},
+			valid: true,
+		},
+		"calico pod CIDR smaller than block": {
+			network: Network{Provider: NPCalico, PodCIDR: "10.200.0.0/26"},
+		},
+		"calico invalid mode": {
+			network: Network{Provider: NPCalico, ProviderConfig: map[string]interface{}{"ipipMode": "Sometimes"}},
+		},
+		"calico unknown setting": {
+			network: Network{Provider: NPCalico, ProviderConfig: map[string]interface{}{"tunnel": "vxlan"}},
+		},
+		"cilium": {
+			network: Network{
+				Provider:       NPCilium,
+				PodCIDR:        "10.200.0.0/16",
+				ProviderConfig: map[string]interface{}{"tunnel": "disabled"},
+			},
+			valid: true,
+		},
+		"cilium pod CIDR for a single node": {
+			network: Network{Provider: NPCilium, PodCIDR: "10.200.0.0/24"},
+		},
+		"cilium invalid MTU": {
+			network: Network{Provider: NPCilium, ProviderConfig: map[string]interface{}{"mtu": 100}},
+		},
+		"unknown provider": {
+			network: Network{Provider: "flannel"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			err := test.network.Validate()
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if !test.valid && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -14,7 +14,10 @@
 
 package pke
 
-import "github.com/pkg/errors"
+import (
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
 
 // TODO add required field to KubeADM if applicable
 
@@ -28,6 +31,74 @@ type CreateClusterPKE struct {
 	DexEnabled bool       `json:"dexEnabled,omitempty"`
 }
 
+// Validate checks the request fields
+func (pke *CreateClusterPKE) Validate() error {
+	if pke == nil {
+		return errors.New("Required field 'pke' is empty.")
+	}
+
+	var masters []NodePool
+	for _, np := range pke.NodePools {
+		if np.Roles.Contains(RoleMaster) {
+			masters = append(masters, np)
+		}
+	}
+
+	if len(masters) != 1 {
+		return errors.Errorf("exactly one node pool with %q role is required, got %d", RoleMaster, len(masters))
+	}
+
+	if err := masters[0].validateMaster(); err != nil {
+		return err
+	}
+
+	if err := pke.validateHosts(); err != nil {
+		return err
+	}
+
+	return pke.Network.Validate()
+}
+
+// validateMaster checks that a master node pool describes a single or a highly available control plane
+func (np *NodePool) validateMaster() error {
+	if np.Provider != NPPAmazon {
+		return nil
+	}
+
+	var providerConfig AmazonProviderConfig
+	if err := mapstructure.Decode(np.ProviderConfig, &providerConfig); err != nil {
+		return errors.Wrapf(err, "invalid provider config of node pool %q", np.Name)
+	}
+
+	count := providerConfig.AutoScalingGroup.Size.Desired
+	if count == 0 {
+		count = 1
+	}
+
+	switch count {
+	case 1:
+		return nil
+	case 3, 5:
+	default:
+		return errors.Errorf("master node pool %q must have 1, 3 or 5 nodes, got %d", np.Name, count)
+	}
+
+	if np.Autoscaling {
+		return errors.Errorf("highly available master node pool %q cannot be autoscaled", np.Name)
+	}
+
+	zones := make(map[Zone]bool)
+	for _, zone := range providerConfig.AutoScalingGroup.Zones {
+		zones[zone] = true
+	}
+
+	if len(zones) < 2 {
+		return errors.Errorf("highly available master node pool %q must span at least two availability zones", np.Name)
+	}
+
+	return nil
+}
+
 // UpdateClusterPKE describes Pipeline's EC2/BanzaiCloud fields of a UpdateCluster request
 type UpdateClusterPKE struct {
 	NodePools UpdateNodePools `json:"nodepools,omitempty" yaml:"nodepools,omitempty" binding:"required"`
@@ -50,19 +121,14 @@ type UpdateNodePool struct {
 }
 
 type Network struct {
-	ServiceCIDR      string                 `json:"serviceCIDR" yaml:"serviceCIDR"`
-	PodCIDR          string                 `json:"podCIDR" yaml:"podCIDR"`
-	Provider         NetworkProvider        `json:"provider" yaml:"provider"`
-	APIServerAddress string                 `json:"apiServerAddress" yaml:"apiServerAddress"`
-	ProviderConfig   map[string]interface{} `json:"cloudProviderConfig" yaml:"cloudProviderConfig"`
+	ServiceCIDR         string                 `json:"serviceCIDR" yaml:"serviceCIDR"`
+	PodCIDR             string                 `json:"podCIDR" yaml:"podCIDR"`
+	Provider            NetworkProvider        `json:"provider" yaml:"provider"`
+	ProviderConfig      map[string]interface{} `json:"providerConfig,omitempty" yaml:"providerConfig,omitempty"`
+	APIServerAddress    string                 `json:"apiServerAddress" yaml:"apiServerAddress"`
+	CloudProviderConfig map[string]interface{} `json:"cloudProviderConfig" yaml:"cloudProviderConfig"`
 }
 
-type NetworkProvider string
-
-const (
-	NPWeave NetworkProvider = "weave"
-)
-
 type NodePools []NodePool
 
 type NodePool struct {
@@ -79,11 +145,23 @@ type NodePoolProvider string
 
 const (
 	NPPAmazon NodePoolProvider = "amazon"
+	NPPHosts  NodePoolProvider = "hosts"
 )
 
 type Roles []Role
 type Role string
 
+// Contains returns true if the role is in the list
+func (r Roles) Contains(role Role) bool {
+	for _, rr := range r {
+		if rr == role {
+			return true
+		}
+	}
+
+	return false
+}
+
 const (
 	RoleMaster         Role = "master"
 	RoleWorker         Role = "worker"
@@ -141,6 +219,14 @@ func (pke *CreateClusterPKE) AddDefaults() error {
 	if pke.Network.Provider == "" {
 		pke.Network.Provider = NPWeave
 	}
+	if pke.Network.APIServerAddress == "" && pke.UsesHosts() {
+		// a single master host serves the API directly
+		for _, np := range pke.NodePools {
+			if np.Roles.Contains(RoleMaster) && len(np.Hosts) == 1 {
+				pke.Network.APIServerAddress = np.Hosts[0].PrivateIP
+			}
+		}
+	}
 
 	return nil
 }
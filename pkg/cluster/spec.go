@@ -0,0 +1,140 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/pkg/errors"
+)
+
+// ClusterSpec describes the desired state of a cluster.
+// Sections left empty are not managed by the spec and are kept as they are.
+type ClusterSpec struct {
+	NodePools map[string]*NodePoolSpec `json:"nodePools,omitempty" yaml:"nodePools,omitempty"`
+	Features  ClusterFeaturesSpec      `json:"features,omitempty" yaml:"features,omitempty"`
+	Secrets   []ClusterSecretSpec      `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	Releases  []ClusterReleaseSpec     `json:"releases,omitempty" yaml:"releases,omitempty"`
+}
+
+// Validate validates the cluster spec
+func (s *ClusterSpec) Validate() error {
+	secrets := make(map[string]bool, len(s.Secrets))
+	for _, secret := range s.Secrets {
+		if secret.Name == "" || secret.Namespace == "" || secret.SourceSecretName == "" {
+			return errors.New("secrets must have name, namespace and sourceSecretName")
+		}
+
+		key := secret.Namespace + "/" + secret.Name
+		if secrets[key] {
+			return errors.WithMessage(pkgErrors.ErrorClusterSpecDuplicateSecret, key)
+		}
+		secrets[key] = true
+	}
+
+	releases := make(map[string]bool, len(s.Releases))
+	for _, release := range s.Releases {
+		if release.ReleaseName == "" || release.Name == "" {
+			return errors.New("releases must have releaseName and name")
+		}
+
+		if releases[release.ReleaseName] {
+			return errors.WithMessage(pkgErrors.ErrorClusterSpecDuplicateRelease, release.ReleaseName)
+		}
+		releases[release.ReleaseName] = true
+	}
+
+	return nil
+}
+
+// NodePoolSpec describes the desired state of a node pool
+type NodePoolSpec struct {
+	InstanceType string            `json:"instanceType,omitempty" yaml:"instanceType,omitempty"`
+	SpotPrice    string            `json:"spotPrice,omitempty" yaml:"spotPrice,omitempty"`
+	Preemptible  bool              `json:"preemptible,omitempty" yaml:"preemptible,omitempty"`
+	Autoscaling  bool              `json:"autoscaling" yaml:"autoscaling"`
+	Count        int               `json:"count" yaml:"count"`
+	MinCount     int               `json:"minCount" yaml:"minCount"`
+	MaxCount     int               `json:"maxCount" yaml:"maxCount"`
+	Labels       map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// ClusterFeaturesSpec describes the desired state of the cluster features
+type ClusterFeaturesSpec struct {
+	Monitoring   *ClusterFeatureSpec `json:"monitoring,omitempty" yaml:"monitoring,omitempty"`
+	Logging      *ClusterFeatureSpec `json:"logging,omitempty" yaml:"logging,omitempty"`
+	ServiceMesh  *ClusterFeatureSpec `json:"serviceMesh,omitempty" yaml:"serviceMesh,omitempty"`
+	SecurityScan *ClusterFeatureSpec `json:"securityScan,omitempty" yaml:"securityScan,omitempty"`
+}
+
+// ClusterFeatureSpec describes whether a feature is enabled and the parameters of the posthook installing it
+type ClusterFeatureSpec struct {
+	Enabled bool          `json:"enabled" yaml:"enabled"`
+	Params  PostHookParam `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// ClusterSecretSpec describes a secret installed to the cluster
+type ClusterSecretSpec struct {
+	Name             string `json:"name" yaml:"name" binding:"required"`
+	Namespace        string `json:"namespace" yaml:"namespace" binding:"required"`
+	SourceSecretName string `json:"sourceSecretName" yaml:"sourceSecretName" binding:"required"`
+}
+
+// ClusterReleaseSpec describes a Helm release deployed to the cluster
+type ClusterReleaseSpec struct {
+	ReleaseName string                 `json:"releaseName" yaml:"releaseName" binding:"required"`
+	Name        string                 `json:"name" yaml:"name" binding:"required"`
+	Version     string                 `json:"version,omitempty" yaml:"version,omitempty"`
+	Namespace   string                 `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Values      map[string]interface{} `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// cluster spec change kinds
+const (
+	ClusterSpecNodePool = "nodePool"
+	ClusterSpecFeature  = "feature"
+	ClusterSpecSecret   = "secret"
+	ClusterSpecRelease  = "release"
+)
+
+// cluster spec change actions
+const (
+	ClusterSpecCreate = "create"
+	ClusterSpecUpdate = "update"
+	ClusterSpecDelete = "delete"
+)
+
+// ClusterSpecChange describes a difference between the desired and the current state of a cluster
+type ClusterSpecChange struct {
+	Kind    string      `json:"kind"`
+	Action  string      `json:"action"`
+	Name    string      `json:"name"`
+	Fields  []string    `json:"fields,omitempty"`
+	Current interface{} `json:"current,omitempty"`
+	Desired interface{} `json:"desired,omitempty"`
+}
+
+// ClusterSpecPlanResponse describes Pipeline's cluster spec plan and apply API response
+type ClusterSpecPlanResponse struct {
+	Changes []ClusterSpecChange `json:"changes"`
+}
+
+// ClusterSpecResponse describes Pipeline's get cluster spec API response
+type ClusterSpecResponse struct {
+	Spec      *ClusterSpec `json:"spec"`
+	AppliedAt time.Time    `json:"appliedAt"`
+	AppliedBy uint         `json:"appliedBy"`
+}
@@ -0,0 +1,75 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+)
+
+// cluster template parameter types
+const (
+	TemplateParameterString  = "string"
+	TemplateParameterInteger = "integer"
+	TemplateParameterNumber  = "number"
+	TemplateParameterBoolean = "boolean"
+)
+
+// ClusterTemplateParameter describes a typed parameter of a cluster template.
+// Parameters are referenced in the template spec as ${name}.
+type ClusterTemplateParameter struct {
+	Name        string      `json:"name" binding:"required"`
+	Type        string      `json:"type" binding:"required"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+}
+
+// ClusterTemplateRequest describes a create or update cluster template request
+type ClusterTemplateRequest struct {
+	Name           string                     `json:"name" binding:"required"`
+	Description    string                     `json:"description,omitempty"`
+	Extends        string                     `json:"extends,omitempty"`
+	ExtendsVersion uint                       `json:"extendsVersion,omitempty"`
+	Parameters     []ClusterTemplateParameter `json:"parameters,omitempty"`
+	Spec           map[string]interface{}     `json:"spec" binding:"required"`
+}
+
+// ClusterTemplateResponse describes a version of a cluster template
+type ClusterTemplateResponse struct {
+	Name           string                     `json:"name"`
+	Version        uint                       `json:"version"`
+	Description    string                     `json:"description,omitempty"`
+	Extends        string                     `json:"extends,omitempty"`
+	ExtendsVersion uint                       `json:"extendsVersion,omitempty"`
+	Parameters     []ClusterTemplateParameter `json:"parameters,omitempty"`
+	Spec           map[string]interface{}     `json:"spec"`
+	CreatedAt      time.Time                  `json:"createdAt"`
+	CreatedBy      uint                       `json:"createdBy,omitempty"`
+}
+
+// ClusterTemplateRef references the cluster template a cluster is created from
+type ClusterTemplateRef struct {
+	Name       string                 `json:"name" yaml:"name" binding:"required"`
+	Version    uint                   `json:"version,omitempty" yaml:"version,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// ClusterTemplateClusterResponse describes a cluster created from a cluster template
+type ClusterTemplateClusterResponse struct {
+	ClusterID       uint   `json:"clusterId"`
+	ClusterName     string `json:"clusterName"`
+	TemplateVersion uint   `json:"templateVersion"`
+	Outdated        bool   `json:"outdated"`
+}
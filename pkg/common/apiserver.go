@@ -0,0 +1,90 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"net"
+
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/pkg/errors"
+)
+
+// APIServerAccess describes how the API server and the nodes of a managed cluster are exposed
+type APIServerAccess struct {
+	// PrivateEndpoint makes the API server reachable only from the network of the cluster
+	PrivateEndpoint bool `json:"privateEndpoint,omitempty" yaml:"privateEndpoint,omitempty"`
+	// PublicAccessCIDRs restricts the public endpoint of the API server to the given CIDR blocks
+	PublicAccessCIDRs []string `json:"publicAccessCidrs,omitempty" yaml:"publicAccessCidrs,omitempty"`
+	// PrivateNodes creates the nodes without public IP addresses
+	PrivateNodes bool `json:"privateNodes,omitempty" yaml:"privateNodes,omitempty"`
+	// Tunnel describes the agent Pipeline reaches the API server through
+	Tunnel *APIServerTunnel `json:"tunnel,omitempty" yaml:"tunnel,omitempty"`
+}
+
+// APIServerTunnel describes an agent running in the network of a cluster which forwards connections to its API server
+type APIServerTunnel struct {
+	// AgentAddress is the host:port of the agent, which forwards every connection to the API server (and nowhere else)
+	AgentAddress string `json:"agentAddress" yaml:"agentAddress"`
+}
+
+// Validate checks the API server access settings
+func (a *APIServerAccess) Validate() error {
+	if a == nil {
+		return nil
+	}
+
+	for _, cidr := range a.PublicAccessCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return errors.Wrapf(pkgErrors.ErrorNotValidPublicAccessCIDR, "%q", cidr)
+		}
+	}
+
+	if a.PrivateEndpoint {
+		if len(a.PublicAccessCIDRs) > 0 {
+			return pkgErrors.ErrorPublicAccessCIDRsWithPrivateEndpoint
+		}
+
+		// Pipeline would not be able to reach the cluster otherwise
+		if a.Tunnel == nil {
+			return pkgErrors.ErrorAPIServerTunnelRequired
+		}
+	}
+
+	if a.Tunnel != nil {
+		if _, _, err := net.SplitHostPort(a.Tunnel.AgentAddress); err != nil {
+			return pkgErrors.ErrorNotValidAPIServerTunnelAgentAddress
+		}
+	}
+
+	return nil
+}
+
+// GetPublicAccessCIDRs returns the CIDR blocks the public endpoint of the API server is restricted to
+func (a *APIServerAccess) GetPublicAccessCIDRs() []string {
+	if a == nil {
+		return nil
+	}
+
+	return a.PublicAccessCIDRs
+}
+
+// TunnelAgentAddress returns the address of the API server tunnel agent or an empty string if there is no tunnel
+func (a *APIServerAccess) TunnelAgentAddress() string {
+	if a == nil || a.Tunnel == nil {
+		return ""
+	}
+
+	return a.Tunnel.AgentAddress
+}
@@ -0,0 +1,50 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
+	"github.com/pkg/errors"
+)
+
+func TestAPIServerAccess_Validate(t *testing.T) {
+	tunnel := &APIServerTunnel{AgentAddress: "10.0.0.10:8443"}
+
+	tests := map[string]struct {
+		access *APIServerAccess
+		err    error
+	}{
+		"nil":                         {access: nil},
+		"public CIDRs":                {access: &APIServerAccess{PublicAccessCIDRs: []string{"203.0.113.0/24"}}},
+		"invalid CIDR":                {access: &APIServerAccess{PublicAccessCIDRs: []string{"203.0.113.0"}}, err: pkgErrors.ErrorNotValidPublicAccessCIDR},
+		"private endpoint":            {access: &APIServerAccess{PrivateEndpoint: true, Tunnel: tunnel}},
+		"private endpoint no tunnel":  {access: &APIServerAccess{PrivateEndpoint: true}, err: pkgErrors.ErrorAPIServerTunnelRequired},
+		"private endpoint with CIDRs": {access: &APIServerAccess{PrivateEndpoint: true, PublicAccessCIDRs: []string{"203.0.113.0/24"}, Tunnel: tunnel}, err: pkgErrors.ErrorPublicAccessCIDRsWithPrivateEndpoint},
+		"invalid agent address":       {access: &APIServerAccess{Tunnel: &APIServerTunnel{AgentAddress: "10.0.0.10"}}, err: pkgErrors.ErrorNotValidAPIServerTunnelAgentAddress},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			err := test.access.Validate()
+			if errors.Cause(err) != test.err {
+				t.Errorf("expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/bank-vaults/pkg/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateIntermediateCA(t *testing.T) {
+	ca, err := tls.GenerateTLS("istio-ca", "24h")
+	require.NoError(t, err)
+
+	caCert, caKey := []byte(ca.CACert), []byte(ca.CAKey)
+
+	certPEM, keyPEM, err := GenerateIntermediateCA(caCert, caKey, "cluster", 24*time.Hour)
+	require.NoError(t, err)
+
+	cert, key, err := parseCABundle(certPEM, keyPEM)
+	require.NoError(t, err)
+	assert.NotNil(t, key)
+
+	assert.True(t, cert.IsCA)
+	assert.Equal(t, "cluster", cert.Subject.CommonName)
+
+	rootPem, _ := pem.Decode(caCert)
+	root, err := x509.ParseCertificate(rootPem.Bytes)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots})
+	assert.NoError(t, err)
+}
+
+func TestGenerateIntermediateCA_ExpiredCA(t *testing.T) {
+	caCert, err := ioutil.ReadFile("testdata/ca.crt")
+	require.NoError(t, err)
+
+	caKey, err := ioutil.ReadFile("testdata/ca.key")
+	require.NoError(t, err)
+
+	_, _, err = GenerateIntermediateCA(caCert, caKey, "cluster", 24*time.Hour)
+	assert.Error(t, err)
+}
@@ -49,6 +49,29 @@ var (
 	ErrorNotValidMasterVersion                 = errors.New("not valid master version")
 	ErrorNotValidNodeVersion                   = errors.New("not valid node version")
 	ErrorNotValidKubernetesVersion             = errors.New("not valid kubernetesVersion")
+	ErrorNotSupportedKubernetesVersionUpgrade  = errors.New("kubernetes version can only be upgraded to the next minor version")
+	ErrorNotValidNodeImageUpdateSurge          = errors.New("'surge' must not be negative")
+	ErrorNotValidPublicAccessCIDR              = errors.New("not valid public access CIDR")
+	ErrorPublicAccessCIDRsWithPrivateEndpoint  = errors.New("'publicAccessCidrs' cannot be set for a private endpoint")
+	ErrorAPIServerTunnelRequired               = errors.New("'tunnel' is required for a private endpoint")
+	ErrorNotValidAPIServerTunnelAgentAddress   = errors.New("'agentAddress' must be in host:port format")
+	ErrorAPIServerAccessNotSupported           = errors.New("API server access settings are not supported for this cluster type")
+	ErrorEksPrivateNodesRequireExistingSubnets = errors.New("private nodes require an existing VPC and subnets")
+	ErrorGkePrivateEndpointWithoutPrivateNodes = errors.New("private endpoint requires private nodes")
+	ErrorGkeNotValidMasterIPv4CIDR             = errors.New("'masterIpv4Cidr' must be a /28 IPv4 CIDR block")
+	ErrorClusterImportNotSupported             = errors.New("only EKS, GKE and AKS clusters can be imported")
+	ErrorImportedClusterNotRunning             = errors.New("only running clusters can be imported")
+	ErrorImportedNodePoolCannotBeDeleted       = errors.New("node pools adopted at cluster import cannot be deleted")
+	ErrorClusterPropertiesRequired             = errors.New("'properties' field is required")
+	ErrorClusterTemplateNameEmpty              = errors.New("cluster template name is empty")
+	ErrorClusterTemplateExists                 = errors.New("cluster template with the given name already exists")
+	ErrorClusterTemplateNotFound               = errors.New("cluster template not found")
+	ErrorClusterTemplateCycle                  = errors.New("cluster template inheritance cycle")
+	ErrorClusterTemplateExtended               = errors.New("cluster template is extended by other templates")
+	ErrorClusterSpecNotFound                   = errors.New("cluster spec has not been applied yet")
+	ErrorClusterSpecNodePoolsNotSupported      = errors.New("node pools can only be resized in the cluster spec of this distribution")
+	ErrorClusterSpecDuplicateSecret            = errors.New("secret is declared more than once in the cluster spec")
+	ErrorClusterSpecDuplicateRelease           = errors.New("release is declared more than once in the cluster spec")
 	ErrorResourceGroupRequired                 = errors.New("resource group is required")
 	ErrStateStorePathEmpty                     = errors.New("statestore path cannot be empty")
 	ErrorAlibabaFieldIsEmpty                   = errors.New("Required field 'alibaba' is empty.")
@@ -61,6 +84,8 @@ var (
 	ErrorBucketDeleteNotEmpty                  = errors.New("non empty buckets can not be deleted")
 	ErrorGkeSubnetRequiredFieldIsEmpty         = errors.New("'subnet' field required if 'vpc' is set")
 	ErrorGkeVPCRequiredFieldIsEmpty            = errors.New("'vpc' field required if 'subnet' is set")
+	ErrorServiceMeshNotInstalled               = errors.New("service mesh is not installed on the cluster")
+	ErrorLoggingNotInstalled                   = errors.New("logging is not installed on the cluster")
 
 	ErrorFunctionShouldNotBeCalled = errors.New("error function should not be called")
 )
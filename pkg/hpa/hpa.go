@@ -18,8 +18,41 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Scale target kinds which are handled through hpa-operator annotations
+const (
+	DeploymentKind  = "Deployment"
+	StatefulSetKind = "StatefulSet"
+)
+
+// ScalingPolicyType is the type of a scaling policy
+type ScalingPolicyType string
+
+// Scaling policy types
+const (
+	PodsScalingPolicy    ScalingPolicyType = "Pods"
+	PercentScalingPolicy ScalingPolicyType = "Percent"
+)
+
+// ScalingPolicySelect specifies which policy should be used when more are specified
+type ScalingPolicySelect string
+
+// Scaling policy selection methods
+const (
+	MaxPolicySelect      ScalingPolicySelect = "Max"
+	MinPolicySelect      ScalingPolicySelect = "Min"
+	DisabledPolicySelect ScalingPolicySelect = "Disabled"
+)
+
+// Scale behavior limits enforced by Kubernetes
+const (
+	MaxStabilizationWindowSeconds = 3600
+	MaxPolicyPeriodSeconds        = 1800
 )
 
 type ValueType string
@@ -54,25 +87,120 @@ type CustomMetricStatus struct {
 	CurrentValue string `json:"currentValue,omitempty"`
 }
 
+// PodsMetric describes a metric of the custom metrics API averaged across the pods of the scale target
+type PodsMetric struct {
+	MetricName         string `json:"metricName"`
+	TargetAverageValue string `json:"targetAverageValue"`
+}
+
+type PodsMetricStatus struct {
+	PodsMetric
+	CurrentAverageValue string `json:"currentAverageValue,omitempty"`
+}
+
+// ObjectReference identifies a Kubernetes object
+type ObjectReference struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	APIVersion string `json:"apiVersion,omitempty"`
+}
+
+// ObjectMetric describes a metric of the custom metrics API describing a single Kubernetes object
+type ObjectMetric struct {
+	MetricName  string          `json:"metricName"`
+	Target      ObjectReference `json:"target"`
+	TargetValue string          `json:"targetValue"`
+}
+
+type ObjectMetricStatus struct {
+	ObjectMetric
+	CurrentValue string `json:"currentValue,omitempty"`
+}
+
+// ExternalMetric describes a metric of the external metrics API not associated with any Kubernetes object
+type ExternalMetric struct {
+	MetricName         string            `json:"metricName"`
+	Selector           map[string]string `json:"selector,omitempty"`
+	TargetValue        string            `json:"targetValue,omitempty"`
+	TargetAverageValue string            `json:"targetAverageValue,omitempty"`
+}
+
+type ExternalMetricStatus struct {
+	ExternalMetric
+	CurrentValue        string `json:"currentValue,omitempty"`
+	CurrentAverageValue string `json:"currentAverageValue,omitempty"`
+}
+
+// ScalingPolicy limits the change of replicas during a period
+type ScalingPolicy struct {
+	Type          ScalingPolicyType `json:"type"`
+	Value         int32             `json:"value"`
+	PeriodSeconds int32             `json:"periodSeconds"`
+}
+
+// ScalingRules configures the scaling behavior in one direction
+type ScalingRules struct {
+	StabilizationWindowSeconds *int32              `json:"stabilizationWindowSeconds,omitempty"`
+	SelectPolicy               ScalingPolicySelect `json:"selectPolicy,omitempty"`
+	Policies                   []ScalingPolicy     `json:"policies,omitempty"`
+}
+
+// Behavior configures the scaling behavior of the target in both up and down directions
+type Behavior struct {
+	ScaleUp   *ScalingRules `json:"scaleUp,omitempty"`
+	ScaleDown *ScalingRules `json:"scaleDown,omitempty"`
+}
+
+type ScaleCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
 type DeploymentScaleStatus struct {
-	CurrentReplicas int32  `json:"currentReplicas,omitempty"`
-	DesiredReplicas int32  `json:"desiredReplicas,omitempty"`
-	Message         string `json:"message,omitempty"`
+	CurrentReplicas int32            `json:"currentReplicas,omitempty"`
+	DesiredReplicas int32            `json:"desiredReplicas,omitempty"`
+	Message         string           `json:"message,omitempty"`
+	Conditions      []ScaleCondition `json:"conditions,omitempty"`
 }
 
 type DeploymentScalingRequest struct {
-	ScaleTarget   string                  `json:"scaleTarget"`
-	MinReplicas   int32                   `json:"minReplicas"`
-	MaxReplicas   int32                   `json:"maxReplicas"`
-	Cpu           ResourceMetric          `json:"cpu,omitempty"`
-	Memory        ResourceMetric          `json:"memory,omitempty"`
-	CustomMetrics map[string]CustomMetric `json:"customMetrics,omitempty"`
+	ScaleTarget     string                    `json:"scaleTarget"`
+	Kind            string                    `json:"kind,omitempty"`
+	APIVersion      string                    `json:"apiVersion,omitempty"`
+	Namespace       string                    `json:"namespace,omitempty"`
+	MinReplicas     int32                     `json:"minReplicas"`
+	MaxReplicas     int32                     `json:"maxReplicas"`
+	Cpu             ResourceMetric            `json:"cpu,omitempty"`
+	Memory          ResourceMetric            `json:"memory,omitempty"`
+	CustomMetrics   map[string]CustomMetric   `json:"customMetrics,omitempty"`
+	PodsMetrics     map[string]PodsMetric     `json:"podsMetrics,omitempty"`
+	ObjectMetrics   map[string]ObjectMetric   `json:"objectMetrics,omitempty"`
+	ExternalMetrics map[string]ExternalMetric `json:"externalMetrics,omitempty"`
+	Behavior        *Behavior                 `json:"behavior,omitempty"`
+}
+
+// IsWorkloadTarget returns true if the scale target is a Deployment or StatefulSet managed through hpa-operator annotations
+func IsWorkloadTarget(kind string) bool {
+	return kind == "" || kind == DeploymentKind || kind == StatefulSetKind
+}
+
+// RequiresDirectHpa returns true if the request contains metrics or a scale behavior hpa-operator annotations
+// can't describe, so the Horizontal Pod Autoscaler of a Deployment or StatefulSet has to be created directly
+func (r *DeploymentScalingRequest) RequiresDirectHpa() bool {
+	return len(r.PodsMetrics) > 0 || len(r.ObjectMetrics) > 0 || len(r.ExternalMetrics) > 0 || r.Behavior != nil
 }
 
 func (r *DeploymentScalingRequest) Validate() error {
 	if r.MaxReplicas <= r.MinReplicas {
 		return errors.New("'maxReplicas' should be greater then 'minReplicas'")
 	}
+	if !IsWorkloadTarget(r.Kind) {
+		if len(r.APIVersion) == 0 || len(r.Namespace) == 0 {
+			return fmt.Errorf("'apiVersion' and 'namespace' are required for scale target kind %s", r.Kind)
+		}
+	}
 	metricCount := 0
 	if len(r.Cpu.TargetAverageValueType) != 0 {
 		err := r.Cpu.validateResourceMetric()
@@ -95,8 +223,63 @@ func (r *DeploymentScalingRequest) Validate() error {
 		}
 		metricCount++
 	}
+	for name, pm := range r.PodsMetrics {
+		err := validateMetricName(name)
+		if err != nil {
+			return err
+		}
+		err = pm.validatePodsMetric()
+		if err != nil {
+			return err
+		}
+		metricCount++
+	}
+	for name, om := range r.ObjectMetrics {
+		err := validateMetricName(name)
+		if err != nil {
+			return err
+		}
+		err = om.validateObjectMetric()
+		if err != nil {
+			return err
+		}
+		metricCount++
+	}
+	for name, em := range r.ExternalMetrics {
+		err := validateMetricName(name)
+		if err != nil {
+			return err
+		}
+		err = em.validateExternalMetric()
+		if err != nil {
+			return err
+		}
+		metricCount++
+	}
 	if metricCount == 0 {
-		return errors.New("there should at least one cpu / memory or custom metric specified")
+		return errors.New("there should at least one cpu / memory, custom, pods, object or external metric specified")
+	}
+	if r.Behavior != nil {
+		err := r.Behavior.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMetricName checks whether a metric name can be used as part of an annotation key
+func validateMetricName(name string) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Errorf("invalid metric name %q: %s", name, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func validateQuantity(field string, value string) error {
+	_, err := resource.ParseQuantity(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %s (%s)", field, value, err.Error())
 	}
 	return nil
 }
@@ -143,13 +326,103 @@ func (rm CustomMetric) validateCustomMetric() error {
 	return nil
 }
 
+func (pm PodsMetric) validatePodsMetric() error {
+	if len(pm.MetricName) == 0 {
+		return errors.New("metricName is required for pods metric")
+	}
+	return validateQuantity("pods metric targetAverageValue", pm.TargetAverageValue)
+}
+
+func (om ObjectMetric) validateObjectMetric() error {
+	if len(om.MetricName) == 0 {
+		return errors.New("metricName is required for object metric")
+	}
+	if len(om.Target.Kind) == 0 || len(om.Target.Name) == 0 {
+		return errors.New("target kind and name are required for object metric")
+	}
+	return validateQuantity("object metric targetValue", om.TargetValue)
+}
+
+func (em ExternalMetric) validateExternalMetric() error {
+	if len(em.MetricName) == 0 {
+		return errors.New("metricName is required for external metric")
+	}
+	for key, value := range em.Selector {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid external metric selector key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid external metric selector value %q: %s", value, strings.Join(errs, ", "))
+		}
+	}
+	if len(em.TargetValue) > 0 {
+		return validateQuantity("external metric targetValue", em.TargetValue)
+	} else if len(em.TargetAverageValue) > 0 {
+		return validateQuantity("external metric targetAverageValue", em.TargetAverageValue)
+	}
+	return errors.New("either targetValue or targetAverageValue is required for external metric")
+}
+
+func (b Behavior) Validate() error {
+	if b.ScaleUp != nil {
+		err := b.ScaleUp.validateScalingRules()
+		if err != nil {
+			return fmt.Errorf("invalid scaleUp behavior: %s", err.Error())
+		}
+	}
+	if b.ScaleDown != nil {
+		err := b.ScaleDown.validateScalingRules()
+		if err != nil {
+			return fmt.Errorf("invalid scaleDown behavior: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+func (sr ScalingRules) validateScalingRules() error {
+	if sr.StabilizationWindowSeconds != nil {
+		if *sr.StabilizationWindowSeconds < 0 || *sr.StabilizationWindowSeconds > MaxStabilizationWindowSeconds {
+			return fmt.Errorf("stabilizationWindowSeconds should be between [0,%d]", MaxStabilizationWindowSeconds)
+		}
+	}
+	switch sr.SelectPolicy {
+	case "", MaxPolicySelect, MinPolicySelect:
+		if len(sr.Policies) == 0 {
+			return errors.New("at least one policy should be specified")
+		}
+	case DisabledPolicySelect:
+	default:
+		return fmt.Errorf("invalid selectPolicy: %s", sr.SelectPolicy)
+	}
+	for _, policy := range sr.Policies {
+		switch policy.Type {
+		case PodsScalingPolicy, PercentScalingPolicy:
+		default:
+			return fmt.Errorf("invalid policy type: %s", policy.Type)
+		}
+		if policy.Value <= 0 {
+			return fmt.Errorf("invalid policy value: %d (should be greater than 0)", policy.Value)
+		}
+		if policy.PeriodSeconds <= 0 || policy.PeriodSeconds > MaxPolicyPeriodSeconds {
+			return fmt.Errorf("invalid policy periodSeconds: %d (should be between [1,%d])", policy.PeriodSeconds, MaxPolicyPeriodSeconds)
+		}
+	}
+	return nil
+}
+
 type DeploymentScalingInfo struct {
-	ScaleTarget   string                        `json:"scaleTarget,omitempty"`
-	Kind          string                        `json:"kind,omitempty"`
-	MinReplicas   int32                         `json:"minReplicas,omitempty"`
-	MaxReplicas   int32                         `json:"maxReplicas,omitempty"`
-	Cpu           ResourceMetricStatus          `json:"cpu,omitempty"`
-	Memory        ResourceMetricStatus          `json:"memory,omitempty"`
-	CustomMetrics map[string]CustomMetricStatus `json:"customMetrics,omitempty"`
-	Status        DeploymentScaleStatus         `json:"status,omitempty"`
+	ScaleTarget     string                          `json:"scaleTarget,omitempty"`
+	Kind            string                          `json:"kind,omitempty"`
+	APIVersion      string                          `json:"apiVersion,omitempty"`
+	Namespace       string                          `json:"namespace,omitempty"`
+	MinReplicas     int32                           `json:"minReplicas,omitempty"`
+	MaxReplicas     int32                           `json:"maxReplicas,omitempty"`
+	Cpu             ResourceMetricStatus            `json:"cpu,omitempty"`
+	Memory          ResourceMetricStatus            `json:"memory,omitempty"`
+	CustomMetrics   map[string]CustomMetricStatus   `json:"customMetrics,omitempty"`
+	PodsMetrics     map[string]PodsMetricStatus     `json:"podsMetrics,omitempty"`
+	ObjectMetrics   map[string]ObjectMetricStatus   `json:"objectMetrics,omitempty"`
+	ExternalMetrics map[string]ExternalMetricStatus `json:"externalMetrics,omitempty"`
+	Behavior        *Behavior                       `json:"behavior,omitempty"`
+	Status          DeploymentScaleStatus           `json:"status,omitempty"`
 }
@@ -0,0 +1,194 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hpa
+
+import (
+	"testing"
+)
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func TestDeploymentScalingRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request DeploymentScalingRequest
+		wantErr bool
+	}{
+		{
+			name: "cpu on deployment",
+			request: DeploymentScalingRequest{
+				ScaleTarget: "app",
+				MinReplicas: 1,
+				MaxReplicas: 3,
+				Cpu:         ResourceMetric{TargetAverageValueType: PercentageValueType, TargetAverageValue: "70"},
+			},
+		},
+		{
+			name: "no metrics",
+			request: DeploymentScalingRequest{
+				ScaleTarget: "app",
+				MinReplicas: 1,
+				MaxReplicas: 3,
+			},
+			wantErr: true,
+		},
+		{
+			name: "external metric on custom resource",
+			request: DeploymentScalingRequest{
+				ScaleTarget: "app",
+				Kind:        "Rollout",
+				APIVersion:  "argoproj.io/v1alpha1",
+				Namespace:   "default",
+				MinReplicas: 1,
+				MaxReplicas: 3,
+				ExternalMetrics: map[string]ExternalMetric{
+					"queue": {MetricName: "queue_messages_ready", Selector: map[string]string{"queue": "worker"}, TargetAverageValue: "30"},
+				},
+			},
+		},
+		{
+			name: "custom resource without namespace",
+			request: DeploymentScalingRequest{
+				ScaleTarget: "app",
+				Kind:        "Rollout",
+				APIVersion:  "argoproj.io/v1alpha1",
+				MinReplicas: 1,
+				MaxReplicas: 3,
+				Cpu:         ResourceMetric{TargetAverageValueType: PercentageValueType, TargetAverageValue: "70"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid metric name",
+			request: DeploymentScalingRequest{
+				ScaleTarget: "app",
+				MinReplicas: 1,
+				MaxReplicas: 3,
+				PodsMetrics: map[string]PodsMetric{
+					"requests_per_second": {MetricName: "requests_per_second", TargetAverageValue: "10"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "object metric without target",
+			request: DeploymentScalingRequest{
+				ScaleTarget: "app",
+				MinReplicas: 1,
+				MaxReplicas: 3,
+				ObjectMetrics: map[string]ObjectMetric{
+					"requests": {MetricName: "requests_per_second", TargetValue: "10"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "scale behavior",
+			request: DeploymentScalingRequest{
+				ScaleTarget: "app",
+				Kind:        StatefulSetKind,
+				MinReplicas: 1,
+				MaxReplicas: 3,
+				Memory:      ResourceMetric{TargetAverageValueType: QuantityValueType, TargetAverageValue: "500Mi"},
+				Behavior: &Behavior{
+					ScaleUp: &ScalingRules{
+						SelectPolicy: MaxPolicySelect,
+						Policies: []ScalingPolicy{
+							{Type: PodsScalingPolicy, Value: 4, PeriodSeconds: 60},
+							{Type: PercentScalingPolicy, Value: 100, PeriodSeconds: 60},
+						},
+					},
+					ScaleDown: &ScalingRules{
+						StabilizationWindowSeconds: int32Ptr(300),
+						SelectPolicy:               DisabledPolicySelect,
+					},
+				},
+			},
+		},
+		{
+			name: "invalid scale behavior",
+			request: DeploymentScalingRequest{
+				ScaleTarget: "app",
+				MinReplicas: 1,
+				MaxReplicas: 3,
+				Cpu:         ResourceMetric{TargetAverageValueType: PercentageValueType, TargetAverageValue: "70"},
+				Behavior: &Behavior{
+					ScaleDown: &ScalingRules{
+						StabilizationWindowSeconds: int32Ptr(7200),
+						Policies:                   []ScalingPolicy{{Type: PodsScalingPolicy, Value: 1, PeriodSeconds: 60}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			err := test.request.Validate()
+			if test.wantErr && err == nil {
+				t.Fatal("expected validation error")
+			} else if !test.wantErr && err != nil {
+				t.Fatalf("unexpected validation error: %s", err)
+			}
+		})
+	}
+}
+
+func TestDeploymentScalingRequest_RequiresDirectHpa(t *testing.T) {
+	tests := []struct {
+		name    string
+		request DeploymentScalingRequest
+		direct  bool
+	}{
+		{
+			name: "resource and custom metrics",
+			request: DeploymentScalingRequest{
+				Cpu:           ResourceMetric{TargetAverageValueType: PercentageValueType, TargetAverageValue: "70"},
+				CustomMetrics: map[string]CustomMetric{"rps": {Query: "sum(rate(http_requests_total[1m]))", TargetAverageValue: "100"}},
+			},
+			direct: false,
+		},
+		{
+			name: "pods metric",
+			request: DeploymentScalingRequest{
+				PodsMetrics: map[string]PodsMetric{"rps": {MetricName: "http_requests", TargetAverageValue: "100"}},
+			},
+			direct: true,
+		},
+		{
+			name: "scale behavior",
+			request: DeploymentScalingRequest{
+				Cpu:      ResourceMetric{TargetAverageValueType: PercentageValueType, TargetAverageValue: "70"},
+				Behavior: &Behavior{ScaleDown: &ScalingRules{StabilizationWindowSeconds: int32Ptr(300)}},
+			},
+			direct: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			if direct := test.request.RequiresDirectHpa(); direct != test.direct {
+				t.Errorf("expected %v, got %v", test.direct, direct)
+			}
+		})
+	}
+}
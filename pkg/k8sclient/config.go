@@ -37,5 +37,12 @@ func NewClientConfig(kubeConfig []byte) (*rest.Config, error) {
 		return nil, emperror.Wrap(err, "failed to build client config from API config")
 	}
 
+	// API servers with a private endpoint are reached through their tunnel agent
+	if host, err := hostOf(config.Host); err == nil {
+		if dial := tunnelDialer(host); dial != nil {
+			config.Dial = dial
+		}
+	}
+
 	return config, nil
 }
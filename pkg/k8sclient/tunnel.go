@@ -0,0 +1,135 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sclient
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// DialFunc creates a connection to an address.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// tunnels holds the dial functions of API servers reachable through a tunnel agent keyed by the API server host.
+// The same dial function is returned for an API server as long as the agent does not change,
+// so that client-go can reuse its cached transports.
+var tunnels = struct {
+	sync.RWMutex
+	agents  map[string]string
+	dialers map[string]DialFunc
+}{
+	agents:  make(map[string]string),
+	dialers: make(map[string]DialFunc),
+}
+
+// RegisterAPIServerTunnel routes the connections of the Kubernetes clients created for the given kube config
+// through an agent running in the network of the cluster.
+// Like a port forward, the agent forwards every connection to the API server only, it must not act as a proxy.
+func RegisterAPIServerTunnel(kubeConfig []byte, agentAddress string) error {
+	host, err := apiServerHost(kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	tunnels.Lock()
+	defer tunnels.Unlock()
+
+	if tunnels.agents[host] == agentAddress {
+		return nil
+	}
+
+	tunnels.agents[host] = agentAddress
+	tunnels.dialers[host] = NewTunnelDialer(host, agentAddress)
+
+	return nil
+}
+
+// tunnelDialer returns the dial function of an API server host if it is reachable through a tunnel.
+func tunnelDialer(host string) DialFunc {
+	tunnels.RLock()
+	defer tunnels.RUnlock()
+
+	return tunnels.dialers[host]
+}
+
+// NewTunnelDialer returns a dial function which connects to the API server through the port forward of a tunnel agent.
+// The tunnel has a single destination, it refuses to dial any other address than the API server.
+// The connection is not terminated by the agent: TLS and authentication are done with the API server itself,
+// using the certificate authority and the credentials of the kube config.
+func NewTunnelDialer(apiServerAddress string, agentAddress string) DialFunc {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if !sameAddress(address, apiServerAddress) {
+			return nil, errors.Errorf("tunnel agent %s only forwards to %s, refusing to dial %s", agentAddress, apiServerAddress, address)
+		}
+
+		conn, err := dialer.DialContext(ctx, network, agentAddress)
+		if err != nil {
+			return nil, emperror.Wrapf(err, "failed to connect to tunnel agent %s", agentAddress)
+		}
+
+		return conn, nil
+	}
+}
+
+// sameAddress compares a dialed host:port address with an API server host, which may omit the default HTTPS port.
+func sameAddress(address string, apiServerAddress string) bool {
+	if address == apiServerAddress {
+		return true
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+
+	return port == "443" && host == strings.Trim(apiServerAddress, "[]")
+}
+
+// apiServerHost returns the host of the API server of the current context of a kube config.
+func apiServerHost(kubeConfig []byte) (string, error) {
+	apiconfig, err := clientcmd.Load(kubeConfig)
+	if err != nil {
+		return "", emperror.Wrap(err, "failed to load kubernetes API config")
+	}
+
+	config, err := clientcmd.NewDefaultClientConfig(*apiconfig, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return "", emperror.Wrap(err, "failed to build client config from API config")
+	}
+
+	return hostOf(config.Host)
+}
+
+func hostOf(server string) (string, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", emperror.Wrapf(err, "failed to parse API server address %q", server)
+	}
+
+	return u.Host, nil
+}
@@ -0,0 +1,77 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sclient
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+)
+
+// forwardAgent serves a single connection and echoes the forwarded data back.
+func forwardAgent(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		defer listener.Close()
+
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestNewTunnelDialer(t *testing.T) {
+	dial := NewTunnelDialer("apiserver.internal", forwardAgent(t))
+
+	conn, err := dial(context.Background(), "tcp", "apiserver.internal:443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, "ping"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(buf) != "ping" {
+		t.Errorf("expected forwarded data %q, got %q", "ping", string(buf))
+	}
+}
+
+func TestNewTunnelDialer_OtherAddress(t *testing.T) {
+	dial := NewTunnelDialer("apiserver.internal:6443", forwardAgent(t))
+
+	for _, address := range []string{"apiserver.internal:443", "10.0.0.1:22", "metadata.internal:80"} {
+		if _, err := dial(context.Background(), "tcp", address); err == nil {
+			t.Errorf("expected dialing %s through the tunnel to be refused", address)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"context"
+	"time"
+
+	"github.com/goph/emperror"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SetNodeUnschedulable cordons or uncordons a node.
+func SetNodeUnschedulable(client kubernetes.Interface, name string, unschedulable bool) error {
+	node, err := client.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return emperror.Wrapf(err, "failed to get node %q", name)
+	}
+
+	if node.Spec.Unschedulable == unschedulable {
+		return nil
+	}
+
+	node.Spec.Unschedulable = unschedulable
+
+	_, err = client.CoreV1().Nodes().Update(node)
+
+	return emperror.Wrapf(err, "failed to update node %q", name)
+}
+
+// DrainNode cordons a node and evicts the pods running on it.
+// Evictions refused by pod disruption budgets are retried every pollInterval until the context is done.
+func DrainNode(ctx context.Context, client kubernetes.Interface, name string, pollInterval time.Duration) error {
+	if err := SetNodeUnschedulable(client, name, true); err != nil {
+		return err
+	}
+
+	for {
+		pods, err := evictablePods(client, name)
+		if err != nil {
+			return err
+		}
+
+		if len(pods) == 0 {
+			return nil
+		}
+
+		for _, pod := range pods {
+			err := client.CoreV1().Pods(pod.Namespace).Evict(&policyv1beta1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pod.Name,
+					Namespace: pod.Namespace,
+				},
+			})
+			if err != nil && !k8sapierrors.IsNotFound(err) && !k8sapierrors.IsTooManyRequests(err) {
+				return emperror.Wrapf(err, "failed to evict pod %s/%s", pod.Namespace, pod.Name)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return emperror.Wrapf(ctx.Err(), "draining node %q", name)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// IsNodeReady returns true if the node reports a ready condition.
+func IsNodeReady(node *v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// evictablePods returns the pods of a node which have to be evicted during a drain.
+func evictablePods(client kubernetes.Interface, node string) ([]v1.Pod, error) {
+	podList, err := client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": node}).String(),
+	})
+	if err != nil {
+		return nil, emperror.Wrapf(err, "failed to list pods of node %q", node)
+	}
+
+	var pods []v1.Pod
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+
+		// mirror pods are managed by the kubelet
+		if _, ok := pod.Annotations[v1.MirrorPodAnnotationKey]; ok {
+			continue
+		}
+
+		// daemon set pods would be recreated on the node
+		controller := metav1.GetControllerOf(&pod)
+		if controller != nil && controller.Kind == "DaemonSet" {
+			continue
+		}
+
+		pods = append(pods, pod)
+	}
+
+	return pods, nil
+}
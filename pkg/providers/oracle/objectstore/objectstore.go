@@ -234,6 +234,15 @@ func (o *objectStore) DeleteObject(bucketName string, key string) error {
 	return nil
 }
 
+// GetNamespace returns the object storage namespace of the tenancy
+func (o *objectStore) GetNamespace() string {
+	if o.osClient == nil {
+		return ""
+	}
+
+	return o.osClient.Namespace
+}
+
 // GetSignedURL gives back a signed URL for the object that expires after the given ttl
 func (o *objectStore) GetSignedURL(bucketName, key string, ttl time.Duration) (string, error) {
 	url, err := o.osClient.GetSignedURL(bucketName, key, ttl)
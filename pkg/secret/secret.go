@@ -116,12 +116,20 @@ const (
 	FrontProxyCACert = "frontProxyCaCert"
 	FrontProxyCAKey  = "frontProxyCaKey"
 
+	SAPub = "saPub"
+	SAKey = "saKey"
+
 	// some useful helpers
 	KubernetesCACommonName           = "kubernetes-ca"
 	EtcdCACommonName                 = "etcd-ca"
 	KubernetesFrontProxyCACommonName = "kubernetes-front-proxy-ca"
 )
 
+// NextCAKey returns the key of the field holding the next value of a CA field during a CA rotation.
+func NextCAKey(key string) string {
+	return key + "Next"
+}
+
 // Fn keys
 const (
 	MasterToken = "master_token"
@@ -138,6 +146,46 @@ const (
 	HtpasswdFile = "htpasswd"
 )
 
+// Slack keys
+const (
+	SlackAPIURL = "apiUrl"
+)
+
+// PagerDuty keys
+const (
+	PagerDutyRoutingKey = "routingKey"
+)
+
+// SMTP keys (+Password keys)
+const (
+	SMTPSmarthost = "smarthost"
+	SMTPFrom      = "from"
+)
+
+// Elasticsearch keys (+Password keys)
+const (
+	ElasticsearchHost   = "host"
+	ElasticsearchPort   = "port"
+	ElasticsearchScheme = "scheme"
+)
+
+// Loki keys (+Password keys)
+const (
+	LokiURL = "url"
+)
+
+// Kafka keys (+Password keys)
+const (
+	KafkaBrokers = "brokers"
+)
+
+// Syslog keys
+const (
+	SyslogHost     = "host"
+	SyslogPort     = "port"
+	SyslogProtocol = "protocol"
+)
+
 // Internal usage
 const (
 	TagKubeConfig     = "KubeConfig"
@@ -168,6 +216,20 @@ const (
 	PasswordSecretType = "password"
 	// HtpasswdSecretType marks secrets as of type "htpasswd"
 	HtpasswdSecretType = "htpasswd"
+	// SlackSecretType marks secrets as of type "slack"
+	SlackSecretType = "slack"
+	// PagerDutySecretType marks secrets as of type "pagerduty"
+	PagerDutySecretType = "pagerduty"
+	// SMTPSecretType marks secrets as of type "smtp"
+	SMTPSecretType = "smtp"
+	// ElasticsearchSecretType marks secrets as of type "elasticsearch"
+	ElasticsearchSecretType = "elasticsearch"
+	// LokiSecretType marks secrets as of type "loki"
+	LokiSecretType = "loki"
+	// KafkaSecretType marks secrets as of type "kafka"
+	KafkaSecretType = "kafka"
+	// SyslogSecretType marks secrets as of type "syslog"
+	SyslogSecretType = "syslog"
 )
 
 // DefaultRules key matching for types
@@ -265,6 +327,18 @@ var DefaultRules = map[string]Meta{
 
 			{Name: FrontProxyCACert, Required: false},
 			{Name: FrontProxyCAKey, Required: false},
+
+			{Name: NextCAKey(KubernetesCACert), Required: false},
+			{Name: NextCAKey(KubernetesCAKey), Required: false},
+
+			{Name: NextCAKey(EtcdCACert), Required: false},
+			{Name: NextCAKey(EtcdCAKey), Required: false},
+
+			{Name: NextCAKey(FrontProxyCACert), Required: false},
+			{Name: NextCAKey(FrontProxyCAKey), Required: false},
+
+			{Name: SAPub, Required: false},
+			{Name: SAKey, Required: false},
 		},
 		Sourcing: Volume,
 	},
@@ -293,6 +367,61 @@ var DefaultRules = map[string]Meta{
 		},
 		Sourcing: Volume,
 	},
+	SlackSecretType: {
+		Fields: []FieldMeta{
+			{Name: SlackAPIURL, Required: true},
+		},
+		Sourcing: EnvVar,
+	},
+	PagerDutySecretType: {
+		Fields: []FieldMeta{
+			{Name: PagerDutyRoutingKey, Required: true},
+		},
+		Sourcing: EnvVar,
+	},
+	SMTPSecretType: {
+		Fields: []FieldMeta{
+			{Name: SMTPSmarthost, Required: true},
+			{Name: SMTPFrom, Required: true},
+			{Name: Username, Required: false},
+			{Name: Password, Required: false},
+		},
+		Sourcing: EnvVar,
+	},
+	ElasticsearchSecretType: {
+		Fields: []FieldMeta{
+			{Name: ElasticsearchHost, Required: true},
+			{Name: ElasticsearchPort, Required: false, Description: "Defaults to 9200"},
+			{Name: ElasticsearchScheme, Required: false, Description: "http or https, defaults to http"},
+			{Name: Username, Required: false},
+			{Name: Password, Required: false},
+		},
+		Sourcing: EnvVar,
+	},
+	LokiSecretType: {
+		Fields: []FieldMeta{
+			{Name: LokiURL, Required: true},
+			{Name: Username, Required: false},
+			{Name: Password, Required: false},
+		},
+		Sourcing: EnvVar,
+	},
+	KafkaSecretType: {
+		Fields: []FieldMeta{
+			{Name: KafkaBrokers, Required: true, Description: "Comma separated list of host:port pairs"},
+			{Name: Username, Required: false},
+			{Name: Password, Required: false},
+		},
+		Sourcing: EnvVar,
+	},
+	SyslogSecretType: {
+		Fields: []FieldMeta{
+			{Name: SyslogHost, Required: true},
+			{Name: SyslogPort, Required: false, Description: "Defaults to 514"},
+			{Name: SyslogProtocol, Required: false, Description: "udp or tcp, defaults to udp"},
+		},
+		Sourcing: EnvVar,
+	},
 }
 
 // ListSecretsQuery represent a secret listing filter
@@ -0,0 +1,255 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"fmt"
+	"strings"
+
+	secretTypes "github.com/banzaicloud/pipeline/pkg/secret"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/pkg/errors"
+)
+
+// PKI generation tags of PKE secrets.
+// Each CA rotation mounts the new CAs under a new generation of the cluster PKI path.
+const (
+	pkiGenerationTagName     = "pkiGeneration"
+	pkiNextGenerationTagName = "pkiNextGeneration"
+)
+
+// pkeCAKeys are the fields of a PKE secret replaced by a CA rotation.
+// nolint: gochecknoglobals
+var pkeCAKeys = []string{
+	secretTypes.KubernetesCAKey,
+	secretTypes.KubernetesCACert,
+	secretTypes.KubernetesCASigningCert,
+	secretTypes.EtcdCAKey,
+	secretTypes.EtcdCACert,
+	secretTypes.FrontProxyCAKey,
+	secretTypes.FrontProxyCACert,
+}
+
+// generatePKECAs mounts a root PKI engine under basePath and generates the Kubernetes, etcd and front proxy CAs.
+func (ss *secretStore) generatePKECAs(clusterID, basePath string) (map[string]string, error) {
+	mountInput := vaultapi.MountInput{
+		Type:        "pki",
+		Description: fmt.Sprintf("root PKI engine for cluster %s", clusterID),
+		Config: vaultapi.MountConfigInput{
+			MaxLeaseTTL:     "43801h",
+			DefaultLeaseTTL: "43801h",
+		},
+	}
+
+	// Mount a separate PKI engine for the cluster
+	path := fmt.Sprintf("%s/ca", basePath)
+
+	err := ss.Client.Vault().Sys().Mount(path, &mountInput)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error mounting pki engine for cluster %s", clusterID)
+	}
+
+	unmount := func() {
+		if err := ss.Client.Vault().Sys().Unmount(path); err != nil {
+			log.Warnf("failed to unmount %s: %s", path, err)
+		}
+	}
+
+	// Generate the root CA
+	rootCAData := map[string]interface{}{
+		"common_name": fmt.Sprintf("cluster-%s-ca", clusterID),
+	}
+
+	_, err = ss.Logical.Write(fmt.Sprintf("%s/root/generate/internal", path), rootCAData)
+	if err != nil {
+		unmount()
+		return nil, errors.Wrapf(err, "Error generating root CA for cluster %s", clusterID)
+	}
+
+	// Get root CA
+	rootCA, err := ss.Logical.Read(fmt.Sprintf("%s/cert/ca", path))
+	if err != nil {
+		unmount()
+		return nil, errors.Wrapf(err, "Error reading root CA for cluster %s", clusterID)
+	}
+	ca := rootCA.Data["certificate"].(string)
+
+	// Generate the intermediate CAs
+	kubernetesCA, err := ss.generateIntermediateCert(clusterID, basePath, secretTypes.KubernetesCACommonName)
+	if err != nil {
+		unmount()
+		return nil, err
+	}
+
+	etcdCA, err := ss.generateIntermediateCert(clusterID, basePath, secretTypes.EtcdCACommonName)
+	if err != nil {
+		unmount()
+		return nil, err
+	}
+
+	frontProxyCA, err := ss.generateIntermediateCert(clusterID, basePath, secretTypes.KubernetesFrontProxyCACommonName)
+	if err != nil {
+		unmount()
+		return nil, err
+	}
+
+	return map[string]string{
+		secretTypes.KubernetesCAKey:         kubernetesCA.Key,
+		secretTypes.KubernetesCACert:        kubernetesCA.Cert + "\n" + ca,
+		secretTypes.KubernetesCASigningCert: kubernetesCA.Cert,
+		secretTypes.EtcdCAKey:               etcdCA.Key,
+		secretTypes.EtcdCACert:              etcdCA.Cert + "\n" + ca,
+		secretTypes.FrontProxyCAKey:         frontProxyCA.Key,
+		secretTypes.FrontProxyCACert:        frontProxyCA.Cert + "\n" + ca,
+	}, nil
+}
+
+// unmountPKI unmounts all PKI engines of a cluster PKI path.
+func (ss *secretStore) unmountPKI(basePath string) {
+	paths := []string{
+		"ca",
+		secretTypes.KubernetesCACommonName,
+		secretTypes.EtcdCACommonName,
+		secretTypes.KubernetesFrontProxyCACommonName,
+	}
+
+	for _, path := range paths {
+		path = fmt.Sprintf("%s/%s", basePath, path)
+		err := ss.Client.Vault().Sys().Unmount(path)
+		if err != nil {
+			log.Warnf("failed to unmount %s: %s", path, err)
+		}
+	}
+}
+
+// GenerateNextPKECAs generates a new set of CAs for a PKE secret under a new PKI generation.
+// The new CAs are stored in the next CA fields of the secret until PromoteNextPKECAs is called.
+// Generating the next CAs again discards the ones of an earlier, unfinished rotation.
+func (ss *secretStore) GenerateNextPKECAs(organizationID uint, secretID string, generation string) error {
+	secret, err := ss.Get(organizationID, secretID)
+	if err != nil {
+		return err
+	}
+
+	if secret.Type != secretTypes.PKESecretType {
+		return errors.Errorf("secret %s is not a %s secret", secretID, secretTypes.PKESecretType)
+	}
+
+	clusterID := getClusterIDFromTags(secret.Tags)
+	if clusterID == "" {
+		return errors.New("clusterID is missing from the tags")
+	}
+
+	if generation == getTagValue(secret.Tags, pkiGenerationTagName) {
+		return errors.Errorf("PKI generation %q is already in use", generation)
+	}
+
+	if previous := getTagValue(secret.Tags, pkiNextGenerationTagName); previous != "" {
+		ss.unmountPKI(clusterPKIGenerationPath(organizationID, clusterID, previous))
+	}
+
+	cas, err := ss.generatePKECAs(clusterID, clusterPKIGenerationPath(organizationID, clusterID, generation))
+	if err != nil {
+		return err
+	}
+
+	for key, value := range cas {
+		secret.Values[secretTypes.NextCAKey(key)] = value
+	}
+
+	return ss.Update(organizationID, secretID, &CreateSecretRequest{
+		Name:    secret.Name,
+		Type:    secret.Type,
+		Values:  secret.Values,
+		Tags:    setTagValue(secret.Tags, pkiNextGenerationTagName, generation),
+		Version: &secret.Version,
+	})
+}
+
+// PromoteNextPKECAs replaces the CAs of a PKE secret with the ones generated by GenerateNextPKECAs,
+// and unmounts the PKI engines of the previous CAs.
+func (ss *secretStore) PromoteNextPKECAs(organizationID uint, secretID string) error {
+	secret, err := ss.Get(organizationID, secretID)
+	if err != nil {
+		return err
+	}
+
+	generation := getTagValue(secret.Tags, pkiNextGenerationTagName)
+	if generation == "" {
+		return errors.Errorf("secret %s has no next CAs", secretID)
+	}
+
+	clusterID := getClusterIDFromTags(secret.Tags)
+	previous := getTagValue(secret.Tags, pkiGenerationTagName)
+
+	for _, key := range pkeCAKeys {
+		secret.Values[key] = secret.Values[secretTypes.NextCAKey(key)]
+		delete(secret.Values, secretTypes.NextCAKey(key))
+	}
+
+	tags := setTagValue(secret.Tags, pkiGenerationTagName, generation)
+	tags = setTagValue(tags, pkiNextGenerationTagName, "")
+
+	err = ss.Update(organizationID, secretID, &CreateSecretRequest{
+		Name:    secret.Name,
+		Type:    secret.Type,
+		Values:  secret.Values,
+		Tags:    tags,
+		Version: &secret.Version,
+	})
+	if err != nil {
+		return err
+	}
+
+	ss.unmountPKI(clusterPKIGenerationPath(organizationID, clusterID, previous))
+
+	return nil
+}
+
+// clusterPKIGenerationPath returns the PKI path of a generation of cluster CAs.
+// The CAs generated at cluster creation have no generation.
+func clusterPKIGenerationPath(organizationID uint, clusterID string, generation string) string {
+	if generation == "" {
+		return clusterPKIPath(organizationID, clusterID)
+	}
+
+	return fmt.Sprintf("%s-%s", clusterPKIPath(organizationID, clusterID), generation)
+}
+
+func getTagValue(tags []string, name string) string {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, name+":") {
+			return strings.TrimPrefix(tag, name+":")
+		}
+	}
+
+	return ""
+}
+
+// setTagValue returns the tags with the value of the given tag replaced, or removed if the value is empty.
+func setTagValue(tags []string, name string, value string) []string {
+	result := make([]string, 0, len(tags)+1)
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, name+":") {
+			result = append(result, tag)
+		}
+	}
+
+	if value != "" {
+		result = append(result, fmt.Sprintf("%s:%s", name, value))
+	}
+
+	return result
+}
@@ -15,8 +15,12 @@
 package secret
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"sort"
 	"strconv"
@@ -274,30 +278,12 @@ func (ss *secretStore) Delete(organizationID uint, secretID string) error {
 	// if type is distribution, unmount all pki engines
 	if secret.Type == secretTypes.PKESecretType {
 		clusterID := getClusterIDFromTags(secret.Tags)
-		basePath := clusterPKIPath(organizationID, clusterID)
-
-		path = fmt.Sprintf("%s/ca", basePath)
-		err = ss.Client.Vault().Sys().Unmount(path)
-		if err != nil {
-			log.Warnf("failed to unmount %s: %s", path, err)
-		}
-
-		path = fmt.Sprintf("%s/%s", basePath, secretTypes.KubernetesCACommonName)
-		err = ss.Client.Vault().Sys().Unmount(path)
-		if err != nil {
-			log.Warnf("failed to unmount %s: %s", path, err)
-		}
 
-		path = fmt.Sprintf("%s/%s", basePath, secretTypes.EtcdCACommonName)
-		err = ss.Client.Vault().Sys().Unmount(path)
-		if err != nil {
-			log.Warnf("failed to unmount %s: %s", path, err)
-		}
+		ss.unmountPKI(clusterPKIGenerationPath(organizationID, clusterID, getTagValue(secret.Tags, pkiGenerationTagName)))
 
-		path = fmt.Sprintf("%s/%s", basePath, secretTypes.KubernetesFrontProxyCACommonName)
-		err = ss.Client.Vault().Sys().Unmount(path)
-		if err != nil {
-			log.Warnf("failed to unmount %s: %s", path, err)
+		// unmount the CAs of an unfinished CA rotation as well
+		if generation := getTagValue(secret.Tags, pkiNextGenerationTagName); generation != "" {
+			ss.unmountPKI(clusterPKIGenerationPath(organizationID, clusterID, generation))
 		}
 	}
 
@@ -656,84 +642,30 @@ func (ss *secretStore) generateValuesIfNeeded(organizationID uint, value *Create
 			return errors.New("clusterID is missing from the tags")
 		}
 
-		mountInput := vaultapi.MountInput{
-			Type:        "pki",
-			Description: fmt.Sprintf("root PKI engine for cluster %s", clusterID),
-			Config: vaultapi.MountConfigInput{
-				MaxLeaseTTL:     "43801h",
-				DefaultLeaseTTL: "43801h",
-			},
-		}
-
-		// Mount a separate PKI engine for the cluster
 		basePath := clusterPKIPath(organizationID, clusterID)
-		path := fmt.Sprintf("%s/ca", basePath)
-
-		err := ss.Client.Vault().Sys().Mount(path, &mountInput)
-		if err != nil {
-			return errors.Wrapf(err, "Error mounting pki engine for cluster %s", clusterID)
-		}
-
-		// Generate the root CA
-		rootCAData := map[string]interface{}{
-			"common_name": fmt.Sprintf("cluster-%s-ca", clusterID),
-		}
-
-		_, err = ss.Logical.Write(fmt.Sprintf("%s/root/generate/internal", path), rootCAData)
-		if err != nil {
-			// Unmount the pki engine first
-			if err := ss.Client.Vault().Sys().Unmount(path); err != nil {
-				log.Warnf("failed to unmount %s: %s", path, err)
-			}
-			return errors.Wrapf(err, "Error generating root CA for cluster %s", clusterID)
-		}
 
-		// Get root CA
-		rootCA, err := ss.Logical.Read(fmt.Sprintf("%s/cert/ca", path))
+		cas, err := ss.generatePKECAs(clusterID, basePath)
 		if err != nil {
-			// Unmount the pki engine first
-			if err := ss.Client.Vault().Sys().Unmount(path); err != nil {
-				log.Warnf("failed to unmount %s: %s", path, err)
-			}
-			return errors.Wrapf(err, "Error reading root CA for cluster %s", clusterID)
-		}
-		ca := rootCA.Data["certificate"].(string)
-
-		// Generate the intermediate CAs
-		kubernetesCA, err := ss.generateIntermediateCert(clusterID, basePath, secretTypes.KubernetesCACommonName)
-		if err != nil {
-			// Unmount the pki backend first
-			if err := ss.Client.Vault().Sys().Unmount(path); err != nil {
-				log.Warnf("failed to unmount %s: %s", path, err)
-			}
 			return err
 		}
 
-		etcdCA, err := ss.generateIntermediateCert(clusterID, basePath, secretTypes.EtcdCACommonName)
-		if err != nil {
-			// Unmount the pki backend first
-			if err := ss.Client.Vault().Sys().Unmount(path); err != nil {
-				log.Warnf("failed to unmount %s: %s", path, err)
-			}
-			return err
+		for key, v := range cas {
+			value.Values[key] = v
 		}
 
-		frontProxyCA, err := ss.generateIntermediateCert(clusterID, basePath, secretTypes.KubernetesFrontProxyCACommonName)
+		// The service account signing key has to be shared by all control plane nodes
+		saKey, saPub, err := generateServiceAccountKeyPair()
 		if err != nil {
 			// Unmount the pki backend first
+			path := fmt.Sprintf("%s/ca", basePath)
 			if err := ss.Client.Vault().Sys().Unmount(path); err != nil {
 				log.Warnf("failed to unmount %s: %s", path, err)
 			}
-			return err
+			return errors.Wrapf(err, "Error generating service account key pair for cluster %s", clusterID)
 		}
 
-		value.Values[secretTypes.KubernetesCAKey] = kubernetesCA.Key
-		value.Values[secretTypes.KubernetesCACert] = kubernetesCA.Cert + "\n" + ca
-		value.Values[secretTypes.KubernetesCASigningCert] = kubernetesCA.Cert
-		value.Values[secretTypes.EtcdCAKey] = etcdCA.Key
-		value.Values[secretTypes.EtcdCACert] = etcdCA.Cert + "\n" + ca
-		value.Values[secretTypes.FrontProxyCAKey] = frontProxyCA.Key
-		value.Values[secretTypes.FrontProxyCACert] = frontProxyCA.Cert + "\n" + ca
+		value.Values[secretTypes.SAKey] = saKey
+		value.Values[secretTypes.SAPub] = saPub
 	}
 
 	return nil
@@ -791,6 +723,23 @@ func (ss *secretStore) generateIntermediateCert(clusterID, basePath, commonName
 	}, nil
 }
 
+func generateServiceAccountKeyPair() (string, string, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", "", err
+	}
+
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})
+
+	return string(keyPEM), string(pubPEM), nil
+}
+
 type certificate struct {
 	Cert string
 	Key  string
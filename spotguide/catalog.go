@@ -0,0 +1,404 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/banzaicloud/pipeline/config"
+	yaml2 "github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const SpotguideCatalogTableName = "spotguide_catalogs"
+
+// SpotguideCatalog is an additional source of spotguides registered by an organization.
+// A catalog is either an organization (group, user) on a source code management provider, or a URL of an index file.
+type SpotguideCatalog struct {
+	ID               uint      `json:"id" gorm:"primary_key"`
+	OrganizationID   uint      `json:"organizationId" gorm:"unique_index:catalog_org_name"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+	Name             string    `json:"name" binding:"required" gorm:"unique_index:catalog_org_name"`
+	Provider         string    `json:"provider,omitempty"`
+	Owner            string    `json:"owner,omitempty"`
+	URL              string    `json:"url,omitempty"`
+	RequireSignature bool      `json:"requireSignature"`
+	PublicKey        string    `json:"publicKey,omitempty" gorm:"type:text"`
+}
+
+func (SpotguideCatalog) TableName() string {
+	return SpotguideCatalogTableName
+}
+
+// catalogIndex is the content of a catalog index file served on a URL.
+type catalogIndex struct {
+	Spotguides []struct {
+		Provider   string `json:"provider"`
+		Repository string `json:"repository"`
+	} `json:"spotguides"`
+}
+
+type validationError struct {
+	msg string
+}
+
+func (e *validationError) Error() string {
+	return e.msg
+}
+
+func (e *validationError) IsInvalid() bool {
+	return true
+}
+
+func (c *SpotguideCatalog) validate() error {
+	if (c.Owner == "") == (c.URL == "") {
+		return &validationError{msg: "exactly one of owner or url has to be specified"}
+	}
+
+	if c.Owner != "" && !isSCMProvider(c.Provider) {
+		return &validationError{msg: fmt.Sprintf("unknown catalog provider %q", c.Provider)}
+	}
+
+	if c.URL != "" {
+		if err := validateCatalogURL(c.URL); err != nil {
+			return &validationError{msg: "invalid catalog url: " + err.Error()}
+		}
+	}
+
+	if c.RequireSignature {
+		if _, err := newManifestVerifier(c.PublicKey); err != nil {
+			return &validationError{msg: "invalid public key: " + err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// validateCatalogURL only accepts HTTPS URLs of hosts which are not (obviously) internal.
+// The resolved addresses are checked again when the index is downloaded.
+func validateCatalogURL(catalogURL string) error {
+	u, err := url.Parse(catalogURL)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "https" {
+		return errors.New("only https urls are allowed")
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("missing host")
+	}
+
+	if strings.ToLower(host) == "localhost" || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return errors.Errorf("host %s is not public", host)
+	}
+
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return errors.Errorf("host %s is not public", host)
+	}
+
+	return nil
+}
+
+// nonPublicNetworks are the IP ranges catalog indexes can't be downloaded from,
+// loopback, link-local, multicast and unspecified addresses are checked separately.
+var nonPublicNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dialPublic connects to the address only if all the IPs of the host are public,
+// the resolved IP is dialed so that the check can't be bypassed by DNS rebinding.
+func dialPublic(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ips) == 0 {
+		return nil, errors.Errorf("no addresses found for host %s", host)
+	}
+
+	for _, ip := range ips {
+		if !isPublicIP(ip.IP) {
+			return nil, errors.Errorf("host %s resolves to non-public address %s", host, ip.IP)
+		}
+	}
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+
+	return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+}
+
+// catalogHTTPClient downloads catalog indexes from public HTTPS URLs only, redirects included.
+var catalogHTTPClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		DialContext:         dialPublic,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(request *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("too many redirects")
+		}
+
+		return validateCatalogURL(request.URL.String())
+	},
+}
+
+func isSCMProvider(provider string) bool {
+	switch provider {
+	case SCMProviderGithub, SCMProviderGitlab, SCMProviderGit:
+		return true
+	default:
+		return false
+	}
+}
+
+// verifier returns the release manifest verifier of the catalog, or nil if signatures are not required.
+func (c *SpotguideCatalog) verifier() (*manifestVerifier, error) {
+	if !c.RequireSignature {
+		return nil, nil
+	}
+
+	return newManifestVerifier(c.PublicKey)
+}
+
+// ListCatalogs returns the spotguide catalogs of an organization.
+func (s *SpotguideManager) ListCatalogs(orgID uint) ([]SpotguideCatalog, error) {
+	var catalogs []SpotguideCatalog
+
+	err := s.db.Where(&SpotguideCatalog{OrganizationID: orgID}).Find(&catalogs).Error
+
+	return catalogs, errors.Wrap(err, "failed to list spotguide catalogs")
+}
+
+// CreateCatalog registers a new spotguide catalog for an organization.
+func (s *SpotguideManager) CreateCatalog(orgID uint, catalog *SpotguideCatalog) error {
+	catalog.ID = 0
+	catalog.OrganizationID = orgID
+
+	if err := catalog.validate(); err != nil {
+		return err
+	}
+
+	return errors.Wrap(s.db.Create(catalog).Error, "failed to create spotguide catalog")
+}
+
+// DeleteCatalog removes a spotguide catalog and the spotguides scraped from it.
+func (s *SpotguideManager) DeleteCatalog(orgID uint, catalogID uint) error {
+	catalog := SpotguideCatalog{}
+
+	err := s.db.Where(&SpotguideCatalog{ID: catalogID, OrganizationID: orgID}).First(&catalog).Error
+	if err != nil {
+		return errors.Wrap(err, "failed to find spotguide catalog")
+	}
+
+	err = s.db.Where(&SpotguideRepo{OrganizationID: orgID, CatalogID: catalog.ID}).Delete(SpotguideRepo{}).Error
+	if err != nil {
+		return errors.Wrap(err, "failed to delete spotguides of catalog")
+	}
+
+	return errors.Wrap(s.db.Delete(&catalog).Error, "failed to delete spotguide catalog")
+}
+
+// catalogSources returns the spotguide sources of the catalogs registered by the organization.
+func (s *SpotguideManager) catalogSources(orgID uint, userID uint) ([]spotguideSource, error) {
+	catalogs, err := s.ListCatalogs(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []spotguideSource
+
+	for _, catalog := range catalogs {
+		catalogSources, err := catalog.sources(userID)
+		if err != nil {
+			// a broken catalog shouldn't prevent scraping the others, its spotguides are kept as they are
+			log.Warnf("failed to scrape spotguide catalog %s: %s", catalog.Name, err)
+			sources = append(sources, spotguideSource{catalogID: catalog.ID, unavailable: true})
+			continue
+		}
+
+		sources = append(sources, catalogSources...)
+	}
+
+	return sources, nil
+}
+
+// sources returns the spotguide sources of a single catalog.
+func (c *SpotguideCatalog) sources(userID uint) ([]spotguideSource, error) {
+	verifier, err := c.verifier()
+	if err != nil {
+		return nil, emperror.Wrap(err, "invalid public key")
+	}
+
+	if c.Owner != "" {
+		provider, err := newSCMProviderForUser(c.Provider, userID)
+		if err != nil {
+			return nil, emperror.Wrap(err, "failed to create provider")
+		}
+
+		repositories, err := provider.ListSpotguideRepositories(c.Owner)
+		if err != nil {
+			return nil, emperror.Wrap(err, "failed to list repositories")
+		}
+
+		return []spotguideSource{{
+			catalogID:    c.ID,
+			providerName: c.Provider,
+			provider:     provider,
+			repositories: repositories,
+			verifier:     verifier,
+		}}, nil
+	}
+
+	index, err := downloadCatalogIndex(c.URL)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to download index")
+	}
+
+	// the index may list repositories from several providers
+	sourcesByProvider := map[string]*spotguideSource{}
+	var providerNames []string
+
+	for _, spotguide := range index.Spotguides {
+		source, ok := sourcesByProvider[spotguide.Provider]
+		if !ok {
+			provider, err := newSCMProviderForUser(spotguide.Provider, userID)
+			if err != nil {
+				return nil, emperror.Wrap(err, "failed to create provider")
+			}
+
+			source = &spotguideSource{
+				catalogID:    c.ID,
+				providerName: spotguide.Provider,
+				provider:     provider,
+				verifier:     verifier,
+			}
+			sourcesByProvider[spotguide.Provider] = source
+			providerNames = append(providerNames, spotguide.Provider)
+		}
+
+		source.repositories = append(source.repositories, scmRepository{FullName: spotguide.Repository})
+	}
+
+	var sources []spotguideSource
+	for _, providerName := range providerNames {
+		sources = append(sources, *sourcesByProvider[providerName])
+	}
+
+	return sources, nil
+}
+
+func downloadCatalogIndex(url string) (*catalogIndex, error) {
+	if err := validateCatalogURL(url); err != nil {
+		return nil, errors.Wrap(err, "invalid catalog index url")
+	}
+
+	response, err := catalogHTTPClient.Get(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch catalog index")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to fetch catalog index: %s", response.Status)
+	}
+
+	indexRaw, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read catalog index")
+	}
+
+	var index catalogIndex
+	if err := yaml2.Unmarshal(indexRaw, &index); err != nil {
+		return nil, errors.Wrap(err, "failed to parse catalog index")
+	}
+
+	for _, spotguide := range index.Spotguides {
+		if !isSCMProvider(spotguide.Provider) {
+			return nil, errors.Errorf("unknown provider %q of spotguide %s", spotguide.Provider, spotguide.Repository)
+		}
+	}
+
+	return &index, nil
+}
+
+// releaseVerifier returns the release manifest verifier of a scraped spotguide, or nil if signatures are not required.
+func (s *SpotguideManager) releaseVerifier(sourceRepo *SpotguideRepo) (*manifestVerifier, error) {
+	if sourceRepo.CatalogID != 0 {
+		catalog := SpotguideCatalog{}
+
+		err := s.db.Where(&SpotguideCatalog{ID: sourceRepo.CatalogID, OrganizationID: sourceRepo.OrganizationID}).First(&catalog).Error
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find spotguide catalog")
+		}
+
+		return catalog.verifier()
+	}
+
+	if s.sharedLibraryOrganization != nil && sourceRepo.OrganizationID == s.sharedLibraryOrganization.ID {
+		return sharedLibraryVerifier()
+	}
+
+	return nil, nil
+}
+
+// sharedLibraryVerifier returns the release manifest verifier of the shared library, or nil if signatures are not required.
+func sharedLibraryVerifier() (*manifestVerifier, error) {
+	publicKey := viper.GetString(config.SpotguideSharedLibraryPublicKey)
+	if publicKey == "" {
+		return nil, nil
+	}
+
+	return newManifestVerifier(publicKey)
+}
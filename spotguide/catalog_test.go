@@ -0,0 +1,136 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCatalogURL(t *testing.T) {
+	for _, catalogURL := range []string{
+		"https://spotguides.example.com/index.yaml",
+		"https://203.0.113.10/index.yaml",
+	} {
+		assert.NoError(t, validateCatalogURL(catalogURL), catalogURL)
+	}
+
+	for _, catalogURL := range []string{
+		"http://spotguides.example.com/index.yaml",
+		"file:///etc/passwd",
+		"https:///index.yaml",
+		"https://localhost/index.yaml",
+		"https://127.0.0.1/index.yaml",
+		"https://10.0.0.1/index.yaml",
+		"https://192.168.1.1/index.yaml",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/index.yaml",
+		"https://[fd00::1]/index.yaml",
+	} {
+		assert.Error(t, validateCatalogURL(catalogURL), catalogURL)
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	assert.True(t, isPublicIP(net.ParseIP("8.8.8.8")))
+	assert.True(t, isPublicIP(net.ParseIP("2001:4860:4860::8888")))
+
+	assert.False(t, isPublicIP(net.ParseIP("172.20.0.1")))
+	assert.False(t, isPublicIP(net.ParseIP("100.64.0.1")))
+	assert.False(t, isPublicIP(net.ParseIP("fe80::1")))
+	assert.False(t, isPublicIP(net.ParseIP("0.0.0.0")))
+}
+
+func TestDownloadCatalogIndex_InternalAddress(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("spotguides: []"))
+	}))
+	defer server.Close()
+
+	_, err := downloadCatalogIndex(server.URL)
+	assert.Error(t, err)
+}
+
+type scrapeSCMProviderStub struct {
+	scmProvider
+
+	releases []scmRelease
+}
+
+func (p scrapeSCMProviderStub) ListReleases(repository string) ([]scmRelease, error) {
+	return p.releases, nil
+}
+
+func (p scrapeSCMProviderStub) DownloadFile(repository, path, ref string) ([]byte, error) {
+	if path == SpotguideYAMLPath {
+		return []byte("name: " + repository), nil
+	}
+
+	return nil, errors.New("file not found")
+}
+
+func TestScrapeSpotguides_NameCollision(t *testing.T) {
+	manager, _ := newTestLaunch(t, nil)
+	require.NoError(t, manager.db.AutoMigrate(&SpotguideRepo{}).Error)
+
+	org := &auth.Organization{ID: 1}
+	provider := scrapeSCMProviderStub{releases: []scmRelease{{Tag: "0.1.0"}}}
+
+	err := manager.scrapeSpotguides(org, []spotguideSource{
+		{providerName: SCMProviderGithub, provider: provider, repositories: []scmRepository{{FullName: "banzaicloud/spotguide-nodejs"}}},
+		{catalogID: 1, providerName: SCMProviderGitlab, provider: provider, repositories: []scmRepository{{FullName: "banzaicloud/spotguide-nodejs"}, {FullName: "acme/spotguide-java"}}},
+	})
+	require.NoError(t, err)
+
+	var spotguides []SpotguideRepo
+	require.NoError(t, manager.db.Order("name").Find(&spotguides).Error)
+
+	require.Len(t, spotguides, 2)
+	assert.Equal(t, "acme/spotguide-java", spotguides[0].Name)
+	assert.Equal(t, uint(1), spotguides[0].CatalogID)
+	assert.Equal(t, "banzaicloud/spotguide-nodejs", spotguides[1].Name)
+	assert.Equal(t, uint(0), spotguides[1].CatalogID)
+	assert.Equal(t, SCMProviderGithub, spotguides[1].Provider)
+}
+
+func TestScrapeSpotguides_UnavailableCatalog(t *testing.T) {
+	manager, _ := newTestLaunch(t, nil)
+	require.NoError(t, manager.db.AutoMigrate(&SpotguideRepo{}).Error)
+
+	org := &auth.Organization{ID: 1}
+
+	for _, repo := range []SpotguideRepo{
+		{OrganizationID: org.ID, Name: "banzaicloud/spotguide-nodejs", Version: "0.1.0", CatalogID: 1},
+		{OrganizationID: org.ID, Name: "banzaicloud/spotguide-java", Version: "0.1.0", CatalogID: 2},
+	} {
+		require.NoError(t, manager.db.Create(&repo).Error)
+	}
+
+	err := manager.scrapeSpotguides(org, []spotguideSource{{catalogID: 1, unavailable: true}})
+	require.NoError(t, err)
+
+	var spotguides []SpotguideRepo
+	require.NoError(t, manager.db.Find(&spotguides).Error)
+
+	require.Len(t, spotguides, 1)
+	assert.Equal(t, "banzaicloud/spotguide-nodejs", spotguides[0].Name)
+}
@@ -0,0 +1,501 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/model"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/banzaicloud/pipeline/secret/verify"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const SpotguideLaunchTableName = "spotguide_launches"
+const SpotguideLaunchStepTableName = "spotguide_launch_steps"
+
+// Spotguide launch statuses
+const (
+	LaunchStatusValidated  = "VALIDATED"
+	LaunchStatusRunning    = "RUNNING"
+	LaunchStatusSucceeded  = "SUCCEEDED"
+	LaunchStatusFailed     = "FAILED"
+	LaunchStatusRolledBack = "ROLLED_BACK"
+)
+
+// Spotguide launch step statuses
+const (
+	LaunchStepStatusPending        = "PENDING"
+	LaunchStepStatusRunning        = "RUNNING"
+	LaunchStepStatusSucceeded      = "SUCCEEDED"
+	LaunchStepStatusFailed         = "FAILED"
+	LaunchStepStatusRolledBack     = "ROLLED_BACK"
+	LaunchStepStatusRollbackFailed = "ROLLBACK_FAILED"
+)
+
+// Spotguide launch steps
+const (
+	LaunchStepCreateSecrets    = "create_secrets"
+	LaunchStepCreateRepository = "create_repository"
+	LaunchStepEnableCICD       = "enable_cicd"
+	LaunchStepAddContent       = "add_content"
+)
+
+// SpotguideLaunch is the tracked record of a spotguide launch.
+type SpotguideLaunch struct {
+	ID               uint                  `json:"id" gorm:"primary_key"`
+	OrganizationID   uint                  `json:"organizationId" gorm:"index"`
+	UserID           uint                  `json:"userId"`
+	CreatedAt        time.Time             `json:"createdAt"`
+	UpdatedAt        time.Time             `json:"updatedAt"`
+	SpotguideName    string                `json:"spotguideName"`
+	SpotguideVersion string                `json:"spotguideVersion"`
+	RepoProvider     string                `json:"repoProvider"`
+	RepoFullname     string                `json:"repoFullname"`
+	DryRun           bool                  `json:"dryRun" gorm:"-"`
+	Status           string                `json:"status"`
+	StatusMessage    string                `json:"statusMessage,omitempty" gorm:"type:text"`
+	Request          []byte                `json:"-" gorm:"type:text"`
+	Steps            []SpotguideLaunchStep `json:"steps" gorm:"foreignkey:LaunchID"`
+}
+
+func (SpotguideLaunch) TableName() string {
+	return SpotguideLaunchTableName
+}
+
+// SpotguideLaunchStep is the status of a single step of a spotguide launch.
+type SpotguideLaunchStep struct {
+	ID         uint       `json:"-" gorm:"primary_key"`
+	LaunchID   uint       `json:"-" gorm:"index"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Message    string     `json:"message,omitempty" gorm:"type:text"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+func (SpotguideLaunchStep) TableName() string {
+	return SpotguideLaunchStepTableName
+}
+
+// launchStep is an executable step of a spotguide launch with its undo action.
+type launchStep struct {
+	name     string
+	run      func() error
+	rollback func() error
+}
+
+// LaunchSpotguide validates the launch request and starts the launch in the background.
+// In dry-run mode only the validation is done, without any side effects.
+func (s *SpotguideManager) LaunchSpotguide(request *LaunchRequest, org *auth.Organization, user *auth.User) (*SpotguideLaunch, error) {
+	sourceRepo, err := s.GetSpotguide(org.ID, request.SpotguideName, request.SpotguideVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find spotguide repo")
+	}
+
+	// LaunchRequest might not have the version
+	request.SpotguideVersion = sourceRepo.Version
+
+	// LaunchRequest might not have the provider, launch into the organization's spotguide provider by default
+	if request.RepoProvider == "" {
+		settings, err := s.GetSettings(org)
+		if err != nil {
+			return nil, err
+		}
+
+		request.RepoProvider = settings.Provider
+	}
+
+	sourceProvider, err := s.sourceProvider(sourceRepo, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create spotguide source provider")
+	}
+
+	targetProvider, err := newSCMProviderForUser(request.RepoProvider, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create spotguide target provider")
+	}
+
+	verifier, err := s.releaseVerifier(sourceRepo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create spotguide release verifier")
+	}
+
+	if err := s.validateLaunchRequest(request, sourceRepo, org.ID); err != nil {
+		return nil, err
+	}
+
+	launch := &SpotguideLaunch{
+		OrganizationID:   org.ID,
+		UserID:           user.ID,
+		SpotguideName:    request.SpotguideName,
+		SpotguideVersion: request.SpotguideVersion,
+		RepoProvider:     request.RepoProvider,
+		RepoFullname:     request.RepoFullname(),
+		DryRun:           request.DryRun,
+		Status:           LaunchStatusRunning,
+	}
+
+	if request.DryRun {
+		launch.Status = LaunchStatusValidated
+		return launch, nil
+	}
+
+	cicdClient := auth.NewCICDClient(user.APIToken)
+
+	var secretIDs []string
+
+	deleteSecrets := func() error {
+		for _, secretID := range secretIDs {
+			if err := secret.Store.Delete(org.ID, secretID); err != nil {
+				return errors.Wrap(err, "failed to delete spotguide secret")
+			}
+		}
+		return nil
+	}
+
+	steps := []launchStep{
+		{
+			name: LaunchStepCreateSecrets,
+			run: func() error {
+				var err error
+				secretIDs, err = createSecrets(request, org.ID, user.ID)
+				if err != nil {
+					// failed steps are not rolled back, delete the secrets created so far
+					if err := deleteSecrets(); err != nil {
+						log.Errorf("failed to clean up spotguide secrets of %s: %s", request.RepoFullname(), err.Error())
+					}
+
+					return errors.Wrap(err, "failed to create secrets for spotguide")
+				}
+
+				return nil
+			},
+			rollback: deleteSecrets,
+		},
+		{
+			name: LaunchStepCreateRepository,
+			run: func() error {
+				err := targetProvider.CreateRepository(request.RepoOrganization, request.RepoName, request.RepoPrivate)
+				if err != nil {
+					return errors.Wrap(err, "failed to create spotguide repository")
+				}
+
+				log.Infof("created spotguide repository: %s", request.RepoFullname())
+				return nil
+			},
+			rollback: func() error {
+				return targetProvider.DeleteRepository(request.RepoFullname())
+			},
+		},
+		{
+			name: LaunchStepEnableCICD,
+			run: func() error {
+				return errors.Wrap(enableCICD(cicdClient, request, org.Name), "failed to enable CI/CD for spotguide")
+			},
+			rollback: func() error {
+				return errors.Wrap(cicdClient.RepoDel(request.RepoOrganization, request.RepoName), "failed to disable CI/CD for spotguide")
+			},
+		},
+		{
+			name: LaunchStepAddContent,
+			run: func() error {
+				err := addSpotguideContent(sourceProvider, targetProvider, request, sourceRepo, verifier)
+				return errors.Wrap(err, "failed to add spotguide content to repository")
+			},
+		},
+	}
+
+	// The request is kept to be able to reproduce the content of the repository on upgrades
+	launch.Request, err = marshalLaunchRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, step := range steps {
+		launch.Steps = append(launch.Steps, SpotguideLaunchStep{Name: step.name, Status: LaunchStepStatusPending})
+	}
+
+	if err := s.db.Create(launch).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to persist spotguide launch")
+	}
+
+	// the launch is updated in the background, return a snapshot of it
+	result := *launch
+	result.Steps = append([]SpotguideLaunchStep(nil), launch.Steps...)
+
+	go s.executeLaunch(launch, steps)
+
+	return &result, nil
+}
+
+// marshalLaunchRequest serializes the launch request without the secret values.
+func marshalLaunchRequest(request *LaunchRequest) ([]byte, error) {
+	requestCopy := *request
+	requestCopy.Secrets = nil
+
+	for _, secretRequest := range request.Secrets {
+		secretCopy := *secretRequest
+		secretCopy.Values = nil
+		requestCopy.Secrets = append(requestCopy.Secrets, &secretCopy)
+	}
+
+	requestRaw, err := json.Marshal(requestCopy)
+	return requestRaw, errors.Wrap(err, "failed to marshal launch request")
+}
+
+// executeLaunch runs the steps of the launch in order, and rolls back the succeeded ones in reverse order on failure.
+func (s *SpotguideManager) executeLaunch(launch *SpotguideLaunch, steps []launchStep) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("spotguide launch %s panicked: %v", launch.RepoFullname, r)
+			launch.StatusMessage = fmt.Sprintf("launch failed unexpectedly: %v", r)
+			s.updateLaunchStatus(launch, LaunchStatusFailed)
+		}
+	}()
+
+	failed := -1
+
+	for i, step := range steps {
+		s.updateLaunchStep(&launch.Steps[i], LaunchStepStatusRunning, "")
+
+		if err := step.run(); err != nil {
+			log.Errorf("failed to launch spotguide %s: %s", launch.RepoFullname, err.Error())
+			s.updateLaunchStep(&launch.Steps[i], LaunchStepStatusFailed, err.Error())
+			launch.StatusMessage = err.Error()
+			failed = i
+			break
+		}
+
+		s.updateLaunchStep(&launch.Steps[i], LaunchStepStatusSucceeded, "")
+	}
+
+	if failed < 0 {
+		s.updateLaunchStatus(launch, LaunchStatusSucceeded)
+		return
+	}
+
+	status := LaunchStatusRolledBack
+
+	// the failed step has no effect to undo: it either did nothing or cleaned up after itself
+	for i := failed - 1; i >= 0; i-- {
+		if steps[i].rollback == nil {
+			continue
+		}
+
+		if err := steps[i].rollback(); err != nil {
+			log.Errorf("failed to roll back spotguide launch step %s of %s: %s", steps[i].name, launch.RepoFullname, err.Error())
+			s.updateLaunchStep(&launch.Steps[i], LaunchStepStatusRollbackFailed, err.Error())
+			status = LaunchStatusFailed
+			continue
+		}
+
+		s.updateLaunchStep(&launch.Steps[i], LaunchStepStatusRolledBack, "")
+	}
+
+	s.updateLaunchStatus(launch, status)
+}
+
+func (s *SpotguideManager) updateLaunchStep(step *SpotguideLaunchStep, status string, message string) {
+	now := time.Now()
+
+	switch status {
+	case LaunchStepStatusRunning:
+		step.StartedAt = &now
+	case LaunchStepStatusSucceeded, LaunchStepStatusFailed:
+		step.FinishedAt = &now
+	}
+
+	step.Status = status
+	if message != "" {
+		step.Message = message
+	}
+
+	if err := s.db.Save(step).Error; err != nil {
+		log.Errorf("failed to update spotguide launch step %s: %s", step.Name, err.Error())
+	}
+}
+
+func (s *SpotguideManager) updateLaunchStatus(launch *SpotguideLaunch, status string) {
+	err := s.db.Model(launch).Updates(map[string]interface{}{
+		"status":         status,
+		"status_message": launch.StatusMessage,
+	}).Error
+	if err != nil {
+		log.Errorf("failed to update spotguide launch %d: %s", launch.ID, err.Error())
+	}
+}
+
+// FailInterruptedLaunches marks the launches left running by a previous Pipeline instance as failed,
+// since their background execution was lost and they will never finish.
+func (s *SpotguideManager) FailInterruptedLaunches() error {
+	err := s.db.Model(&SpotguideLaunch{}).
+		Where("status = ?", LaunchStatusRunning).
+		Updates(map[string]interface{}{
+			"status":         LaunchStatusFailed,
+			"status_message": "launch was interrupted by a Pipeline restart",
+		}).Error
+	if err != nil {
+		return errors.Wrap(err, "failed to update interrupted spotguide launches")
+	}
+
+	err = s.db.Model(&SpotguideLaunchStep{}).
+		Where("status = ?", LaunchStepStatusRunning).
+		Updates(map[string]interface{}{
+			"status":  LaunchStepStatusFailed,
+			"message": "launch was interrupted by a Pipeline restart",
+		}).Error
+
+	return errors.Wrap(err, "failed to update interrupted spotguide launch steps")
+}
+
+// GetLaunch returns a spotguide launch with the status of its steps.
+func (s *SpotguideManager) GetLaunch(orgID uint, launchID uint) (*SpotguideLaunch, error) {
+	launch := SpotguideLaunch{}
+
+	err := s.db.
+		Preload("Steps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Where(&SpotguideLaunch{ID: launchID, OrganizationID: orgID}).
+		First(&launch).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find spotguide launch")
+	}
+
+	return &launch, nil
+}
+
+// validateLaunchRequest checks the secrets, the cluster request and the question answers
+// of a launch request without side effects, all the invalid fields are reported at once.
+func (s *SpotguideManager) validateLaunchRequest(request *LaunchRequest, sourceRepo *SpotguideRepo, orgID uint) error {
+	validationErr := &LaunchValidationError{}
+
+	requestSecretIDs := map[string]bool{}
+
+	for i, secretRequest := range request.Secrets {
+		field := fmt.Sprintf("secrets[%d]", i)
+
+		if errorList := validation.IsDNS1123Subdomain(secretRequest.Name); errorList != nil {
+			validationErr.add(field+".name", "invalid secret name %q: %s", secretRequest.Name, errorList[0])
+			continue
+		}
+
+		verifier := verify.NewVerifier(secretRequest.Type, secretRequest.Values)
+		if err := secretRequest.ValidateAsNew(verifier); err != nil {
+			validationErr.add(field, "invalid secret %q: %s", secretRequest.Name, err.Error())
+			continue
+		}
+
+		secretID := secret.GenerateSecretID(secretRequest)
+		if _, err := secret.Store.Get(orgID, secretID); err == nil {
+			validationErr.add(field+".name", "secret %q already exists", secretRequest.Name)
+		} else if err != secret.ErrSecretNotExists {
+			return errors.Wrap(err, "failed to check secret")
+		}
+
+		requestSecretIDs[secretID] = true
+	}
+
+	if err := validateClusterRequest(request, orgID, requestSecretIDs, validationErr); err != nil {
+		return err
+	}
+
+	lookup := questionLookup{
+		secretType: func(name string) (string, error) {
+			secretItem, err := secret.Store.Get(orgID, secret.GenerateSecretIDFromName(name))
+			if err == secret.ErrSecretNotExists {
+				return "", nil
+			} else if err != nil {
+				return "", err
+			}
+
+			return secretItem.Type, nil
+		},
+		clusterCloud: func(name string) (string, error) {
+			var cluster model.ClusterModel
+
+			err := s.db.Where(&model.ClusterModel{OrganizationId: orgID, Name: name}).First(&cluster).Error
+			if gorm.IsRecordNotFoundError(err) {
+				return "", nil
+			} else if err != nil {
+				return "", err
+			}
+
+			return cluster.Cloud, nil
+		},
+	}
+
+	if err := validateAnswers(sourceRepo.Questions, request, lookup, validationErr); err != nil {
+		return err
+	}
+
+	if len(validationErr.Fields) > 0 {
+		return validationErr
+	}
+
+	return nil
+}
+
+func validateClusterRequest(request *LaunchRequest, orgID uint, requestSecretIDs map[string]bool, validationErr *LaunchValidationError) error {
+	if request.Cluster == nil {
+		validationErr.add("cluster", "is required")
+		return nil
+	}
+
+	// The cluster in the request is the client representation, validate it as a cluster create request
+	clusterJSON, err := json.Marshal(request.Cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal cluster request")
+	}
+
+	var clusterRequest pkgCluster.CreateClusterRequest
+	if err := json.Unmarshal(clusterJSON, &clusterRequest); err != nil {
+		validationErr.add("cluster", "invalid cluster request: %s", err.Error())
+		return nil
+	}
+
+	if clusterRequest.Properties == nil {
+		validationErr.add("cluster.properties", "is required")
+		return nil
+	}
+
+	if err := clusterRequest.Validate(); err != nil {
+		validationErr.add("cluster", "invalid cluster request: %s", err.Error())
+		return nil
+	}
+
+	// The cluster secret is either an existing one or created by the launch
+	secretID := clusterRequest.SecretId
+	if secretID == "" && clusterRequest.SecretName != "" {
+		secretID = secret.GenerateSecretIDFromName(clusterRequest.SecretName)
+	}
+
+	if secretID != "" && !requestSecretIDs[secretID] {
+		if _, err := secret.Store.Get(orgID, secretID); err == secret.ErrSecretNotExists {
+			validationErr.add("cluster.secretId", "cluster secret doesn't exist")
+		} else if err != nil {
+			return errors.Wrap(err, "failed to check cluster secret")
+		}
+	}
+
+	return nil
+}
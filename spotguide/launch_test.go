@@ -0,0 +1,109 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLaunch(t *testing.T, steps []launchStep) (*SpotguideManager, *SpotguideLaunch) {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&SpotguideLaunch{}, &SpotguideLaunchStep{}).Error)
+
+	launch := &SpotguideLaunch{RepoFullname: "banzaicloud/spotguide-nodejs", Status: LaunchStatusRunning}
+	for _, step := range steps {
+		launch.Steps = append(launch.Steps, SpotguideLaunchStep{Name: step.name, Status: LaunchStepStatusPending})
+	}
+	require.NoError(t, db.Create(launch).Error)
+
+	return &SpotguideManager{db: db}, launch
+}
+
+func TestExecuteLaunch_RollsBackSucceededSteps(t *testing.T) {
+	var rolledBack []string
+
+	newStep := func(name string, err error) launchStep {
+		return launchStep{
+			name: name,
+			run:  func() error { return err },
+			rollback: func() error {
+				rolledBack = append(rolledBack, name)
+				return nil
+			},
+		}
+	}
+
+	steps := []launchStep{
+		newStep(LaunchStepCreateSecrets, nil),
+		newStep(LaunchStepCreateRepository, nil),
+		newStep(LaunchStepEnableCICD, errors.New("failed to enable CI/CD")),
+		newStep(LaunchStepAddContent, nil),
+	}
+
+	manager, launch := newTestLaunch(t, steps)
+
+	manager.executeLaunch(launch, steps)
+
+	assert.Equal(t, []string{LaunchStepCreateRepository, LaunchStepCreateSecrets}, rolledBack)
+
+	stored, err := manager.GetLaunch(0, launch.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, LaunchStatusRolledBack, stored.Status)
+	assert.Equal(t, LaunchStepStatusRolledBack, stored.Steps[0].Status)
+	assert.Equal(t, LaunchStepStatusRolledBack, stored.Steps[1].Status)
+	assert.Equal(t, LaunchStepStatusFailed, stored.Steps[2].Status)
+	assert.Equal(t, LaunchStepStatusPending, stored.Steps[3].Status)
+}
+
+func TestExecuteLaunch_Panic(t *testing.T) {
+	steps := []launchStep{
+		{
+			name: LaunchStepCreateSecrets,
+			run:  func() error { panic("boom") },
+		},
+	}
+
+	manager, launch := newTestLaunch(t, steps)
+
+	manager.executeLaunch(launch, steps)
+
+	stored, err := manager.GetLaunch(0, launch.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, LaunchStatusFailed, stored.Status)
+}
+
+func TestFailInterruptedLaunches(t *testing.T) {
+	steps := []launchStep{{name: LaunchStepCreateSecrets}}
+
+	manager, launch := newTestLaunch(t, steps)
+	manager.updateLaunchStep(&launch.Steps[0], LaunchStepStatusRunning, "")
+
+	require.NoError(t, manager.FailInterruptedLaunches())
+
+	stored, err := manager.GetLaunch(0, launch.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, LaunchStatusFailed, stored.Status)
+	assert.Equal(t, LaunchStepStatusFailed, stored.Steps[0].Status)
+}
@@ -0,0 +1,185 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"strings"
+
+	yaml2 "github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// ManifestPath is the path of the release manifest, which lists the SHA-256 checksums of the release files.
+const ManifestPath = ".banzaicloud/manifest.yaml"
+
+// ManifestSignaturePath is the path of the base64 encoded detached signature of the release manifest.
+const ManifestSignaturePath = ".banzaicloud/manifest.yaml.sig"
+
+// releaseManifest is the content of the release manifest.
+type releaseManifest struct {
+	// Files maps the paths of the release files to their hex encoded SHA-256 checksums
+	Files map[string]string `json:"files"`
+}
+
+// manifestVerifier checks the signed manifests of spotguide releases with a public key.
+type manifestVerifier struct {
+	publicKey crypto.PublicKey
+}
+
+// newManifestVerifier creates a verifier from a PEM encoded (PKIX) RSA or ECDSA public key.
+func newManifestVerifier(publicKeyPEM string) (*manifestVerifier, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, errors.New("failed to decode PEM public key")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public key")
+	}
+
+	switch publicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, errors.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	return &manifestVerifier{publicKey: publicKey}, nil
+}
+
+// verifyManifest checks the detached signature of the manifest and parses it.
+func (v *manifestVerifier) verifyManifest(manifestRaw, signatureRaw []byte) (*releaseManifest, error) {
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signatureRaw)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode manifest signature")
+	}
+
+	digest := sha256.Sum256(manifestRaw)
+
+	switch publicKey := v.publicKey.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, errors.New("invalid manifest signature")
+		}
+
+	case *ecdsa.PublicKey:
+		var ecdsaSignature struct {
+			R, S *big.Int
+		}
+		if _, err := asn1.Unmarshal(signature, &ecdsaSignature); err != nil {
+			return nil, errors.Wrap(err, "failed to parse manifest signature")
+		}
+
+		if !ecdsa.Verify(publicKey, digest[:], ecdsaSignature.R, ecdsaSignature.S) {
+			return nil, errors.New("invalid manifest signature")
+		}
+	}
+
+	var manifest releaseManifest
+	if err := yaml2.Unmarshal(manifestRaw, &manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to parse manifest")
+	}
+
+	return &manifest, nil
+}
+
+// verifyFile checks the checksum of a single release file.
+func (m *releaseManifest) verifyFile(path string, content []byte) error {
+	checksum, ok := m.Files[path]
+	if !ok {
+		return errors.Errorf("file %s is not listed in the manifest", path)
+	}
+
+	actual := sha256.Sum256(content)
+	if hex.EncodeToString(actual[:]) != strings.ToLower(checksum) {
+		return errors.Errorf("checksum mismatch of file %s", path)
+	}
+
+	return nil
+}
+
+// verifyFiles checks that the release files are exactly the ones listed in the manifest.
+func (m *releaseManifest) verifyFiles(files []scmFile) error {
+	seen := map[string]bool{}
+
+	for _, file := range files {
+		if file.Path == ManifestPath || file.Path == ManifestSignaturePath {
+			continue
+		}
+
+		if err := m.verifyFile(file.Path, file.Content); err != nil {
+			return err
+		}
+
+		seen[file.Path] = true
+	}
+
+	for path := range m.Files {
+		if !seen[path] {
+			return errors.Errorf("file %s listed in the manifest is missing", path)
+		}
+	}
+
+	return nil
+}
+
+// verifyRelease downloads and checks the signed manifest of a release.
+func (v *manifestVerifier) verifyRelease(provider scmProvider, repository, ref string) (*releaseManifest, error) {
+	manifestRaw, err := provider.DownloadFile(repository, ManifestPath, ref)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download release manifest")
+	}
+
+	signatureRaw, err := provider.DownloadFile(repository, ManifestSignaturePath, ref)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download release manifest signature")
+	}
+
+	return v.verifyManifest(manifestRaw, signatureRaw)
+}
+
+// verifyReleaseFiles checks the signed manifest of a release, and that the files match it.
+func (v *manifestVerifier) verifyReleaseFiles(files []scmFile) error {
+	var manifestRaw, signatureRaw []byte
+	for _, file := range files {
+		switch file.Path {
+		case ManifestPath:
+			manifestRaw = file.Content
+		case ManifestSignaturePath:
+			signatureRaw = file.Content
+		}
+	}
+
+	if manifestRaw == nil || signatureRaw == nil {
+		return errors.New("release has no signed manifest")
+	}
+
+	manifest, err := v.verifyManifest(manifestRaw, signatureRaw)
+	if err != nil {
+		return err
+	}
+
+	return manifest.verifyFiles(files)
+}
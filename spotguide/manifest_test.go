@@ -0,0 +1,101 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func signedRelease(t *testing.T, key *ecdsa.PrivateKey, files map[string]string) []scmFile {
+	manifest := "files:\n"
+	for path, content := range files {
+		manifest += fmt.Sprintf("  %s: %s\n", path, checksum(content))
+	}
+
+	digest := sha256.Sum256([]byte(manifest))
+	signature, err := key.Sign(rand.Reader, digest[:], nil)
+	require.NoError(t, err)
+
+	release := []scmFile{
+		{Path: ManifestPath, Content: []byte(manifest)},
+		{Path: ManifestSignaturePath, Content: []byte(base64.StdEncoding.EncodeToString(signature) + "\n")},
+	}
+	for path, content := range files {
+		release = append(release, scmFile{Path: path, Content: []byte(content)})
+	}
+
+	return release
+}
+
+func TestManifestVerifier(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	verifier, err := newManifestVerifier(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})))
+	require.NoError(t, err)
+
+	files := map[string]string{
+		"README.md":                  "# Spotguide",
+		".banzaicloud/pipeline.yaml": "pipeline: {}",
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, verifier.verifyReleaseFiles(signedRelease(t, key, files)))
+	})
+
+	t.Run("tampered file", func(t *testing.T) {
+		release := signedRelease(t, key, files)
+		release = append(release[:2], scmFile{Path: "README.md", Content: []byte("# Malicious")}, scmFile{Path: ".banzaicloud/pipeline.yaml", Content: []byte("pipeline: {}")})
+
+		assert.EqualError(t, verifier.verifyReleaseFiles(release), "checksum mismatch of file README.md")
+	})
+
+	t.Run("unlisted file", func(t *testing.T) {
+		release := append(signedRelease(t, key, files), scmFile{Path: "extra", Content: []byte("extra")})
+
+		assert.EqualError(t, verifier.verifyReleaseFiles(release), "file extra is not listed in the manifest")
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		assert.EqualError(t, verifier.verifyReleaseFiles(signedRelease(t, otherKey, files)), "invalid manifest signature")
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		assert.EqualError(t, verifier.verifyReleaseFiles([]scmFile{{Path: "README.md", Content: []byte("# Spotguide")}}), "release has no signed manifest")
+	})
+}
@@ -0,0 +1,269 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxMergeLines limits the size of the files which are merged line by line, bigger files are conflicting when changed on both sides.
+// Matching the lines takes quadratic memory (about 8MB for two files at the limit).
+const maxMergeLines = 1000
+
+// mergeResult is the outcome of a three-way merge of spotguide file trees.
+type mergeResult struct {
+	Created   []scmFile
+	Updated   []scmFile
+	Deleted   []string
+	Conflicts []string
+}
+
+func (r mergeResult) changed() bool {
+	return len(r.Created) > 0 || len(r.Updated) > 0 || len(r.Deleted) > 0
+}
+
+// mergeTrees merges the changes between the base and their (the new spotguide release) file trees into our (the repository) tree.
+// Conflicting text files are merged with conflict markers, other conflicts leave our version in place.
+func mergeTrees(base, their, our []scmFile, theirLabel string) mergeResult {
+	baseFiles := filesByPath(base)
+	theirFiles := filesByPath(their)
+	ourFiles := filesByPath(our)
+
+	var paths []string
+	for path := range baseFiles {
+		paths = append(paths, path)
+	}
+	for path := range theirFiles {
+		if _, ok := baseFiles[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var result mergeResult
+
+	for _, path := range paths {
+		baseContent, inBase := baseFiles[path]
+		theirContent, inTheir := theirFiles[path]
+		ourContent, inOur := ourFiles[path]
+
+		switch {
+		// unchanged in the spotguide
+		case inBase && inTheir && bytes.Equal(baseContent, theirContent):
+			continue
+
+		// deleted in the spotguide
+		case !inTheir:
+			if !inOur {
+				continue
+			}
+			if bytes.Equal(ourContent, baseContent) {
+				result.Deleted = append(result.Deleted, path)
+			} else {
+				result.Conflicts = append(result.Conflicts, path)
+			}
+
+		// deleted or not yet present in the repository
+		case !inOur:
+			if inBase {
+				result.Conflicts = append(result.Conflicts, path)
+			} else {
+				result.Created = append(result.Created, scmFile{Path: path, Content: theirContent})
+			}
+
+		case bytes.Equal(ourContent, theirContent):
+			continue
+
+		// unchanged in the repository
+		case inBase && bytes.Equal(ourContent, baseContent):
+			result.Updated = append(result.Updated, scmFile{Path: path, Content: theirContent})
+
+		// changed on both sides
+		default:
+			if !isText(baseContent) || !isText(theirContent) || !isText(ourContent) {
+				result.Conflicts = append(result.Conflicts, path)
+				continue
+			}
+
+			merged, conflict := merge3(string(baseContent), string(ourContent), string(theirContent), theirLabel)
+			if conflict {
+				result.Conflicts = append(result.Conflicts, path)
+			}
+
+			result.Updated = append(result.Updated, scmFile{Path: path, Content: []byte(merged)})
+		}
+	}
+
+	return result
+}
+
+func filesByPath(files []scmFile) map[string][]byte {
+	result := map[string][]byte{}
+	for _, file := range files {
+		result[file.Path] = file.Content
+	}
+
+	return result
+}
+
+func isText(content []byte) bool {
+	return utf8.Valid(content) && bytes.IndexByte(content, 0) < 0
+}
+
+// merge3 is a line based three-way merge (diff3) of text contents,
+// conflicting hunks are surrounded with the usual conflict markers.
+func merge3(base, our, their string, theirLabel string) (string, bool) {
+	baseLines := splitLines(base)
+	ourLines := splitLines(our)
+	theirLines := splitLines(their)
+
+	if len(baseLines) > maxMergeLines || len(ourLines) > maxMergeLines || len(theirLines) > maxMergeLines {
+		return conflictHunk(ourLines, theirLines, theirLabel), true
+	}
+
+	ourMatches := matchLines(baseLines, ourLines)
+	theirMatches := matchLines(baseLines, theirLines)
+
+	var merged strings.Builder
+	conflict := false
+
+	i, j, k := 0, 0, 0
+	for {
+		// find the next base line which is kept on both sides
+		next := i
+		for next < len(baseLines) && (ourMatches[next] < 0 || theirMatches[next] < 0) {
+			next++
+		}
+
+		ourEnd, theirEnd := len(ourLines), len(theirLines)
+		if next < len(baseLines) {
+			ourEnd, theirEnd = ourMatches[next], theirMatches[next]
+		}
+
+		baseHunk, ourHunk, theirHunk := baseLines[i:next], ourLines[j:ourEnd], theirLines[k:theirEnd]
+
+		switch {
+		case equalLines(ourHunk, baseHunk):
+			merged.WriteString(strings.Join(theirHunk, ""))
+		case equalLines(theirHunk, baseHunk), equalLines(ourHunk, theirHunk):
+			merged.WriteString(strings.Join(ourHunk, ""))
+		default:
+			merged.WriteString(conflictHunk(ourHunk, theirHunk, theirLabel))
+			conflict = true
+		}
+
+		if next == len(baseLines) {
+			break
+		}
+
+		merged.WriteString(baseLines[next])
+		i, j, k = next+1, ourEnd+1, theirEnd+1
+	}
+
+	return merged.String(), conflict
+}
+
+func conflictHunk(our, their []string, theirLabel string) string {
+	var hunk strings.Builder
+
+	hunk.WriteString("<<<<<<< repository\n")
+	writeLines(&hunk, our)
+	hunk.WriteString("=======\n")
+	writeLines(&hunk, their)
+	hunk.WriteString(">>>>>>> " + theirLabel + "\n")
+
+	return hunk.String()
+}
+
+func writeLines(builder *strings.Builder, lines []string) {
+	for _, line := range lines {
+		builder.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			builder.WriteString("\n")
+		}
+	}
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchLines returns for each base line the index of the matching line in the other content
+// (based on their longest common subsequence), or -1 if the line was removed.
+func matchLines(base, other []string) []int {
+	// lcs[i][j] is the length of the longest common subsequence of base[i:] and other[j:]
+	lcs := make([][]int, len(base)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(other)+1)
+	}
+
+	for i := len(base) - 1; i >= 0; i-- {
+		for j := len(other) - 1; j >= 0; j-- {
+			if base[i] == other[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	matches := make([]int, len(base))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	for i, j := 0, 0; i < len(base) && j < len(other); {
+		switch {
+		case base[i] == other[j]:
+			matches[i] = j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,95 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := map[string]struct {
+		base, our, their string
+		merged           string
+		conflict         bool
+	}{
+		"their change only": {
+			base:   "a\nb\nc\n",
+			our:    "a\nb\nc\n",
+			their:  "a\nB\nc\n",
+			merged: "a\nB\nc\n",
+		},
+		"non-overlapping changes": {
+			base:   "a\nb\nc\nd\ne\n",
+			our:    "A\nb\nc\nd\ne\n",
+			their:  "a\nb\nc\nd\nE\nf\n",
+			merged: "A\nb\nc\nd\nE\nf\n",
+		},
+		"same change": {
+			base:   "a\nb\n",
+			our:    "a\nX\n",
+			their:  "a\nX\n",
+			merged: "a\nX\n",
+		},
+		"conflicting changes": {
+			base:     "a\nb\nc\n",
+			our:      "a\nours\nc\n",
+			their:    "a\ntheirs\nc\n",
+			merged:   "a\n<<<<<<< repository\nours\n=======\ntheirs\n>>>>>>> spotguide v2\nc\n",
+			conflict: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			merged, conflict := merge3(test.base, test.our, test.their, "spotguide v2")
+
+			assert.Equal(t, test.merged, merged)
+			assert.Equal(t, test.conflict, conflict)
+		})
+	}
+}
+
+func TestMergeTrees(t *testing.T) {
+	base := []scmFile{
+		{Path: "unchanged", Content: []byte("same")},
+		{Path: "removed", Content: []byte("old")},
+		{Path: "removed-modified", Content: []byte("old")},
+		{Path: "updated", Content: []byte("v1")},
+		{Path: "customized", Content: []byte("v1")},
+	}
+	their := []scmFile{
+		{Path: "unchanged", Content: []byte("same")},
+		{Path: "updated", Content: []byte("v2")},
+		{Path: "customized", Content: []byte("v2")},
+		{Path: "added", Content: []byte("new")},
+	}
+	our := []scmFile{
+		{Path: "unchanged", Content: []byte("custom")},
+		{Path: "removed", Content: []byte("old")},
+		{Path: "removed-modified", Content: []byte("custom")},
+		{Path: "updated", Content: []byte("v1")},
+		{Path: "customized", Content: []byte{0, 1}},
+		{Path: "user-file", Content: []byte("mine")},
+	}
+
+	result := mergeTrees(base, their, our, "spotguide v2")
+
+	assert.Equal(t, []scmFile{{Path: "added", Content: []byte("new")}}, result.Created)
+	assert.Equal(t, []scmFile{{Path: "updated", Content: []byte("v2")}}, result.Updated)
+	assert.Equal(t, []string{"removed"}, result.Deleted)
+	assert.Equal(t, []string{"customized", "removed-modified"}, result.Conflicts)
+}
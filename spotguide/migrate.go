@@ -26,6 +26,11 @@ import (
 func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 	tables := []interface{}{
 		&SpotguideRepo{},
+		&SpotguideLaunch{},
+		&SpotguideLaunchStep{},
+		&SpotguideUpgrade{},
+		&SpotguideCatalog{},
+		&SpotguideSettings{},
 	}
 
 	var tableNames string
@@ -0,0 +1,364 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Question input types
+const (
+	InputTypeString  = "string"
+	InputTypeInt     = "int"
+	InputTypeEnum    = "enum"
+	InputTypeSecret  = "secret"
+	InputTypeCluster = "cluster"
+)
+
+// QuestionInput is the typed input declaration of a question, it is read from the "input" key of the question.
+// The constraint keywords follow their JSON Schema counterparts.
+type QuestionInput struct {
+	// Key is the dot separated path of the answer in the pipeline section of the launch request
+	Key      string `json:"key"`
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+
+	// string constraints
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+
+	// int constraints
+	Minimum *int64 `json:"minimum,omitempty"`
+	Maximum *int64 `json:"maximum,omitempty"`
+
+	// enum constraints
+	Enum []interface{} `json:"enum,omitempty"`
+
+	// secret constraints
+	SecretType string `json:"secretType,omitempty"`
+
+	// cluster constraints
+	Clouds []string `json:"clouds,omitempty"`
+}
+
+// Input returns the typed input declaration of the question, or nil if the question doesn't declare one.
+func (q Question) Input() (*QuestionInput, error) {
+	rawInput, ok := q["input"]
+	if !ok {
+		return nil, nil
+	}
+
+	inputJSON, err := json.Marshal(rawInput)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal question input")
+	}
+
+	var input QuestionInput
+	if err := json.Unmarshal(inputJSON, &input); err != nil {
+		return nil, errors.Wrap(err, "failed to parse question input")
+	}
+
+	return &input, input.validate()
+}
+
+// validate checks the input declaration itself, so that broken spotguides are rejected when scraped.
+func (i *QuestionInput) validate() error {
+	switch i.Type {
+	case InputTypeString, InputTypeInt, InputTypeSecret:
+	case InputTypeEnum:
+		if len(i.Enum) == 0 {
+			return errors.Errorf("enum input %q has no values", i.Key)
+		}
+	case InputTypeCluster:
+		// the cluster of the launch request is checked if there is no key
+		return nil
+	default:
+		return errors.Errorf("unknown input type %q", i.Type)
+	}
+
+	if i.Key == "" {
+		return errors.New("question input key is missing")
+	}
+
+	if i.Pattern != "" {
+		if _, err := regexp.Compile(i.Pattern); err != nil {
+			return errors.Wrapf(err, "invalid pattern for input %q", i.Key)
+		}
+	}
+
+	return nil
+}
+
+// validateQuestions checks that the spotguide questions are well-formed.
+func validateQuestions(questions []Question) error {
+	for _, question := range questions {
+		if _, err := question.Input(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FieldError describes a problem with a single field of a launch request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// LaunchValidationError is returned when a launch request is invalid, it contains all the invalid fields.
+type LaunchValidationError struct {
+	Fields []FieldError
+}
+
+func (e *LaunchValidationError) Error() string {
+	var messages []string
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+
+	return "invalid launch request: " + strings.Join(messages, ", ")
+}
+
+func (e *LaunchValidationError) IsInvalid() bool {
+	return true
+}
+
+func (e *LaunchValidationError) add(field, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// questionLookup resolves the resources referenced by question answers.
+type questionLookup struct {
+	// secretType returns the type of an existing secret, or an empty string if it doesn't exist
+	secretType func(name string) (string, error)
+
+	// clusterCloud returns the cloud of an existing cluster, or an empty string if it doesn't exist
+	clusterCloud func(name string) (string, error)
+}
+
+// validateAnswers checks the answers of the launch request against the typed inputs of the spotguide questions.
+func validateAnswers(questions []Question, request *LaunchRequest, lookup questionLookup, validationErr *LaunchValidationError) error {
+	for _, question := range questions {
+		input, err := question.Input()
+		if err != nil {
+			return errors.Wrap(err, "invalid spotguide question")
+		}
+
+		if input == nil {
+			continue
+		}
+
+		field := "pipeline." + input.Key
+
+		if input.Type == InputTypeCluster && input.Key == "" {
+			if request.Cluster != nil {
+				validateClusterCloud(input, "cluster.cloud", request.Cluster.Cloud, validationErr)
+			}
+			continue
+		}
+
+		value, ok := lookupValue(request.Pipeline, input.Key)
+		if !ok || value == nil {
+			if input.Required {
+				validationErr.add(field, "is required")
+			}
+			continue
+		}
+
+		switch input.Type {
+		case InputTypeString:
+			validateString(input, field, value, validationErr)
+
+		case InputTypeInt:
+			validateInt(input, field, value, validationErr)
+
+		case InputTypeEnum:
+			validateEnum(input, field, value, validationErr)
+
+		case InputTypeSecret:
+			if err := validateSecretRef(input, field, value, request, lookup, validationErr); err != nil {
+				return err
+			}
+
+		case InputTypeCluster:
+			if err := validateClusterRef(input, field, value, request, lookup, validationErr); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupValue returns the value at the dot separated path of the pipeline section.
+func lookupValue(pipeline map[string]interface{}, key string) (interface{}, bool) {
+	var current interface{} = pipeline
+
+	for _, part := range strings.Split(key, ".") {
+		values, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = values[part]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func validateString(input *QuestionInput, field string, value interface{}, validationErr *LaunchValidationError) {
+	s, ok := value.(string)
+	if !ok {
+		validationErr.add(field, "must be a string")
+		return
+	}
+
+	length := utf8.RuneCountInString(s)
+	if input.MinLength != nil && length < *input.MinLength {
+		validationErr.add(field, "must be at least %d characters long", *input.MinLength)
+	}
+	if input.MaxLength != nil && length > *input.MaxLength {
+		validationErr.add(field, "must be at most %d characters long", *input.MaxLength)
+	}
+
+	if input.Pattern != "" && !regexp.MustCompile(input.Pattern).MatchString(s) {
+		validationErr.add(field, "must match pattern %q", input.Pattern)
+	}
+}
+
+func validateInt(input *QuestionInput, field string, value interface{}, validationErr *LaunchValidationError) {
+	f, ok := value.(float64)
+	if !ok || f != math.Trunc(f) {
+		validationErr.add(field, "must be an integer")
+		return
+	}
+
+	i := int64(f)
+	if input.Minimum != nil && i < *input.Minimum {
+		validationErr.add(field, "must be greater than or equal to %d", *input.Minimum)
+	}
+	if input.Maximum != nil && i > *input.Maximum {
+		validationErr.add(field, "must be less than or equal to %d", *input.Maximum)
+	}
+}
+
+func validateEnum(input *QuestionInput, field string, value interface{}, validationErr *LaunchValidationError) {
+	// only scalars are comparable
+	switch value.(type) {
+	case string, float64, bool:
+		for _, allowed := range input.Enum {
+			if allowed == value {
+				return
+			}
+		}
+	}
+
+	var allowedValues []string
+	for _, allowed := range input.Enum {
+		allowedValues = append(allowedValues, fmt.Sprint(allowed))
+	}
+
+	validationErr.add(field, "must be one of: %s", strings.Join(allowedValues, ", "))
+}
+
+// validateSecretRef checks that the referenced secret is either created by the launch or already exists.
+func validateSecretRef(input *QuestionInput, field string, value interface{}, request *LaunchRequest, lookup questionLookup, validationErr *LaunchValidationError) error {
+	name, ok := value.(string)
+	if !ok {
+		validationErr.add(field, "must be a secret name")
+		return nil
+	}
+
+	secretType := ""
+	for _, secretRequest := range request.Secrets {
+		if secretRequest.Name == name {
+			secretType = secretRequest.Type
+			break
+		}
+	}
+
+	if secretType == "" {
+		var err error
+		secretType, err = lookup.secretType(name)
+		if err != nil {
+			return errors.Wrap(err, "failed to look up secret")
+		}
+
+		if secretType == "" {
+			validationErr.add(field, "secret %q doesn't exist", name)
+			return nil
+		}
+	}
+
+	if input.SecretType != "" && input.SecretType != secretType {
+		validationErr.add(field, "secret %q must be of type %s", name, input.SecretType)
+	}
+
+	return nil
+}
+
+// validateClusterRef checks that the referenced cluster is either created by the launch or already exists.
+func validateClusterRef(input *QuestionInput, field string, value interface{}, request *LaunchRequest, lookup questionLookup, validationErr *LaunchValidationError) error {
+	name, ok := value.(string)
+	if !ok {
+		validationErr.add(field, "must be a cluster name")
+		return nil
+	}
+
+	if request.Cluster != nil && request.Cluster.Name == name {
+		validateClusterCloud(input, field, request.Cluster.Cloud, validationErr)
+		return nil
+	}
+
+	cloud, err := lookup.clusterCloud(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to look up cluster")
+	}
+
+	if cloud == "" {
+		validationErr.add(field, "cluster %q doesn't exist", name)
+		return nil
+	}
+
+	validateClusterCloud(input, field, cloud, validationErr)
+
+	return nil
+}
+
+func validateClusterCloud(input *QuestionInput, field string, cloud string, validationErr *LaunchValidationError) {
+	if len(input.Clouds) == 0 {
+		return
+	}
+
+	for _, allowed := range input.Clouds {
+		if allowed == cloud {
+			return
+		}
+	}
+
+	validationErr.add(field, "cluster must be on one of the clouds: %s", strings.Join(input.Clouds, ", "))
+}
@@ -0,0 +1,168 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"encoding/json"
+	"testing"
+
+	yaml2 "github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testQuestionsYAML = `
+name: Test spotguide
+questions:
+- label: Database name
+  input:
+    key: deploy_application.deployment.values.mongodb.mongodbDatabase
+    type: string
+    required: true
+    pattern: "^[a-z]+$"
+    maxLength: 16
+- label: Replicas
+  input:
+    key: deploy_application.deployment.values.replicaCount
+    type: int
+    minimum: 1
+    maximum: 5
+- label: Log level
+  input:
+    key: deploy_application.deployment.values.logLevel
+    type: enum
+    enum: [debug, info, error]
+- label: MongoDB secret
+  input:
+    key: deploy_application.deployment.values.mongodb.existingSecret
+    type: secret
+    secretType: password
+- label: Target cluster
+  input:
+    key: deploy_application.cluster
+    type: cluster
+    clouds: [google, amazon]
+- label: Untyped question
+  type: pipeline
+`
+
+func testQuestions(t *testing.T) []Question {
+	var spotguideYAML SpotguideYAML
+	require.NoError(t, yaml2.Unmarshal([]byte(testQuestionsYAML), &spotguideYAML))
+	require.NoError(t, validateQuestions(spotguideYAML.Questions))
+
+	return spotguideYAML.Questions
+}
+
+func testLookup() questionLookup {
+	return questionLookup{
+		secretType: func(name string) (string, error) {
+			if name == "existing-password" {
+				return "password", nil
+			}
+			if name == "existing-aws" {
+				return "amazon", nil
+			}
+			return "", nil
+		},
+		clusterCloud: func(name string) (string, error) {
+			if name == "existing-azure" {
+				return "azure", nil
+			}
+			return "", nil
+		},
+	}
+}
+
+func testAnswers(t *testing.T, pipelineJSON string) *LaunchRequest {
+	request := &LaunchRequest{}
+	require.NoError(t, json.Unmarshal([]byte(`{"cluster": {"name": "new-cluster", "cloud": "google"}, "pipeline": `+pipelineJSON+`}`), request))
+
+	return request
+}
+
+func TestValidateAnswers(t *testing.T) {
+	questions := testQuestions(t)
+
+	tests := map[string]struct {
+		pipeline string
+		fields   []FieldError
+	}{
+		"valid": {
+			pipeline: `{"deploy_application": {"cluster": "new-cluster", "deployment": {"values": {
+				"mongodb": {"mongodbDatabase": "application", "existingSecret": "existing-password"},
+				"replicaCount": 3,
+				"logLevel": "info"
+			}}}}`,
+		},
+		"missing required": {
+			pipeline: `{}`,
+			fields: []FieldError{
+				{Field: "pipeline.deploy_application.deployment.values.mongodb.mongodbDatabase", Message: "is required"},
+			},
+		},
+		"invalid values": {
+			pipeline: `{"deploy_application": {"cluster": "existing-azure", "deployment": {"values": {
+				"mongodb": {"mongodbDatabase": "Application", "existingSecret": "existing-aws"},
+				"replicaCount": 1.5,
+				"logLevel": "trace"
+			}}}}`,
+			fields: []FieldError{
+				{Field: "pipeline.deploy_application.deployment.values.mongodb.mongodbDatabase", Message: `must match pattern "^[a-z]+$"`},
+				{Field: "pipeline.deploy_application.deployment.values.replicaCount", Message: "must be an integer"},
+				{Field: "pipeline.deploy_application.deployment.values.logLevel", Message: "must be one of: debug, info, error"},
+				{Field: "pipeline.deploy_application.deployment.values.mongodb.existingSecret", Message: `secret "existing-aws" must be of type password`},
+				{Field: "pipeline.deploy_application.cluster", Message: "cluster must be on one of the clouds: google, amazon"},
+			},
+		},
+		"missing references": {
+			pipeline: `{"deploy_application": {"cluster": "missing", "deployment": {"values": {
+				"mongodb": {"mongodbDatabase": "application", "existingSecret": "missing"},
+				"replicaCount": 10
+			}}}}`,
+			fields: []FieldError{
+				{Field: "pipeline.deploy_application.deployment.values.replicaCount", Message: "must be less than or equal to 5"},
+				{Field: "pipeline.deploy_application.deployment.values.mongodb.existingSecret", Message: `secret "missing" doesn't exist`},
+				{Field: "pipeline.deploy_application.cluster", Message: `cluster "missing" doesn't exist`},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			validationErr := &LaunchValidationError{}
+
+			err := validateAnswers(questions, testAnswers(t, test.pipeline), testLookup(), validationErr)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.fields, validationErr.Fields)
+		})
+	}
+}
+
+func TestValidateQuestionsInvalid(t *testing.T) {
+	tests := map[string]Question{
+		"unknown type":  {"input": map[string]interface{}{"key": "a", "type": "float"}},
+		"missing key":   {"input": map[string]interface{}{"type": "string"}},
+		"empty enum":    {"input": map[string]interface{}{"key": "a", "type": "enum"}},
+		"invalid regex": {"input": map[string]interface{}{"key": "a", "type": "string", "pattern": "("}},
+	}
+
+	for name, question := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, validateQuestions([]Question{question}))
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// Source code management providers spotguides can be scraped from and launched into
+const (
+	SCMProviderGithub = "github"
+	SCMProviderGitlab = "gitlab"
+	SCMProviderGit    = "git"
+)
+
+// scmRepository is a repository hosted by a source code management provider.
+type scmRepository struct {
+	// FullName is the owner (organization, group or user) and the name of the repository separated by a '/'
+	FullName string
+	Private  bool
+}
+
+// scmRelease is a release (or a tag) of a repository.
+type scmRelease struct {
+	Tag        string
+	Prerelease bool
+	Body       string
+}
+
+// scmFile is a file of a repository.
+type scmFile struct {
+	Path    string
+	Content []byte
+}
+
+// scmPullRequest is a set of changes to be proposed on a new branch of a repository.
+type scmPullRequest struct {
+	Base    string
+	Branch  string
+	Title   string
+	Body    string
+	Created []scmFile
+	Updated []scmFile
+	Deleted []string
+}
+
+// scmProvider is the abstraction of source code management providers (GitHub, GitLab, plain Git)
+// spotguides can be scraped from and launched into.
+type scmProvider interface {
+	// ListSpotguideRepositories lists the spotguide repositories of the given owner.
+	ListSpotguideRepositories(owner string) ([]scmRepository, error)
+
+	// ListReleases lists the releases of a repository.
+	ListReleases(repository string) ([]scmRelease, error)
+
+	// DownloadFile downloads a single file of a repository at the given ref.
+	DownloadFile(repository, path, ref string) ([]byte, error)
+
+	// DownloadContent downloads all the files of a repository at the given ref.
+	DownloadContent(repository, ref string) ([]scmFile, error)
+
+	// DownloadBranch downloads all the files of a repository at the head of the given branch.
+	DownloadBranch(repository, branch string) ([]scmFile, error)
+
+	// CreateRepository creates a new, empty repository.
+	CreateRepository(owner, name string, private bool) error
+
+	// DeleteRepository deletes a repository, used for rolling back failed launches.
+	DeleteRepository(repository string) error
+
+	// CommitFiles commits the given files to the master branch of a freshly created repository.
+	CommitFiles(repository string, files []scmFile, message string) error
+
+	// CreatePullRequest commits the changes to a new branch and proposes them to be merged into the base branch,
+	// it returns the URL of the pull request.
+	CreatePullRequest(repository string, pullRequest scmPullRequest) (string, error)
+
+	// IsBranchMerged checks whether the changes of a pull request branch have been merged into the base branch.
+	IsBranchMerged(repository, base, branch string) (bool, error)
+}
+
+// newSCMProvider returns the source code management provider implementation for the given provider name.
+func newSCMProvider(provider, token string) (scmProvider, error) {
+	switch provider {
+	case SCMProviderGithub, "":
+		return newGithubSCMProvider(token), nil
+
+	case SCMProviderGitlab:
+		return newGitlabSCMProvider(viper.GetString(config.SpotguideGitlabURL), token), nil
+
+	case SCMProviderGit:
+		gitURL := viper.GetString(config.SpotguideGitURL)
+		if gitURL == "" {
+			return nil, errors.New("plain Git spotguide provider URL is not configured")
+		}
+
+		return newGitSCMProvider(gitURL, token), nil
+
+	default:
+		return nil, errors.Errorf("unsupported spotguide provider: %s", provider)
+	}
+}
+
+// newSCMProviderForUser returns a source code management provider authenticated with the user's token.
+func newSCMProviderForUser(provider string, userID uint) (scmProvider, error) {
+	tokenID, err := scmTokenID(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := auth.GetUserSCMToken(userID, tokenID)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get user's access token")
+	}
+
+	if token == "" {
+		return nil, errors.Errorf("user's %s token is not set", provider)
+	}
+
+	return newSCMProvider(provider, token)
+}
+
+func scmTokenID(provider string) (string, error) {
+	switch provider {
+	case SCMProviderGithub, "":
+		return auth.GithubTokenID, nil
+	case SCMProviderGitlab:
+		return auth.GitlabTokenID, nil
+	case SCMProviderGit:
+		return auth.GitTokenID, nil
+	default:
+		return "", errors.Errorf("unsupported spotguide provider: %s", provider)
+	}
+}
+
+// splitRepoFullName splits a repository full name into owner and name.
+// The owner part may contain further '/'s (like GitLab subgroups).
+func splitRepoFullName(fullName string) (string, string) {
+	i := strings.LastIndex(fullName, "/")
+	if i < 0 {
+		return "", fullName
+	}
+
+	return fullName[:i], fullName[i+1:]
+}
+
+// extractZipArchive extracts the files from a repository archive, the first directory is the name of the repository.
+func extractZipArchive(archive []byte) ([]scmFile, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open repository archive")
+	}
+
+	var files []scmFile
+
+	for _, zf := range zipReader.File {
+		// symlinks and other non-regular files could point outside of the repository
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+
+		// First directory is the name of the repo
+		parts := strings.SplitN(zf.Name, "/", 2)
+		if len(parts) < 2 {
+			continue
+		}
+
+		file, err := zf.Open()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to extract repository archive")
+		}
+
+		content, err := ioutil.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to extract repository archive")
+		}
+
+		if err := validateFilePath(parts[1]); err != nil {
+			return nil, errors.Wrap(err, "failed to extract repository archive")
+		}
+
+		files = append(files, scmFile{Path: parts[1], Content: content})
+	}
+
+	return files, nil
+}
+
+// validateFilePath checks that a repository file path is relative and stays inside the repository.
+func validateFilePath(filePath string) error {
+	if filePath == "" || path.IsAbs(filePath) || filepath.IsAbs(filepath.FromSlash(filePath)) || filepath.VolumeName(filePath) != "" {
+		return errors.Errorf("invalid file path: %q", filePath)
+	}
+
+	for _, element := range strings.Split(filepath.ToSlash(filePath), "/") {
+		if element == ".." {
+			return errors.Errorf("invalid file path: %q", filePath)
+		}
+	}
+
+	if strings.SplitN(path.Clean(filepath.ToSlash(filePath)), "/", 2)[0] == ".git" {
+		return errors.Errorf("invalid file path: %q", filePath)
+	}
+
+	return nil
+}
+
+// localFilePath returns the location of a repository file inside the local directory dir.
+func localFilePath(dir, filePath string) (string, error) {
+	if err := validateFilePath(filePath); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(filePath)), nil
+}
@@ -0,0 +1,413 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	yaml2 "github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+// SpotguideGitIndexRepository is the name of the repository which lists the spotguides of an owner on plain Git servers.
+const SpotguideGitIndexRepository = "spotguides"
+
+// SpotguideGitIndexPath is the path of the spotguide index file in the index repository.
+const SpotguideGitIndexPath = "index.yaml"
+
+// gitIndex is the content of the spotguide index file.
+type gitIndex struct {
+	Repositories []string `json:"repositories"`
+}
+
+// gitSCMProvider works with any Git server over HTTP(S) by invoking the git command line client.
+// Since there is no API to discover repositories, spotguides are listed in an index repository.
+type gitSCMProvider struct {
+	baseURL string
+	token   string
+}
+
+func newGitSCMProvider(baseURL, token string) *gitSCMProvider {
+	return &gitSCMProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+	}
+}
+
+// Environment variables the credential helper reads the Git credentials from.
+const (
+	gitUsernameEnv = "PIPELINE_GIT_USERNAME"
+	gitPasswordEnv = "PIPELINE_GIT_PASSWORD"
+)
+
+// gitCredentialHelper passes the credentials to Git from the environment,
+// so that they never appear on the command line, in remote URLs or in error messages.
+const gitCredentialHelper = `!f() { test "$1" = get && echo "username=${` + gitUsernameEnv + `}" && echo "password=${` + gitPasswordEnv + `}"; }; f`
+
+// repositoryURL returns the clone URL of a repository.
+func (p *gitSCMProvider) repositoryURL(repository string) (string, error) {
+	repoURL, err := url.Parse(p.baseURL + "/" + repository + ".git")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse git repository URL")
+	}
+
+	return repoURL.String(), nil
+}
+
+// credentials returns the username and password to authenticate with.
+// The token may be in the "username:password" format, otherwise it is used as password.
+func (p *gitSCMProvider) credentials() (string, string) {
+	parts := strings.SplitN(p.token, ":", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+
+	return "pipeline", p.token
+}
+
+func (p *gitSCMProvider) git(dir string, args ...string) ([]byte, error) {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	if p.token != "" {
+		username, password := p.credentials()
+		env = append(env, gitUsernameEnv+"="+username, gitPasswordEnv+"="+password)
+
+		// the empty helper resets the ones configured on the host
+		args = append([]string{"-c", "credential.helper=", "-c", "credential.helper=" + gitCredentialHelper}, args...)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("git command failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+// clone makes a shallow clone of the repository at the given ref (or the default branch if empty)
+// into a temporary directory, the caller is responsible for removing it.
+// Additional options are passed to the clone command as is.
+func (p *gitSCMProvider) clone(repository, ref string, options ...string) (string, error) {
+	repoURL, err := p.repositoryURL(repository)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := ioutil.TempDir("", "spotguide")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temporary directory")
+	}
+
+	args := append([]string{"clone", "--quiet", "--depth", "1"}, options...)
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, repoURL, dir)
+
+	if _, err := p.git("", args...); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return dir, nil
+}
+
+func (p *gitSCMProvider) ListSpotguideRepositories(owner string) ([]scmRepository, error) {
+	indexRaw, err := p.DownloadFile(owner+"/"+SpotguideGitIndexRepository, SpotguideGitIndexPath, "")
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to download spotguide index")
+	}
+
+	var index gitIndex
+	if err := yaml2.Unmarshal(indexRaw, &index); err != nil {
+		return nil, emperror.Wrap(err, "failed to parse spotguide index")
+	}
+
+	var repositories []scmRepository
+	for _, name := range index.Repositories {
+		if !strings.Contains(name, "/") {
+			name = owner + "/" + name
+		}
+
+		repositories = append(repositories, scmRepository{FullName: name})
+	}
+
+	return repositories, nil
+}
+
+func (p *gitSCMProvider) ListReleases(repository string) ([]scmRelease, error) {
+	repoURL, err := p.repositoryURL(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := p.git("", "ls-remote", "--tags", "--refs", repoURL)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list git tags")
+	}
+
+	var releases []scmRelease
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		releases = append(releases, scmRelease{Tag: strings.TrimPrefix(fields[1], "refs/tags/")})
+	}
+
+	return releases, nil
+}
+
+// DownloadFile fetches a single file without checking out the repository:
+// file contents are only downloaded on demand if the server supports partial clones.
+func (p *gitSCMProvider) DownloadFile(repository, path, ref string) ([]byte, error) {
+	if err := validateFilePath(path); err != nil {
+		return nil, err
+	}
+
+	dir, err := p.clone(repository, ref, "--no-checkout", "--filter=blob:none")
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to download file from Git")
+	}
+	defer os.RemoveAll(dir)
+
+	data, err := p.git(dir, "cat-file", "blob", "HEAD:"+path)
+	return data, emperror.Wrap(err, "failed to download file from Git")
+}
+
+func (p *gitSCMProvider) DownloadContent(repository, ref string) ([]scmFile, error) {
+	dir, err := p.clone(repository, ref)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to download repository from Git")
+	}
+	defer os.RemoveAll(dir)
+
+	var files []scmFile
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// symlinks and other non-regular files could point outside of the repository
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, scmFile{Path: filepath.ToSlash(relPath), Content: content})
+
+		return nil
+	})
+
+	return files, errors.Wrap(err, "failed to read repository content")
+}
+
+func (p *gitSCMProvider) DownloadBranch(repository, branch string) ([]scmFile, error) {
+	return p.DownloadContent(repository, branch)
+}
+
+// CreateRepository can't create repositories on plain Git servers,
+// it only checks that the repository has been created in advance.
+func (p *gitSCMProvider) CreateRepository(owner, name string, private bool) error {
+	repoURL, err := p.repositoryURL(owner + "/" + name)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.git("", "ls-remote", repoURL)
+
+	return errors.Wrap(err, "repository has to be created on the Git server in advance")
+}
+
+// DeleteRepository is a no-op on plain Git servers, since the repository wasn't created by Pipeline.
+func (p *gitSCMProvider) DeleteRepository(repository string) error {
+	return nil
+}
+
+func (p *gitSCMProvider) CommitFiles(repository string, files []scmFile, message string) error {
+	repoURL, err := p.repositoryURL(repository)
+	if err != nil {
+		return err
+	}
+
+	dir, err := ioutil.TempDir("", "spotguide")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := p.git(dir, "init", "--quiet"); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		path, err := localFilePath(dir, file.Path)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.Wrap(err, "failed to create directory")
+		}
+
+		if err := ioutil.WriteFile(path, file.Content, 0644); err != nil {
+			return errors.Wrap(err, "failed to write file: "+file.Path)
+		}
+	}
+
+	commands := [][]string{
+		{"add", "--all"},
+		{"-c", "user.name=Banzai Cloud Pipeline", "-c", "user.email=pipeline@banzaicloud.com", "commit", "--quiet", "--message", message},
+		{"push", "--quiet", repoURL, "HEAD:refs/heads/master"},
+	}
+
+	for _, args := range commands {
+		if _, err := p.git(dir, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreatePullRequest can only push the changes to a new branch on plain Git servers,
+// the pull request has to be opened manually, so no URL is returned.
+func (p *gitSCMProvider) CreatePullRequest(repository string, pullRequest scmPullRequest) (string, error) {
+	repoURL, err := p.repositoryURL(repository)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := p.clone(repository, pullRequest.Base)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, file := range append(pullRequest.Created, pullRequest.Updated...) {
+		path, err := localFilePath(dir, file.Path)
+		if err != nil {
+			return "", err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", errors.Wrap(err, "failed to create directory")
+		}
+
+		if err := ioutil.WriteFile(path, file.Content, 0644); err != nil {
+			return "", errors.Wrap(err, "failed to write file: "+file.Path)
+		}
+	}
+
+	for _, filePath := range pullRequest.Deleted {
+		path, err := localFilePath(dir, filePath)
+		if err != nil {
+			return "", err
+		}
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return "", errors.Wrap(err, "failed to delete file: "+filePath)
+		}
+	}
+
+	commands := [][]string{
+		{"checkout", "--quiet", "-b", pullRequest.Branch},
+		{"add", "--all"},
+		{"-c", "user.name=Banzai Cloud Pipeline", "-c", "user.email=pipeline@banzaicloud.com", "commit", "--quiet", "--message", pullRequest.Title},
+		{"push", "--quiet", repoURL, "HEAD:refs/heads/" + pullRequest.Branch},
+	}
+
+	for _, args := range commands {
+		if _, err := p.git(dir, args...); err != nil {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+// IsBranchMerged checks whether the head of the branch is reachable from the base branch on plain Git servers,
+// since there are no pull requests. A branch deleted after the merge can't be checked, it's reported as not merged.
+func (p *gitSCMProvider) IsBranchMerged(repository, base, branch string) (bool, error) {
+	repoURL, err := p.repositoryURL(repository)
+	if err != nil {
+		return false, err
+	}
+
+	out, err := p.git("", "ls-remote", "--heads", repoURL, branch)
+	if err != nil {
+		return false, emperror.Wrap(err, "failed to list git branches")
+	}
+
+	if len(strings.TrimSpace(string(out))) == 0 {
+		return false, nil
+	}
+
+	dir, err := ioutil.TempDir("", "spotguide")
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	commands := [][]string{
+		{"init", "--quiet", "--bare"},
+		{"fetch", "--quiet", "--filter=blob:none", repoURL, "refs/heads/" + base + ":refs/heads/base", "refs/heads/" + branch + ":refs/heads/branch"},
+	}
+
+	for _, args := range commands {
+		if _, err := p.git(dir, args...); err != nil {
+			return false, err
+		}
+	}
+
+	out, err = p.git(dir, "branch", "--list", "base", "--contains", "branch")
+	if err != nil {
+		return false, err
+	}
+
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
@@ -0,0 +1,391 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/google/go-github/github"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+type githubSCMProvider struct {
+	client *github.Client
+}
+
+func newGithubSCMProvider(token string) *githubSCMProvider {
+	return &githubSCMProvider{client: auth.NewGithubClient(token)}
+}
+
+func (p *githubSCMProvider) ListSpotguideRepositories(owner string) ([]scmRepository, error) {
+	var repositories []scmRepository
+	query := fmt.Sprintf("org:%s topic:%s fork:true", owner, SpotguideGithubTopic)
+	if !viper.GetBool(config.SpotguideAllowPrivateRepos) {
+		query += " is:public"
+	}
+	listOpts := github.ListOptions{PerPage: 100}
+	for {
+		reposRes, resp, err := p.client.Search.Repositories(ctx, query, &github.SearchOptions{
+			Sort:        "created",
+			Order:       "asc",
+			ListOptions: listOpts,
+		})
+
+		if err != nil {
+			// Empty organization, no repositories
+			if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+				return nil, nil
+			}
+
+			return nil, emperror.Wrap(err, "failed to list github repositories")
+		}
+
+		for _, repository := range reposRes.Repositories {
+			repositories = append(repositories, scmRepository{
+				FullName: repository.GetFullName(),
+				Private:  repository.GetPrivate(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		listOpts.Page = resp.NextPage
+	}
+
+	return repositories, nil
+}
+
+func (p *githubSCMProvider) ListReleases(repository string) ([]scmRelease, error) {
+	owner, name := splitRepoFullName(repository)
+
+	releases, _, err := p.client.Repositories.ListReleases(ctx, owner, name, &github.ListOptions{})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list github repo releases")
+	}
+
+	var result []scmRelease
+	for _, release := range releases {
+		result = append(result, scmRelease{
+			Tag:        release.GetTagName(),
+			Prerelease: release.GetPrerelease(),
+			Body:       release.GetBody(),
+		})
+	}
+
+	return result, nil
+}
+
+func (p *githubSCMProvider) DownloadFile(repository, path, ref string) ([]byte, error) {
+	owner, name := splitRepoFullName(repository)
+
+	reader, err := p.client.Repositories.DownloadContents(ctx, owner, name, path, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to download file from GitHub")
+	}
+
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	return data, emperror.Wrap(err, "failed to download file from GitHub")
+}
+
+func (p *githubSCMProvider) DownloadContent(repository, ref string) ([]scmFile, error) {
+	owner, name := splitRepoFullName(repository)
+
+	release, _, err := p.client.Repositories.GetReleaseByTag(ctx, owner, name, ref)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find repository release")
+	}
+
+	// Support private repositories via downloading with an authenticated client
+	downloadRequest, err := http.NewRequest(http.MethodGet, release.GetZipballURL(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository release download request")
+	}
+
+	repoBytes := bytes.NewBuffer(nil)
+	_, err = p.client.Do(ctx, downloadRequest, repoBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download repository release")
+	}
+
+	return extractZipArchive(repoBytes.Bytes())
+}
+
+func (p *githubSCMProvider) CreateRepository(owner, name string, private bool) error {
+	repo := github.Repository{
+		Name:        github.String(name),
+		Description: github.String("Spotguide by Banzai Cloud"),
+		Private:     github.Bool(private),
+	}
+
+	user, _, err := p.client.Users.Get(ctx, "")
+	if err != nil {
+		return errors.Wrap(err, "failed to get authenticated github user")
+	}
+
+	// If the user's name is used as organization name, it has to be cleared in repo create.
+	// See: https://developer.github.com/v3/repos/#create
+	if user.GetLogin() == owner {
+		owner = ""
+	}
+
+	_, _, err = p.client.Repositories.Create(ctx, owner, &repo)
+	return errors.Wrap(err, "failed to create github repository")
+}
+
+func (p *githubSCMProvider) DeleteRepository(repository string) error {
+	owner, name := splitRepoFullName(repository)
+
+	_, err := p.client.Repositories.Delete(ctx, owner, name)
+	return errors.Wrap(err, "failed to delete github repository")
+}
+
+// treeEntry returns a git tree entry of a file to be committed.
+func (p *githubSCMProvider) treeEntry(owner, name string, file scmFile) (github.TreeEntry, error) {
+	// The GitHub API accepts blobs as utf-8 by default, and we can change the encoding only in the
+	// CreateBlob call, so if the file is utf-8 let's spare an API call, otherwise create the blob
+	// with base64 encoding specified.
+	var blobSHA, blobContent *string
+
+	if strings.HasSuffix(http.DetectContentType(file.Content), "charset=utf-8") {
+		blobContent = github.String(string(file.Content))
+	} else {
+		blob, _, err := p.client.Git.CreateBlob(ctx, owner, name, &github.Blob{
+			Content:  github.String(base64.StdEncoding.EncodeToString(file.Content)),
+			Encoding: github.String("base64"),
+		})
+		if err != nil {
+			return github.TreeEntry{}, errors.Wrap(err, "failed to create blob for repository: "+file.Path)
+		}
+
+		blobSHA = blob.SHA
+	}
+
+	return github.TreeEntry{
+		Type:    github.String("blob"),
+		Mode:    github.String("100644"),
+		Path:    github.String(file.Path),
+		SHA:     blobSHA,
+		Content: blobContent,
+	}, nil
+}
+
+func (p *githubSCMProvider) CommitFiles(repository string, files []scmFile, message string) error {
+	owner, name := splitRepoFullName(repository)
+
+	// An initial files have to be created with the API to be able to use the fresh repo
+	createFile := &github.RepositoryContentFileOptions{
+		Content: []byte("# Say hello to Spotguides!"),
+		Message: github.String("initial import"),
+	}
+
+	contentResponse, _, err := p.client.Repositories.CreateFile(ctx, owner, name, "README.md", createFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize repository")
+	}
+
+	// List the files here that needs to be created in this commit and create a tree from them
+	entries := []github.TreeEntry{}
+
+	for _, file := range files {
+		entry, err := p.treeEntry(owner, name, file)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	tree, _, err := p.client.Git.CreateTree(ctx, owner, name, contentResponse.GetSHA(), entries)
+	if err != nil {
+		return errors.Wrap(err, "failed to create git tree for repository")
+	}
+
+	// Create a commit from the tree
+	contentResponse.Commit.SHA = contentResponse.SHA
+
+	commit := &github.Commit{
+		Message: github.String(message),
+		Parents: []github.Commit{contentResponse.Commit},
+		Tree:    tree,
+	}
+
+	newCommit, _, err := p.client.Git.CreateCommit(ctx, owner, name, commit)
+	if err != nil {
+		return errors.Wrap(err, "failed to create git commit for repository")
+	}
+
+	// Attach the commit to the master branch.
+	// This can be changed later to another branch + create PR.
+	// See: https://github.com/google/go-github/blob/master/example/commitpr/main.go#L62
+	ref, _, err := p.client.Git.GetRef(ctx, owner, name, "refs/heads/master")
+	if err != nil {
+		return errors.Wrap(err, "failed to get git ref for repository")
+	}
+
+	ref.Object.SHA = newCommit.SHA
+
+	_, _, err = p.client.Git.UpdateRef(ctx, owner, name, ref, false)
+
+	return errors.Wrap(err, "failed to update git ref for repository")
+}
+
+func (p *githubSCMProvider) DownloadBranch(repository, branch string) ([]scmFile, error) {
+	owner, name := splitRepoFullName(repository)
+
+	archiveURL, _, err := p.client.Repositories.GetArchiveLink(ctx, owner, name, github.Zipball, &github.RepositoryContentGetOptions{
+		Ref: branch,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get repository archive link")
+	}
+
+	downloadRequest, err := http.NewRequest(http.MethodGet, archiveURL.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository archive download request")
+	}
+
+	repoBytes := bytes.NewBuffer(nil)
+	_, err = p.client.Do(ctx, downloadRequest, repoBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download repository archive")
+	}
+
+	return extractZipArchive(repoBytes.Bytes())
+}
+
+func (p *githubSCMProvider) CreatePullRequest(repository string, pullRequest scmPullRequest) (string, error) {
+	owner, name := splitRepoFullName(repository)
+
+	baseRef, _, err := p.client.Git.GetRef(ctx, owner, name, "refs/heads/"+pullRequest.Base)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get git ref for repository")
+	}
+
+	baseTree, _, err := p.client.Git.GetTree(ctx, owner, name, baseRef.Object.GetSHA(), true)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get git tree for repository")
+	}
+
+	changes := map[string]scmFile{}
+	for _, file := range append(pullRequest.Created, pullRequest.Updated...) {
+		changes[file.Path] = file
+	}
+
+	deleted := map[string]bool{}
+	for _, path := range pullRequest.Deleted {
+		deleted[path] = true
+	}
+
+	// The tree is built from scratch, since deleting an entry from a base tree is not supported by the client,
+	// unchanged blobs are referenced by their SHA
+	entries := []github.TreeEntry{}
+
+	for _, entry := range baseTree.Entries {
+		path := entry.GetPath()
+		if entry.GetType() == "tree" || deleted[path] {
+			continue
+		}
+
+		if _, ok := changes[path]; ok {
+			continue
+		}
+
+		entries = append(entries, github.TreeEntry{
+			Type: entry.Type,
+			Mode: entry.Mode,
+			Path: entry.Path,
+			SHA:  entry.SHA,
+		})
+	}
+
+	for _, file := range changes {
+		entry, err := p.treeEntry(owner, name, file)
+		if err != nil {
+			return "", err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	tree, _, err := p.client.Git.CreateTree(ctx, owner, name, "", entries)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create git tree for repository")
+	}
+
+	commit, _, err := p.client.Git.CreateCommit(ctx, owner, name, &github.Commit{
+		Message: github.String(pullRequest.Title),
+		Parents: []github.Commit{{SHA: baseRef.Object.SHA}},
+		Tree:    tree,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create git commit for repository")
+	}
+
+	_, _, err = p.client.Git.CreateRef(ctx, owner, name, &github.Reference{
+		Ref:    github.String("refs/heads/" + pullRequest.Branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create git branch for repository")
+	}
+
+	pr, _, err := p.client.PullRequests.Create(ctx, owner, name, &github.NewPullRequest{
+		Title: github.String(pullRequest.Title),
+		Head:  github.String(pullRequest.Branch),
+		Base:  github.String(pullRequest.Base),
+		Body:  github.String(pullRequest.Body),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create github pull request")
+	}
+
+	return pr.GetHTMLURL(), nil
+}
+
+func (p *githubSCMProvider) IsBranchMerged(repository, base, branch string) (bool, error) {
+	owner, name := splitRepoFullName(repository)
+
+	pullRequests, _, err := p.client.PullRequests.List(ctx, owner, name, &github.PullRequestListOptions{
+		State: "closed",
+		Head:  owner + ":" + branch,
+		Base:  base,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list github pull requests")
+	}
+
+	for _, pullRequest := range pullRequests {
+		if pullRequest.MergedAt != nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
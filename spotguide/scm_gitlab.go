@@ -0,0 +1,357 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// gitlabSCMProvider talks to the GitLab REST API (v4), both gitlab.com and self-hosted instances are supported.
+type gitlabSCMProvider struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+type gitlabProject struct {
+	ID                int      `json:"id"`
+	PathWithNamespace string   `json:"path_with_namespace"`
+	Visibility        string   `json:"visibility"`
+	TagList           []string `json:"tag_list"`
+	Topics            []string `json:"topics"`
+}
+
+type gitlabRelease struct {
+	TagName         string `json:"tag_name"`
+	Description     string `json:"description"`
+	UpcomingRelease bool   `json:"upcoming_release"`
+}
+
+type gitlabCommitAction struct {
+	Action   string `json:"action"`
+	FilePath string `json:"file_path"`
+	Content  string `json:"content,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type gitlabError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e gitlabError) Error() string {
+	return fmt.Sprintf("gitlab API error (%d): %s", e.StatusCode, e.Message)
+}
+
+func newGitlabSCMProvider(baseURL, token string) *gitlabSCMProvider {
+	return &gitlabSCMProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/api/v4",
+		token:   token,
+		client:  http.DefaultClient,
+	}
+}
+
+// do executes a GitLab API request, the JSON response is decoded into result (if not nil).
+func (p *gitlabSCMProvider) do(method, path string, query url.Values, body interface{}, result interface{}) (*http.Response, error) {
+	requestURL := p.baseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		rawBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal gitlab request")
+		}
+		bodyReader = bytes.NewReader(rawBody)
+	}
+
+	request, err := http.NewRequest(method, requestURL, bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create gitlab request")
+	}
+
+	request.Header.Set("Private-Token", p.token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute gitlab request")
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return response, errors.Wrap(err, "failed to read gitlab response")
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return response, gitlabError{StatusCode: response.StatusCode, Message: string(data)}
+	}
+
+	if result != nil {
+		if raw, ok := result.(*[]byte); ok {
+			*raw = data
+		} else if err := json.Unmarshal(data, result); err != nil {
+			return response, errors.Wrap(err, "failed to decode gitlab response")
+		}
+	}
+
+	return response, nil
+}
+
+func projectPath(repository string) string {
+	return "/projects/" + url.PathEscape(repository)
+}
+
+func (p *gitlabSCMProvider) ListSpotguideRepositories(owner string) ([]scmRepository, error) {
+	allowPrivate := viper.GetBool(config.SpotguideAllowPrivateRepos)
+
+	var repositories []scmRepository
+	page := "1"
+	for page != "" {
+		query := url.Values{
+			"include_subgroups": {"true"},
+			"per_page":          {"100"},
+			"page":              {page},
+		}
+
+		var projects []gitlabProject
+		response, err := p.do(http.MethodGet, "/groups/"+url.PathEscape(owner)+"/projects", query, nil, &projects)
+		if gitlabErr, ok := errors.Cause(err).(gitlabError); ok && gitlabErr.StatusCode == http.StatusNotFound {
+			// The owner is not a group, try it as a user namespace
+			query.Del("include_subgroups")
+			response, err = p.do(http.MethodGet, "/users/"+url.PathEscape(owner)+"/projects", query, nil, &projects)
+		}
+		if err != nil {
+			return nil, emperror.Wrap(err, "failed to list gitlab projects")
+		}
+
+		for _, project := range projects {
+			if !hasTopic(project, SpotguideGithubTopic) {
+				continue
+			}
+
+			private := project.Visibility != "public"
+			if private && !allowPrivate {
+				continue
+			}
+
+			repositories = append(repositories, scmRepository{
+				FullName: project.PathWithNamespace,
+				Private:  private,
+			})
+		}
+
+		page = response.Header.Get("X-Next-Page")
+	}
+
+	return repositories, nil
+}
+
+func hasTopic(project gitlabProject, topic string) bool {
+	for _, t := range append(project.TagList, project.Topics...) {
+		if t == topic {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *gitlabSCMProvider) ListReleases(repository string) ([]scmRelease, error) {
+	var releases []gitlabRelease
+	_, err := p.do(http.MethodGet, projectPath(repository)+"/releases", nil, nil, &releases)
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to list gitlab project releases")
+	}
+
+	var result []scmRelease
+	for _, release := range releases {
+		result = append(result, scmRelease{
+			Tag:        release.TagName,
+			Prerelease: release.UpcomingRelease,
+			Body:       release.Description,
+		})
+	}
+
+	return result, nil
+}
+
+func (p *gitlabSCMProvider) DownloadFile(repository, path, ref string) ([]byte, error) {
+	var data []byte
+	_, err := p.do(http.MethodGet, projectPath(repository)+"/repository/files/"+url.PathEscape(path)+"/raw", url.Values{"ref": {ref}}, nil, &data)
+
+	return data, emperror.Wrap(err, "failed to download file from GitLab")
+}
+
+func (p *gitlabSCMProvider) DownloadContent(repository, ref string) ([]scmFile, error) {
+	var archive []byte
+	_, err := p.do(http.MethodGet, projectPath(repository)+"/repository/archive.zip", url.Values{"sha": {ref}}, nil, &archive)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download repository archive from GitLab")
+	}
+
+	return extractZipArchive(archive)
+}
+
+func (p *gitlabSCMProvider) DownloadBranch(repository, branch string) ([]scmFile, error) {
+	return p.DownloadContent(repository, branch)
+}
+
+func (p *gitlabSCMProvider) CreateRepository(owner, name string, private bool) error {
+	visibility := "public"
+	if private {
+		visibility = "private"
+	}
+
+	project := map[string]interface{}{
+		"name":        name,
+		"path":        name,
+		"description": "Spotguide by Banzai Cloud",
+		"visibility":  visibility,
+	}
+
+	var user struct {
+		Username string `json:"username"`
+	}
+	if _, err := p.do(http.MethodGet, "/user", nil, nil, &user); err != nil {
+		return errors.Wrap(err, "failed to get authenticated gitlab user")
+	}
+
+	// Projects are created in the user's namespace by default
+	if user.Username != owner {
+		var namespace struct {
+			ID int `json:"id"`
+		}
+		if _, err := p.do(http.MethodGet, "/namespaces/"+url.PathEscape(owner), nil, nil, &namespace); err != nil {
+			return errors.Wrap(err, "failed to find gitlab namespace")
+		}
+
+		project["namespace_id"] = namespace.ID
+	}
+
+	_, err := p.do(http.MethodPost, "/projects", nil, project, nil)
+
+	return errors.Wrap(err, "failed to create gitlab project")
+}
+
+func (p *gitlabSCMProvider) DeleteRepository(repository string) error {
+	_, err := p.do(http.MethodDelete, projectPath(repository), nil, nil, nil)
+
+	return errors.Wrap(err, "failed to delete gitlab project")
+}
+
+func (p *gitlabSCMProvider) CommitFiles(repository string, files []scmFile, message string) error {
+	var actions []gitlabCommitAction
+	for _, file := range files {
+		actions = append(actions, gitlabCommitAction{
+			Action:   "create",
+			FilePath: file.Path,
+			Content:  base64.StdEncoding.EncodeToString(file.Content),
+			Encoding: "base64",
+		})
+	}
+
+	commit := map[string]interface{}{
+		"branch":         "master",
+		"commit_message": message,
+		"actions":        actions,
+	}
+
+	_, err := p.do(http.MethodPost, projectPath(repository)+"/repository/commits", nil, commit, nil)
+
+	return errors.Wrap(err, "failed to create gitlab commit")
+}
+
+func (p *gitlabSCMProvider) CreatePullRequest(repository string, pullRequest scmPullRequest) (string, error) {
+	var actions []gitlabCommitAction
+	for action, files := range map[string][]scmFile{"create": pullRequest.Created, "update": pullRequest.Updated} {
+		for _, file := range files {
+			actions = append(actions, gitlabCommitAction{
+				Action:   action,
+				FilePath: file.Path,
+				Content:  base64.StdEncoding.EncodeToString(file.Content),
+				Encoding: "base64",
+			})
+		}
+	}
+
+	for _, path := range pullRequest.Deleted {
+		actions = append(actions, gitlabCommitAction{Action: "delete", FilePath: path})
+	}
+
+	commit := map[string]interface{}{
+		"branch":         pullRequest.Branch,
+		"start_branch":   pullRequest.Base,
+		"commit_message": pullRequest.Title,
+		"actions":        actions,
+	}
+
+	_, err := p.do(http.MethodPost, projectPath(repository)+"/repository/commits", nil, commit, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create gitlab commit")
+	}
+
+	mergeRequest := map[string]interface{}{
+		"source_branch": pullRequest.Branch,
+		"target_branch": pullRequest.Base,
+		"title":         pullRequest.Title,
+		"description":   pullRequest.Body,
+	}
+
+	var result struct {
+		WebURL string `json:"web_url"`
+	}
+	_, err = p.do(http.MethodPost, projectPath(repository)+"/merge_requests", nil, mergeRequest, &result)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create gitlab merge request")
+	}
+
+	return result.WebURL, nil
+}
+
+func (p *gitlabSCMProvider) IsBranchMerged(repository, base, branch string) (bool, error) {
+	query := url.Values{}
+	query.Set("state", "merged")
+	query.Set("source_branch", branch)
+	query.Set("target_branch", base)
+
+	var mergeRequests []struct {
+		ID int `json:"id"`
+	}
+	_, err := p.do(http.MethodGet, projectPath(repository)+"/merge_requests", query, nil, &mergeRequests)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list gitlab merge requests")
+	}
+
+	return len(mergeRequests) > 0, nil
+}
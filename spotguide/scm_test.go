@@ -0,0 +1,225 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractZipArchive(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	zipWriter := zip.NewWriter(buffer)
+
+	for name, content := range map[string]string{
+		"spotguide-nodejs-v0.1.0/README.md":                  "# Spotguide",
+		"spotguide-nodejs-v0.1.0/.banzaicloud/pipeline.yaml": "pipeline: {}",
+	} {
+		w, err := zipWriter.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zipWriter.Close())
+
+	files, err := extractZipArchive(buffer.Bytes())
+	require.NoError(t, err)
+
+	contents := map[string]string{}
+	for _, file := range files {
+		contents[file.Path] = string(file.Content)
+	}
+
+	assert.Equal(t, map[string]string{
+		"README.md":                  "# Spotguide",
+		".banzaicloud/pipeline.yaml": "pipeline: {}",
+	}, contents)
+}
+
+func TestExtractZipArchive_PathTraversal(t *testing.T) {
+	for _, name := range []string{
+		"spotguide-nodejs-v0.1.0/../../etc/passwd",
+		"spotguide-nodejs-v0.1.0//etc/passwd",
+		"spotguide-nodejs-v0.1.0/.git/hooks/post-checkout",
+	} {
+		t.Run(name, func(t *testing.T) {
+			buffer := bytes.NewBuffer(nil)
+			zipWriter := zip.NewWriter(buffer)
+
+			w, err := zipWriter.Create(name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte("content"))
+			require.NoError(t, err)
+			require.NoError(t, zipWriter.Close())
+
+			_, err = extractZipArchive(buffer.Bytes())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExtractZipArchive_Symlink(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	zipWriter := zip.NewWriter(buffer)
+
+	header := &zip.FileHeader{Name: "spotguide-nodejs-v0.1.0/passwd"}
+	header.SetMode(os.ModeSymlink | 0777)
+	w, err := zipWriter.CreateHeader(header)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("/etc/passwd"))
+	require.NoError(t, err)
+
+	w, err = zipWriter.Create("spotguide-nodejs-v0.1.0/README.md")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("# Spotguide"))
+	require.NoError(t, err)
+	require.NoError(t, zipWriter.Close())
+
+	files, err := extractZipArchive(buffer.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, []scmFile{{Path: "README.md", Content: []byte("# Spotguide")}}, files)
+}
+
+func TestValidateFilePath(t *testing.T) {
+	for _, path := range []string{"README.md", ".banzaicloud/pipeline.yaml", "a/b..c/d", ".gitignore"} {
+		assert.NoError(t, validateFilePath(path), path)
+	}
+
+	for _, path := range []string{"", "/etc/passwd", "../README.md", "a/../../b", "a/..", ".git/config", "./.git/config"} {
+		assert.Error(t, validateFilePath(path), path)
+	}
+}
+
+func TestGitSCMProvider(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	baseDir, err := ioutil.TempDir("", "spotguide-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(baseDir)
+
+	repoDir := filepath.Join(baseDir, "banzaicloud", "spotguide-nodejs.git")
+	require.NoError(t, exec.Command("git", "init", "--quiet", "--bare", repoDir).Run())
+
+	provider := newGitSCMProvider("file://"+baseDir, "user:token")
+
+	err = provider.CommitFiles("banzaicloud/spotguide-nodejs", []scmFile{
+		{Path: "README.md", Content: []byte("# Spotguide")},
+		{Path: ".banzaicloud/pipeline.yaml", Content: []byte("pipeline: {}")},
+	}, "initial commit")
+	require.NoError(t, err)
+
+	content, err := provider.DownloadFile("banzaicloud/spotguide-nodejs", ".banzaicloud/pipeline.yaml", "master")
+	require.NoError(t, err)
+	assert.Equal(t, "pipeline: {}", string(content))
+
+	_, err = provider.DownloadFile("banzaicloud/spotguide-nodejs", "../spotguide-nodejs.git/config", "master")
+	assert.Error(t, err)
+
+	workDir := filepath.Join(baseDir, "work")
+	require.NoError(t, exec.Command("git", "clone", "--quiet", repoDir, workDir).Run())
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(workDir, "passwd")))
+	require.NoError(t, exec.Command("git", "-C", workDir, "add", "passwd").Run())
+	require.NoError(t, exec.Command("git", "-C", workDir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "symlink").Run())
+	require.NoError(t, exec.Command("git", "-C", workDir, "push", "--quiet", "origin", "master").Run())
+
+	files, err := provider.DownloadContent("banzaicloud/spotguide-nodejs", "master")
+	require.NoError(t, err)
+	for _, file := range files {
+		assert.NotEqual(t, "passwd", file.Path)
+	}
+	assert.Len(t, files, 2)
+
+	err = provider.CommitFiles("banzaicloud/spotguide-nodejs", []scmFile{
+		{Path: "../../escaped", Content: []byte("content")},
+	}, "escape")
+	assert.Error(t, err)
+
+	_, err = provider.CreatePullRequest("banzaicloud/spotguide-nodejs", scmPullRequest{
+		Base:    "master",
+		Branch:  "spotguide-upgrade-0.2.0",
+		Title:   "Upgrade spotguide",
+		Updated: []scmFile{{Path: "README.md", Content: []byte("# Spotguide 0.2.0")}},
+	})
+	require.NoError(t, err)
+
+	merged, err := provider.IsBranchMerged("banzaicloud/spotguide-nodejs", "master", "spotguide-upgrade-0.2.0")
+	require.NoError(t, err)
+	assert.False(t, merged)
+
+	require.NoError(t, exec.Command("git", "-C", repoDir, "update-ref", "refs/heads/master", "refs/heads/spotguide-upgrade-0.2.0").Run())
+
+	merged, err = provider.IsBranchMerged("banzaicloud/spotguide-nodejs", "master", "spotguide-upgrade-0.2.0")
+	require.NoError(t, err)
+	assert.True(t, merged)
+
+	merged, err = provider.IsBranchMerged("banzaicloud/spotguide-nodejs", "master", "spotguide-upgrade-0.3.0")
+	require.NoError(t, err)
+	assert.False(t, merged)
+}
+
+func TestGitlabListSpotguideRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("Private-Token"))
+
+		switch r.URL.EscapedPath() {
+		case "/api/v4/groups/banzaicloud/projects":
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				w.Write([]byte(`[
+					{"id": 1, "path_with_namespace": "banzaicloud/spotguide-nodejs", "visibility": "public", "tag_list": ["spotguide"]},
+					{"id": 2, "path_with_namespace": "banzaicloud/pipeline", "visibility": "public", "tag_list": []}
+				]`))
+				return
+			}
+			w.Write([]byte(`[
+				{"id": 3, "path_with_namespace": "banzaicloud/sub/spotguide-java", "visibility": "public", "topics": ["spotguide"]},
+				{"id": 4, "path_with_namespace": "banzaicloud/spotguide-private", "visibility": "private", "topics": ["spotguide"]}
+			]`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := newGitlabSCMProvider(server.URL, "token")
+
+	repositories, err := provider.ListSpotguideRepositories("banzaicloud")
+	require.NoError(t, err)
+
+	assert.Equal(t, []scmRepository{
+		{FullName: "banzaicloud/spotguide-nodejs"},
+		{FullName: "banzaicloud/sub/spotguide-java"},
+	}, repositories)
+}
+
+func TestSplitRepoFullName(t *testing.T) {
+	owner, name := splitRepoFullName("banzaicloud/sub/spotguide-java")
+
+	assert.Equal(t, "banzaicloud/sub", owner)
+	assert.Equal(t, "spotguide-java", name)
+}
@@ -0,0 +1,80 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package spotguide
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+const SpotguideSettingsTableName = "spotguide_settings"
+
+// SpotguideSettings is the source code management configuration of an organization's own spotguides.
+// The spotguides are scraped from the owner (group, user) on the provider, and launched into the provider by default.
+type SpotguideSettings struct {
+	OrganizationID uint      `json:"organizationId" gorm:"primary_key;auto_increment:false"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	Provider       string    `json:"provider" binding:"required"`
+	Owner          string    `json:"owner,omitempty"`
+}
+
+func (SpotguideSettings) TableName() string {
+	return SpotguideSettingsTableName
+}
+
+func (s *SpotguideSettings) validate() error {
+	if !isSCMProvider(s.Provider) {
+		return &validationError{msg: fmt.Sprintf("unknown spotguide provider %q", s.Provider)}
+	}
+
+	return nil
+}
+
+// GetSettings returns the spotguide settings of an organization.
+// Organizations without settings use their spotguides on GitHub.
+func (s *SpotguideManager) GetSettings(org *auth.Organization) (*SpotguideSettings, error) {
+	settings := SpotguideSettings{}
+
+	err := s.db.Where(&SpotguideSettings{OrganizationID: org.ID}).First(&settings).Error
+	if gorm.IsRecordNotFoundError(err) {
+		settings = SpotguideSettings{
+			OrganizationID: org.ID,
+			Provider:       SCMProviderGithub,
+		}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get spotguide settings")
+	}
+
+	if settings.Owner == "" {
+		settings.Owner = org.Name
+	}
+
+	return &settings, nil
+}
+
+// UpdateSettings stores the spotguide settings of an organization, the spotguides are scraped on the next synchronization.
+func (s *SpotguideManager) UpdateSettings(org *auth.Organization, settings *SpotguideSettings) error {
+	settings.OrganizationID = org.ID
+
+	if err := settings.validate(); err != nil {
+		return err
+	}
+
+	return errors.Wrap(s.db.Save(settings).Error, "failed to update spotguide settings")
+}
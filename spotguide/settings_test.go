@@ -0,0 +1,49 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package spotguide
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpotguideManager_Settings(t *testing.T) {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&SpotguideSettings{}).Error)
+
+	manager := &SpotguideManager{db: db}
+	org := &auth.Organization{ID: 1, Name: "acme", Provider: auth.ProviderGitlab}
+
+	settings, err := manager.GetSettings(org)
+	require.NoError(t, err)
+	assert.Equal(t, SCMProviderGithub, settings.Provider, "the auth provider of the organization must not be used")
+	assert.Equal(t, "acme", settings.Owner)
+
+	err = manager.UpdateSettings(org, &SpotguideSettings{Provider: "bitbucket"})
+	assert.True(t, err.(*validationError).IsInvalid())
+
+	require.NoError(t, manager.UpdateSettings(org, &SpotguideSettings{Provider: SCMProviderGitlab, Owner: "acme-group"}))
+	require.NoError(t, manager.UpdateSettings(org, &SpotguideSettings{Provider: SCMProviderGitlab}))
+
+	settings, err = manager.GetSettings(org)
+	require.NoError(t, err)
+	assert.Equal(t, SCMProviderGitlab, settings.Provider)
+	assert.Equal(t, "acme", settings.Owner)
+}
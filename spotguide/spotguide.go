@@ -15,14 +15,9 @@
 package spotguide
 
 import (
-	"archive/zip"
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
 	"os"
 	"strings"
 	"text/template"
@@ -66,7 +61,7 @@ type SpotguideYAML struct {
 	Questions   []Question                `json:"questions"`
 }
 
-// Question is an opaque struct from Pipeline's point of view
+// Question is an opaque struct from Pipeline's point of view, except for its typed input declaration
 type Question map[string]interface{}
 
 type SpotguideRepo struct {
@@ -79,6 +74,8 @@ type SpotguideRepo struct {
 	Icon             []byte    `json:"-" gorm:"type:mediumblob"`
 	Readme           string    `json:"readme" gorm:"type:mediumtext"`
 	Version          string    `json:"version" gorm:"unique_index:name_and_version"`
+	Provider         string    `json:"provider"`
+	CatalogID        uint      `json:"catalogId,omitempty"`
 	SpotguideYAMLRaw []byte    `json:"-" gorm:"type:text"`
 	SpotguideYAML    `gorm:"-"`
 }
@@ -114,10 +111,12 @@ func (r *SpotguideRepo) AfterFind() error {
 type LaunchRequest struct {
 	SpotguideName    string                        `json:"spotguideName" binding:"required"`
 	SpotguideVersion string                        `json:"spotguideVersion,omitempty"`
+	RepoProvider     string                        `json:"repoProvider,omitempty"`
 	RepoOrganization string                        `json:"repoOrganization" binding:"required"`
 	RepoName         string                        `json:"repoName" binding:"required"`
 	RepoPrivate      bool                          `json:"repoPrivate"`
 	RepoLatent       bool                          `json:"repoLatent"`
+	DryRun           bool                          `json:"dryRun,omitempty"`
 	Cluster          *client.CreateClusterRequest  `json:"cluster" binding:"required"`
 	Secrets          []*secret.CreateSecretRequest `json:"secrets,omitempty"`
 	Pipeline         map[string]interface{}        `json:"pipeline,omitempty"`
@@ -131,54 +130,59 @@ type ReleaseBody struct {
 	Pipeline string `json:"pipeline" yaml:"pipeline"`
 }
 
-// SpotguideManager is responsible to scrape spotguides on GitHub and persist them to database
+// SpotguideManager is responsible to scrape spotguides on GitHub, GitLab or plain Git and persist them to database
 type SpotguideManager struct {
 	db                        *gorm.DB
 	pipelineVersion           *semver.Version
-	githubToken               string
+	sharedLibraryProvider     string
+	sharedLibraryToken        string
 	sharedLibraryOrganization *auth.Organization
 }
 
-func CreateSharedSpotguideOrganization(db *gorm.DB, sharedLibraryGitHubOrganization string) (*auth.Organization, error) {
+func CreateSharedSpotguideOrganization(db *gorm.DB, provider string, sharedLibraryOrganization string) (*auth.Organization, error) {
 	// insert shared organization to DB if not exists
-	var sharedOrg *auth.Organization
-	githubOrg, _, err := github.NewClient(nil).Organizations.Get(context.Background(), sharedLibraryGitHubOrganization)
-	if err != nil {
-		return nil, emperror.Wrap(err, "failed to query shared Github organization")
+	sharedOrg := &auth.Organization{Name: sharedLibraryOrganization, Provider: provider}
+
+	if provider == SCMProviderGithub {
+		githubOrg, _, err := github.NewClient(nil).Organizations.Get(context.Background(), sharedLibraryOrganization)
+		if err != nil {
+			return nil, emperror.Wrap(err, "failed to query shared Github organization")
+		}
+		sharedOrg = &auth.Organization{Name: *githubOrg.Login, GithubID: githubOrg.ID, Provider: auth.ProviderGithub}
 	}
-	sharedOrg = &auth.Organization{Name: *githubOrg.Login, GithubID: githubOrg.ID, Provider: auth.ProviderGithub}
+
 	if err := db.Where(sharedOrg).FirstOrCreate(sharedOrg).Error; err != nil {
 		return nil, emperror.Wrap(err, "failed to create shared organization")
 	}
 	return sharedOrg, nil
 }
 
-func NewSpotguideManager(db *gorm.DB, pipelineVersionString string, githubToken string, sharedLibraryOrganization *auth.Organization) *SpotguideManager {
+func NewSpotguideManager(db *gorm.DB, pipelineVersionString string, sharedLibraryProvider string, sharedLibraryToken string, sharedLibraryOrganization *auth.Organization) *SpotguideManager {
 
 	pipelineVersion, _ := semver.NewVersion(pipelineVersionString)
 
 	return &SpotguideManager{
 		db:                        db,
 		pipelineVersion:           pipelineVersion,
-		githubToken:               githubToken,
+		sharedLibraryProvider:     sharedLibraryProvider,
+		sharedLibraryToken:        sharedLibraryToken,
 		sharedLibraryOrganization: sharedLibraryOrganization,
 	}
 }
 
-func (s *SpotguideManager) isSpotguideReleaseAllowed(release *github.RepositoryRelease) bool {
-	version, err := semver.NewVersion(release.GetTagName())
+func (s *SpotguideManager) isSpotguideReleaseAllowed(release scmRelease) bool {
+	version, err := semver.NewVersion(release.Tag)
 	if err != nil {
 		log.Warn("failed to parse spotguide release tag: ", err)
 		return false
 	}
 
 	supported := true
-	prerelease := version.Prerelease() != "" || *release.Prerelease
+	prerelease := version.Prerelease() != "" || release.Prerelease
 
 	// try to parse release body as YAML
-	rawBody := release.GetBody()
 	body := ReleaseBody{}
-	err = yaml2.Unmarshal([]byte(rawBody), &body)
+	err = yaml2.Unmarshal([]byte(release.Body), &body)
 	if s.pipelineVersion != nil && err == nil {
 		// check whether this release has support for this pipeline version
 		supportedConstraint, err := semver.NewConstraint(body.Pipeline)
@@ -190,61 +194,83 @@ func (s *SpotguideManager) isSpotguideReleaseAllowed(release *github.RepositoryR
 	return supported && (!prerelease || viper.GetBool(config.SpotguideAllowPrereleases))
 }
 
+// spotguideSource is a set of spotguide repositories hosted by a provider.
+type spotguideSource struct {
+	// catalogID is zero for the organization's own and the shared spotguides
+	catalogID    uint
+	providerName string
+	provider     scmProvider
+	repositories []scmRepository
+	// verifier is nil if the releases don't have to be signed
+	verifier *manifestVerifier
+	// unavailable catalogs couldn't be listed, their previously scraped spotguides are kept
+	unavailable bool
+}
+
 func (s *SpotguideManager) ScrapeSharedSpotguides() error {
 	if s.sharedLibraryOrganization == nil {
 		return errors.New("failed to scrape shared spotguides")
 	}
 
-	githubClient := auth.NewGithubClient(s.githubToken)
-	return s.scrapeSpotguides(s.sharedLibraryOrganization, githubClient)
-}
+	provider, err := newSCMProvider(s.sharedLibraryProvider, s.sharedLibraryToken)
+	if err != nil {
+		return emperror.Wrap(err, "failed to create spotguide provider")
+	}
 
-func (s *SpotguideManager) ScrapeSpotguides(orgID uint, userID uint) error {
-	githubClient, err := auth.NewGithubClientForUser(userID)
+	repositories, err := provider.ListSpotguideRepositories(s.sharedLibraryOrganization.Name)
 	if err != nil {
-		return emperror.Wrap(err, "failed to create GitHub client")
+		return emperror.Wrap(err, "failed to list spotguide repositories")
 	}
 
-	org, err := auth.GetOrganizationById(orgID)
+	verifier, err := sharedLibraryVerifier()
 	if err != nil {
-		return emperror.Wrap(err, "failed to resolve organization from id")
+		return emperror.Wrap(err, "invalid shared spotguide library public key")
 	}
 
-	return s.scrapeSpotguides(org, githubClient)
+	return s.scrapeSpotguides(s.sharedLibraryOrganization, []spotguideSource{{
+		providerName: s.sharedLibraryProvider,
+		provider:     provider,
+		repositories: repositories,
+		verifier:     verifier,
+	}})
 }
 
-func (s *SpotguideManager) scrapeSpotguides(org *auth.Organization, githubClient *github.Client) error {
-	var allRepositories []github.Repository
-	query := fmt.Sprintf("org:%s topic:%s fork:true", org.Name, SpotguideGithubTopic)
-	if !viper.GetBool(config.SpotguideAllowPrivateRepos) {
-		query += " is:public"
+func (s *SpotguideManager) ScrapeSpotguides(orgID uint, userID uint) error {
+	org, err := auth.GetOrganizationById(orgID)
+	if err != nil {
+		return emperror.Wrap(err, "failed to resolve organization from id")
 	}
-	listOpts := github.ListOptions{PerPage: 100}
-	for {
-		reposRes, resp, err := githubClient.Search.Repositories(ctx, query, &github.SearchOptions{
-			Sort:        "created",
-			Order:       "asc",
-			ListOptions: listOpts,
-		})
 
-		if err != nil {
-			// Empty organization, no repositories
-			if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
-				return nil
-			}
+	settings, err := s.GetSettings(org)
+	if err != nil {
+		return err
+	}
 
-			return emperror.Wrap(err, "failed to list github repositories")
-		}
+	provider, err := newSCMProviderForUser(settings.Provider, userID)
+	if err != nil {
+		return emperror.Wrap(err, "failed to create spotguide provider")
+	}
 
-		allRepositories = append(allRepositories, reposRes.Repositories...)
+	repositories, err := provider.ListSpotguideRepositories(settings.Owner)
+	if err != nil {
+		return emperror.Wrap(err, "failed to list spotguide repositories")
+	}
 
-		if resp.NextPage == 0 {
-			break
-		}
+	sources := []spotguideSource{{
+		providerName: settings.Provider,
+		provider:     provider,
+		repositories: repositories,
+	}}
 
-		listOpts.Page = resp.NextPage
+	catalogSources, err := s.catalogSources(org.ID, userID)
+	if err != nil {
+		return emperror.Wrap(err, "failed to list spotguide catalogs")
 	}
 
+	return s.scrapeSpotguides(org, append(sources, catalogSources...))
+}
+
+func (s *SpotguideManager) scrapeSpotguides(org *auth.Organization, sources []spotguideSource) error {
 	where := SpotguideRepo{
 		OrganizationID: org.ID,
 	}
@@ -261,64 +287,134 @@ func (s *SpotguideManager) scrapeSpotguides(org *auth.Organization, githubClient
 		oldSpotguidesIndexed[sg.Key()] = sg
 	}
 
-	for _, repository := range allRepositories {
-		owner := repository.GetOwner().GetLogin()
-		name := repository.GetName()
-
-		releases, _, err := githubClient.Repositories.ListReleases(ctx, owner, name, &github.ListOptions{})
-		if err != nil {
-			return emperror.Wrap(err, "failed to list github repo releases")
+	// spotguides are identified by their names, so the first source (the organization's own spotguides come first)
+	// claims a name, the repositories of the same name in the other catalogs are rejected
+	nameOwners := map[string]uint{}
+	claimName := func(catalogID uint, name string) bool {
+		owner, ok := nameOwners[name]
+		if !ok {
+			nameOwners[name] = catalogID
+			return true
 		}
 
-		for _, release := range releases {
+		return owner == catalogID
+	}
 
-			if !s.isSpotguideReleaseAllowed(release) {
-				continue
+	// keepSpotguides prevents deleting the spotguides of a catalog repository (or a whole catalog if name is empty)
+	// which couldn't be scraped this time
+	keepSpotguides := func(catalogID uint, name string) {
+		for _, sg := range oldSpotguides {
+			if sg.CatalogID == catalogID && (name == "" || sg.Name == name) && claimName(catalogID, sg.Name) {
+				delete(oldSpotguidesIndexed, sg.Key())
 			}
+		}
+	}
 
-			tag := release.GetTagName()
-
-			spotguideRaw, err := downloadGithubFile(githubClient, owner, name, SpotguideYAMLPath, tag)
-			if err != nil {
-				log.Warnf("failed to scrape spotguide.yaml of '%s/%s' at version '%s': %s", owner, name, tag, err)
-				continue
-			}
+	for _, source := range sources {
+		if source.unavailable {
+			keepSpotguides(source.catalogID, "")
+			continue
+		}
 
-			// syntax check spotguide.yaml
-			err = yaml2.Unmarshal(spotguideRaw, &SpotguideYAML{})
-			if err != nil {
-				log.Warnf("failed to parse spotguide.yaml of '%s/%s' at version '%s': %s", owner, name, tag, err)
-				continue
-			}
+		provider := source.provider
 
-			readme, err := downloadGithubFile(githubClient, owner, name, ReadmePath, tag)
-			if err != nil {
-				log.Warnf("failed to scrape the readme of '%s/%s' at version '%s': %s", owner, name, tag, err)
-			}
+		for _, repository := range source.repositories {
+			name := repository.FullName
 
-			icon, err := downloadGithubFile(githubClient, owner, name, IconPath, tag)
-			if err != nil {
-				log.Warnf("failed to scrape the icon of '%s/%s' at version '%s': %s", owner, name, tag, err)
+			if !claimName(source.catalogID, name) {
+				log.Warnf("spotguide '%s' of spotguide catalog %d is already provided by another source", name, source.catalogID)
+				continue
 			}
 
-			model := SpotguideRepo{
-				OrganizationID:   org.ID,
-				Name:             repository.GetFullName(),
-				SpotguideYAMLRaw: spotguideRaw,
-				Readme:           string(readme),
-				Icon:             icon,
-				Version:          tag,
+			releases, err := provider.ListReleases(name)
+			if err != nil && source.catalogID != 0 {
+				log.Warnf("failed to list releases of '%s' in spotguide catalog %d: %s", name, source.catalogID, err)
+				keepSpotguides(source.catalogID, name)
+				continue
+			} else if err != nil {
+				return emperror.Wrap(err, "failed to list repo releases")
 			}
 
-			where := model.Key()
-
-			err = s.db.Where(&where).Assign(&model).FirstOrCreate(&SpotguideRepo{}).Error
-
-			if err != nil {
-				return err
+			for _, release := range releases {
+
+				if !s.isSpotguideReleaseAllowed(release) {
+					continue
+				}
+
+				tag := release.Tag
+
+				var manifest *releaseManifest
+				if source.verifier != nil {
+					manifest, err = source.verifier.verifyRelease(provider, name, tag)
+					if err != nil {
+						log.Warnf("failed to verify the manifest of '%s' at version '%s': %s", name, tag, err)
+						continue
+					}
+				}
+
+				spotguideRaw, err := provider.DownloadFile(name, SpotguideYAMLPath, tag)
+				if err != nil {
+					log.Warnf("failed to scrape spotguide.yaml of '%s' at version '%s': %s", name, tag, err)
+					continue
+				}
+
+				// syntax check spotguide.yaml
+				spotguideYAML := SpotguideYAML{}
+				err = yaml2.Unmarshal(spotguideRaw, &spotguideYAML)
+				if err != nil {
+					log.Warnf("failed to parse spotguide.yaml of '%s' at version '%s': %s", name, tag, err)
+					continue
+				}
+
+				err = validateQuestions(spotguideYAML.Questions)
+				if err != nil {
+					log.Warnf("invalid questions in spotguide.yaml of '%s' at version '%s': %s", name, tag, err)
+					continue
+				}
+
+				readme, err := provider.DownloadFile(name, ReadmePath, tag)
+				if err != nil {
+					log.Warnf("failed to scrape the readme of '%s' at version '%s': %s", name, tag, err)
+				}
+
+				icon, err := provider.DownloadFile(name, IconPath, tag)
+				if err != nil {
+					log.Warnf("failed to scrape the icon of '%s' at version '%s': %s", name, tag, err)
+				}
+
+				if manifest != nil {
+					err = verifyScrapedFiles(manifest, map[string][]byte{
+						SpotguideYAMLPath: spotguideRaw,
+						ReadmePath:        readme,
+						IconPath:          icon,
+					})
+					if err != nil {
+						log.Warnf("failed to verify the files of '%s' at version '%s': %s", name, tag, err)
+						continue
+					}
+				}
+
+				model := SpotguideRepo{
+					OrganizationID:   org.ID,
+					Name:             name,
+					SpotguideYAMLRaw: spotguideRaw,
+					Readme:           string(readme),
+					Icon:             icon,
+					Version:          tag,
+					Provider:         source.providerName,
+					CatalogID:        source.catalogID,
+				}
+
+				where := model.Key()
+
+				err = s.db.Where(&where).Assign(&model).FirstOrCreate(&SpotguideRepo{}).Error
+
+				if err != nil {
+					return err
+				}
+
+				delete(oldSpotguidesIndexed, model.Key())
 			}
-
-			delete(oldSpotguidesIndexed, model.Key())
 		}
 	}
 
@@ -332,6 +428,21 @@ func (s *SpotguideManager) scrapeSpotguides(org *auth.Organization, githubClient
 	return nil
 }
 
+// verifyScrapedFiles checks the checksums of the downloaded files, missing optional files are skipped.
+func verifyScrapedFiles(manifest *releaseManifest, files map[string][]byte) error {
+	for path, content := range files {
+		if content == nil {
+			continue
+		}
+
+		if err := manifest.verifyFile(path, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *SpotguideManager) GetSpotguides(orgID uint) (spotguides []*SpotguideRepo, err error) {
 	query := s.db.Where(SpotguideRepo{OrganizationID: orgID})
 	if s.sharedLibraryOrganization != nil {
@@ -357,43 +468,14 @@ func (s *SpotguideManager) GetSpotguide(orgID uint, name, version string) (*Spot
 	return &spotguide, nil
 }
 
-func (s *SpotguideManager) LaunchSpotguide(request *LaunchRequest, org *auth.Organization, user *auth.User) error {
-	sourceRepo, err := s.GetSpotguide(org.ID, request.SpotguideName, request.SpotguideVersion)
-	if err != nil {
-		return errors.Wrap(err, "failed to find spotguide repo")
-	}
-
-	// LaunchRequest might not have the version
-	request.SpotguideVersion = sourceRepo.Version
-
-	err = createSecrets(request, org.ID, user.ID)
-	if err != nil {
-		return errors.Wrap(err, "failed to create secrets for spotguide")
-	}
-
-	githubClient, err := auth.NewGithubClientForUser(user.ID)
-	if err != nil {
-		return errors.Wrap(err, "failed to create GitHub client")
-	}
-
-	err = createGithubRepo(githubClient, request, user.ID, sourceRepo)
-	if err != nil {
-		return errors.Wrap(err, "failed to create GitHub repository")
-	}
-
-	cicdClient := auth.NewCICDClient(user.APIToken)
-
-	err = enableCICD(cicdClient, request, org.Name)
-	if err != nil {
-		return errors.Wrap(err, "failed to enable CI/CD for spotguide")
+// sourceProvider returns the provider the spotguide can be downloaded from,
+// shared spotguides are downloaded with the shared library credentials.
+func (s *SpotguideManager) sourceProvider(sourceRepo *SpotguideRepo, userID uint) (scmProvider, error) {
+	if s.sharedLibraryOrganization != nil && sourceRepo.OrganizationID == s.sharedLibraryOrganization.ID {
+		return newSCMProvider(sourceRepo.Provider, s.sharedLibraryToken)
 	}
 
-	err = addSpotguideContent(githubClient, request, user.ID, sourceRepo)
-	if err != nil {
-		return errors.Wrap(err, "failed to add spotguide content to repository")
-	}
-
-	return nil
+	return newSCMProviderForUser(sourceRepo.Provider, userID)
 }
 
 func preparePipelineYAML(request *LaunchRequest, sourceRepo *SpotguideRepo, pipelineYAML []byte) ([]byte, error) {
@@ -411,202 +493,85 @@ func preparePipelineYAML(request *LaunchRequest, sourceRepo *SpotguideRepo, pipe
 	return repoConfigRaw, nil
 }
 
-func getSpotguideContent(githubClient *github.Client, request *LaunchRequest, sourceRepo *SpotguideRepo) ([]github.TreeEntry, error) {
-	// Download source repo zip
-	sourceRepoParts := strings.Split(sourceRepo.Name, "/")
-	sourceRepoOwner := sourceRepoParts[0]
-	sourceRepoName := sourceRepoParts[1]
-
-	sourceRelease, _, err := githubClient.Repositories.GetReleaseByTag(ctx, sourceRepoOwner, sourceRepoName, request.SpotguideVersion)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to find source spotguide repository release")
-	}
-
-	// Support private repositories via downloading with an authenticated client
-	downloadRequest, err := http.NewRequest(http.MethodGet, sourceRelease.GetZipballURL(), nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create source spotguide repository release download request")
-	}
-
-	repoBytes := bytes.NewBuffer(nil)
-	_, err = githubClient.Do(ctx, downloadRequest, repoBytes)
+// getSpotguideContent downloads and prepares the content of a spotguide release,
+// if verifier is not nil the release has to match its signed manifest.
+func getSpotguideContent(provider scmProvider, request *LaunchRequest, sourceRepo *SpotguideRepo, verifier *manifestVerifier) ([]scmFile, error) {
+	// Download source repo content
+	sourceFiles, err := provider.DownloadContent(sourceRepo.Name, request.SpotguideVersion)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to download source spotguide repository release")
 	}
 
-	zipReader, err := zip.NewReader(bytes.NewReader(repoBytes.Bytes()), int64(repoBytes.Len()))
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to extract source spotguide repository release")
-	}
-
-	// List the files here that needs to be created in this commit and create a tree from them
-	entries := []github.TreeEntry{}
-
-	for _, zf := range zipReader.File {
-		if zf.FileInfo().IsDir() {
-			continue
+	if verifier != nil {
+		if err := verifier.verifyReleaseFiles(sourceFiles); err != nil {
+			return nil, errors.Wrap(err, "failed to verify spotguide release")
 		}
+	}
 
-		// First directory is the name of the repo
-		path := strings.SplitN(zf.Name, "/", 2)[1]
+	// List the files here that needs to be created in this commit
+	files := []scmFile{}
 
-		// Skip files inside ignored paths
-		if isIgnoredPath(path) {
+	for _, file := range sourceFiles {
+		// Skip files inside ignored paths and the release manifest
+		if isIgnoredPath(file.Path) || file.Path == ManifestPath || file.Path == ManifestSignaturePath {
 			continue
 		}
 
-		file, err := zf.Open()
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to extract source spotguide repository release")
-		}
-		defer file.Close()
-
-		content, err := ioutil.ReadAll(file)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to extract source spotguide repository release")
-		}
-
 		// Prepare pipeline.yaml
-		if path == PipelineYAMLPath {
-			content, err = preparePipelineYAML(request, sourceRepo, content)
+		if file.Path == PipelineYAMLPath {
+			content, err := preparePipelineYAML(request, sourceRepo, file.Content)
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to prepare pipeline.yaml")
 			}
-		}
 
-		// The GitHub API accepts blobs as utf-8 by default, and we can change the encoding only in the
-		// CreateBlob call, so if the file is utf-8 let's spare an API call, otherwise create the blob
-		// with base64 encoding specified.
-		var blobSHA, blobContent *string
-
-		if strings.HasSuffix(http.DetectContentType(content), "charset=utf-8") {
-			blobContent = github.String(string(content))
-		} else {
-			blob, _, err := githubClient.Git.CreateBlob(ctx, request.RepoOrganization, request.RepoName, &github.Blob{
-				Content:  github.String(base64.StdEncoding.EncodeToString(content)),
-				Encoding: github.String("base64"),
-			})
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to create blob for spotguide repository: "+path)
-			}
-
-			blobSHA = blob.SHA
+			file.Content = content
 		}
 
-		entry := github.TreeEntry{
-			Type:    github.String("blob"),
-			Mode:    github.String("100644"),
-			Path:    github.String(path),
-			SHA:     blobSHA,
-			Content: blobContent,
-		}
-
-		entries = append(entries, entry)
+		files = append(files, file)
 	}
 
-	return entries, nil
+	return files, nil
 }
 
-func createGithubRepo(githubClient *github.Client, request *LaunchRequest, userID uint, sourceRepo *SpotguideRepo) error {
-
-	repo := github.Repository{
-		Name:        github.String(request.RepoName),
-		Description: github.String("Spotguide by Banzai Cloud"),
-		Private:     github.Bool(request.RepoPrivate),
-	}
-
-	// If the user's name is used as organization name, it has to be cleared in repo create.
-	// See: https://developer.github.com/v3/repos/#create
-	orgName := request.RepoOrganization
-	if auth.GetUserNickNameById(userID) == orgName {
-		orgName = ""
-	}
-
-	_, _, err := githubClient.Repositories.Create(ctx, orgName, &repo)
-	if err != nil {
-		return errors.Wrap(err, "failed to create spotguide repository")
-	}
-
-	log.Infof("created spotguide repository: %s", request.RepoFullname())
-	return nil
-}
-
-func addSpotguideContent(githubClient *github.Client, request *LaunchRequest, userID uint, sourceRepo *SpotguideRepo) error {
-
-	// An initial files have to be created with the API to be able to use the fresh repo
-	createFile := &github.RepositoryContentFileOptions{
-		Content: []byte("# Say hello to Spotguides!"),
-		Message: github.String("initial import"),
-	}
-
-	contentResponse, _, err := githubClient.Repositories.CreateFile(ctx, request.RepoOrganization, request.RepoName, "README.md", createFile)
-
-	if err != nil {
-		return errors.Wrap(err, "failed to initialize spotguide repository")
-	}
+func addSpotguideContent(sourceProvider, targetProvider scmProvider, request *LaunchRequest, sourceRepo *SpotguideRepo, verifier *manifestVerifier) error {
 
 	// Prepare the spotguide commit
-	spotguideEntries, err := getSpotguideContent(githubClient, request, sourceRepo)
+	spotguideFiles, err := getSpotguideContent(sourceProvider, request, sourceRepo, verifier)
 	if err != nil {
 		return errors.Wrap(err, "failed to prepare spotguide git content")
 	}
 
-	tree, _, err := githubClient.Git.CreateTree(ctx, request.RepoOrganization, request.RepoName, contentResponse.GetSHA(), spotguideEntries)
-
-	if err != nil {
-		return errors.Wrap(err, "failed to create git tree for spotguide repository")
-	}
-
-	// Create a commit from the tree
-	contentResponse.Commit.SHA = contentResponse.SHA
-
-	commit := &github.Commit{
-		Message: github.String("initial Banzai Cloud Pipeline commit"),
-		Parents: []github.Commit{contentResponse.Commit},
-		Tree:    tree,
-	}
-
-	newCommit, _, err := githubClient.Git.CreateCommit(ctx, request.RepoOrganization, request.RepoName, commit)
-
-	if err != nil {
-		return errors.Wrap(err, "failed to create git commit for spotguide repository")
-	}
-
-	// Attach the commit to the master branch.
-	// This can be changed later to another branch + create PR.
-	// See: https://github.com/google/go-github/blob/master/example/commitpr/main.go#L62
-	ref, _, err := githubClient.Git.GetRef(ctx, request.RepoOrganization, request.RepoName, "refs/heads/master")
-	if err != nil {
-		return errors.Wrap(err, "failed to get git ref for spotguide repository")
-	}
-
-	ref.Object.SHA = newCommit.SHA
-
-	_, _, err = githubClient.Git.UpdateRef(ctx, request.RepoOrganization, request.RepoName, ref, false)
-
+	err = targetProvider.CommitFiles(request.RepoFullname(), spotguideFiles, "initial Banzai Cloud Pipeline commit")
 	if err != nil {
-		return errors.Wrap(err, "failed to update git ref for spotguide repository")
+		return errors.Wrap(err, "failed to commit spotguide content to repository")
 	}
 
 	return nil
 }
 
-func createSecrets(request *LaunchRequest, orgID uint, userID uint) error {
+// createSecrets creates the secrets of the launch request and returns the IDs of the created ones,
+// even if the creation of a secret fails, so that they can be rolled back.
+func createSecrets(request *LaunchRequest, orgID uint, userID uint) ([]string, error) {
 
 	repoTag := "repo:" + request.RepoFullname()
 
+	var secretIDs []string
+
 	for _, secretRequest := range request.Secrets {
 
 		secretRequest.Tags = append(secretRequest.Tags, repoTag)
 
-		if _, err := secret.Store.Store(orgID, secretRequest); err != nil {
-			return errors.Wrap(err, "failed to create spotguide secret: "+secretRequest.Name)
+		secretID, err := secret.Store.Store(orgID, secretRequest)
+		if err != nil {
+			return secretIDs, errors.Wrap(err, "failed to create spotguide secret: "+secretRequest.Name)
 		}
+
+		secretIDs = append(secretIDs, secretID)
 	}
 
 	log.Infof("created secrets for spotguide: %s", request.RepoFullname())
 
-	return nil
+	return secretIDs, nil
 }
 
 func enableCICD(cicdClient cicd.Client, request *LaunchRequest, org string) error {
@@ -795,20 +760,6 @@ func cicdRepoConfigPipeline(request *LaunchRequest, repoConfig *cicdRepoConfig)
 	return nil
 }
 
-func downloadGithubFile(githubClient *github.Client, owner, repo, file, tag string) ([]byte, error) {
-	reader, err := githubClient.Repositories.DownloadContents(ctx, owner, repo, file, &github.RepositoryContentGetOptions{
-		Ref: tag,
-	})
-	if err != nil {
-		return nil, emperror.Wrap(err, "failed to download file from GitHub")
-	}
-
-	defer reader.Close()
-
-	data, err := ioutil.ReadAll(reader)
-	return data, emperror.Wrap(err, "failed to download file from GitHub")
-}
-
 func isIgnoredPath(path string) bool {
 	for _, ignoredPath := range IgnoredPaths {
 		if path == ignoredPath || strings.HasPrefix(path, ignoredPath+string(os.PathSeparator)) {
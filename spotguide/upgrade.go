@@ -0,0 +1,211 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/pkg/errors"
+)
+
+const SpotguideUpgradeTableName = "spotguide_upgrades"
+
+// SpotguideUpgradeBaseBranch is the branch of launched repositories the upgrades are proposed to.
+const SpotguideUpgradeBaseBranch = "master"
+
+// SpotguideUpgrade is the record of a spotguide upgrade proposed to a launched repository.
+type SpotguideUpgrade struct {
+	ID             uint      `json:"id" gorm:"primary_key"`
+	LaunchID       uint      `json:"launchId" gorm:"index"`
+	UserID         uint      `json:"userId"`
+	CreatedAt      time.Time `json:"createdAt"`
+	FromVersion    string    `json:"fromVersion"`
+	ToVersion      string    `json:"toVersion"`
+	Branch         string    `json:"branch,omitempty"`
+	PullRequestURL string    `json:"pullRequestUrl,omitempty"`
+	Conflicts      []string  `json:"conflicts,omitempty" gorm:"-"`
+}
+
+func (SpotguideUpgrade) TableName() string {
+	return SpotguideUpgradeTableName
+}
+
+// UpgradeRequest describes the spotguide version a launched repository should be upgraded to.
+type UpgradeRequest struct {
+	// SpotguideVersion defaults to the latest version of the spotguide
+	SpotguideVersion string `json:"spotguideVersion,omitempty"`
+}
+
+type upgradeError struct {
+	msg string
+}
+
+func (e *upgradeError) Error() string {
+	return e.msg
+}
+
+func (e *upgradeError) IsInvalid() bool {
+	return true
+}
+
+// UpgradeSpotguide re-applies a newer spotguide release to a launched repository.
+// The changes between the launched and the new release are merged into the current content of the repository,
+// and they are proposed in a pull request.
+func (s *SpotguideManager) UpgradeSpotguide(orgID uint, launchID uint, upgradeRequest *UpgradeRequest, user *auth.User) (*SpotguideUpgrade, error) {
+	launch, err := s.GetLaunch(orgID, launchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if launch.Status != LaunchStatusSucceeded {
+		return nil, &upgradeError{msg: fmt.Sprintf("spotguide launch is in %s status, only succeeded launches can be upgraded", launch.Status)}
+	}
+
+	if len(launch.Request) == 0 {
+		return nil, &upgradeError{msg: "spotguide launch was created before upgrades were supported"}
+	}
+
+	targetProvider, err := newSCMProviderForUser(launch.RepoProvider, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create spotguide target provider")
+	}
+
+	fromVersion, err := s.currentVersion(launch, targetProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceRepo, err := s.GetSpotguide(orgID, launch.SpotguideName, upgradeRequest.SpotguideVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find spotguide repo")
+	}
+
+	if sourceRepo.Version == fromVersion {
+		return nil, &upgradeError{msg: fmt.Sprintf("repository is already at spotguide version %s", fromVersion)}
+	}
+
+	var request LaunchRequest
+	if err := json.Unmarshal(launch.Request, &request); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal launch request")
+	}
+
+	sourceProvider, err := s.sourceProvider(sourceRepo, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create spotguide source provider")
+	}
+
+	verifier, err := s.releaseVerifier(sourceRepo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create spotguide release verifier")
+	}
+
+	// The launched and the new release are rendered with the original launch request, so that only the spotguide changes differ
+	request.SpotguideVersion = fromVersion
+	baseFiles, err := getSpotguideContent(sourceProvider, &request, sourceRepo, verifier)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get launched spotguide content")
+	}
+
+	request.SpotguideVersion = sourceRepo.Version
+	theirFiles, err := getSpotguideContent(sourceProvider, &request, sourceRepo, verifier)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get new spotguide content")
+	}
+
+	ourFiles, err := targetProvider.DownloadBranch(launch.RepoFullname, SpotguideUpgradeBaseBranch)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download repository content")
+	}
+
+	result := mergeTrees(baseFiles, theirFiles, ourFiles, "spotguide "+sourceRepo.Version)
+
+	upgrade := &SpotguideUpgrade{
+		LaunchID:    launch.ID,
+		UserID:      user.ID,
+		FromVersion: fromVersion,
+		ToVersion:   sourceRepo.Version,
+		Conflicts:   result.Conflicts,
+	}
+
+	if result.changed() {
+		// the same version may be proposed again if the previous pull request was closed without merging
+		upgrade.Branch = fmt.Sprintf("spotguide-upgrade-%s-%d", sourceRepo.Version, time.Now().Unix())
+
+		upgrade.PullRequestURL, err = targetProvider.CreatePullRequest(launch.RepoFullname, scmPullRequest{
+			Base:    SpotguideUpgradeBaseBranch,
+			Branch:  upgrade.Branch,
+			Title:   fmt.Sprintf("Upgrade spotguide %s to %s", launch.SpotguideName, sourceRepo.Version),
+			Body:    upgradePullRequestBody(launch, upgrade),
+			Created: result.Created,
+			Updated: result.Updated,
+			Deleted: result.Deleted,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open spotguide upgrade pull request")
+		}
+	}
+
+	if err := s.db.Create(upgrade).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to persist spotguide upgrade")
+	}
+
+	log.Infof("upgraded spotguide repository %s from %s to %s", launch.RepoFullname, fromVersion, sourceRepo.Version)
+
+	return upgrade, nil
+}
+
+// currentVersion returns the spotguide version of the launched repository, taking the previous upgrades into account:
+// the version of the last upgrade which has been merged (or had nothing to merge) is the current one.
+func (s *SpotguideManager) currentVersion(launch *SpotguideLaunch, targetProvider scmProvider) (string, error) {
+	var upgrades []SpotguideUpgrade
+
+	err := s.db.Where(&SpotguideUpgrade{LaunchID: launch.ID}).Order("id desc").Find(&upgrades).Error
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find spotguide upgrades")
+	}
+
+	for _, upgrade := range upgrades {
+		if upgrade.Branch == "" {
+			return upgrade.ToVersion, nil
+		}
+
+		merged, err := targetProvider.IsBranchMerged(launch.RepoFullname, SpotguideUpgradeBaseBranch, upgrade.Branch)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to check spotguide upgrade branch")
+		}
+
+		if merged {
+			return upgrade.ToVersion, nil
+		}
+	}
+
+	return launch.SpotguideVersion, nil
+}
+
+func upgradePullRequestBody(launch *SpotguideLaunch, upgrade *SpotguideUpgrade) string {
+	body := fmt.Sprintf("This pull request upgrades the repository from spotguide %s version %s to %s.\n",
+		launch.SpotguideName, upgrade.FromVersion, upgrade.ToVersion)
+
+	if len(upgrade.Conflicts) > 0 {
+		body += "\nThe following files were changed both in the repository and in the spotguide, please resolve the conflicts:\n\n- " +
+			strings.Join(upgrade.Conflicts, "\n- ") + "\n"
+	}
+
+	return body
+}
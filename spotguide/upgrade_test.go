@@ -0,0 +1,62 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spotguide
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mergedBranchesProvider struct {
+	scmProvider
+
+	merged map[string]bool
+}
+
+func (p mergedBranchesProvider) IsBranchMerged(repository, base, branch string) (bool, error) {
+	return p.merged[branch], nil
+}
+
+func TestCurrentVersion(t *testing.T) {
+	manager, launch := newTestLaunch(t, nil)
+	launch.SpotguideVersion = "0.1.0"
+
+	require.NoError(t, manager.db.AutoMigrate(&SpotguideUpgrade{}).Error)
+
+	provider := mergedBranchesProvider{merged: map[string]bool{}}
+
+	version, err := manager.currentVersion(launch, provider)
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.0", version)
+
+	for _, upgrade := range []SpotguideUpgrade{
+		{LaunchID: launch.ID, FromVersion: "0.1.0", ToVersion: "0.2.0", Branch: "spotguide-upgrade-0.2.0-1"},
+		{LaunchID: launch.ID, FromVersion: "0.1.0", ToVersion: "0.3.0", Branch: "spotguide-upgrade-0.3.0-2"},
+	} {
+		require.NoError(t, manager.db.Create(&upgrade).Error)
+	}
+
+	version, err = manager.currentVersion(launch, provider)
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.0", version, "unmerged upgrades are ignored")
+
+	provider.merged["spotguide-upgrade-0.2.0-1"] = true
+
+	version, err = manager.currentVersion(launch, provider)
+	require.NoError(t, err)
+	assert.Equal(t, "0.2.0", version)
+}